@@ -56,50 +56,48 @@ func (u *Mux) RegisterProvider(key string, p func(string) (*Driver, error)) {
 }
 
 // OpenAtlas is used for opening an atlas driver on a specific data source.
+// Errors returned by the driver are redacted from the password of the dsn.
 func (u *Mux) OpenAtlas(dsn string) (*Driver, error) {
-	key, dsn, err := parseDSN(dsn)
+	pu, err := ParseURL(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init atlas driver, %s", err)
 	}
-	p, ok := u.providers[key]
+	p, ok := u.providers[pu.Driver]
 	if !ok {
-		return nil, fmt.Errorf("could not find provider: %s", key)
+		return nil, fmt.Errorf("could not find provider: %s", pu.Driver)
 	}
-	if key == "sqlite" {
-		if err := sqliteFileExists(dsn); err != nil {
+	if pu.Driver == "sqlite" {
+		if err := sqliteFileExists(pu.DSN); err != nil {
 			return nil, err
 		}
 	}
-	return p(dsn)
-}
-
-func parseDSN(url string) (string, string, error) {
-	a := strings.SplitN(url, "://", 2)
-	if len(a) != 2 {
-		return "", "", fmt.Errorf(`failed to parse dsn: "%s"`, url)
+	d, err := p(pu.DSN)
+	if err != nil {
+		return nil, openError(pu, err)
 	}
-	return a[0], a[1], nil
+	return d, nil
 }
 
 // SchemaNameFromDSN parses the dsn the returns schema name
 func SchemaNameFromDSN(url string) (string, error) {
-	key, dsn, err := parseDSN(url)
+	u, err := ParseURL(url)
 	if err != nil {
 		return "", err
 	}
-	switch key {
+	switch u.Driver {
 	case "mysql", "mariadb":
-		cfg, err := mysql.ParseDSN(dsn)
+		cfg, err := mysql.ParseDSN(u.DSN)
 		if err != nil {
-			return "", err
+			return "", u.Redact(err)
 		}
 		return cfg.DBName, err
 	case "postgres":
-		return postgresSchema(dsn)
+		s, err := postgresSchema(u.DSN)
+		return s, u.Redact(err)
 	case "sqlite":
-		return schemaName(dsn)
+		return schemaName(u.DSN)
 	default:
-		return "", fmt.Errorf("unknown database type: %q", key)
+		return "", fmt.Errorf("unknown database type: %q", u.Driver)
 	}
 }
 
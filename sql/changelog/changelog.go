@@ -0,0 +1,463 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package changelog renders schema changes as a human-readable changelog that
+// is grouped by the schemas and tables they modify. For example:
+//
+//	### public.users
+//
+//	- added column `users.age` (int, not null, default 0)
+//	- dropped index `users.name`
+//
+// The output is meant for humans (e.g. PR descriptions and release notes),
+// and it should not be parsed by programs, as its exact format may change.
+package changelog
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Format of the rendered changelog.
+type Format uint8
+
+// List of changelog formats.
+const (
+	// Text is the default format. Groups are written as "<name>:"
+	// lines, followed by their changes as an indented list.
+	Text Format = iota
+
+	// Markdown writes groups as headings, followed by their changes
+	// as a bullet list. Identifiers are formatted as inline code.
+	Markdown
+)
+
+// Renderer renders schema changes as a changelog.
+type Renderer struct {
+	// Format of the output. The default is Text.
+	Format Format
+
+	// FormatType is an optional function for formatting column types (e.g. mysql.FormatType).
+	// If it is nil or returns an error, the type is formatted from its raw or its type name.
+	FormatType func(schema.Type) (string, error)
+}
+
+type (
+	// group of entries that describe the changes of a schema or a table.
+	group struct {
+		name    string
+		entries []*entry
+	}
+	// entry describes one change, and optionally its sub-changes.
+	entry struct {
+		text string
+		sub  []string
+	}
+)
+
+// Render writes the changelog of the given changes to w.
+func (r *Renderer) Render(w io.Writer, changes []schema.Change) error {
+	var (
+		groups []*group
+		byName = make(map[string]*group)
+	)
+	add := func(name string, e *entry) {
+		g, ok := byName[name]
+		if !ok {
+			g = &group{name: name}
+			byName[name] = g
+			groups = append(groups, g)
+		}
+		g.entries = append(g.entries, e)
+	}
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			add(c.S.Name, &entry{text: "added schema " + r.ident(c.S.Name)})
+		case *schema.DropSchema:
+			add(c.S.Name, &entry{text: "dropped schema " + r.ident(c.S.Name)})
+		case *schema.ModifySchema:
+			for _, c1 := range c.Changes {
+				add(c.S.Name, &entry{text: r.attrChange(c1, "schema "+r.ident(c.S.Name))})
+			}
+		case *schema.AddTable:
+			e := &entry{text: "added table " + r.ident(c.T.Name)}
+			for _, col := range c.T.Columns {
+				e.sub = append(e.sub, "column "+r.ident(col.Name)+" "+r.column(col))
+			}
+			if pk := c.T.PrimaryKey; pk != nil {
+				e.sub = append(e.sub, "primary key on "+r.parts(pk))
+			}
+			for _, idx := range c.T.Indexes {
+				e.sub = append(e.sub, r.index(idx))
+			}
+			for _, fk := range c.T.ForeignKeys {
+				e.sub = append(e.sub, r.foreignKey(fk))
+			}
+			add(tableName(c.T), e)
+		case *schema.DropTable:
+			add(tableName(c.T), &entry{text: "dropped table " + r.ident(c.T.Name)})
+		case *schema.ModifyTable:
+			for _, c1 := range c.Changes {
+				add(tableName(c.T), &entry{text: r.tableChange(c.T, c1)})
+			}
+		default:
+			add("", &entry{text: unknown(c)})
+		}
+	}
+	var b strings.Builder
+	for i, g := range groups {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		r.group(&b, g)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// group writes the given group to the builder.
+func (r *Renderer) group(b *strings.Builder, g *group) {
+	name := g.name
+	if name == "" {
+		name = "other changes"
+	}
+	if r.Format == Markdown {
+		fmt.Fprintf(b, "### %s\n\n", name)
+		for _, e := range g.entries {
+			fmt.Fprintf(b, "- %s\n", e.text)
+			for _, s := range e.sub {
+				fmt.Fprintf(b, "  - %s\n", s)
+			}
+		}
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", name)
+	for _, e := range g.entries {
+		fmt.Fprintf(b, "  - %s\n", e.text)
+		for _, s := range e.sub {
+			fmt.Fprintf(b, "    - %s\n", s)
+		}
+	}
+}
+
+// tableChange describes a change of a ModifyTable.
+func (r *Renderer) tableChange(t *schema.Table, c schema.Change) string {
+	qualify := func(name string) string {
+		return r.ident(t.Name + "." + name)
+	}
+	switch c := c.(type) {
+	case *schema.AddColumn:
+		return "added column " + qualify(c.C.Name) + " " + r.column(c.C)
+	case *schema.DropColumn:
+		return "dropped column " + qualify(c.C.Name)
+	case *schema.ModifyColumn:
+		return "modified column " + qualify(c.To.Name) + ": " + r.columnChange(c)
+	case *schema.RenameColumn:
+		return "renamed column " + qualify(c.From.Name) + " to " + r.ident(c.To.Name)
+	case *schema.AddIndex:
+		return "added " + r.index(&schema.Index{Name: t.Name + "." + c.I.Name, Unique: c.I.Unique, Parts: c.I.Parts})
+	case *schema.DropIndex:
+		return "dropped index " + qualify(c.I.Name)
+	case *schema.ModifyIndex:
+		return "modified index " + qualify(c.To.Name) + ": " + r.indexChange(c)
+	case *schema.AddForeignKey:
+		return "added " + r.foreignKey(c.F)
+	case *schema.DropForeignKey:
+		return "dropped foreign key " + qualify(c.F.Symbol)
+	case *schema.ModifyForeignKey:
+		return "modified foreign key " + qualify(c.To.Symbol) + ": " + r.foreignKeyChange(c)
+	case *schema.AddCheck:
+		return fmt.Sprintf("added check %s (%s)", qualify(c.C.Name), c.C.Expr)
+	case *schema.DropCheck:
+		return "dropped check " + qualify(c.C.Name)
+	case *schema.ModifyCheck:
+		return fmt.Sprintf("modified check %s: expression from %s to %s", qualify(c.To.Name), c.From.Expr, c.To.Expr)
+	default:
+		return r.attrChange(c, "table "+r.ident(t.Name))
+	}
+}
+
+// attrChange describes an attribute change of the given element.
+func (r *Renderer) attrChange(c schema.Change, elem string) string {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		return fmt.Sprintf("added %s to %s", attr(c.A), elem)
+	case *schema.DropAttr:
+		return fmt.Sprintf("dropped %s from %s", attr(c.A), elem)
+	case *schema.ModifyAttr:
+		return fmt.Sprintf("changed %s to %s on %s", attr(c.From), attr(c.To), elem)
+	default:
+		return unknown(c) + " on " + elem
+	}
+}
+
+// column describes a column definition: "(int, not null, default 0)".
+func (r *Renderer) column(c *schema.Column) string {
+	parts := []string{r.typ(c), "not null"}
+	if c.Type != nil && c.Type.Null {
+		parts[1] = "null"
+	}
+	if c.Default != nil {
+		parts = append(parts, "default "+expr(c.Default))
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// columnChange describes the changes of a ModifyColumn.
+func (r *Renderer) columnChange(c *schema.ModifyColumn) string {
+	var parts []string
+	if c.Change.Is(schema.ChangeType) {
+		parts = append(parts, fmt.Sprintf("type from %s to %s", r.typ(c.From), r.typ(c.To)))
+	}
+	if c.Change.Is(schema.ChangeNull) {
+		if c.To.Type != nil && c.To.Type.Null {
+			parts = append(parts, "made nullable")
+		} else {
+			parts = append(parts, "made not null")
+		}
+	}
+	if c.Change.Is(schema.ChangeDefault) {
+		switch {
+		case c.From.Default == nil:
+			parts = append(parts, "set default "+expr(c.To.Default))
+		case c.To.Default == nil:
+			parts = append(parts, "dropped default "+expr(c.From.Default))
+		default:
+			parts = append(parts, fmt.Sprintf("default from %s to %s", expr(c.From.Default), expr(c.To.Default)))
+		}
+	}
+	for _, k := range []struct {
+		kind schema.ChangeKind
+		text string
+	}{
+		{schema.ChangeCharset, "changed charset"},
+		{schema.ChangeCollation, "changed collation"},
+		{schema.ChangeComment, "changed comment"},
+		{schema.ChangePosition, "changed position"},
+		{schema.ChangeAttr, "changed attributes"},
+	} {
+		if c.Change.Is(k.kind) {
+			parts = append(parts, k.text)
+		}
+	}
+	return changed(parts)
+}
+
+// index describes an index definition: "unique index `name` on (a, b)".
+func (r *Renderer) index(idx *schema.Index) string {
+	kind := "index "
+	if idx.Unique {
+		kind = "unique index "
+	}
+	return kind + r.ident(idx.Name) + " on " + r.parts(idx)
+}
+
+// parts describes the parts of an index: "(a, b)".
+func (r *Renderer) parts(idx *schema.Index) string {
+	parts := make([]string, 0, len(idx.Parts))
+	for _, p := range idx.Parts {
+		switch {
+		case p.C != nil:
+			parts = append(parts, p.C.Name)
+		case p.X != nil:
+			parts = append(parts, expr(p.X))
+		}
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// indexChange describes the changes of a ModifyIndex.
+func (r *Renderer) indexChange(c *schema.ModifyIndex) string {
+	var parts []string
+	if c.Change.Is(schema.ChangeUnique) {
+		if c.To.Unique {
+			parts = append(parts, "made unique")
+		} else {
+			parts = append(parts, "made non-unique")
+		}
+	}
+	if c.Change.Is(schema.ChangeParts) {
+		parts = append(parts, fmt.Sprintf("parts from %s to %s", r.parts(c.From), r.parts(c.To)))
+	}
+	if c.Change.Is(schema.ChangeComment) {
+		parts = append(parts, "changed comment")
+	}
+	if c.Change.Is(schema.ChangeAttr) {
+		parts = append(parts, "changed attributes")
+	}
+	return changed(parts)
+}
+
+// foreignKey describes a foreign-key definition.
+func (r *Renderer) foreignKey(fk *schema.ForeignKey) string {
+	var b strings.Builder
+	name := fk.Symbol
+	if fk.Table != nil {
+		name = fk.Table.Name + "." + name
+	}
+	fmt.Fprintf(&b, "foreign key %s (%s)", r.ident(name), columns(fk.Columns))
+	if fk.RefTable != nil {
+		fmt.Fprintf(&b, " references %s (%s)", r.ident(fk.RefTable.Name), columns(fk.RefColumns))
+	}
+	if fk.OnUpdate != "" {
+		fmt.Fprintf(&b, " on update %s", fk.OnUpdate)
+	}
+	if fk.OnDelete != "" {
+		fmt.Fprintf(&b, " on delete %s", fk.OnDelete)
+	}
+	if fk.Match != "" {
+		fmt.Fprintf(&b, " match %s", fk.Match)
+	}
+	return b.String()
+}
+
+// foreignKeyChange describes the changes of a ModifyForeignKey.
+func (r *Renderer) foreignKeyChange(c *schema.ModifyForeignKey) string {
+	var parts []string
+	if c.Change.Is(schema.ChangeColumn) {
+		parts = append(parts, fmt.Sprintf("columns from (%s) to (%s)", columns(c.From.Columns), columns(c.To.Columns)))
+	}
+	if c.Change.Is(schema.ChangeRefTable) && c.From.RefTable != nil && c.To.RefTable != nil {
+		parts = append(parts, fmt.Sprintf("referenced table from %s to %s", r.ident(c.From.RefTable.Name), r.ident(c.To.RefTable.Name)))
+	}
+	if c.Change.Is(schema.ChangeRefColumn) {
+		parts = append(parts, fmt.Sprintf("referenced columns from (%s) to (%s)", columns(c.From.RefColumns), columns(c.To.RefColumns)))
+	}
+	if c.Change.Is(schema.ChangeUpdateAction) {
+		parts = append(parts, fmt.Sprintf("on update from %s to %s", action(c.From.OnUpdate), action(c.To.OnUpdate)))
+	}
+	if c.Change.Is(schema.ChangeDeleteAction) {
+		parts = append(parts, fmt.Sprintf("on delete from %s to %s", action(c.From.OnDelete), action(c.To.OnDelete)))
+	}
+	if c.Change.Is(schema.ChangeType) {
+		parts = append(parts, "columns retyped")
+	}
+	if c.Change.Is(schema.ChangeMatch) {
+		parts = append(parts, fmt.Sprintf("match from %s to %s", match(c.From.Match), match(c.To.Match)))
+	}
+	return changed(parts)
+}
+
+// typ returns the string representation of the column type.
+func (r *Renderer) typ(c *schema.Column) string {
+	if c.Type == nil {
+		return "unknown type"
+	}
+	if r.FormatType != nil && c.Type.Type != nil {
+		if s, err := r.FormatType(c.Type.Type); err == nil {
+			return s
+		}
+	}
+	if c.Type.Raw != "" {
+		return c.Type.Raw
+	}
+	if c.Type.Type != nil {
+		// All builtin types hold their name in the T field.
+		v := reflect.Indirect(reflect.ValueOf(c.Type.Type))
+		if v.Kind() == reflect.Struct {
+			if f := v.FieldByName("T"); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+				return f.String()
+			}
+		}
+	}
+	return "unknown type"
+}
+
+// ident formats the given identifier.
+func (r *Renderer) ident(s string) string {
+	if r.Format == Markdown {
+		return "`" + s + "`"
+	}
+	return s
+}
+
+// tableName returns the group name of the given table.
+func tableName(t *schema.Table) string {
+	if t.Schema != nil && t.Schema.Name != "" {
+		return t.Schema.Name + "." + t.Name
+	}
+	return t.Name
+}
+
+// attr describes a schema attribute.
+func attr(a schema.Attr) string {
+	switch a := a.(type) {
+	case *schema.Comment:
+		return fmt.Sprintf("comment %q", a.Text)
+	case *schema.Charset:
+		return "charset " + a.V
+	case *schema.Collation:
+		return "collation " + a.V
+	case *schema.Check:
+		return fmt.Sprintf("check %s (%s)", a.Name, a.Expr)
+	default:
+		return "attribute " + typeName(a)
+	}
+}
+
+// expr returns the string representation of the given expression.
+func expr(x schema.Expr) string {
+	switch x := x.(type) {
+	case *schema.Literal:
+		return x.V
+	case *schema.RawExpr:
+		return x.X
+	default:
+		return typeName(x)
+	}
+}
+
+// columns joins the names of the given columns.
+func columns(cs []*schema.Column) string {
+	names := make([]string, len(cs))
+	for i, c := range cs {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// action returns the string representation of a reference option.
+func action(o schema.ReferenceOption) string {
+	if o == "" {
+		return "default"
+	}
+	return string(o)
+}
+
+func match(m schema.ReferenceMatch) string {
+	if m == "" {
+		return "default"
+	}
+	return string(m)
+}
+
+// changed joins the given parts, or returns a generic description if there are none.
+func changed(parts []string) string {
+	if len(parts) == 0 {
+		return "changed"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// unknown describes a change that is not supported by the renderer.
+func unknown(c schema.Change) string {
+	return "change " + typeName(c)
+}
+
+// typeName returns the name of the underlying type of v without its package.
+func typeName(v interface{}) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "<nil>"
+	}
+	return t.Name()
+}
@@ -0,0 +1,138 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package changelog_test
+
+import (
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/changelog"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	var (
+		id    = schema.NewIntColumn("id", "bigint")
+		name  = schema.NewStringColumn("name", "varchar", schema.StringSize(255))
+		age   = schema.NewIntColumn("age", "int").SetDefault(&schema.Literal{V: "0"})
+		owner = schema.NewNullIntColumn("owner_id", "bigint")
+		pets  = schema.NewTable("pets").AddColumns(id, owner)
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint"))
+		s     = schema.New("public").AddTables(users, pets)
+	)
+	pets.SetPrimaryKey(schema.NewPrimaryKey(id))
+	pets.AddForeignKeys(schema.NewForeignKey("owner").AddColumns(owner).SetRefTable(users).AddRefColumns(users.Columns[0]).SetOnDelete(schema.Cascade))
+	changes := []schema.Change{
+		&schema.AddSchema{S: s},
+		&schema.ModifySchema{S: s, Changes: []schema.Change{
+			&schema.ModifyAttr{From: &schema.Charset{V: "latin1"}, To: &schema.Charset{V: "utf8mb4"}},
+		}},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.AddColumn{C: age},
+			&schema.ModifyColumn{
+				From:   schema.NewNullStringColumn("name", "varchar", schema.StringSize(100)),
+				To:     name,
+				Change: schema.ChangeType | schema.ChangeNull,
+			},
+			&schema.DropColumn{C: schema.NewIntColumn("deleted", "bool")},
+			&schema.RenameColumn{From: schema.NewIntColumn("nick", "int"), To: schema.NewIntColumn("alias", "int")},
+			&schema.AddIndex{I: schema.NewUniqueIndex("users_name").AddColumns(name)},
+			&schema.DropIndex{I: schema.NewIndex("users_age")},
+			&schema.AddCheck{C: schema.NewCheck().SetName("positive_age").SetExpr("age > 0")},
+			&schema.AddAttr{A: &schema.Comment{Text: "users table"}},
+		}},
+		&schema.AddTable{T: pets},
+		&schema.DropTable{T: schema.NewTable("groups").SetSchema(s)},
+	}
+	var b strings.Builder
+	r := &changelog.Renderer{}
+	require.NoError(t, r.Render(&b, changes))
+	require.Equal(t, `public:
+  - added schema public
+  - changed charset latin1 to charset utf8mb4 on schema public
+
+public.users:
+  - added column users.age (int, not null, default 0)
+  - modified column users.name: type from varchar to varchar, made not null
+  - dropped column users.deleted
+  - renamed column users.nick to alias
+  - added unique index users.users_name on (name)
+  - dropped index users.users_age
+  - added check users.positive_age (age > 0)
+  - added comment "users table" to table users
+
+public.pets:
+  - added table pets
+    - column id (bigint, not null)
+    - column owner_id (bigint, null)
+    - primary key on (id)
+    - foreign key pets.owner (owner_id) references users (id) on delete CASCADE
+
+public.groups:
+  - dropped table groups
+`, b.String())
+
+	b.Reset()
+	r = &changelog.Renderer{Format: changelog.Markdown, FormatType: mysql.FormatType}
+	require.NoError(t, r.Render(&b, changes[2:4]))
+	require.Equal(t, "### public.users\n\n"+
+		"- added column `users.age` (int, not null, default 0)\n"+
+		"- modified column `users.name`: type from varchar(100) to varchar(255), made not null\n"+
+		"- dropped column `users.deleted`\n"+
+		"- renamed column `users.nick` to `alias`\n"+
+		"- added unique index `users.users_name` on (name)\n"+
+		"- dropped index `users.users_age`\n"+
+		"- added check `users.positive_age` (age > 0)\n"+
+		"- added comment \"users table\" to table `users`\n"+
+		"\n"+
+		"### public.pets\n\n"+
+		"- added table `pets`\n"+
+		"  - column `id` (bigint, not null)\n"+
+		"  - column `owner_id` (bigint, null)\n"+
+		"  - primary key on (id)\n"+
+		"  - foreign key `pets.owner` (owner_id) references `users` (id) on delete CASCADE\n", b.String())
+}
+
+func TestRenderer_Modify(t *testing.T) {
+	var (
+		users = schema.NewTable("users")
+		a, b  = schema.NewIntColumn("a", "int"), schema.NewIntColumn("b", "int")
+	)
+	changes := []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.ModifyColumn{
+				From:   schema.NewIntColumn("a", "int").SetDefault(&schema.Literal{V: "1"}),
+				To:     schema.NewNullIntColumn("a", "int").SetDefault(&schema.RawExpr{X: "(1 + 1)"}),
+				Change: schema.ChangeNull | schema.ChangeDefault | schema.ChangeComment,
+			},
+			&schema.ModifyColumn{From: a, To: a},
+			&schema.ModifyIndex{
+				From:   schema.NewUniqueIndex("idx").AddColumns(a),
+				To:     schema.NewIndex("idx").AddColumns(a, b),
+				Change: schema.ChangeUnique | schema.ChangeParts,
+			},
+			&schema.ModifyForeignKey{
+				From:   schema.NewForeignKey("fk").AddColumns(a),
+				To:     schema.NewForeignKey("fk").AddColumns(b).SetOnUpdate(schema.Cascade),
+				Change: schema.ChangeColumn | schema.ChangeUpdateAction,
+			},
+			&schema.ModifyCheck{From: &schema.Check{Name: "c", Expr: "a > 0"}, To: &schema.Check{Name: "c", Expr: "a > 1"}},
+			&schema.DropAttr{A: &mysql.AutoIncrement{V: 10}},
+		}},
+	}
+	var s strings.Builder
+	require.NoError(t, (&changelog.Renderer{}).Render(&s, changes))
+	require.Equal(t, `users:
+  - modified column users.a: made nullable, default from 1 to (1 + 1), changed comment
+  - modified column users.a: changed
+  - modified index users.idx: made non-unique, parts from (a) to (a, b)
+  - modified foreign key users.fk: columns from (a) to (b), on update from default to CASCADE
+  - modified check users.c: expression from a > 0 to a > 1
+  - dropped attribute AutoIncrement from table users
+`, s.String())
+}
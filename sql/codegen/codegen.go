@@ -0,0 +1,315 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package codegen generates code and documents, such as Go structs and JSON Schema
+// documents, from inspected database schemas.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/go-openapi/inflect"
+)
+
+type (
+	// Generator generates Go structs from schema tables.
+	Generator struct {
+		pkg      string
+		tag      string
+		nullable Nullable
+		structN  func(*schema.Table) string
+		fieldN   func(*schema.Column) string
+		typeF    func(*schema.Column) (GoType, bool)
+	}
+
+	// Option allows configuring the Generator using functional options.
+	Option func(*Generator)
+
+	// Nullable describes how nullable columns are represented in the generated code.
+	Nullable uint
+
+	// GoType describes a Go type that is used for a struct field.
+	GoType struct {
+		// Name is the type expression as it appears in the source code. e.g. "time.Time".
+		Name string
+		// Import is the package path that needs to be imported in
+		// order to use the type. Empty for builtin types.
+		Import string
+	}
+)
+
+// List of nullable representations.
+const (
+	// NullPointer represents nullable columns as pointers to their Go type (e.g. *string).
+	NullPointer Nullable = iota
+	// NullSQL represents nullable columns using the sql.Null* types (e.g. sql.NullString).
+	// Types without a matching sql.Null* type fall back to pointers.
+	NullSQL
+)
+
+// New returns a new Generator configured with the given options.
+func New(opts ...Option) *Generator {
+	g := &Generator{
+		pkg:     "models",
+		tag:     "db",
+		structN: StructName,
+		fieldN:  FieldName,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// WithPackage sets the package name of the generated file. Defaults to "models".
+func WithPackage(name string) Option {
+	return func(g *Generator) {
+		g.pkg = name
+	}
+}
+
+// WithTag sets the struct tag key used for mapping fields to columns. Defaults to "db".
+func WithTag(key string) Option {
+	return func(g *Generator) {
+		g.tag = key
+	}
+}
+
+// WithNullable sets the representation of nullable columns. Defaults to NullPointer.
+func WithNullable(n Nullable) Option {
+	return func(g *Generator) {
+		g.nullable = n
+	}
+}
+
+// WithStructName sets the function that is used for naming the generated structs.
+func WithStructName(f func(*schema.Table) string) Option {
+	return func(g *Generator) {
+		g.structN = f
+	}
+}
+
+// WithFieldName sets the function that is used for naming the generated struct fields.
+func WithFieldName(f func(*schema.Column) string) Option {
+	return func(g *Generator) {
+		g.fieldN = f
+	}
+}
+
+// WithType allows overriding the Go type of columns. The function reports false
+// in case it does not handle the column type, and the default mapping should be used.
+func WithType(f func(*schema.Column) (GoType, bool)) Option {
+	return func(g *Generator) {
+		g.typeF = f
+	}
+}
+
+// StructName is the default naming function for structs. It returns
+// the singular and camel-cased form of the table name.
+func StructName(t *schema.Table) string {
+	return inflect.Camelize(inflect.Singularize(t.Name))
+}
+
+// FieldName is the default naming function for struct fields. It returns
+// the camel-cased form of the column name, with common initialisms in
+// upper case, as recommended by the Go code review comments.
+func FieldName(c *schema.Column) string {
+	words := strings.FieldsFunc(c.Name, func(r rune) bool {
+		return r == '_' || r == '-' || r == ' ' || r == '.'
+	})
+	var b strings.Builder
+	for _, w := range words {
+		if u := strings.ToUpper(w); initialisms[u] {
+			b.WriteString(u)
+		} else {
+			b.WriteString(inflect.Capitalize(w))
+		}
+	}
+	name := b.String()
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "C" + name
+	}
+	return name
+}
+
+// Generate generates the Go structs for all tables in the given schema and writes
+// the formatted source code to w. Tables are emitted ordered by their names. An error
+// is returned if two tables map to the same struct name (e.g. "user_roles" and "UserRoles").
+func (g *Generator) Generate(w io.Writer, s *schema.Schema) error {
+	tables := make([]*schema.Table, len(s.Tables))
+	copy(tables, s.Tables)
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	var (
+		b       bytes.Buffer
+		imports = make(map[string]struct{})
+		structs = make(map[string]string, len(tables))
+	)
+	for _, t := range tables {
+		name := g.structN(t)
+		if prev, ok := structs[name]; ok {
+			return fmt.Errorf("codegen: tables %q and %q map to the same struct name %q", prev, t.Name, name)
+		}
+		structs[name] = t.Name
+		if err := g.table(&b, t, name, imports); err != nil {
+			return err
+		}
+	}
+	src := g.header(s, imports) + b.String()
+	out, err := format.Source([]byte(src))
+	if err != nil {
+		return fmt.Errorf("codegen: formatting generated source: %w", err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// header returns the file header, including the package clause and imports.
+func (g *Generator) header(s *schema.Schema, imports map[string]struct{}) string {
+	var b strings.Builder
+	b.WriteString("// Code generated by atlas. DO NOT EDIT.\n\n")
+	if s.Name != "" {
+		fmt.Fprintf(&b, "// Package %s holds the models of the %q schema.\n", g.pkg, s.Name)
+	}
+	fmt.Fprintf(&b, "package %s\n\n", g.pkg)
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for p := range imports {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		b.WriteString("import (\n")
+		for _, p := range paths {
+			fmt.Fprintf(&b, "\t%q\n", p)
+		}
+		b.WriteString(")\n\n")
+	}
+	return b.String()
+}
+
+// table writes the struct definition of the given table.
+func (g *Generator) table(b *bytes.Buffer, t *schema.Table, name string, imports map[string]struct{}) error {
+	fmt.Fprintf(b, "// %s represents a row in the %q table.\n", name, t.Name)
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	fields := make(map[string]string, len(t.Columns))
+	for _, c := range t.Columns {
+		f := g.fieldN(c)
+		if prev, ok := fields[f]; ok {
+			return fmt.Errorf("codegen: columns %q and %q of table %q map to the same field name %q", prev, c.Name, t.Name, f)
+		}
+		fields[f] = c.Name
+		typ, err := g.goType(c)
+		if err != nil {
+			return fmt.Errorf("codegen: table %q: %w", t.Name, err)
+		}
+		if typ.Import != "" {
+			imports[typ.Import] = struct{}{}
+		}
+		fmt.Fprintf(b, "\t%s %s `%s:%q`\n", f, typ.Name, g.tag, c.Name)
+	}
+	b.WriteString("}\n\n")
+	return nil
+}
+
+// goType returns the Go type of the given column.
+func (g *Generator) goType(c *schema.Column) (GoType, error) {
+	if g.typeF != nil {
+		if t, ok := g.typeF(c); ok {
+			return t, nil
+		}
+	}
+	if c.Type == nil || c.Type.Type == nil {
+		return GoType{}, fmt.Errorf("missing type for column %q", c.Name)
+	}
+	t := goType(c.Type.Type)
+	if !c.Type.Null || strings.HasPrefix(t.Name, "[]") || t.Name == "interface{}" || t.Name == "json.RawMessage" {
+		return t, nil
+	}
+	if g.nullable == NullSQL {
+		if n, ok := nullTypes[t.Name]; ok {
+			return GoType{Name: n, Import: "database/sql"}, nil
+		}
+	}
+	return GoType{Name: "*" + t.Name, Import: t.Import}, nil
+}
+
+// goType returns the default Go type for the given schema type.
+func goType(t schema.Type) GoType {
+	switch t := t.(type) {
+	case *schema.BoolType:
+		return GoType{Name: "bool"}
+	case *schema.IntegerType:
+		return intType(t)
+	case *schema.FloatType:
+		if t.T == "float" && t.Precision > 0 && t.Precision <= 24 || t.T == "real" || t.T == "float4" {
+			return GoType{Name: "float32"}
+		}
+		return GoType{Name: "float64"}
+	case *schema.DecimalType:
+		// Decimal values are kept as strings to avoid losing precision.
+		return GoType{Name: "string"}
+	case *schema.StringType, *schema.EnumType:
+		return GoType{Name: "string"}
+	case *schema.BinaryType, *schema.SpatialType:
+		return GoType{Name: "[]byte"}
+	case *schema.JSONType:
+		return GoType{Name: "json.RawMessage", Import: "encoding/json"}
+	case *schema.TimeType:
+		return GoType{Name: "time.Time", Import: "time"}
+	default:
+		return GoType{Name: "interface{}"}
+	}
+}
+
+// intType returns the Go type for the given integer type.
+func intType(t *schema.IntegerType) GoType {
+	var name string
+	switch strings.ToLower(t.T) {
+	case "tinyint", "int1":
+		name = "int8"
+	case "smallint", "int2", "smallserial":
+		name = "int16"
+	case "mediumint", "int", "integer", "int4", "serial":
+		name = "int32"
+	default:
+		name = "int64"
+	}
+	if t.Unsigned {
+		name = "u" + name
+	}
+	return GoType{Name: name}
+}
+
+var (
+	// nullTypes maps Go types to their database/sql nullable representation.
+	nullTypes = map[string]string{
+		"bool":      "sql.NullBool",
+		"int8":      "sql.NullInt16",
+		"int16":     "sql.NullInt16",
+		"uint8":     "sql.NullInt16",
+		"int32":     "sql.NullInt32",
+		"uint16":    "sql.NullInt32",
+		"int64":     "sql.NullInt64",
+		"uint32":    "sql.NullInt64",
+		"float32":   "sql.NullFloat64",
+		"float64":   "sql.NullFloat64",
+		"string":    "sql.NullString",
+		"time.Time": "sql.NullTime",
+	}
+	// initialisms is a set of common initialisms taken from golint.
+	initialisms = map[string]bool{
+		"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+		"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+		"IP": true, "JSON": true, "LHS": true, "QPS": true, "RAM": true, "RHS": true,
+		"RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true, "TCP": true,
+		"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true,
+		"URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true, "XMPP": true,
+	}
+)
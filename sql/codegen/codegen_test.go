@@ -0,0 +1,131 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package codegen_test
+
+import (
+	"bytes"
+	"testing"
+
+	"ariga.io/atlas/sql/codegen"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSchema() *schema.Schema {
+	return schema.New("app").
+		AddTables(
+			schema.NewTable("users").
+				AddColumns(
+					schema.NewIntColumn("id", "bigint"),
+					schema.NewStringColumn("user_name", "varchar", schema.StringSize(255)),
+					schema.NewNullStringColumn("email", "varchar"),
+					schema.NewNullTimeColumn("created_at", "timestamp"),
+					schema.NewNullJSONColumn("profile_json", "json"),
+				),
+			schema.NewTable("pets").
+				AddColumns(
+					schema.NewIntColumn("id", "int"),
+					schema.NewUintColumn("age", "tinyint"),
+					schema.NewNullBoolColumn("is_good", "bool"),
+				),
+		)
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	var b bytes.Buffer
+	err := codegen.New().Generate(&b, testSchema())
+	require.NoError(t, err)
+	require.Equal(t, `// Code generated by atlas. DO NOT EDIT.
+
+// Package models holds the models of the "app" schema.
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Pet represents a row in the "pets" table.
+type Pet struct {
+	ID     int32 `+"`db:\"id\"`"+`
+	Age    uint8 `+"`db:\"age\"`"+`
+	IsGood *bool `+"`db:\"is_good\"`"+`
+}
+
+// User represents a row in the "users" table.
+type User struct {
+	ID          int64           `+"`db:\"id\"`"+`
+	UserName    string          `+"`db:\"user_name\"`"+`
+	Email       *string         `+"`db:\"email\"`"+`
+	CreatedAt   *time.Time      `+"`db:\"created_at\"`"+`
+	ProfileJSON json.RawMessage `+"`db:\"profile_json\"`"+`
+}
+`, b.String())
+}
+
+func TestGenerator_GenerateOptions(t *testing.T) {
+	var b bytes.Buffer
+	err := codegen.New(
+		codegen.WithPackage("db"),
+		codegen.WithTag("sql"),
+		codegen.WithNullable(codegen.NullSQL),
+		codegen.WithStructName(func(t *schema.Table) string { return "T" + codegen.StructName(t) }),
+		codegen.WithType(func(c *schema.Column) (codegen.GoType, bool) {
+			if c.Name == "id" {
+				return codegen.GoType{Name: "uuid.UUID", Import: "github.com/google/uuid"}, true
+			}
+			return codegen.GoType{}, false
+		}),
+	).Generate(&b, schema.New("").AddTables(testSchema().Tables[1]))
+	require.NoError(t, err)
+	require.Equal(t, `// Code generated by atlas. DO NOT EDIT.
+
+package db
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+)
+
+// TPet represents a row in the "pets" table.
+type TPet struct {
+	ID     uuid.UUID    `+"`sql:\"id\"`"+`
+	Age    uint8        `+"`sql:\"age\"`"+`
+	IsGood sql.NullBool `+"`sql:\"is_good\"`"+`
+}
+`, b.String())
+}
+
+func TestGenerator_GenerateConflict(t *testing.T) {
+	s := schema.New("").AddTables(
+		schema.NewTable("t").AddColumns(
+			schema.NewIntColumn("user_id", "int"),
+			schema.NewIntColumn("user id", "int"),
+		),
+	)
+	err := codegen.New().Generate(&bytes.Buffer{}, s)
+	require.EqualError(t, err, `codegen: columns "user_id" and "user id" of table "t" map to the same field name "UserID"`)
+
+	s = schema.New("").AddTables(
+		schema.NewTable("user_roles").AddColumns(schema.NewIntColumn("id", "int")),
+		schema.NewTable("UserRoles").AddColumns(schema.NewIntColumn("id", "int")),
+	)
+	err = codegen.New().Generate(&bytes.Buffer{}, s)
+	require.EqualError(t, err, `codegen: tables "UserRoles" and "user_roles" map to the same struct name "UserRole"`)
+}
+
+func TestFieldName(t *testing.T) {
+	for n, want := range map[string]string{
+		"id":        "ID",
+		"user_id":   "UserID",
+		"api_url":   "APIURL",
+		"createdAt": "CreatedAt",
+		"1st":       "C1st",
+		"a-b c":     "ABC",
+	} {
+		require.Equal(t, want, codegen.FieldName(schema.NewColumn(n)))
+	}
+}
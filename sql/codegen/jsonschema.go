@@ -0,0 +1,198 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package codegen
+
+import (
+	"math"
+	"reflect"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect used by the generated documents.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema describes a JSON Schema document. Only the keywords that
+// are required for describing table rows are supported.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+}
+
+// TableJSONSchema returns a JSON Schema document that describes valid row objects
+// of the given table. Properties are named after the table columns. A column is
+// required if it is not nullable, has no default value, and its value is not
+// generated by the database (e.g. AUTO_INCREMENT, identity or serial columns).
+func TableJSONSchema(t *schema.Table) *JSONSchema {
+	additional := false
+	s := &JSONSchema{
+		Schema:               JSONSchemaDraft,
+		Title:                t.Name,
+		Description:          comment(t.Attrs),
+		Type:                 "object",
+		Properties:           make(map[string]*JSONSchema, len(t.Columns)),
+		AdditionalProperties: &additional,
+	}
+	for _, c := range t.Columns {
+		s.Properties[c.Name] = columnJSONSchema(c)
+		if c.Type != nil && !c.Type.Null && c.Default == nil && !generated(c) {
+			s.Required = append(s.Required, c.Name)
+		}
+	}
+	return s
+}
+
+// generated reports if the value of the given column is generated by the database on
+// insert. Since this package does not depend on the drivers, their attributes and types
+// are matched by name. For example, mysql.AutoIncrement, postgres.Identity and postgres.SerialType.
+func generated(c *schema.Column) bool {
+	for _, a := range c.Attrs {
+		switch typeName(a) {
+		case "AutoIncrement", "Identity":
+			return true
+		}
+	}
+	if t, ok := c.Type.Type.(*schema.IntegerType); ok {
+		switch strings.ToLower(t.T) {
+		case "smallserial", "serial", "bigserial", "serial2", "serial4", "serial8":
+			return true
+		}
+	}
+	return typeName(c.Type.Type) == "SerialType"
+}
+
+// typeName returns the name of the underlying type of v.
+func typeName(v interface{}) string {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return ""
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// columnJSONSchema returns the JSON Schema of the given column value.
+func columnJSONSchema(c *schema.Column) *JSONSchema {
+	s := &JSONSchema{Description: comment(c.Attrs)}
+	if c.Type == nil {
+		return s
+	}
+	switch t := c.Type.Type.(type) {
+	case *schema.BoolType:
+		s.Type = "boolean"
+	case *schema.IntegerType:
+		s.Type = "integer"
+		s.Minimum, s.Maximum = intRange(t)
+	case *schema.FloatType:
+		s.Type = "number"
+		if t.Unsigned {
+			s.Minimum = new(float64)
+		}
+	case *schema.DecimalType:
+		s.Type = "number"
+		if t.Unsigned {
+			s.Minimum = new(float64)
+		}
+		if t.Precision > 0 && t.Precision-t.Scale < 308 {
+			max := math.Pow10(t.Precision-t.Scale) - math.Pow10(-t.Scale)
+			s.Maximum = &max
+			if !t.Unsigned {
+				min := -max
+				s.Minimum = &min
+			}
+		}
+	case *schema.StringType:
+		s.Type = "string"
+		if t.Size > 0 {
+			size := t.Size
+			s.MaxLength = &size
+		}
+	case *schema.EnumType:
+		s.Type = "string"
+		for _, v := range t.Values {
+			s.Enum = append(s.Enum, v)
+		}
+		if c.Type.Null {
+			s.Enum = append(s.Enum, nil)
+		}
+	case *schema.BinaryType:
+		s.Type, s.ContentEncoding = "string", "base64"
+	case *schema.TimeType:
+		s.Type, s.Format = "string", timeFormat(t.T)
+	case *schema.JSONType, *schema.SpatialType, *schema.UnsupportedType:
+		// Any JSON value is accepted.
+		return s
+	default:
+		// Driver-specific types are described as strings,
+		// as this is how most drivers scan unknown types.
+		s.Type = "string"
+	}
+	if c.Type.Null {
+		s.Type = []string{s.Type.(string), "null"}
+	}
+	return s
+}
+
+// intRange returns the range of values that can be stored in the given integer type.
+func intRange(t *schema.IntegerType) (*float64, *float64) {
+	var bits int
+	switch strings.ToLower(t.T) {
+	case "tinyint", "int1":
+		bits = 8
+	case "smallint", "int2", "smallserial", "serial2":
+		bits = 16
+	case "mediumint":
+		bits = 24
+	case "int", "integer", "int4", "serial", "serial4":
+		bits = 32
+	default:
+		return nil, nil
+	}
+	var min, max float64
+	if t.Unsigned {
+		max = math.Pow(2, float64(bits)) - 1
+	} else {
+		min, max = -math.Pow(2, float64(bits-1)), math.Pow(2, float64(bits-1))-1
+	}
+	return &min, &max
+}
+
+// timeFormat returns the JSON Schema format of the given time type.
+func timeFormat(t string) string {
+	switch t = strings.ToLower(t); {
+	case t == "date":
+		return "date"
+	case strings.HasPrefix(t, "time") && !strings.HasPrefix(t, "timestamp"):
+		return "time"
+	case t == "year", t == "interval":
+		return ""
+	default:
+		return "date-time"
+	}
+}
+
+// comment returns the comment text from the given attributes, if exists.
+func comment(attrs []schema.Attr) string {
+	for _, a := range attrs {
+		if c, ok := a.(*schema.Comment); ok {
+			return c.Text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package codegen_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"ariga.io/atlas/sql/codegen"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableJSONSchema(t *testing.T) {
+	tbl := schema.NewTable("users").
+		SetComment("users of the app").
+		AddColumns(
+			schema.NewIntColumn("id", "bigint"),
+			schema.NewUintColumn("age", "tinyint"),
+			schema.NewStringColumn("name", "varchar", schema.StringSize(255)).SetComment("full name"),
+			schema.NewNullEnumColumn("state", schema.EnumValues("on", "off")),
+			schema.NewDecimalColumn("balance", "decimal", schema.DecimalPrecision(4), schema.DecimalScale(2)),
+			schema.NewBoolColumn("active", "bool").SetDefault(&schema.Literal{V: "true"}),
+			schema.NewNullTimeColumn("created_at", "timestamp"),
+			schema.NewBinaryColumn("data", "blob"),
+			schema.NewJSONColumn("extra", "json"),
+		)
+	b, err := json.MarshalIndent(codegen.TableJSONSchema(tbl), "", "  ")
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "users",
+  "description": "users of the app",
+  "type": "object",
+  "properties": {
+    "id": {"type": "integer"},
+    "age": {"type": "integer", "minimum": 0, "maximum": 255},
+    "name": {"description": "full name", "type": "string", "maxLength": 255},
+    "state": {"type": ["string", "null"], "enum": ["on", "off", null]},
+    "balance": {"type": "number", "minimum": -99.99, "maximum": 99.99},
+    "active": {"type": "boolean"},
+    "created_at": {"type": ["string", "null"], "format": "date-time"},
+    "data": {"type": "string", "contentEncoding": "base64"},
+    "extra": {}
+  },
+  "required": ["id", "age", "name", "balance", "data", "extra"],
+  "additionalProperties": false
+}`, string(b))
+}
+
+func TestTableJSONSchema_Generated(t *testing.T) {
+	tbl := schema.NewTable("t").
+		AddColumns(
+			schema.NewIntColumn("a", "int").AddAttrs(&mysql.AutoIncrement{}),
+			schema.NewIntColumn("b", "integer").AddAttrs(&sqlite.AutoIncrement{}),
+			schema.NewIntColumn("c", "bigint").AddAttrs(&postgres.Identity{Generation: "ALWAYS"}),
+			schema.NewColumn("d").SetType(&postgres.SerialType{T: "bigserial"}),
+			schema.NewIntColumn("e", "serial"),
+			schema.NewIntColumn("f", "int"),
+		)
+	require.Equal(t, []string{"f"}, codegen.TableJSONSchema(tbl).Required)
+}
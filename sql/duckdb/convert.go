@@ -0,0 +1,221 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// FormatType converts schema type to its column form in the database.
+// An error is returned if the type cannot be recognized.
+//
+// Character types are formatted as VARCHAR, as DuckDB does not enforce (or keep)
+// their length, and enum types are formatted inline (e.g. ENUM('a', 'b')).
+func FormatType(t schema.Type) (string, error) {
+	var f string
+	switch t := t.(type) {
+	case *schema.BoolType:
+		f = strings.ToUpper(TypeBoolean)
+	case *schema.BinaryType:
+		f = strings.ToUpper(TypeBlob)
+	case *schema.DecimalType:
+		p, s := t.Precision, t.Scale
+		if p == 0 && s == 0 {
+			p, s = defaultPrecision, defaultScale
+		}
+		if p == 0 {
+			p = defaultPrecision
+		}
+		f = fmt.Sprintf("DECIMAL(%d,%d)", p, s)
+	case *schema.EnumType:
+		if len(t.Values) == 0 {
+			return "", fmt.Errorf("duckdb: missing values for enum type")
+		}
+		vs := make([]string, len(t.Values))
+		for i, v := range t.Values {
+			vs[i] = quote(v)
+		}
+		f = fmt.Sprintf("ENUM(%s)", strings.Join(vs, ", "))
+	case *schema.FloatType:
+		switch strings.ToLower(t.T) {
+		case TypeFloat, "float4", "real":
+			f = strings.ToUpper(TypeFloat)
+			// FLOAT(p) with precision above 24 is a double in the SQL standard.
+			if t.Precision > 24 {
+				f = strings.ToUpper(TypeDouble)
+			}
+		default:
+			f = strings.ToUpper(TypeDouble)
+		}
+	case *schema.IntegerType:
+		switch strings.ToLower(t.T) {
+		case TypeTinyInt, "int1":
+			f = strings.ToUpper(TypeTinyInt)
+		case TypeSmallInt, "int2", "short":
+			f = strings.ToUpper(TypeSmallInt)
+		case TypeBigInt, "int8", "long":
+			f = strings.ToUpper(TypeBigInt)
+		case TypeHugeInt, "int128":
+			f = strings.ToUpper(TypeHugeInt)
+		case TypeUTinyInt, TypeUSmallInt, TypeUInteger, TypeUBigInt, TypeUHugeInt:
+			return strings.ToUpper(t.T), nil
+		default:
+			f = strings.ToUpper(TypeInteger)
+		}
+		if t.Unsigned {
+			f = "U" + f
+		}
+	case *schema.JSONType:
+		f = strings.ToUpper(TypeJSON)
+	case *schema.SpatialType:
+		f = "GEOMETRY"
+	case *schema.StringType:
+		f = strings.ToUpper(TypeVarchar)
+	case *schema.TimeType:
+		switch f = strings.ToUpper(t.T); strings.ToLower(t.T) {
+		case TypeDate, TypeTime, TypeTimeTZ, TypeTimestamp, TypeTimestampTZ, TypeTimestampS, TypeTimestampMS, TypeTimestampNS:
+		case "datetime", "timestamp without time zone":
+			f = strings.ToUpper(TypeTimestamp)
+		case "timestamptz":
+			f = strings.ToUpper(TypeTimestampTZ)
+		case "timetz":
+			f = strings.ToUpper(TypeTimeTZ)
+		case "time without time zone":
+			f = strings.ToUpper(TypeTime)
+		default:
+			return "", fmt.Errorf("duckdb: unsupported time type: %q", t.T)
+		}
+	case *schema.UnsupportedType:
+		// Types that are unknown to the driver (e.g. LIST, STRUCT or MAP) are passed as is.
+		if t.T == "" {
+			return "", fmt.Errorf("duckdb: missing unsupported type definition")
+		}
+		f = t.T
+	default:
+		return "", fmt.Errorf("duckdb: invalid schema type: %T", t)
+	}
+	return f, nil
+}
+
+// mustFormat calls to FormatType and panics in case of error.
+func mustFormat(t schema.Type) string {
+	s, err := FormatType(t)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// ParseType returns the schema.Type value represented by the given raw type.
+// The raw value is expected to follow the format of the data_type column in
+// the duckdb_columns() function (e.g. DECIMAL(18,3) or ENUM('a', 'b')).
+// https://duckdb.org/docs/sql/data_types/overview
+//
+// Nested types (LIST, STRUCT, MAP and UNION) are parsed as schema.UnsupportedType.
+func ParseType(raw string) (schema.Type, error) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+	// Nested types are kept in their raw form.
+	if strings.HasSuffix(name, "]") {
+		return &schema.UnsupportedType{T: raw}, nil
+	}
+	var mods string
+	if i, j := strings.IndexByte(name, '('), strings.LastIndexByte(name, ')'); i > 0 && j > i {
+		mods = strings.TrimSpace(raw)[i+1 : j]
+		name = strings.TrimSpace(name[:i])
+	}
+	switch name {
+	case TypeBoolean, "bool", "logical":
+		return &schema.BoolType{T: TypeBoolean}, nil
+	case TypeTinyInt, TypeSmallInt, TypeInteger, TypeBigInt, TypeHugeInt:
+		return &schema.IntegerType{T: name}, nil
+	case TypeUTinyInt, TypeUSmallInt, TypeUInteger, TypeUBigInt, TypeUHugeInt:
+		return &schema.IntegerType{T: name[1:], Unsigned: true}, nil
+	case TypeDecimal, "numeric":
+		t := &schema.DecimalType{T: TypeDecimal, Precision: defaultPrecision, Scale: defaultScale}
+		if mods == "" {
+			return t, nil
+		}
+		ints, err := parseMods(strings.Split(mods, ","))
+		if err != nil {
+			return nil, fmt.Errorf("duckdb: parse modifiers of type %q: %w", raw, err)
+		}
+		t.Precision, t.Scale = ints[0], 0
+		if len(ints) > 1 {
+			t.Scale = ints[1]
+		}
+		return t, nil
+	case TypeFloat, "float4", "real":
+		return &schema.FloatType{T: TypeFloat}, nil
+	case TypeDouble, "float8":
+		return &schema.FloatType{T: TypeDouble}, nil
+	case TypeVarchar, "char", "bpchar", "text", "string":
+		return &schema.StringType{T: TypeVarchar}, nil
+	case TypeBlob, "bytea", "binary", "varbinary":
+		return &schema.BinaryType{T: TypeBlob}, nil
+	case TypeDate, TypeTime, TypeTimeTZ, TypeTimestamp, TypeTimestampTZ, TypeTimestampS, TypeTimestampMS, TypeTimestampNS:
+		return &schema.TimeType{T: name}, nil
+	case "datetime":
+		return &schema.TimeType{T: TypeTimestamp}, nil
+	case TypeJSON:
+		return &schema.JSONType{T: TypeJSON}, nil
+	case "geometry":
+		return &schema.SpatialType{T: name}, nil
+	case TypeEnum:
+		values, err := enumValues(mods)
+		if err != nil {
+			return nil, fmt.Errorf("duckdb: parse values of type %q: %w", raw, err)
+		}
+		return &schema.EnumType{T: TypeEnum, Values: values}, nil
+	default:
+		return &schema.UnsupportedType{T: raw}, nil
+	}
+}
+
+// parseMods parses the numeric modifiers of a type (e.g. DECIMAL(10,2)).
+func parseMods(mods []string) ([]int, error) {
+	ints := make([]int, len(mods))
+	for i := range mods {
+		n, err := strconv.Atoi(strings.TrimSpace(mods[i]))
+		if err != nil {
+			return nil, err
+		}
+		ints[i] = n
+	}
+	return ints, nil
+}
+
+// enumValues parses the quoted values of an enum type (e.g. 'a', 'b').
+func enumValues(s string) ([]string, error) {
+	var (
+		values []string
+		quoted bool
+		b      strings.Builder
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			b.WriteByte(c)
+			i++
+		case quoted && c == '\'':
+			values = append(values, b.String())
+			b.Reset()
+			quoted = false
+		case quoted:
+			b.WriteByte(c)
+		case c == '\'':
+			quoted = true
+		case c != ',' && c != ' ':
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated value %q", b.String())
+	}
+	return values, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A diff provides a DuckDB implementation for sqlx.DiffDriver.
+type diff struct{ conn }
+
+// SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
+func (d *diff) SchemaAttrDiff(_, _ *schema.Schema) []schema.Change {
+	// No special schema attribute diffing for DuckDB.
+	return nil
+}
+
+// TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
+func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
+	var changes []schema.Change
+	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	return append(changes, sqlx.CheckDiff(from, to)...), nil
+}
+
+// ColumnChange returns the schema changes (if any) for migrating one column to the other.
+func (d *diff) ColumnChange(from, to *schema.Column) (schema.ChangeKind, error) {
+	change := sqlx.CommentChange(from.Attrs, to.Attrs)
+	if from.Type.Null != to.Type.Null {
+		change |= schema.ChangeNull
+	}
+	changed, err := d.typeChanged(from, to)
+	if err != nil {
+		return schema.NoChange, err
+	}
+	if changed {
+		change |= schema.ChangeType
+	}
+	if d.defaultChanged(from, to) {
+		change |= schema.ChangeDefault
+	}
+	return change, nil
+}
+
+// typeChanged reports if the column type was changed. Types are compared by
+// their formatted form, as DuckDB types have many aliases (e.g. INT4 and INTEGER,
+// or TEXT and VARCHAR).
+func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
+	if from.Type.Type == nil || to.Type.Type == nil {
+		return false, fmt.Errorf("duckdb: missing type information for column %q", from.Name)
+	}
+	t1, err := FormatType(from.Type.Type)
+	if err != nil {
+		return false, err
+	}
+	t2, err := FormatType(to.Type.Type)
+	if err != nil {
+		return false, err
+	}
+	return !strings.EqualFold(t1, t2), nil
+}
+
+// defaultChanged reports if the default value of a column was changed.
+func (d *diff) defaultChanged(from, to *schema.Column) bool {
+	d1, ok1 := defaultValue(from)
+	d2, ok2 := defaultValue(to)
+	if ok1 != ok2 {
+		return true
+	}
+	d1, d2 = sqlx.TrimParens(d1), sqlx.TrimParens(d2)
+	// Literals are compared as-is, and expressions
+	// are compared without whitespace and case.
+	if sqlx.IsQuoted(d1, '\'') || sqlx.IsQuoted(d2, '\'') {
+		return d1 != d2
+	}
+	return !strings.EqualFold(strings.Map(dropSpace, d1), strings.Map(dropSpace, d2))
+}
+
+// dropSpace is a strings.Map function that drops whitespaces.
+func dropSpace(r rune) rune {
+	if unicode.IsSpace(r) {
+		return -1
+	}
+	return r
+}
+
+// IsGeneratedIndexName reports if the index name was generated by the driver
+// for unnamed UNIQUE constraints (e.g. users_email_key).
+func (d *diff) IsGeneratedIndexName(_ *schema.Table, idx *schema.Index) bool {
+	return sqlx.Has(idx.Attrs, &UniqueConstraint{})
+}
+
+// IndexAttrChanged reports if the index attributes were changed.
+func (*diff) IndexAttrChanged(_, _ []schema.Attr) bool {
+	return false
+}
+
+// IndexPartAttrChanged reports if the index-part attributes were changed.
+func (*diff) IndexPartAttrChanged(_, _ *schema.IndexPart) bool {
+	return false
+}
+
+// ReferenceChanged reports if the foreign key referential action was changed.
+func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
+	// DuckDB supports only the "NO ACTION" (and "RESTRICT")
+	// actions, which is the default if no action is specified.
+	if from == "" {
+		from = schema.NoAction
+	}
+	if to == "" {
+		to = schema.NoAction
+	}
+	return from != to
+}
+
+// Normalize implements the sqlx.Normalizer interface. Foreign keys are unnamed in
+// DuckDB, and their inspected (generated) names are replaced by the names of their
+// equivalent foreign keys in the desired state.
+func (d *diff) Normalize(from, to *schema.Table) {
+	for _, fk1 := range from.ForeignKeys {
+		if _, ok := to.ForeignKey(fk1.Symbol); ok {
+			continue
+		}
+		for _, fk2 := range to.ForeignKeys {
+			if _, ok := from.ForeignKey(fk2.Symbol); !ok && sameFK(fk1, fk2) {
+				fk1.Symbol = fk2.Symbol
+				break
+			}
+		}
+	}
+}
+
+// sameFK reports if the two foreign keys have the same columns and referenced columns.
+func sameFK(fk1, fk2 *schema.ForeignKey) bool {
+	if fk1.RefTable.Name != fk2.RefTable.Name || len(fk1.Columns) != len(fk2.Columns) || len(fk1.RefColumns) != len(fk2.RefColumns) {
+		return false
+	}
+	for i := range fk1.Columns {
+		if fk1.Columns[i].Name != fk2.Columns[i].Name {
+			return false
+		}
+	}
+	for i := range fk1.RefColumns {
+		if fk1.RefColumns[i].Name != fk2.RefColumns[i].Name {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff_TableDiff(t *testing.T) {
+	type testcase struct {
+		name        string
+		from, to    *schema.Table
+		wantChanges []schema.Change
+		wantErr     bool
+	}
+	tests := []testcase{
+		{
+			name: "int equals integer",
+			from: &schema.Table{Name: "t", Columns: []*schema.Column{{Name: "c", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeInteger}}}}},
+			to:   &schema.Table{Name: "t", Columns: []*schema.Column{{Name: "c", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "int"}}}}},
+		},
+		{
+			name: "text equals varchar",
+			from: &schema.Table{Name: "t", Columns: []*schema.Column{{Name: "c", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar}}}}},
+			to:   &schema.Table{Name: "t", Columns: []*schema.Column{{Name: "c", Type: &schema.ColumnType{Type: &schema.StringType{T: "text", Size: 255}}}}},
+		},
+		func() testcase {
+			var (
+				from = &schema.Table{Name: "t", Columns: []*schema.Column{{Name: "c", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeInteger}}}}}
+				to   = &schema.Table{Name: "t", Columns: []*schema.Column{{Name: "c", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeBigInt}, Null: true}, Default: &schema.Literal{V: "1"}}}}
+			)
+			return testcase{
+				name: "modify column",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeNull | schema.ChangeType | schema.ChangeDefault},
+				},
+			}
+		}(),
+		{
+			name: "add check",
+			from: &schema.Table{Name: "t"},
+			to:   &schema.Table{Name: "t", Attrs: []schema.Attr{&schema.Check{Expr: "c > 0"}}},
+			wantChanges: []schema.Change{
+				&schema.AddCheck{C: &schema.Check{Expr: "c > 0"}},
+			},
+		},
+		{
+			name: "unchanged check",
+			from: &schema.Table{Name: "t", Attrs: []schema.Attr{&schema.Check{Expr: "(c > 0)"}}},
+			to:   &schema.Table{Name: "t", Attrs: []schema.Attr{&schema.Check{Name: "positive", Expr: "c > 0"}}},
+		},
+		{
+			name:    "missing type",
+			from:    &schema.Table{Name: "t", Columns: []*schema.Column{{Name: "c", Type: &schema.ColumnType{}}}},
+			to:      &schema.Table{Name: "t", Columns: []*schema.Column{{Name: "c", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeInteger}}}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.params()
+		drv, err := Open(db)
+		require.NoError(t, err)
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := drv.TableDiff(tt.from, tt.to)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.EqualValues(t, tt.wantChanges, changes)
+		})
+	}
+}
+
+func TestDiff_GeneratedNames(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.params()
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		users = &schema.Table{Name: "users", Columns: []*schema.Column{{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeBigInt}}}}}
+		from  = &schema.Table{Name: "t", Columns: []*schema.Column{{Name: "c", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeBigInt}}}}}
+		to    = &schema.Table{Name: "t", Columns: []*schema.Column{{Name: "c", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeBigInt}}}}}
+	)
+	from.Indexes = []*schema.Index{{Name: "t_c_key", Unique: true, Table: from, Parts: []*schema.IndexPart{{SeqNo: 1, C: from.Columns[0]}}, Attrs: []schema.Attr{&UniqueConstraint{}}}}
+	from.ForeignKeys = []*schema.ForeignKey{{Symbol: "t_c_fkey", Table: from, Columns: from.Columns, RefTable: users, RefColumns: users.Columns}}
+	to.Indexes = []*schema.Index{{Unique: true, Table: to, Parts: []*schema.IndexPart{{SeqNo: 1, C: to.Columns[0]}}}}
+	to.ForeignKeys = []*schema.ForeignKey{{Symbol: "owner", Table: to, Columns: to.Columns, RefTable: users, RefColumns: users.Columns}}
+	changes, err := drv.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	require.Equal(t, "owner", from.ForeignKeys[0].Symbol)
+}
@@ -0,0 +1,203 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlmetrics"
+	"ariga.io/atlas/sql/sqltrace"
+)
+
+type (
+	// Driver represents a DuckDB driver for introspecting database schemas,
+	// generating diff between schema elements and apply migrations changes.
+	//
+	// A Realm in DuckDB is the database (catalog) of the connection, and other
+	// attached databases are not inspected. Like in SQLite, table changes that
+	// are not supported by the ALTER TABLE command (e.g. adding a constraint)
+	// are applied by rewriting the table.
+	//
+	// A Driver is safe for concurrent use by multiple goroutines, as long as its
+	// underlying connection is (e.g. *sql.DB). Inspections, planning and applying
+	// of drivers that were opened on a single connection (i.e. *sql.Tx or *sql.Conn)
+	// are serialized. Note that diffing normalizes the given schema elements, and
+	// the same elements should not be diffed concurrently.
+	Driver struct {
+		conn
+		schema.Differ
+		schema.Inspector
+		migrate.PlanApplier
+	}
+
+	// Option allows configuring the Driver using functional options.
+	Option func(*options)
+
+	// options holds the configuration of the Driver.
+	options struct {
+		trace    *sqltrace.Config
+		metrics  sqlmetrics.Recorder
+		log      sqlx.LogFunc
+		readOnly bool
+	}
+
+	// database connection and its information.
+	conn struct {
+		schema.ExecQuerier
+		// System variables that are set on `Open`.
+		version string
+		// The database (catalog) of the connection,
+		// which is the realm that is inspected.
+		database string
+		// The schema of the connection, which is
+		// inspected if no schema name is provided.
+		schema string
+		// Opened on a single database connection (i.e. *sql.Tx or *sql.Conn),
+		// before it was wrapped by the options of the driver (e.g. WithReadOnly).
+		single bool
+	}
+)
+
+// Open opens a new DuckDB driver.
+func Open(db schema.ExecQuerier, opts ...Option) (*Driver, error) {
+	var (
+		o      options
+		single = sqlx.SingleConn(db)
+	)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.readOnly {
+		db = sqlx.ReadOnlyExecQuerier(db)
+	}
+	if o.log != nil {
+		db = sqlx.LogExecQuerier(db, o.log)
+	}
+	var tracer *sqltrace.Tracer
+	if o.trace != nil {
+		tracer = sqltrace.New("duckdb", *o.trace)
+		db = tracer.ExecQuerier(db)
+	}
+	var metrics *sqlmetrics.Metrics
+	if o.metrics != nil {
+		metrics = sqlmetrics.New("duckdb", o.metrics)
+		db = metrics.ExecQuerier(db)
+	}
+	c := conn{ExecQuerier: db, single: single}
+	rows, err := db.QueryContext(context.Background(), paramsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb: query version and current schema: %w", err)
+	}
+	var current sql.NullString
+	if err := sqlx.ScanOne(rows, &c.version, &c.database, &current); err != nil {
+		return nil, fmt.Errorf("duckdb: scan version and current schema: %w", err)
+	}
+	if c.schema = current.String; c.schema == "" {
+		c.schema = "main"
+	}
+	drv := &Driver{
+		conn:        c,
+		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
+		Inspector:   &inspect{c},
+		PlanApplier: &planApply{c},
+	}
+	if single {
+		var mu sync.Mutex
+		drv.Inspector = sqlx.SerialInspector(drv.Inspector, &mu)
+		drv.PlanApplier = sqlx.SerialPlanApplier(drv.PlanApplier, &mu)
+	}
+	if tracer != nil {
+		drv.Differ = tracer.Differ(drv.Differ)
+		drv.Inspector = tracer.Inspector(drv.Inspector)
+		drv.PlanApplier = tracer.PlanApplier(drv.PlanApplier)
+	}
+	if metrics != nil {
+		drv.Differ = metrics.Differ(drv.Differ)
+		drv.Inspector = metrics.Inspector(drv.Inspector)
+		drv.PlanApplier = metrics.PlanApplier(drv.PlanApplier)
+	}
+	return drv, nil
+}
+
+// WithTracing enables OpenTelemetry tracing for the driver. Spans are created
+// for schema inspection, diff computation and each executed statement.
+func WithTracing(cfg sqltrace.Config) Option {
+	return func(o *options) {
+		o.trace = &cfg
+	}
+}
+
+// WithMetrics reports the events of schema inspections, diff computations, planning,
+// and each executed statement of the driver to the given Recorder. For example, the
+// sqlmetrics.Collector exposes them as Prometheus metrics.
+func WithMetrics(r sqlmetrics.Recorder) Option {
+	return func(o *options) {
+		o.metrics = r
+	}
+}
+
+// WithReadOnly opens the driver in read-only mode. In this mode, all statements that
+// may write to the database are rejected with a schema.ReadOnlyError before they are
+// sent to it, and the driver can be used only for inspecting the database. Note that
+// this is not a replacement for opening the database file in read-only mode, but a
+// guard against writes that are attempted by the driver or by its users.
+func WithReadOnly(b bool) Option {
+	return func(o *options) {
+		o.readOnly = b
+	}
+}
+
+// WithStatementLog calls f with each statement (and its arguments) that is
+// executed or queried by the driver, before it is sent to the database.
+func WithStatementLog(f func(ctx context.Context, stmt string, args []interface{})) Option {
+	return func(o *options) {
+		o.log = f
+	}
+}
+
+// DuckDB data types.
+// https://duckdb.org/docs/sql/data_types/overview
+const (
+	TypeBoolean     = "boolean"
+	TypeTinyInt     = "tinyint"
+	TypeSmallInt    = "smallint"
+	TypeInteger     = "integer"
+	TypeBigInt      = "bigint"
+	TypeHugeInt     = "hugeint"
+	TypeUTinyInt    = "utinyint"
+	TypeUSmallInt   = "usmallint"
+	TypeUInteger    = "uinteger"
+	TypeUBigInt     = "ubigint"
+	TypeUHugeInt    = "uhugeint"
+	TypeDecimal     = "decimal"
+	TypeFloat       = "float"
+	TypeDouble      = "double"
+	TypeVarchar     = "varchar"
+	TypeBlob        = "blob"
+	TypeDate        = "date"
+	TypeTime        = "time"
+	TypeTimeTZ      = "time with time zone"
+	TypeTimestamp   = "timestamp"
+	TypeTimestampTZ = "timestamp with time zone"
+	TypeTimestampS  = "timestamp_s"
+	TypeTimestampMS = "timestamp_ms"
+	TypeTimestampNS = "timestamp_ns"
+	TypeInterval    = "interval"
+	TypeUUID        = "uuid"
+	TypeJSON        = "json"
+	TypeEnum        = "enum"
+)
+
+// Default precision and scale of the DECIMAL type.
+const (
+	defaultPrecision = 18
+	defaultScale     = 3
+)
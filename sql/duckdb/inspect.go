@@ -0,0 +1,525 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// An inspect provides a DuckDB implementation for schema.Inspector.
+type inspect struct{ conn }
+
+var _ schema.Inspector = (*inspect)(nil)
+
+// InspectRealm returns schema descriptions of all resources in the database
+// of the connection. The information_schema and pg_catalog schemas are skipped.
+func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	schemas, err := i.schemas(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	realm := &schema.Realm{Schemas: schemas}
+	for _, s := range schemas {
+		if err := i.inspectSchema(ctx, s, nil); err != nil {
+			return nil, err
+		}
+		s.Realm = realm
+	}
+	sqlx.LinkSchemaTables(schemas)
+	return realm, nil
+}
+
+// InspectSchema returns schema descriptions of the tables in the given schema.
+// If the schema name is empty, the current schema of the connection is used.
+func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
+	if name == "" {
+		name = i.schema
+	}
+	schemas, err := i.schemas(ctx, &schema.InspectRealmOption{Schemas: []string{name}})
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, &schema.NotExistError{
+			Err: fmt.Errorf("duckdb: schema %q was not found", name),
+		}
+	}
+	s := schemas[0]
+	if err := i.inspectSchema(ctx, s, opts); err != nil {
+		return nil, err
+	}
+	sqlx.LinkSchemaTables(schemas)
+	s.Realm = &schema.Realm{Schemas: schemas}
+	return s, nil
+}
+
+// inspectSchema inspects and appends the tables of the given schema. Columns,
+// constraints and indexes are inspected for all tables of the schema at once.
+func (i *inspect) inspectSchema(ctx context.Context, s *schema.Schema, opts *schema.InspectOptions) error {
+	tables, err := i.tables(ctx, s.Name, opts)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		t.Schema = s
+	}
+	s.Tables = append(s.Tables, tables...)
+	if len(s.Tables) == 0 {
+		return nil
+	}
+	if err := i.columns(ctx, s); err != nil {
+		return err
+	}
+	if err := i.constraints(ctx, s); err != nil {
+		return err
+	}
+	return i.indexes(ctx, s)
+}
+
+// schemas returns the list of the schemas in the database.
+func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
+	var (
+		args  []interface{}
+		query = schemasQuery
+	)
+	if opts != nil && len(opts.Schemas) > 0 {
+		query, args = inStrings(opts.Schemas, schemasQueryArgs, args)
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb: querying schemas: %w", err)
+	}
+	names, err := sqlx.ScanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb: scanning schemas: %w", err)
+	}
+	schemas := make([]*schema.Schema, len(names))
+	for j := range names {
+		schemas[j] = &schema.Schema{Name: names[j]}
+	}
+	return schemas, nil
+}
+
+// tables returns the tables of the given schema.
+func (i *inspect) tables(ctx context.Context, ns string, opts *schema.InspectOptions) ([]*schema.Table, error) {
+	query, args := tablesQuery, []interface{}{ns}
+	if opts != nil && len(opts.Tables) > 0 {
+		query, args = inStrings(opts.Tables, tablesQueryArgs, args)
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb: querying schema tables: %w", err)
+	}
+	defer rows.Close()
+	var tables []*schema.Table
+	for rows.Next() {
+		var (
+			name    string
+			comment sql.NullString
+		)
+		if err := rows.Scan(&name, &comment); err != nil {
+			return nil, fmt.Errorf("duckdb: scanning table: %w", err)
+		}
+		t := &schema.Table{Name: name}
+		if sqlx.ValidString(comment) {
+			t.Attrs = append(t.Attrs, &schema.Comment{Text: comment.String})
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// columns queries and appends the columns of the schema tables.
+func (i *inspect) columns(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.QueryContext(ctx, columnsQuery, s.Name)
+	if err != nil {
+		return fmt.Errorf("duckdb: querying schema %q columns: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := addColumn(s, rows); err != nil {
+			return fmt.Errorf("duckdb: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+// addColumn scans the current row and adds a new column from it to its table.
+// Columns of tables that were not inspected (e.g. views) are skipped.
+func addColumn(s *schema.Schema, rows *sql.Rows) error {
+	var (
+		nullable          bool
+		table, name, typ  string
+		defaults, comment sql.NullString
+	)
+	if err := rows.Scan(&table, &name, &typ, &nullable, &defaults, &comment); err != nil {
+		return err
+	}
+	t, ok := s.Table(table)
+	if !ok {
+		return nil
+	}
+	ct, err := ParseType(typ)
+	if err != nil {
+		return err
+	}
+	c := &schema.Column{
+		Name: name,
+		Type: &schema.ColumnType{
+			Raw:  typ,
+			Null: nullable,
+			Type: ct,
+		},
+	}
+	if sqlx.ValidString(defaults) {
+		c.Default = defaultExpr(defaults.String)
+	}
+	if sqlx.ValidString(comment) {
+		c.Attrs = append(c.Attrs, &schema.Comment{Text: comment.String})
+	}
+	t.Columns = append(t.Columns, c)
+	return nil
+}
+
+// reCastLiteral matches literals that are cast to their column
+// type by DuckDB (e.g. CAST('t' AS BOOLEAN)).
+var reCastLiteral = regexp.MustCompile(`(?i)^CAST\(('(?:[^']|'')*') AS [\w ]+\)$`)
+
+// defaultExpr returns the schema expression of the given DEFAULT clause.
+func defaultExpr(x string) schema.Expr {
+	x = strings.TrimSpace(x)
+	if m := reCastLiteral.FindStringSubmatch(x); m != nil {
+		switch x = m[1]; strings.ToLower(x) {
+		case "'t'", "'true'":
+			return &schema.Literal{V: "true"}
+		case "'f'", "'false'":
+			return &schema.Literal{V: "false"}
+		}
+	}
+	switch {
+	case sqlx.IsLiteralNumber(x), sqlx.IsLiteralBool(x), sqlx.IsQuoted(x, '\''):
+		return &schema.Literal{V: x}
+	default:
+		return &schema.RawExpr{X: x}
+	}
+}
+
+// constraints queries and appends the PRIMARY KEY, UNIQUE, FOREIGN KEY and CHECK
+// constraints of the schema tables. Constraints are unnamed in DuckDB, and UNIQUE
+// constraints and foreign keys are given generated names (see UniqueConstraint).
+func (i *inspect) constraints(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.QueryContext(ctx, constraintsQuery, s.Name)
+	if err != nil {
+		return fmt.Errorf("duckdb: querying schema %q constraints: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			table, typ                 string
+			expr, columns, ref, refCol sql.NullString
+		)
+		if err := rows.Scan(&table, &typ, &expr, &columns, &ref, &refCol); err != nil {
+			return fmt.Errorf("duckdb: scanning constraint: %w", err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			continue
+		}
+		if typ == "CHECK" {
+			t.Attrs = append(t.Attrs, &schema.Check{Expr: expr.String})
+			continue
+		}
+		cols, err := tableColumns(t, columns.String)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case "PRIMARY KEY":
+			t.PrimaryKey = &schema.Index{Unique: true, Table: t}
+			addParts(t.PrimaryKey, cols)
+		case "UNIQUE":
+			idx := &schema.Index{
+				Name:   constraintName(t, cols, "key"),
+				Unique: true,
+				Table:  t,
+				Attrs:  []schema.Attr{&UniqueConstraint{}},
+			}
+			addParts(idx, cols)
+			t.Indexes = append(t.Indexes, idx)
+		case "FOREIGN KEY":
+			fk := &schema.ForeignKey{
+				Symbol:  constraintName(t, cols, "fkey"),
+				Table:   t,
+				Columns: cols,
+			}
+			if fk.RefTable, ok = s.Table(ref.String); !ok {
+				fk.RefTable = &schema.Table{Name: ref.String, Schema: s}
+			}
+			var names []string
+			if err := json.Unmarshal([]byte(refCol.String), &names); err != nil {
+				return fmt.Errorf("duckdb: parse referenced columns of %q: %w", fk.Symbol, err)
+			}
+			for _, n := range names {
+				c, ok := fk.RefTable.Column(n)
+				if !ok {
+					c = &schema.Column{Name: n}
+				}
+				fk.RefColumns = append(fk.RefColumns, c)
+			}
+			for _, c := range cols {
+				c.ForeignKeys = append(c.ForeignKeys, fk)
+			}
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		}
+	}
+	return rows.Err()
+}
+
+// reIndexParts matches the parts of a CREATE INDEX statement.
+var reIndexParts = regexp.MustCompile(`(?is)\sON\s+\S+\s*\((.*)\)\s*;?\s*$`)
+
+// indexes queries and appends the indexes of the schema tables. Index parts
+// are extracted from the CREATE INDEX statement of the index.
+func (i *inspect) indexes(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.QueryContext(ctx, indexesQuery, s.Name)
+	if err != nil {
+		return fmt.Errorf("duckdb: querying schema %q indexes: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			unique        bool
+			table, name   string
+			stmt, comment sql.NullString
+		)
+		if err := rows.Scan(&table, &name, &unique, &stmt, &comment); err != nil {
+			return fmt.Errorf("duckdb: scanning index: %w", err)
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			continue
+		}
+		m := reIndexParts.FindStringSubmatch(stmt.String)
+		if m == nil {
+			return fmt.Errorf("duckdb: unexpected definition for index %q: %q", name, stmt.String)
+		}
+		idx := &schema.Index{Name: name, Unique: unique, Table: t}
+		for _, x := range splitExprs(m[1]) {
+			part := &schema.IndexPart{SeqNo: len(idx.Parts) + 1}
+			if c, ok := t.Column(unquote(x)); ok {
+				part.C = c
+				c.Indexes = append(c.Indexes, idx)
+			} else {
+				part.X = &schema.RawExpr{X: x}
+			}
+			idx.Parts = append(idx.Parts, part)
+		}
+		if sqlx.ValidString(comment) {
+			idx.Attrs = append(idx.Attrs, &schema.Comment{Text: comment.String})
+		}
+		t.Indexes = append(t.Indexes, idx)
+	}
+	return rows.Err()
+}
+
+// tableColumns returns the table columns of the given JSON array of names.
+func tableColumns(t *schema.Table, names string) ([]*schema.Column, error) {
+	var ns []string
+	if err := json.Unmarshal([]byte(names), &ns); err != nil {
+		return nil, fmt.Errorf("duckdb: parse constraint columns of table %q: %w", t.Name, err)
+	}
+	cols := make([]*schema.Column, len(ns))
+	for i, n := range ns {
+		c, ok := t.Column(n)
+		if !ok {
+			return nil, fmt.Errorf("duckdb: column %q was not found for constraint in table %q", n, t.Name)
+		}
+		cols[i] = c
+	}
+	return cols, nil
+}
+
+// addParts appends the given columns as parts of the index.
+func addParts(idx *schema.Index, cols []*schema.Column) {
+	for _, c := range cols {
+		idx.Parts = append(idx.Parts, &schema.IndexPart{SeqNo: len(idx.Parts) + 1, C: c})
+		c.Indexes = append(c.Indexes, idx)
+	}
+}
+
+// constraintName returns the name that is generated for an unnamed
+// constraint, following the PostgreSQL convention (e.g. users_email_key).
+func constraintName(t *schema.Table, cols []*schema.Column, suffix string) string {
+	parts := make([]string, 0, len(cols)+2)
+	parts = append(parts, t.Name)
+	for _, c := range cols {
+		parts = append(parts, c.Name)
+	}
+	return strings.Join(append(parts, suffix), "_")
+}
+
+// splitExprs splits the given comma-separated list of expressions,
+// ignoring commas that are nested in parentheses or quotes.
+func splitExprs(s string) []string {
+	var (
+		exprs []string
+		depth int
+		quote rune
+		start int
+	)
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			exprs = append(exprs, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if x := strings.TrimSpace(s[start:]); x != "" {
+		exprs = append(exprs, x)
+	}
+	return exprs
+}
+
+// unquote returns the unquoted form of a quoted identifier.
+func unquote(s string) string {
+	if sqlx.IsQuoted(s, '"') {
+		return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+	}
+	return s
+}
+
+// inStrings writes the "IN" (or "=") clause of the given strings
+// to the query, and appends them to the positional arguments.
+func inStrings(s []string, query string, args []interface{}) (string, []interface{}) {
+	var b strings.Builder
+	switch len(s) {
+	case 1:
+		args = append(args, s[0])
+		b.WriteString("= ?")
+	default:
+		b.WriteString("IN (")
+		for i := range s {
+			args = append(args, s[i])
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte('?')
+		}
+		b.WriteByte(')')
+	}
+	return fmt.Sprintf(query, b.String()), args
+}
+
+// UniqueConstraint describes a unique index that was defined as a UNIQUE
+// constraint in the table definition. Unlike indexes, UNIQUE constraints
+// are unnamed in DuckDB, and they cannot be added to (or dropped from)
+// existing tables without rewriting them.
+type UniqueConstraint struct {
+	schema.Attr
+}
+
+const (
+	// Query to get the database version, and the current database and schema.
+	paramsQuery = `SELECT version(), current_database(), current_schema()`
+
+	// Query to list the schemas of the database, except the system schemas.
+	schemasQuery = `SELECT schema_name FROM duckdb_schemas() WHERE database_name = current_database() AND schema_name NOT IN ('information_schema', 'pg_catalog') ORDER BY schema_name`
+
+	// Query to list specific schemas.
+	schemasQueryArgs = `SELECT schema_name FROM duckdb_schemas() WHERE database_name = current_database() AND schema_name %s ORDER BY schema_name`
+
+	// Query to list the tables of a schema. Temporary tables are skipped.
+	tablesQuery = `
+SELECT
+	table_name,
+	comment
+FROM duckdb_tables()
+WHERE
+	database_name = current_database()
+	AND schema_name = ?
+	AND NOT temporary
+ORDER BY table_name
+`
+
+	tablesQueryArgs = `
+SELECT
+	table_name,
+	comment
+FROM duckdb_tables()
+WHERE
+	database_name = current_database()
+	AND schema_name = ?
+	AND NOT temporary
+	AND table_name %s
+ORDER BY table_name
+`
+
+	// Query to list the columns of all tables and views in a schema.
+	columnsQuery = `
+SELECT
+	table_name,
+	column_name,
+	data_type,
+	is_nullable,
+	column_default,
+	comment
+FROM duckdb_columns()
+WHERE
+	database_name = current_database()
+	AND schema_name = ?
+ORDER BY table_name, column_index
+`
+
+	// Query to list the constraints of all tables in a schema.
+	// NOT NULL constraints are inspected as part of the columns.
+	constraintsQuery = `
+SELECT
+	table_name,
+	constraint_type,
+	expression,
+	CAST(to_json(constraint_column_names) AS VARCHAR),
+	referenced_table,
+	CAST(to_json(referenced_column_names) AS VARCHAR)
+FROM duckdb_constraints()
+WHERE
+	database_name = current_database()
+	AND schema_name = ?
+	AND constraint_type IN ('PRIMARY KEY', 'UNIQUE', 'FOREIGN KEY', 'CHECK')
+ORDER BY table_name, constraint_index
+`
+
+	// Query to list the indexes of all tables in a schema.
+	indexesQuery = `
+SELECT
+	table_name,
+	index_name,
+	is_unique,
+	sql,
+	comment
+FROM duckdb_indexes()
+WHERE
+	database_name = current_database()
+	AND schema_name = ?
+ORDER BY table_name, index_name
+`
+)
@@ -0,0 +1,212 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectSchema(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.params()
+	mk.ExpectQuery(sqltest.Escape(`SELECT schema_name FROM duckdb_schemas() WHERE database_name = current_database() AND schema_name = ? ORDER BY schema_name`)).
+		WithArgs("main").
+		WillReturnRows(sqltest.Rows(`
+ schema_name
+-------------
+ main
+`))
+	mk.ExpectQuery(sqltest.Escape(tablesQuery)).
+		WithArgs("main").
+		WillReturnRows(sqltest.Rows(`
+ table_name | comment
+------------+-----------
+ events     | nil
+ users      | app users
+`))
+	mk.ExpectQuery(sqltest.Escape(columnsQuery)).
+		WithArgs("main").
+		WillReturnRows(sqltest.Rows(`
+ table_name   | column_name | data_type             | is_nullable | column_default          | comment
+--------------+-------------+-----------------------+-------------+-------------------------+------------
+ active_users | id          | BIGINT                | false       | nil                     | nil
+ events       | id          | BIGINT                | false       | nil                     | nil
+ events       | user_id     | BIGINT                | true        | nil                     | nil
+ events       | kind        | ENUM('click', 'view') | false       | 'click'                 | nil
+ events       | tags        | VARCHAR[]             | true        | nil                     | event tags
+ events       | created     | TIMESTAMP             | false       | CURRENT_TIMESTAMP       | nil
+ users        | id          | BIGINT                | false       | nil                     | nil
+ users        | email       | VARCHAR               | false       | nil                     | nil
+ users        | balance     | DECIMAL(10,2)         | true        | 0                       | nil
+ users        | active      | BOOLEAN               | false       | CAST('t' AS BOOLEAN)    | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(constraintsQuery)).
+		WithArgs("main").
+		WillReturnRows(sqltest.Rows(`
+ table_name | constraint_type | expression    | constraint_column_names | referenced_table | referenced_column_names
+------------+-----------------+---------------+-------------------------+------------------+-------------------------
+ events     | PRIMARY KEY     | nil           | ["id"]                  | nil              | nil
+ events     | FOREIGN KEY     | nil           | ["user_id"]             | users            | ["id"]
+ users      | PRIMARY KEY     | nil           | ["id"]                  | nil              | nil
+ users      | UNIQUE          | nil           | ["email"]               | nil              | nil
+ users      | CHECK           | (balance > 0) | ["balance"]             | nil              | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(indexesQuery)).
+		WithArgs("main").
+		WillReturnRows(sqltest.Rows(`
+ table_name | index_name   | is_unique | sql                                                    | comment
+------------+--------------+-----------+--------------------------------------------------------+---------
+ events     | events_kind  | false     | CREATE INDEX events_kind ON events(kind, lower(kind)); | nil
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s, err := drv.InspectSchema(context.Background(), "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "main", s.Name)
+	require.Len(t, s.Tables, 2)
+
+	events, ok := s.Table("events")
+	require.True(t, ok)
+	columns := []*schema.Column{
+		{Name: "id", Type: &schema.ColumnType{Raw: "BIGINT", Type: &schema.IntegerType{T: TypeBigInt}}},
+		{Name: "user_id", Type: &schema.ColumnType{Raw: "BIGINT", Null: true, Type: &schema.IntegerType{T: TypeBigInt}}},
+		{Name: "kind", Type: &schema.ColumnType{Raw: "ENUM('click', 'view')", Type: &schema.EnumType{T: TypeEnum, Values: []string{"click", "view"}}}, Default: &schema.Literal{V: "'click'"}},
+		{Name: "tags", Type: &schema.ColumnType{Raw: "VARCHAR[]", Null: true, Type: &schema.UnsupportedType{T: "VARCHAR[]"}}, Attrs: []schema.Attr{&schema.Comment{Text: "event tags"}}},
+		{Name: "created", Type: &schema.ColumnType{Raw: "TIMESTAMP", Type: &schema.TimeType{T: TypeTimestamp}}, Default: &schema.RawExpr{X: "CURRENT_TIMESTAMP"}},
+	}
+	require.Len(t, events.Columns, len(columns))
+	for i, c := range columns {
+		require.EqualValues(t, c.Name, events.Columns[i].Name)
+		require.EqualValues(t, c.Type, events.Columns[i].Type)
+		require.EqualValues(t, c.Default, events.Columns[i].Default)
+		require.EqualValues(t, c.Attrs, events.Columns[i].Attrs)
+	}
+	require.Equal(t, events.Columns[0], events.PrimaryKey.Parts[0].C)
+	require.Len(t, events.Indexes, 1)
+	require.Equal(t, "events_kind", events.Indexes[0].Name)
+	require.Equal(t, events.Columns[2], events.Indexes[0].Parts[0].C)
+	require.Equal(t, &schema.RawExpr{X: "lower(kind)"}, events.Indexes[0].Parts[1].X)
+
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "app users"}, &schema.Check{Expr: "(balance > 0)"}}, users.Attrs)
+	require.Equal(t, &schema.DecimalType{T: TypeDecimal, Precision: 10, Scale: 2}, users.Columns[2].Type.Type)
+	require.Equal(t, &schema.Literal{V: "0"}, users.Columns[2].Default)
+	require.Equal(t, &schema.Literal{V: "true"}, users.Columns[3].Default)
+	require.Len(t, users.Indexes, 1)
+	require.Equal(t, "users_email_key", users.Indexes[0].Name)
+	require.True(t, users.Indexes[0].Unique)
+	require.Equal(t, []schema.Attr{&UniqueConstraint{}}, users.Indexes[0].Attrs)
+
+	require.Len(t, events.ForeignKeys, 1)
+	fk := events.ForeignKeys[0]
+	require.Equal(t, "events_user_id_fkey", fk.Symbol)
+	require.Equal(t, users, fk.RefTable)
+	require.Equal(t, users.Columns[0], fk.RefColumns[0])
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDriver_InspectSchema_NotExist(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.params()
+	mk.ExpectQuery(sqltest.Escape(`SELECT schema_name FROM duckdb_schemas() WHERE database_name = current_database() AND schema_name = ? ORDER BY schema_name`)).
+		WithArgs("other").
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	_, err = drv.InspectSchema(context.Background(), "other", nil)
+	require.True(t, schema.IsNotExistError(err))
+}
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want schema.Type
+	}{
+		{raw: "INTEGER", want: &schema.IntegerType{T: TypeInteger}},
+		{raw: "UBIGINT", want: &schema.IntegerType{T: TypeBigInt, Unsigned: true}},
+		{raw: "DECIMAL(10,2)", want: &schema.DecimalType{T: TypeDecimal, Precision: 10, Scale: 2}},
+		{raw: "DECIMAL", want: &schema.DecimalType{T: TypeDecimal, Precision: 18, Scale: 3}},
+		{raw: "FLOAT", want: &schema.FloatType{T: TypeFloat}},
+		{raw: "DOUBLE", want: &schema.FloatType{T: TypeDouble}},
+		{raw: "VARCHAR", want: &schema.StringType{T: TypeVarchar}},
+		{raw: "BLOB", want: &schema.BinaryType{T: TypeBlob}},
+		{raw: "BOOLEAN", want: &schema.BoolType{T: TypeBoolean}},
+		{raw: "TIMESTAMP WITH TIME ZONE", want: &schema.TimeType{T: TypeTimestampTZ}},
+		{raw: "TIMESTAMP_MS", want: &schema.TimeType{T: TypeTimestampMS}},
+		{raw: "JSON", want: &schema.JSONType{T: TypeJSON}},
+		{raw: "ENUM('a', 'it''s')", want: &schema.EnumType{T: TypeEnum, Values: []string{"a", "it's"}}},
+		{raw: "INTEGER[]", want: &schema.UnsupportedType{T: "INTEGER[]"}},
+		{raw: "STRUCT(a INTEGER, b VARCHAR)", want: &schema.UnsupportedType{T: "STRUCT(a INTEGER, b VARCHAR)"}},
+		{raw: "UUID", want: &schema.UnsupportedType{T: "UUID"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			typ, err := ParseType(tt.raw)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, typ)
+		})
+	}
+	_, err := ParseType("DECIMAL(x)")
+	require.Error(t, err)
+	_, err = ParseType("ENUM('a)")
+	require.Error(t, err)
+}
+
+func TestFormatType(t *testing.T) {
+	tests := []struct {
+		typ  schema.Type
+		want string
+	}{
+		{typ: &schema.IntegerType{T: "int"}, want: "INTEGER"},
+		{typ: &schema.IntegerType{T: "bigint", Unsigned: true}, want: "UBIGINT"},
+		{typ: &schema.IntegerType{T: "int2"}, want: "SMALLINT"},
+		{typ: &schema.DecimalType{T: TypeDecimal, Precision: 10, Scale: 2}, want: "DECIMAL(10,2)"},
+		{typ: &schema.DecimalType{T: "numeric"}, want: "DECIMAL(18,3)"},
+		{typ: &schema.FloatType{T: "real"}, want: "FLOAT"},
+		{typ: &schema.FloatType{T: "double precision"}, want: "DOUBLE"},
+		{typ: &schema.StringType{T: "text"}, want: "VARCHAR"},
+		{typ: &schema.StringType{T: TypeVarchar, Size: 255}, want: "VARCHAR"},
+		{typ: &schema.BinaryType{T: "bytea"}, want: "BLOB"},
+		{typ: &schema.TimeType{T: "datetime"}, want: "TIMESTAMP"},
+		{typ: &schema.TimeType{T: "timestamptz"}, want: "TIMESTAMP WITH TIME ZONE"},
+		{typ: &schema.JSONType{T: "jsonb"}, want: "JSON"},
+		{typ: &schema.EnumType{Values: []string{"a", "it's"}}, want: "ENUM('a', 'it''s')"},
+		{typ: &schema.UnsupportedType{T: "INTEGER[]"}, want: "INTEGER[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			f, err := FormatType(tt.typ)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, f)
+		})
+	}
+	_, err := FormatType(&schema.EnumType{})
+	require.Error(t, err)
+}
+
+type mock struct {
+	sqlmock.Sqlmock
+}
+
+func (m mock) params() {
+	m.ExpectQuery(sqltest.Escape(paramsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ version() | current_database() | current_schema()
+-----------+--------------------+------------------
+ v1.1.3    | memory             | main
+`))
+}
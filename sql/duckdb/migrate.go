@@ -0,0 +1,692 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A planApply provides migration capabilities for schema elements.
+type planApply struct{ conn }
+
+// PlanChanges returns a migration plan for the given schema changes. DDL
+// statements are transactional in DuckDB, and the returned plan is too.
+func (p *planApply) PlanChanges(ctx context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+	s := &state{
+		conn: p.conn,
+		Plan: migrate.Plan{
+			Name:          name,
+			Reversible:    true,
+			Transactional: true,
+		},
+	}
+	if err := s.plan(changes); err != nil {
+		return nil, err
+	}
+	for _, c := range s.Changes {
+		if c.Reverse == "" {
+			s.Reversible = false
+		}
+	}
+	return &s.Plan, nil
+}
+
+// ApplyChanges applies the changes on the database. An error is returned
+// if the driver is unable to produce a plan to do so, or one of the statements
+// is failed or unsupported.
+func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change) error {
+	return sqlx.ApplyChanges(ctx, changes, p)
+}
+
+// SingleConn reports if the driver was opened on a single database connection.
+// It is used by sqlx.ApplyChanges to check if savepoints can be used.
+func (p *planApply) SingleConn() bool {
+	return p.single
+}
+
+// state represents the state of a planning. It is not part of
+// planApply so that multiple planning/applying can be called
+// in parallel.
+type state struct {
+	conn
+	migrate.Plan
+}
+
+// plan builds the migration plan of the changes. An error is
+// returned if one of the changes is not supported.
+func (s *state) plan(changes []schema.Change) (err error) {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			b := Build("CREATE SCHEMA")
+			if sqlx.Has(c.Extra, &schema.IfNotExists{}) {
+				b.P("IF NOT EXISTS")
+			}
+			s.append(&migrate.Change{
+				Cmd:     b.Ident(c.S.Name).String(),
+				Source:  c,
+				Reverse: Build("DROP SCHEMA").Ident(c.S.Name).String(),
+				Comment: fmt.Sprintf("Add new schema named %q", c.S.Name),
+			})
+		case *schema.DropSchema:
+			b := Build("DROP SCHEMA")
+			if sqlx.Has(c.Extra, &schema.IfExists{}) {
+				b.P("IF EXISTS")
+			}
+			s.append(&migrate.Change{
+				Cmd:     b.Ident(c.S.Name).P("CASCADE").String(),
+				Source:  c,
+				Comment: fmt.Sprintf("Drop schema named %q", c.S.Name),
+			})
+		case *schema.ModifySchema:
+			if len(c.Changes) > 0 {
+				err = fmt.Errorf("unsupported schema changes for schema %q", c.S.Name)
+			}
+		case *schema.AddTable:
+			err = s.addTable(c)
+		case *schema.DropTable:
+			err = s.dropTable(c)
+		case *schema.ModifyTable:
+			err = s.modifyTable(c)
+		default:
+			err = fmt.Errorf("unsupported change %T", c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTable builds the statements for creating a table, its indexes and comments.
+func (s *state) addTable(add *schema.AddTable) error {
+	indexes, err := s.createTable(add, add.T)
+	if err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		s.append(indexChange(&schema.AddIndex{I: idx}, add.T, idx))
+	}
+	s.comments(add, add.T)
+	return nil
+}
+
+// createTable appends the CREATE TABLE statement of the given table, and returns
+// its indexes that should be created separately. UNIQUE constraints (see UniqueConstraint),
+// foreign keys and CHECK constraints are defined inline, as they cannot be added to
+// existing tables in DuckDB.
+func (s *state) createTable(add *schema.AddTable, t *schema.Table) ([]*schema.Index, error) {
+	var (
+		errs    []string
+		indexes []*schema.Index
+		b       = Build("CREATE TABLE")
+	)
+	if sqlx.Has(add.Extra, &schema.IfNotExists{}) {
+		b.P("IF NOT EXISTS")
+	}
+	b.Table(t)
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(t.Columns, func(i int, b *sqlx.Builder) {
+			if err := s.column(b, t.Columns[i]); err != nil {
+				errs = append(errs, err.Error())
+			}
+		})
+		if pk := t.PrimaryKey; pk != nil {
+			b.Comma().P("PRIMARY KEY")
+			indexParts(b, pk.Parts)
+		}
+		for _, idx := range t.Indexes {
+			if !sqlx.Has(idx.Attrs, &UniqueConstraint{}) {
+				indexes = append(indexes, idx)
+				continue
+			}
+			b.Comma().P("UNIQUE")
+			indexParts(b, idx.Parts)
+		}
+		for _, fk := range t.ForeignKeys {
+			b.Comma()
+			if err := s.fk(b, fk); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		for _, attr := range t.Attrs {
+			if c, ok := attr.(*schema.Check); ok {
+				b.Comma()
+				check(b, c)
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("create table %q: %s", t.Name, strings.Join(errs, ", "))
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  add,
+		Comment: fmt.Sprintf("create %q table", t.Name),
+		Reverse: Build("DROP TABLE").Table(t).String(),
+	})
+	return indexes, nil
+}
+
+// comments appends the statements for setting the comments of the
+// table and its columns, if they were defined.
+func (s *state) comments(source schema.Change, t *schema.Table) {
+	if x := (schema.Comment{}); sqlx.Has(t.Attrs, &x) && x.Text != "" {
+		s.append(tableComment(source, t, x.Text, ""))
+	}
+	for _, c := range t.Columns {
+		if x := (schema.Comment{}); sqlx.Has(c.Attrs, &x) && x.Text != "" {
+			s.append(columnComment(source, t, c, x.Text, ""))
+		}
+	}
+}
+
+// dropTable builds the statement for dropping a table from a schema.
+func (s *state) dropTable(drop *schema.DropTable) error {
+	b := Build("DROP TABLE")
+	if sqlx.Has(drop.Extra, &schema.IfExists{}) {
+		b.P("IF EXISTS")
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.Table(drop.T).String(),
+		Source:  drop,
+		Comment: fmt.Sprintf("drop %q table", drop.T.Name),
+	})
+	return nil
+}
+
+// modifyTable builds the statements that bring the table into its modified state.
+// DuckDB allows one action per ALTER TABLE statement, and supports only adding,
+// dropping, renaming and altering columns. Other changes (e.g. adding a foreign key
+// or a CHECK constraint) are applied by rewriting the table, similar to SQLite.
+func (s *state) modifyTable(modify *schema.ModifyTable) error {
+	if !alterable(modify) {
+		return s.rewriteTable(modify)
+	}
+	var drops, columns, adds []schema.Change
+	for _, change := range modify.Changes {
+		switch change := change.(type) {
+		case *schema.DropIndex:
+			drops = append(drops, change)
+		case *schema.ModifyIndex:
+			drops = append(drops, &schema.DropIndex{I: change.From})
+			adds = append(adds, &schema.AddIndex{I: change.To})
+		case *schema.AddIndex:
+			adds = append(adds, change)
+		default:
+			columns = append(columns, change)
+		}
+	}
+	t := modify.T
+	// Indexes are dropped first, as they may reference dropped
+	// columns, and created last, as they may reference added or
+	// renamed columns.
+	for _, change := range append(append(drops, columns...), adds...) {
+		switch change := change.(type) {
+		case *schema.AddIndex:
+			s.append(indexChange(change, t, change.I))
+		case *schema.DropIndex:
+			s.append(reverseChange(indexChange(change, t, change.I)))
+		case *schema.AddColumn:
+			b := Build("ALTER TABLE").Table(t).P("ADD COLUMN")
+			if err := s.column(b, change.C); err != nil {
+				return err
+			}
+			s.append(&migrate.Change{
+				Cmd:     b.String(),
+				Source:  change,
+				Comment: fmt.Sprintf("add column %q to table: %q", change.C.Name, t.Name),
+				Reverse: Build("ALTER TABLE").Table(t).P("DROP COLUMN").Ident(change.C.Name).String(),
+			})
+			if x := (schema.Comment{}); sqlx.Has(change.C.Attrs, &x) && x.Text != "" {
+				s.append(columnComment(change, t, change.C, x.Text, ""))
+			}
+		case *schema.DropColumn:
+			s.append(&migrate.Change{
+				Cmd:     Build("ALTER TABLE").Table(t).P("DROP COLUMN").Ident(change.C.Name).String(),
+				Source:  change,
+				Comment: fmt.Sprintf("drop column %q from table: %q", change.C.Name, t.Name),
+			})
+		case *schema.RenameColumn:
+			s.append(&migrate.Change{
+				Cmd:     Build("ALTER TABLE").Table(t).P("RENAME COLUMN").Ident(change.From.Name).P("TO").Ident(change.To.Name).String(),
+				Source:  change,
+				Reverse: Build("ALTER TABLE").Table(t).P("RENAME COLUMN").Ident(change.To.Name).P("TO").Ident(change.From.Name).String(),
+				Comment: fmt.Sprintf("rename column %q to %q in table: %q", change.From.Name, change.To.Name, t.Name),
+			})
+		case *schema.ModifyColumn:
+			if err := s.alterColumn(change, t); err != nil {
+				return err
+			}
+		case *schema.AddAttr, *schema.DropAttr, *schema.ModifyAttr:
+			c, err := tableAttrChange(t, change)
+			if err != nil {
+				return err
+			}
+			s.append(c)
+		default:
+			return fmt.Errorf("unexpected change in alter table: %T", change)
+		}
+	}
+	return nil
+}
+
+// alterColumn appends the ALTER COLUMN statements for modifying the column.
+func (s *state) alterColumn(change *schema.ModifyColumn, t *schema.Table) error {
+	from, to := change.From, change.To
+	alter := func(c *schema.Column) *sqlx.Builder {
+		return Build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(c.Name)
+	}
+	if change.Change.Is(schema.ChangeType) {
+		t1, err := FormatType(from.Type.Type)
+		if err != nil {
+			return err
+		}
+		t2, err := FormatType(to.Type.Type)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     alter(to).P("SET DATA TYPE", t2).String(),
+			Source:  change,
+			Comment: fmt.Sprintf("modify type of column %q in table: %q", to.Name, t.Name),
+			Reverse: alter(from).P("SET DATA TYPE", t1).String(),
+		})
+	}
+	if change.Change.Is(schema.ChangeDefault) {
+		s.append(&migrate.Change{
+			Cmd:     defaultClause(alter(to), to).String(),
+			Source:  change,
+			Comment: fmt.Sprintf("modify default value of column %q in table: %q", to.Name, t.Name),
+			Reverse: defaultClause(alter(from), from).String(),
+		})
+	}
+	if change.Change.Is(schema.ChangeNull) {
+		s.append(&migrate.Change{
+			Cmd:     nullClause(alter(to), to).String(),
+			Source:  change,
+			Comment: fmt.Sprintf("modify nullability of column %q in table: %q", to.Name, t.Name),
+			Reverse: nullClause(alter(from), from).String(),
+		})
+	}
+	if change.Change.Is(schema.ChangeComment) {
+		var c1, c2 schema.Comment
+		sqlx.Has(from.Attrs, &c1)
+		sqlx.Has(to.Attrs, &c2)
+		s.append(columnComment(change, t, to, c2.Text, c1.Text))
+	}
+	return nil
+}
+
+// rewriteTable applies the table modification using a new table: creating it with
+// a temporary name, copying the rows of the current table to it, dropping the current
+// table, renaming the new table to its real name and creating its indexes. Indexes
+// are created after the rename, as DuckDB does not allow renaming indexed tables.
+func (s *state) rewriteTable(modify *schema.ModifyTable) error {
+	newT := *modify.T
+	newT.Name = "new_" + newT.Name
+	indexes, err := s.createTable(&schema.AddTable{T: &newT}, &newT)
+	if err != nil {
+		return err
+	}
+	if err := s.copyRows(modify, &newT); err != nil {
+		return err
+	}
+	s.append(&migrate.Change{
+		Cmd:     Build("DROP TABLE").Table(modify.T).String(),
+		Source:  modify,
+		Comment: fmt.Sprintf("drop %q table after copying rows", modify.T.Name),
+	})
+	s.append(&migrate.Change{
+		Cmd:     Build("ALTER TABLE").Table(&newT).P("RENAME TO").Ident(modify.T.Name).String(),
+		Source:  modify,
+		Comment: fmt.Sprintf("rename temporary table %q to %q", newT.Name, modify.T.Name),
+	})
+	for _, idx := range indexes {
+		s.append(indexChange(&schema.AddIndex{I: idx}, modify.T, idx))
+	}
+	s.comments(modify, modify.T)
+	return nil
+}
+
+// copyRows appends the statement for copying the rows of the current table to
+// the new one. Columns that were changed from nullable to non-nullable with a
+// default value are copied with the default value in place of NULLs.
+func (s *state) copyRows(modify *schema.ModifyTable, to *schema.Table) error {
+	var (
+		changes    = modify.Changes
+		fromC, toC []string
+	)
+	for _, column := range to.Columns {
+		var (
+			change schema.Change
+			// The name of the column in the old table.
+			name = column.Name
+		)
+		for i := range changes {
+			switch c := changes[i].(type) {
+			case *schema.RenameColumn:
+				if c.To.Name == column.Name {
+					name = c.From.Name
+				}
+			case *schema.AddColumn:
+				if c.C.Name == column.Name {
+					change = c
+				}
+			case *schema.ModifyColumn:
+				if c.To.Name == column.Name {
+					change = c
+				}
+			}
+		}
+		switch change := change.(type) {
+		// New columns are filled with their default values.
+		case *schema.AddColumn:
+		case *schema.ModifyColumn:
+			toC = append(toC, ident(column.Name))
+			if x, ok := defaultValue(column); ok && !column.Type.Null && change.Change.Is(schema.ChangeNull) {
+				fromC = append(fromC, fmt.Sprintf("COALESCE(%s, %s) AS %s", ident(name), x, ident(column.Name)))
+			} else {
+				fromC = append(fromC, ident(name))
+			}
+		default:
+			toC = append(toC, ident(column.Name))
+			fromC = append(fromC, ident(name))
+		}
+	}
+	s.append(&migrate.Change{
+		Cmd: Build("INSERT INTO").Table(to).P("("+strings.Join(toC, ", ")+")").
+			P("SELECT", strings.Join(fromC, ", "), "FROM").Table(modify.T).String(),
+		Source:  modify,
+		Comment: fmt.Sprintf("copy rows from old table %q to new temporary table %q", modify.T.Name, to.Name),
+	})
+	return nil
+}
+
+// alterable reports if the table modification can be applied using
+// ALTER TABLE, CREATE INDEX and COMMENT statements, instead of
+// rewriting the table.
+func alterable(modify *schema.ModifyTable) bool {
+	for _, change := range modify.Changes {
+		switch change := change.(type) {
+		case *schema.AddIndex:
+			if sqlx.Has(change.I.Attrs, &UniqueConstraint{}) {
+				return false
+			}
+		case *schema.DropIndex:
+			if sqlx.Has(change.I.Attrs, &UniqueConstraint{}) {
+				return false
+			}
+		case *schema.ModifyIndex:
+			if sqlx.Has(change.From.Attrs, &UniqueConstraint{}) || sqlx.Has(change.To.Attrs, &UniqueConstraint{}) {
+				return false
+			}
+		// Columns are added without constraints, and
+		// they must be nullable or have a default value.
+		case *schema.AddColumn:
+			if !change.C.Type.Null && change.C.Default == nil {
+				return false
+			}
+		// Columns that are referenced by constraints cannot be dropped.
+		case *schema.DropColumn:
+			if len(change.C.Indexes) > 0 || len(change.C.ForeignKeys) > 0 {
+				return false
+			}
+		case *schema.RenameColumn, *schema.ModifyColumn:
+		case *schema.AddAttr:
+			if _, ok := change.A.(*schema.Comment); !ok {
+				return false
+			}
+		case *schema.DropAttr:
+			if _, ok := change.A.(*schema.Comment); !ok {
+				return false
+			}
+		case *schema.ModifyAttr:
+			if _, ok := change.To.(*schema.Comment); !ok {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// column writes the definition of the column to the builder.
+func (s *state) column(b *sqlx.Builder, c *schema.Column) error {
+	t, err := FormatType(c.Type.Type)
+	if err != nil {
+		return err
+	}
+	b.Ident(c.Name).P(t)
+	if !c.Type.Null {
+		b.P("NOT NULL")
+	}
+	if x, ok := defaultValue(c); ok {
+		b.P("DEFAULT", x)
+	}
+	for _, attr := range c.Attrs {
+		switch attr.(type) {
+		case *schema.Comment:
+		default:
+			return fmt.Errorf("unexpected attribute %T for column %q", attr, c.Name)
+		}
+	}
+	return nil
+}
+
+// fk writes the definition of the foreign key to the builder. DuckDB does not
+// support referential actions, and foreign keys behave as "NO ACTION".
+func (s *state) fk(b *sqlx.Builder, fk *schema.ForeignKey) error {
+	for _, a := range []schema.ReferenceOption{fk.OnUpdate, fk.OnDelete} {
+		if a != "" && a != schema.NoAction && a != schema.Restrict {
+			return fmt.Errorf("foreign key %q: unsupported action %q", fk.Symbol, a)
+		}
+	}
+	b.P("FOREIGN KEY")
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(fk.Columns, func(i int, b *sqlx.Builder) {
+			b.Ident(fk.Columns[i].Name)
+		})
+	})
+	b.P("REFERENCES").Table(fk.RefTable)
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(fk.RefColumns, func(i int, b *sqlx.Builder) {
+			b.Ident(fk.RefColumns[i].Name)
+		})
+	})
+	return nil
+}
+
+// check writes the CHECK constraint to the builder. Constraint
+// names are not kept by DuckDB, and therefore, are not written.
+func check(b *sqlx.Builder, c *schema.Check) {
+	expr := c.Expr
+	// Expressions should be wrapped with parens.
+	if t := strings.TrimSpace(expr); !strings.HasPrefix(t, "(") || !strings.HasSuffix(t, ")") {
+		expr = "(" + t + ")"
+	}
+	b.P("CHECK", expr)
+}
+
+// indexChange returns the statement for creating the given index.
+// The reverse statement drops it.
+func indexChange(source schema.Change, t *schema.Table, idx *schema.Index) *migrate.Change {
+	b := Build("CREATE")
+	if idx.Unique {
+		b.P("UNIQUE")
+	}
+	b.P("INDEX").Ident(idx.Name).P("ON").Table(t)
+	indexParts(b, idx.Parts)
+	return &migrate.Change{
+		Cmd:     b.String(),
+		Source:  source,
+		Comment: fmt.Sprintf("create index %q to table: %q", idx.Name, t.Name),
+		Reverse: Build("DROP INDEX").P(object(t.Schema, idx.Name)).String(),
+	}
+}
+
+func indexParts(b *sqlx.Builder, parts []*schema.IndexPart) {
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(parts, func(i int, b *sqlx.Builder) {
+			switch part := parts[i]; {
+			case part.C != nil:
+				b.Ident(part.C.Name)
+			case part.X != nil:
+				// Expressions should be wrapped with parens.
+				x := strings.TrimSpace(part.X.(*schema.RawExpr).X)
+				if !strings.HasPrefix(x, "(") || !strings.HasSuffix(x, ")") {
+					x = "(" + x + ")"
+				}
+				b.WriteString(x)
+			}
+			if parts[i].Desc {
+				b.P("DESC")
+			}
+		})
+	})
+}
+
+// tableAttrChange returns the statement for changing a table attribute.
+func tableAttrChange(t *schema.Table, change schema.Change) (*migrate.Change, error) {
+	var from, to schema.Attr
+	switch change := change.(type) {
+	case *schema.AddAttr:
+		to = change.A
+	case *schema.DropAttr:
+		from = change.A
+	case *schema.ModifyAttr:
+		from, to = change.From, change.To
+	}
+	var c1, c2 string
+	for _, a := range []struct {
+		attr schema.Attr
+		text *string
+	}{{from, &c1}, {to, &c2}} {
+		switch c := a.attr.(type) {
+		case nil:
+		case *schema.Comment:
+			*a.text = c.Text
+		default:
+			return nil, fmt.Errorf("unsupported table attribute change %T", change)
+		}
+	}
+	return tableComment(change, t, c2, c1), nil
+}
+
+// tableComment returns the statement for changing the comment of a table.
+func tableComment(source schema.Change, t *schema.Table, to, from string) *migrate.Change {
+	b := Build("COMMENT ON TABLE").Table(t).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(commentValue(to)).String(),
+		Source:  source,
+		Comment: fmt.Sprintf("set comment to table: %q", t.Name),
+		Reverse: b.Clone().P(commentValue(from)).String(),
+	}
+}
+
+// columnComment returns the statement for changing the comment of a column.
+func columnComment(source schema.Change, t *schema.Table, c *schema.Column, to, from string) *migrate.Change {
+	b := Build("COMMENT ON COLUMN").P(object(t.Schema, t.Name)+"."+ident(c.Name), "IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(commentValue(to)).String(),
+		Source:  source,
+		Comment: fmt.Sprintf("set comment to column %q of table: %q", c.Name, t.Name),
+		Reverse: b.Clone().P(commentValue(from)).String(),
+	}
+}
+
+// commentValue returns the value of a COMMENT ON statement.
+func commentValue(text string) string {
+	if text == "" {
+		return "NULL"
+	}
+	return quote(text)
+}
+
+// defaultClause writes the SET or DROP DEFAULT clause of the column to the builder.
+func defaultClause(b *sqlx.Builder, c *schema.Column) *sqlx.Builder {
+	if x, ok := defaultValue(c); ok {
+		return b.P("SET DEFAULT", x)
+	}
+	return b.P("DROP DEFAULT")
+}
+
+// nullClause writes the SET or DROP NOT NULL clause of the column to the builder.
+func nullClause(b *sqlx.Builder, c *schema.Column) *sqlx.Builder {
+	if c.Type.Null {
+		return b.P("DROP NOT NULL")
+	}
+	return b.P("SET NOT NULL")
+}
+
+// defaultValue returns the DEFAULT clause of the column, if it has one.
+func defaultValue(c *schema.Column) (string, bool) {
+	switch x := c.Default.(type) {
+	case *schema.Literal:
+		switch c.Type.Type.(type) {
+		case *schema.BoolType, *schema.DecimalType, *schema.IntegerType, *schema.FloatType:
+			return x.V, true
+		default:
+			return quote(x.V), true
+		}
+	case *schema.RawExpr:
+		return x.X, true
+	default:
+		return "", false
+	}
+}
+
+// reverseChange returns a change that executes the
+// reverse statement of c, and reverses it with c.
+func reverseChange(c *migrate.Change) *migrate.Change {
+	comment := c.Comment
+	if i := strings.IndexByte(comment, ' '); i != -1 && comment[:i] == "create" {
+		comment = "drop" + comment[i:]
+	}
+	return &migrate.Change{
+		Cmd:     c.Reverse,
+		Source:  c.Source,
+		Comment: strings.Replace(comment, " to table: ", " from table: ", 1),
+		Reverse: c.Cmd,
+	}
+}
+
+func (s *state) append(c ...*migrate.Change) {
+	s.Changes = append(s.Changes, c...)
+}
+
+// Build instantiates a new builder and writes the given phrase to it.
+func Build(phrase string) *sqlx.Builder {
+	b := &sqlx.Builder{QuoteChar: '"'}
+	return b.P(phrase)
+}
+
+// object returns the name of a schema object (e.g. an index),
+// qualified with the schema name if it is known.
+func object(s *schema.Schema, name string) string {
+	if s != nil && s.Name != "" {
+		return ident(s.Name) + "." + ident(name)
+	}
+	return ident(name)
+}
+
+// ident returns the quoted form of the given identifier.
+func ident(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func quote(s string) string {
+	if sqlx.IsQuoted(s, '\'') {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,250 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package duckdb
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChanges(t *testing.T) {
+	main := &schema.Schema{Name: "main"}
+	users := &schema.Table{
+		Name:   "users",
+		Schema: main,
+		Columns: []*schema.Column{
+			{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeBigInt}}},
+			{Name: "email", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar}}},
+			{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar}, Null: true}, Attrs: []schema.Attr{&schema.Comment{Text: "user's name"}}},
+		},
+		Attrs: []schema.Attr{&schema.Comment{Text: "app users"}, &schema.Check{Expr: "length(email) > 3"}},
+	}
+	users.PrimaryKey = &schema.Index{Parts: []*schema.IndexPart{{C: users.Columns[0]}}}
+	users.Indexes = []*schema.Index{
+		{Name: "users_email_key", Unique: true, Table: users, Parts: []*schema.IndexPart{{C: users.Columns[1]}}, Attrs: []schema.Attr{&UniqueConstraint{}}},
+		{Name: "users_name", Table: users, Parts: []*schema.IndexPart{{C: users.Columns[2]}}},
+	}
+	events := &schema.Table{
+		Name:   "events",
+		Schema: main,
+		Columns: []*schema.Column{
+			{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeBigInt}}},
+			{Name: "user_id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeBigInt}, Null: true}},
+			{Name: "kind", Type: &schema.ColumnType{Type: &schema.EnumType{Values: []string{"click", "view"}}}, Default: &schema.Literal{V: "click"}},
+		},
+	}
+	events.PrimaryKey = &schema.Index{Parts: []*schema.IndexPart{{C: events.Columns[0]}}}
+	events.ForeignKeys = []*schema.ForeignKey{
+		{Symbol: "events_user_id_fkey", Table: events, Columns: events.Columns[1:2], RefTable: users, RefColumns: users.Columns[:1]},
+	}
+
+	tests := []struct {
+		changes  []schema.Change
+		wantPlan *migrate.Plan
+		wantErr  bool
+	}{
+		{
+			changes: []schema.Change{&schema.AddSchema{S: &schema.Schema{Name: "stage"}, Extra: []schema.Clause{&schema.IfNotExists{}}}},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE SCHEMA IF NOT EXISTS "stage"`,
+						Reverse: `DROP SCHEMA "stage"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.DropSchema{S: &schema.Schema{Name: "stage"}}},
+			wantPlan: &migrate.Plan{
+				Changes: []*migrate.Change{
+					{Cmd: `DROP SCHEMA "stage" CASCADE`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: users}, &schema.AddTable{T: events}},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "main"."users" ("id" BIGINT NOT NULL, "email" VARCHAR NOT NULL, "name" VARCHAR, PRIMARY KEY ("id"), UNIQUE ("email"), CHECK (length(email) > 3))`,
+						Reverse: `DROP TABLE "main"."users"`,
+					},
+					{
+						Cmd:     `CREATE INDEX "users_name" ON "main"."users" ("name")`,
+						Reverse: `DROP INDEX "main"."users_name"`,
+					},
+					{
+						Cmd:     `COMMENT ON TABLE "main"."users" IS 'app users'`,
+						Reverse: `COMMENT ON TABLE "main"."users" IS NULL`,
+					},
+					{
+						Cmd:     `COMMENT ON COLUMN "main"."users"."name" IS 'user''s name'`,
+						Reverse: `COMMENT ON COLUMN "main"."users"."name" IS NULL`,
+					},
+					{
+						Cmd:     `CREATE TABLE "main"."events" ("id" BIGINT NOT NULL, "user_id" BIGINT, "kind" ENUM('click', 'view') NOT NULL DEFAULT 'click', PRIMARY KEY ("id"), FOREIGN KEY ("user_id") REFERENCES "main"."users" ("id"))`,
+						Reverse: `DROP TABLE "main"."events"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.DropIndex{I: users.Indexes[1]},
+						&schema.AddColumn{C: &schema.Column{Name: "age", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeInteger}, Null: true}}},
+						&schema.RenameColumn{From: &schema.Column{Name: "nick"}, To: users.Columns[2]},
+						&schema.ModifyColumn{
+							From:   &schema.Column{Name: "email", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar}, Null: true}},
+							To:     &schema.Column{Name: "email", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar}}, Default: &schema.Literal{V: "unknown"}},
+							Change: schema.ChangeNull | schema.ChangeDefault,
+						},
+						&schema.ModifyAttr{From: &schema.Comment{Text: "users"}, To: &schema.Comment{Text: "app users"}},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DROP INDEX "main"."users_name"`,
+						Reverse: `CREATE INDEX "users_name" ON "main"."users" ("name")`,
+					},
+					{
+						Cmd:     `ALTER TABLE "main"."users" ADD COLUMN "age" INTEGER`,
+						Reverse: `ALTER TABLE "main"."users" DROP COLUMN "age"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "main"."users" RENAME COLUMN "nick" TO "name"`,
+						Reverse: `ALTER TABLE "main"."users" RENAME COLUMN "name" TO "nick"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "main"."users" ALTER COLUMN "email" SET DEFAULT 'unknown'`,
+						Reverse: `ALTER TABLE "main"."users" ALTER COLUMN "email" DROP DEFAULT`,
+					},
+					{
+						Cmd:     `ALTER TABLE "main"."users" ALTER COLUMN "email" SET NOT NULL`,
+						Reverse: `ALTER TABLE "main"."users" ALTER COLUMN "email" DROP NOT NULL`,
+					},
+					{
+						Cmd:     `COMMENT ON TABLE "main"."users" IS 'app users'`,
+						Reverse: `COMMENT ON TABLE "main"."users" IS 'users'`,
+					},
+				},
+			},
+		},
+		// Adding a foreign key requires rewriting the table.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: events,
+					Changes: []schema.Change{
+						&schema.AddForeignKey{F: events.ForeignKeys[0]},
+						&schema.ModifyColumn{
+							From:   &schema.Column{Name: "kind", Type: &schema.ColumnType{Type: &schema.EnumType{Values: []string{"click", "view"}}, Null: true}},
+							To:     events.Columns[2],
+							Change: schema.ChangeNull | schema.ChangeDefault,
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "main"."new_events" ("id" BIGINT NOT NULL, "user_id" BIGINT, "kind" ENUM('click', 'view') NOT NULL DEFAULT 'click', PRIMARY KEY ("id"), FOREIGN KEY ("user_id") REFERENCES "main"."users" ("id"))`,
+						Reverse: `DROP TABLE "main"."new_events"`,
+					},
+					{
+						Cmd: `INSERT INTO "main"."new_events" ("id", "user_id", "kind") SELECT "id", "user_id", COALESCE("kind", 'click') AS "kind" FROM "main"."events"`,
+					},
+					{
+						Cmd: `DROP TABLE "main"."events"`,
+					},
+					{
+						Cmd: `ALTER TABLE "main"."new_events" RENAME TO "events"`,
+					},
+				},
+			},
+		},
+		// Dropping a UNIQUE constraint requires rewriting the table.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: &schema.Table{Name: "t", Schema: main, Columns: users.Columns[:1]},
+					Changes: []schema.Change{
+						&schema.DropIndex{I: users.Indexes[0]},
+						&schema.DropColumn{C: users.Columns[1]},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "main"."new_t" ("id" BIGINT NOT NULL)`,
+						Reverse: `DROP TABLE "main"."new_t"`,
+					},
+					{
+						Cmd: `INSERT INTO "main"."new_t" ("id") SELECT "id" FROM "main"."t"`,
+					},
+					{
+						Cmd: `DROP TABLE "main"."t"`,
+					},
+					{
+						Cmd: `ALTER TABLE "main"."new_t" RENAME TO "t"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: &schema.Table{
+						Name:    "t",
+						Columns: events.Columns[1:2],
+						ForeignKeys: []*schema.ForeignKey{
+							{Symbol: "fk", Columns: events.Columns[1:2], RefTable: users, RefColumns: users.Columns[:1], OnDelete: schema.Cascade},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			db, m, err := sqlmock.New()
+			require.NoError(t, err)
+			mock{m}.params()
+			drv, err := Open(db)
+			require.NoError(t, err)
+			plan, err := drv.PlanChanges(context.Background(), "plan", tt.changes)
+			if tt.wantErr {
+				require.Error(t, err, "expect plan to fail")
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, plan)
+			require.True(t, plan.Transactional)
+			require.Equal(t, tt.wantPlan.Reversible, plan.Reversible)
+			require.Len(t, plan.Changes, len(tt.wantPlan.Changes))
+			for i, c := range plan.Changes {
+				require.Equal(t, tt.wantPlan.Changes[i].Cmd, c.Cmd)
+				require.Equal(t, tt.wantPlan.Changes[i].Reverse, c.Reverse)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package entconv
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+)
+
+// ExecQuerier wraps an Ent dialect.ExecQuerier (e.g. a dialect.Driver or a dialect.Tx),
+// and implements the schema.ExecQuerier interface. It allows executing Atlas operations
+// through Ent drivers, including the drivers that wrap them with hooks or tracing (e.g.
+// dialect.Debug), instead of opening a separate *sql.DB.
+//
+//	drv, err := postgres.Open(entconv.NewExecQuerier(client.Driver()))
+//	if err != nil {
+//		return err
+//	}
+type ExecQuerier struct {
+	drv dialect.ExecQuerier
+}
+
+// NewExecQuerier returns a schema.ExecQuerier that executes its statements on the given Ent driver.
+func NewExecQuerier(drv dialect.ExecQuerier) *ExecQuerier {
+	return &ExecQuerier{drv: drv}
+}
+
+// ExecContext implements the schema.ExecQuerier interface.
+func (e *ExecQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var res sql.Result
+	if err := e.drv.Exec(ctx, query, args, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// QueryContext implements the schema.ExecQuerier interface.
+func (e *ExecQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	var rows entsql.Rows
+	if err := e.drv.Query(ctx, query, args, &rows); err != nil {
+		return nil, err
+	}
+	if rows.Rows == nil {
+		return nil, fmt.Errorf("entconv: no rows were returned by the driver for query %q", query)
+	}
+	return rows.Rows, nil
+}
+
+// Open opens an Atlas driver that executes its statements on the given Ent driver. The
+// Atlas driver is selected by the dialect of the Ent driver. In order to configure the
+// Atlas driver with dialect-specific options, use NewExecQuerier with the driver package.
+//
+//	drv, err := entconv.Open(client.Driver())
+//	if err != nil {
+//		return err
+//	}
+//	if err := drv.ApplyChanges(ctx, changes); err != nil {
+//		return err
+//	}
+func Open(drv dialect.Driver) (migrate.Driver, error) {
+	var (
+		err error
+		d   migrate.Driver
+		eq  schema.ExecQuerier = NewExecQuerier(drv)
+	)
+	switch drv.Dialect() {
+	case dialect.MySQL:
+		d, err = mysql.Open(eq)
+	case dialect.Postgres:
+		d, err = postgres.Open(eq)
+	case dialect.SQLite:
+		d, err = sqlite.Open(eq)
+	default:
+		return nil, fmt.Errorf("entconv: unsupported dialect %q", drv.Dialect())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,476 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package entconv converts between Ent schema definitions and Atlas schema graphs,
+// and bridges Ent drivers to the Atlas drivers.
+//
+// The package lives in its own module in order to keep the main Atlas module free
+// of the Ent dependency.
+package entconv
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+
+	"entgo.io/ent/dialect"
+	entschema "entgo.io/ent/dialect/sql/schema"
+	"entgo.io/ent/schema/field"
+)
+
+// FromEnt converts the given Ent tables to an Atlas schema with
+// the given name. The column types are resolved using the type
+// mapping that is used by Ent for the given dialect.
+func FromEnt(d, name string, tables []*entschema.Table) (*schema.Schema, error) {
+	parse, err := parser(d)
+	if err != nil {
+		return nil, err
+	}
+	s := schema.New(name)
+	for _, et := range tables {
+		t := schema.NewTable(et.Name)
+		s.AddTables(t)
+		for _, ec := range et.Columns {
+			c, err := fromColumn(d, parse, ec)
+			if err != nil {
+				return nil, fmt.Errorf("entconv: table %q: %w", et.Name, err)
+			}
+			t.AddColumns(c)
+		}
+		if len(et.PrimaryKey) > 0 {
+			pk, err := columns(t, et.PrimaryKey)
+			if err != nil {
+				return nil, err
+			}
+			t.SetPrimaryKey(schema.NewPrimaryKey(pk...))
+		}
+		for _, ei := range et.Indexes {
+			cs, err := columns(t, ei.Columns)
+			if err != nil {
+				return nil, err
+			}
+			idx := schema.NewIndex(ei.Name).SetUnique(ei.Unique).AddColumns(cs...)
+			t.AddIndexes(idx)
+		}
+	}
+	// Foreign keys are linked after all tables were created, as
+	// they may reference tables that appear later in the list.
+	for _, et := range tables {
+		t, _ := s.Table(et.Name)
+		for _, efk := range et.ForeignKeys {
+			if efk.RefTable == nil {
+				return nil, fmt.Errorf("entconv: missing referenced table for foreign key %q", efk.Symbol)
+			}
+			ref, ok := s.Table(efk.RefTable.Name)
+			if !ok {
+				return nil, fmt.Errorf("entconv: referenced table %q was not found", efk.RefTable.Name)
+			}
+			cs, err := columns(t, efk.Columns)
+			if err != nil {
+				return nil, err
+			}
+			refs, err := columns(ref, efk.RefColumns)
+			if err != nil {
+				return nil, err
+			}
+			t.AddForeignKeys(
+				schema.NewForeignKey(efk.Symbol).
+					AddColumns(cs...).
+					SetRefTable(ref).
+					AddRefColumns(refs...).
+					SetOnUpdate(schema.ReferenceOption(efk.OnUpdate)).
+					SetOnDelete(schema.ReferenceOption(efk.OnDelete)),
+			)
+		}
+	}
+	return s, nil
+}
+
+// ToEnt converts the tables of the given Atlas schema to Ent tables.
+// Column types that cannot be represented using the builtin Ent field
+// types are preserved as field.TypeOther with their SchemaType set
+// for the given dialect.
+func ToEnt(d string, s *schema.Schema) ([]*entschema.Table, error) {
+	format, err := formatter(d)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		tables = make([]*entschema.Table, 0, len(s.Tables))
+		byName = make(map[string]*entschema.Table, len(s.Tables))
+	)
+	for _, t := range s.Tables {
+		et := entschema.NewTable(t.Name)
+		pk := make(map[string]bool)
+		if t.PrimaryKey != nil {
+			for _, p := range t.PrimaryKey.Parts {
+				if p.C == nil {
+					return nil, fmt.Errorf("entconv: expression primary key in table %q is not supported", t.Name)
+				}
+				pk[p.C.Name] = true
+			}
+		}
+		for _, c := range t.Columns {
+			ec, err := toColumn(d, format, c)
+			if err != nil {
+				return nil, fmt.Errorf("entconv: table %q: %w", t.Name, err)
+			}
+			if pk[c.Name] {
+				et.AddPrimary(ec)
+			} else {
+				et.AddColumn(ec)
+			}
+		}
+		for _, idx := range t.Indexes {
+			names := make([]string, 0, len(idx.Parts))
+			for _, p := range idx.Parts {
+				if p.C == nil {
+					return nil, fmt.Errorf("entconv: expression index %q in table %q is not supported", idx.Name, t.Name)
+				}
+				names = append(names, p.C.Name)
+			}
+			et.AddIndex(idx.Name, idx.Unique, names)
+		}
+		tables = append(tables, et)
+		byName[t.Name] = et
+	}
+	for i, t := range s.Tables {
+		et := tables[i]
+		for _, fk := range t.ForeignKeys {
+			ref, ok := byName[fk.RefTable.Name]
+			if !ok {
+				return nil, fmt.Errorf("entconv: foreign key %q references table %q from another schema", fk.Symbol, fk.RefTable.Name)
+			}
+			efk := &entschema.ForeignKey{
+				Symbol:   fk.Symbol,
+				RefTable: ref,
+				OnUpdate: entschema.ReferenceOption(fk.OnUpdate),
+				OnDelete: entschema.ReferenceOption(fk.OnDelete),
+			}
+			for _, c := range fk.Columns {
+				efk.Columns = append(efk.Columns, entColumn(et, c.Name))
+			}
+			for _, c := range fk.RefColumns {
+				efk.RefColumns = append(efk.RefColumns, entColumn(ref, c.Name))
+			}
+			et.AddForeignKey(efk)
+		}
+	}
+	return tables, nil
+}
+
+// fromColumn converts an Ent column to an Atlas column.
+func fromColumn(d string, parse func(string) (schema.Type, error), ec *entschema.Column) (*schema.Column, error) {
+	raw, err := entType(d, ec)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse type %q of column %q: %w", raw, ec.Name, err)
+	}
+	c := &schema.Column{
+		Name: ec.Name,
+		Type: &schema.ColumnType{Type: t, Raw: raw, Null: ec.Nullable},
+	}
+	if ec.Default != nil {
+		switch v := ec.Default.(type) {
+		case string:
+			c.Default = &schema.Literal{V: "'" + strings.ReplaceAll(v, "'", "''") + "'"}
+		case bool:
+			c.Default = &schema.Literal{V: strconv.FormatBool(v)}
+		default:
+			c.Default = &schema.Literal{V: fmt.Sprint(v)}
+		}
+	}
+	if ec.Collation != "" {
+		c.SetCollation(ec.Collation)
+	}
+	if ec.Increment {
+		switch d {
+		case dialect.MySQL:
+			c.AddAttrs(&mysql.AutoIncrement{})
+		case dialect.Postgres:
+			c.AddAttrs(&postgres.Identity{Generation: "BY DEFAULT"})
+		case dialect.SQLite:
+			c.AddAttrs(&sqlite.AutoIncrement{})
+		}
+	}
+	return c, nil
+}
+
+// entType returns the database type of the Ent column as it is
+// defined by Ent when creating the column in the given dialect.
+func entType(d string, c *entschema.Column) (string, error) {
+	if t := c.SchemaType[d]; t != "" {
+		return strings.ToLower(t), nil
+	}
+	if types, ok := entTypes[d]; ok {
+		if t, ok := types[c.Type]; ok {
+			return t, nil
+		}
+	}
+	switch {
+	case c.Type == field.TypeString && d == dialect.MySQL:
+		if c.Size == 0 {
+			return fmt.Sprintf("varchar(%d)", entschema.DefaultStringLen), nil
+		}
+		if c.Size <= math.MaxUint16 {
+			return fmt.Sprintf("varchar(%d)", c.Size), nil
+		}
+		return mysql.TypeLongText, nil
+	case c.Type == field.TypeString && d == dialect.Postgres:
+		if c.Size > 10<<20 {
+			return postgres.TypeText, nil
+		}
+		return postgres.TypeVarChar, nil
+	case c.Type == field.TypeBytes && d == dialect.MySQL:
+		switch size := c.Size; {
+		case size == 0 || size > math.MaxUint8 && size <= math.MaxUint16:
+			return mysql.TypeBlob, nil
+		case size <= math.MaxUint8:
+			return mysql.TypeTinyBlob, nil
+		case size < 1<<24:
+			return mysql.TypeMediumBlob, nil
+		default:
+			return mysql.TypeLongBlob, nil
+		}
+	case c.Type == field.TypeEnum && d == dialect.MySQL:
+		values := make([]string, len(c.Enums))
+		for i, e := range c.Enums {
+			values[i] = "'" + e + "'"
+		}
+		return fmt.Sprintf("enum(%s)", strings.Join(values, ",")), nil
+	}
+	return "", fmt.Errorf("unsupported type %q for column %q", c.Type, c.Name)
+}
+
+// toColumn converts an Atlas column to an Ent column.
+func toColumn(d string, format func(schema.Type) (string, error), c *schema.Column) (*entschema.Column, error) {
+	ec := &entschema.Column{
+		Name:     c.Name,
+		Nullable: c.Type.Null,
+	}
+	switch t := c.Type.Type.(type) {
+	case *schema.BoolType:
+		ec.Type = field.TypeBool
+	case *schema.IntegerType:
+		ec.Type = intType(d, t)
+	case *schema.FloatType:
+		ec.Type = field.TypeFloat64
+		if t.T == postgres.TypeReal || t.T == postgres.TypeFloat4 {
+			ec.Type = field.TypeFloat32
+		}
+	case *schema.StringType:
+		ec.Type, ec.Size = field.TypeString, int64(t.Size)
+	case *schema.BinaryType:
+		ec.Type, ec.Size = field.TypeBytes, int64(t.Size)
+	case *schema.EnumType:
+		ec.Type, ec.Enums = field.TypeEnum, t.Values
+	case *schema.JSONType:
+		ec.Type = field.TypeJSON
+	case *schema.TimeType:
+		ec.Type = field.TypeTime
+	case *postgres.UUIDType, *sqlite.UUIDType:
+		ec.Type = field.TypeUUID
+	case *postgres.SerialType:
+		ec.Type, ec.Increment = field.TypeInt64, true
+		if t.T == postgres.TypeSmallSerial || t.T == postgres.TypeSerial2 {
+			ec.Type = field.TypeInt16
+		} else if t.T == postgres.TypeSerial || t.T == postgres.TypeSerial4 {
+			ec.Type = field.TypeInt32
+		}
+	default:
+		ec.Type = field.TypeOther
+	}
+	// Keep the exact database type in case it cannot be
+	// inferred from the Ent type (e.g. DECIMAL or CHAR).
+	raw, err := format(c.Type.Type)
+	if err != nil {
+		return nil, fmt.Errorf("format type of column %q: %w", c.Name, err)
+	}
+	if et, err := entType(d, ec); err != nil || et != raw {
+		ec.SchemaType = map[string]string{d: raw}
+	}
+	if x, ok := c.Default.(*schema.Literal); ok {
+		ec.Default = defaultValue(ec.Type, x.V)
+	}
+	for _, a := range c.Attrs {
+		switch a := a.(type) {
+		case *schema.Collation:
+			ec.Collation = a.V
+		case *mysql.AutoIncrement, *postgres.Identity, *sqlite.AutoIncrement:
+			ec.Increment = true
+		}
+	}
+	return ec, nil
+}
+
+// defaultValue converts a literal default value to its Ent representation.
+func defaultValue(t field.Type, v string) interface{} {
+	if len(v) > 1 && (v[0] == '\'' || v[0] == '"') && v[len(v)-1] == v[0] {
+		return strings.ReplaceAll(v[1:len(v)-1], string(v[0])+string(v[0]), string(v[0]))
+	}
+	switch {
+	case t == field.TypeBool:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	case t.Integer():
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i
+		}
+	case t.Numeric():
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+// intType returns the Ent type of the given integer type.
+func intType(d string, t *schema.IntegerType) field.Type {
+	// All integers in SQLite are stored as 64-bit signed integers.
+	if d == dialect.SQLite {
+		return field.TypeInt64
+	}
+	var typ field.Type
+	switch strings.ToLower(t.T) {
+	case mysql.TypeTinyInt:
+		typ = field.TypeInt8
+	case mysql.TypeSmallInt, postgres.TypeInt2:
+		typ = field.TypeInt16
+	case mysql.TypeMediumInt, mysql.TypeInt, postgres.TypeInteger, postgres.TypeInt4:
+		typ = field.TypeInt32
+	default:
+		typ = field.TypeInt64
+	}
+	if t.Unsigned {
+		// Unsigned types follow their signed types in the field.Type enum.
+		typ += field.TypeUint8 - field.TypeInt8
+	}
+	return typ
+}
+
+// columns returns the Atlas columns matching the given Ent columns.
+func columns(t *schema.Table, ecs []*entschema.Column) ([]*schema.Column, error) {
+	cs := make([]*schema.Column, 0, len(ecs))
+	for _, ec := range ecs {
+		c, ok := t.Column(ec.Name)
+		if !ok {
+			return nil, fmt.Errorf("entconv: column %q was not found in table %q", ec.Name, t.Name)
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
+
+// entColumn returns the Ent column with the given name.
+func entColumn(t *entschema.Table, name string) *entschema.Column {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// parser returns the type parser of the given dialect.
+func parser(d string) (func(string) (schema.Type, error), error) {
+	switch d {
+	case dialect.MySQL:
+		return mysql.ParseType, nil
+	case dialect.Postgres:
+		return postgres.ParseType, nil
+	case dialect.SQLite:
+		return sqlite.ParseType, nil
+	default:
+		return nil, fmt.Errorf("entconv: unsupported dialect %q", d)
+	}
+}
+
+// formatter returns the type formatter of the given dialect.
+func formatter(d string) (func(schema.Type) (string, error), error) {
+	switch d {
+	case dialect.MySQL:
+		return mysql.FormatType, nil
+	case dialect.Postgres:
+		return postgres.FormatType, nil
+	case dialect.SQLite:
+		return sqlite.FormatType, nil
+	default:
+		return nil, fmt.Errorf("entconv: unsupported dialect %q", d)
+	}
+}
+
+// entTypes holds the static type mapping that is used by Ent
+// for each dialect. Types with dynamic mapping (based on size,
+// for example) are handled by the entType function.
+var entTypes = map[string]map[field.Type]string{
+	dialect.MySQL: {
+		field.TypeBool:    mysql.TypeBoolean,
+		field.TypeInt8:    mysql.TypeTinyInt,
+		field.TypeUint8:   "tinyint unsigned",
+		field.TypeInt16:   mysql.TypeSmallInt,
+		field.TypeUint16:  "smallint unsigned",
+		field.TypeInt32:   mysql.TypeInt,
+		field.TypeUint32:  "int unsigned",
+		field.TypeInt:     mysql.TypeBigInt,
+		field.TypeInt64:   mysql.TypeBigInt,
+		field.TypeUint:    "bigint unsigned",
+		field.TypeUint64:  "bigint unsigned",
+		field.TypeFloat32: mysql.TypeDouble,
+		field.TypeFloat64: mysql.TypeDouble,
+		field.TypeJSON:    mysql.TypeJSON,
+		field.TypeTime:    mysql.TypeTimestamp,
+		field.TypeUUID:    "char(36)",
+	},
+	dialect.Postgres: {
+		field.TypeBool:    postgres.TypeBoolean,
+		field.TypeInt8:    postgres.TypeSmallInt,
+		field.TypeUint8:   postgres.TypeSmallInt,
+		field.TypeInt16:   postgres.TypeSmallInt,
+		field.TypeUint16:  postgres.TypeSmallInt,
+		field.TypeInt32:   postgres.TypeInteger,
+		field.TypeUint32:  postgres.TypeInteger,
+		field.TypeInt:     postgres.TypeBigInt,
+		field.TypeInt64:   postgres.TypeBigInt,
+		field.TypeUint:    postgres.TypeBigInt,
+		field.TypeUint64:  postgres.TypeBigInt,
+		field.TypeFloat32: postgres.TypeReal,
+		field.TypeFloat64: postgres.TypeDouble,
+		field.TypeBytes:   postgres.TypeBytea,
+		field.TypeJSON:    postgres.TypeJSONB,
+		field.TypeUUID:    postgres.TypeUUID,
+		field.TypeTime:    postgres.TypeTimestampWTZ,
+		field.TypeEnum:    postgres.TypeVarChar,
+	},
+	dialect.SQLite: {
+		field.TypeBool:    "bool",
+		field.TypeInt8:    sqlite.TypeInteger,
+		field.TypeUint8:   sqlite.TypeInteger,
+		field.TypeInt16:   sqlite.TypeInteger,
+		field.TypeUint16:  sqlite.TypeInteger,
+		field.TypeInt32:   sqlite.TypeInteger,
+		field.TypeUint32:  sqlite.TypeInteger,
+		field.TypeInt:     sqlite.TypeInteger,
+		field.TypeInt64:   sqlite.TypeInteger,
+		field.TypeUint:    sqlite.TypeInteger,
+		field.TypeUint64:  sqlite.TypeInteger,
+		field.TypeFloat32: sqlite.TypeReal,
+		field.TypeFloat64: sqlite.TypeReal,
+		field.TypeBytes:   sqlite.TypeBlob,
+		field.TypeString:  fmt.Sprintf("varchar(%d)", entschema.DefaultStringLen),
+		field.TypeEnum:    fmt.Sprintf("varchar(%d)", entschema.DefaultStringLen),
+		field.TypeJSON:    "json",
+		field.TypeUUID:    "uuid",
+		field.TypeTime:    "datetime",
+	},
+}
@@ -0,0 +1,177 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package entconv
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+
+	"entgo.io/ent/dialect"
+	entschema "entgo.io/ent/dialect/sql/schema"
+	"entgo.io/ent/schema/field"
+	"github.com/stretchr/testify/require"
+)
+
+func entTables() []*entschema.Table {
+	var (
+		usersID = &entschema.Column{Name: "id", Type: field.TypeInt, Increment: true}
+		users   = entschema.NewTable("users").
+			AddPrimary(usersID).
+			AddColumn(&entschema.Column{Name: "name", Type: field.TypeString, Size: 100, Default: "a8m"}).
+			AddColumn(&entschema.Column{Name: "age", Type: field.TypeUint8, Nullable: true}).
+			AddColumn(&entschema.Column{Name: "state", Type: field.TypeEnum, Enums: []string{"on", "off"}}).
+			AddColumn(&entschema.Column{Name: "price", Type: field.TypeFloat64, SchemaType: map[string]string{dialect.MySQL: "decimal(6,2)"}}).
+			AddIndex("name_idx", true, []string{"name"})
+		petsID    = &entschema.Column{Name: "id", Type: field.TypeInt, Increment: true}
+		petsOwner = &entschema.Column{Name: "owner_id", Type: field.TypeInt, Nullable: true}
+		pets      = entschema.NewTable("pets").AddPrimary(petsID).AddColumn(petsOwner)
+	)
+	pets.AddForeignKey(&entschema.ForeignKey{
+		Symbol:     "pets_users_pets",
+		Columns:    []*entschema.Column{petsOwner},
+		RefTable:   users,
+		RefColumns: []*entschema.Column{usersID},
+		OnDelete:   entschema.SetNull,
+	})
+	return []*entschema.Table{users, pets}
+}
+
+func TestFromEnt(t *testing.T) {
+	s, err := FromEnt(dialect.MySQL, "test", entTables())
+	require.NoError(t, err)
+	require.Len(t, s.Tables, 2)
+
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	require.Equal(t, "id", users.PrimaryKey.Parts[0].C.Name)
+	id, _ := users.Column("id")
+	require.Equal(t, &schema.IntegerType{T: "bigint"}, id.Type.Type)
+	require.Equal(t, []schema.Attr{&mysql.AutoIncrement{}}, id.Attrs)
+	name, _ := users.Column("name")
+	require.Equal(t, &schema.StringType{T: "varchar", Size: 100}, name.Type.Type)
+	require.Equal(t, &schema.Literal{V: "'a8m'"}, name.Default)
+	age, _ := users.Column("age")
+	require.True(t, age.Type.Null)
+	require.Equal(t, &schema.IntegerType{T: "tinyint", Unsigned: true}, age.Type.Type)
+	state, _ := users.Column("state")
+	require.Equal(t, &schema.EnumType{T: "enum", Values: []string{"on", "off"}}, state.Type.Type)
+	price, _ := users.Column("price")
+	require.Equal(t, &schema.DecimalType{T: "decimal", Precision: 6, Scale: 2}, price.Type.Type)
+	require.Len(t, users.Indexes, 1)
+	require.True(t, users.Indexes[0].Unique)
+	require.Equal(t, name, users.Indexes[0].Parts[0].C)
+
+	pets, ok := s.Table("pets")
+	require.True(t, ok)
+	require.Len(t, pets.ForeignKeys, 1)
+	fk := pets.ForeignKeys[0]
+	require.Equal(t, users, fk.RefTable)
+	require.Equal(t, id, fk.RefColumns[0])
+	require.Equal(t, schema.SetNull, fk.OnDelete)
+
+	_, err = FromEnt("oracle", "test", entTables())
+	require.EqualError(t, err, `entconv: unsupported dialect "oracle"`)
+}
+
+func TestToEnt(t *testing.T) {
+	for _, d := range []string{dialect.MySQL, dialect.SQLite} {
+		s, err := FromEnt(d, "test", entTables())
+		require.NoError(t, err)
+		tables, err := ToEnt(d, s)
+		require.NoError(t, err)
+		require.Len(t, tables, 2)
+
+		users := tables[0]
+		require.Equal(t, "users", users.Name)
+		require.Len(t, users.PrimaryKey, 1)
+		id := users.PrimaryKey[0]
+		require.True(t, id.Increment)
+		require.Equal(t, field.TypeInt64, id.Type)
+		require.Equal(t, "a8m", users.Columns[1].Default)
+		require.True(t, users.Columns[2].Nullable)
+		require.Len(t, users.Indexes, 1)
+		require.Equal(t, "name_idx", users.Indexes[0].Name)
+		require.Equal(t, users.Columns[1], users.Indexes[0].Columns[0])
+
+		pets := tables[1]
+		require.Len(t, pets.ForeignKeys, 1)
+		require.Equal(t, users, pets.ForeignKeys[0].RefTable)
+		require.Equal(t, id, pets.ForeignKeys[0].RefColumns[0])
+		require.Equal(t, entschema.SetNull, pets.ForeignKeys[0].OnDelete)
+	}
+
+	s, err := FromEnt(dialect.MySQL, "test", entTables())
+	require.NoError(t, err)
+	tables, err := ToEnt(dialect.MySQL, s)
+	require.NoError(t, err)
+	cs := tables[0].Columns
+	require.Equal(t, field.TypeUint8, cs[2].Type)
+	require.Nil(t, cs[2].SchemaType)
+	require.Equal(t, field.TypeEnum, cs[3].Type)
+	require.Equal(t, []string{"on", "off"}, cs[3].Enums)
+	require.Equal(t, field.TypeOther, cs[4].Type)
+	require.Equal(t, map[string]string{dialect.MySQL: "decimal(6,2)"}, cs[4].SchemaType)
+}
+
+func TestToEnt_Postgres(t *testing.T) {
+	s := schema.New("public").AddTables(
+		schema.NewTable("users").
+			AddColumns(
+				schema.NewColumn("id").SetType(&postgres.SerialType{T: postgres.TypeSerial}),
+				schema.NewColumn("uid").SetType(&postgres.UUIDType{T: postgres.TypeUUID}),
+				schema.NewBoolColumn("active", postgres.TypeBoolean).SetDefault(&schema.Literal{V: "true"}),
+				schema.NewIntColumn("rank", postgres.TypeInteger).SetDefault(&schema.Literal{V: "1"}),
+			),
+	)
+	tables, err := ToEnt(dialect.Postgres, s)
+	require.NoError(t, err)
+	cs := tables[0].Columns
+	require.Equal(t, field.TypeInt32, cs[0].Type)
+	require.True(t, cs[0].Increment)
+	require.Equal(t, field.TypeUUID, cs[1].Type)
+	require.Nil(t, cs[1].SchemaType)
+	require.Equal(t, true, cs[2].Default)
+	require.Equal(t, int64(1), cs[3].Default)
+}
+
+func TestExecQuerier(t *testing.T) {
+	eq := NewExecQuerier(&mockDriver{res: driver.RowsAffected(1)})
+	res, err := eq.ExecContext(context.Background(), "DELETE FROM users")
+	require.NoError(t, err)
+	n, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+
+	_, err = eq.QueryContext(context.Background(), "SELECT * FROM users")
+	require.EqualError(t, err, `entconv: no rows were returned by the driver for query "SELECT * FROM users"`)
+}
+
+func TestOpen_Unsupported(t *testing.T) {
+	_, err := Open(&mockDriver{dialect: dialect.Gremlin})
+	require.EqualError(t, err, `entconv: unsupported dialect "gremlin"`)
+}
+
+type mockDriver struct {
+	dialect.Driver
+	dialect string
+	res     sql.Result
+}
+
+func (d *mockDriver) Dialect() string { return d.dialect }
+
+func (d *mockDriver) Exec(_ context.Context, _ string, _, v interface{}) error {
+	*v.(*sql.Result) = d.res
+	return nil
+}
+
+func (d *mockDriver) Query(context.Context, string, interface{}, interface{}) error {
+	return nil
+}
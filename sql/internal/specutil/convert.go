@@ -62,6 +62,9 @@ func Schema(spec *sqlspec.Schema, tables []*sqlspec.Table, convertTable ConvertT
 	sch := &schema.Schema{
 		Name: spec.Name,
 	}
+	if err := convertCommentFromSpec(spec, &sch.Attrs); err != nil {
+		return nil, err
+	}
 	m := make(map[*schema.Table]*sqlspec.Table)
 	for _, ts := range tables {
 		table, err := convertTable(ts, sch)
@@ -239,6 +242,7 @@ func LinkForeignKeys(tbl *schema.Table, sch *schema.Schema, table *sqlspec.Table
 			Table:    tbl,
 			OnUpdate: spec.OnUpdate,
 			OnDelete: spec.OnDelete,
+			Match:    spec.Match,
 		}
 		for _, ref := range spec.Columns {
 			col, err := resolveCol(ref, sch)
@@ -305,6 +309,7 @@ func FromSchema(s *schema.Schema, fn TableSpecFunc) (*sqlspec.Schema, []*sqlspec
 	spec := &sqlspec.Schema{
 		Name: s.Name,
 	}
+	convertCommentFromSchema(s.Attrs, &spec.Extra.Attrs)
 	tables := make([]*sqlspec.Table, 0, len(s.Tables))
 	for _, t := range s.Tables {
 		table, err := fn(t)
@@ -486,6 +491,7 @@ func FromForeignKey(s *schema.ForeignKey) (*sqlspec.ForeignKey, error) {
 		RefColumns: r,
 		OnDelete:   s.OnDelete,
 		OnUpdate:   s.OnUpdate,
+		Match:      s.Match,
 	}, nil
 }
 
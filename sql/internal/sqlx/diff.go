@@ -66,6 +66,38 @@ type (
 	Normalizer interface {
 		Normalize(from, to *schema.Table)
 	}
+
+	// A ColumnOrderer wraps the ColumnOrder method for drivers that can treat the order of
+	// table columns as significant. If the DiffDriver implements the ColumnOrderer interface
+	// and ColumnOrder returns true, TableDiff reports the columns that were moved as modified
+	// with the schema.ChangePosition kind.
+	ColumnOrderer interface {
+		ColumnOrder() bool
+	}
+
+	// A NameFolder wraps the FoldName method for drivers that compare the names of some
+	// objects case-insensitively. If the DiffDriver implements the NameFolder interface,
+	// objects of the two states are matched by their folded names. For example, a table
+	// named "Users" in the desired state is matched with an existing "users" table.
+	NameFolder interface {
+		// FoldName returns the form of the object name that is used for comparison.
+		FoldName(kind schema.ObjectKind, name string) string
+	}
+
+	// A MatchComparer wraps the MatchChanged method for drivers that enforce the MATCH type
+	// of foreign keys. If the DiffDriver implements the MatchComparer interface, foreign keys
+	// with different match types are reported as modified with the schema.ChangeMatch kind.
+	// Drivers that parse this clause but ignore it (e.g. MySQL and SQLite) should not implement it.
+	MatchComparer interface {
+		MatchChanged(from, to schema.ReferenceMatch) bool
+	}
+
+	// A RealmAttrDiffer wraps the RealmAttrDiff method for drivers that manage objects on the
+	// realm level, like user accounts. If the DiffDriver implements the RealmAttrDiffer interface,
+	// RealmDiff appends its changes to the changes of the schemas.
+	RealmAttrDiffer interface {
+		RealmAttrDiff(from, to *schema.Realm) []schema.Change
+	}
 )
 
 // RealmDiff implements the schema.Differ for Realm objects and returns a list of changes
@@ -74,7 +106,7 @@ func (d *Diff) RealmDiff(from, to *schema.Realm) ([]schema.Change, error) {
 	var changes []schema.Change
 	// Drop or modify schema.
 	for _, s1 := range from.Schemas {
-		s2, ok := to.Schema(s1.Name)
+		s2, ok := d.schema(to, s1.Name)
 		if !ok {
 			changes = append(changes, &schema.DropSchema{S: s1})
 			continue
@@ -87,7 +119,7 @@ func (d *Diff) RealmDiff(from, to *schema.Realm) ([]schema.Change, error) {
 	}
 	// Add schemas.
 	for _, s1 := range to.Schemas {
-		if _, ok := from.Schema(s1.Name); ok {
+		if _, ok := d.schema(from, s1.Name); ok {
 			continue
 		}
 		changes = append(changes, &schema.AddSchema{S: s1})
@@ -95,13 +127,16 @@ func (d *Diff) RealmDiff(from, to *schema.Realm) ([]schema.Change, error) {
 			changes = append(changes, &schema.AddTable{T: t})
 		}
 	}
+	if r, ok := d.DiffDriver.(RealmAttrDiffer); ok {
+		changes = append(changes, r.RealmAttrDiff(from, to)...)
+	}
 	return changes, nil
 }
 
 // SchemaDiff implements the schema.Differ interface and returns a list of
 // changes that need to be applied in order to move from one state to the other.
 func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
-	if from.Name != to.Name {
+	if !d.sameName(schema.ObjectSchema, from.Name, to.Name) {
 		return nil, fmt.Errorf("mismatched schema names: %q != %q", from.Name, to.Name)
 	}
 	var changes []schema.Change
@@ -115,7 +150,7 @@ func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
 
 	// Drop or modify tables.
 	for _, t1 := range from.Tables {
-		t2, ok := to.Table(t1.Name)
+		t2, ok := d.table(to, t1.Name)
 		if !ok {
 			changes = append(changes, &schema.DropTable{T: t1})
 			continue
@@ -133,7 +168,7 @@ func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
 	}
 	// Add tables.
 	for _, t1 := range to.Tables {
-		if _, ok := from.Table(t1.Name); !ok {
+		if _, ok := d.table(from, t1.Name); !ok {
 			changes = append(changes, &schema.AddTable{T: t1})
 		}
 	}
@@ -143,7 +178,7 @@ func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
 // TableDiff implements the schema.TableDiffer interface and returns a list of
 // changes that need to be applied in order to move from one state to the other.
 func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
-	if from.Name != to.Name {
+	if !d.sameName(schema.ObjectTable, from.Name, to.Name) {
 		return nil, fmt.Errorf("mismatched table names: %q != %q", from.Name, to.Name)
 	}
 	// Normalizing tables before starting the diff process.
@@ -151,7 +186,7 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 		n.Normalize(from, to)
 	}
 	var changes []schema.Change
-	if from.Name != to.Name {
+	if !d.sameName(schema.ObjectTable, from.Name, to.Name) {
 		return nil, fmt.Errorf("mismatched table names: %q != %q", from.Name, to.Name)
 	}
 	// PK modification is not supported.
@@ -167,8 +202,12 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 	changes = append(changes, change...)
 
 	// Drop or modify columns.
+	var moved map[string]bool
+	if o, ok := d.DiffDriver.(ColumnOrderer); ok && o.ColumnOrder() {
+		moved = d.movedColumns(from, to)
+	}
 	for _, c1 := range from.Columns {
-		c2, ok := to.Column(c1.Name)
+		c2, ok := d.column(to, c1.Name)
 		if !ok {
 			changes = append(changes, &schema.DropColumn{C: c1})
 			continue
@@ -177,6 +216,9 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 		if err != nil {
 			return nil, err
 		}
+		if moved[d.fold(schema.ObjectColumn, c1.Name)] {
+			change |= schema.ChangePosition
+		}
 		if change != schema.NoChange {
 			changes = append(changes, &schema.ModifyColumn{
 				From:   c1,
@@ -187,7 +229,7 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 	}
 	// Add columns.
 	for _, c1 := range to.Columns {
-		if _, ok := from.Column(c1.Name); !ok {
+		if _, ok := d.column(from, c1.Name); !ok {
 			changes = append(changes, &schema.AddColumn{C: c1})
 		}
 	}
@@ -197,7 +239,7 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 
 	// Drop or modify foreign-keys.
 	for _, fk1 := range from.ForeignKeys {
-		fk2, ok := to.ForeignKey(fk1.Symbol)
+		fk2, ok := d.foreignKey(to, fk1.Symbol)
 		if !ok {
 			changes = append(changes, &schema.DropForeignKey{F: fk1})
 			continue
@@ -212,13 +254,92 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 	}
 	// Add foreign-keys.
 	for _, fk1 := range to.ForeignKeys {
-		if _, ok := from.ForeignKey(fk1.Symbol); !ok {
+		if _, ok := d.foreignKey(from, fk1.Symbol); !ok {
 			changes = append(changes, &schema.AddForeignKey{F: fk1})
 		}
 	}
 	return changes, nil
 }
 
+// ReorderColumns reports if applying the changes on the table requires reordering its
+// columns. That is, one of its columns was moved, or added before an existing column.
+// It is used by drivers that cannot add or move columns to an arbitrary position.
+func ReorderColumns(t *schema.Table, changes []schema.Change) bool {
+	added := make(map[string]bool)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddColumn:
+			added[c.C.Name] = true
+		case *schema.ModifyColumn:
+			if c.Change.Is(schema.ChangePosition) {
+				return true
+			}
+		}
+	}
+	var adding bool
+	for _, c := range t.Columns {
+		switch {
+		case added[c.Name]:
+			adding = true
+		case adding:
+			return true
+		}
+	}
+	return false
+}
+
+// movedColumns returns the (folded) names of the columns that should be moved for migrating
+// the columns order of one table to the other. Dropped and added columns are ignored, and the
+// longest common subsequence of the remaining columns keeps its position.
+func (d *Diff) movedColumns(from, to *schema.Table) map[string]bool {
+	var c1, c2 []string
+	for _, c := range from.Columns {
+		if _, ok := d.column(to, c.Name); ok {
+			c1 = append(c1, d.fold(schema.ObjectColumn, c.Name))
+		}
+	}
+	for _, c := range to.Columns {
+		if _, ok := d.column(from, c.Name); ok {
+			c2 = append(c2, d.fold(schema.ObjectColumn, c.Name))
+		}
+	}
+	// lcs[i][j] holds the length of the LCS of c1[i:] and c2[j:].
+	lcs := make([][]int, len(c1)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(c2)+1)
+	}
+	for i := len(c1) - 1; i >= 0; i-- {
+		for j := len(c2) - 1; j >= 0; j-- {
+			if c1[i] == c2[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	keep := make(map[string]bool, lcs[0][0])
+	for i, j := 0, 0; i < len(c1) && j < len(c2); {
+		switch {
+		case c1[i] == c2[j]:
+			keep[c1[i]] = true
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	moved := make(map[string]bool)
+	for _, c := range c1 {
+		if !keep[c] {
+			moved[c] = true
+		}
+	}
+	return moved
+}
+
 // indexDiff returns the schema changes (if any) for migrating table
 // indexes from current state to the desired state.
 func (d *Diff) indexDiff(from, to *schema.Table) []schema.Change {
@@ -228,7 +349,7 @@ func (d *Diff) indexDiff(from, to *schema.Table) []schema.Change {
 	)
 	// Drop or modify indexes.
 	for _, idx1 := range from.Indexes {
-		idx2, ok := to.Index(idx1.Name)
+		idx2, ok := d.index(to, idx1.Name)
 		// Found directly.
 		if ok {
 			if change := d.indexChange(idx1, idx2); change != schema.NoChange {
@@ -256,7 +377,7 @@ func (d *Diff) indexDiff(from, to *schema.Table) []schema.Change {
 		if exists[idx] {
 			continue
 		}
-		if _, ok := from.Index(idx.Name); !ok {
+		if _, ok := d.index(from, idx.Name); !ok {
 			changes = append(changes, &schema.AddIndex{I: idx})
 		}
 	}
@@ -294,7 +415,7 @@ func (d *Diff) partsChange(from, to []*schema.IndexPart) schema.ChangeKind {
 		case from[i].Desc != to[i].Desc || d.IndexPartAttrChanged(from[i], to[i]):
 			return schema.ChangeParts
 		case from[i].C != nil && to[i].C != nil:
-			if from[i].C.Name != to[i].C.Name {
+			if !d.sameName(schema.ObjectColumn, from[i].C.Name, to[i].C.Name) {
 				return schema.ChangeParts
 			}
 		case from[i].X != nil && to[i].X != nil:
@@ -312,13 +433,13 @@ func (d *Diff) partsChange(from, to []*schema.IndexPart) schema.ChangeKind {
 func (d *Diff) fkChange(from, to *schema.ForeignKey) schema.ChangeKind {
 	var change schema.ChangeKind
 	switch {
-	case from.Table.Name != to.Table.Name:
+	case !d.sameName(schema.ObjectTable, from.Table.Name, to.Table.Name):
 		change |= schema.ChangeRefTable | schema.ChangeRefColumn
 	case len(from.RefColumns) != len(to.RefColumns):
 		change |= schema.ChangeRefColumn
 	default:
 		for i := range from.RefColumns {
-			if from.RefColumns[i].Name != to.RefColumns[i].Name {
+			if !d.sameName(schema.ObjectColumn, from.RefColumns[i].Name, to.RefColumns[i].Name) {
 				change |= schema.ChangeRefColumn
 			}
 		}
@@ -328,7 +449,7 @@ func (d *Diff) fkChange(from, to *schema.ForeignKey) schema.ChangeKind {
 		change |= schema.ChangeColumn
 	default:
 		for i := range from.Columns {
-			if from.Columns[i].Name != to.Columns[i].Name {
+			if !d.sameName(schema.ObjectColumn, from.Columns[i].Name, to.Columns[i].Name) {
 				change |= schema.ChangeColumn
 			}
 		}
@@ -339,9 +460,31 @@ func (d *Diff) fkChange(from, to *schema.ForeignKey) schema.ChangeKind {
 	if d.ReferenceChanged(from.OnDelete, to.OnDelete) {
 		change |= schema.ChangeDeleteAction
 	}
+	if m, ok := d.DiffDriver.(MatchComparer); ok && m.MatchChanged(from.Match, to.Match) {
+		change |= schema.ChangeMatch
+	}
+	// A foreign key that keeps its columns, but one of them was retyped, is reported as
+	// modified, as it needs to be dropped before altering the column and created again.
+	if !change.Is(schema.ChangeColumn|schema.ChangeRefColumn) && (d.typesChanged(from.Columns, to.Columns) || d.typesChanged(from.RefColumns, to.RefColumns)) {
+		change |= schema.ChangeType
+	}
 	return change
 }
 
+// typesChanged reports if the type of one of the given foreign-key columns was changed.
+// Columns without types (e.g. stubs of referenced columns) are ignored.
+func (d *Diff) typesChanged(from, to []*schema.Column) bool {
+	for i := range from {
+		if from[i].Type == nil || from[i].Type.Type == nil || to[i].Type == nil || to[i].Type.Type == nil {
+			continue
+		}
+		if k, err := d.ColumnChange(from[i], to[i]); err == nil && k.Is(schema.ChangeType) {
+			return true
+		}
+	}
+	return false
+}
+
 // similarUnnamedIndex searches for an unnamed index with the same index-parts in the table.
 func (d *Diff) similarUnnamedIndex(t *schema.Table, idx1 *schema.Index) (*schema.Index, bool) {
 	for _, idx2 := range t.Indexes {
@@ -355,6 +498,82 @@ func (d *Diff) similarUnnamedIndex(t *schema.Table, idx1 *schema.Index) (*schema
 	return nil, false
 }
 
+// fold returns the folded form of the object name, if the DiffDriver folds names.
+func (d *Diff) fold(kind schema.ObjectKind, name string) string {
+	if f, ok := d.DiffDriver.(NameFolder); ok {
+		return f.FoldName(kind, name)
+	}
+	return name
+}
+
+// sameName reports if the two names identify the same object.
+func (d *Diff) sameName(kind schema.ObjectKind, name1, name2 string) bool {
+	return name1 == name2 || d.fold(kind, name1) == d.fold(kind, name2)
+}
+
+// The methods below find objects by their names. Exact matches
+// are preferred over matches of the folded names.
+
+func (d *Diff) schema(r *schema.Realm, name string) (*schema.Schema, bool) {
+	if s, ok := r.Schema(name); ok {
+		return s, true
+	}
+	for _, s := range r.Schemas {
+		if d.sameName(schema.ObjectSchema, s.Name, name) {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+func (d *Diff) table(s *schema.Schema, name string) (*schema.Table, bool) {
+	if t, ok := s.Table(name); ok {
+		return t, true
+	}
+	for _, t := range s.Tables {
+		if d.sameName(schema.ObjectTable, t.Name, name) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func (d *Diff) column(t *schema.Table, name string) (*schema.Column, bool) {
+	if c, ok := t.Column(name); ok {
+		return c, true
+	}
+	for _, c := range t.Columns {
+		if d.sameName(schema.ObjectColumn, c.Name, name) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+func (d *Diff) index(t *schema.Table, name string) (*schema.Index, bool) {
+	if idx, ok := t.Index(name); ok {
+		return idx, true
+	}
+	for _, idx := range t.Indexes {
+		if d.sameName(schema.ObjectIndex, idx.Name, name) {
+			return idx, true
+		}
+	}
+	return nil, false
+}
+
+func (d *Diff) foreignKey(t *schema.Table, symbol string) (*schema.ForeignKey, bool) {
+	if fk, ok := t.ForeignKey(symbol); ok {
+		return fk, true
+	}
+	for _, fk := range t.ForeignKeys {
+		if d.sameName(schema.ObjectForeignKey, fk.Symbol, symbol) {
+			return fk, true
+		}
+	}
+	return nil, false
+}
+
 // CommentChange reports if the element comment was changed.
 func CommentChange(from, to []schema.Attr) schema.ChangeKind {
 	var c1, c2 schema.Comment
@@ -395,15 +614,6 @@ func Has(elements, target interface{}) bool {
 	return false
 }
 
-// UnsupportedTypeError describes an unsupported type error.
-type UnsupportedTypeError struct {
-	schema.Type
-}
-
-func (e UnsupportedTypeError) Error() string {
-	return fmt.Sprintf("unsupported type %T", e.Type)
-}
-
 // CommentDiff computes the comment diff between the 2 attribute list.
 // Note that, the implementation relies on the fact that both PostgreSQL
 // and MySQL treat empty comment as "no comment" and a way to clear comments.
@@ -438,8 +648,14 @@ func CommentDiff(from, to []schema.Attr) schema.Change {
 }
 
 // CheckDiff computes the change diff between the 2 tables. A compare
-// function is provided to check if a Check object was modified.
+// function is provided to check if a Check object was modified. If it
+// is not provided, checks are compared by their normalized expressions.
 func CheckDiff(from, to *schema.Table, compare ...func(c1, c2 *schema.Check) bool) []schema.Change {
+	if len(compare) == 0 {
+		compare = append(compare, func(c1, c2 *schema.Check) bool {
+			return NormalizeExpr(c1.Expr) == NormalizeExpr(c2.Expr)
+		})
+	}
 	var changes []schema.Change
 	// Drop or modify checks.
 	for _, c1 := range checks(from.Attrs) {
@@ -448,7 +664,7 @@ func CheckDiff(from, to *schema.Table, compare ...func(c1, c2 *schema.Check) boo
 			changes = append(changes, &schema.DropCheck{
 				C: c1,
 			})
-		case len(compare) == 1 && !compare[0](c1, c2):
+		case !compare[0](c1, c2):
 			changes = append(changes, &schema.ModifyCheck{
 				From: c1,
 				To:   c2,
@@ -476,7 +692,7 @@ func checks(attr []schema.Attr) (checks []*schema.Check) {
 	return checks
 }
 
-// similarCheck returns a CHECK by its constraints name or expression.
+// similarCheck returns a CHECK by its constraints name or normalized expression.
 func similarCheck(attrs []schema.Attr, c *schema.Check) (*schema.Check, bool) {
 	var byName, byExpr *schema.Check
 	for i := 0; i < len(attrs) && (byName == nil || byExpr == nil); i++ {
@@ -487,7 +703,7 @@ func similarCheck(attrs []schema.Attr, c *schema.Check) (*schema.Check, bool) {
 		if check.Name != "" && check.Name == c.Name {
 			byName = check
 		}
-		if check.Expr == c.Expr {
+		if byExpr == nil && (check.Expr == c.Expr || NormalizeExpr(check.Expr) == NormalizeExpr(c.Expr)) {
 			byExpr = check
 		}
 	}
@@ -513,6 +729,198 @@ func Unquote(s string) (string, error) {
 	}
 }
 
+// TrimParens trims the parentheses that wrap the entire given
+// expression, if exist. For example, "((a) + (b))" => "(a) + (b)".
+func TrimParens(s string) string {
+	for {
+		s = strings.TrimSpace(s)
+		if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+			return s
+		}
+		var (
+			depth int
+			quote byte
+		)
+		for i := 0; i < len(s)-1; i++ {
+			switch c := s[i]; {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '\'' || c == '"' || c == '`':
+				quote = c
+			case c == '(':
+				depth++
+			case c == ')':
+				depth--
+			}
+			// The first parenthesis was closed before the end of the
+			// expression. For example, "(a) + (b)".
+			if depth == 0 {
+				return s
+			}
+		}
+		s = s[1 : len(s)-1]
+	}
+}
+
+// NormalizeExpr returns a canonical form of the given expression that can be used to
+// compare expressions that were formatted differently by the database and the user.
+// Identifier quotes, charset introducers, whitespaces and redundant parentheses are
+// removed, and tokens outside of string literals are lowercased. For example, both
+// "(`a` > (0)) AND (b <> '')" and "A > 0 and b != ''" are normalized to "a > 0 and b <> ''".
+func NormalizeExpr(x string) string {
+	tokens := exprTokens(x)
+	for trimmed := true; trimmed; {
+		tokens, trimmed = trimGroup(tokens)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// exprTokens splits the given expression into its tokens.
+func exprTokens(x string) []string {
+	var tokens []string
+	for i := 0; i < len(x); {
+		switch c := x[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			j := i + 1
+			for ; j < len(x); j++ {
+				if x[j] == '\\' {
+					j++
+				} else if x[j] == '\'' {
+					if j+1 >= len(x) || x[j+1] != '\'' {
+						break
+					}
+					j++
+				}
+			}
+			if j < len(x) {
+				j++
+			}
+			// Charset introducers. For example, "_utf8mb4'a'".
+			if n := len(tokens); n > 0 && i > 0 && isIdentByte(x[i-1]) && strings.HasPrefix(tokens[n-1], "_") {
+				tokens = tokens[:n-1]
+			}
+			tokens = append(tokens, x[i:j])
+			i = j
+		case c == '"' || c == '`':
+			j := strings.IndexByte(x[i+1:], c)
+			if j == -1 {
+				j = len(x)
+			} else {
+				j += i + 1
+			}
+			tokens = append(tokens, strings.ToLower(x[i+1:j]))
+			i = j + 1
+		case isIdentByte(c):
+			j := i + 1
+			for j < len(x) && isIdentByte(x[j]) {
+				j++
+			}
+			tokens = append(tokens, strings.ToLower(x[i:j]))
+			i = j
+		default:
+			t := x[i : i+1]
+			if i+1 < len(x) {
+				switch op := x[i : i+2]; op {
+				case "::", "<=", ">=", "<>", "||":
+					t = op
+				case "!=":
+					t = "<>"
+				}
+			}
+			tokens = append(tokens, t)
+			i += len(t)
+		}
+	}
+	return tokens
+}
+
+// trimGroup removes the first redundant pair of parentheses from the
+// given tokens, and reports if such a pair was found.
+func trimGroup(tokens []string) ([]string, bool) {
+	for i := range tokens {
+		if tokens[i] != "(" {
+			continue
+		}
+		depth, j := 0, i
+		for ; j < len(tokens); j++ {
+			if tokens[j] == "(" {
+				depth++
+			} else if tokens[j] == ")" {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+		// Unbalanced parentheses.
+		if j == len(tokens) {
+			return tokens, false
+		}
+		if redundantGroup(tokens, i, j) {
+			trimmed := append(make([]string, 0, len(tokens)-2), tokens[:i]...)
+			trimmed = append(trimmed, tokens[i+1:j]...)
+			return append(trimmed, tokens[j+1:]...), true
+		}
+	}
+	return tokens, false
+}
+
+// redundantGroup reports if the parentheses at positions i and j can
+// be removed from the expression without changing its meaning.
+func redundantGroup(tokens []string, i, j int) bool {
+	var prev, next string
+	if i > 0 {
+		prev = tokens[i-1]
+	}
+	if j < len(tokens)-1 {
+		next = tokens[j+1]
+	}
+	// Function calls and lists. For example, "lower(a)" or "a IN (1, 2)".
+	if prev != "" && prev != "and" && prev != "or" && prev != "not" && isIdentByte(prev[0]) {
+		return false
+	}
+	// Wrapped literal or identifier. For example, "(0)::numeric".
+	if j == i+2 {
+		return true
+	}
+	switch prev {
+	case "", "(", ",", "and", "or", "not":
+	default:
+		return false
+	}
+	switch next {
+	case "", ")", ",", "and", "or":
+	default:
+		return false
+	}
+	// Logical operators in the group must bind
+	// looser than the operators around it.
+	for k, depth := i+1, 0; k < j; k++ {
+		switch tokens[k] {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case "or":
+			if depth == 0 && (prev == "and" || prev == "not" || next == "and") {
+				return false
+			}
+		case "and":
+			if depth == 0 && prev == "not" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
 // SingleQuote quotes the given string with single quote.
 func SingleQuote(s string) (string, error) {
 	switch {
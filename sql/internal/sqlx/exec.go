@@ -0,0 +1,145 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// LogFunc is a function that is called with each statement that is
+// executed or queried by a driver, before it is sent to the database.
+type LogFunc func(ctx context.Context, stmt string, args []interface{})
+
+// LogExecQuerier wraps the given ExecQuerier and calls f with each
+// statement before it is executed.
+func LogExecQuerier(eq schema.ExecQuerier, f LogFunc) schema.ExecQuerier {
+	return &logExecQuerier{ExecQuerier: eq, log: f}
+}
+
+type logExecQuerier struct {
+	schema.ExecQuerier
+	log LogFunc
+}
+
+func (e *logExecQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	e.log(ctx, query, args)
+	return e.ExecQuerier.QueryContext(ctx, query, args...)
+}
+
+func (e *logExecQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	e.log(ctx, query, args)
+	return e.ExecQuerier.ExecContext(ctx, query, args...)
+}
+
+// CatalogQuerier wraps the given ExecQuerier and replaces the qualifier of
+// objects in the catalog schema (e.g. "information_schema") with the given
+// name in all queries. The name is expected to be quoted by the caller, if
+// needed. Statements passed to ExecContext are left as-is.
+func CatalogQuerier(eq schema.ExecQuerier, catalog, name string) schema.ExecQuerier {
+	return &catalogQuerier{
+		ExecQuerier: eq,
+		name:        strings.ReplaceAll(name, "$", "$$"),
+		re:          regexp.MustCompile("(?i)(^|[^\\w$.\"`])[\"`]?" + regexp.QuoteMeta(catalog) + "[\"`]?\\."),
+	}
+}
+
+type catalogQuerier struct {
+	schema.ExecQuerier
+	name string
+	re   *regexp.Regexp
+}
+
+func (e *catalogQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return e.ExecQuerier.QueryContext(ctx, e.re.ReplaceAllString(query, "${1}"+e.name+"."), args...)
+}
+
+// ReadOnlyExecQuerier wraps the given ExecQuerier and rejects all statements that
+// may write to the database with a schema.ReadOnlyError, before they are sent
+// to it. That is, all calls to ExecContext, and queries that are not read-only
+// statements. For example, SELECT statements that create tables (SELECT INTO)
+// or CTEs that modify data.
+func ReadOnlyExecQuerier(eq schema.ExecQuerier) schema.ExecQuerier {
+	return &readOnlyExecQuerier{ExecQuerier: eq}
+}
+
+type readOnlyExecQuerier struct {
+	schema.ExecQuerier
+}
+
+var (
+	// Leading whitespace, comments and parentheses of statements.
+	reStmtPrefix = regexp.MustCompile(`^(\s+|--[^\n]*\n?|/\*(?s:.*?)\*/|\()+`)
+	// Keywords that modify data or definitions in read statements.
+	reWriteWord = regexp.MustCompile(`(?i)\b(INSERT|UPDATE|DELETE|MERGE|INTO|TRUNCATE|CREATE|ALTER|DROP)\b`)
+	// SHOW CREATE statements print the definitions of objects.
+	reShowCreate = regexp.MustCompile(`(?i)^SHOW\s+CREATE\b`)
+)
+
+func (e *readOnlyExecQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if !ReadOnlyStmt(query) {
+		return nil, &schema.ReadOnlyError{Stmt: query}
+	}
+	return e.ExecQuerier.QueryContext(ctx, query, args...)
+}
+
+func (e *readOnlyExecQuerier) ExecContext(_ context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	return nil, &schema.ReadOnlyError{Stmt: query}
+}
+
+// ReadOnlyStmt reports if the given statement is a read-only statement. The check
+// is conservative, and statements that are not known to be safe are rejected.
+func ReadOnlyStmt(stmt string) bool {
+	stmt = reStmtPrefix.ReplaceAllString(stmt, "")
+	i := strings.IndexFunc(stmt, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	})
+	word := stmt
+	if i != -1 {
+		word = stmt[:i]
+	}
+	// Strings and quoted identifiers are not checked, and
+	// multiple statements are not allowed.
+	body := strings.TrimRight(stripQuoted(stmt), "; \t\n")
+	if strings.Contains(body, ";") {
+		return false
+	}
+	switch strings.ToUpper(word) {
+	case "SELECT", "WITH", "VALUES", "TABLE":
+		return !reWriteWord.MatchString(body)
+	case "SHOW", "DESCRIBE", "DESC", "EXPLAIN":
+		body = reShowCreate.ReplaceAllString(body, "")
+		return !reWriteWord.MatchString(body) && !strings.Contains(strings.ToUpper(body), "ANALYZE")
+	case "PRAGMA":
+		// Setting a PRAGMA value modifies the database or the connection.
+		return !strings.ContainsAny(body, "=(")
+	default:
+		return false
+	}
+}
+
+// stripQuoted removes the quoted strings and identifiers from the given statement.
+func stripQuoted(s string) string {
+	var (
+		b     strings.Builder
+		quote rune
+	)
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,384 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Formatter formats SQL statements for readability. It uppercases keywords,
+// writes the definitions of CREATE TABLE statements one per line, and splits
+// ALTER TABLE statements with multiple clauses into one clause per line. The
+// rest of the statement (including its whitespace) is preserved as-is.
+type Formatter struct {
+	Lexer
+	// Indent used for nested lines. Defaults to 2 spaces.
+	Indent string
+}
+
+// Lexer splits SQL statements into tokens.
+type Lexer struct {
+	// Backslash indicates if backslashes escape characters
+	// in string literals (e.g. MySQL default SQL mode).
+	Backslash bool
+	// Dollar indicates if dollar-quoted strings are supported (PostgreSQL).
+	Dollar bool
+	// HashComments indicates if "#" starts a single-line comment (MySQL).
+	HashComments bool
+	// DashSpace indicates if "--" starts a comment only if it is followed
+	// by a whitespace or a control character (MySQL). e.g. "1--1" is 2.
+	DashSpace bool
+	// NestedComments indicates if block comments can be nested (PostgreSQL).
+	NestedComments bool
+	// EscapeStrings indicates if E'...' strings, in which backslashes escape
+	// characters, are supported (PostgreSQL).
+	EscapeStrings bool
+	// Brackets indicates if identifiers can be quoted with brackets (SQLite).
+	Brackets bool
+}
+
+// Token kinds.
+const (
+	TokSpace = iota
+	TokComment
+	TokString
+	TokIdent  // Quoted identifier.
+	TokWord   // Unquoted identifier or keyword.
+	TokNumber // Numeric literal.
+	TokPunct  // Punctuation or operator.
+)
+
+// A Token is a lexical token in an SQL statement.
+type Token struct {
+	Kind int
+	Text string
+	// Pos is the byte offset of the token in the input.
+	Pos int
+	// Unterminated is set for strings, quoted identifiers and
+	// block comments that are not terminated in the input.
+	Unterminated bool
+}
+
+// Tokens splits the given SQL into tokens. Unterminated strings, quoted identifiers
+// and comments are returned as a single token that spans until the end of the input.
+func (l *Lexer) Tokens(s string) []Token {
+	var tokens []Token
+	for i := 0; i < len(s); {
+		kind, n, ok := l.scan(s[i:])
+		tokens = append(tokens, Token{Kind: kind, Text: s[i : i+n], Pos: i, Unterminated: !ok})
+		i += n
+	}
+	return tokens
+}
+
+// scan returns the kind and the length of the first token in s,
+// and false if the token is not terminated.
+func (l *Lexer) scan(s string) (int, int, bool) {
+	switch c := s[0]; {
+	case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		n := 1
+		for n < len(s) && strings.IndexByte(" \t\n\r", s[n]) != -1 {
+			n++
+		}
+		return TokSpace, n, true
+	case strings.HasPrefix(s, "--") && (!l.DashSpace || len(s) == 2 || s[2] <= ' ') || c == '#' && l.HashComments:
+		if n := strings.IndexByte(s, '\n'); n != -1 {
+			return TokComment, n, true
+		}
+		return TokComment, len(s), true
+	case strings.HasPrefix(s, "/*"):
+		n, ok := l.blockComment(s)
+		return TokComment, n, ok
+	case c == '\'':
+		n, ok := quoted(s, '\'', l.Backslash)
+		return TokString, n, ok
+	case (c == 'E' || c == 'e') && l.EscapeStrings && len(s) > 1 && s[1] == '\'':
+		n, ok := quoted(s[1:], '\'', true)
+		return TokString, n + 1, ok
+	case c == '"' || c == '`':
+		n, ok := quoted(s, c, false)
+		return TokIdent, n, ok
+	case c == '[' && l.Brackets:
+		if n := strings.IndexByte(s, ']'); n != -1 {
+			return TokIdent, n + 1, true
+		}
+		return TokIdent, len(s), false
+	case c == '$' && l.Dollar:
+		if n, ok := dollarQuoted(s); n > 0 {
+			return TokString, n, ok
+		}
+		n := 1
+		for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+		return TokPunct, n, true
+	case c >= '0' && c <= '9' || c == '.' && len(s) > 1 && s[1] >= '0' && s[1] <= '9':
+		n := 1
+		for n < len(s) && (s[n] >= '0' && s[n] <= '9' || s[n] == '.' || s[n] == 'e' || s[n] == 'E') {
+			n++
+		}
+		return TokNumber, n, true
+	case isWordStart(rune(c)) || c >= 0x80:
+		n := 0
+		for _, r := range s {
+			if !isWordStart(r) && !unicode.IsDigit(r) && r != '$' {
+				break
+			}
+			n += len(string(r))
+		}
+		if n == 0 {
+			n = 1
+		}
+		return TokWord, n, true
+	default:
+		return TokPunct, 1, true
+	}
+}
+
+// blockComment returns the length of the block comment in s.
+func (l *Lexer) blockComment(s string) (int, bool) {
+	if !l.NestedComments {
+		if n := strings.Index(s[2:], "*/"); n != -1 {
+			return n + 4, true
+		}
+		return len(s), false
+	}
+	depth := 0
+	for i := 0; i+1 < len(s); i++ {
+		switch {
+		case s[i] == '/' && s[i+1] == '*':
+			depth++
+			i++
+		case s[i] == '*' && s[i+1] == '/':
+			if depth--; depth == 0 {
+				return i + 2, true
+			}
+			i++
+		}
+	}
+	return len(s), false
+}
+
+// quoted returns the length of the quoted string or identifier in s.
+// Quotes are escaped by doubling them, or optionally with backslashes.
+func quoted(s string, q byte, backslash bool) (int, bool) {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case backslash && s[i] == '\\':
+			i++
+		case s[i] == q && i+1 < len(s) && s[i+1] == q:
+			i++
+		case s[i] == q:
+			return i + 1, true
+		}
+	}
+	return len(s), false
+}
+
+// dollarQuoted returns the length of the dollar-quoted string in s, or 0.
+func dollarQuoted(s string) (int, bool) {
+	end := strings.IndexByte(s[1:], '$')
+	if end == -1 {
+		return 0, false
+	}
+	tag := s[:end+2]
+	for _, r := range tag[1 : len(tag)-1] {
+		if !isWordStart(r) && !unicode.IsDigit(r) {
+			return 0, false
+		}
+	}
+	if n := strings.Index(s[len(tag):], tag); n != -1 {
+		return len(tag) + n + len(tag), true
+	}
+	return len(s), false
+}
+
+func isWordStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// keywords that are uppercased by the formatter. Words that are commonly
+// used in type names (e.g. "character varying" or "time zone") are excluded.
+var keywords = func() map[string]bool {
+	m := make(map[string]bool)
+	for _, k := range strings.Fields(`
+		ADD AFTER ALTER ALWAYS AND AS ASC AUTO_INCREMENT AUTOINCREMENT BY CASCADE CHANGE CHARSET CHECK COLLATE
+		COLUMN COMMENT CONCURRENTLY CONSTRAINT CREATE DATABASE DEFAULT DEFERRABLE DELETE DESC DISTINCT DROP
+		ENFORCED EXISTS FIRST FOREIGN FROM FULLTEXT GENERATED IDENTITY IF IN INCREMENT INDEX INITIALLY INSERT
+		INTO IS KEY LIKE MATCH MODIFY NO NOT NULL ON OR PRIMARY REFERENCES RENAME RESTART RESTRICT SCHEMA
+		SELECT SET SPATIAL START TABLE TEMPORARY TO TYPE UNIQUE UPDATE USING VALUES WHERE`) {
+		m[k] = true
+	}
+	return m
+}()
+
+// Format formats the given statement.
+func (f *Formatter) Format(stmt string) string {
+	var (
+		tokens = f.Tokens(stmt)
+		words  []string
+	)
+	for i, t := range tokens {
+		if t.Kind == TokWord && keywords[strings.ToUpper(t.Text)] {
+			tokens[i].Text = strings.ToUpper(t.Text)
+		}
+		if t.Kind == TokWord && len(words) < 3 {
+			words = append(words, strings.ToUpper(t.Text))
+		}
+	}
+	switch {
+	case len(words) >= 2 && words[0] == "CREATE" && (words[1] == "TABLE" || len(words) > 2 && words[2] == "TABLE"):
+		return f.createTable(tokens)
+	case len(words) >= 2 && words[0] == "ALTER" && words[1] == "TABLE":
+		return f.alterTable(tokens)
+	default:
+		return join(tokens)
+	}
+}
+
+// createTable writes the top-level definitions of the table one per line.
+func (f *Formatter) createTable(tokens []Token) string {
+	var (
+		b      strings.Builder
+		depth  int
+		done   bool
+		indent = f.indent()
+	)
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case done || t.Kind != TokPunct:
+			b.WriteString(t.Text)
+		case t.Text == "(":
+			depth++
+			b.WriteString(t.Text)
+			if depth == 1 {
+				b.WriteString("\n" + indent)
+				i = skipSpace(tokens, i)
+			}
+		case t.Text == ")":
+			if depth == 1 {
+				done = true
+				trimSpace(&b)
+				b.WriteString("\n")
+			}
+			depth--
+			b.WriteString(t.Text)
+		case t.Text == "," && depth == 1:
+			b.WriteString(",\n" + indent)
+			i = skipSpace(tokens, i)
+		default:
+			b.WriteString(t.Text)
+		}
+	}
+	return b.String()
+}
+
+// alterTable writes each top-level clause of the statement in a separate line.
+func (f *Formatter) alterTable(tokens []Token) string {
+	var (
+		depth  int
+		commas int
+		indent = f.indent()
+	)
+	for _, t := range tokens {
+		switch {
+		case t.Kind != TokPunct:
+		case t.Text == "(":
+			depth++
+		case t.Text == ")":
+			depth--
+		case t.Text == "," && depth == 0:
+			commas++
+		}
+	}
+	if commas == 0 {
+		return join(tokens)
+	}
+	var (
+		b     strings.Builder
+		start = clauseStart(tokens)
+	)
+	depth = 0
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case i == start:
+			trimSpace(&b)
+			b.WriteString("\n" + indent + t.Text)
+		case t.Kind != TokPunct:
+			b.WriteString(t.Text)
+		case t.Text == "(":
+			depth++
+			b.WriteString(t.Text)
+		case t.Text == ")":
+			depth--
+			b.WriteString(t.Text)
+		case t.Text == "," && depth == 0 && i > start:
+			b.WriteString(",\n" + indent)
+			i = skipSpace(tokens, i)
+		default:
+			b.WriteString(t.Text)
+		}
+	}
+	return b.String()
+}
+
+// clauseStart returns the index of the first token after the
+// (optionally qualified) table name of an ALTER TABLE statement.
+func clauseStart(tokens []Token) int {
+	var words int
+	for i := 0; i < len(tokens); i++ {
+		switch t := tokens[i]; {
+		case t.Kind == TokSpace || t.Kind == TokComment:
+		case words < 2 && t.Kind == TokWord:
+			words++
+		case t.Kind == TokWord && (strings.EqualFold(t.Text, "IF") || strings.EqualFold(t.Text, "EXISTS") || strings.EqualFold(t.Text, "ONLY")):
+		case t.Kind == TokWord || t.Kind == TokIdent:
+			// Table name, optionally qualified with its schema.
+			for i+2 < len(tokens) && tokens[i+1].Text == "." {
+				i += 2
+			}
+			for i+1 < len(tokens) && tokens[i+1].Kind == TokSpace {
+				i++
+			}
+			return i + 1
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+func (f *Formatter) indent() string {
+	if f.Indent != "" {
+		return f.Indent
+	}
+	return "  "
+}
+
+// skipSpace returns the index of the last space token following position i.
+func skipSpace(tokens []Token, i int) int {
+	for i+1 < len(tokens) && tokens[i+1].Kind == TokSpace {
+		i++
+	}
+	return i
+}
+
+// trimSpace trims the trailing whitespace of the builder.
+func trimSpace(b *strings.Builder) {
+	s := strings.TrimRight(b.String(), " \t\n\r")
+	b.Reset()
+	b.WriteString(s)
+}
+
+func join(tokens []Token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(t.Text)
+	}
+	return b.String()
+}
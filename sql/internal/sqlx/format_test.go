@@ -0,0 +1,78 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatter_Format(t *testing.T) {
+	f := &Formatter{}
+	for _, tt := range []struct{ in, out string }{
+		{
+			in:  "create index `idx` on `t` (`a`, `b`)",
+			out: "CREATE INDEX `idx` ON `t` (`a`, `b`)",
+		},
+		{
+			in:  "CREATE TABLE `t` (`id` bigint NOT NULL AUTO_INCREMENT, `name` varchar(255) NOT NULL DEFAULT 'a, (b)', PRIMARY KEY (`id`), CHECK (`id` > 0)) CHARSET utf8mb4",
+			out: "CREATE TABLE `t` (\n  `id` bigint NOT NULL AUTO_INCREMENT,\n  `name` varchar(255) NOT NULL DEFAULT 'a, (b)',\n  PRIMARY KEY (`id`),\n  CHECK (`id` > 0)\n) CHARSET utf8mb4",
+		},
+		{
+			in:  `CREATE TABLE IF NOT EXISTS "public"."t" ("c" character varying(10) not null, "d" timestamp with time zone)`,
+			out: "CREATE TABLE IF NOT EXISTS \"public\".\"t\" (\n  \"c\" character varying(10) NOT NULL,\n  \"d\" timestamp with time zone\n)",
+		},
+		{
+			in:  `ALTER TABLE "public"."users" ADD COLUMN "name" text, DROP CONSTRAINT "c", ADD CONSTRAINT "c" CHECK (("id") % 2 = 0)`,
+			out: "ALTER TABLE \"public\".\"users\"\n  ADD COLUMN \"name\" text,\n  DROP CONSTRAINT \"c\",\n  ADD CONSTRAINT \"c\" CHECK ((\"id\") % 2 = 0)",
+		},
+		{
+			in:  "ALTER TABLE `users` DROP INDEX `name_index`",
+			out: "ALTER TABLE `users` DROP INDEX `name_index`",
+		},
+		{
+			in:  `ALTER TABLE IF EXISTS ONLY t add column c int, drop column ",d"`,
+			out: "ALTER TABLE IF EXISTS ONLY t\n  ADD COLUMN c int,\n  DROP COLUMN \",d\"",
+		},
+		{
+			in:  "-- create table t (a int, b int)\nselect 1",
+			out: "-- create table t (a int, b int)\nSELECT 1",
+		},
+	} {
+		require.Equal(t, tt.out, f.Format(tt.in))
+	}
+	f = &Formatter{Indent: "\t", Lexer: Lexer{Backslash: true}}
+	require.Equal(t, "CREATE TABLE t (\n\ta text DEFAULT 'it\\'s, ok'\n)", f.Format("create table t (a text default 'it\\'s, ok')"))
+}
+
+func TestFormatter_Tokens(t *testing.T) {
+	var (
+		l      = &Lexer{Dollar: true}
+		src    = `SELECT $1, $tag$a 'b' $tag$, "x""y", 1.5e3 /* c */`
+		tokens = l.Tokens(src)
+	)
+	for i, tk := range tokens {
+		require.Equal(t, tk.Text, src[tk.Pos:tk.Pos+len(tk.Text)])
+		tokens[i].Pos = 0
+	}
+	require.Equal(t, []Token{
+		{Kind: TokWord, Text: "SELECT"},
+		{Kind: TokSpace, Text: " "},
+		{Kind: TokPunct, Text: "$1"},
+		{Kind: TokPunct, Text: ","},
+		{Kind: TokSpace, Text: " "},
+		{Kind: TokString, Text: "$tag$a 'b' $tag$"},
+		{Kind: TokPunct, Text: ","},
+		{Kind: TokSpace, Text: " "},
+		{Kind: TokIdent, Text: `"x""y"`},
+		{Kind: TokPunct, Text: ","},
+		{Kind: TokSpace, Text: " "},
+		{Kind: TokNumber, Text: "1.5e3"},
+		{Kind: TokSpace, Text: " "},
+		{Kind: TokComment, Text: "/* c */"},
+	}, tokens)
+	require.Equal(t, []Token{{Kind: TokString, Text: "'unterminated", Unterminated: true}}, l.Tokens("'unterminated"))
+}
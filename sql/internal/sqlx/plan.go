@@ -15,28 +15,236 @@ import (
 	"ariga.io/atlas/sql/schema"
 )
 
-type execPlanner interface {
-	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
-	PlanChanges(context.Context, string, []schema.Change) (*migrate.Plan, error)
-}
+type (
+	execPlanner interface {
+		ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+		PlanChanges(context.Context, string, []schema.Change) (*migrate.Plan, error)
+	}
+
+	// RowsCounter is an optional interface that can be implemented by drivers
+	// to report the number of rows that are copied by a planned change. It is
+	// used only when progress reporting was requested by the caller.
+	RowsCounter interface {
+		// CountRows returns the number of rows that are copied by the given
+		// change, or false if the change does not copy rows.
+		CountRows(context.Context, *migrate.Change) (int64, bool, error)
+	}
+
+	// ChunkExecer is an optional interface that can be implemented by drivers
+	// to execute planned changes that copy table rows in multiple statements
+	// (chunks), instead of one large statement. The context is checked for
+	// cancellation between chunks.
+	ChunkExecer interface {
+		// ExecChunks executes the given change in chunks, and calls copied with
+		// the number of rows that were copied so far after each chunk. It returns
+		// false if the change should be executed as a single statement.
+		ExecChunks(ctx context.Context, c *migrate.Change, copied func(int64)) (bool, error)
+	}
+
+	// ChangeChecker is an optional interface that can be implemented by drivers
+	// to run planned changes that verify the state of the database by querying
+	// it (e.g. integrity checks after table rewrites), instead of executing them.
+	ChangeChecker interface {
+		// CheckChange runs the given change if it is a check, and returns false
+		// otherwise. The returned error describes the failure of the check.
+		CheckChange(context.Context, *migrate.Change) (bool, error)
+	}
+
+	// ErrorConverter is an optional interface that can be implemented by drivers
+	// to convert driver-specific execution errors into the typed errors defined
+	// in the schema package (e.g. schema.LockError).
+	ErrorConverter interface {
+		// ConvertError converts the error returned by executing the given
+		// statement. The returned error is expected to wrap the original one.
+		ConvertError(stmt string, err error) error
+	}
+
+	// SingleConnChecker is an optional interface that can be implemented by drivers
+	// to report if they were opened on a single database connection (e.g. *sql.Tx).
+	// Savepoints are bound to the connection they were created on, and therefore, are
+	// used only by drivers that implement this interface and report true.
+	SingleConnChecker interface {
+		SingleConn() bool
+	}
+)
 
 // ApplyChanges is a helper used by the different drivers to apply changes.
+// The progress is reported to the migrate.ProgressFunc stored in the context,
+// if exists.
+//
+// The context is checked for cancellation before each statement is executed,
+// and in-flight statements are canceled by the underlying database/sql driver.
+// In case the execution was stopped in the middle, a *migrate.ApplyError that
+// describes which changes were applied is returned.
+//
+// If savepoints were requested using migrate.WithSavepoints, and the plan is
+// transactional, each change is executed within a savepoint, and failed changes
+// are rolled back to it and handled by the configured policy. An error is returned
+// if the driver is not opened on a single connection (see SingleConnChecker).
 func ApplyChanges(ctx context.Context, changes []schema.Change, p execPlanner) error {
 	plan, err := p.PlanChanges(ctx, "apply", changes)
 	if err != nil {
 		return err
 	}
-	for _, c := range plan.Changes {
-		if _, err := p.ExecContext(ctx, c.Cmd, c.Args...); err != nil {
-			if c.Comment != "" {
-				err = fmt.Errorf("%s: %w", c.Comment, err)
+	var (
+		report             = migrate.ProgressFromContext(ctx)
+		policy, savepoints = migrate.SavepointsFromContext(ctx)
+		rollback           *migrate.RollbackError
+	)
+	savepoints = savepoints && plan.Transactional
+	if sc, ok := p.(SingleConnChecker); savepoints && (!ok || !sc.SingleConn()) {
+		return errSavepointConn
+	}
+	for i, c := range plan.Changes {
+		if err := ctx.Err(); err != nil {
+			return migrate.NewApplyError(plan, i, nil, err)
+		}
+		progress := migrate.Progress{Change: c, Index: i, Total: len(plan.Changes)}
+		if report != nil {
+			if rc, ok := p.(RowsCounter); ok {
+				n, copies, err := rc.CountRows(ctx, c)
+				if err != nil {
+					return migrate.NewApplyError(plan, i, nil, err)
+				}
+				if copies {
+					progress.RowsTotal = n
+				}
 			}
+			report(progress)
+		}
+		if savepoints {
+			if _, err := p.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+				return migrate.NewApplyError(plan, i, nil, StmtError(p, "SAVEPOINT "+savepoint, err))
+			}
+		}
+		var (
+			res  sql.Result
+			done bool
+			err  error
+		)
+		if cc, ok := p.(ChangeChecker); ok {
+			done, err = cc.CheckChange(ctx, c)
+		}
+		if ce, ok := p.(ChunkExecer); ok && !done && err == nil {
+			done, err = ce.ExecChunks(ctx, c, func(n int64) {
+				if report != nil {
+					progress.RowsCopied = n
+					report(progress)
+				}
+			})
+		}
+		if !done && err == nil {
+			res, err = p.ExecContext(ctx, c.Cmd, c.Args...)
+		}
+		if err != nil {
+			err = StmtError(p, c.Cmd, err)
+		}
+		if savepoints {
+			rolledBack, serr := releaseSavepoint(ctx, p, err != nil)
+			if serr != nil {
+				return migrate.NewApplyError(plan, i, c, serr)
+			}
+			progress.RolledBack = rolledBack
+		}
+		skip := err != nil && progress.RolledBack && policy == migrate.SavepointContinue
+		if err != nil && !skip {
+			err = migrate.NewApplyError(plan, i, c, err)
+		}
+		if report != nil {
+			progress.Done, progress.Err = true, err
+			if err == nil && res != nil && progress.RowsTotal > 0 {
+				if n, err := res.RowsAffected(); err == nil {
+					progress.RowsCopied = n
+				}
+			}
+			report(progress)
+		}
+		switch {
+		case skip:
+			if rollback == nil {
+				rollback = &migrate.RollbackError{Plan: plan}
+			}
+			rollback.Failed = append(rollback.Failed, c)
+			rollback.Errs = append(rollback.Errs, err)
+		case err != nil:
 			return err
 		}
 	}
+	if rollback != nil {
+		return rollback
+	}
 	return nil
 }
 
+// savepoint is the name of the savepoint that wraps each change.
+const savepoint = "atlas_apply"
+
+// errSavepointConn is returned when savepoints were requested on a connection pool,
+// as the SAVEPOINT, ROLLBACK TO and RELEASE statements may run on different connections.
+var errSavepointConn = errors.New("sql/sqlx: savepoints require a driver that is opened on a single connection (e.g. *sql.Tx)")
+
+// releaseSavepoint releases the savepoint of the executed change, after rolling
+// back to it if the change failed. Changes that were stopped by the context are
+// not rolled back, as the transaction is expected to be rolled back by the caller.
+func releaseSavepoint(ctx context.Context, p execPlanner, failed bool) (bool, error) {
+	if failed && ctx.Err() != nil {
+		return false, nil
+	}
+	if failed {
+		if _, err := p.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); err != nil {
+			return false, StmtError(p, "ROLLBACK TO SAVEPOINT "+savepoint, err)
+		}
+	}
+	if _, err := p.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); err != nil {
+		return failed, StmtError(p, "RELEASE SAVEPOINT "+savepoint, err)
+	}
+	return failed, nil
+}
+
+// StmtError wraps the error returned by executing the given statement with
+// a *schema.StmtError, after it was converted by the driver, if it implements
+// the ErrorConverter interface. Context errors are returned as-is.
+func StmtError(p interface{}, stmt string, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if c, ok := p.(ErrorConverter); ok {
+		err = c.ConvertError(stmt, err)
+	}
+	var e *schema.StmtError
+	if errors.As(err, &e) {
+		return err
+	}
+	return &schema.StmtError{Stmt: stmt, Err: err}
+}
+
+// CoalesceChanges merges the ModifyTable changes of the same table into one
+// ModifyTable change, positioned at the first occurrence of the table. It is
+// used by drivers that combine multiple changes of a table into a single ALTER
+// TABLE statement.
+func CoalesceChanges(changes []schema.Change) []schema.Change {
+	var (
+		planned = make([]schema.Change, 0, len(changes))
+		byTable = make(map[*schema.Table]*schema.ModifyTable)
+	)
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok {
+			planned = append(planned, c)
+			continue
+		}
+		if prev, ok := byTable[m.T]; ok {
+			prev.Changes = append(prev.Changes, m.Changes...)
+			continue
+		}
+		// Copy the change to avoid modifying the one provided by the caller.
+		m = &schema.ModifyTable{T: m.T, Changes: append([]schema.Change(nil), m.Changes...)}
+		byTable[m.T] = m
+		planned = append(planned, m)
+	}
+	return planned
+}
+
 // DetachCycles takes a list of schema changes, and detaches
 // references between changes if there is at least one circular
 // reference in the changeset. More explicitly, it postpones fks
@@ -51,7 +259,7 @@ func DetachCycles(changes []schema.Change) ([]schema.Change, error) {
 	}
 	planned := make([]schema.Change, len(changes))
 	copy(planned, changes)
-	sort.Slice(planned, func(i, j int) bool {
+	sort.SliceStable(planned, func(i, j int) bool {
 		return sorted[table(planned[i])] < sorted[table(planned[j])]
 	})
 	return planned, nil
@@ -113,6 +321,70 @@ func detachReferences(changes []schema.Change) []schema.Change {
 	return append(planned, deferred...)
 }
 
+// DetachAlteredReferences takes a list of schema changes, and detaches the foreign-key
+// modifications whose child or parent columns are renamed or retyped in the changeset.
+// Such foreign keys are dropped before all other changes, and created again after them,
+// as most databases reject altering a column while a constraint references it, and the
+// planned statements (and their reverse) should not interleave with the column changes.
+func DetachAlteredReferences(changes []schema.Change) []schema.Change {
+	altered := make(map[string]bool)
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok {
+			continue
+		}
+		for _, c := range m.Changes {
+			switch c := c.(type) {
+			case *schema.RenameColumn:
+				altered[qualified(m.T)+"."+c.From.Name] = true
+			case *schema.ModifyColumn:
+				if c.Change.Is(schema.ChangeType) {
+					altered[qualified(m.T)+"."+c.From.Name] = true
+				}
+			}
+		}
+	}
+	if len(altered) == 0 {
+		return changes
+	}
+	isAltered := func(t *schema.Table, columns []*schema.Column) bool {
+		for _, c := range columns {
+			if altered[qualified(t)+"."+c.Name] {
+				return true
+			}
+		}
+		return false
+	}
+	var drops, planned, adds []schema.Change
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok {
+			planned = append(planned, c)
+			continue
+		}
+		var rest, dropF, addF []schema.Change
+		for _, c := range m.Changes {
+			fk, ok := c.(*schema.ModifyForeignKey)
+			if !ok || !isAltered(m.T, fk.From.Columns) && (fk.From.RefTable == nil || !isAltered(fk.From.RefTable, fk.From.RefColumns)) {
+				rest = append(rest, c)
+				continue
+			}
+			dropF = append(dropF, &schema.DropForeignKey{F: fk.From})
+			addF = append(addF, &schema.AddForeignKey{F: fk.To})
+		}
+		if len(dropF) == 0 {
+			planned = append(planned, c)
+			continue
+		}
+		drops = append(drops, &schema.ModifyTable{T: m.T, Changes: dropF})
+		if len(rest) > 0 {
+			planned = append(planned, &schema.ModifyTable{T: m.T, Changes: rest})
+		}
+		adds = append(adds, &schema.ModifyTable{T: m.T, Changes: addF})
+	}
+	return append(append(drops, planned...), adds...)
+}
+
 // errCycle is an internal error to indicate a case of a cycle.
 var errCycle = errors.New("cycle detected")
 
@@ -138,7 +410,7 @@ func sortMap(changes []schema.Change) (map[string]int, error) {
 		}
 		progress[name] = true
 		for _, ref := range deps[name] {
-			if visit(ref.Name) {
+			if visit(qualified(ref)) {
 				return true
 			}
 		}
@@ -146,7 +418,20 @@ func sortMap(changes []schema.Change) (map[string]int, error) {
 		sorted[name] = len(sorted)
 		return false
 	}
-	for node := range deps {
+	// Tables are visited in the order they appear in the changeset,
+	// and then by name, to keep the result stable between runs.
+	nodes := make([]string, 0, len(deps))
+	for _, c := range changes {
+		if name := table(c); len(deps[name]) > 0 {
+			nodes = append(nodes, name)
+		}
+	}
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, node := range append(nodes, names...) {
 		if visit(node) {
 			return nil, errCycle
 		}
@@ -165,7 +450,7 @@ func dependencies(changes []schema.Change) (map[string][]*schema.Table, error) {
 					return nil, err
 				}
 				if fk.RefTable != change.T {
-					deps[change.T.Name] = append(deps[change.T.Name], fk.RefTable)
+					deps[qualified(change.T)] = append(deps[qualified(change.T)], fk.RefTable)
 				}
 			}
 		case *schema.DropTable:
@@ -174,7 +459,7 @@ func dependencies(changes []schema.Change) (map[string][]*schema.Table, error) {
 					return nil, err
 				}
 				if isDropped(changes, fk.RefTable) {
-					deps[fk.RefTable.Name] = append(deps[fk.RefTable.Name], fk.Table)
+					deps[qualified(fk.RefTable)] = append(deps[qualified(fk.RefTable)], fk.Table)
 				}
 			}
 		case *schema.ModifyTable:
@@ -185,14 +470,14 @@ func dependencies(changes []schema.Change) (map[string][]*schema.Table, error) {
 						return nil, err
 					}
 					if c.F.RefTable != change.T {
-						deps[change.T.Name] = append(deps[change.T.Name], c.F.RefTable)
+						deps[qualified(change.T)] = append(deps[qualified(change.T)], c.F.RefTable)
 					}
 				case *schema.ModifyForeignKey:
 					if err := checkFK(c.To); err != nil {
 						return nil, err
 					}
 					if c.To.RefTable != change.T {
-						deps[change.T.Name] = append(deps[change.T.Name], c.To.RefTable)
+						deps[qualified(change.T)] = append(deps[qualified(change.T)], c.To.RefTable)
 					}
 				}
 			}
@@ -225,19 +510,28 @@ func checkFK(fk *schema.ForeignKey) error {
 func table(change schema.Change) (t string) {
 	switch change := change.(type) {
 	case *schema.AddTable:
-		t = change.T.Name
+		t = qualified(change.T)
 	case *schema.DropTable:
-		t = change.T.Name
+		t = qualified(change.T)
 	case *schema.ModifyTable:
-		t = change.T.Name
+		t = qualified(change.T)
 	}
 	return
 }
 
+// qualified returns the qualified name of the table, in order to
+// distinguish between tables with the same name in different schemas.
+func qualified(t *schema.Table) string {
+	if t.Schema == nil || t.Schema.Name == "" {
+		return t.Name
+	}
+	return t.Schema.Name + "." + t.Name
+}
+
 // isDropped checks if the given table is marked as a deleted in the changeset.
 func isDropped(changes []schema.Change, t *schema.Table) bool {
 	for _, c := range changes {
-		if c, ok := c.(*schema.DropTable); ok && c.T.Name == t.Name {
+		if c, ok := c.(*schema.DropTable); ok && qualified(c.T) == qualified(t) {
 			return true
 		}
 	}
@@ -5,8 +5,12 @@
 package sqlx
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"testing"
 
+	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/stretchr/testify/require"
@@ -88,3 +92,186 @@ func TestDetachCycles(t *testing.T) {
 	workplaces.ForeignKeys = nil
 	require.Equal(t, deletion, planned[2:])
 }
+
+func TestDetachCycles_Stable(t *testing.T) {
+	var (
+		changes []schema.Change
+		users   = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+	)
+	for _, name := range []string{"posts", "pets", "groups", "comments"} {
+		t := schema.NewTable(name).AddColumns(schema.NewIntColumn("owner_id", "int"))
+		t.AddForeignKeys(schema.NewForeignKey(name + "_owner").AddColumns(t.Columns...).SetRefTable(users).AddRefColumns(users.Columns...))
+		changes = append(changes, &schema.AddTable{T: t})
+	}
+	changes = append(changes, &schema.AddTable{T: users}, &schema.AddTable{T: schema.NewTable("logs")})
+	// Tables that do not depend on others are planned first, in their relative order.
+	expected := []schema.Change{changes[4], changes[5], changes[0], changes[1], changes[2], changes[3]}
+	for i := 0; i < 50; i++ {
+		planned, err := DetachCycles(changes)
+		require.NoError(t, err)
+		require.Equal(t, expected, planned)
+	}
+}
+
+func TestCoalesceChanges(t *testing.T) {
+	var (
+		users = schema.NewTable("users")
+		pets  = schema.NewTable("pets")
+		c1    = &schema.AddColumn{C: schema.NewIntColumn("c1", "int")}
+		c2    = &schema.DropColumn{C: schema.NewIntColumn("c2", "int")}
+		c3    = &schema.AddIndex{I: schema.NewIndex("c3")}
+		m1    = &schema.ModifyTable{T: users, Changes: []schema.Change{c1}}
+	)
+	planned := CoalesceChanges([]schema.Change{
+		m1,
+		&schema.AddTable{T: pets},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{c2}},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{c2, c3}},
+	})
+	require.Equal(t, []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{c1, c2, c3}},
+		&schema.AddTable{T: pets},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{c2}},
+	}, planned)
+	require.Equal(t, []schema.Change{c1}, m1.Changes, "changes should not be modified")
+}
+
+func TestDetachAlteredReferences(t *testing.T) {
+	var (
+		users = schema.NewTable("users").
+			AddColumns(schema.NewIntColumn("id", "bigint"), schema.NewIntColumn("tenant", "int"))
+		pets = schema.NewTable("pets").
+			AddColumns(schema.NewIntColumn("owner_id", "int"), schema.NewIntColumn("tenant", "int"))
+		from = schema.NewForeignKey("owner").SetTable(pets).AddColumns(pets.Columns...).SetRefTable(users).AddRefColumns(users.Columns...)
+		to   = schema.NewForeignKey("owner").SetTable(pets).AddColumns(schema.NewIntColumn("user_id", "bigint"), pets.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns...)
+		c1   = &schema.ModifyColumn{From: schema.NewIntColumn("id", "int"), To: users.Columns[0], Change: schema.ChangeType}
+		c2   = &schema.RenameColumn{From: pets.Columns[0], To: to.Columns[0]}
+		c3   = &schema.AddColumn{C: schema.NewIntColumn("age", "int")}
+	)
+	changes := []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{c1}},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{c2, c3, &schema.ModifyForeignKey{From: from, To: to, Change: schema.ChangeColumn}}},
+	}
+	require.Equal(t, []schema.Change{
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.DropForeignKey{F: from}}},
+		changes[0],
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{c2, c3}},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.AddForeignKey{F: to}}},
+	}, DetachAlteredReferences(changes))
+
+	// Foreign keys that their columns were not altered are kept in place.
+	changes = []schema.Change{
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{c3, &schema.ModifyForeignKey{From: from, To: to, Change: schema.ChangeDeleteAction}}},
+	}
+	require.Equal(t, changes, DetachAlteredReferences(changes))
+}
+
+func TestApplyChanges(t *testing.T) {
+	p := &mockPlanner{
+		plan: &migrate.Plan{
+			Changes: []*migrate.Change{
+				{Cmd: "CREATE TABLE t1"},
+				{Cmd: "CREATE TABLE t2", Comment: "create t2"},
+				{Cmd: "CREATE TABLE t3"},
+			},
+		},
+		fail: map[string]error{"CREATE TABLE t2": errors.New("exists")},
+	}
+	err := ApplyChanges(context.Background(), nil, p)
+	require.EqualError(t, err, "create t2: exists")
+	var aerr *migrate.ApplyError
+	require.True(t, errors.As(err, &aerr))
+	require.Equal(t, p.plan.Changes[:1], aerr.Applied)
+	require.Equal(t, p.plan.Changes[1], aerr.Failed)
+	require.Equal(t, p.plan.Changes[2:], aerr.Pending)
+	require.Equal(t, []string{"CREATE TABLE t1", "CREATE TABLE t2"}, p.executed)
+
+	// Cancel the context after the first statement.
+	ctx, cancel := context.WithCancel(context.Background())
+	p = &mockPlanner{plan: p.plan, onExec: cancel}
+	err = ApplyChanges(ctx, nil, p)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.True(t, errors.As(err, &aerr))
+	require.Equal(t, p.plan.Changes[:1], aerr.Applied)
+	require.Nil(t, aerr.Failed)
+	require.Equal(t, p.plan.Changes[1:], aerr.Pending)
+	require.Equal(t, []string{"CREATE TABLE t1"}, p.executed)
+}
+
+func TestApplyChanges_Savepoints(t *testing.T) {
+	plan := &migrate.Plan{
+		Transactional: true,
+		Changes: []*migrate.Change{
+			{Cmd: "CREATE TABLE t1"},
+			{Cmd: "CREATE TABLE t2", Comment: "create t2"},
+			{Cmd: "CREATE TABLE t3"},
+		},
+	}
+	var reported []migrate.Progress
+	ctx := migrate.WithProgress(context.Background(), func(p migrate.Progress) {
+		if p.Done {
+			reported = append(reported, p)
+		}
+	})
+	p := &mockPlanner{plan: plan, single: true, fail: map[string]error{"CREATE TABLE t2": errors.New("exists")}}
+	err := ApplyChanges(migrate.WithSavepoints(ctx, migrate.SavepointAbort), nil, p)
+	var aerr *migrate.ApplyError
+	require.True(t, errors.As(err, &aerr))
+	require.Equal(t, plan.Changes[1], aerr.Failed)
+	require.Equal(t, []string{
+		"SAVEPOINT atlas_apply", "CREATE TABLE t1", "RELEASE SAVEPOINT atlas_apply",
+		"SAVEPOINT atlas_apply", "CREATE TABLE t2", "ROLLBACK TO SAVEPOINT atlas_apply", "RELEASE SAVEPOINT atlas_apply",
+	}, p.executed)
+	require.Len(t, reported, 2)
+	require.False(t, reported[0].RolledBack)
+	require.True(t, reported[1].RolledBack)
+
+	reported = nil
+	p = &mockPlanner{plan: plan, single: true, fail: map[string]error{"CREATE TABLE t2": errors.New("exists")}}
+	err = ApplyChanges(migrate.WithSavepoints(ctx, migrate.SavepointContinue), nil, p)
+	require.EqualError(t, err, "1 change(s) were rolled back: create t2: exists")
+	var rerr *migrate.RollbackError
+	require.True(t, errors.As(err, &rerr))
+	require.Equal(t, plan.Changes[1:2], rerr.Failed)
+	require.Equal(t, "CREATE TABLE t3", p.executed[len(p.executed)-2])
+	require.Len(t, reported, 3)
+	require.True(t, reported[1].RolledBack)
+	require.Error(t, reported[1].Err)
+
+	// Savepoints are not used for non-transactional plans.
+	p = &mockPlanner{plan: &migrate.Plan{Changes: plan.Changes}}
+	require.NoError(t, ApplyChanges(migrate.WithSavepoints(ctx, migrate.SavepointContinue), nil, p))
+	require.Equal(t, []string{"CREATE TABLE t1", "CREATE TABLE t2", "CREATE TABLE t3"}, p.executed)
+
+	// Savepoints cannot be used on connection pools.
+	reported = nil
+	p = &mockPlanner{plan: plan}
+	err = ApplyChanges(migrate.WithSavepoints(ctx, migrate.SavepointAbort), nil, p)
+	require.EqualError(t, err, "sql/sqlx: savepoints require a driver that is opened on a single connection (e.g. *sql.Tx)")
+	require.Empty(t, p.executed)
+	require.Empty(t, reported)
+}
+
+type mockPlanner struct {
+	plan     *migrate.Plan
+	fail     map[string]error
+	onExec   func()
+	single   bool
+	executed []string
+}
+
+func (m *mockPlanner) SingleConn() bool {
+	return m.single
+}
+
+func (m *mockPlanner) PlanChanges(context.Context, string, []schema.Change) (*migrate.Plan, error) {
+	return m.plan, nil
+}
+
+func (m *mockPlanner) ExecContext(_ context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	m.executed = append(m.executed, query)
+	if m.onExec != nil {
+		m.onExec()
+	}
+	return nil, m.fail[query]
+}
@@ -0,0 +1,77 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import "strings"
+
+// ReservedWord reports if the given identifier is a reserved word. The list is
+// a superset of the words that are reserved in the supported dialects and in the
+// SQL standard, as quoting an identifier that is not reserved is harmless.
+func ReservedWord(s string) bool {
+	return reserved[strings.ToUpper(s)]
+}
+
+// SimpleIdent reports if the given identifier consists only of ASCII letters,
+// digits and underscores, does not start with a digit, and contains no letters
+// that are disallowed by the given function (e.g. uppercase letters in dialects
+// that fold unquoted identifiers to lowercase). A nil function allows all letters.
+func SimpleIdent(s string, allow func(r rune) bool) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_':
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				return false
+			}
+		case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			if allow != nil && !allow(r) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+var reserved = func() map[string]bool {
+	m := make(map[string]bool)
+	for _, k := range strings.Fields(`
+		ABORT ACCESSIBLE ACTION ADD AFTER ALL ALTER ALWAYS ANALYSE ANALYZE AND ANY ARRAY AS ASC ASENSITIVE
+		ASYMMETRIC ATTACH AUTHORIZATION AUTOINCREMENT BEFORE BEGIN BETWEEN BIGINT BINARY BLOB BOTH BY CALL
+		CASCADE CASE CAST CHANGE CHAR CHARACTER CHECK COLLATE COLLATION COLUMN COLUMNS COMMIT CONCURRENTLY
+		CONDITION CONFLICT CONSTRAINT CONTINUE CONVERT CREATE CROSS CUBE CUME_DIST CURRENT CURRENT_CATALOG
+		CURRENT_DATE CURRENT_ROLE CURRENT_SCHEMA CURRENT_TIME CURRENT_TIMESTAMP CURRENT_USER CURSOR DATABASE
+		DATABASES DAY_HOUR DAY_MICROSECOND DAY_MINUTE DAY_SECOND DEC DECIMAL DECLARE DEFAULT DEFERRABLE
+		DEFERRED DELAYED DELETE DENSE_RANK DESC DESCRIBE DETACH DETERMINISTIC DISTINCT DISTINCTROW DIV DO
+		DOUBLE DROP DUAL EACH ELSE ELSEIF EMPTY ENCLOSED END ESCAPE ESCAPED EXCEPT EXCLUDE EXCLUSIVE EXISTS
+		EXIT EXPLAIN FAIL FALSE FETCH FILTER FIRST_VALUE FLOAT FLOAT4 FLOAT8 FOLLOWING FOR FORCE FOREIGN
+		FREEZE FROM FULL FULLTEXT FUNCTION GENERATED GET GLOB GRANT GROUP GROUPING GROUPS HAVING
+		HIGH_PRIORITY HOUR_MICROSECOND HOUR_MINUTE HOUR_SECOND IF IGNORE ILIKE IMMEDIATE IN INDEX INDEXED
+		INFILE INITIALLY INNER INOUT INSENSITIVE INSERT INSTEAD INT INT1 INT2 INT3 INT4 INT8 INTEGER
+		INTERSECT INTERVAL INTO IO_AFTER_GTIDS IO_BEFORE_GTIDS IS ISNULL ITERATE JOIN JSON_TABLE KEY KEYS
+		KILL LAG LAST_VALUE LATERAL LEAD LEADING LEAVE LEFT LIKE LIMIT LINEAR LINES LOAD LOCALTIME
+		LOCALTIMESTAMP LOCK LONG LONGBLOB LONGTEXT LOOP LOW_PRIORITY MASTER_BIND
+		MASTER_SSL_VERIFY_SERVER_CERT MATCH MAXVALUE MEDIUMBLOB MEDIUMINT MEDIUMTEXT MIDDLEINT
+		MINUTE_MICROSECOND MINUTE_SECOND MOD MODIFIES NATURAL NO NO_WRITE_TO_BINLOG NOT NOTHING NOTNULL
+		NTH_VALUE NTILE NULL NULLS NUMERIC OF OFFSET ON ONLY OPTIMIZE OPTIMIZER_COSTS OPTION OPTIONALLY OR
+		ORDER OTHERS OUT OUTER OUTFILE OVER OVERLAPS PARTITION PERCENT_RANK PLACING PLAN PRAGMA PRECEDING
+		PRECISION PRIMARY PROCEDURE PURGE QUERY RAISE RANGE RANK READ READS READ_WRITE REAL RECURSIVE
+		REFERENCES REGEXP REINDEX RELEASE RENAME REPEAT REPLACE REQUIRE RESIGNAL RESTRICT RETURN RETURNING
+		REVOKE RIGHT RLIKE ROLLBACK ROW ROWS ROW_NUMBER SAVEPOINT SCHEMA SCHEMAS SECOND_MICROSECOND SELECT
+		SENSITIVE SEPARATOR SESSION_USER SET SHOW SIGNAL SIMILAR SMALLINT SOME SPATIAL SPECIFIC SQL
+		SQLEXCEPTION SQLSTATE SQLWARNING SQL_BIG_RESULT SQL_CALC_FOUND_ROWS SQL_SMALL_RESULT SSL STARTING
+		STORED STRAIGHT_JOIN SYMMETRIC SYSTEM SYSTEM_USER TABLE TABLESAMPLE TEMP TEMPORARY TERMINATED THEN
+		TIES TINYBLOB TINYINT TINYTEXT TO TRAILING TRANSACTION TRIGGER TRUE UNBOUNDED UNDO UNION UNIQUE
+		UNLOCK UNSIGNED UPDATE USAGE USE USER USING UTC_DATE UTC_TIME UTC_TIMESTAMP VACUUM VALUES VARBINARY
+		VARCHAR VARCHARACTER VARIADIC VARYING VERBOSE VIEW VIRTUAL WHEN WHERE WHILE WINDOW WITH WITHOUT
+		WRITE XOR YEAR_MONTH ZEROFILL`) {
+		m[k] = true
+	}
+	return m
+}()
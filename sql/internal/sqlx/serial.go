@@ -0,0 +1,88 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	serialInspector struct {
+		schema.Inspector
+		mu *sync.Mutex
+	}
+
+	serialPlanApplier struct {
+		migrate.PlanApplier
+		mu *sync.Mutex
+	}
+)
+
+// SingleConn reports if the given ExecQuerier holds a single database connection,
+// like *sql.Tx and *sql.Conn. Unlike *sql.DB, these connections do not support
+// concurrent queries, and rows that are open block other statements.
+func SingleConn(eq schema.ExecQuerier) bool {
+	switch eq.(type) {
+	case *sql.Tx, *sql.Conn:
+		return true
+	default:
+		return false
+	}
+}
+
+// SerialInspector wraps the given Inspector and serializes its
+// inspections using the given mutex.
+func SerialInspector(i schema.Inspector, mu *sync.Mutex) schema.Inspector {
+	return &serialInspector{Inspector: i, mu: mu}
+}
+
+// InspectSchema calls the underlying InspectSchema while holding the mutex.
+func (i *serialInspector) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.Inspector.InspectSchema(ctx, name, opts)
+}
+
+// InspectRealm calls the underlying InspectRealm while holding the mutex.
+func (i *serialInspector) InspectRealm(ctx context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.Inspector.InspectRealm(ctx, opts)
+}
+
+// InspectTables calls the underlying InspectTables while holding the mutex. Note
+// that fn is called while the mutex is held, and therefore, it must not use the
+// driver that owns the mutex.
+func (i *serialInspector) InspectTables(ctx context.Context, opts *schema.InspectRealmOption, fn func(*schema.Table) error) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return schema.InspectTables(ctx, i.Inspector, opts, fn)
+}
+
+// SerialPlanApplier wraps the given PlanApplier and serializes its planning
+// and applying of changes using the given mutex. Using the same mutex for the
+// Inspector and the PlanApplier of a driver serializes all its operations.
+func SerialPlanApplier(p migrate.PlanApplier, mu *sync.Mutex) migrate.PlanApplier {
+	return &serialPlanApplier{PlanApplier: p, mu: mu}
+}
+
+// PlanChanges calls the underlying PlanChanges while holding the mutex.
+func (p *serialPlanApplier) PlanChanges(ctx context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.PlanApplier.PlanChanges(ctx, name, changes)
+}
+
+// ApplyChanges calls the underlying ApplyChanges while holding the mutex.
+func (p *serialPlanApplier) ApplyChanges(ctx context.Context, changes []schema.Change) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.PlanApplier.ApplyChanges(ctx, changes)
+}
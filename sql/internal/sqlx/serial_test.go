@@ -0,0 +1,83 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSingleConn(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	require.False(t, SingleConn(db))
+	m.ExpectBegin()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	require.True(t, SingleConn(tx))
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	require.True(t, SingleConn(conn))
+	require.False(t, SingleConn(LogExecQuerier(db, func(context.Context, string, []interface{}) {})))
+}
+
+func TestSerial(t *testing.T) {
+	var (
+		mu sync.Mutex
+		c  = &concurrent{}
+		i  = SerialInspector(c, &mu)
+		p  = SerialPlanApplier(c, &mu)
+		wg sync.WaitGroup
+	)
+	for n := 0; n < 10; n++ {
+		wg.Add(3)
+		go func() { defer wg.Done(); _, _ = i.InspectSchema(context.Background(), "", nil) }()
+		go func() { defer wg.Done(); _, _ = i.InspectRealm(context.Background(), nil) }()
+		go func() { defer wg.Done(); _ = p.ApplyChanges(context.Background(), nil) }()
+	}
+	wg.Wait()
+	require.EqualValues(t, 30, c.calls)
+	require.EqualValues(t, 1, c.max)
+}
+
+// concurrent records the maximum number of concurrent calls.
+type concurrent struct {
+	schema.Inspector
+	migrate.PlanApplier
+	active, max, calls int32
+}
+
+func (c *concurrent) enter() {
+	atomic.AddInt32(&c.calls, 1)
+	if n := atomic.AddInt32(&c.active, 1); n > atomic.LoadInt32(&c.max) {
+		atomic.StoreInt32(&c.max, n)
+	}
+	time.Sleep(time.Millisecond)
+	atomic.AddInt32(&c.active, -1)
+}
+
+func (c *concurrent) InspectSchema(context.Context, string, *schema.InspectOptions) (*schema.Schema, error) {
+	c.enter()
+	return nil, nil
+}
+
+func (c *concurrent) InspectRealm(context.Context, *schema.InspectRealmOption) (*schema.Realm, error) {
+	c.enter()
+	return nil, nil
+}
+
+func (c *concurrent) ApplyChanges(context.Context, []schema.Change) error {
+	c.enter()
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+)
+
+// Splitter splits SQL scripts into statements.
+//
+// Statements are terminated by semicolons (or by the delimiter that was set by the
+// DELIMITER command) that are not part of strings, quoted identifiers, comments or
+// dollar-quoted bodies (if supported by the Lexer). Errors report the line and the
+// column of the offending token, and statements keep their offset in the script.
+// Semicolons in BEGIN ... END blocks of CREATE TRIGGER, FUNCTION, PROCEDURE and
+// EVENT statements do not terminate the statement.
+type Splitter struct {
+	Lexer
+	// Delimiter indicates if the DELIMITER client command is supported (MySQL).
+	Delimiter bool
+}
+
+// Split splits the given script into statements.
+func (s *Splitter) Split(script string) ([]*migrate.Stmt, error) {
+	var (
+		stmts    []*migrate.Stmt
+		comments []string
+		delim    = ";"
+		start    = -1
+		first    string
+		depth    int
+		routine  bool
+		prev     string
+		tokens   = s.Tokens(script)
+	)
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.Unterminated {
+			return nil, fmt.Errorf("unterminated %s at %s", tokenName(t), position(script, t.Pos))
+		}
+		switch {
+		case start == -1 && t.Kind == TokSpace:
+			continue
+		case start == -1 && t.Kind == TokComment:
+			comments = append(comments, t.Text)
+			continue
+		case start == -1 && s.Delimiter && t.Kind == TokWord && strings.EqualFold(t.Text, "DELIMITER"):
+			end := strings.IndexByte(script[t.Pos:], '\n')
+			if end == -1 {
+				end = len(script)
+			} else {
+				end += t.Pos
+			}
+			f := strings.Fields(script[t.Pos+len(t.Text) : end])
+			if len(f) != 1 {
+				return nil, fmt.Errorf("invalid DELIMITER command at %s", position(script, t.Pos))
+			}
+			delim, comments = f[0], nil
+			for i+1 < len(tokens) && tokens[i+1].Pos < end {
+				i++
+			}
+			continue
+		}
+		if t.Kind != TokString && t.Kind != TokIdent && t.Kind != TokComment && depth == 0 && strings.HasPrefix(script[t.Pos:], delim) {
+			if start != -1 {
+				stmts = append(stmts, &migrate.Stmt{Pos: start, Text: strings.TrimSpace(script[start:t.Pos]), Comments: comments})
+			}
+			start, routine, comments = -1, false, nil
+			for i+1 < len(tokens) && tokens[i+1].Pos < t.Pos+len(delim) {
+				i++
+			}
+			continue
+		}
+		if start == -1 {
+			start, first = t.Pos, strings.ToUpper(t.Text)
+		}
+		if t.Kind != TokWord || delim != ";" {
+			continue
+		}
+		w := strings.ToUpper(t.Text)
+		switch {
+		case prev == "END" && (w == "CASE" || w == "IF" || w == "LOOP" || w == "WHILE" || w == "REPEAT"):
+			// Closing keyword of a block (e.g. END CASE).
+		case w == "TRIGGER" || w == "FUNCTION" || w == "PROCEDURE" || w == "EVENT":
+			routine = routine || first == "CREATE"
+		case w == "BEGIN" && routine, w == "CASE" && depth > 0:
+			depth++
+		case w == "END" && depth > 0:
+			// Blocks like IF, LOOP and WHILE are closed with END, but their
+			// openers are not counted, as their keywords are not unique.
+			switch next := nextWord(tokens, i); next {
+			case "IF", "LOOP", "WHILE", "REPEAT":
+			default:
+				depth--
+			}
+		}
+		prev = w
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("unterminated BEGIN block at %s", position(script, start))
+	}
+	if start != -1 {
+		stmts = append(stmts, &migrate.Stmt{Pos: start, Text: strings.TrimSpace(script[start:]), Comments: comments})
+	}
+	return stmts, nil
+}
+
+// position returns the line and the column (both 1-based)
+// of the given byte offset in the script, for error reporting.
+func position(script string, pos int) string {
+	line := strings.Count(script[:pos], "\n") + 1
+	col := pos - strings.LastIndexByte(script[:pos], '\n')
+	return fmt.Sprintf("line %d, column %d", line, col)
+}
+
+// tokenName returns a human-readable name of the token kind.
+func tokenName(t Token) string {
+	switch t.Kind {
+	case TokComment:
+		return "comment"
+	case TokIdent:
+		return "quoted identifier"
+	default:
+		return "string"
+	}
+}
+
+// nextWord returns the next word token after position i, uppercased.
+func nextWord(tokens []Token, i int) string {
+	for i++; i < len(tokens); i++ {
+		switch tokens[i].Kind {
+		case TokSpace, TokComment:
+		case TokWord:
+			return strings.ToUpper(tokens[i].Text)
+		default:
+			return ""
+		}
+	}
+	return ""
+}
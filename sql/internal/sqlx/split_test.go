@@ -0,0 +1,117 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitter_Split(t *testing.T) {
+	var (
+		mysql    = &Splitter{Lexer: Lexer{Backslash: true, HashComments: true, DashSpace: true}, Delimiter: true}
+		postgres = &Splitter{Lexer: Lexer{Dollar: true, NestedComments: true, EscapeStrings: true}}
+		sqlite   = &Splitter{Lexer: Lexer{Brackets: true}}
+	)
+	for _, tt := range []struct {
+		s     *Splitter
+		in    string
+		stmts []string
+	}{
+		{
+			s:     sqlite,
+			in:    "CREATE TABLE t (a text DEFAULT ';');\n-- comment; with semicolon\nINSERT INTO t VALUES ('a;b'); /* ; */ DROP TABLE \"x;y\"",
+			stmts: []string{"CREATE TABLE t (a text DEFAULT ';')", "INSERT INTO t VALUES ('a;b')", "DROP TABLE \"x;y\""},
+		},
+		{
+			s:     sqlite,
+			in:    ";; SELECT 1;;",
+			stmts: []string{"SELECT 1"},
+		},
+		{
+			s:  sqlite,
+			in: "CREATE TRIGGER tr AFTER INSERT ON t BEGIN\n  UPDATE t SET a = 1;\n  DELETE FROM t WHERE CASE WHEN a THEN 1 ELSE 0 END;\nEND;\nSELECT 1;",
+			stmts: []string{
+				"CREATE TRIGGER tr AFTER INSERT ON t BEGIN\n  UPDATE t SET a = 1;\n  DELETE FROM t WHERE CASE WHEN a THEN 1 ELSE 0 END;\nEND",
+				"SELECT 1",
+			},
+		},
+		{
+			s:     sqlite,
+			in:    "BEGIN; INSERT INTO t VALUES (1); END;",
+			stmts: []string{"BEGIN", "INSERT INTO t VALUES (1)", "END"},
+		},
+		{
+			s:  postgres,
+			in: "CREATE FUNCTION f() RETURNS trigger AS $$\nBEGIN\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\nSELECT $tag$;$tag$, $1;",
+			stmts: []string{
+				"CREATE FUNCTION f() RETURNS trigger AS $$\nBEGIN\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql",
+				"SELECT $tag$;$tag$, $1",
+			},
+		},
+		{
+			s:     sqlite,
+			in:    "DROP TABLE [a;b]; SELECT 1",
+			stmts: []string{"DROP TABLE [a;b]", "SELECT 1"},
+		},
+		{
+			s:     postgres,
+			in:    "/* outer /* inner; */ still; comment */ SELECT E'it\\'s;', 'a\\'; SELECT 2;",
+			stmts: []string{"SELECT E'it\\'s;', 'a\\'", "SELECT 2"},
+		},
+		{
+			s:     mysql,
+			in:    "SELECT 1--1;\nSELECT 2; -- comment;\nSELECT 3;--\n",
+			stmts: []string{"SELECT 1--1", "SELECT 2", "SELECT 3"},
+		},
+		{
+			s:  mysql,
+			in: "DELIMITER //\nCREATE PROCEDURE p() BEGIN SELECT 1; END //\nDELIMITER ;\n# comment;\nSELECT 'it\\'s;';",
+			stmts: []string{
+				"CREATE PROCEDURE p() BEGIN SELECT 1; END",
+				"SELECT 'it\\'s;'",
+			},
+		},
+		{
+			s:  mysql,
+			in: "CREATE PROCEDURE p(a int) BEGIN\n  IF a > 0 THEN SELECT 1; END IF;\n  CASE a WHEN 1 THEN SELECT 2; END CASE;\n  WHILE a > 0 DO SET a = a - 1; END WHILE;\nEND;\nCREATE TABLE t (a int);",
+			stmts: []string{
+				"CREATE PROCEDURE p(a int) BEGIN\n  IF a > 0 THEN SELECT 1; END IF;\n  CASE a WHEN 1 THEN SELECT 2; END CASE;\n  WHILE a > 0 DO SET a = a - 1; END WHILE;\nEND",
+				"CREATE TABLE t (a int)",
+			},
+		},
+	} {
+		stmts, err := tt.s.Split(tt.in)
+		require.NoError(t, err, tt.in)
+		require.Len(t, stmts, len(tt.stmts))
+		for i := range stmts {
+			require.Equal(t, tt.stmts[i], stmts[i].Text)
+		}
+	}
+
+	stmts, err := sqlite.Split("-- first\n/* second */\nSELECT 1;\n-- third\nSELECT 2")
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Stmt{
+		{Pos: 22, Text: "SELECT 1", Comments: []string{"-- first", "/* second */"}},
+		{Pos: 41, Text: "SELECT 2", Comments: []string{"-- third"}},
+	}, stmts)
+
+	for in, msg := range map[string]string{
+		"SELECT 1; SELECT 'a":                    "unterminated string at line 1, column 18",
+		"SELECT \"a":                             "unterminated quoted identifier at line 1, column 8",
+		"SELECT 1;\nSELECT 1 /* comment":         "unterminated comment at line 2, column 10",
+		"CREATE TRIGGER t BEGIN SELECT 1;":       "unterminated BEGIN block at line 1, column 1",
+		"DELIMITER\nSELECT 1":                    "invalid DELIMITER command at line 1, column 1",
+		"SELECT 1;\nDELIMITER $$ ;\nSELECT 2 $$": "invalid DELIMITER command at line 2, column 1",
+	} {
+		_, err := mysql.Split(in)
+		require.EqualError(t, err, msg, in)
+	}
+	_, err = postgres.Split("SELECT 1; /* a /* b */")
+	require.EqualError(t, err, "unterminated comment at line 1, column 11")
+}
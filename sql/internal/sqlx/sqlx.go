@@ -11,6 +11,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 
 	"ariga.io/atlas/sql/schema"
 )
@@ -34,12 +35,13 @@ func ScanOne(rows *sql.Rows, dest ...interface{}) error {
 
 // ScanFKs scans the rows and adds the foreign-key to the table.
 // Reference elements are added as stubs and should be linked
-// manually by the caller.
+// manually by the caller. An optional tenth column may hold the
+// MATCH option of the foreign key.
 func ScanFKs(t *schema.Table, rows *sql.Rows) error {
 	names := make(map[string]*schema.ForeignKey)
 	for rows.Next() {
-		var name, table, column, tSchema, refTable, refColumn, refSchema, updateRule, deleteRule string
-		if err := rows.Scan(&name, &table, &column, &tSchema, &refTable, &refColumn, &refSchema, &updateRule, &deleteRule); err != nil {
+		var name, table, column, tSchema, refTable, refColumn, refSchema, updateRule, deleteRule, matchOption string
+		if err := scanFK(rows, &name, &table, &column, &tSchema, &refTable, &refColumn, &refSchema, &updateRule, &deleteRule, &matchOption); err != nil {
 			return err
 		}
 		fk, ok := names[name]
@@ -50,6 +52,7 @@ func ScanFKs(t *schema.Table, rows *sql.Rows) error {
 				RefTable: t,
 				OnDelete: schema.ReferenceOption(deleteRule),
 				OnUpdate: schema.ReferenceOption(updateRule),
+				Match:    referenceMatch(matchOption),
 			}
 			if refTable != t.Name || tSchema != refSchema {
 				fk.RefTable = &schema.Table{Name: refTable, Schema: &schema.Schema{Name: refSchema}}
@@ -84,13 +87,38 @@ func ScanFKs(t *schema.Table, rows *sql.Rows) error {
 	return nil
 }
 
+// scanFK scans one foreign-key row. The last destination holds the MATCH option
+// of the foreign key, and it is scanned only if the rows contain this column.
+func scanFK(rows *sql.Rows, dest ...interface{}) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if len(columns) < len(dest) {
+		dest = dest[:len(dest)-1]
+	}
+	return rows.Scan(dest...)
+}
+
+// referenceMatch returns the match type of the given MATCH option. The default
+// type (MATCH SIMPLE, reported as NONE by the information schema) is returned as
+// an empty string, similar to how it is usually defined in the desired state.
+func referenceMatch(m string) schema.ReferenceMatch {
+	switch m := schema.ReferenceMatch(strings.ToUpper(m)); m {
+	case schema.MatchFull, schema.MatchPartial:
+		return m
+	default:
+		return ""
+	}
+}
+
 // ScanSchemaFKs scans the rows and adds the foreign-key to the schema table.
 // Reference elements are added as stubs and should be linked manually by the
 // caller.
 func ScanSchemaFKs(s *schema.Schema, rows *sql.Rows) error {
 	for rows.Next() {
-		var name, table, column, tSchema, refTable, refColumn, refSchema, updateRule, deleteRule string
-		if err := rows.Scan(&name, &table, &column, &tSchema, &refTable, &refColumn, &refSchema, &updateRule, &deleteRule); err != nil {
+		var name, table, column, tSchema, refTable, refColumn, refSchema, updateRule, deleteRule, matchOption string
+		if err := scanFK(rows, &name, &table, &column, &tSchema, &refTable, &refColumn, &refSchema, &updateRule, &deleteRule, &matchOption); err != nil {
 			return err
 		}
 		t, ok := s.Table(table)
@@ -105,9 +133,10 @@ func ScanSchemaFKs(s *schema.Schema, rows *sql.Rows) error {
 				RefTable: t,
 				OnDelete: schema.ReferenceOption(deleteRule),
 				OnUpdate: schema.ReferenceOption(updateRule),
+				Match:    referenceMatch(matchOption),
 			}
 			switch {
-			case refTable == table:
+			case refTable == table && tSchema == refSchema:
 			case tSchema == refSchema:
 				if fk.RefTable, ok = s.Table(refTable); !ok {
 					fk.RefTable = &schema.Table{Name: refTable, Schema: s}
@@ -203,6 +232,84 @@ func ValuesEqual(v1, v2 []string) bool {
 	return true
 }
 
+// A TypeParser parses a raw column type that is not recognized by the
+// driver, and reports if the type was handled by it.
+type TypeParser func(raw string) (schema.Type, bool)
+
+// ParseCustomType returns the type of the first parser that handles the raw type.
+func ParseCustomType(raw string, parsers []TypeParser) (schema.Type, bool) {
+	for _, p := range parsers {
+		if t, ok := p(raw); ok && t != nil {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// A CustomType describes a type that was registered by the user in the driver.
+type CustomType struct {
+	// Name of the type. For example, "vector" or "citext".
+	Name string
+	// Parse parses the raw definition of the type. For example, "vector(3)".
+	Parse func(raw string) (schema.Type, error)
+	// Format reports if the type is handled by this custom
+	// type, and returns its raw definition. Optional.
+	Format func(t schema.Type) (string, bool)
+}
+
+// CustomTypes holds the custom types that were registered in a driver.
+type CustomTypes struct {
+	mu    sync.RWMutex
+	types []*CustomType
+}
+
+// Register registers the custom type, and replaces an existing one with the same name.
+func (c *CustomTypes) Register(t *CustomType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.types {
+		if strings.EqualFold(c.types[i].Name, t.Name) {
+			c.types[i] = t
+			return
+		}
+	}
+	c.types = append(c.types, t)
+}
+
+// Parse parses the raw type using the custom type that is named as its
+// name (i.e. the part before the type modifiers), if it was registered.
+func (c *CustomTypes) Parse(raw string) (schema.Type, bool, error) {
+	name := raw
+	if i := strings.IndexByte(name, '('); i != -1 {
+		name = name[:i]
+	}
+	name = strings.TrimSpace(name)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, t := range c.types {
+		if strings.EqualFold(t.Name, name) {
+			typ, err := t.Parse(raw)
+			return typ, true, err
+		}
+	}
+	return nil, false, nil
+}
+
+// Format formats the type using the first custom type that handles it.
+func (c *CustomTypes) Format(typ schema.Type) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, t := range c.types {
+		if t.Format == nil {
+			continue
+		}
+		if f, ok := t.Format(typ); ok {
+			return f, true
+		}
+	}
+	return "", false
+}
+
 // VersionPermutations returns permutations of the dialect version sorted
 // from coarse to fine grained. For example:
 //
@@ -227,6 +334,12 @@ func VersionPermutations(dialect, version string) []string {
 type Builder struct {
 	bytes.Buffer
 	QuoteChar byte
+	// MustQuote, if not nil, reports if the given identifier must
+	// be quoted. If it is nil, all identifiers are quoted.
+	MustQuote func(string) bool
+	// Schema, if not empty, is used for qualifying
+	// tables that are not attached to any schema.
+	Schema string
 }
 
 // P writes a list of phrases to the builder separated and
@@ -247,26 +360,47 @@ func (b *Builder) P(phrases ...string) *Builder {
 	return b
 }
 
-// Ident writes the given string quoted as an SQL identifier.
+// Ident writes the given string quoted as an SQL identifier. Quote
+// characters in the identifier are escaped by doubling them, and the
+// quoting is skipped if the builder was configured to quote only the
+// identifiers that must be quoted.
 func (b *Builder) Ident(s string) *Builder {
 	if s != "" {
-		b.WriteByte(b.QuoteChar)
-		b.WriteString(s)
-		b.WriteByte(b.QuoteChar)
+		b.quote(s)
 		b.WriteByte(' ')
 	}
 	return b
 }
 
+// QualifiedIdent writes the given identifier qualified with the given
+// qualifier (e.g. a schema name), if it is not empty.
+func (b *Builder) QualifiedIdent(qualifier, s string) *Builder {
+	if qualifier != "" {
+		b.quote(qualifier)
+		b.WriteByte('.')
+	}
+	return b.Ident(s)
+}
+
 // Table writes the table identifier to the builder, prefixed
-// with the schema name if exists.
+// with the schema name if exists, or the default schema of the
+// builder if it was configured.
 func (b *Builder) Table(t *schema.Table) *Builder {
-	if t.Schema != nil {
-		b.Ident(t.Schema.Name)
-		b.rewriteLastByte('.')
+	qualifier := b.Schema
+	if t.Schema != nil && t.Schema.Name != "" {
+		qualifier = t.Schema.Name
 	}
-	b.Ident(t.Name)
-	return b
+	return b.QualifiedIdent(qualifier, t.Name)
+}
+
+func (b *Builder) quote(s string) {
+	if b.MustQuote != nil && !b.MustQuote(s) {
+		b.WriteString(s)
+		return
+	}
+	b.WriteByte(b.QuoteChar)
+	b.WriteString(strings.ReplaceAll(s, string(b.QuoteChar), string([]byte{b.QuoteChar, b.QuoteChar})))
+	b.WriteByte(b.QuoteChar)
 }
 
 // Comma writes a comma in case the buffer is not empty, or
@@ -326,6 +460,8 @@ func (b *Builder) Wrap(f func(b *Builder)) *Builder {
 func (b *Builder) Clone() *Builder {
 	return &Builder{
 		QuoteChar: b.QuoteChar,
+		MustQuote: b.MustQuote,
+		Schema:    b.Schema,
 		Buffer:    *bytes.NewBufferString(b.String()),
 	}
 }
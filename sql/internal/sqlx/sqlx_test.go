@@ -6,9 +6,11 @@ package sqlx
 
 import (
 	"testing"
+	"unicode"
 
 	"ariga.io/atlas/sql/schema"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -38,4 +40,127 @@ func TestBuilder(t *testing.T) {
 			})
 		})
 	require.Equal(t, `CREATE TABLE "users" ("a" int NOT NULL, "b" int NOT NULL, "c" int NOT NULL, PRIMARY KEY ("a", "b", "c"))`, b.String())
+
+	// Quote characters are escaped.
+	b = &Builder{QuoteChar: '"', Schema: "public"}
+	b.P("DROP TABLE").Table(&schema.Table{Name: `a"b`})
+	require.Equal(t, `DROP TABLE "public"."a""b"`, b.String())
+
+	// Only identifiers that must be quoted.
+	b = &Builder{QuoteChar: '"', MustQuote: func(s string) bool {
+		return ReservedWord(s) || !SimpleIdent(s, unicode.IsLower)
+	}}
+	b.P("ALTER TABLE").Table(&schema.Table{Name: "order", Schema: &schema.Schema{Name: "public"}}).
+		P("RENAME COLUMN").Ident("userId").P("TO").Ident("user_id")
+	require.Equal(t, `ALTER TABLE public."order" RENAME COLUMN "userId" TO user_id`, b.Clone().String())
+}
+
+func TestSimpleIdent(t *testing.T) {
+	for s, want := range map[string]bool{
+		"users":   true,
+		"_users1": true,
+		"1users":  false,
+		"":        false,
+		"a b":     false,
+		"a-b":     false,
+		"Users":   true,
+		"users$":  false,
+		"usérs":   false,
+	} {
+		require.Equal(t, want, SimpleIdent(s, nil), s)
+	}
+	require.False(t, SimpleIdent("Users", unicode.IsLower))
+	require.True(t, ReservedWord("order"))
+	require.False(t, ReservedWord("users"))
+}
+
+func TestScanSchemaFKs(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"name", "table", "column", "schema", "ref_table", "ref_column", "ref_schema", "update", "delete"}).
+		AddRow("auth_fk", "users", "auth_id", "app", "users", "id", "auth", "NO ACTION", "CASCADE").
+		AddRow("parent_fk", "users", "parent_id", "app", "users", "id", "app", "NO ACTION", "NO ACTION"))
+	rows, err := db.Query("SELECT")
+	require.NoError(t, err)
+	var (
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("auth_id", "int"), schema.NewIntColumn("parent_id", "int"))
+		app   = schema.New("app").AddTables(users)
+	)
+	require.NoError(t, ScanSchemaFKs(app, rows))
+	require.Len(t, users.ForeignKeys, 2)
+	// Tables with the same name in different schemas are not self-references.
+	fk := users.ForeignKeys[0]
+	require.Equal(t, "users", fk.RefTable.Name)
+	require.Equal(t, "auth", fk.RefTable.Schema.Name)
+	require.NotEqual(t, users, fk.RefTable)
+	fk = users.ForeignKeys[1]
+	require.Equal(t, users, fk.RefTable)
+	require.Equal(t, users.Columns[:1], fk.RefColumns)
+}
+
+func TestTrimParens(t *testing.T) {
+	for x, want := range map[string]string{
+		"a":              "a",
+		"(a)":            "a",
+		" ((a)) ":        "a",
+		"(a) + (b)":      "(a) + (b)",
+		"((a) + (b))":    "(a) + (b)",
+		"(now())":        "now()",
+		"('(')":          "'('",
+		"(')') + ('(')":  "(')') + ('(')",
+		"(json_array())": "json_array()",
+	} {
+		require.Equal(t, want, TrimParens(x), x)
+	}
+}
+
+func TestNormalizeExpr(t *testing.T) {
+	for x, want := range map[string]string{
+		"a > 0":                           "a > 0",
+		"(`a` > 0)":                       "a > 0",
+		`(("A" > (0)))`:                   "a > 0",
+		"((a > 0) AND (b > 0))":           "a > 0 and b > 0",
+		"((a > 0) OR (b > 0)) AND c":      "( a > 0 or b > 0 ) and c",
+		"NOT ((a > 0) AND b)":             "not ( a > 0 and b )",
+		"(a + b) * c":                     "( a + b ) * c",
+		"LOWER(`name`) != _utf8mb4'A B'":  "lower ( name ) <> 'A B'",
+		"a IN (1, (2))":                   "a in ( 1 , 2 )",
+		"(length(\"text\") > 0)":          "length ( text ) > 0",
+		"('it''s' <> 'it\\'s')":           "'it''s' <> 'it\\'s'",
+		"price > (0)::numeric":            "price > 0 :: numeric",
+		"coalesce(a, (b > 0) OR (c > 0))": "coalesce ( a , b > 0 or c > 0 )",
+	} {
+		require.Equal(t, want, NormalizeExpr(x), x)
+	}
+}
+
+func TestReadOnlyStmt(t *testing.T) {
+	for _, s := range []string{
+		"SELECT 1",
+		"\n\t-- comment\n/* multi\nline */ (SELECT 1)",
+		"select table_name from information_schema.tables where update_rule = 'CREATE'",
+		"WITH t AS (SELECT 1) SELECT * FROM t",
+		"SHOW server_version_num",
+		"SHOW CREATE TABLE `t`",
+		"PRAGMA foreign_keys",
+		"SELECT replace(name, 'a', 'b') FROM t",
+		`SELECT "into" FROM t`,
+	} {
+		require.True(t, ReadOnlyStmt(s), s)
+	}
+	for _, s := range []string{
+		"",
+		"CREATE TABLE t(c int)",
+		"INSERT INTO t VALUES (1)",
+		"REPLACE INTO t VALUES (1)",
+		"SET search_path TO public",
+		"SELECT * INTO t2 FROM t",
+		"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d",
+		"SHOW CREATE TABLE t; DROP TABLE t",
+		"EXPLAIN ANALYZE SELECT 1",
+		"PRAGMA foreign_keys = off",
+		"SELECT pg_catalog.set_config('search_path', '', false); UPDATE t SET c = 1",
+	} {
+		require.False(t, ReadOnlyStmt(s), s)
+	}
 }
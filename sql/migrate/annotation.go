@@ -0,0 +1,109 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"sort"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// An Annotation holds the provenance metadata of a schema change, such as
+	// who requested it and why. Annotations are attached to the planned changes
+	// by Annotations.Apply, are written to the migration files by the default
+	// template, and are available to ProgressFunc hooks through Progress.Change.
+	Annotation struct {
+		Author string            `json:"author,omitempty"` // Author of the change.
+		Ticket string            `json:"ticket,omitempty"` // Ticket or issue that tracks the change.
+		Reason string            `json:"reason,omitempty"` // Reason for the change.
+		Labels map[string]string `json:"labels,omitempty"` // Additional metadata.
+	}
+
+	// Annotations maps schema changes to their annotations. For example:
+	//
+	//	changes, err := drv.SchemaDiff(current, desired)
+	//	a := make(migrate.Annotations)
+	//	for _, c := range changes {
+	//		a[c] = &migrate.Annotation{Author: "a8m", Ticket: "ATL-123"}
+	//	}
+	//	plan, err := drv.PlanChanges(ctx, "add_users", changes)
+	//	a.Apply(plan)
+	//
+	Annotations map[schema.Change]*Annotation
+)
+
+// String returns the annotation in one line, suitable for SQL comments.
+//
+//	author: a8m, ticket: ATL-123, reason: add users table
+//
+func (a *Annotation) String() string {
+	var parts []string
+	for _, kv := range [][2]string{{"author", a.Author}, {"ticket", a.Ticket}, {"reason", a.Reason}} {
+		if kv[1] != "" {
+			parts = append(parts, kv[0]+": "+kv[1])
+		}
+	}
+	keys := make([]string, 0, len(a.Labels))
+	for k := range a.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+": "+a.Labels[k])
+	}
+	// Annotations are written as single-line comments.
+	return strings.Join(strings.Fields(strings.Join(parts, ", ")), " ")
+}
+
+// Apply attaches the annotations to the changes of the plan, based on the schema
+// change that caused each planned change (i.e. Change.Source). Changes that are
+// caused by a table or schema modification are annotated with the annotations
+// of the modifications they hold, as drivers may plan several of them together.
+func (a Annotations) Apply(p *Plan) {
+	for _, c := range p.Changes {
+		if c.Source == nil {
+			continue
+		}
+		c.Annotations = a.of(c.Source)
+	}
+}
+
+// of returns the annotations of the given schema change.
+func (a Annotations) of(c schema.Change) []*Annotation {
+	if an, ok := a[c]; ok {
+		return []*Annotation{an}
+	}
+	var changes []schema.Change
+	switch c := c.(type) {
+	case *schema.ModifyTable:
+		changes = c.Changes
+	case *schema.ModifySchema:
+		changes = c.Changes
+	}
+	var (
+		list []*Annotation
+		seen = make(map[*Annotation]bool)
+	)
+	for _, c := range changes {
+		for _, an := range a.of(c) {
+			if !seen[an] {
+				seen[an] = true
+				list = append(list, an)
+			}
+		}
+	}
+	// Drivers may split a table modification into
+	// multiple changes that are attached to the table.
+	if m, ok := c.(*schema.ModifyTable); ok && len(list) == 0 {
+		for c, an := range a {
+			if c, ok := c.(*schema.ModifyTable); ok && c.T == m.T {
+				list = append(list, an)
+			}
+		}
+	}
+	return list
+}
@@ -0,0 +1,214 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// directivePrefix is the prefix of the directive comments in migration files.
+const directivePrefix = "atlas:"
+
+// List of the directives that are honored by the migration directory.
+const (
+	// DirectiveDelimiter sets the delimiter of the statements in the file. Files with
+	// this directive are split by the delimiter as-is, without lexing the statements,
+	// and escape sequences are supported. For example, "-- atlas:delimiter \n\n"
+	// splits the file by empty lines.
+	DirectiveDelimiter = "delimiter"
+
+	// DirectiveTxMode sets the transaction mode of the file. See TxModeNone and TxModeFile.
+	DirectiveTxMode = "txmode"
+
+	// DirectiveNolint suppresses the given lint rules (e.g. the names of policy rules, or
+	// the classes of changes) for the file or the statement. Without arguments, all rules
+	// are suppressed. See Directives.Nolint.
+	DirectiveNolint = "nolint"
+)
+
+// List of the transaction modes of migration files.
+const (
+	// TxModeNone executes the statements of the file without a transaction. It is
+	// the default mode, and allows resuming files from their failing statement.
+	TxModeNone = "none"
+
+	// TxModeFile executes the statements of the file in one transaction, that is
+	// rolled back if one of them fails. It requires a connection that can begin
+	// transactions (e.g. *sql.DB, or a Driver that was combined with one using TxConn).
+	TxModeFile = "file"
+)
+
+// Directives maps the names of directives to their arguments. Directives are set by
+// comments in the header of migration files (file directives), or by the comments
+// that precede their statements (statement directives). For example:
+//
+//	-- atlas:txmode file
+//	-- atlas:nolint drop_table
+//
+//	DROP TABLE users;
+//
+type Directives map[string][]string
+
+// Directive returns the comment of the given directive and its arguments, as it
+// is written to migration files. It is also available to the templates of the
+// migration directory as the "directive" function.
+//
+//	migrate.Directive("txmode", "none")	// -- atlas:txmode none
+//
+func Directive(name string, args ...string) string {
+	return "-- " + directivePrefix + strings.Join(append([]string{name}, args...), " ")
+}
+
+// ParseDirectives parses the directives from the given comments. Comments that are
+// not directives are ignored, and the arguments of repeated directives are merged.
+func ParseDirectives(comments ...string) Directives {
+	var d Directives
+	for _, c := range comments {
+		c = strings.TrimSpace(c)
+		switch {
+		case strings.HasPrefix(c, "--"):
+			c = c[2:]
+		case strings.HasPrefix(c, "#"):
+			c = c[1:]
+		case strings.HasPrefix(c, "/*") && strings.HasSuffix(c, "*/"):
+			c = c[2 : len(c)-2]
+		default:
+			continue
+		}
+		c = strings.TrimSpace(c)
+		if !strings.HasPrefix(c, directivePrefix) {
+			continue
+		}
+		f := strings.Fields(c[len(directivePrefix):])
+		if len(f) == 0 {
+			continue
+		}
+		if d == nil {
+			d = make(Directives)
+		}
+		d[f[0]] = append(d[f[0]], f[1:]...)
+	}
+	return d
+}
+
+// FileDirectives returns the directives of the given migration script. That is, the
+// directives in the comment lines that precede the first statement of the script.
+func FileDirectives(script string) Directives {
+	var comments []string
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !isLineComment(line) {
+			break
+		}
+		if line != "" {
+			comments = append(comments, line)
+		}
+	}
+	return ParseDirectives(comments...)
+}
+
+// Directives returns the directives of the statement.
+func (s *Stmt) Directives() Directives {
+	return ParseDirectives(s.Comments...)
+}
+
+// Has reports if the directive with the given name exists.
+func (d Directives) Has(name string) bool {
+	_, ok := d[name]
+	return ok
+}
+
+// Nolint reports if the given lint rule is suppressed by the nolint directive.
+func (d Directives) Nolint(rule string) bool {
+	args, ok := d[DirectiveNolint]
+	if !ok {
+		return false
+	}
+	if len(args) == 0 {
+		return true
+	}
+	for _, a := range args {
+		if a == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Delimiter returns the delimiter that was set by the delimiter directive, or an
+// empty string if it was not set.
+func (d Directives) Delimiter() (string, error) {
+	args, ok := d[DirectiveDelimiter]
+	if !ok {
+		return "", nil
+	}
+	if len(args) != 1 {
+		return "", fmt.Errorf("directive %q expects one argument, got %d", DirectiveDelimiter, len(args))
+	}
+	return unescape.Replace(args[0]), nil
+}
+
+// TxMode returns the transaction mode that was set by the txmode directive,
+// or TxModeNone if it was not set.
+func (d Directives) TxMode() (string, error) {
+	args, ok := d[DirectiveTxMode]
+	if !ok {
+		return TxModeNone, nil
+	}
+	if len(args) != 1 || args[0] != TxModeNone && args[0] != TxModeFile {
+		return "", fmt.Errorf("directive %q expects %q or %q, got %q", DirectiveTxMode, TxModeNone, TxModeFile, strings.Join(args, " "))
+	}
+	return args[0], nil
+}
+
+// unescape replaces the escape sequences that are supported in directive arguments.
+var unescape = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\\`, `\`)
+
+// isLineComment reports if the given (trimmed) line is a single-line comment.
+func isLineComment(line string) bool {
+	return strings.HasPrefix(line, "--") || strings.HasPrefix(line, "#")
+}
+
+// splitDelim splits the given script by the delimiter as-is. The comment lines that
+// precede each statement are skipped, and are kept as the comments of the statement.
+func splitDelim(script, delim string) []*Stmt {
+	var stmts []*Stmt
+	for pos := 0; pos < len(script); {
+		var comments []string
+		for pos < len(script) {
+			end := strings.IndexByte(script[pos:], '\n')
+			if end == -1 {
+				end = len(script)
+			} else {
+				end += pos
+			}
+			line := strings.TrimSpace(script[pos:end])
+			if line != "" && !isLineComment(line) {
+				break
+			}
+			if line != "" {
+				comments = append(comments, line)
+			}
+			pos = end + 1
+		}
+		if pos >= len(script) {
+			break
+		}
+		end := strings.Index(script[pos:], delim)
+		if end == -1 {
+			end = len(script)
+		} else {
+			end += pos
+		}
+		text := strings.TrimSpace(script[pos:end])
+		if text != "" {
+			start := pos + strings.Index(script[pos:end], text)
+			stmts = append(stmts, &Stmt{Pos: start, Text: text, Comments: comments})
+		}
+		pos = end + len(delim)
+	}
+	return stmts
+}
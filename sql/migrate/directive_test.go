@@ -0,0 +1,57 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileDirectives(t *testing.T) {
+	d := migrate.FileDirectives(`
+-- atlas:txmode file
+# atlas:nolint drop_table
+-- Comments are ignored.
+-- atlas:delimiter \n\n
+CREATE TABLE t(c int);
+-- atlas:nolint drop_column
+ALTER TABLE t DROP COLUMN c;
+`)
+	require.Equal(t, migrate.Directives{"txmode": {"file"}, "nolint": {"drop_table"}, "delimiter": {`\n\n`}}, d)
+	mode, err := d.TxMode()
+	require.NoError(t, err)
+	require.Equal(t, migrate.TxModeFile, mode)
+	delim, err := d.Delimiter()
+	require.NoError(t, err)
+	require.Equal(t, "\n\n", delim)
+	require.True(t, d.Nolint("drop_table"))
+	require.False(t, d.Nolint("drop_column"))
+
+	d = migrate.FileDirectives("CREATE TABLE t(c int);\n-- atlas:txmode file\n")
+	require.Nil(t, d)
+	mode, err = d.TxMode()
+	require.NoError(t, err)
+	require.Equal(t, migrate.TxModeNone, mode)
+
+	_, err = migrate.ParseDirectives("-- atlas:txmode statement").TxMode()
+	require.EqualError(t, err, `directive "txmode" expects "none" or "file", got "statement"`)
+	_, err = migrate.ParseDirectives("-- atlas:delimiter").Delimiter()
+	require.EqualError(t, err, `directive "delimiter" expects one argument, got 0`)
+}
+
+func TestStmt_Directives(t *testing.T) {
+	s := &migrate.Stmt{
+		Text:     "DROP TABLE t",
+		Comments: []string{"-- Drop the table.", "/* atlas:nolint drop_table */", "-- atlas:nolint no-drops"},
+	}
+	d := s.Directives()
+	require.Equal(t, migrate.Directives{"nolint": {"drop_table", "no-drops"}}, d)
+	require.True(t, d.Nolint("no-drops"))
+	require.True(t, migrate.ParseDirectives("-- atlas:nolint").Nolint("drop_table"))
+	require.Equal(t, "-- atlas:nolint drop_table", migrate.Directive(migrate.DirectiveNolint, "drop_table"))
+}
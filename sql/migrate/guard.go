@@ -0,0 +1,33 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import "context"
+
+// guardsKey is the context key used for requesting existence guards.
+type guardsKey struct{}
+
+// WithGuards returns a new context that requests existence guards. Passing this context
+// to PlanApplier.PlanChanges instructs the PostgreSQL driver to guard the planned statements
+// with existence checks, using the IF [NOT] EXISTS clauses or catalog checks in DO blocks
+// for statements that do not support them (e.g. adding constraints). Guarded plans can be
+// re-applied on databases that already contain some of their changes, for example, when
+// the database is managed partly by other tools. Note that renames are not guarded.
+//
+// The MySQL and SQLite drivers guard only the statements that support the IF [NOT] EXISTS
+// clauses (e.g. creating tables or indexes), and fail planning table modifications that
+// cannot be guarded (e.g. adding columns).
+//
+//	plan, err := drv.PlanChanges(migrate.WithGuards(ctx), "add_users", changes)
+//
+func WithGuards(ctx context.Context) context.Context {
+	return context.WithValue(ctx, guardsKey{}, true)
+}
+
+// GuardsFromContext reports if existence guards were requested by the context.
+func GuardsFromContext(ctx context.Context) bool {
+	v, _ := ctx.Value(guardsKey{}).(bool)
+	return v
+}
@@ -0,0 +1,172 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A Snapshot is a state of a database that was stored at a specific time. For
+	// example, an HCL file that was written by a periodic export job, or a SQL dump
+	// that is replayed on a dev database using a Dir.
+	Snapshot struct {
+		// Name identifies the snapshot, e.g. its file name.
+		Name string
+
+		// Time when the snapshot was taken.
+		Time time.Time
+
+		// State reads the stored state of the database.
+		State StateReader
+	}
+
+	// History holds the snapshots of a database, in any order.
+	History []*Snapshot
+
+	// A SinceReport describes what was changed in a database since a snapshot was
+	// taken. It is intended for audits and incident investigations.
+	SinceReport struct {
+		// Snapshot the current state was compared with.
+		Snapshot *Snapshot
+
+		// Changes that bring the database from the snapshot state to its current state.
+		Changes []schema.Change
+
+		// Drift describes the objects that were changed since the snapshot. Note that
+		// the snapshot is the desired state of the report. i.e. Extra holds the objects
+		// that were added since the snapshot, and Missing holds the objects that were
+		// dropped since then.
+		Drift *schema.DriftReport
+	}
+)
+
+// LoadHistory loads the snapshots that match the given pattern (see fs.Glob) from fsys.
+// The time of each snapshot is read from the Unix timestamp that prefixes its file name
+// (e.g. "1650000000_prod.hcl"), similar to the default naming of migration files, and
+// the read function returns the reader of its state.
+//
+//	h, err := migrate.LoadHistory(os.DirFS("snapshots"), "*.hcl", func(name string, data []byte) (migrate.StateReader, error) {
+//		var r schema.Realm
+//		if err := mysql.UnmarshalSpec(data, schemahcl.Unmarshal, &r); err != nil {
+//			return nil, err
+//		}
+//		return migrate.Realm(&r), nil
+//	})
+//
+func LoadHistory(fsys fs.FS, pattern string, read func(name string, data []byte) (StateReader, error)) (History, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	h := make(History, 0, len(files))
+	for _, f := range files {
+		ts := path.Base(f)
+		if i := strings.IndexByte(ts, '_'); i != -1 {
+			ts = ts[:i]
+		}
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: snapshot %q is not prefixed with a timestamp", f)
+		}
+		buf, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: read snapshot %q: %w", f, err)
+		}
+		state, err := read(f, buf)
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: read snapshot %q: %w", f, err)
+		}
+		h = append(h, &Snapshot{Name: f, Time: time.Unix(sec, 0), State: state})
+	}
+	return h, nil
+}
+
+// At returns the last snapshot that was taken at or before the given time, or nil.
+func (h History) At(t time.Time) *Snapshot {
+	var last *Snapshot
+	for _, s := range h {
+		if !s.Time.After(t) && (last == nil || s.Time.After(last.Time)) {
+			last = s
+		}
+	}
+	return last
+}
+
+// Since compares the current state of the database with the snapshot that was taken at
+// (or last before) the given time, and reports what was changed since then.
+//
+//	r, err := h.Since(ctx, drv, migrate.InspectState(drv, nil), time.Now().Add(-24*time.Hour))
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(r)
+//
+func (h History) Since(ctx context.Context, d schema.Differ, current StateReader, t time.Time) (*SinceReport, error) {
+	s := h.At(t)
+	if s == nil {
+		return nil, fmt.Errorf("sql/migrate: no snapshot was taken at or before %s", t.Format(time.RFC3339))
+	}
+	return Since(ctx, d, current, s)
+}
+
+// Since compares the current state of the database with the given snapshot, and
+// reports what was changed since it was taken.
+func Since(ctx context.Context, d schema.Differ, current StateReader, s *Snapshot) (*SinceReport, error) {
+	from, err := s.State.ReadState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql/migrate: read snapshot %q: %w", s.Name, err)
+	}
+	to, err := current.ReadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := d.RealmDiff(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &SinceReport{Snapshot: s, Changes: changes, Drift: schema.Drift(to, from)}, nil
+}
+
+// InspectState returns a StateReader that inspects the current state of the database.
+func InspectState(i schema.Inspector, opts *schema.InspectRealmOption) StateReader {
+	return StateReaderFunc(func(ctx context.Context) (*schema.Realm, error) {
+		return i.InspectRealm(ctx, opts)
+	})
+}
+
+// Empty reports if nothing was changed since the snapshot.
+func (r *SinceReport) Empty() bool {
+	return len(r.Changes) == 0 && r.Drift.Empty()
+}
+
+// String returns a multiline description of the report.
+//
+//	changed since "1650000000_prod.hcl" (2022-04-15T05:20:00Z):
+//	added column "public.users.age"
+//	modified column "public.users.name": type "varchar(100)" -> "text"
+//
+func (r *SinceReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "changed since %q (%s):\n", r.Snapshot.Name, r.Snapshot.Time.UTC().Format(time.RFC3339))
+	for _, o := range r.Drift.Extra {
+		fmt.Fprintf(&b, "added %s\n", o)
+	}
+	for _, o := range r.Drift.Missing {
+		fmt.Fprintf(&b, "dropped %s\n", o)
+	}
+	for _, a := range r.Drift.Modified {
+		fmt.Fprintf(&b, "modified %s: %s %q -> %q\n", &a.DriftObject, a.Attr, a.Desired, a.Current)
+	}
+	return b.String()
+}
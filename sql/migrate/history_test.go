@@ -0,0 +1,69 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory_Since(t *testing.T) {
+	var (
+		ctx = context.Background()
+		f   = &mockFS{files: []struct{ N, F string }{
+			{N: "1650000000_prod.txt", F: "users"},
+			{N: "1650086400_prod.txt", F: "users,pets"},
+		}}
+		read = func(_ string, data []byte) (migrate.StateReader, error) {
+			s := schema.New("public")
+			for _, name := range strings.Split(string(data), ",") {
+				s.AddTables(schema.NewTable(name).AddColumns(intColumn("id", "int")))
+			}
+			return migrate.Realm(schema.NewRealm(s)), nil
+		}
+	)
+	h, err := migrate.LoadHistory(f, "*.txt", read)
+	require.NoError(t, err)
+	require.Len(t, h, 2)
+	require.Nil(t, h.At(time.Unix(1649999999, 0)))
+	require.Equal(t, "1650000000_prod.txt", h.At(time.Unix(1650000000, 0)).Name)
+	require.Equal(t, "1650000000_prod.txt", h.At(time.Unix(1650086399, 0)).Name)
+	require.Equal(t, "1650086400_prod.txt", h.At(time.Unix(1750000000, 0)).Name)
+
+	current := schema.New("public").AddTables(
+		schema.NewTable("users").AddColumns(intColumn("id", "bigint")),
+		schema.NewTable("groups").AddColumns(intColumn("id", "int")),
+	)
+	m := &mockDriver{changes: []schema.Change{&schema.AddTable{T: current.Tables[1]}}}
+	r, err := h.Since(ctx, m, migrate.Realm(schema.NewRealm(current)), time.Unix(1650100000, 0))
+	require.NoError(t, err)
+	require.False(t, r.Empty())
+	require.Equal(t, m.changes, r.Changes)
+	require.Equal(t, `changed since "1650086400_prod.txt" (2022-04-16T05:20:00Z):
+added table "public.groups"
+dropped table "public.pets"
+modified column "public.users.id": type "int" -> "bigint"
+`, r.String())
+
+	_, err = h.Since(ctx, m, migrate.Realm(schema.NewRealm(current)), time.Unix(0, 0))
+	require.EqualError(t, err, "sql/migrate: no snapshot was taken at or before 1970-01-01T00:00:00Z")
+
+	f.files = append(f.files, struct{ N, F string }{N: "prod.txt", F: "users"})
+	_, err = migrate.LoadHistory(f, "*.txt", read)
+	require.EqualError(t, err, `sql/migrate: snapshot "prod.txt" is not prefixed with a timestamp`)
+}
+
+func intColumn(name, typ string) *schema.Column {
+	c := schema.NewIntColumn(name, typ)
+	c.Type.Raw = typ
+	return c
+}
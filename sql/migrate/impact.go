@@ -0,0 +1,142 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+)
+
+type (
+	// An Impact describes the estimated impact of executing a planned change on
+	// its table. Impacts are estimated by the drivers from the catalog statistics
+	// and the server version, and are not accurate. They are intended to help
+	// operators to detect risky changes and schedule them accordingly.
+	Impact struct {
+		// Table that is affected by the change, qualified with
+		// its schema name if it is known.
+		Table string `json:"table"`
+
+		// Rows is the approximate number of rows in the table, based on
+		// the statistics of the database, or -1 if it is not known.
+		Rows int64 `json:"rows"`
+
+		// Rewrite reports if the table (and its indexes) are
+		// rewritten by the change.
+		Rewrite bool `json:"rewrite,omitempty"`
+
+		// Scan reports if the rows of the table are scanned by the
+		// change, for example, to validate constraints or build indexes.
+		Scan bool `json:"scan,omitempty"`
+
+		// Lock is the lock level that is held on the table while the
+		// change is executed. Note that changes that neither rewrite
+		// nor scan the table hold their lock for a short time, but
+		// they may wait for (and block) other transactions.
+		Lock LockLevel `json:"lock"`
+
+		// Reason explains the estimation.
+		Reason string `json:"reason,omitempty"`
+	}
+
+	// LockLevel describes which operations are blocked by a lock.
+	LockLevel uint8
+
+	// impactKey is the context key used for requesting impact estimation.
+	impactKey struct{}
+)
+
+// List of lock levels.
+const (
+	// LockNone means that concurrent reads and writes are allowed.
+	LockNone LockLevel = iota
+	// LockWrite means that concurrent writes are blocked.
+	LockWrite
+	// LockExclusive means that concurrent reads and writes are blocked.
+	LockExclusive
+)
+
+// String implements the fmt.Stringer interface.
+func (l LockLevel) String() string {
+	switch l {
+	case LockNone:
+		return "none"
+	case LockWrite:
+		return "write"
+	case LockExclusive:
+		return "exclusive"
+	default:
+		return fmt.Sprintf("LockLevel(%d)", l)
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (l LockLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (l *LockLevel) UnmarshalText(text []byte) error {
+	for _, v := range []LockLevel{LockNone, LockWrite, LockExclusive} {
+		if v.String() == string(text) {
+			*l = v
+			return nil
+		}
+	}
+	return fmt.Errorf("sql/migrate: unknown lock level %q", text)
+}
+
+// Blocking reports if the change blocks writes to the table for a duration
+// that depends on its size. That is, the table is rewritten or scanned while
+// a lock that blocks writes is held.
+func (i *Impact) Blocking() bool {
+	return (i.Rewrite || i.Scan) && i.Lock != LockNone
+}
+
+// String implements the fmt.Stringer interface.
+//
+//	table "users" (~1000 rows): rewrite, exclusive lock (column type change)
+//
+func (i *Impact) String() string {
+	s := fmt.Sprintf("table %q", i.Table)
+	if i.Rows >= 0 {
+		s += fmt.Sprintf(" (~%d rows)", i.Rows)
+	}
+	s += ": "
+	switch {
+	case i.Rewrite:
+		s += "rewrite, "
+	case i.Scan:
+		s += "scan, "
+	}
+	s += i.Lock.String() + " lock"
+	if i.Reason != "" {
+		s += " (" + i.Reason + ")"
+	}
+	return s
+}
+
+// WithImpact returns a new context that requests impact estimation. Passing this
+// context to PlanApplier.PlanChanges instructs the MySQL and PostgreSQL drivers to
+// estimate the impact of each planned change that modifies an existing table, and to
+// set it in Change.Impact. Note that the drivers query the database for the table
+// statistics, and the estimation depends on the server version.
+//
+//	plan, err := drv.PlanChanges(migrate.WithImpact(ctx), "dry-run", changes)
+//	for _, c := range plan.Changes {
+//		if c.Impact != nil && c.Impact.Blocking() {
+//			fmt.Println(c.Cmd, c.Impact)
+//		}
+//	}
+//
+func WithImpact(ctx context.Context) context.Context {
+	return context.WithValue(ctx, impactKey{}, true)
+}
+
+// ImpactFromContext reports if impact estimation was requested by the context.
+func ImpactFromContext(ctx context.Context) bool {
+	v, _ := ctx.Value(impactKey{}).(bool)
+	return v
+}
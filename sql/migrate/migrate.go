@@ -7,6 +7,7 @@ package migrate
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -56,6 +57,14 @@ type (
 
 		// The Source that caused this change, or nil.
 		Source schema.Change
+
+		// Annotations holds the provenance metadata of the source
+		// change, if it was annotated. See Annotations.Apply.
+		Annotations []*Annotation
+
+		// Impact holds the estimated impact of the change on its
+		// table, if it was requested by the caller. See WithImpact.
+		Impact *Impact
 	}
 )
 
@@ -80,7 +89,9 @@ type (
 
 		// ApplyChanges is responsible for applying the given changeset.
 		// An error may return from ApplyChanges if the driver is unable
-		// to execute a change.
+		// to execute a change, or if the context was canceled. In this
+		// case, the builtin drivers return an *ApplyError that describes
+		// which changes were applied before the execution was stopped.
 		ApplyChanges(context.Context, []schema.Change) error
 	}
 
@@ -94,6 +105,38 @@ type (
 		ReadState(ctx context.Context) (*schema.Realm, error)
 	}
 
+	// StmtSplitter is an optional interface that can be implemented by drivers to
+	// split migration scripts into statements. If the Driver that is configured
+	// for the Dir implements it, migration files are executed statement by statement.
+	StmtSplitter interface {
+		SplitStmts(script string) ([]*Stmt, error)
+	}
+
+	// TxBeginner is the interface that is required by the Dir for executing migration
+	// files in transactions (see TxModeFile). It is implemented by *sql.DB and *sql.Conn.
+	// Drivers do not implement it, and can be combined with one using TxConn.
+	TxBeginner interface {
+		BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+	}
+
+	// txConn is a connection that begins its transactions using a TxBeginner.
+	txConn struct {
+		schema.ExecQuerier
+		TxBeginner
+	}
+
+	// A Stmt is a statement in a migration script.
+	Stmt struct {
+		// Pos is the byte offset of the statement in the script.
+		Pos int
+
+		// Text of the statement, without its delimiter.
+		Text string
+
+		// Comments that precede the statement, if exist.
+		Comments []string
+	}
+
 	// The StateReaderFunc type is an adapter to allow the use of
 	// ordinary functions as state readers.
 	StateReaderFunc func(ctx context.Context) (*schema.Realm, error)
@@ -107,6 +150,61 @@ func (f StateReaderFunc) ReadState(ctx context.Context) (*schema.Realm, error) {
 // ErrNoPlan is returned by Plan when there is no change between the two states.
 var ErrNoPlan = errors.New("sql/migrate: no plan for matched states")
 
+// ApplyError is returned by PlanApplier.ApplyChanges when the execution of a plan
+// was stopped in the middle, either because a statement failed or because the
+// context was canceled. It describes which changes were applied, in order to
+// allow callers to resume the execution safely.
+//
+// Note that the applied changes are committed to the database, unless the caller
+// executed them within a transaction that was rolled back.
+type ApplyError struct {
+	// Plan is the plan that was executed.
+	Plan *Plan
+
+	// Applied holds the changes that were executed successfully.
+	Applied []*Change
+
+	// Failed is the change that failed to execute, or nil
+	// if the execution was canceled between statements.
+	Failed *Change
+
+	// Pending holds the changes that were not executed, excluding the failed one.
+	Pending []*Change
+
+	// Err is the underlying error.
+	Err error
+}
+
+// NewApplyError returns an ApplyError for the given plan, that was stopped at
+// the change in position i. The failed argument is nil if the change at this
+// position was not executed at all.
+func NewApplyError(plan *Plan, i int, failed *Change, err error) *ApplyError {
+	pending := plan.Changes[i:]
+	if failed != nil {
+		pending = plan.Changes[i+1:]
+	}
+	return &ApplyError{
+		Plan:    plan,
+		Applied: plan.Changes[:i],
+		Failed:  failed,
+		Pending: pending,
+		Err:     err,
+	}
+}
+
+// Error implements the error interface.
+func (e *ApplyError) Error() string {
+	if e.Failed != nil && e.Failed.Comment != "" {
+		return fmt.Sprintf("%s: %v", e.Failed.Comment, e.Err)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ApplyError) Unwrap() error {
+	return e.Err
+}
+
 // Realm returns a state reader for the static Realm object.
 func Realm(r *schema.Realm) StateReader {
 	return StateReaderFunc(func(context.Context) (*schema.Realm, error) {
@@ -132,7 +230,9 @@ type (
 		fs        fs.FS
 		conn      Driver
 		pattern   string
+		format    func(string) string
 		templates []struct{ N, T *template.Template }
+		blocks    []string
 	}
 
 	// DirOption allows configuring the Dir
@@ -158,6 +258,11 @@ func NewDir(opts ...DirOption) (*Dir, error) {
 	if len(d.templates) == 0 {
 		d.templates = []struct{ N, T *template.Template }{defaultTemplate}
 	}
+	if len(d.blocks) > 0 {
+		if err := d.defineBlocks(); err != nil {
+			return nil, err
+		}
+	}
 	return d, nil
 }
 
@@ -234,6 +339,21 @@ func DirConn(conn Driver) DirOption {
 	}
 }
 
+// DirFormatter configures a function for formatting the statements (and their
+// reverse) of plans that are written to the directory. For example:
+//
+//	migrate.NewDir(
+//		migrate.DirPath("migrations"),
+//		migrate.DirFormatter(mysql.FormatStmt),
+//	)
+//
+func DirFormatter(f func(string) string) DirOption {
+	return func(d *Dir) error {
+		d.format = f
+		return nil
+	}
+}
+
 var (
 	// TemplateFuncs defines the global functions available for the templates.
 	TemplateFuncs = template.FuncMap{
@@ -241,6 +361,7 @@ var (
 		"timestamp": func() int64 {
 			return time.Now().Unix()
 		},
+		"directive": Directive,
 	}
 	defaultTemplate = struct {
 		N, T *template.Template
@@ -251,13 +372,23 @@ var (
 		T: template.Must(template.New("name").
 			Funcs(TemplateFuncs).
 			Parse(`
-{{- range $c := .Changes }}
-	{{- $cmd := $c.Cmd }}
-	{{- if not (hasSuffix $c.Cmd ";") }}
-		{{- $cmd = print $cmd ";" }}
+{{- block "header" . }}{{ end }}
+{{- range $i, $c := .Changes }}
+	{{- if $i }}
+		{{- block "separator" $c }}{{ end }}
+	{{- end }}
+	{{- block "change" $c }}
+		{{- range $a := .Annotations }}
+			{{- println "--" $a.String }}
+		{{- end }}
+		{{- $cmd := .Cmd }}
+		{{- if not (hasSuffix .Cmd ";") }}
+			{{- $cmd = print $cmd ";" }}
+		{{- end }}
+		{{- println $cmd }}
 	{{- end }}
-	{{- println $cmd }}
-{{- end }}`)),
+{{- end }}
+{{- block "footer" . }}{{ end }}`)),
 	}
 )
 
@@ -291,6 +422,34 @@ func DirTemplates(nameFileTmpl ...string) DirOption {
 	}
 }
 
+// DirTemplateBlocks configures template definitions that override the blocks of the
+// file templates. The default template defines the following blocks, that can be used
+// for matching the house style and the tooling directives of the migration files:
+//
+//	header     executed with the Plan, before the first change. Empty by default.
+//	change     executed with each Change. Writes its annotations and its statement.
+//	separator  executed with each Change, except the first one. Empty by default.
+//	footer     executed with the Plan, after the last change. Empty by default.
+//
+// Custom templates (see DirTemplates) can define (or use) the same blocks as well.
+//
+//	migrate.NewDir(
+//		migrate.DirPath("migrations"),
+//		migrate.DirTemplateBlocks(`
+//			{{- define "header" }}
+//				{{- if not .Transactional }}{{ println (directive "txmode" "none") }}{{ end }}
+//			{{- end }}
+//			{{- define "separator" }}{{ println }}{{ end }}
+//		`),
+//	)
+//
+func DirTemplateBlocks(defs string) DirOption {
+	return func(d *Dir) error {
+		d.blocks = append(d.blocks, defs)
+		return nil
+	}
+}
+
 // ReadState reads the current database/realm state that is stored in the migration directory.
 // The given emulator driver is used for playing all migration files against to it.
 func (d *Dir) ReadState(ctx context.Context) (*schema.Realm, error) {
@@ -327,6 +486,9 @@ func (d *Dir) WritePlan(p *Plan) error {
 	if !ok {
 		return fmt.Errorf("fs.FS does not support editing: %T", d.fs)
 	}
+	if d.format != nil {
+		p = d.formatPlan(p)
+	}
 	for _, t := range d.templates {
 		var b bytes.Buffer
 		if err := t.N.Execute(&b, p); err != nil {
@@ -347,6 +509,21 @@ func (d *Dir) WritePlan(p *Plan) error {
 	return nil
 }
 
+// formatPlan returns a copy of the plan with its statements formatted.
+func (d *Dir) formatPlan(p *Plan) *Plan {
+	formatted := *p
+	formatted.Changes = make([]*Change, len(p.Changes))
+	for i, c := range p.Changes {
+		c1 := *c
+		c1.Cmd = d.format(c.Cmd)
+		if c.Reverse != "" {
+			c1.Reverse = d.format(c.Reverse)
+		}
+		formatted.Changes[i] = &c1
+	}
+	return &formatted
+}
+
 // Compact compacts the first n migration files into one. If n < 0, all files are selected.
 func (d *Dir) Compact(ctx context.Context, name string, n int) error {
 	rw, ok := d.fs.(FileRemoveWriter)
@@ -385,7 +562,7 @@ func (d *Dir) Compact(ctx context.Context, name string, n int) error {
 
 // readStateOf of first n files. If n < 0, all files are selected.
 func (d *Dir) readStateOf(ctx context.Context, n int) (*schema.Realm, []string, error) {
-	files, err := fs.Glob(d.fs, d.pattern)
+	files, err := d.files()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -395,17 +572,13 @@ func (d *Dir) readStateOf(ctx context.Context, n int) (*schema.Realm, []string,
 	case n > 0:
 		files = files[:n]
 	}
-	// Files are expected to be sorted lexicographically.
-	sort.Slice(files, func(i, j int) bool {
-		return files[i] < files[j]
-	})
 	for _, f := range files {
 		buf, err := fs.ReadFile(d.fs, f)
 		if err != nil {
 			return nil, nil, fmt.Errorf("sql/migrate: scan migration script %q: %w", f, err)
 		}
-		if _, err := d.conn.ExecContext(ctx, string(buf)); err != nil {
-			return nil, nil, fmt.Errorf("sql/migrate: execute migration script %q: %w", f, err)
+		if err := d.exec(ctx, f, string(buf)); err != nil {
+			return nil, nil, err
 		}
 	}
 	realm, err := d.conn.InspectRealm(ctx, nil)
@@ -415,6 +588,224 @@ func (d *Dir) readStateOf(ctx context.Context, n int) (*schema.Realm, []string,
 	return realm, files, nil
 }
 
+// files returns the migration files of the directory, sorted lexicographically.
+func (d *Dir) files() ([]string, error) {
+	files, err := fs.Glob(d.fs, d.pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Apply executes the migration files of the directory on the given connection, and
+// records the progress of each file in revs after every statement. Files that were
+// applied are skipped, and a file that failed in one of the previous executions is
+// resumed from its failing statement. That is, after the cause of the failure was
+// fixed manually (e.g. by fixing the data, or the failing statement), calling Apply
+// again continues the migration instead of re-running the file from scratch.
+//
+// An error is returned if one of the statements that were applied was changed since
+// it was executed, as the database state no longer matches the file. If the connection
+// implements the StmtSplitter interface, files are executed statement by statement.
+// Otherwise, each file is considered as a single statement.
+//
+// Files can control their execution using directives in their header. The delimiter
+// directive sets the delimiter of their statements, and "txmode file" executes them
+// in one transaction, if the connection implements the TxBeginner interface. Drivers
+// can be combined with the TxBeginner they were opened on using TxConn.
+//
+//	dir, err := migrate.NewDir(migrate.DirPath("migrations"))
+//	err = dir.Apply(ctx, migrate.TxConn(drv, db), migrate.RevisionFile("migrations/revisions.json"))
+//
+func (d *Dir) Apply(ctx context.Context, conn schema.ExecQuerier, revs RevisionReadWriter) error {
+	files, err := d.files()
+	if err != nil {
+		return err
+	}
+	list, err := revs.ReadRevisions(ctx)
+	if err != nil {
+		return err
+	}
+	applied := make(map[string]*Revision, len(list))
+	for _, r := range list {
+		applied[r.Version] = r
+	}
+	for _, f := range files {
+		r := applied[f]
+		if r != nil && r.Done() {
+			continue
+		}
+		buf, err := fs.ReadFile(d.fs, f)
+		if err != nil {
+			return fmt.Errorf("sql/migrate: scan migration script %q: %w", f, err)
+		}
+		script := string(buf)
+		dirs := FileDirectives(script)
+		stmts, err := d.stmts(conn, f, script, dirs)
+		if err != nil {
+			return err
+		}
+		mode, err := dirs.TxMode()
+		if err != nil {
+			return fmt.Errorf("sql/migrate: migration script %q: %w", f, err)
+		}
+		if r == nil {
+			r = &Revision{Version: f}
+		}
+		if r.Applied > len(stmts) || r.Applied > 0 && stmtsHash(stmts[:r.Applied]) != r.Hash {
+			return fmt.Errorf("sql/migrate: migration script %q was changed after %d of its statements were applied", f, r.Applied)
+		}
+		r.Total = len(stmts)
+		if mode == TxModeFile {
+			if err := d.applyTx(ctx, conn, revs, r, script, stmts); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, stmt := range stmts[r.Applied:] {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			_, err := conn.ExecContext(ctx, stmt.Text)
+			if err == nil {
+				r.Applied++
+				r.Hash = stmtsHash(stmts[:r.Applied])
+				r.Error, r.ErrorStmt = "", ""
+			} else {
+				r.Error, r.ErrorStmt = err.Error(), stmt.Text
+			}
+			r.ExecutedAt = time.Now()
+			if werr := revs.WriteRevision(ctx, r); werr != nil {
+				return werr
+			}
+			if err != nil {
+				line := strings.Count(script[:stmt.Pos], "\n") + 1
+				return fmt.Errorf("sql/migrate: execute migration script %q (line %d): %w", f, line, err)
+			}
+		}
+		// Empty files are recorded as well.
+		if r.Total == 0 {
+			r.ExecutedAt = time.Now()
+			if err := revs.WriteRevision(ctx, r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// TxConn returns a connection for Dir.Apply that executes statements using conn, and
+// begins the transactions of migration files in TxModeFile using b. Scripts are split
+// into statements by conn, if it implements the StmtSplitter interface (e.g. a Driver),
+// and the statements of files in TxModeFile are executed one by one in the transaction.
+func TxConn(conn schema.ExecQuerier, b TxBeginner) schema.ExecQuerier {
+	return &txConn{ExecQuerier: conn, TxBeginner: b}
+}
+
+// applyTx executes the remaining statements of a migration script in one transaction
+// (see TxModeFile). The revision is written after the transaction was committed, or
+// after it was rolled back with the error of the failing statement.
+func (d *Dir) applyTx(ctx context.Context, conn schema.ExecQuerier, revs RevisionReadWriter, r *Revision, script string, stmts []*Stmt) error {
+	b, ok := conn.(TxBeginner)
+	if !ok {
+		return fmt.Errorf("sql/migrate: migration script %q requires a transaction, but the connection does not support transactions", r.Version)
+	}
+	tx, err := b.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sql/migrate: begin transaction for migration script %q: %w", r.Version, err)
+	}
+	for _, stmt := range stmts[r.Applied:] {
+		if err = ctx.Err(); err == nil {
+			_, err = tx.ExecContext(ctx, stmt.Text)
+		}
+		if err != nil {
+			if rerr := tx.Rollback(); rerr != nil {
+				err = fmt.Errorf("%w: %v", err, rerr)
+			}
+			r.Error, r.ErrorStmt, r.ExecutedAt = err.Error(), stmt.Text, time.Now()
+			if werr := revs.WriteRevision(ctx, r); werr != nil {
+				return werr
+			}
+			line := strings.Count(script[:stmt.Pos], "\n") + 1
+			return fmt.Errorf("sql/migrate: execute migration script %q (line %d): %w", r.Version, line, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sql/migrate: commit migration script %q: %w", r.Version, err)
+	}
+	r.Applied, r.Hash = len(stmts), stmtsHash(stmts)
+	r.Error, r.ErrorStmt, r.ExecutedAt = "", "", time.Now()
+	return revs.WriteRevision(ctx, r)
+}
+
+// stmts returns the statements of the given migration script. Scripts that set their
+// delimiter using the delimiter directive are split by it, regardless of the connection.
+func (d *Dir) stmts(conn schema.ExecQuerier, name, script string, dirs Directives) ([]*Stmt, error) {
+	delim, err := dirs.Delimiter()
+	if err != nil {
+		return nil, fmt.Errorf("sql/migrate: migration script %q: %w", name, err)
+	}
+	if delim != "" {
+		return splitDelim(script, delim), nil
+	}
+	if c, ok := conn.(*txConn); ok {
+		conn = c.ExecQuerier
+	}
+	s, ok := conn.(StmtSplitter)
+	if !ok {
+		if strings.TrimSpace(script) == "" {
+			return nil, nil
+		}
+		return []*Stmt{{Text: script}}, nil
+	}
+	stmts, err := s.SplitStmts(script)
+	if err != nil {
+		return nil, fmt.Errorf("sql/migrate: split migration script %q: %w", name, err)
+	}
+	return stmts, nil
+}
+
+// exec executes the given migration script.
+func (d *Dir) exec(ctx context.Context, name, script string) error {
+	dirs := FileDirectives(script)
+	if _, ok := d.conn.(StmtSplitter); !ok && !dirs.Has(DirectiveDelimiter) {
+		if _, err := d.conn.ExecContext(ctx, script); err != nil {
+			return fmt.Errorf("sql/migrate: execute migration script %q: %w", name, err)
+		}
+		return nil
+	}
+	stmts, err := d.stmts(d.conn, name, script, dirs)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := d.conn.ExecContext(ctx, stmt.Text); err != nil {
+			line := strings.Count(script[:stmt.Pos], "\n") + 1
+			return fmt.Errorf("sql/migrate: execute migration script %q (line %d): %w", name, line, err)
+		}
+	}
+	return nil
+}
+
+// defineBlocks adds the block definitions to the file templates. The
+// templates are cloned, as the default template is shared between dirs.
+func (d *Dir) defineBlocks() error {
+	for i, t := range d.templates {
+		fileT, err := t.T.Clone()
+		if err != nil {
+			return err
+		}
+		for _, defs := range d.blocks {
+			if fileT, err = fileT.Parse(defs); err != nil {
+				return err
+			}
+		}
+		d.templates[i].T = fileT
+	}
+	return nil
+}
+
 func (d *Dir) addTemplate(nameTmpl, fileTmpl string) error {
 	nameT, err := template.New("name").Funcs(TemplateFuncs).Parse(nameTmpl)
 	if err != nil {
@@ -7,9 +7,11 @@ package migrate_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/sql/migrate"
@@ -118,6 +120,129 @@ func TestDir_WritePlan_Compact(t *testing.T) {
 
 }
 
+func TestDir_WritePlan_Formatter(t *testing.T) {
+	f := &mockFS{}
+	dir, err := migrate.NewDir(
+		migrate.DirFS(f),
+		migrate.DirFormatter(strings.ToLower),
+		migrate.DirTemplates("{{.Name}}.sql", `{{range $c := .Changes}}{{println $c.Cmd}}{{println $c.Reverse}}{{end}}`),
+	)
+	require.NoError(t, err)
+	plan := &migrate.Plan{
+		Name:    "add_t1",
+		Changes: []*migrate.Change{{Cmd: "CREATE TABLE T1 (C INT)", Reverse: "DROP TABLE T1"}},
+	}
+	require.NoError(t, dir.WritePlan(plan))
+	require.Equal(t, "create table t1 (c int)\ndrop table t1\n", f.files[0].F)
+	require.Equal(t, "CREATE TABLE T1 (C INT)", plan.Changes[0].Cmd, "plan should not be modified")
+}
+
+func TestDir_WritePlan_Blocks(t *testing.T) {
+	f := &mockFS{}
+	dir, err := migrate.NewDir(
+		migrate.DirFS(f),
+		migrate.DirTemplateBlocks(`
+			{{- define "header" }}
+				{{- println "-- Plan:" .Name }}
+				{{- if not .Transactional }}{{ println (directive "txmode" "none") }}{{ end }}
+			{{- end }}
+			{{- define "separator" }}{{ println }}{{ end }}
+		`),
+	)
+	require.NoError(t, err)
+	plan := &migrate.Plan{
+		Name: "add_t1_t2",
+		Changes: []*migrate.Change{
+			{Cmd: "CREATE TABLE t1 (c int)"},
+			{Cmd: "CREATE TABLE t2 (c int)", Annotations: []*migrate.Annotation{{Author: "a8m"}}},
+		},
+	}
+	require.NoError(t, dir.WritePlan(plan))
+	require.Equal(t, "-- Plan: add_t1_t2\n-- atlas:txmode none\nCREATE TABLE t1 (c int);\n\n-- author: a8m\nCREATE TABLE t2 (c int);\n", f.files[0].F)
+
+	// Blocks do not leak to other directories.
+	f.files = nil
+	dir, err = migrate.NewDir(migrate.DirFS(f))
+	require.NoError(t, err)
+	require.NoError(t, dir.WritePlan(plan))
+	require.Equal(t, "CREATE TABLE t1 (c int);\n-- author: a8m\nCREATE TABLE t2 (c int);\n", f.files[0].F)
+
+	// Custom templates can use the blocks as well.
+	f.files = nil
+	dir, err = migrate.NewDir(
+		migrate.DirFS(f),
+		migrate.DirTemplates("{{.Name}}.sql", `{{ block "header" . }}{{ end }}{{ range .Changes }}{{ println .Cmd }}{{ end }}`),
+		migrate.DirTemplateBlocks(`{{ define "header" }}{{ println "BEGIN;" }}{{ end }}`),
+	)
+	require.NoError(t, err)
+	plan.Transactional = true
+	require.NoError(t, dir.WritePlan(plan))
+	require.Equal(t, "BEGIN;\nCREATE TABLE t1 (c int)\nCREATE TABLE t2 (c int)\n", f.files[0].F)
+
+	_, err = migrate.NewDir(migrate.DirTemplateBlocks(`{{ define "header" }}`))
+	require.Error(t, err)
+}
+
+func TestAnnotations_Apply(t *testing.T) {
+	var (
+		users   = schema.NewTable("users")
+		addC    = &schema.AddColumn{C: schema.NewIntColumn("age", "int")}
+		dropC   = &schema.DropColumn{C: schema.NewIntColumn("name", "int")}
+		addT    = &schema.AddTable{T: schema.NewTable("pets")}
+		a1      = &migrate.Annotation{Author: "a8m", Ticket: "ATL-1", Reason: "add age\ncolumn"}
+		a2      = &migrate.Annotation{Author: "rotemtam", Labels: map[string]string{"team": "core", "env": "prod"}}
+		changes = []schema.Change{addT, &schema.ModifyTable{T: users, Changes: []schema.Change{addC, dropC}}}
+		plan    = &migrate.Plan{
+			Name: "annotated",
+			Changes: []*migrate.Change{
+				{Cmd: "CREATE TABLE pets (id int)", Source: addT},
+				{Cmd: "ALTER TABLE users ADD COLUMN age int, DROP COLUMN name", Source: &schema.ModifyTable{T: users, Changes: []schema.Change{addC, dropC}}},
+				{Cmd: "CREATE INDEX pets_id ON pets (id)"},
+			},
+		}
+	)
+	migrate.Annotations{addC: a1, dropC: a2, addT: a2}.Apply(plan)
+	require.Equal(t, []*migrate.Annotation{a2}, plan.Changes[0].Annotations)
+	require.Equal(t, []*migrate.Annotation{a1, a2}, plan.Changes[1].Annotations)
+	require.Empty(t, plan.Changes[2].Annotations)
+	require.Equal(t, "author: a8m, ticket: ATL-1, reason: add age column", a1.String())
+	require.Equal(t, "author: rotemtam, env: prod, team: core", a2.String())
+
+	// Annotations of the table modification.
+	p := &migrate.Plan{Changes: []*migrate.Change{{Cmd: "ALTER TABLE users", Source: &schema.ModifyTable{T: users}}}}
+	migrate.Annotations{changes[1]: a1}.Apply(p)
+	require.Equal(t, []*migrate.Annotation{a1}, p.Changes[0].Annotations)
+
+	f := &mockFS{}
+	dir, err := migrate.NewDir(migrate.DirFS(f))
+	require.NoError(t, err)
+	require.NoError(t, dir.WritePlan(plan))
+	require.Equal(t, `-- author: rotemtam, env: prod, team: core
+CREATE TABLE pets (id int);
+-- author: a8m, ticket: ATL-1, reason: add age column
+-- author: rotemtam, env: prod, team: core
+ALTER TABLE users ADD COLUMN age int, DROP COLUMN name;
+CREATE INDEX pets_id ON pets (id);
+`, f.files[0].F)
+}
+
+func TestDir_Plan_SplitStmts(t *testing.T) {
+	var (
+		m   = &splitDriver{mockDriver: &mockDriver{}}
+		ctx = context.Background()
+		f   = &mockFS{files: []struct{ N, F string }{{N: "1.sql", F: "CREATE TABLE t1(c int);\nCREATE TABLE t2(c int);\n"}}}
+	)
+	dir, err := migrate.NewDir(migrate.DirFS(f), migrate.DirConn(m))
+	require.NoError(t, err)
+	_, err = dir.Plan(ctx, "plan_name", migrate.Realm(nil))
+	require.Equal(t, migrate.ErrNoPlan, err)
+	require.Equal(t, []string{"CREATE TABLE t1(c int)", "CREATE TABLE t2(c int)"}, m.executed)
+
+	m.executed, m.fail = nil, "CREATE TABLE t2(c int)"
+	_, err = dir.Plan(ctx, "plan_name", migrate.Realm(nil))
+	require.EqualError(t, err, `sql/migrate: execute migration script "1.sql" (line 2): exec error`)
+}
+
 type mockFS struct {
 	fs.GlobFS
 	files []struct{ N, F string }
@@ -182,3 +307,29 @@ func (m mockDriver) RealmDiff(_, _ *schema.Realm) ([]schema.Change, error) {
 func (m mockDriver) PlanChanges(context.Context, string, []schema.Change) (*migrate.Plan, error) {
 	return m.plan, nil
 }
+
+type splitDriver struct {
+	*mockDriver
+	fail string
+}
+
+func (m *splitDriver) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if query == m.fail {
+		return nil, errors.New("exec error")
+	}
+	return m.mockDriver.ExecContext(ctx, query, args...)
+}
+
+func (*splitDriver) SplitStmts(script string) ([]*migrate.Stmt, error) {
+	var (
+		pos   int
+		stmts []*migrate.Stmt
+	)
+	for _, s := range strings.SplitAfter(script, ";\n") {
+		if text := strings.TrimSuffix(strings.TrimSpace(s), ";"); text != "" {
+			stmts = append(stmts, &migrate.Stmt{Pos: pos, Text: text})
+		}
+		pos += len(s)
+	}
+	return stmts, nil
+}
@@ -0,0 +1,226 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A PlanFile is a serializable plan that was computed against the current state of a
+	// database, in order to be reviewed and applied later. It records the hash of the state
+	// it was computed against, and Apply refuses to execute it if the database has drifted
+	// since planning. For example:
+	//
+	//	current, err := drv.InspectRealm(ctx, &schema.InspectRealmOption{Schemas: []string{"app"}})
+	//	changes, err := drv.RealmDiff(current, desired)
+	//	plan, err := drv.PlanChanges(migrate.WithImpact(ctx), "add_users", changes)
+	//	err = migrate.WritePlanFile(w, migrate.NewPlanFile(plan, current, "app"))
+	//
+	//	// After the plan was reviewed.
+	//	f, err := migrate.ReadPlanFile(r)
+	//	err = migrate.Apply(ctx, drv, f)
+	//
+	PlanFile struct {
+		// Version of the file format.
+		Version int `json:"version"`
+
+		// Name, Reversible and Transactional are copied from the plan.
+		Name          string `json:"name"`
+		Reversible    bool   `json:"reversible"`
+		Transactional bool   `json:"transactional"`
+
+		// Schemas holds the names of the schemas that were inspected for computing
+		// the current state. An empty list means the entire realm was inspected.
+		Schemas []string `json:"schemas,omitempty"`
+
+		// State holds the hash of the current state the plan was computed
+		// against, as returned by schema.Hash.
+		State string `json:"state"`
+
+		// Changes holds the planned changes.
+		Changes []*PlanFileChange `json:"changes"`
+
+		// CreatedAt holds the time the file was created.
+		CreatedAt time.Time `json:"created_at"`
+
+		// Sum holds the checksum of the file content,
+		// in order to detect manual edits of the file.
+		Sum string `json:"sum"`
+	}
+
+	// A PlanFileChange is the serializable form of a planned Change.
+	PlanFileChange struct {
+		Cmd         string        `json:"cmd"`
+		Args        []interface{} `json:"args,omitempty"`
+		Comment     string        `json:"comment,omitempty"`
+		Reverse     string        `json:"reverse,omitempty"`
+		Impact      *Impact       `json:"impact,omitempty"`
+		Annotations []*Annotation `json:"annotations,omitempty"`
+	}
+
+	// DriftError is returned by Apply when the current state of
+	// the database is different from the state it was planned for.
+	DriftError struct {
+		// Expected is the hash that is recorded in the plan
+		// file, and Actual is the hash of the current state.
+		Expected, Actual string
+	}
+)
+
+// planFileVersion is the version of the plan file format.
+const planFileVersion = 1
+
+// NewPlanFile returns a plan file for the given plan, that was computed against the
+// current state. The schemas argument holds the names of the schemas that the current
+// state was inspected for, and it is used by Apply for inspecting the same schemas.
+func NewPlanFile(p *Plan, current *schema.Realm, schemas ...string) *PlanFile {
+	f := &PlanFile{
+		Version:       planFileVersion,
+		Name:          p.Name,
+		Reversible:    p.Reversible,
+		Transactional: p.Transactional,
+		Schemas:       schemas,
+		State:         schema.Hash(current),
+		Changes:       make([]*PlanFileChange, len(p.Changes)),
+		CreatedAt:     time.Now().UTC(),
+	}
+	for i, c := range p.Changes {
+		f.Changes[i] = &PlanFileChange{
+			Cmd:         c.Cmd,
+			Args:        c.Args,
+			Comment:     c.Comment,
+			Reverse:     c.Reverse,
+			Impact:      c.Impact,
+			Annotations: c.Annotations,
+		}
+	}
+	f.Sum = f.sum()
+	return f
+}
+
+// Plan returns the plan that is described by the file. Note that the
+// changes of the returned plan do not hold their source schema changes.
+func (f *PlanFile) Plan() *Plan {
+	p := &Plan{
+		Name:          f.Name,
+		Reversible:    f.Reversible,
+		Transactional: f.Transactional,
+		Changes:       make([]*Change, len(f.Changes)),
+	}
+	for i, c := range f.Changes {
+		p.Changes[i] = &Change{
+			Cmd:         c.Cmd,
+			Args:        c.Args,
+			Comment:     c.Comment,
+			Reverse:     c.Reverse,
+			Impact:      c.Impact,
+			Annotations: c.Annotations,
+		}
+	}
+	return p
+}
+
+// Verify checks that the file has a supported version, and that
+// its content was not modified since it was created.
+func (f *PlanFile) Verify() error {
+	if f.Version != planFileVersion {
+		return fmt.Errorf("sql/migrate: unsupported plan file version: %d", f.Version)
+	}
+	if f.Sum != f.sum() {
+		return fmt.Errorf("sql/migrate: plan file %q checksum mismatch", f.Name)
+	}
+	return nil
+}
+
+// sum returns the checksum of the file content. Impacts and annotations
+// are informative, and are not part of the checksum.
+func (f *PlanFile) sum() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%q\n%t\n%t\n%q\n%q\n", f.Version, f.Name, f.Reversible, f.Transactional, f.Schemas, f.State)
+	for _, c := range f.Changes {
+		fmt.Fprintf(h, "%q\n%q\n%q\n", c.Cmd, fmt.Sprint(c.Args...), c.Reverse)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// WritePlanFile writes the given plan file to w in JSON format.
+func WritePlanFile(w io.Writer, f *PlanFile) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return fmt.Errorf("sql/migrate: write plan file: %w", err)
+	}
+	return nil
+}
+
+// ReadPlanFile reads a plan file from r, and verifies it.
+func ReadPlanFile(r io.Reader) (*PlanFile, error) {
+	f := &PlanFile{}
+	if err := json.NewDecoder(r).Decode(f); err != nil {
+		return nil, fmt.Errorf("sql/migrate: read plan file: %w", err)
+	}
+	if err := f.Verify(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Apply executes the changes of the plan file on the database, after verifying that the
+// current state of the database is the state the plan was computed against. A *DriftError
+// is returned if the database has drifted since planning, and no changes are executed.
+//
+// Like PlanApplier.ApplyChanges, the progress is reported to the ProgressFunc stored in
+// the context, and an *ApplyError is returned if the execution was stopped in the middle.
+func Apply(ctx context.Context, drv Driver, f *PlanFile) error {
+	if err := f.Verify(); err != nil {
+		return err
+	}
+	current, err := drv.InspectRealm(ctx, &schema.InspectRealmOption{Schemas: f.Schemas})
+	if err != nil {
+		return fmt.Errorf("sql/migrate: inspect current state: %w", err)
+	}
+	if h := schema.Hash(current); h != f.State {
+		return &DriftError{Expected: f.State, Actual: h}
+	}
+	var (
+		plan   = f.Plan()
+		report = ProgressFromContext(ctx)
+	)
+	for i, c := range plan.Changes {
+		if err := ctx.Err(); err != nil {
+			return NewApplyError(plan, i, nil, err)
+		}
+		progress := Progress{Change: c, Index: i, Total: len(plan.Changes)}
+		if report != nil {
+			report(progress)
+		}
+		_, err := drv.ExecContext(ctx, c.Cmd, c.Args...)
+		if err != nil {
+			err = NewApplyError(plan, i, c, fmt.Errorf("sql/migrate: execute %q: %w", c.Cmd, err))
+		}
+		if report != nil {
+			progress.Done, progress.Err = true, err
+			report(progress)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Error implements the error interface.
+func (e *DriftError) Error() string {
+	return fmt.Sprintf("sql/migrate: database state has drifted since planning (expected state %.12s, got %.12s)", e.Expected, e.Actual)
+}
@@ -0,0 +1,94 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanFile(t *testing.T) {
+	var (
+		users   = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		current = schema.NewRealm(schema.New("app").AddTables(users))
+		drv     = &stateDriver{mockDriver: &mockDriver{}, realm: current}
+		plan    = &migrate.Plan{
+			Name:       "add_name",
+			Reversible: true,
+			Changes: []*migrate.Change{
+				{
+					Cmd:         "ALTER TABLE users ADD COLUMN name text",
+					Reverse:     "ALTER TABLE users DROP COLUMN name",
+					Impact:      &migrate.Impact{Table: "app.users", Rows: 10, Lock: migrate.LockExclusive},
+					Annotations: []*migrate.Annotation{{Author: "a8m", Ticket: "ATL-1"}},
+				},
+				{Cmd: "CREATE INDEX name ON users (name)", Reverse: "DROP INDEX name"},
+			},
+		}
+	)
+	f := migrate.NewPlanFile(plan, current, "app")
+	require.Equal(t, schema.Hash(current), f.State)
+	require.NoError(t, f.Verify())
+
+	var buf bytes.Buffer
+	require.NoError(t, migrate.WritePlanFile(&buf, f))
+	require.Contains(t, buf.String(), `"lock": "exclusive"`)
+	f, err := migrate.ReadPlanFile(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, []string{"app"}, f.Schemas)
+	p := f.Plan()
+	require.Equal(t, plan.Name, p.Name)
+	require.True(t, p.Reversible)
+	require.Len(t, p.Changes, 2)
+	require.Equal(t, plan.Changes[0].Impact, p.Changes[0].Impact)
+	require.Equal(t, plan.Changes[0].Annotations, p.Changes[0].Annotations)
+
+	// Edited files are rejected.
+	edited := bytes.Replace(buf.Bytes(), []byte("ADD COLUMN name text"), []byte("DROP COLUMN id"), 1)
+	_, err = migrate.ReadPlanFile(bytes.NewReader(edited))
+	require.EqualError(t, err, `sql/migrate: plan file "add_name" checksum mismatch`)
+
+	// Drifted databases are rejected.
+	drv.realm = schema.NewRealm(schema.New("app").AddTables(
+		schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint")),
+	))
+	err = migrate.Apply(context.Background(), drv, f)
+	var de *migrate.DriftError
+	require.True(t, errors.As(err, &de))
+	require.Equal(t, f.State, de.Expected)
+	require.Equal(t, schema.Hash(drv.realm), de.Actual)
+	require.Empty(t, drv.executed)
+	require.Equal(t, []string{"app"}, drv.schemas)
+
+	drv.realm = schema.NewRealm(schema.New("app").AddTables(
+		schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")),
+	))
+	var progress []migrate.Progress
+	ctx := migrate.WithProgress(context.Background(), func(p migrate.Progress) {
+		progress = append(progress, p)
+	})
+	require.NoError(t, migrate.Apply(ctx, drv, f))
+	require.Equal(t, []string{"ALTER TABLE users ADD COLUMN name text", "CREATE INDEX name ON users (name)"}, drv.executed)
+	require.Len(t, progress, 4)
+	require.True(t, progress[3].Done)
+}
+
+type stateDriver struct {
+	*mockDriver
+	realm   *schema.Realm
+	schemas []string
+}
+
+func (d *stateDriver) InspectRealm(_ context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	d.schemas = opts.Schemas
+	return d.realm, nil
+}
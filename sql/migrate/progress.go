@@ -0,0 +1,63 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import "context"
+
+type (
+	// Progress describes the progress of applying a migration plan. It is
+	// reported by the drivers twice for each change in the plan: before it
+	// is executed, and after it was executed (Done is set to true).
+	Progress struct {
+		// Change is the change that is currently executed.
+		Change *Change
+
+		// Index is the position of the change in the plan,
+		// and Total is the number of changes in the plan.
+		Index, Total int
+
+		// Done reports if the change was executed.
+		Done bool
+
+		// Err holds the execution error, if there was any.
+		Err error
+
+		// RolledBack reports if the change failed and was
+		// rolled back to its savepoint. See WithSavepoints.
+		RolledBack bool
+
+		// RowsTotal holds the number of rows that are copied by the change,
+		// and RowsCopied holds the number of rows that were copied so far.
+		// Both are set only for changes that copy table rows, for example,
+		// SQLite table rewrites, and are zero otherwise.
+		RowsTotal, RowsCopied int64
+	}
+
+	// ProgressFunc is called by the drivers to report the progress of applying changes.
+	ProgressFunc func(Progress)
+
+	// progressKey is the context key used for holding the ProgressFunc.
+	progressKey struct{}
+)
+
+// WithProgress returns a new context that carries the given ProgressFunc. Passing this context
+// to PlanApplier.ApplyChanges instructs the driver to report its progress to f.
+//
+//	ctx := migrate.WithProgress(ctx, func(p migrate.Progress) {
+//		if p.Done {
+//			bar.Set(p.Index + 1)
+//		}
+//	})
+//	err := drv.ApplyChanges(ctx, changes)
+//
+func WithProgress(ctx context.Context, f ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, f)
+}
+
+// ProgressFromContext returns the ProgressFunc stored in the context, or nil if there is none.
+func ProgressFromContext(ctx context.Context) ProgressFunc {
+	f, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return f
+}
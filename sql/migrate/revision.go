@@ -0,0 +1,124 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type (
+	// A Revision records the execution state of a migration file on a database.
+	// Revisions are written by Dir.Apply after each statement that is executed,
+	// in order to allow resuming a failed migration from the failing statement.
+	Revision struct {
+		// Version is the name of the migration file.
+		Version string `json:"version"`
+
+		// Total is the number of statements in the file,
+		// and Applied is the number of statements that
+		// were executed successfully.
+		Total   int `json:"total"`
+		Applied int `json:"applied"`
+
+		// Hash is the hash of the applied statements. It is used for detecting
+		// changes in statements that were applied before the file is resumed.
+		Hash string `json:"hash"`
+
+		// Error holds the error of the last failed execution, if there
+		// is one, and ErrorStmt holds the statement that failed.
+		Error     string `json:"error,omitempty"`
+		ErrorStmt string `json:"error_stmt,omitempty"`
+
+		// ExecutedAt holds the time the revision was last updated.
+		ExecutedAt time.Time `json:"executed_at"`
+	}
+
+	// RevisionReadWriter wraps the methods for reading and writing the revisions
+	// of a database. Implementations are expected to store the revisions outside
+	// of the transaction of the migration, as they are written after each statement.
+	RevisionReadWriter interface {
+		// ReadRevisions returns all revisions that were written.
+		ReadRevisions(context.Context) ([]*Revision, error)
+		// WriteRevision creates or updates the given revision.
+		WriteRevision(context.Context, *Revision) error
+	}
+
+	// revisionFile is a RevisionReadWriter that stores revisions in a JSON file.
+	revisionFile struct {
+		path string
+	}
+)
+
+// Done reports if all statements of the file were applied.
+func (r *Revision) Done() bool {
+	return r.Applied == r.Total && r.Error == ""
+}
+
+// RevisionFile returns a RevisionReadWriter that stores the revisions in the given
+// JSON file. The file is created on the first write, if it does not exist.
+func RevisionFile(path string) RevisionReadWriter {
+	return &revisionFile{path: path}
+}
+
+// ReadRevisions implements the RevisionReadWriter interface.
+func (f *revisionFile) ReadRevisions(context.Context) ([]*Revision, error) {
+	buf, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sql/migrate: read revisions: %w", err)
+	}
+	var revs []*Revision
+	if err := json.Unmarshal(buf, &revs); err != nil {
+		return nil, fmt.Errorf("sql/migrate: decode revisions: %w", err)
+	}
+	return revs, nil
+}
+
+// WriteRevision implements the RevisionReadWriter interface. The file
+// is replaced atomically, in order to not be corrupted by crashes.
+func (f *revisionFile) WriteRevision(ctx context.Context, r *Revision) error {
+	revs, err := f.ReadRevisions(ctx)
+	if err != nil {
+		return err
+	}
+	i := 0
+	for ; i < len(revs) && revs[i].Version != r.Version; i++ {
+	}
+	if i == len(revs) {
+		revs = append(revs, r)
+	}
+	revs[i] = r
+	buf, err := json.MarshalIndent(revs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("sql/migrate: encode revisions: %w", err)
+	}
+	tmp := filepath.Join(filepath.Dir(f.path), "."+filepath.Base(f.path)+".tmp")
+	if err := os.WriteFile(tmp, buf, 0644); err != nil {
+		return fmt.Errorf("sql/migrate: write revisions: %w", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("sql/migrate: write revisions: %w", err)
+	}
+	return nil
+}
+
+// stmtsHash returns the hash of the given statements.
+func stmtsHash(stmts []*Stmt) string {
+	h := sha256.New()
+	for _, s := range stmts {
+		fmt.Fprintf(h, "%q\n", s.Text)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,137 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDir_Apply(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		m    = &splitDriver{mockDriver: &mockDriver{}, fail: "INSERT INTO t1 VALUES (1)"}
+		revs = migrate.RevisionFile(filepath.Join(t.TempDir(), "revisions.json"))
+		f    = &mockFS{files: []struct{ N, F string }{
+			{N: "1.sql", F: "CREATE TABLE t1(c int);\n"},
+			{N: "2.sql", F: "ALTER TABLE t1 ADD COLUMN d int;\nINSERT INTO t1 VALUES (1);\nCREATE TABLE t2(c int);\n"},
+			{N: "3.sql", F: "CREATE TABLE t3(c int);\n"},
+		}}
+	)
+	dir, err := migrate.NewDir(migrate.DirFS(f))
+	require.NoError(t, err)
+	err = dir.Apply(ctx, m, revs)
+	require.EqualError(t, err, `sql/migrate: execute migration script "2.sql" (line 2): exec error`)
+	require.Equal(t, []string{"CREATE TABLE t1(c int)", "ALTER TABLE t1 ADD COLUMN d int"}, m.executed)
+
+	list, err := revs.ReadRevisions(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.True(t, list[0].Done())
+	require.Equal(t, "2.sql", list[1].Version)
+	require.Equal(t, 3, list[1].Total)
+	require.Equal(t, 1, list[1].Applied)
+	require.Equal(t, "exec error", list[1].Error)
+	require.Equal(t, "INSERT INTO t1 VALUES (1)", list[1].ErrorStmt)
+	require.False(t, list[1].Done())
+
+	// Fix the failing statement, and resume from it.
+	m.executed = nil
+	f.files[1].F = "ALTER TABLE t1 ADD COLUMN d int;\nINSERT INTO t1 VALUES (1, 1);\nCREATE TABLE t2(c int);\n"
+	require.NoError(t, dir.Apply(ctx, m, revs))
+	require.Equal(t, []string{"INSERT INTO t1 VALUES (1, 1)", "CREATE TABLE t2(c int)", "CREATE TABLE t3(c int)"}, m.executed)
+	list, err = revs.ReadRevisions(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	for _, r := range list {
+		require.True(t, r.Done())
+		require.Empty(t, r.ErrorStmt)
+	}
+
+	// Applied files are skipped.
+	m.executed = nil
+	require.NoError(t, dir.Apply(ctx, m, revs))
+	require.Empty(t, m.executed)
+
+	// Statements that were applied cannot be changed.
+	m.executed, m.fail = nil, "CREATE TABLE t5(c int)"
+	f.files = append(f.files, struct{ N, F string }{N: "4.sql", F: "CREATE TABLE t4(c int);\nCREATE TABLE t5(c int);\n"})
+	require.Error(t, dir.Apply(ctx, m, revs))
+	require.Equal(t, []string{"CREATE TABLE t4(c int)"}, m.executed)
+	f.files[3].F = "CREATE TABLE t4(c bigint);\nCREATE TABLE t5(c int);\n"
+	m.fail = ""
+	err = dir.Apply(ctx, m, revs)
+	require.EqualError(t, err, `sql/migrate: migration script "4.sql" was changed after 1 of its statements were applied`)
+}
+
+func TestDir_Apply_Directives(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		m    = &mockDriver{}
+		revs = migrate.RevisionFile(filepath.Join(t.TempDir(), "revisions.json"))
+		f    = &mockFS{files: []struct{ N, F string }{
+			{N: "1.sql", F: "-- atlas:delimiter \\n--\\n\n\nCREATE TABLE t1(c int);\n--\nCREATE FUNCTION f() BEGIN SELECT 1; END\n"},
+		}}
+	)
+	dir, err := migrate.NewDir(migrate.DirFS(f))
+	require.NoError(t, err)
+	require.NoError(t, dir.Apply(ctx, m, revs))
+	require.Equal(t, []string{"CREATE TABLE t1(c int);", "CREATE FUNCTION f() BEGIN SELECT 1; END"}, m.executed)
+
+	// Files in transaction mode require a connection that can begin transactions.
+	f.files = append(f.files, struct{ N, F string }{N: "2.sql", F: "-- atlas:txmode file\nCREATE TABLE t2(c int);\nINSERT INTO t2 VALUES (1);\n"})
+	err = dir.Apply(ctx, m, revs)
+	require.EqualError(t, err, `sql/migrate: migration script "2.sql" requires a transaction, but the connection does not support transactions`)
+
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mk.ExpectBegin()
+	mk.ExpectExec("CREATE TABLE t2").WillReturnError(errors.New("exec error"))
+	mk.ExpectRollback()
+	err = dir.Apply(ctx, db, revs)
+	require.EqualError(t, err, `sql/migrate: execute migration script "2.sql" (line 1): exec error`)
+	list, err := revs.ReadRevisions(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Zero(t, list[1].Applied)
+	require.Equal(t, "exec error", list[1].Error)
+
+	mk.ExpectBegin()
+	mk.ExpectExec("CREATE TABLE t2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mk.ExpectCommit()
+	require.NoError(t, dir.Apply(ctx, db, revs))
+	require.NoError(t, mk.ExpectationsWereMet())
+	list, err = revs.ReadRevisions(ctx)
+	require.NoError(t, err)
+	require.True(t, list[1].Done())
+	require.Empty(t, list[1].Error)
+
+
+	// Drivers split the files in transaction mode, and their statements
+	// are executed one by one in the transaction of the TxBeginner.
+	f.files = append(f.files, struct{ N, F string }{N: "3.sql", F: "-- atlas:txmode file\nCREATE TABLE t3(c int);\nINSERT INTO t3 VALUES (1);\n"})
+	drv := &splitDriver{mockDriver: &mockDriver{}}
+	err = dir.Apply(ctx, drv, revs)
+	require.EqualError(t, err, `sql/migrate: migration script "3.sql" requires a transaction, but the connection does not support transactions`)
+	mk.ExpectBegin()
+	mk.ExpectExec("CREATE TABLE t3").WillReturnResult(sqlmock.NewResult(0, 0))
+	mk.ExpectExec("INSERT INTO t3 VALUES").WillReturnResult(sqlmock.NewResult(0, 1))
+	mk.ExpectCommit()
+	require.NoError(t, dir.Apply(ctx, migrate.TxConn(drv, db), revs))
+	require.NoError(t, mk.ExpectationsWereMet())
+	require.Empty(t, drv.executed)
+	list, err = revs.ReadRevisions(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	require.True(t, list[2].Done())
+	require.Equal(t, 2, list[2].Total)
+}
@@ -0,0 +1,93 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type (
+	// SavepointPolicy controls what drivers do after a change that was executed
+	// within a savepoint failed, and was rolled back to it. See WithSavepoints.
+	SavepointPolicy uint8
+
+	// RollbackError is returned by PlanApplier.ApplyChanges in case changes failed
+	// and were rolled back to their savepoints, and the execution was continued with
+	// the next changes (see SavepointContinue). All other changes were applied, and
+	// it is up to the caller to decide whether to commit the transaction or not.
+	RollbackError struct {
+		// Plan is the plan that was executed.
+		Plan *Plan
+
+		// Failed holds the changes that were rolled back,
+		// and Errs holds their errors, respectively.
+		Failed []*Change
+		Errs   []error
+	}
+
+	// savepointKey is the context key used for holding the SavepointPolicy.
+	savepointKey struct{}
+)
+
+// List of savepoint policies.
+const (
+	// SavepointAbort rolls back the failed change to its savepoint, and stops the
+	// execution with an *ApplyError. The transaction remains usable, and holds all
+	// changes that were applied before the failed one.
+	SavepointAbort SavepointPolicy = iota
+
+	// SavepointContinue rolls back the failed change to its savepoint, reports it to
+	// the ProgressFunc (if exists), and continues with the next changes. A *RollbackError
+	// that describes the failed changes is returned after all changes were executed.
+	SavepointContinue
+)
+
+// WithSavepoints returns a new context that instructs the drivers to execute each change
+// of transactional plans within a savepoint, and to handle failed changes by the given
+// policy. Savepoints can be used only within transactions, and therefore, the driver is
+// expected to be opened on one (i.e. *sql.Tx). Drivers that are opened on a connection
+// pool (i.e. *sql.DB) return an error, as the savepoint statements might be executed on
+// different connections. Plans that are not transactional (e.g. in MySQL, as DDL statements
+// cause an implicit commit) are executed as usual.
+//
+//	tx, err := db.Begin()
+//	if err != nil {
+//		return err
+//	}
+//	drv, err := postgres.Open(tx)
+//	if err != nil {
+//		return err
+//	}
+//	ctx = migrate.WithSavepoints(ctx, migrate.SavepointContinue)
+//	var rerr *migrate.RollbackError
+//	if err := drv.ApplyChanges(ctx, changes); err != nil && !errors.As(err, &rerr) {
+//		return tx.Rollback()
+//	}
+//	return tx.Commit()
+//
+func WithSavepoints(ctx context.Context, p SavepointPolicy) context.Context {
+	return context.WithValue(ctx, savepointKey{}, p)
+}
+
+// SavepointsFromContext returns the SavepointPolicy stored in the context, and
+// false if savepoints were not requested.
+func SavepointsFromContext(ctx context.Context) (SavepointPolicy, bool) {
+	p, ok := ctx.Value(savepointKey{}).(SavepointPolicy)
+	return p, ok
+}
+
+// Error implements the error interface.
+func (e *RollbackError) Error() string {
+	errs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		errs[i] = err.Error()
+		if c := e.Failed[i].Comment; c != "" {
+			errs[i] = fmt.Sprintf("%s: %v", c, err)
+		}
+	}
+	return fmt.Sprintf("%d change(s) were rolled back: %s", len(e.Failed), strings.Join(errs, ", "))
+}
@@ -0,0 +1,293 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A Target is a database that the desired state is applied to by the Orchestrator.
+	Target struct {
+		// Name identifies the target in the results (e.g. the tenant name or the shard id).
+		Name string
+
+		// Driver is the connection to the target database.
+		Driver Driver
+	}
+
+	// TargetStatus describes the status of applying the desired state to a target.
+	TargetStatus uint8
+
+	// TargetResult holds the result of applying the desired state to a target.
+	TargetResult struct {
+		// Target is the target this result describes.
+		Target *Target
+
+		// Status of the target.
+		Status TargetStatus
+
+		// Plan holds the plan that was executed (or was being executed in case of an
+		// error) on the target. It is nil if the target was not planned (e.g. skipped)
+		// or if there are no changes between the target and the desired state.
+		Plan *Plan
+
+		// Err holds the error that caused the target to fail or to be canceled. Errors
+		// returned by ApplyChanges are usually of type *ApplyError, which describes the
+		// changes that were already applied to the target.
+		Err error
+
+		// Start and End hold the time the target was started and ended.
+		Start, End time.Time
+	}
+
+	// FailurePolicy controls how the Orchestrator reacts to targets that failed.
+	FailurePolicy uint8
+
+	// Orchestrator applies the same desired state to many databases concurrently.
+	// A common use case is applying changes to the databases of all tenants, or to
+	// all shards, that are expected to have identical schemas.
+	//
+	//	o := &migrate.Orchestrator{Parallel: 10, Policy: migrate.ContinueOnError}
+	//	results, err := o.Apply(ctx, migrate.Realm(desired), targets...)
+	//
+	Orchestrator struct {
+		// Parallel is the maximum number of targets that are
+		// applied concurrently. Zero or negative values mean 1.
+		Parallel int
+
+		// Policy controls what happens when a target fails. The default is FailFast.
+		Policy FailurePolicy
+
+		// Report is called each time the status of a target is changed, with
+		// a copy of its result. Calls to Report are serialized, but it should
+		// not block for long, as it delays the processing of other targets.
+		Report func(TargetResult)
+
+		mu sync.Mutex
+	}
+
+	// TargetsError is returned by Orchestrator.Apply in case one or more
+	// targets failed or were not applied. It holds the results of all targets.
+	TargetsError struct {
+		Results []*TargetResult
+	}
+)
+
+// List of target statuses.
+const (
+	// TargetPending indicates the target was not started yet.
+	TargetPending TargetStatus = iota
+
+	// TargetRunning indicates the target is being planned or applied.
+	TargetRunning
+
+	// TargetApplied indicates all changes were applied on the target.
+	TargetApplied
+
+	// TargetNoChange indicates the target is already in the desired state.
+	TargetNoChange
+
+	// TargetFailed indicates the target failed to be inspected, planned or applied.
+	TargetFailed
+
+	// TargetCanceled indicates the target was started, but was canceled because
+	// another target failed (in FailFast mode) or because the context was canceled.
+	TargetCanceled
+
+	// TargetSkipped indicates the target was not started because another target
+	// failed (in FailFast mode) or because the context was canceled.
+	TargetSkipped
+)
+
+// List of failure policies.
+const (
+	// FailFast is the default policy. Once a target fails, targets that were
+	// not started are skipped, and in-flight targets are canceled.
+	FailFast FailurePolicy = iota
+
+	// ContinueOnError continues applying the desired state to the rest of
+	// the targets in case of failures.
+	ContinueOnError
+)
+
+// String implements the fmt.Stringer interface.
+func (s TargetStatus) String() string {
+	switch s {
+	case TargetPending:
+		return "pending"
+	case TargetRunning:
+		return "running"
+	case TargetApplied:
+		return "applied"
+	case TargetNoChange:
+		return "no change"
+	case TargetFailed:
+		return "failed"
+	case TargetCanceled:
+		return "canceled"
+	case TargetSkipped:
+		return "skipped"
+	default:
+		return fmt.Sprintf("TargetStatus(%d)", s)
+	}
+}
+
+// Apply reads the desired state once, and applies it to all targets concurrently.
+// Each target is inspected (limited to the schemas of the desired state), diffed
+// against the desired state, and then the changes are planned and applied on it.
+//
+// The results are returned in the order of the targets. In case one or more targets
+// were not applied successfully, a *TargetsError that holds the results is returned.
+// Note that the desired state is shared by all targets, and should not be modified
+// while Apply is running.
+func (o *Orchestrator) Apply(ctx context.Context, to StateReader, targets ...*Target) ([]*TargetResult, error) {
+	desired, err := to.ReadState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sql/migrate: read desired state: %w", err)
+	}
+	opts := &schema.InspectRealmOption{}
+	for _, s := range desired.Schemas {
+		opts.Schemas = append(opts.Schemas, s.Name)
+	}
+	results := make([]*TargetResult, len(targets))
+	for i, t := range targets {
+		results[i] = &TargetResult{Target: t}
+	}
+	n := o.Parallel
+	if n <= 0 {
+		n = 1
+	}
+	var (
+		wg          sync.WaitGroup
+		sem         = make(chan struct{}, n)
+		cctx, abort = context.WithCancel(ctx)
+	)
+	defer abort()
+	for _, r := range results {
+		select {
+		case sem <- struct{}{}:
+		case <-cctx.Done():
+		}
+		if cctx.Err() != nil {
+			o.update(r, func(r *TargetResult) {
+				r.Status, r.Err = TargetSkipped, cctx.Err()
+			})
+			continue
+		}
+		wg.Add(1)
+		go func(r *TargetResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			o.apply(cctx, r, desired, opts)
+			if r.Status == TargetFailed && o.Policy == FailFast {
+				abort()
+			}
+		}(r)
+	}
+	wg.Wait()
+	for _, r := range results {
+		if r.Status != TargetApplied && r.Status != TargetNoChange {
+			return results, &TargetsError{Results: results}
+		}
+	}
+	return results, nil
+}
+
+// apply applies the desired state on the target of the given result.
+func (o *Orchestrator) apply(ctx context.Context, r *TargetResult, desired *schema.Realm, opts *schema.InspectRealmOption) {
+	o.update(r, func(r *TargetResult) {
+		r.Status, r.Start = TargetRunning, time.Now()
+	})
+	var (
+		plan   *Plan
+		status = TargetApplied
+		drv    = r.Target.Driver
+	)
+	current, err := drv.InspectRealm(ctx, opts)
+	if err != nil {
+		err = fmt.Errorf("inspect target: %w", err)
+	}
+	var changes []schema.Change
+	if err == nil {
+		changes, err = drv.RealmDiff(current, desired)
+	}
+	switch {
+	case err != nil:
+	case len(changes) == 0:
+		status = TargetNoChange
+	default:
+		if plan, err = drv.PlanChanges(ctx, r.Target.Name, changes); err == nil {
+			err = drv.ApplyChanges(ctx, changes)
+		}
+	}
+	o.update(r, func(r *TargetResult) {
+		r.Plan, r.Err, r.End = plan, err, time.Now()
+		switch {
+		case err == nil:
+			r.Status = status
+		case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+			r.Status = TargetCanceled
+		default:
+			r.Status = TargetFailed
+		}
+	})
+}
+
+// update updates the given result, and reports it.
+func (o *Orchestrator) update(r *TargetResult, f func(*TargetResult)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	f(r)
+	if o.Report != nil {
+		o.Report(*r)
+	}
+}
+
+// Failed returns the results of the targets that failed.
+func (e *TargetsError) Failed() []*TargetResult {
+	var failed []*TargetResult
+	for _, r := range e.Results {
+		if r.Status == TargetFailed {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// Error implements the error interface.
+func (e *TargetsError) Error() string {
+	var (
+		b      strings.Builder
+		failed = e.Failed()
+	)
+	fmt.Fprintf(&b, "sql/migrate: %d of %d targets failed", len(failed), len(e.Results))
+	for _, r := range failed {
+		fmt.Fprintf(&b, "; %s: %v", r.Target.Name, r.Err)
+	}
+	if n := len(e.Results) - len(failed) - e.count(TargetApplied) - e.count(TargetNoChange); n > 0 {
+		fmt.Fprintf(&b, " (%d targets were not applied)", n)
+	}
+	return b.String()
+}
+
+// count returns the number of targets with the given status.
+func (e *TargetsError) count(s TargetStatus) (n int) {
+	for _, r := range e.Results {
+		if r.Status == s {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,153 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrchestrator_Apply(t *testing.T) {
+	var (
+		running, peak int32
+		desired       = schema.NewRealm(schema.New("public").AddTables(schema.NewTable("t")))
+		targets       = make([]*migrate.Target, 10)
+	)
+	for i := range targets {
+		targets[i] = &migrate.Target{
+			Name: fmt.Sprintf("tenant_%d", i),
+			Driver: &targetDriver{
+				changes: i % 2,
+				apply: func(context.Context) error {
+					n := atomic.AddInt32(&running, 1)
+					defer atomic.AddInt32(&running, -1)
+					for {
+						p := atomic.LoadInt32(&peak)
+						if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+							break
+						}
+					}
+					return nil
+				},
+			},
+		}
+	}
+	var (
+		mu      sync.Mutex
+		reports []migrate.TargetStatus
+	)
+	o := &migrate.Orchestrator{
+		Parallel: 3,
+		Report: func(r migrate.TargetResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			reports = append(reports, r.Status)
+		},
+	}
+	results, err := o.Apply(context.Background(), migrate.Realm(desired), targets...)
+	require.NoError(t, err)
+	require.Len(t, results, len(targets))
+	require.LessOrEqual(t, peak, int32(3))
+	require.Len(t, reports, 2*len(targets))
+	for i, r := range results {
+		require.Equal(t, targets[i], r.Target)
+		require.NoError(t, r.Err)
+		require.False(t, r.End.Before(r.Start))
+		if i%2 == 0 {
+			require.Equal(t, migrate.TargetNoChange, r.Status)
+			require.Nil(t, r.Plan)
+		} else {
+			require.Equal(t, migrate.TargetApplied, r.Status)
+			require.Equal(t, r.Target.Name, r.Plan.Name)
+		}
+		require.Equal(t, []string{"public"}, r.Target.Driver.(*targetDriver).inspected)
+	}
+}
+
+func TestOrchestrator_Policy(t *testing.T) {
+	fail := func(context.Context) error { return errors.New("boom") }
+	block := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	targets := func(applies ...func(context.Context) error) []*migrate.Target {
+		ts := make([]*migrate.Target, len(applies))
+		for i := range applies {
+			ts[i] = &migrate.Target{Name: fmt.Sprintf("t%d", i), Driver: &targetDriver{changes: 1, apply: applies[i]}}
+		}
+		return ts
+	}
+	desired := migrate.Realm(schema.NewRealm(schema.New("public")))
+
+	o := &migrate.Orchestrator{Parallel: 2}
+	results, err := o.Apply(context.Background(), desired, targets(block, fail, nil)...)
+	require.EqualError(t, err, "sql/migrate: 1 of 3 targets failed; t1: boom (2 targets were not applied)")
+	require.Equal(t, migrate.TargetCanceled, results[0].Status)
+	require.ErrorIs(t, results[0].Err, context.Canceled)
+	require.Equal(t, migrate.TargetFailed, results[1].Status)
+	require.Equal(t, migrate.TargetSkipped, results[2].Status)
+	var terr *migrate.TargetsError
+	require.True(t, errors.As(err, &terr))
+	require.Equal(t, []*migrate.TargetResult{results[1]}, terr.Failed())
+
+	o = &migrate.Orchestrator{Parallel: 2, Policy: migrate.ContinueOnError}
+	results, err = o.Apply(context.Background(), desired, targets(fail, nil, fail, nil)...)
+	require.EqualError(t, err, "sql/migrate: 2 of 4 targets failed; t0: boom; t2: boom")
+	require.Equal(t, migrate.TargetFailed, results[0].Status)
+	require.Equal(t, migrate.TargetApplied, results[1].Status)
+	require.Equal(t, migrate.TargetFailed, results[2].Status)
+	require.Equal(t, migrate.TargetApplied, results[3].Status)
+
+	_, err = o.Apply(context.Background(), migrate.StateReaderFunc(func(context.Context) (*schema.Realm, error) {
+		return nil, errors.New("no state")
+	}), targets(nil)...)
+	require.EqualError(t, err, "sql/migrate: read desired state: no state")
+}
+
+func TestTargetStatus_String(t *testing.T) {
+	require.Equal(t, "no change", migrate.TargetNoChange.String())
+	require.Equal(t, "skipped", migrate.TargetSkipped.String())
+	require.Equal(t, "TargetStatus(100)", migrate.TargetStatus(100).String())
+}
+
+type targetDriver struct {
+	migrate.Driver
+	changes   int
+	apply     func(context.Context) error
+	inspected []string
+}
+
+func (d *targetDriver) InspectRealm(_ context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	d.inspected = opts.Schemas
+	return schema.NewRealm(), nil
+}
+
+func (d *targetDriver) RealmDiff(_, _ *schema.Realm) ([]schema.Change, error) {
+	changes := make([]schema.Change, d.changes)
+	for i := range changes {
+		changes[i] = &schema.AddTable{T: schema.NewTable("t")}
+	}
+	return changes, nil
+}
+
+func (d *targetDriver) PlanChanges(_ context.Context, name string, _ []schema.Change) (*migrate.Plan, error) {
+	return &migrate.Plan{Name: name}, nil
+}
+
+func (d *targetDriver) ApplyChanges(ctx context.Context, _ []schema.Change) error {
+	if d.apply == nil {
+		return nil
+	}
+	return d.apply(ctx)
+}
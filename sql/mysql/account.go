@@ -0,0 +1,586 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A User describes a user account of the database, its privileges and the roles
+	// that are granted to it. Users are held in the attributes of the realm, and they
+	// are managed only if the driver was opened with WithAccounts. Note that account
+	// credentials are not managed by the driver, and users are created without them.
+	User struct {
+		schema.Attr
+		Name string
+		// Host of the account. An empty host means "%" (any host).
+		Host string
+		// Roles that are granted to the user (MySQL 8.0 and above).
+		Roles  []string
+		Grants []*Grant
+	}
+
+	// A Role describes a named collection of privileges (MySQL 8.0 and above). Roles are
+	// held in the attributes of the realm, and they are granted to users by their names.
+	Role struct {
+		schema.Attr
+		Name   string
+		Grants []*Grant
+	}
+
+	// A Grant describes privileges that are granted on a database object.
+	Grant struct {
+		// Privileges that are granted. For example, "SELECT" or "INSERT". Note that
+		// "ALL PRIVILEGES" is not expanded, and inspected grants list the privileges.
+		Privileges []string
+		// Schema and Table the privileges are granted on. An empty Schema
+		// means all objects (*.*), and an empty Table means all the tables
+		// of the schema (schema.*).
+		Schema, Table string
+		// GrantOption indicates if the privileges are granted WITH GRANT OPTION.
+		GrantOption bool
+	}
+
+	// AddUser describes a user creation change.
+	AddUser struct {
+		schema.Change
+		U *User
+	}
+
+	// DropUser describes a user removal change.
+	DropUser struct {
+		schema.Change
+		U *User
+	}
+
+	// ModifyUser describes a change of the privileges or the roles of a user.
+	ModifyUser struct {
+		schema.Change
+		From, To *User
+	}
+
+	// AddRole describes a role creation change.
+	AddRole struct {
+		schema.Change
+		R *Role
+	}
+
+	// DropRole describes a role removal change.
+	DropRole struct {
+		schema.Change
+		R *Role
+	}
+
+	// ModifyRole describes a change of the privileges of a role.
+	ModifyRole struct {
+		schema.Change
+		From, To *Role
+	}
+)
+
+// grantOption is the privilege that represents the GRANT OPTION in privilege sets.
+const grantOption = "GRANT OPTION"
+
+// Queries for inspecting the accounts of the database. Roles are accounts that cannot be
+// used for logging in: locked, with an expired password and without authentication. The
+// internal accounts of the server are ignored.
+const (
+	accountsQuery = "SELECT `User`, `Host`, %s AS `is_role` FROM `mysql`.`user` WHERE `User` NOT IN ('mysql.sys', 'mysql.session', 'mysql.infoschema') ORDER BY `User`, `Host`"
+
+	rolesExpr = "(`account_locked` = 'Y' AND `password_expired` = 'Y' AND `authentication_string` = '')"
+
+	grantsQuery = "SELECT `GRANTEE`, '' AS `TABLE_SCHEMA`, '' AS `TABLE_NAME`, `PRIVILEGE_TYPE`, `IS_GRANTABLE` FROM `INFORMATION_SCHEMA`.`USER_PRIVILEGES` " +
+		"UNION ALL SELECT `GRANTEE`, `TABLE_SCHEMA`, '', `PRIVILEGE_TYPE`, `IS_GRANTABLE` FROM `INFORMATION_SCHEMA`.`SCHEMA_PRIVILEGES` " +
+		"UNION ALL SELECT `GRANTEE`, `TABLE_SCHEMA`, `TABLE_NAME`, `PRIVILEGE_TYPE`, `IS_GRANTABLE` FROM `INFORMATION_SCHEMA`.`TABLE_PRIVILEGES` " +
+		"ORDER BY 1, 2, 3, 4"
+
+	roleEdgesQuery = "SELECT `FROM_USER`, `TO_USER`, `TO_HOST` FROM `mysql`.`role_edges` ORDER BY `TO_USER`, `TO_HOST`, `FROM_USER`"
+)
+
+// inspectAccounts adds the users and the roles of the database to the realm attributes.
+func (i *inspect) inspectAccounts(ctx context.Context, r *schema.Realm) error {
+	isRole := "FALSE"
+	if i.supportsRoles() {
+		isRole = rolesExpr
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(accountsQuery, isRole))
+	if err != nil {
+		return fmt.Errorf("mysql: querying accounts: %w", err)
+	}
+	var (
+		attrs  []schema.Attr
+		grants = make(map[string]*[]*Grant)
+		byName = make(map[string]*User)
+	)
+	for rows.Next() {
+		var (
+			name, host string
+			isRole     bool
+		)
+		if err := rows.Scan(&name, &host, &isRole); err != nil {
+			rows.Close()
+			return fmt.Errorf("mysql: scanning accounts: %w", err)
+		}
+		if isRole {
+			r := &Role{Name: name}
+			attrs, grants[accountName(name, host)] = append(attrs, r), &r.Grants
+		} else {
+			u := &User{Name: name, Host: host}
+			attrs, grants[accountName(name, host)], byName[accountName(name, host)] = append(attrs, u), &u.Grants, u
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if rows, err = i.QueryContext(ctx, grantsQuery); err != nil {
+		return fmt.Errorf("mysql: querying grants: %w", err)
+	}
+	for rows.Next() {
+		var (
+			grantee, priv, grantable string
+			sname, tname             sql.NullString
+		)
+		if err := rows.Scan(&grantee, &sname, &tname, &priv, &grantable); err != nil {
+			rows.Close()
+			return fmt.Errorf("mysql: scanning grants: %w", err)
+		}
+		gs, ok := grants[grantee]
+		// USAGE means "no privileges".
+		if !ok || priv == "USAGE" {
+			continue
+		}
+		g := grantOn(*gs, sname.String, tname.String)
+		if g == nil {
+			g = &Grant{Schema: sname.String, Table: tname.String}
+			*gs = append(*gs, g)
+		}
+		g.Privileges = append(g.Privileges, priv)
+		g.GrantOption = g.GrantOption || grantable == "YES"
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if i.supportsRoles() {
+		if rows, err = i.QueryContext(ctx, roleEdgesQuery); err != nil {
+			return fmt.Errorf("mysql: querying role grants: %w", err)
+		}
+		for rows.Next() {
+			var role, name, host string
+			if err := rows.Scan(&role, &name, &host); err != nil {
+				rows.Close()
+				return fmt.Errorf("mysql: scanning role grants: %w", err)
+			}
+			if u, ok := byName[accountName(name, host)]; ok {
+				u.Roles = append(u.Roles, role)
+			}
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+	}
+	r.Attrs = append(r.Attrs, attrs...)
+	return nil
+}
+
+// RealmAttrDiff implements the sqlx.RealmAttrDiffer interface, and returns the changes of
+// the users and the roles of the realm. Accounts are ignored if WithAccounts was not set.
+func (d *diff) RealmAttrDiff(from, to *schema.Realm) []schema.Change {
+	if !d.accounts {
+		return nil
+	}
+	var changes []schema.Change
+	for _, r1 := range roles(from.Attrs) {
+		switch r2 := role(to.Attrs, r1.Name); {
+		case r2 == nil:
+			changes = append(changes, &DropRole{R: r1})
+		case !sameGrants(r1.Grants, r2.Grants):
+			changes = append(changes, &ModifyRole{From: r1, To: r2})
+		}
+	}
+	for _, r2 := range roles(to.Attrs) {
+		if role(from.Attrs, r2.Name) == nil {
+			changes = append(changes, &AddRole{R: r2})
+		}
+	}
+	for _, u1 := range users(from.Attrs) {
+		switch u2 := user(to.Attrs, u1.account()); {
+		case u2 == nil:
+			changes = append(changes, &DropUser{U: u1})
+		case !sameGrants(u1.Grants, u2.Grants) || !sameSet(u1.Roles, u2.Roles):
+			changes = append(changes, &ModifyUser{From: u1, To: u2})
+		}
+	}
+	for _, u2 := range users(to.Attrs) {
+		if user(from.Attrs, u2.account()) == nil {
+			changes = append(changes, &AddUser{U: u2})
+		}
+	}
+	return changes
+}
+
+// splitAccounts splits the account changes from the given changes. Dropped accounts are
+// planned before all other changes, users before roles, and created or modified accounts
+// after them, roles before users, as their privileges may refer to the created objects.
+func splitAccounts(changes []schema.Change) (pre, rest, post []schema.Change) {
+	for _, c := range changes {
+		switch c.(type) {
+		case *DropUser, *DropRole:
+			pre = append(pre, c)
+		case *AddRole, *ModifyRole, *AddUser, *ModifyUser:
+			post = append(post, c)
+		default:
+			rest = append(rest, c)
+		}
+	}
+	sort.SliceStable(pre, func(i, j int) bool {
+		_, ok1 := pre[i].(*DropUser)
+		_, ok2 := pre[j].(*DropUser)
+		return ok1 && !ok2
+	})
+	sort.SliceStable(post, func(i, j int) bool {
+		return isRoleChange(post[i]) && !isRoleChange(post[j])
+	})
+	return pre, rest, post
+}
+
+// isRoleChange reports if the given change is a role change.
+func isRoleChange(c schema.Change) bool {
+	switch c.(type) {
+	case *AddRole, *ModifyRole, *DropRole:
+		return true
+	}
+	return false
+}
+
+// account builds the statements of an account change.
+func (s *state) account(c schema.Change) error {
+	switch c := c.(type) {
+	case *AddRole:
+		name := quoteAccount(c.R.Name)
+		s.append(&migrate.Change{
+			Cmd:     "CREATE ROLE " + s.ifNotExists() + name,
+			Source:  c,
+			Comment: fmt.Sprintf("create role %q", c.R.Name),
+			Reverse: "DROP ROLE " + name,
+		})
+		s.grants(c, name, nil, c.R.Grants)
+	case *DropRole:
+		s.append(&migrate.Change{
+			Cmd:     "DROP ROLE " + s.ifExists() + quoteAccount(c.R.Name),
+			Source:  c,
+			Comment: fmt.Sprintf("drop role %q", c.R.Name),
+		})
+	case *ModifyRole:
+		s.grants(c, quoteAccount(c.To.Name), c.From.Grants, c.To.Grants)
+	case *AddUser:
+		name := c.U.account()
+		s.append(&migrate.Change{
+			Cmd:     "CREATE USER " + s.ifNotExists() + name,
+			Source:  c,
+			Comment: fmt.Sprintf("create user %s", name),
+			Reverse: "DROP USER " + name,
+		})
+		s.grants(c, name, nil, c.U.Grants)
+		s.roles(c, name, nil, c.U.Roles)
+	case *DropUser:
+		name := c.U.account()
+		s.append(&migrate.Change{
+			Cmd:     "DROP USER " + s.ifExists() + name,
+			Source:  c,
+			Comment: fmt.Sprintf("drop user %s", name),
+		})
+	case *ModifyUser:
+		name := c.To.account()
+		s.grants(c, name, c.From.Grants, c.To.Grants)
+		s.roles(c, name, c.From.Roles, c.To.Roles)
+	default:
+		return fmt.Errorf("unsupported account change %T", c)
+	}
+	return nil
+}
+
+// ifNotExists returns the IF NOT EXISTS clause of account statements, if guards were requested.
+func (s *state) ifNotExists() string {
+	if s.guards {
+		return "IF NOT EXISTS "
+	}
+	return ""
+}
+
+// ifExists returns the IF EXISTS clause of account statements, if guards were requested.
+func (s *state) ifExists() string {
+	if s.guards {
+		return "IF EXISTS "
+	}
+	return ""
+}
+
+// grants appends the REVOKE and GRANT statements for migrating the privileges of the given
+// account from one state to the other. Privileges are revoked before they are granted.
+func (s *state) grants(c schema.Change, name string, from, to []*Grant) {
+	revoke, grant := privilegesDiff(from, to)
+	for _, g := range revoke {
+		s.append(&migrate.Change{
+			Cmd:     s.revokeStmt(g, name),
+			Source:  c,
+			Comment: fmt.Sprintf("revoke privileges on %s from %s", grantObject(g), name),
+			Reverse: s.grantStmt(g, name),
+		})
+	}
+	for _, g := range grant {
+		s.append(&migrate.Change{
+			Cmd:     s.grantStmt(g, name),
+			Source:  c,
+			Comment: fmt.Sprintf("grant privileges on %s to %s", grantObject(g), name),
+			Reverse: s.revokeStmt(g, name),
+		})
+	}
+}
+
+// roles appends the statements for migrating the roles that are granted to the given user.
+func (s *state) roles(c schema.Change, name string, from, to []string) {
+	var revoke, grant []string
+	for _, r := range from {
+		if !contains(to, r) {
+			revoke = append(revoke, quoteAccount(r))
+		}
+	}
+	for _, r := range to {
+		if !contains(from, r) {
+			grant = append(grant, quoteAccount(r))
+		}
+	}
+	if len(revoke) > 0 {
+		s.append(&migrate.Change{
+			Cmd:     fmt.Sprintf("REVOKE %s FROM %s", strings.Join(revoke, ", "), name),
+			Source:  c,
+			Comment: fmt.Sprintf("revoke roles from %s", name),
+			Reverse: fmt.Sprintf("GRANT %s TO %s", strings.Join(revoke, ", "), name),
+		})
+	}
+	if len(grant) > 0 {
+		s.append(&migrate.Change{
+			Cmd:     fmt.Sprintf("GRANT %s TO %s", strings.Join(grant, ", "), name),
+			Source:  c,
+			Comment: fmt.Sprintf("grant roles to %s", name),
+			Reverse: fmt.Sprintf("REVOKE %s FROM %s", strings.Join(grant, ", "), name),
+		})
+	}
+}
+
+// grantStmt returns the GRANT statement of the given grant. The GRANT OPTION is
+// granted using the WITH GRANT OPTION clause, and the USAGE privilege is used in
+// case it is the only privilege that is granted.
+func (s *state) grantStmt(g *Grant, name string) string {
+	var privs []string
+	for _, p := range g.Privileges {
+		if p != grantOption {
+			privs = append(privs, p)
+		}
+	}
+	if len(privs) == 0 {
+		privs = append(privs, "USAGE")
+	}
+	stmt := fmt.Sprintf("GRANT %s ON %s TO %s", strings.Join(privs, ", "), s.grantTarget(g), name)
+	if g.GrantOption {
+		stmt += " WITH GRANT OPTION"
+	}
+	return stmt
+}
+
+// revokeStmt returns the REVOKE statement of the given grant.
+func (s *state) revokeStmt(g *Grant, name string) string {
+	privs := g.Privileges
+	if g.GrantOption {
+		privs = append(privs[:len(privs):len(privs)], grantOption)
+	}
+	return fmt.Sprintf("REVOKE %s ON %s FROM %s", strings.Join(privs, ", "), s.grantTarget(g), name)
+}
+
+// grantTarget returns the quoted object that the privileges of the grant are granted on.
+func (s *state) grantTarget(g *Grant) string {
+	switch {
+	case g.Schema == "":
+		return "*.*"
+	case g.Table == "":
+		return s.build("").Ident(g.Schema).String() + ".*"
+	default:
+		return s.build("").Table(&schema.Table{Name: g.Table, Schema: &schema.Schema{Name: g.Schema}}).String()
+	}
+}
+
+// privilegesDiff returns the privileges that should be revoked and granted for migrating one
+// set of grants to the other. Grants are compared by their objects and privileges, and the
+// GRANT OPTION is treated as a privilege of the object.
+func privilegesDiff(from, to []*Grant) (revoke, grant []*Grant) {
+	fromP, toP := privileges(from), privileges(to)
+	add := func(gs []*Grant, g *Grant, p string) []*Grant {
+		x := grantOn(gs, g.Schema, g.Table)
+		if x == nil {
+			x = &Grant{Schema: g.Schema, Table: g.Table}
+			gs = append(gs, x)
+		}
+		if p == grantOption {
+			x.GrantOption = true
+		} else {
+			x.Privileges = append(x.Privileges, p)
+		}
+		return gs
+	}
+	for _, g := range from {
+		for _, p := range grantPrivileges(g) {
+			if !toP[privilegeKey(g, p)] {
+				revoke = add(revoke, g, p)
+			}
+		}
+	}
+	for _, g := range to {
+		for _, p := range grantPrivileges(g) {
+			if !fromP[privilegeKey(g, p)] {
+				grant = add(grant, g, p)
+			}
+		}
+	}
+	return revoke, grant
+}
+
+// privileges returns the set of privilege keys of the given grants.
+func privileges(gs []*Grant) map[string]bool {
+	keys := make(map[string]bool)
+	for _, g := range gs {
+		for _, p := range grantPrivileges(g) {
+			keys[privilegeKey(g, p)] = true
+		}
+	}
+	return keys
+}
+
+// grantPrivileges returns the normalized privileges of the grant, including its GRANT OPTION.
+func grantPrivileges(g *Grant) []string {
+	ps := make([]string, 0, len(g.Privileges)+1)
+	for _, p := range g.Privileges {
+		ps = append(ps, strings.ToUpper(strings.TrimSpace(p)))
+	}
+	if g.GrantOption {
+		ps = append(ps, grantOption)
+	}
+	return ps
+}
+
+// privilegeKey returns the key of the privilege on the object of the grant.
+func privilegeKey(g *Grant, p string) string {
+	return g.Schema + "\x00" + g.Table + "\x00" + p
+}
+
+// sameGrants reports if the two sets of grants are equal.
+func sameGrants(from, to []*Grant) bool {
+	revoke, grant := privilegesDiff(from, to)
+	return len(revoke) == 0 && len(grant) == 0
+}
+
+// grantOn returns the grant on the given object, or nil.
+func grantOn(gs []*Grant, sname, tname string) *Grant {
+	for _, g := range gs {
+		if g.Schema == sname && g.Table == tname {
+			return g
+		}
+	}
+	return nil
+}
+
+// grantObject returns the object of the grant, as it is described in comments.
+func grantObject(g *Grant) string {
+	switch {
+	case g.Schema == "":
+		return "*.*"
+	case g.Table == "":
+		return g.Schema + ".*"
+	default:
+		return g.Schema + "." + g.Table
+	}
+}
+
+// account returns the quoted account name of the user (i.e. 'name'@'host').
+func (u *User) account() string {
+	host := u.Host
+	if host == "" {
+		host = "%"
+	}
+	return accountName(u.Name, host)
+}
+
+// accountName returns the quoted account name of the given user and host,
+// in the format it is reported by the GRANTEE columns of the information schema.
+func accountName(name, host string) string {
+	return quoteAccount(name) + "@" + quoteAccount(host)
+}
+
+// quoteAccount quotes the given account name or host.
+func quoteAccount(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// users returns the users in the given attributes.
+func users(attrs []schema.Attr) []*User {
+	var us []*User
+	for _, a := range attrs {
+		if u, ok := a.(*User); ok {
+			us = append(us, u)
+		}
+	}
+	return us
+}
+
+// user returns the user with the given account name, or nil.
+func user(attrs []schema.Attr, account string) *User {
+	for _, u := range users(attrs) {
+		if u.account() == account {
+			return u
+		}
+	}
+	return nil
+}
+
+// roles returns the roles in the given attributes.
+func roles(attrs []schema.Attr) []*Role {
+	var rs []*Role
+	for _, a := range attrs {
+		if r, ok := a.(*Role); ok {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// role returns the role with the given name, or nil.
+func role(attrs []schema.Attr, name string) *Role {
+	for _, r := range roles(attrs) {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// sameSet reports if the two lists hold the same elements, regardless of their order.
+func sameSet(l1, l2 []string) bool {
+	if len(l1) != len(l2) {
+		return false
+	}
+	for _, v := range l1 {
+		if !contains(l2, v) {
+			return false
+		}
+	}
+	return true
+}
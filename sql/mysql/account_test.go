@@ -0,0 +1,159 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectAccounts(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("8.0.13")
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| app         | utf8mb4                    | utf8mb4_unicode_ci     |
++-------------+----------------------------+------------------------+
+`))
+	mk.tables("app")
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(accountsQuery, rolesExpr))).
+		WillReturnRows(sqltest.Rows(`
++--------+-----------+---------+
+| User   | Host      | is_role |
++--------+-----------+---------+
+| admin  | localhost | 0       |
+| app    | %         | 0       |
+| reader | %         | 1       |
++--------+-----------+---------+
+`))
+	mk.ExpectQuery(sqltest.Escape(grantsQuery)).
+		WillReturnRows(sqltest.Rows(`
++-----------------------+--------------+------------+----------------+--------------+
+| GRANTEE               | TABLE_SCHEMA | TABLE_NAME | PRIVILEGE_TYPE | IS_GRANTABLE |
++-----------------------+--------------+------------+----------------+--------------+
+| 'admin'@'localhost'   |              |            | SELECT         | YES          |
+| 'app'@'%'             |              |            | USAGE          | NO           |
+| 'app'@'%'             | app          |            | INSERT         | NO           |
+| 'app'@'%'             | app          |            | UPDATE         | NO           |
+| 'app'@'%'             | app          | logs       | SELECT         | NO           |
+| 'reader'@'%'          | app          |            | SELECT         | NO           |
+| 'root'@'localhost'    |              |            | SELECT         | YES          |
++-----------------------+--------------+------------+----------------+--------------+
+`))
+	mk.ExpectQuery(sqltest.Escape(roleEdgesQuery)).
+		WillReturnRows(sqltest.Rows(`
++-----------+---------+---------+
+| FROM_USER | TO_USER | TO_HOST |
++-----------+---------+---------+
+| reader    | app     | %       |
++-----------+---------+---------+
+`))
+	drv, err := Open(db, WithAccounts(true))
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{})
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{
+		&User{Name: "admin", Host: "localhost", Grants: []*Grant{{Privileges: []string{"SELECT"}, GrantOption: true}}},
+		&User{Name: "app", Host: "%", Roles: []string{"reader"}, Grants: []*Grant{
+			{Privileges: []string{"INSERT", "UPDATE"}, Schema: "app"},
+			{Privileges: []string{"SELECT"}, Schema: "app", Table: "logs"},
+		}},
+		&Role{Name: "reader", Grants: []*Grant{{Privileges: []string{"SELECT"}, Schema: "app"}}},
+	}, realm.Attrs[2:])
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDiff_Accounts(t *testing.T) {
+	var (
+		reader = &Role{Name: "reader", Grants: []*Grant{{Privileges: []string{"SELECT"}, Schema: "app"}}}
+		old    = &Role{Name: "old"}
+		app    = &User{Name: "app", Host: "%", Grants: []*Grant{{Privileges: []string{"INSERT", "UPDATE"}, Schema: "app"}}}
+		from   = schema.NewRealm().AddAttrs(reader, old, app)
+		to     = schema.NewRealm().AddAttrs(
+			&Role{Name: "reader", Grants: []*Grant{{Privileges: []string{"select"}, Schema: "app"}}},
+			&Role{Name: "writer"},
+			&User{Name: "app", Roles: []string{"reader"}, Grants: []*Grant{{Privileges: []string{"UPDATE", "INSERT"}, Schema: "app"}}},
+			&User{Name: "ops", Host: "10.0.0.%"},
+		)
+	)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.13")
+	drv, err := Open(db, WithAccounts(true))
+	require.NoError(t, err)
+	changes, err := drv.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&DropRole{R: old},
+		&AddRole{R: to.Attrs[1].(*Role)},
+		&ModifyUser{From: app, To: to.Attrs[2].(*User)},
+		&AddUser{U: to.Attrs[3].(*User)},
+	}, changes)
+
+	// Accounts are ignored without the WithAccounts option.
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.13")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	changes, err = drv.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestPlanChanges_Accounts(t *testing.T) {
+	var (
+		app   = schema.New("app")
+		users = schema.NewTable("users").SetSchema(app).AddColumns(schema.NewIntColumn("id", "int"))
+	)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.13")
+	drv, err := Open(db, WithAccounts(true))
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&AddUser{U: &User{Name: "app", Roles: []string{"reader"}, Grants: []*Grant{{Privileges: []string{"INSERT", "UPDATE"}, Schema: "app", Table: "users"}}}},
+		&AddRole{R: &Role{Name: "reader", Grants: []*Grant{{Privileges: []string{"SELECT"}, Schema: "app"}}}},
+		&schema.AddTable{T: users},
+		&DropRole{R: &Role{Name: "old"}},
+		&DropUser{U: &User{Name: "legacy", Host: "localhost"}},
+		&ModifyUser{
+			From: &User{Name: "ops", Roles: []string{"old"}, Grants: []*Grant{{Privileges: []string{"SELECT", "DELETE"}}}},
+			To:   &User{Name: "ops", Roles: []string{"reader"}, Grants: []*Grant{{Privileges: []string{"SELECT"}, GrantOption: true}}},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, plan.Reversible)
+	for i, c := range []struct{ cmd, reverse string }{
+		{"DROP USER 'legacy'@'localhost'", ""},
+		{"DROP ROLE 'old'", ""},
+		{"CREATE TABLE `app`.`users` (`id` int NOT NULL)", "DROP TABLE `app`.`users`"},
+		{"CREATE ROLE 'reader'", "DROP ROLE 'reader'"},
+		{"GRANT SELECT ON `app`.* TO 'reader'", "REVOKE SELECT ON `app`.* FROM 'reader'"},
+		{"CREATE USER 'app'@'%'", "DROP USER 'app'@'%'"},
+		{"GRANT INSERT, UPDATE ON `app`.`users` TO 'app'@'%'", "REVOKE INSERT, UPDATE ON `app`.`users` FROM 'app'@'%'"},
+		{"GRANT 'reader' TO 'app'@'%'", "REVOKE 'reader' FROM 'app'@'%'"},
+		{"REVOKE DELETE ON *.* FROM 'ops'@'%'", "GRANT DELETE ON *.* TO 'ops'@'%'"},
+		{"GRANT USAGE ON *.* TO 'ops'@'%' WITH GRANT OPTION", "REVOKE GRANT OPTION ON *.* FROM 'ops'@'%'"},
+		{"REVOKE 'old' FROM 'ops'@'%'", "GRANT 'old' TO 'ops'@'%'"},
+		{"GRANT 'reader' TO 'ops'@'%'", "REVOKE 'reader' FROM 'ops'@'%'"},
+	} {
+		require.Equal(t, c.cmd, plan.Changes[i].Cmd)
+		require.Equal(t, c.reverse, plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 12)
+}
@@ -0,0 +1,87 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// Aurora describes the Amazon Aurora MySQL cluster the driver is connected to. Aurora
+// clusters report the version of MySQL they are compatible with (e.g. 5.7.12 for all
+// Aurora MySQL 2 versions), and they are detected on Open by their aurora_version
+// system variable, in case the driver was opened with the WithAurora option.
+// https://docs.aws.amazon.com/AmazonRDS/latest/AuroraUserGuide/AuroraMySQL.Updates.Versions.html
+type Aurora struct {
+	// Version is the Aurora MySQL version (e.g. "2.11.2" or "3.04.0").
+	Version string
+	// LabMode reports if the aurora_lab_mode variable is enabled, which
+	// enables features such as fast DDL in Aurora MySQL 1 and 2.
+	LabMode bool
+}
+
+// Aurora returns the Aurora cluster information, and a flag that
+// indicates if the driver is connected to an Aurora MySQL cluster.
+func (d *Driver) Aurora() (Aurora, bool) {
+	if d.aurora == nil {
+		return Aurora{}, false
+	}
+	return *d.aurora, true
+}
+
+// Major returns the major version of Aurora MySQL, or 0 if it is unknown.
+func (a Aurora) Major() int {
+	v := a.Version
+	if i := strings.IndexByte(v, '.'); i != -1 {
+		v = v[:i]
+	}
+	n, _ := strconv.Atoi(v)
+	return n
+}
+
+// auroraVersion returns the Aurora information of the database that is connected to the
+// given connection, or nil if it is not an Aurora cluster. SHOW VARIABLES is used instead
+// of selecting the variables, as unknown variables fail the query on non-Aurora servers.
+func auroraVersion(ctx context.Context, db schema.ExecQuerier) (*Aurora, error) {
+	rows, err := db.QueryContext(ctx, auroraQuery)
+	if err != nil {
+		return nil, fmt.Errorf("mysql: querying aurora variables: %w", err)
+	}
+	defer rows.Close()
+	var a Aurora
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("mysql: scanning aurora variables: %w", err)
+		}
+		switch strings.ToLower(name) {
+		case "aurora_version":
+			a.Version = value
+		case "aurora_lab_mode":
+			a.LabMode = value == "1" || strings.EqualFold(value, "ON")
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if a.Version == "" {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+// supportsFastAdd reports if the given column is added using the Aurora fast DDL,
+// without rebuilding the table. Aurora MySQL 1 and 2 support it in lab mode only,
+// for nullable columns without a default value that are added as the last column.
+// Aurora MySQL 3 uses the INSTANT algorithm of MySQL 8.0 instead.
+// https://docs.aws.amazon.com/AmazonRDS/latest/AuroraUserGuide/AuroraMySQL.Managing.FastDDL.html
+func (d *conn) supportsFastAdd(c *schema.Column) bool {
+	return d.aurora != nil && d.aurora.LabMode && d.aurora.Major() < 3 &&
+		c.Type != nil && c.Type.Null && c.Default == nil
+}
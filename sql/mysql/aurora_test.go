@@ -0,0 +1,132 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_Aurora(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.aurora("5.7.12", "2.11.2", "ON")
+	drv, err := Open(db, WithAurora(true))
+	require.NoError(t, err)
+	a, ok := drv.Aurora()
+	require.True(t, ok)
+	require.Equal(t, Aurora{Version: "2.11.2", LabMode: true}, a)
+	require.Equal(t, 2, a.Major())
+	require.NoError(t, m.ExpectationsWereMet())
+
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.30")
+	m.ExpectQuery(sqltest.Escape(auroraQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}))
+	drv, err = Open(db, WithAurora(true))
+	require.NoError(t, err)
+	_, ok = drv.Aurora()
+	require.False(t, ok)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	// Aurora is not detected by default.
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("5.7.12")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	_, ok = drv.Aurora()
+	require.False(t, ok)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestPlanChanges_AuroraFastDDL(t *testing.T) {
+	var (
+		users   = schema.NewTable("users").SetSchema(schema.New("test"))
+		changes = []schema.Change{
+			&schema.ModifyTable{
+				T: users,
+				Changes: []schema.Change{
+					&schema.AddColumn{C: schema.NewNullIntColumn("age", "int")},
+				},
+			},
+			&schema.ModifyTable{
+				T: users,
+				Changes: []schema.Change{
+					&schema.AddColumn{C: schema.NewIntColumn("rank", "int")},
+				},
+			},
+		}
+	)
+	tests := []struct {
+		version, aurora, labMode string
+		impacts                  []*migrate.Impact
+	}{
+		{
+			version: "5.7.12",
+			aurora:  "2.11.2",
+			labMode: "OFF",
+			impacts: []*migrate.Impact{
+				{Table: "test.users", Rows: 100, Rewrite: true, Reason: `add column "age"`},
+				{Table: "test.users", Rows: 100, Rewrite: true, Reason: `add column "rank"`},
+			},
+		},
+		{
+			version: "5.7.12",
+			aurora:  "2.11.2",
+			labMode: "ON",
+			impacts: []*migrate.Impact{
+				{Table: "test.users", Rows: 100},
+				{Table: "test.users", Rows: 100, Rewrite: true, Reason: `add column "rank"`},
+			},
+		},
+		{
+			version: "8.0.23",
+			aurora:  "3.02.0",
+			labMode: "OFF",
+			impacts: []*migrate.Impact{
+				{Table: "test.users", Rows: 100},
+				{Table: "test.users", Rows: 100},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.aurora+"/"+tt.labMode, func(t *testing.T) {
+			db, m, err := sqlmock.New()
+			require.NoError(t, err)
+			mock{m}.aurora(tt.version, tt.aurora, tt.labMode)
+			drv, err := Open(db, WithAurora(true))
+			require.NoError(t, err)
+			m.ExpectQuery(sqltest.Escape(rowsQuerySchema)).
+				WithArgs("test", "users").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS"}).AddRow(100))
+			plan, err := drv.PlanChanges(migrate.WithImpact(context.Background()), "plan", changes)
+			require.NoError(t, err)
+			require.NoError(t, m.ExpectationsWereMet())
+			require.Len(t, plan.Changes, len(tt.impacts))
+			for i, c := range plan.Changes {
+				require.Equal(t, tt.impacts[i], c.Impact)
+			}
+		})
+	}
+}
+
+func (m mock) aurora(version, aurora, labMode string) {
+	m.ExpectQuery(sqltest.Escape(variablesQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"@@version", "@@collation_server", "@@character_set_server"}).
+			AddRow(version, "utf8mb4_general_ci", "utf8mb4"))
+	m.ExpectQuery(sqltest.Escape(auroraQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("aurora_lab_mode", labMode).
+			AddRow("aurora_version", aurora))
+}
@@ -13,9 +13,37 @@ import (
 	"ariga.io/atlas/sql/schema"
 )
 
+// customTypes holds the types that were registered using RegisterType.
+var customTypes sqlx.CustomTypes
+
+// RegisterType registers a custom type, or an alias of a built-in type, in the driver. The parse
+// function is called with the raw definition of columns whose type is named as the registered
+// type (e.g. "vector" or "vector(3)"), on inspection and when parsing schema specs. The format
+// function is optional, and reports if it handles the given type and returns its raw definition.
+// Types that are handled by the format function are also compared by their formatted form.
+//
+//	mysql.RegisterType("uuid", func(string) (schema.Type, error) {
+//		return &schema.StringType{T: "uuid"}, nil
+//	}, func(t schema.Type) (string, bool) {
+//		s, ok := t.(*schema.StringType)
+//		return "uuid", ok && s.T == "uuid"
+//	})
+//
+// Types are expected to be registered on init, before the driver is used.
+func RegisterType(name string, parse func(raw string) (schema.Type, error), format func(schema.Type) (string, bool)) error {
+	if name == "" || parse == nil {
+		return fmt.Errorf("mysql: missing name or parse function for custom type %q", name)
+	}
+	customTypes.Register(&sqlx.CustomType{Name: name, Parse: parse, Format: format})
+	return registerSpec(name)
+}
+
 // FormatType converts schema type to its column form in the database.
 // An error is returned if the type cannot be recognized.
 func FormatType(t schema.Type) (string, error) {
+	if f, ok := customTypes.Format(t); ok {
+		return f, nil
+	}
 	var f string
 	switch t := t.(type) {
 	case *BitType:
@@ -94,9 +122,12 @@ func FormatType(t schema.Type) (string, error) {
 		if t.Precision > 0 {
 			f = fmt.Sprintf("%s(%d)", f, t.Precision)
 		}
+	// Types that are unknown to the driver are passed as is.
 	case *schema.UnsupportedType:
-		// Do not accept unsupported types as we should cover all cases.
-		return "", fmt.Errorf("unsupported type %q", t.T)
+		if t.T == "" {
+			return "", fmt.Errorf("mysql: missing unsupported type definition")
+		}
+		f = t.T
 	default:
 		return "", fmt.Errorf("invalid schema type %T", t)
 	}
@@ -106,6 +137,9 @@ func FormatType(t schema.Type) (string, error) {
 // ParseType returns the schema.Type value represented by the given raw type.
 // The raw value is expected to follow the format in MySQL information schema.
 func ParseType(raw string) (schema.Type, error) {
+	if t, ok, err := customTypes.Parse(raw); ok {
+		return t, err
+	}
 	parts, size, unsigned, err := parseColumn(raw)
 	if err != nil {
 		return nil, err
@@ -8,6 +8,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -18,6 +19,47 @@ import (
 // A diff provides a MySQL implementation for sqlx.DiffDriver.
 type diff struct{ conn }
 
+// ColumnOrder reports if the order of table columns is significant.
+func (d *diff) ColumnOrder() bool {
+	return d.ordered
+}
+
+// FoldName implements the sqlx.NameFolder interface. Names of columns and indexes are not
+// case-sensitive in MySQL, and names of schemas, tables and foreign keys are not case-sensitive
+// if lower_case_table_names is set to 1 or 2. Names are folded only if the driver was configured
+// using the WithLowerCaseTableNames option.
+func (d *diff) FoldName(kind schema.ObjectKind, name string) string {
+	switch {
+	case !d.foldNames:
+		return name
+	case kind == schema.ObjectSchema, kind == schema.ObjectTable, kind == schema.ObjectForeignKey:
+		if d.lowerNames == 0 {
+			return name
+		}
+	}
+	return strings.ToLower(name)
+}
+
+// column returns the column of the table that has the given (folded) name.
+func (d *diff) column(t *schema.Table, name string) (*schema.Column, bool) {
+	for _, c := range t.Columns {
+		if d.FoldName(schema.ObjectColumn, c.Name) == d.FoldName(schema.ObjectColumn, name) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// index returns the index of the table that has the given (folded) name.
+func (d *diff) index(t *schema.Table, name string) (*schema.Index, bool) {
+	for _, idx := range t.Indexes {
+		if d.FoldName(schema.ObjectIndex, idx.Name) == d.FoldName(schema.ObjectIndex, name) {
+			return idx, true
+		}
+	}
+	return nil, false
+}
+
 // SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
 func (d *diff) SchemaAttrDiff(from, to *schema.Schema) []schema.Change {
 	var changes []schema.Change
@@ -38,6 +80,18 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	if change := d.autoIncChange(from.Attrs, to.Attrs); change != noChange {
 		changes = append(changes, change)
 	}
+	if change := engineChange(from.Attrs, to.Attrs); change != noChange {
+		changes = append(changes, change)
+	}
+	if change := rowFormatChange(from.Attrs, to.Attrs); change != noChange {
+		changes = append(changes, change)
+	}
+	if _, ok := systemVersioned(to.Attrs); ok && !d.supportsSystemVersioning() {
+		return nil, fmt.Errorf("version %q does not support system-versioned tables", d.version)
+	}
+	if change := systemVersionedChange(from.Attrs, to.Attrs); change != noChange {
+		changes = append(changes, change)
+	}
 	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
 		changes = append(changes, change)
 	}
@@ -56,7 +110,7 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	// using "MODIFY COLUMN".
 	var checks []schema.Change
 	for _, c := range sqlx.CheckDiff(from, to, func(c1, c2 *schema.Check) bool {
-		return enforced(c1.Attrs) == enforced(c2.Attrs)
+		return enforced(c1.Attrs) == enforced(c2.Attrs) && sqlx.NormalizeExpr(c1.Expr) == sqlx.NormalizeExpr(c2.Expr)
 	}) {
 		drop, ok := c.(*schema.DropCheck)
 		if !ok || !strings.HasPrefix(drop.C.Expr, "json_valid") {
@@ -65,7 +119,7 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 		}
 		// Generated CHECK have the form of "json_valid(`<column>`)"
 		// and named as the column.
-		if _, ok := to.Column(drop.C.Name); !ok {
+		if _, ok := d.column(to, drop.C.Name); !ok {
 			checks = append(checks, c)
 		}
 	}
@@ -78,6 +132,10 @@ func (d *diff) ColumnChange(from, to *schema.Column) (schema.ChangeKind, error)
 	if from.Type.Null != to.Type.Null {
 		change |= schema.ChangeNull
 	}
+	change |= columnCharsetChange(from.Attrs, to.Attrs)
+	if onUpdateChanged(from.Attrs, to.Attrs) || sridChanged(from.Attrs, to.Attrs) {
+		change |= schema.ChangeAttr
+	}
 	changed, err := d.typeChanged(from, to)
 	if err != nil {
 		return schema.NoChange, err
@@ -150,7 +208,10 @@ func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
 }
 
 // Normalize implements the sqlx.Normalizer interface.
-func (*diff) Normalize(from, to *schema.Table) {
+func (d *diff) Normalize(from, to *schema.Table) {
+	d.normalizeParts(from, to)
+	d.normalizePeriod(from, to)
+	d.normalizeCollation(from, to)
 	indexes := make([]*schema.Index, 0, len(from.Indexes))
 	for _, idx := range from.Indexes {
 		// MySQL requires that foreign key columns be indexed; Therefore, if the child
@@ -159,13 +220,95 @@ func (*diff) Normalize(from, to *schema.Table) {
 		// Therefore, if no such key was defined on the desired state, the diff will
 		// recommend to drop it on migration. Therefore, we fix it by dropping it from
 		// the current state manually.
-		if _, ok := to.Index(idx.Name); ok || !keySupportsFK(from, idx) {
+		if _, ok := d.index(to, idx.Name); ok || !keySupportsFK(from, idx) {
 			indexes = append(indexes, idx)
 		}
 	}
 	from.Indexes = indexes
 }
 
+// normalizeCollation sets the implicit charset and collation of the desired columns,
+// that is, the ones of their table, in case they are defined explicitly on the current
+// columns. This allows detecting columns that were changed from an explicit collation
+// to the table default, without reporting columns that already use the default.
+func (d *diff) normalizeCollation(from, to *schema.Table) {
+	charset, collate := tableCharset(to)
+	for _, c2 := range to.Columns {
+		c1, ok := d.column(from, c2.Name)
+		if !ok || c2.Type == nil || !supportsCharset(c2.Type.Type) {
+			continue
+		}
+		if charset != "" && sqlx.Has(c1.Attrs, &schema.Charset{}) && !sqlx.Has(c2.Attrs, &schema.Charset{}) {
+			c2.Attrs = append(c2.Attrs, &schema.Charset{V: charset})
+		}
+		if collate != "" && sqlx.Has(c1.Attrs, &schema.Collation{}) && !sqlx.Has(c2.Attrs, &schema.Collation{}) {
+			c2.Attrs = append(c2.Attrs, &schema.Collation{V: collate})
+		}
+	}
+}
+
+// tableCharset returns the charset and collation of the table, that are inherited
+// from its schema if they are not defined on the table.
+func tableCharset(t *schema.Table) (charset, collate string) {
+	var (
+		cs schema.Charset
+		cl schema.Collation
+	)
+	if sqlx.Has(t.Attrs, &cs) {
+		charset = cs.V
+	} else if t.Schema != nil && sqlx.Has(t.Schema.Attrs, &cs) {
+		charset = cs.V
+	}
+	if sqlx.Has(t.Attrs, &cl) {
+		collate = cl.V
+	} else if t.Schema != nil && sqlx.Has(t.Schema.Attrs, &cl) {
+		collate = cl.V
+	}
+	return charset, collate
+}
+
+// normalizePeriod removes the period columns of system-versioned tables from the
+// current state, in case the desired state uses the implicit period columns that
+// are hidden by the database, in order to not drop them.
+func (d *diff) normalizePeriod(from, to *schema.Table) {
+	v1, ok1 := systemVersioned(from.Attrs)
+	v2, ok2 := systemVersioned(to.Attrs)
+	if !ok1 || !ok2 || v2.Start != "" || v1.Start == "" {
+		return
+	}
+	columns := make([]*schema.Column, 0, len(from.Columns))
+	for _, c := range from.Columns {
+		if _, ok := d.column(to, c.Name); ok || c.Name != v1.Start && c.Name != v1.End {
+			columns = append(columns, c)
+		}
+	}
+	from.Columns = columns
+}
+
+// normalizeParts normalizes the parts of the current indexes that are semantically
+// identical to the desired ones, but cannot be compared as-is. For example, functional
+// key parts that are formatted by the database ("(LOWER(name))" and "lower(`name`)"),
+// or DESC parts on databases that ignore the index-part direction.
+func (d *diff) normalizeParts(from, to *schema.Table) {
+	for _, idx1 := range from.Indexes {
+		idx2, ok := d.index(to, idx1.Name)
+		if !ok || len(idx1.Parts) != len(idx2.Parts) {
+			continue
+		}
+		for i, p1 := range idx1.Parts {
+			p2 := idx2.Parts[i]
+			if !d.supportsDescIndex() {
+				p1.Desc = p2.Desc
+			}
+			x1, ok1 := p1.X.(*schema.RawExpr)
+			x2, ok2 := p2.X.(*schema.RawExpr)
+			if ok1 && ok2 && x1.X != x2.X && sqlx.NormalizeExpr(x1.X) == sqlx.NormalizeExpr(x2.X) {
+				p1.X = p2.X
+			}
+		}
+	}
+}
+
 // collationChange returns the schema change for migrating the collation if
 // it was changed and its not the default attribute inherited from its parent.
 func (*diff) collationChange(from, top, to []schema.Attr) schema.Change {
@@ -224,14 +367,70 @@ func (*diff) charsetChange(from, top, to []schema.Attr) schema.Change {
 	return noChange
 }
 
+// columnCharsetChange reports if the column CHARSET or COLLATE were changed. Columns that
+// do not define them explicitly inherit them from their table, and in this case, the
+// change is detected (and planned) on the table level.
+func columnCharsetChange(from, to []schema.Attr) schema.ChangeKind {
+	var (
+		change                 schema.ChangeKind
+		fromCharset, toCharset schema.Charset
+		fromCollate, toCollate schema.Collation
+	)
+	if sqlx.Has(from, &fromCharset) && sqlx.Has(to, &toCharset) && fromCharset.V != toCharset.V {
+		change |= schema.ChangeCharset
+	}
+	if sqlx.Has(from, &fromCollate) && sqlx.Has(to, &toCollate) && fromCollate.V != toCollate.V {
+		change |= schema.ChangeCollation
+	}
+	return change
+}
+
+// onUpdateChanged reports if the ON UPDATE clause of the column was changed.
+func onUpdateChanged(from, to []schema.Attr) bool {
+	var u1, u2 OnUpdate
+	ok1, ok2 := sqlx.Has(from, &u1), sqlx.Has(to, &u2)
+	return ok1 != ok2 || ok1 && nowExpr(u1.A) != nowExpr(u2.A)
+}
+
+// nowExpr normalizes the synonyms of the CURRENT_TIMESTAMP function, that are
+// accepted by the ON UPDATE clause. For example, "NOW(6)" and "current_timestamp(6)".
+func nowExpr(x string) string {
+	x = strings.ToLower(strings.TrimSpace(x))
+	for _, f := range []string{"now", "localtimestamp", "localtime"} {
+		if strings.HasPrefix(x, f) {
+			x = "current_timestamp" + x[len(f):]
+			break
+		}
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(x, "()"), "(0)")
+}
+
+// sridChanged reports if the SRID attribute of the column was changed.
+func sridChanged(from, to []schema.Attr) bool {
+	var s1, s2 SRID
+	ok1, ok2 := sqlx.Has(from, &s1), sqlx.Has(to, &s2)
+	return ok1 != ok2 || s1.ID != s2.ID
+}
+
 // autoIncChange returns the schema change for changing the AUTO_INCREMENT
-// attribute in case it is not the default.
-func (*diff) autoIncChange(from, to []schema.Attr) schema.Change {
+// attribute according to the configured AutoIncrementMode.
+func (d *diff) autoIncChange(from, to []schema.Attr) schema.Change {
 	var fromA, toA AutoIncrement
+	switch fromHas, toHas := sqlx.Has(from, &fromA), sqlx.Has(to, &toA); {
+	case d.autoInc == AutoIncrementIgnore:
 	// The table is empty and AUTO_INCREMENT was not configured. This can happen
 	// because older versions of MySQL (< 8.0) stored the AUTO_INCREMENT counter
 	// in main memory (not persistent), and the value is reset on process restart.
-	if sqlx.Has(from, &fromA) && sqlx.Has(to, &toA) && fromA.V <= 1 && toA.V > 1 {
+	case d.autoInc == AutoIncrementInit && fromHas && toHas && fromA.V <= 1 && toA.V > 1:
+		return &schema.ModifyAttr{
+			From: &fromA,
+			To:   &toA,
+		}
+	case d.autoInc == AutoIncrementSync && !fromHas && toHas && toA.V > 1:
+		return &schema.AddAttr{
+			A: &toA,
+		}
+	case d.autoInc == AutoIncrementSync && fromHas && toHas && fromA.V != toA.V:
 		return &schema.ModifyAttr{
 			From: &fromA,
 			To:   &toA,
@@ -240,6 +439,116 @@ func (*diff) autoIncChange(from, to []schema.Attr) schema.Change {
 	return noChange
 }
 
+// engineChange returns the schema change for migrating the table ENGINE. Tables
+// that do not define an engine in their desired state use the server default.
+func engineChange(from, to []schema.Attr) schema.Change {
+	var fromE, toE Engine
+	switch fromHas, toHas := sqlx.Has(from, &fromE), sqlx.Has(to, &toE); {
+	case !toHas:
+	case !fromHas:
+		return &schema.AddAttr{
+			A: &toE,
+		}
+	case !strings.EqualFold(fromE.V, toE.V):
+		return &schema.ModifyAttr{
+			From: &fromE,
+			To:   &toE,
+		}
+	}
+	return noChange
+}
+
+// systemVersionedChange returns the schema change for enabling or disabling the
+// system versioning of a table. Changing the period columns of system-versioned
+// tables is not supported, as it requires dropping the history of the table.
+func systemVersionedChange(from, to []schema.Attr) schema.Change {
+	v1, ok1 := systemVersioned(from)
+	v2, ok2 := systemVersioned(to)
+	switch {
+	case !ok1 && ok2:
+		return &schema.AddAttr{
+			A: v2,
+		}
+	case ok1 && !ok2:
+		return &schema.DropAttr{
+			A: v1,
+		}
+	}
+	return noChange
+}
+
+// rowFormatChange returns the schema change for migrating the table ROW_FORMAT.
+// An explicit ROW_FORMAT that was removed from the desired state is reset to the
+// default row format of the engine.
+func rowFormatChange(from, to []schema.Attr) schema.Change {
+	var fromR, toR RowFormat
+	switch fromHas, toHas := sqlx.Has(from, &fromR), sqlx.Has(to, &toR); {
+	case !fromHas && !toHas:
+	case !fromHas:
+		return &schema.AddAttr{
+			A: &toR,
+		}
+	case !toHas:
+		if !strings.EqualFold(fromR.V, RowFormatDefault) {
+			return &schema.ModifyAttr{
+				From: &fromR,
+				To:   &RowFormat{V: RowFormatDefault},
+			}
+		}
+	case !strings.EqualFold(fromR.V, toR.V):
+		return &schema.ModifyAttr{
+			From: &fromR,
+			To:   &toR,
+		}
+	}
+	return noChange
+}
+
+var (
+	// reIntroducer matches string literals with a character set introducer,
+	// as MySQL returns string literals that are used in expression defaults.
+	// For example: "_utf8mb4\'a\'". See: https://dev.mysql.com/doc/refman/8.0/en/charset-introducer.html.
+	reIntroducer = regexp.MustCompile(`^_[a-z0-9]+(\\?'.*\\?')$`)
+	// reTimeFunc matches functions and keywords that are synonyms for getting the current time.
+	// For example, "NOW()", "CURRENT_TIMESTAMP" and "LOCALTIMESTAMP(6)".
+	reTimeFunc = regexp.MustCompile(`(?i)^(?:(current_timestamp|localtime|localtimestamp|current_date|current_time)(?:\(\s*(\d*)\s*\))?|(now|curdate|curtime)\(\s*(\d*)\s*\))$`)
+)
+
+// NormalizeDefault returns a canonical form of the given DEFAULT expression. It can be
+// used for comparing DEFAULT values that are semantically identical but written in a
+// different form, for example "(now())" and "CURRENT_TIMESTAMP", or "'a'" and "\"a\"".
+func NormalizeDefault(x string) string {
+	x = sqlx.TrimParens(x)
+	if m := reIntroducer.FindStringSubmatch(x); m != nil {
+		x = strings.ReplaceAll(m[1], "\\'", "'")
+	}
+	if m := reTimeFunc.FindStringSubmatch(x); m != nil {
+		switch strings.ToLower(m[1] + m[3]) {
+		case "current_date", "curdate":
+			return "CURRENT_DATE"
+		case "current_time", "curtime":
+			x = "CURRENT_TIME"
+		default:
+			x = "CURRENT_TIMESTAMP"
+		}
+		// Zero precision is the default.
+		if p := m[2] + m[4]; p != "" && p != "0" {
+			x += "(" + p + ")"
+		}
+		return x
+	}
+	switch u := strings.ToUpper(x); u {
+	case "NULL", "TRUE", "FALSE":
+		return u
+	}
+	if sqlx.IsQuoted(x, '"', '\'') {
+		if q, err := sqlx.SingleQuote(x); err == nil {
+			return q
+		}
+	}
+	return x
+}
+
 // indexType returns the index type from its attribute.
 // The default type is BTREE if no type was specified.
 func indexType(attr []schema.Attr) *IndexType {
@@ -267,6 +576,11 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	if fromT == nil || toT == nil {
 		return false, fmt.Errorf("mysql: missing type information for column %q", from.Name)
 	}
+	// Custom types are compared by their formatted form.
+	if f1, ok := customTypes.Format(fromT); ok {
+		f2, ok := customTypes.Format(toT)
+		return !ok || !strings.EqualFold(f1, f2), nil
+	}
 	if reflect.TypeOf(fromT) != reflect.TypeOf(toT) {
 		return true, nil
 	}
@@ -302,7 +616,7 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 		changed = mustFormat(fromT) != mustFormat(toT)
 	case *schema.SpatialType:
 		toT := toT.(*schema.SpatialType)
-		changed = fromT.T != toT.T
+		changed = !strings.EqualFold(fromT.T, toT.T)
 	case *schema.TimeType:
 		toT := toT.(*schema.TimeType)
 		changed = fromT.T != toT.T
@@ -312,8 +626,12 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	case *SetType:
 		toT := toT.(*SetType)
 		changed = !sqlx.ValuesEqual(fromT.Values, toT.Values)
+	// Types that are unknown to the driver are compared by their raw form.
+	case *schema.UnsupportedType:
+		toT := toT.(*schema.UnsupportedType)
+		changed = !strings.EqualFold(fromT.T, toT.T)
 	default:
-		return false, &sqlx.UnsupportedTypeError{Type: fromT}
+		return false, &schema.UnsupportedTypeError{Type: fromT}
 	}
 	return changed, nil
 }
@@ -326,7 +644,7 @@ func (d *diff) defaultChanged(from, to *schema.Column) (bool, error) {
 	if ok1 != ok2 {
 		return true, nil
 	}
-	if d1 == d2 {
+	if d1 == d2 || NormalizeDefault(d1) == NormalizeDefault(d2) {
 		return false, nil
 	}
 	switch from.Type.Type.(type) {
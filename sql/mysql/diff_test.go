@@ -37,6 +37,11 @@ func TestDiff_TableDiff(t *testing.T) {
 			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Columns: []*schema.Column{{Name: "enum", Default: &schema.RawExpr{X: "'A'"}, Type: &schema.ColumnType{Type: &schema.EnumType{Values: []string{"A"}}}}}},
 			to:   &schema.Table{Name: "users", Columns: []*schema.Column{{Name: "enum", Default: &schema.RawExpr{X: `"A"`}, Type: &schema.ColumnType{Type: &schema.EnumType{Values: []string{"A"}}}}}},
 		},
+		{
+			name: "no changes",
+			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Columns: []*schema.Column{{Name: "ts", Default: &schema.RawExpr{X: "CURRENT_TIMESTAMP(6)"}, Type: &schema.ColumnType{Type: &schema.TimeType{T: "timestamp"}}}}},
+			to:   &schema.Table{Name: "users", Columns: []*schema.Column{{Name: "ts", Default: &schema.RawExpr{X: "(now(6))"}, Type: &schema.ColumnType{Type: &schema.TimeType{T: "timestamp"}}}}},
+		},
 		{
 			name: "change primary key",
 			from: func() *schema.Table {
@@ -60,6 +65,42 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "modify engine and row format",
+			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&Engine{V: "InnoDB"}, &RowFormat{V: "DYNAMIC"}}},
+			to:   &schema.Table{Name: "users", Attrs: []schema.Attr{&Engine{V: "MyISAM"}, &RowFormat{V: "COMPRESSED"}}},
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &Engine{V: "InnoDB"},
+					To:   &Engine{V: "MyISAM"},
+				},
+				&schema.ModifyAttr{
+					From: &RowFormat{V: "DYNAMIC"},
+					To:   &RowFormat{V: "COMPRESSED"},
+				},
+			},
+		},
+		{
+			name: "default engine and row format",
+			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&Engine{V: "InnoDB"}, &RowFormat{V: "COMPRESSED"}}},
+			to:   &schema.Table{Name: "users", Attrs: []schema.Attr{&Engine{V: "innodb"}}},
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &RowFormat{V: "COMPRESSED"},
+					To:   &RowFormat{V: "DEFAULT"},
+				},
+			},
+		},
+		{
+			name: "add row format",
+			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&Engine{V: "InnoDB"}}},
+			to:   &schema.Table{Name: "users", Attrs: []schema.Attr{&RowFormat{V: "COMPRESSED"}}},
+			wantChanges: []schema.Change{
+				&schema.AddAttr{
+					A: &RowFormat{V: "COMPRESSED"},
+				},
+			},
+		},
 		{
 			name: "add collation",
 			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}},
@@ -155,6 +196,22 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "check expressions formatted differently",
+			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&schema.Check{Name: "t1_chk_1", Expr: "((`c1` > 0) and (`c2` <> _utf8mb4'a'))"}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Expr: "c1 > 0 AND c2 != 'a'"}}},
+		},
+		{
+			name: "modify check expression",
+			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&schema.Check{Name: "c", Expr: "(`c1` > 0)"}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "c", Expr: "c1 > 1"}}},
+			wantChanges: []schema.Change{
+				&schema.ModifyCheck{
+					From: &schema.Check{Name: "c", Expr: "(`c1` > 0)"},
+					To:   &schema.Check{Name: "c", Expr: "c1 > 1"},
+				},
+			},
+		},
 		{
 			name: "add comment",
 			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}},
@@ -227,6 +284,38 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			}
 		}(),
+		func() testcase {
+			var (
+				from = &schema.Table{
+					Name:   "t1",
+					Schema: &schema.Schema{Name: "public"},
+					Columns: []*schema.Column{
+						{Name: "c1", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}}, Attrs: []schema.Attr{&schema.Charset{V: "utf8"}, &schema.Collation{V: "utf8_general_ci"}}},
+						{Name: "c2", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}}, Attrs: []schema.Attr{&schema.Charset{V: "utf8"}, &schema.Collation{V: "utf8_general_ci"}}},
+					},
+				}
+				to = &schema.Table{
+					Name: "t1",
+					Columns: []*schema.Column{
+						{Name: "c1", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}}, Attrs: []schema.Attr{&schema.Charset{V: "utf8mb4"}, &schema.Collation{V: "utf8mb4_bin"}}},
+						// Inherits its charset and collation from the table.
+						{Name: "c2", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}}},
+					},
+				}
+			)
+			return testcase{
+				name: "column charset",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyColumn{
+						From:   from.Columns[0],
+						To:     to.Columns[0],
+						Change: schema.ChangeCharset | schema.ChangeCollation,
+					},
+				},
+			}
+		}(),
 		func() testcase {
 			var (
 				from = &schema.Table{
@@ -327,6 +416,38 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			}
 		}(),
+		func() testcase {
+			var (
+				from = schema.NewTable("t1").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewIntColumn("t2_id", "int"), schema.NewIntColumn("t2_tenant", "int"))
+				to = schema.NewTable("t1").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewIntColumn("t2_id", "bigint"), schema.NewIntColumn("t2_tenant", "int"))
+				ref = schema.NewTable("t2").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewIntColumn("id", "bigint"), schema.NewIntColumn("tenant", "int"))
+			)
+			from.AddForeignKeys(schema.NewForeignKey("t2_fk").AddColumns(from.Columns...).SetRefTable(ref).AddRefColumns(ref.Columns...))
+			to.AddForeignKeys(schema.NewForeignKey("t2_fk").AddColumns(to.Columns...).SetRefTable(ref).AddRefColumns(ref.Columns...))
+			return testcase{
+				name: "foreign-keys retyped columns",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyColumn{
+						From:   from.Columns[0],
+						To:     to.Columns[0],
+						Change: schema.ChangeType,
+					},
+					&schema.ModifyForeignKey{
+						From:   from.ForeignKeys[0],
+						To:     to.ForeignKeys[0],
+						Change: schema.ChangeType,
+					},
+				},
+			}
+		}(),
 	}
 	for _, tt := range tests {
 		db, m, err := sqlmock.New()
@@ -342,6 +463,221 @@ func TestDiff_TableDiff(t *testing.T) {
 	}
 }
 
+func TestDiff_IndexParts(t *testing.T) {
+	table := func(x string, desc bool) *schema.Table {
+		t := schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewStringColumn("name", "varchar"))
+		return t.AddIndexes(schema.NewIndex("idx").AddParts(
+			&schema.IndexPart{SeqNo: 1, X: &schema.RawExpr{X: x}},
+			&schema.IndexPart{SeqNo: 2, C: t.Columns[0], Desc: desc},
+		))
+	}
+	for _, tt := range []struct {
+		version  string
+		from, to *schema.Table
+		changed  bool
+	}{
+		{version: "8.0.19", from: table("lower(`name`)", false), to: table("(LOWER(name))", false)},
+		{version: "8.0.19", from: table("concat(`name`,_utf8mb4'a b')", false), to: table("CONCAT(name, 'a b')", false)},
+		{version: "8.0.19", from: table("lower(`name`)", false), to: table("upper(`name`)", false), changed: true},
+		{version: "8.0.19", from: table("concat(`name`,_utf8mb4'ab')", false), to: table("concat(name, 'a b')", false), changed: true},
+		{version: "8.0.19", from: table("lower(`name`)", false), to: table("lower(`name`)", true), changed: true},
+		{version: "5.7.36", from: table("lower(`name`)", false), to: table("lower(`name`)", true)},
+	} {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.version(tt.version)
+		drv, err := Open(db)
+		require.NoError(t, err)
+		changes, err := drv.TableDiff(tt.from, tt.to)
+		require.NoError(t, err)
+		if !tt.changed {
+			require.Empty(t, changes)
+			continue
+		}
+		require.Len(t, changes, 1)
+		require.Equal(t, schema.ChangeParts, changes[0].(*schema.ModifyIndex).Change)
+	}
+}
+
+func TestDiff_AutoIncrementMode(t *testing.T) {
+	table := func(v int64) *schema.Table {
+		t := &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}}
+		if v > 0 {
+			t.Attrs = append(t.Attrs, &AutoIncrement{V: v})
+		}
+		return t
+	}
+	tests := []struct {
+		mode        AutoIncrementMode
+		from, to    *schema.Table
+		wantChanges []schema.Change
+	}{
+		{mode: AutoIncrementInit, from: table(1), to: table(100), wantChanges: []schema.Change{&schema.ModifyAttr{From: &AutoIncrement{V: 1}, To: &AutoIncrement{V: 100}}}},
+		{mode: AutoIncrementInit, from: table(50), to: table(100)},
+		{mode: AutoIncrementIgnore, from: table(1), to: table(100)},
+		{mode: AutoIncrementSync, from: table(50), to: table(100), wantChanges: []schema.Change{&schema.ModifyAttr{From: &AutoIncrement{V: 50}, To: &AutoIncrement{V: 100}}}},
+		{mode: AutoIncrementSync, from: table(0), to: table(100), wantChanges: []schema.Change{&schema.AddAttr{A: &AutoIncrement{V: 100}}}},
+		{mode: AutoIncrementSync, from: table(50), to: table(0)},
+	}
+	for _, tt := range tests {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.version("8.0.19")
+		drv, err := Open(db, WithAutoIncrement(tt.mode))
+		require.NoError(t, err)
+		changes, err := drv.TableDiff(tt.from, tt.to)
+		require.NoError(t, err)
+		require.EqualValues(t, tt.wantChanges, changes)
+	}
+}
+
+func TestDiff_LowerCaseTableNames(t *testing.T) {
+	newSchema := func(table, column, index string) *schema.Schema {
+		t := schema.NewTable(table).AddColumns(schema.NewIntColumn(column, "int"))
+		t.AddIndexes(schema.NewIndex(index).AddColumns(t.Columns...))
+		return schema.NewRealm(schema.New("public").AddTables(t)).Schemas[0]
+	}
+	from, to := newSchema("Users", "ID", "Users_ID"), newSchema("users", "id", "users_id")
+	tests := []struct {
+		opts    []Option
+		changes []schema.Change
+	}{
+		{
+			changes: []schema.Change{&schema.DropTable{T: from.Tables[0]}, &schema.AddTable{T: to.Tables[0]}},
+		},
+		// Table names are case-sensitive.
+		{
+			opts:    []Option{WithLowerCaseTableNames(0)},
+			changes: []schema.Change{&schema.DropTable{T: from.Tables[0]}, &schema.AddTable{T: to.Tables[0]}},
+		},
+		{
+			opts: []Option{WithLowerCaseTableNames(1)},
+		},
+		{
+			opts: []Option{WithLowerCaseTableNames(2)},
+		},
+	}
+	for _, tt := range tests {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.version("8.0.19")
+		drv, err := Open(db, tt.opts...)
+		require.NoError(t, err)
+		changes, err := drv.SchemaDiff(from, to)
+		require.NoError(t, err)
+		require.Equal(t, tt.changes, changes)
+	}
+
+	// Names of columns and indexes are not case-sensitive.
+	from, to = newSchema("users", "ID", "Users_ID"), newSchema("users", "id", "users_id")
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.19")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	changes, err := drv.TableDiff(from.Tables[0], to.Tables[0])
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+	mock{m}.version("8.0.19")
+	drv, err = Open(db, WithLowerCaseTableNames(0))
+	require.NoError(t, err)
+	changes, err = drv.TableDiff(from.Tables[0], to.Tables[0])
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff_ColumnOrder(t *testing.T) {
+	table := func(names ...string) *schema.Table {
+		t := schema.NewTable("users").SetSchema(schema.New("public"))
+		for _, n := range names {
+			t.AddColumns(schema.NewIntColumn(n, "int"))
+		}
+		return t
+	}
+	tests := []struct {
+		from, to *schema.Table
+		moved    []string
+	}{
+		{from: table("a", "b", "c"), to: table("a", "b", "c")},
+		{from: table("a", "b", "c"), to: table("a", "c", "b"), moved: []string{"b"}},
+		{from: table("a", "b", "c"), to: table("c", "a", "b"), moved: []string{"c"}},
+		{from: table("a", "b", "c", "d"), to: table("d", "c", "b", "a"), moved: []string{"a", "b", "c"}},
+		// Added and dropped columns do not affect the order.
+		{from: table("a", "b", "c"), to: table("x", "a", "c")},
+		{from: table("a", "b", "c"), to: table("b", "x", "a"), moved: []string{"a"}},
+	}
+	for _, tt := range tests {
+		for _, ordered := range []bool{false, true} {
+			db, m, err := sqlmock.New()
+			require.NoError(t, err)
+			mock{m}.version("8.0.19")
+			drv, err := Open(db, WithColumnOrder(ordered))
+			require.NoError(t, err)
+			changes, err := drv.TableDiff(tt.from, tt.to)
+			require.NoError(t, err)
+			var moved []string
+			for _, c := range changes {
+				if m, ok := c.(*schema.ModifyColumn); ok {
+					require.Equal(t, schema.ChangePosition, m.Change)
+					moved = append(moved, m.To.Name)
+				}
+			}
+			if !ordered {
+				require.Empty(t, moved)
+				continue
+			}
+			require.Equal(t, tt.moved, moved)
+		}
+	}
+}
+
+func TestDiff_ColumnClauses(t *testing.T) {
+	column := func(typ schema.Type, attrs ...schema.Attr) *schema.Table {
+		return schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			SetCharset("utf8mb4").
+			SetCollation("utf8mb4_0900_ai_ci").
+			AddColumns(&schema.Column{Name: "c", Type: &schema.ColumnType{Type: typ}, Attrs: attrs})
+	}
+	var (
+		ts   = &schema.TimeType{T: "timestamp", Precision: 6}
+		text = &schema.StringType{T: "text"}
+		geo  = &schema.SpatialType{T: "point"}
+	)
+	tests := []struct {
+		from, to *schema.Table
+		change   schema.ChangeKind
+	}{
+		{from: column(ts, &OnUpdate{A: "CURRENT_TIMESTAMP(6)"}), to: column(ts, &OnUpdate{A: "now(6)"})},
+		{from: column(ts, &OnUpdate{A: "current_timestamp"}), to: column(ts, &OnUpdate{A: "CURRENT_TIMESTAMP()"})},
+		{from: column(ts, &OnUpdate{A: "current_timestamp(6)"}), to: column(ts), change: schema.ChangeAttr},
+		{from: column(ts), to: column(ts, &OnUpdate{A: "current_timestamp(6)"}), change: schema.ChangeAttr},
+		{from: column(geo), to: column(&schema.SpatialType{T: "POINT"})},
+		{from: column(geo, &SRID{ID: 4326}), to: column(geo, &SRID{ID: 4326})},
+		{from: column(geo), to: column(geo, &SRID{ID: 4326}), change: schema.ChangeAttr},
+		{from: column(geo, &SRID{ID: 0}), to: column(geo, &SRID{ID: 4326}), change: schema.ChangeAttr},
+		// Columns that are defined with the table default collation.
+		{from: column(text, &schema.Charset{V: "utf8mb4"}, &schema.Collation{V: "utf8mb4_0900_ai_ci"}), to: column(text)},
+		{from: column(text, &schema.Charset{V: "utf8mb4"}, &schema.Collation{V: "utf8mb4_bin"}), to: column(text), change: schema.ChangeCollation},
+		{from: column(text, &schema.Charset{V: "latin1"}, &schema.Collation{V: "latin1_swedish_ci"}), to: column(text), change: schema.ChangeCharset | schema.ChangeCollation},
+	}
+	for i, tt := range tests {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.version("8.0.19")
+		drv, err := Open(db)
+		require.NoError(t, err)
+		changes, err := drv.TableDiff(tt.from, tt.to)
+		require.NoError(t, err)
+		if tt.change == schema.NoChange {
+			require.Empty(t, changes, i)
+			continue
+		}
+		require.Len(t, changes, 1, i)
+		require.Equal(t, tt.change, changes[0].(*schema.ModifyColumn).Change, i)
+	}
+}
+
 func TestDiff_UnsupportedChecks(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -357,6 +693,45 @@ func TestDiff_UnsupportedChecks(t *testing.T) {
 	require.EqualError(t, err, `version "5.6.35" does not support CHECK constraints`)
 }
 
+func TestDiff_SystemVersioning(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("10.7.1-MariaDB")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s := schema.New("public")
+	from := schema.NewTable("t").SetSchema(s).AddColumns(schema.NewIntColumn("id", "int"))
+	to := schema.NewTable("t").SetSchema(s).AddColumns(schema.NewIntColumn("id", "int")).AddAttrs(&SystemVersioned{})
+	changes, err := drv.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.AddAttr{A: &SystemVersioned{}}}, changes)
+	changes, err = drv.TableDiff(to, from)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.DropAttr{A: &SystemVersioned{}}}, changes)
+
+	// Explicit period columns in the database are kept,
+	// if the desired state uses the implicit ones.
+	from = schema.NewTable("t").SetSchema(s).
+		AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewTimeColumn("row_start", "timestamp", schema.TimePrecision(6)),
+			schema.NewTimeColumn("row_end", "timestamp", schema.TimePrecision(6)),
+		).
+		AddAttrs(&SystemVersioned{Start: "row_start", End: "row_end"})
+	changes, err = drv.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.19")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	changes, err = drv.TableDiff(schema.NewTable("t").SetSchema(s), schema.NewTable("t").SetSchema(s).AddAttrs(&SystemVersioned{}))
+	require.Nil(t, changes)
+	require.EqualError(t, err, `version "8.0.19" does not support system-versioned tables`)
+}
+
 func TestDiff_SchemaDiff(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -474,3 +849,23 @@ func TestDiff_RealmDiff(t *testing.T) {
 		&schema.AddTable{T: to.Schemas[1].Tables[0]},
 	}, changes)
 }
+
+func TestNormalizeDefault(t *testing.T) {
+	for x, want := range map[string]string{
+		"CURRENT_TIMESTAMP":    "CURRENT_TIMESTAMP",
+		"current_timestamp()":  "CURRENT_TIMESTAMP",
+		"(now())":              "CURRENT_TIMESTAMP",
+		"now(6)":               "CURRENT_TIMESTAMP(6)",
+		"CURRENT_TIMESTAMP(6)": "CURRENT_TIMESTAMP(6)",
+		"localtimestamp":       "CURRENT_TIMESTAMP",
+		"curdate()":            "CURRENT_DATE",
+		"now":                  "now",
+		`_utf8mb4\'a\'`:        "'a'",
+		`("a")`:                "'a'",
+		"'it''s'":              "'it''s'",
+		"null":                 "NULL",
+		"(json_array())":       "json_array()",
+	} {
+		require.Equal(t, want, NormalizeDefault(x), x)
+	}
+}
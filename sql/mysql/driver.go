@@ -8,10 +8,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/naming"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlmetrics"
+	"ariga.io/atlas/sql/sqltrace"
 
 	"golang.org/x/mod/semver"
 )
@@ -19,6 +23,12 @@ import (
 type (
 	// Driver represents a MySQL driver for introspecting database schemas,
 	// generating diff between schema elements and apply migrations changes.
+	//
+	// A Driver is safe for concurrent use by multiple goroutines, as long as its
+	// underlying connection is (e.g. *sql.DB). Inspections, planning and applying
+	// of drivers that were opened on a single connection (i.e. *sql.Tx or *sql.Conn)
+	// are serialized. Note that diffing normalizes the given schema elements, and
+	// the same elements should not be diffed concurrently.
 	Driver struct {
 		conn
 		schema.Differ
@@ -26,6 +36,34 @@ type (
 		migrate.PlanApplier
 	}
 
+	// Option allows configuring the Driver using functional options.
+	Option func(*options)
+
+	// options holds the configuration of the Driver.
+	options struct {
+		trace    *sqltrace.Config
+		metrics  sqlmetrics.Recorder
+		autoInc  AutoIncrementMode
+		version  string
+		collate  string
+		catalog  string
+		log      sqlx.LogFunc
+		readOnly bool
+		coalesce bool
+		ordered  bool
+		minQuote bool
+		fold     bool
+		accounts bool
+		aurora   bool
+		lower    int
+		namer    naming.Strategy
+		parsers  []sqlx.TypeParser
+		vitess   *Vitess
+	}
+
+	// AutoIncrementMode controls how the AUTO_INCREMENT table option is diffed and planned.
+	AutoIncrementMode uint8
+
 	// database connection and its information.
 	conn struct {
 		schema.ExecQuerier
@@ -33,12 +71,80 @@ type (
 		version string
 		collate string
 		charset string
+		// Options that control the diff and the planning.
+		autoInc AutoIncrementMode
+		// Merge the changes of the same table into one ALTER statement.
+		coalesce bool
+		// Treat the order of table columns as significant.
+		ordered bool
+		// Quote only the identifiers that must be quoted.
+		minQuote bool
+		// Compare object names the way the server does,
+		// given its lower_case_table_names variable.
+		foldNames  bool
+		lowerNames int
+		// Strategy for naming the unnamed objects of the planned changes.
+		namer naming.Strategy
+		// Parsers for column types that are not recognized by the driver.
+		parsers []sqlx.TypeParser
+		// The Vitess configuration of the driver,
+		// if it is connected to a Vitess cluster.
+		vitess *Vitess
+		// Connected to an Amazon Aurora MySQL cluster.
+		aurora *Aurora
+		// Inspect and diff the user accounts and roles of the realm.
+		accounts bool
+		// Opened on a single database connection (i.e. *sql.Tx or *sql.Conn),
+		// before it was wrapped by the options of the driver (e.g. WithReadOnly).
+		single bool
 	}
 )
 
+// List of AUTO_INCREMENT modes.
+const (
+	// AutoIncrementInit is the default mode. The AUTO_INCREMENT value is
+	// set only on tables that have no value configured (e.g. new or empty
+	// tables), and the counter of existing tables is not compared, as it
+	// is advanced by the database on inserts.
+	AutoIncrementInit AutoIncrementMode = iota
+
+	// AutoIncrementIgnore ignores the AUTO_INCREMENT value of existing tables.
+	// New tables are still created with their configured AUTO_INCREMENT value.
+	AutoIncrementIgnore
+
+	// AutoIncrementSync compares the AUTO_INCREMENT value of existing tables,
+	// and updates the counter in case it is different from the desired value.
+	// Note that InnoDB resets values that are lower than (or equal to) the
+	// maximum value in the column to the maximum value plus one.
+	AutoIncrementSync
+)
+
 // Open opens a new MySQL driver.
-func Open(db schema.ExecQuerier) (*Driver, error) {
-	c := conn{ExecQuerier: db}
+func Open(db schema.ExecQuerier, opts ...Option) (*Driver, error) {
+	var (
+		o      options
+		single = sqlx.SingleConn(db)
+	)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.readOnly {
+		db = sqlx.ReadOnlyExecQuerier(db)
+	}
+	if o.log != nil {
+		db = sqlx.LogExecQuerier(db, o.log)
+	}
+	var tracer *sqltrace.Tracer
+	if o.trace != nil {
+		tracer = sqltrace.New("mysql", *o.trace)
+		db = tracer.ExecQuerier(db)
+	}
+	var metrics *sqlmetrics.Metrics
+	if o.metrics != nil {
+		metrics = sqlmetrics.New("mysql", o.metrics)
+		db = metrics.ExecQuerier(db)
+	}
+	c := conn{ExecQuerier: db, single: single, autoInc: o.autoInc, coalesce: o.coalesce, ordered: o.ordered, minQuote: o.minQuote, foldNames: o.fold, lowerNames: o.lower, namer: o.namer, parsers: o.parsers, accounts: o.accounts}
 	rows, err := db.QueryContext(context.Background(), variablesQuery)
 	if err != nil {
 		return nil, fmt.Errorf("mysql: query system variables: %w", err)
@@ -46,12 +152,214 @@ func Open(db schema.ExecQuerier) (*Driver, error) {
 	if err := sqlx.ScanOne(rows, &c.version, &c.collate, &c.charset); err != nil {
 		return nil, fmt.Errorf("mysql: scan system variables: %w", err)
 	}
-	return &Driver{
+	if o.version != "" {
+		c.version = o.version
+	}
+	if v, ok := vitessVersion(c.version); ok || o.vitess != nil {
+		c.version, c.vitess = v, o.vitess
+		if c.vitess == nil {
+			c.vitess = &Vitess{}
+		}
+	}
+	// Vitess clusters are not served by Aurora.
+	if o.aurora && c.vitess == nil {
+		if c.aurora, err = auroraVersion(context.Background(), db); err != nil {
+			return nil, err
+		}
+	}
+	if o.collate != "" {
+		c.collate, c.charset = o.collate, o.collate
+		// Collation names are prefixed with the name of their character set.
+		if i := strings.IndexByte(o.collate, '_'); i > 0 {
+			c.charset = o.collate[:i]
+		}
+	}
+	ic := c
+	if o.catalog != "" {
+		ic.ExecQuerier = sqlx.CatalogQuerier(c.ExecQuerier, "INFORMATION_SCHEMA", "`"+o.catalog+"`")
+	}
+	drv := &Driver{
 		conn:        c,
 		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
-		Inspector:   &inspect{c},
+		Inspector:   &inspect{ic},
 		PlanApplier: &planApply{c},
-	}, nil
+	}
+	if single {
+		var mu sync.Mutex
+		drv.Inspector = sqlx.SerialInspector(drv.Inspector, &mu)
+		drv.PlanApplier = sqlx.SerialPlanApplier(drv.PlanApplier, &mu)
+	}
+	if tracer != nil {
+		drv.Differ = tracer.Differ(drv.Differ)
+		drv.Inspector = tracer.Inspector(drv.Inspector)
+		drv.PlanApplier = tracer.PlanApplier(drv.PlanApplier)
+	}
+	if metrics != nil {
+		drv.Differ = metrics.Differ(drv.Differ)
+		drv.Inspector = metrics.Inspector(drv.Inspector)
+		drv.PlanApplier = metrics.PlanApplier(drv.PlanApplier)
+	}
+	return drv, nil
+}
+
+// InspectTables implements the schema.TablesInspector interface. It streams the tables
+// of the connected database to fn, instead of loading the realm as a whole. See the
+// schema.TablesInspector for more info.
+func (d *Driver) InspectTables(ctx context.Context, opts *schema.InspectRealmOption, fn func(*schema.Table) error) error {
+	return schema.InspectTables(ctx, d.Inspector, opts, fn)
+}
+
+// WithTracing enables OpenTelemetry tracing for the driver. Spans are created
+// for schema inspection, diff computation and each executed statement.
+func WithTracing(cfg sqltrace.Config) Option {
+	return func(o *options) {
+		o.trace = &cfg
+	}
+}
+
+// WithMetrics reports the events of schema inspections, diff computations, planning,
+// and each executed statement of the driver to the given Recorder. For example, the
+// sqlmetrics.Collector exposes them as Prometheus metrics.
+func WithMetrics(r sqlmetrics.Recorder) Option {
+	return func(o *options) {
+		o.metrics = r
+	}
+}
+
+// WithAutoIncrement configures how the AUTO_INCREMENT table option is compared
+// by the Differ and applied by the PlanApplier. See AutoIncrementMode for details.
+func WithAutoIncrement(m AutoIncrementMode) Option {
+	return func(o *options) {
+		o.autoInc = m
+	}
+}
+
+// WithVersion overrides the server version that is detected on Open (e.g. "8.0.19"
+// or "10.5.8-MariaDB"). It is useful for proxies and MySQL-compatible databases
+// that report a version that does not reflect their supported features.
+func WithVersion(v string) Option {
+	return func(o *options) {
+		o.version = v
+	}
+}
+
+// WithCollation overrides the default collation of the server (collation_server),
+// and its character set, that are used for omitting default attributes on inspection
+// and for comparing schema elements that do not define their collation explicitly.
+func WithCollation(c string) Option {
+	return func(o *options) {
+		o.collate = c
+	}
+}
+
+// WithCatalogSchema configures the driver to inspect the database using the given
+// schema instead of the INFORMATION_SCHEMA. For example, a schema that holds views
+// with the same structure, or a copy of the catalog tables.
+func WithCatalogSchema(name string) Option {
+	return func(o *options) {
+		o.catalog = name
+	}
+}
+
+// WithCoalesceAlter configures the PlanApplier to merge the changes of the same
+// table into one ALTER TABLE statement, including the rebuild of modified indexes,
+// in order to reduce the number of times large tables are locked and copied.
+// Modified foreign keys are still dropped in a separate statement, because MySQL
+// does not allow dropping and adding a constraint with the same name at once.
+func WithCoalesceAlter(b bool) Option {
+	return func(o *options) {
+		o.coalesce = b
+	}
+}
+
+// WithColumnOrder configures the Differ to treat the order of table columns as
+// significant, and the PlanApplier to move the columns to their position using the
+// FIRST and AFTER clauses. By default, the column order is ignored, and new columns
+// are appended to the end of the table.
+func WithColumnOrder(b bool) Option {
+	return func(o *options) {
+		o.ordered = b
+	}
+}
+
+// WithMinimalQuoting configures the PlanApplier to quote identifiers only when it is
+// needed, that is, when they are reserved words or contain characters other than ASCII
+// letters, digits and underscores. By default, all identifiers are quoted.
+func WithMinimalQuoting(b bool) Option {
+	return func(o *options) {
+		o.minQuote = b
+	}
+}
+
+// WithLowerCaseTableNames configures the Differ to compare object names the way the server
+// does, given the value of its lower_case_table_names system variable (0, 1 or 2). That is,
+// names of columns and indexes are compared case-insensitively, and names of schemas, tables
+// and foreign keys are compared case-insensitively if the value is not 0. By default, names
+// are compared as-is, and objects whose names differ only in case are dropped and recreated.
+func WithLowerCaseTableNames(v int) Option {
+	return func(o *options) {
+		o.fold, o.lower = true, v
+	}
+}
+
+// WithNaming configures the PlanApplier to name the indexes, foreign keys and checks that
+// are added unnamed by the planned changes, using the given naming strategy. For example,
+// naming.Rails{}. Note that the objects are named in place, that is, the given changes are
+// modified. By default, unnamed objects are left for the database to name.
+func WithNaming(s naming.Strategy) Option {
+	return func(o *options) {
+		o.namer = s
+	}
+}
+
+// WithTypeParser registers a parser for column types that are not recognized by the
+// driver on inspection (e.g. types that are added by extensions or plugins). Parsers
+// are called in the order they were registered, and should return one of the types
+// that are supported by the driver. Types that are not handled by any of the parsers
+// are kept as schema.UnsupportedType, and compared and planned by their raw form.
+func WithTypeParser(p func(raw string) (schema.Type, bool)) Option {
+	return func(o *options) {
+		o.parsers = append(o.parsers, p)
+	}
+}
+
+// WithReadOnly opens the driver in read-only mode. In this mode, all statements that
+// may write to the database are rejected with a schema.ReadOnlyError before they are
+// sent to it, and the driver can be used only for inspecting the database. Note that
+// this is not a replacement for connecting with a read-only credential, but a guard
+// against writes that are attempted by the driver or by its users.
+func WithReadOnly(b bool) Option {
+	return func(o *options) {
+		o.readOnly = b
+	}
+}
+
+// WithStatementLog calls f with each statement (and its arguments) that is
+// executed or queried by the driver, before it is sent to the database.
+func WithStatementLog(f func(ctx context.Context, stmt string, args []interface{})) Option {
+	return func(o *options) {
+		o.log = f
+	}
+}
+
+// WithAccounts configures the driver to manage the user accounts and roles of the
+// database, and their privileges. Realm inspections add them to the attributes of
+// the realm as *User and *Role, and they are diffed and planned with the schemas.
+// By default, accounts are not inspected, and are ignored by the differ.
+func WithAccounts(b bool) Option {
+	return func(o *options) {
+		o.accounts = b
+	}
+}
+
+// WithAurora configures the driver to detect on Open if it is connected to an Amazon Aurora
+// MySQL cluster, and to use its capabilities (e.g. fast DDL) in planning. The detection
+// runs an additional query that may be rejected by MySQL-compatible servers and proxies.
+// Hence, it is disabled by default. See Driver.Aurora for more info.
+func WithAurora(b bool) Option {
+	return func(o *options) {
+		o.aurora = b
+	}
 }
 
 // supportsCheck reports if the connected database supports
@@ -70,6 +378,23 @@ func (d *conn) supportsIndexExpr() bool {
 	return !d.mariadb() && d.gteV("8.0.13")
 }
 
+// supportsSRID reports if the connected database supports
+// the SRID attribute of spatial columns.
+func (d *conn) supportsSRID() bool {
+	return !d.mariadb() && d.gteV("8.0.3")
+}
+
+// supportsDescIndex reports if the connected database supports
+// descending indexes. Older versions parse the DESC keyword, but
+// create the index in ascending order.
+func (d *conn) supportsDescIndex() bool {
+	v := "8.0.1"
+	if d.mariadb() {
+		v = "10.8.1"
+	}
+	return d.gteV(v)
+}
+
 // supportsDisplayWidth reports if the connected database supports
 // getting the display width information from the information schema.
 func (d *conn) supportsDisplayWidth() bool {
@@ -94,6 +419,52 @@ func (d *conn) supportsEnforceCheck() bool {
 	return !d.mariadb() && d.gteV("8.0.16")
 }
 
+// supportsInstantAdd reports if the connected database adds columns
+// using the INSTANT algorithm, without rebuilding the table.
+func (d *conn) supportsInstantAdd() bool {
+	v := "8.0.12"
+	if d.mariadb() {
+		v = "10.3.2"
+	}
+	return d.gteV(v)
+}
+
+// supportsInstantDrop reports if the connected database drops
+// columns using the INSTANT algorithm, without rebuilding the table.
+func (d *conn) supportsInstantDrop() bool {
+	v := "8.0.29"
+	if d.mariadb() {
+		v = "10.4.0"
+	}
+	return d.gteV(v)
+}
+
+// supportsRoles reports if the connected database
+// supports roles (i.e. CREATE ROLE and role grants).
+func (d *conn) supportsRoles() bool {
+	return !d.mariadb() && d.gteV("8.0.0")
+}
+
+// supportsSystemVersioning reports if the connected database
+// supports system-versioned tables (i.e. WITH SYSTEM VERSIONING).
+func (d *conn) supportsSystemVersioning() bool {
+	return d.mariadb() && d.gteV("10.3.4")
+}
+
+// maxKeyLen returns the maximum length in bytes of an index key. Since MySQL 5.7.7
+// and MariaDB 10.2.2, innodb_large_prefix is enabled by default and the limit of
+// the DYNAMIC row format is 3072 bytes. Older versions are limited to 767 bytes.
+func (d *conn) maxKeyLen() int {
+	v := "5.7.7"
+	if d.mariadb() {
+		v = "10.2.2"
+	}
+	if d.gteV(v) {
+		return 3072
+	}
+	return 767
+}
+
 // mariadb reports if the Driver is connected to a MariaDB database.
 func (d *conn) mariadb() bool {
 	return strings.Index(d.version, "MariaDB") > 0
@@ -192,3 +563,12 @@ const (
 	defaultGen    = "default_generated"
 	autoIncrement = "auto_increment"
 )
+
+// Table row formats. See: https://dev.mysql.com/doc/refman/8.0/en/innodb-row-format.html.
+const (
+	RowFormatDefault    = "DEFAULT"
+	RowFormatDynamic    = "DYNAMIC"
+	RowFormatCompact    = "COMPACT"
+	RowFormatRedundant  = "REDUNDANT"
+	RowFormatCompressed = "COMPRESSED"
+)
@@ -0,0 +1,180 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// Queries to get the approximate number of rows in a table. For InnoDB
+// tables, the value is an estimation that is based on sampled pages.
+const (
+	rowsQuery       = "SELECT `TABLE_ROWS` FROM `INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA` = (SELECT DATABASE()) AND `TABLE_NAME` = ?"
+	rowsQuerySchema = "SELECT `TABLE_ROWS` FROM `INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ?"
+)
+
+// estimate sets the impact of the planned changes that modify or drop existing tables.
+func (s *state) estimate(ctx context.Context) error {
+	rows := make(map[*schema.Table]int64)
+	for _, c := range s.Changes {
+		var t *schema.Table
+		switch src := c.Source.(type) {
+		case *schema.DropTable:
+			t = src.T
+		case *schema.ModifyTable:
+			t = src.T
+		default:
+			continue
+		}
+		n, ok := rows[t]
+		if !ok {
+			var err error
+			if n, err = s.tableRows(ctx, t); err != nil {
+				return err
+			}
+			rows[t] = n
+		}
+		name := t.Name
+		if t.Schema != nil && t.Schema.Name != "" {
+			name = t.Schema.Name + "." + name
+		}
+		i := &migrate.Impact{Table: name, Rows: n}
+		s.impact(i, c)
+		c.Impact = i
+	}
+	return nil
+}
+
+// tableRows returns the approximate number of rows in the
+// table, or -1 if it is not known or the table does not exist.
+func (s *state) tableRows(ctx context.Context, t *schema.Table) (int64, error) {
+	// Tables that were inspected with their statistics
+	// do not require an additional query.
+	var stats schema.TableStats
+	if sqlx.Has(t.Attrs, &stats) {
+		return stats.Rows, nil
+	}
+	query, args := rowsQuery, []interface{}{t.Name}
+	if t.Schema != nil && t.Schema.Name != "" {
+		query, args = rowsQuerySchema, []interface{}{t.Schema.Name, t.Name}
+	}
+	rows, err := s.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("mysql: querying table %q statistics: %w", t.Name, err)
+	}
+	var n sql.NullInt64
+	switch err := sqlx.ScanOne(rows, &n); {
+	case errors.Is(err, sql.ErrNoRows):
+		return -1, nil
+	case err != nil:
+		return 0, fmt.Errorf("mysql: scanning table %q statistics: %w", t.Name, err)
+	case !n.Valid:
+		return -1, nil
+	}
+	return n.Int64, nil
+}
+
+// impact estimates the impact of a planned change according to the algorithms
+// that are used by InnoDB for online DDL operations (INSTANT, INPLACE or COPY).
+// See: https://dev.mysql.com/doc/refman/8.0/en/innodb-online-ddl-operations.html
+func (s *state) impact(i *migrate.Impact, c *migrate.Change) {
+	var reasons []string
+	switch src := c.Source.(type) {
+	case *schema.DropTable:
+		i.Lock, reasons = migrate.LockExclusive, append(reasons, "drop table")
+	case *schema.ModifyTable:
+		for _, change := range src.Changes {
+			lock, r := s.alterImpact(i, change)
+			if lock > i.Lock {
+				i.Lock = lock
+			}
+			if r != "" {
+				reasons = append(reasons, r)
+			}
+		}
+	}
+	i.Reason = strings.Join(reasons, ", ")
+}
+
+// alterImpact returns the lock level and the rewrite or scan reason of one
+// ALTER TABLE clause.
+func (s *state) alterImpact(i *migrate.Impact, c schema.Change) (migrate.LockLevel, string) {
+	switch c := c.(type) {
+	case *schema.AddColumn:
+		switch {
+		case sqlx.Has(c.C.Attrs, &AutoIncrement{}):
+			i.Rewrite = true
+			return migrate.LockWrite, fmt.Sprintf("add auto_increment column %q", c.C.Name)
+		case !s.supportsInstantAdd() && !s.supportsFastAdd(c.C):
+			i.Rewrite = true
+			return migrate.LockNone, fmt.Sprintf("add column %q", c.C.Name)
+		}
+	case *schema.DropColumn:
+		if !s.supportsInstantDrop() {
+			i.Rewrite = true
+			return migrate.LockNone, fmt.Sprintf("drop column %q", c.C.Name)
+		}
+	case *schema.ModifyColumn:
+		switch k := c.Change; {
+		case k.Is(schema.ChangeType), k.Is(schema.ChangeCharset), k.Is(schema.ChangeCollation):
+			i.Rewrite = true
+			return migrate.LockWrite, fmt.Sprintf("change type of column %q", c.To.Name)
+		case k.Is(schema.ChangeNull):
+			i.Rewrite = true
+			return migrate.LockNone, fmt.Sprintf("change nullability of column %q", c.To.Name)
+		case k.Is(schema.ChangePosition):
+			i.Rewrite = true
+			return migrate.LockNone, fmt.Sprintf("move column %q", c.To.Name)
+		}
+	case *schema.AddIndex:
+		var t IndexType
+		if sqlx.Has(c.I.Attrs, &t) && (strings.EqualFold(t.T, "FULLTEXT") || strings.EqualFold(t.T, "SPATIAL")) {
+			i.Scan = true
+			return migrate.LockWrite, fmt.Sprintf("build %s index %q", strings.ToLower(t.T), c.I.Name)
+		}
+		i.Scan = true
+		return migrate.LockNone, fmt.Sprintf("build index %q", c.I.Name)
+	// Foreign keys are added using the COPY algorithm,
+	// unless the foreign_key_checks variable is disabled.
+	case *schema.AddForeignKey:
+		i.Rewrite = true
+		return migrate.LockWrite, fmt.Sprintf("add foreign key %q", c.F.Symbol)
+	case *schema.AddCheck:
+		i.Rewrite = true
+		return migrate.LockWrite, fmt.Sprintf("add check %q", c.C.Name)
+	case *schema.ModifyCheck:
+		i.Rewrite = true
+		return migrate.LockWrite, fmt.Sprintf("modify check %q", c.To.Name)
+	case *schema.AddAttr:
+		if charsetAttr(c.A) {
+			i.Rewrite = true
+			return migrate.LockWrite, "change table charset"
+		}
+	case *schema.ModifyAttr:
+		if charsetAttr(c.To) {
+			i.Rewrite = true
+			return migrate.LockWrite, "change table charset"
+		}
+	}
+	return migrate.LockNone, ""
+}
+
+// charsetAttr reports if the table attribute changes the
+// character set of the table, which requires rebuilding it.
+func charsetAttr(a schema.Attr) bool {
+	switch a.(type) {
+	case *schema.Charset, *schema.Collation:
+		return true
+	}
+	return false
+}
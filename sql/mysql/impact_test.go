@@ -0,0 +1,102 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChanges_Impact(t *testing.T) {
+	var (
+		users   = schema.NewTable("users").SetSchema(schema.New("test"))
+		name    = schema.NewStringColumn("name", "varchar(255)")
+		changes = []schema.Change{
+			&schema.ModifyTable{
+				T: users,
+				Changes: []schema.Change{
+					&schema.AddColumn{C: schema.NewIntColumn("age", "int")},
+					&schema.DropColumn{C: schema.NewIntColumn("rank", "int")},
+				},
+			},
+			&schema.ModifyTable{
+				T: users,
+				Changes: []schema.Change{
+					&schema.AddIndex{I: schema.NewIndex("users_name").AddColumns(name)},
+				},
+			},
+			&schema.ModifyTable{
+				T: schema.NewTable("pets"),
+				Changes: []schema.Change{
+					&schema.ModifyColumn{
+						From:   schema.NewIntColumn("age", "int"),
+						To:     schema.NewIntColumn("age", "bigint"),
+						Change: schema.ChangeType,
+					},
+				},
+			},
+		}
+	)
+	tests := []struct {
+		version string
+		impacts []*migrate.Impact
+	}{
+		{
+			version: "5.7.32",
+			impacts: []*migrate.Impact{
+				{Table: "test.users", Rows: 100, Rewrite: true, Reason: `add column "age", drop column "rank"`},
+				{Table: "test.users", Rows: 100, Scan: true, Reason: `build index "users_name"`},
+				{Table: "pets", Rows: -1, Rewrite: true, Lock: migrate.LockWrite, Reason: `change type of column "age"`},
+			},
+		},
+		{
+			version: "8.0.19",
+			impacts: []*migrate.Impact{
+				{Table: "test.users", Rows: 100, Rewrite: true, Reason: `drop column "rank"`},
+				{Table: "test.users", Rows: 100, Scan: true, Reason: `build index "users_name"`},
+				{Table: "pets", Rows: -1, Rewrite: true, Lock: migrate.LockWrite, Reason: `change type of column "age"`},
+			},
+		},
+		{
+			version: "8.0.29",
+			impacts: []*migrate.Impact{
+				{Table: "test.users", Rows: 100},
+				{Table: "test.users", Rows: 100, Scan: true, Reason: `build index "users_name"`},
+				{Table: "pets", Rows: -1, Rewrite: true, Lock: migrate.LockWrite, Reason: `change type of column "age"`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			db, m, err := sqlmock.New()
+			require.NoError(t, err)
+			mock{m}.version(tt.version)
+			drv, err := Open(db)
+			require.NoError(t, err)
+			m.ExpectQuery(sqltest.Escape(rowsQuerySchema)).
+				WithArgs("test", "users").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS"}).AddRow(100))
+			m.ExpectQuery(sqltest.Escape(rowsQuery)).
+				WithArgs("pets").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS"}))
+			plan, err := drv.PlanChanges(migrate.WithImpact(context.Background()), "plan", changes)
+			require.NoError(t, err)
+			require.NoError(t, m.ExpectationsWereMet())
+			require.Len(t, plan.Changes, len(tt.impacts))
+			for i, c := range plan.Changes {
+				require.Equal(t, tt.impacts[i], c.Impact)
+			}
+			require.True(t, plan.Changes[2].Impact.Blocking())
+			require.False(t, plan.Changes[1].Impact.Blocking())
+		})
+	}
+}
@@ -28,9 +28,18 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 		return nil, err
 	}
 	r := schema.NewRealm(schemas...).SetCharset(i.charset).SetCollation(i.collate)
-	if err := i.inspectTables(ctx, r, nil); err != nil {
+	var topts *schema.InspectOptions
+	if opts != nil && opts.Stats {
+		topts = &schema.InspectOptions{Stats: true}
+	}
+	if err := i.inspectTables(ctx, r, topts); err != nil {
 		return nil, err
 	}
+	if i.accounts {
+		if err := i.inspectAccounts(ctx, r); err != nil {
+			return nil, err
+		}
+	}
 	sqlx.LinkSchemaTables(schemas)
 	return r, nil
 }
@@ -61,30 +70,104 @@ func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *sche
 		return err
 	}
 	for _, s := range r.Schemas {
-		if len(s.Tables) == 0 {
-			continue
-		}
-		if err := i.columns(ctx, s); err != nil {
-			return err
-		}
-		if err := i.indexes(ctx, s); err != nil {
+		if err := i.schemaTables(ctx, s, opts); err != nil {
 			return err
 		}
+	}
+	return nil
+}
+
+// schemaTables inspects the columns, indexes, foreign keys, checks
+// and definitions of the tables that were added to the schema.
+func (i *inspect) schemaTables(ctx context.Context, s *schema.Schema, opts *schema.InspectOptions) error {
+	if len(s.Tables) == 0 {
+		return nil
+	}
+	if err := i.columns(ctx, s); err != nil {
+		return err
+	}
+	if err := i.indexes(ctx, s); err != nil {
+		return err
+	}
+	if i.vitess == nil || i.vitess.ForeignKeys {
 		if err := i.fks(ctx, s); err != nil {
 			return err
 		}
-		if err := i.checks(ctx, s); err != nil {
+	}
+	if err := i.checks(ctx, s); err != nil {
+		return err
+	}
+	if err := i.showCreate(ctx, s); err != nil {
+		return err
+	}
+	if opts != nil && opts.Stats {
+		if err := i.stats(ctx, s); err != nil {
 			return err
 		}
-		if err := i.showCreate(ctx, s); err != nil {
+	}
+	return nil
+}
+
+// streamBatch is the number of tables that are inspected
+// together by the queries of a streamed inspection.
+const streamBatch = 100
+
+// InspectTables implements the schema.TablesInspector interface. The tables of each schema are
+// inspected in batches, and passed to fn without being added to their schema. Hence, foreign keys
+// are linked only to the tables in their batch, and to stub tables otherwise.
+func (i *inspect) InspectTables(ctx context.Context, opts *schema.InspectRealmOption, fn func(*schema.Table) error) error {
+	schemas, err := i.schemas(ctx, opts)
+	if err != nil {
+		return err
+	}
+	schema.NewRealm(schemas...).SetCharset(i.charset).SetCollation(i.collate)
+	var topts *schema.InspectOptions
+	if opts != nil && opts.Stats {
+		topts = &schema.InspectOptions{Stats: true}
+	}
+	for _, s := range schemas {
+		// Table attributes are loaded at once, as they are loaded from
+		// a single row. Other table parts are loaded in batches.
+		all := &schema.Schema{Name: s.Name}
+		if err := i.tables(ctx, &schema.Realm{Schemas: []*schema.Schema{all}}, nil); err != nil {
 			return err
 		}
+		for len(all.Tables) > 0 {
+			n := streamBatch
+			if n > len(all.Tables) {
+				n = len(all.Tables)
+			}
+			batch := schema.New(s.Name).AddTables(all.Tables[:n]...)
+			all.Tables = all.Tables[n:]
+			if err := i.schemaTables(ctx, batch, topts); err != nil {
+				return err
+			}
+			sqlx.LinkSchemaTables([]*schema.Schema{batch})
+			// Link the tables and the stubbed referenced tables to
+			// the schema, as the batch schema is not held by realm.
+			for _, t := range batch.Tables {
+				t.Schema = s
+				for _, fk := range t.ForeignKeys {
+					if fk.RefTable.Schema == batch {
+						fk.RefTable.Schema = s
+					}
+				}
+			}
+			for _, t := range batch.Tables {
+				if err := fn(t); err != nil {
+					return err
+				}
+			}
+		}
 	}
 	return nil
 }
 
 // schemas returns the list of the schemas in the database.
 func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
+	if i.vitess != nil {
+		return i.vitessSchemas(ctx, opts)
+	}
 	var (
 		args  []interface{}
 		query = schemasQuery
@@ -130,10 +213,25 @@ func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption)
 }
 
 func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.InspectOptions) error {
+	// Keyspaces are queried one by one, because vtgate routes INFORMATION_SCHEMA
+	// queries to the keyspace in their predicate, and the schema names in their
+	// results are the names of the underlying databases (e.g. vt_<keyspace>).
+	if i.vitess != nil && len(realm.Schemas) > 1 {
+		for _, s := range realm.Schemas {
+			if err := i.tables(ctx, &schema.Realm{Schemas: []*schema.Schema{s}}, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	var (
-		args  []interface{}
-		query = fmt.Sprintf(tablesQuery, nArgs(len(realm.Schemas)))
+		args         []interface{}
+		versioning   = i.supportsSystemVersioning()
+		query, qargs = tablesQuery, tablesQueryArgs
 	)
+	if versioning {
+		query, qargs = marTablesQuery, marTablesQueryArgs
+	}
 	for _, s := range realm.Schemas {
 		args = append(args, s.Name)
 	}
@@ -141,7 +239,9 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 		for _, t := range opts.Tables {
 			args = append(args, t)
 		}
-		query = fmt.Sprintf(tablesQueryArgs, nArgs(len(realm.Schemas)), nArgs(len(opts.Tables)))
+		query = fmt.Sprintf(qargs, nArgs(len(realm.Schemas)), nArgs(len(opts.Tables)))
+	} else {
+		query = fmt.Sprintf(query, nArgs(len(realm.Schemas)))
 	}
 	rows, err := i.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -150,16 +250,23 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 	defer rows.Close()
 	for rows.Next() {
 		var (
-			autoinc                                             sql.NullInt64
-			tSchema, name, charset, collation, comment, options sql.NullString
+			autoinc                                                            sql.NullInt64
+			tSchema, name, charset, collation, comment, options, engine, ttype sql.NullString
+			dest                                                               = []interface{}{&tSchema, &name, &charset, &collation, &autoinc, &comment, &options, &engine}
 		)
-		if err := rows.Scan(&tSchema, &name, &charset, &collation, &autoinc, &comment, &options); err != nil {
+		if versioning {
+			dest = append(dest, &ttype)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return fmt.Errorf("scan table information: %w", err)
 		}
 		if !sqlx.ValidString(tSchema) || !sqlx.ValidString(name) {
 			return fmt.Errorf("invalid schema or table name: %q.%q", tSchema.String, name.String)
 		}
 		s, ok := realm.Schema(tSchema.String)
+		if !ok && i.vitess != nil && len(realm.Schemas) == 1 {
+			s, ok = realm.Schemas[0], true
+		}
 		if !ok {
 			return fmt.Errorf("schema %q was not found in realm", tSchema.String)
 		}
@@ -180,16 +287,34 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 				Text: comment.String,
 			})
 		}
-		if sqlx.ValidString(options) {
-			t.Attrs = append(t.Attrs, &CreateOptions{
-				V: options.String,
+		if sqlx.ValidString(engine) {
+			t.Attrs = append(t.Attrs, &Engine{
+				V: engine.String,
 			})
 		}
+		if sqlx.ValidString(options) {
+			// ROW_FORMAT is extracted from the CREATE_OPTIONS
+			// in order to be compared and planned separately.
+			if m := reRowFormat.FindStringSubmatch(options.String); m != nil {
+				t.Attrs = append(t.Attrs, &RowFormat{
+					V: strings.ToUpper(m[1]),
+				})
+				options.String = strings.TrimSpace(reRowFormat.ReplaceAllString(options.String, ""))
+			}
+			if options.String != "" {
+				t.Attrs = append(t.Attrs, &CreateOptions{
+					V: options.String,
+				})
+			}
+		}
 		if autoinc.Valid {
 			t.Attrs = append(t.Attrs, &AutoIncrement{
 				V: autoinc.Int64,
 			})
 		}
+		if ttype.String == "SYSTEM VERSIONED" {
+			t.Attrs = append(t.Attrs, &SystemVersioned{})
+		}
 	}
 	if err := rows.Close(); err != nil {
 		return err
@@ -197,6 +322,9 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 	return nil
 }
 
+// reRowFormat matches the ROW_FORMAT option in the CREATE_OPTIONS column.
+var reRowFormat = regexp.MustCompile(`(?i)\brow_format=(\w+)`)
+
 // columns queries and appends the columns of the given table.
 func (i *inspect) columns(ctx context.Context, s *schema.Schema) error {
 	rows, err := i.querySchema(ctx, columnsQuery, s)
@@ -233,7 +361,18 @@ func (i *inspect) addColumn(s *schema.Schema, rows *sql.Rows) error {
 	if err != nil {
 		return err
 	}
+	if _, ok := ct.(*schema.UnsupportedType); ok {
+		if t, ok := sqlx.ParseCustomType(c.Type.Raw, i.parsers); ok {
+			ct = t
+		}
+	}
 	c.Type.Type = ct
+	// The SRID attribute is not exposed in INFORMATION_SCHEMA
+	// and it is extracted from the 'SHOW CREATE' command.
+	if _, ok := ct.(*schema.SpatialType); ok && i.supportsSRID() {
+		s := putShow(t)
+		s.srid = append(s.srid, c)
+	}
 	if err := i.extraAttr(t, c, extra.String); err != nil {
 		return err
 	}
@@ -433,6 +572,9 @@ func (i *inspect) checks(ctx context.Context, s *schema.Schema) error {
 	return rows.Err()
 }
 
+// reRowPeriod matches the EXTRA column of the period columns of system-versioned tables.
+var reRowPeriod = regexp.MustCompile(`(?i)^row (start|end)(?: invisible)?$`)
+
 var reTimeOnUpdate = regexp.MustCompile(`(?i)^(?:default_generated )?on update (current_timestamp(?:\(\d?\))?)$`)
 
 // extraAttr parses the EXTRA column from the INFORMATION_SCHEMA.COLUMNS table
@@ -455,6 +597,18 @@ func (i *inspect) extraAttr(t *schema.Table, c *schema.Column, extra string) err
 		c.Attrs = append(c.Attrs, a)
 	case reTimeOnUpdate.MatchString(extra):
 		c.Attrs = append(c.Attrs, &OnUpdate{A: reTimeOnUpdate.FindStringSubmatch(extra)[1]})
+	case reRowPeriod.MatchString(extra):
+		// Period columns of system-versioned tables in MariaDB.
+		v, ok := systemVersioned(t.Attrs)
+		if !ok {
+			return fmt.Errorf("period column %q of table %q that is not system-versioned", c.Name, t.Name)
+		}
+		m := reRowPeriod.FindStringSubmatch(extra)
+		if strings.EqualFold(m[1], "start") {
+			v.Start = c.Name
+		} else {
+			v.End = c.Name
+		}
 	default:
 		return fmt.Errorf("unknown attribute %q", extra)
 	}
@@ -475,7 +629,11 @@ func (i *inspect) showCreate(ctx context.Context, s *schema.Schema) error {
 		if err := i.setAutoInc(s, t); err != nil {
 			return err
 		}
-		// TODO(a8m): setChecks, setIndexExpr from CREATE statement.
+		if err := i.setIndexExpr(s, t); err != nil {
+			return err
+		}
+		i.setSRID(s, t)
+		// TODO(a8m): setChecks from CREATE statement.
 	}
 	return nil
 }
@@ -507,6 +665,96 @@ func (i *inspect) setAutoInc(s *showTable, t *schema.Table) error {
 	return nil
 }
 
+// setIndexExpr extracts the expressions of functional key parts from CREATE TABLE,
+// because INFORMATION_SCHEMA returns them escaped (e.g. "_utf8mb4\'a\'"). Expressions
+// of indexes that are not found in the statement are kept as-is.
+func (i *inspect) setIndexExpr(s *showTable, t *schema.Table) error {
+	if len(s.indexes) == 0 {
+		return nil
+	}
+	var c CreateStmt
+	if !sqlx.Has(t.Attrs, &c) {
+		return fmt.Errorf("missing CREATE TABLE statment in attribuets for %q", t.Name)
+	}
+	for idx, pos := range s.indexes {
+		parts, ok := keyParts(c.S, idx.Name)
+		if !ok || len(parts) != len(idx.Parts) {
+			continue
+		}
+		for _, p := range pos {
+			// Functional key parts are wrapped with parentheses,
+			// and may be followed by their direction.
+			x := strings.TrimSpace(parts[p])
+			if u := strings.ToUpper(x); strings.HasSuffix(u, " DESC") || strings.HasSuffix(u, " ASC") {
+				x = strings.TrimSpace(x[:strings.LastIndexByte(x, ' ')])
+			}
+			if strings.HasPrefix(x, "(") && strings.HasSuffix(x, ")") {
+				idx.Parts[p].X = &schema.RawExpr{X: x[1 : len(x)-1]}
+			}
+		}
+	}
+	return nil
+}
+
+// setSRID extracts the SRID attribute of spatial columns from CREATE TABLE.
+// For example: "`location` point NOT NULL /*!80003 SRID 4326 */".
+func (i *inspect) setSRID(s *showTable, t *schema.Table) {
+	var c CreateStmt
+	if len(s.srid) == 0 || !sqlx.Has(t.Attrs, &c) {
+		return
+	}
+	for _, column := range s.srid {
+		name := "`" + strings.ReplaceAll(column.Name, "`", "``") + "`"
+		re := regexp.MustCompile(`(?m)^\s*` + regexp.QuoteMeta(name) + ` [^\n]*\bSRID (\d+)`)
+		m := re.FindStringSubmatch(c.S)
+		if m == nil {
+			continue
+		}
+		id, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		column.Attrs = append(column.Attrs, &SRID{ID: id})
+	}
+}
+
+// keyParts returns the key parts of the given index, as they are defined
+// in the CREATE TABLE statement. For example, ["(lower(`a`))", "`b` DESC"].
+func keyParts(stmt, name string) ([]string, bool) {
+	i := strings.Index(stmt, "KEY `"+strings.ReplaceAll(name, "`", "``")+"` (")
+	if i == -1 {
+		return nil, false
+	}
+	var (
+		parts []string
+		depth int
+		quote byte
+		start = strings.IndexByte(stmt[i:], '(') + i + 1
+	)
+	for j := start; j < len(stmt); j++ {
+		switch c := stmt[j]; {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				j++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case c == ')':
+			return append(parts, stmt[start:j]), true
+		case c == ',' && depth == 0:
+			parts = append(parts, stmt[start:j])
+			start = j + 1
+		}
+	}
+	return nil, false
+}
+
 // createStmt loads the CREATE TABLE statement for the table.
 func (i *inspect) createStmt(ctx context.Context, t *schema.Table) error {
 	c := &CreateStmt{}
@@ -610,6 +858,40 @@ func (i *inspect) marDefaultExpr(c *schema.Column, x string) schema.Expr {
 	return &schema.RawExpr{X: x}
 }
 
+// stats queries and sets the approximate statistics of the schema tables.
+// Note that for InnoDB tables, the values are estimations that are based
+// on sampled pages, and may be stale if the tables were not analyzed lately.
+func (i *inspect) stats(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.querySchema(ctx, statsQuery, s)
+	if err != nil {
+		return fmt.Errorf("mysql: query schema %q statistics: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name             string
+			n, data, indexes sql.NullInt64
+		)
+		if err := rows.Scan(&name, &n, &data, &indexes); err != nil {
+			return fmt.Errorf("mysql: scan table statistics: %w", err)
+		}
+		t, ok := s.Table(name)
+		if !ok {
+			return fmt.Errorf("mysql: table %q was not found in schema", name)
+		}
+		t.Attrs = append(t.Attrs, &schema.TableStats{Rows: nullInt(n), DataSize: nullInt(data), IndexSize: nullInt(indexes)})
+	}
+	return rows.Err()
+}
+
+// nullInt returns the value of n, or -1 if it is NULL.
+func nullInt(n sql.NullInt64) int64 {
+	if !n.Valid {
+		return -1
+	}
+	return n.Int64
+}
+
 func (i *inspect) querySchema(ctx context.Context, query string, s *schema.Schema) (*sql.Rows, error) {
 	args := []interface{}{s.Name}
 	for _, t := range s.Tables {
@@ -624,6 +906,9 @@ const (
 	// Query to list system variables.
 	variablesQuery = "SELECT @@version, @@collation_server, @@character_set_server"
 
+	// Query to detect Amazon Aurora clusters and their lab mode.
+	auroraQuery = "SHOW VARIABLES WHERE `Variable_name` IN ('aurora_version', 'aurora_lab_mode')"
+
 	// Query to list database schemas.
 	schemasQuery = "SELECT `SCHEMA_NAME`, `DEFAULT_CHARACTER_SET_NAME`, `DEFAULT_COLLATION_NAME` from `INFORMATION_SCHEMA`.`SCHEMATA` WHERE `SCHEMA_NAME` NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys') ORDER BY `SCHEMA_NAME`"
 
@@ -645,7 +930,8 @@ SELECT
 	t1.TABLE_COLLATION,
 	t1.AUTO_INCREMENT,
 	t1.TABLE_COMMENT,
-	t1.CREATE_OPTIONS
+	t1.CREATE_OPTIONS,
+	t1.ENGINE
 FROM
 	INFORMATION_SCHEMA.TABLES AS t1
 	JOIN INFORMATION_SCHEMA.COLLATIONS AS t2
@@ -664,7 +950,8 @@ SELECT
 	t1.TABLE_COLLATION,
 	t1.AUTO_INCREMENT,
 	t1.TABLE_COMMENT,
-	t1.CREATE_OPTIONS
+	t1.CREATE_OPTIONS,
+	t1.ENGINE
 FROM
 	INFORMATION_SCHEMA.TABLES AS t1
 	JOIN INFORMATION_SCHEMA.COLLATIONS AS t2
@@ -692,6 +979,9 @@ ORDER BY
 	t1.CONSTRAINT_NAME
 `
 
+	// Query to list the approximate statistics of tables.
+	statsQuery = "SELECT `TABLE_NAME`, `TABLE_ROWS`, `DATA_LENGTH`, `INDEX_LENGTH` FROM `INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `TABLE_NAME`"
+
 	// Query to list table foreign keys.
 	fksQuery = `
 SELECT
@@ -721,6 +1011,13 @@ ORDER BY
 	t1.ORDINAL_POSITION`
 )
 
+// MariaDB queries also select the type of the tables,
+// in order to detect system-versioned tables.
+var (
+	marTablesQuery     = strings.Replace(tablesQuery, "t1.ENGINE\n", "t1.ENGINE,\n\tt1.TABLE_TYPE\n", 1)
+	marTablesQueryArgs = strings.Replace(tablesQueryArgs, "t1.ENGINE\n", "t1.ENGINE,\n\tt1.TABLE_TYPE\n", 1)
+)
+
 type (
 	// AutoIncrement attribute for columns with "AUTO_INCREMENT" as a default.
 	// V represent an optional start value for the counter.
@@ -735,6 +1032,31 @@ type (
 		V string
 	}
 
+	// Engine attribute describes the storage engine of a table (e.g. InnoDB).
+	Engine struct {
+		schema.Attr
+		V string
+	}
+
+	// RowFormat attribute describes the ROW_FORMAT table option (e.g. DYNAMIC or COMPRESSED).
+	RowFormat struct {
+		schema.Attr
+		V string
+	}
+
+	// SystemVersioned attribute describes a MariaDB system-versioned table, that keeps
+	// the history of its rows (i.e. WITH SYSTEM VERSIONING). Start and End hold the names
+	// of the period columns of the table, if they were defined explicitly (i.e. columns
+	// GENERATED ALWAYS AS ROW START and ROW END), or are empty if the table uses the
+	// implicit ROW_START and ROW_END columns that are hidden by the database.
+	//
+	// Note that MariaDB rejects most ALTER TABLE commands on system-versioned tables,
+	// unless the system_versioning_alter_history variable is set to KEEP.
+	SystemVersioned struct {
+		schema.Attr
+		Start, End string
+	}
+
 	// CreateStmt describes the SQL statement used to create a table.
 	CreateStmt struct {
 		schema.Attr
@@ -747,6 +1069,14 @@ type (
 		A string
 	}
 
+	// SRID describes the spatial reference system identifier of a spatial column.
+	// Values of the column are restricted to the given SRID, and they can be used
+	// by SPATIAL indexes. Supported by MySQL 8.0.3 and above.
+	SRID struct {
+		schema.Attr
+		ID int
+	}
+
 	// SubPart attribute defines an option index prefix length for columns.
 	SubPart struct {
 		schema.Attr
@@ -801,9 +1131,21 @@ type (
 		checks bool
 		// indexes that contain expressions.
 		indexes map[*schema.Index][]int
+		// spatial columns that may be restricted to an SRID.
+		srid []*schema.Column
 	}
 )
 
+// systemVersioned returns the SystemVersioned attribute of a table, if it exists.
+func systemVersioned(attrs []schema.Attr) (*SystemVersioned, bool) {
+	for _, a := range attrs {
+		if v, ok := a.(*SystemVersioned); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
 func putShow(t *schema.Table) *showTable {
 	for i := range t.Attrs {
 		if s, ok := t.Attrs[i].(*showTable); ok {
@@ -21,6 +21,7 @@ import (
 var (
 	queryFKs         = sqltest.Escape(fmt.Sprintf(fksQuery, "?"))
 	queryTable       = sqltest.Escape(fmt.Sprintf(tablesQuery, "?"))
+	queryMarTable    = sqltest.Escape(fmt.Sprintf(marTablesQuery, "?"))
 	queryColumns     = sqltest.Escape(fmt.Sprintf(columnsQuery, "?"))
 	queryIndexes     = sqltest.Escape(fmt.Sprintf(indexesQuery, "?"))
 	queryIndexesExpr = sqltest.Escape(fmt.Sprintf(indexesExprQuery, "?"))
@@ -41,11 +42,11 @@ func TestDriver_InspectTable(t *testing.T) {
 				m.ExpectQuery(queryTable).
 					WithArgs("public").
 					WillReturnRows(sqltest.Rows(`
-+--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+
-| TABLE_SCHEMA | TABLE_NAME   | CHARACTER_SET_NAME | TABLE_COLLATION    | AUTO_INCREMENT | TABLE_COMMENT | CREATE_OPTIONS     |
-+--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+
-| public       | users        | utf8mb4            | utf8mb4_0900_ai_ci | nil            | Comment       | COMPRESSION="ZLIB" |
-+--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+
++--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------------------------------+--------+
+| TABLE_SCHEMA | TABLE_NAME   | CHARACTER_SET_NAME | TABLE_COLLATION    | AUTO_INCREMENT | TABLE_COMMENT | CREATE_OPTIONS                             | ENGINE |
++--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------------------------------+--------+
+| public       | users        | utf8mb4            | utf8mb4_0900_ai_ci | nil            | Comment       | COMPRESSION="ZLIB" row_format=COMPRESSED   | InnoDB |
++--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------------------------------+--------+
 `))
 				m.ExpectQuery(queryColumns).
 					WithArgs("public", "users").
@@ -82,6 +83,8 @@ func TestDriver_InspectTable(t *testing.T) {
 					&schema.Charset{V: "utf8mb4"},
 					&schema.Collation{V: "utf8mb4_0900_ai_ci"},
 					&schema.Comment{Text: "Comment"},
+					&Engine{V: "InnoDB"},
+					&RowFormat{V: "COMPRESSED"},
 					&CreateOptions{V: `COMPRESSION="ZLIB"`},
 					&CreateStmt{S: "CREATE TABLE users (id bigint NOT NULL AUTO_INCREMENT) ENGINE=InnoDB AUTO_INCREMENT=55834574848 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"},
 					&AutoIncrement{V: 55834574848},
@@ -99,11 +102,11 @@ func TestDriver_InspectTable(t *testing.T) {
 				m.ExpectQuery(queryTable).
 					WithArgs("public").
 					WillReturnRows(sqltest.Rows(`
-+--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+
-| TABLE_SCHEMA | TABLE_NAME   | CHARACTER_SET_NAME | TABLE_COLLATION    | AUTO_INCREMENT | TABLE_COMMENT | CREATE_OPTIONS     |
-+--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+
-| public       | users        | utf8mb4            | utf8mb4_0900_ai_ci | nil            | Comment       | COMPRESSION="ZLIB" |
-+--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+
++--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+--------+
+| TABLE_SCHEMA | TABLE_NAME   | CHARACTER_SET_NAME | TABLE_COLLATION    | AUTO_INCREMENT | TABLE_COMMENT | CREATE_OPTIONS     | ENGINE |
++--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+--------+
+| public       | users        | utf8mb4            | utf8mb4_0900_ai_ci | nil            | Comment       | COMPRESSION="ZLIB" | nil    |
++--------------+--------------+--------------------+--------------------+----------------+---------------+--------------------+--------+
 `))
 				m.ExpectQuery(queryColumns).
 					WithArgs("public", "users").
@@ -155,7 +158,7 @@ func TestDriver_InspectTable(t *testing.T) {
 			name:    "maria/types",
 			version: "10.7.1-MariaDB",
 			before: func(m mock) {
-				m.tableExists("public", "users", true)
+				m.marTableExists("public", "users", "BASE TABLE")
 				m.ExpectQuery(queryColumns).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
@@ -200,6 +203,45 @@ func TestDriver_InspectTable(t *testing.T) {
 				}, t.Columns)
 			},
 		},
+		{
+			name:    "maria/system versioning",
+			version: "10.7.1-MariaDB",
+			before: func(m mock) {
+				m.marTableExists("public", "users", "SYSTEM VERSIONED")
+				m.ExpectQuery(queryColumns).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
++------------+--------------+--------------+----------------+-------------+------------+----------------+-----------------------+--------------------+----------------+
+| table_name |  column_name | column_type  | column_comment | is_nullable | column_key | column_default | extra                 | character_set_name | collation_name |
++------------+--------------+--------------+----------------+-------------+------------+----------------+-----------------------+--------------------+----------------+
+| users      |  id          | bigint(20)   |                | NO          | PRI        | NULL           |                       | NULL               | NULL           |
+| users      |  s           | timestamp(6) |                | NO          |            | NULL           | ROW START INVISIBLE   | NULL               | NULL           |
+| users      |  e           | timestamp(6) |                | NO          |            | NULL           | ROW END INVISIBLE     | NULL               | NULL           |
++------------+--------------+--------------+----------------+-------------+------------+----------------+-----------------------+--------------------+----------------+
+`))
+				m.ExpectQuery(queryIndexes).
+					WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "non_unique", "key_part", "expression"}))
+				m.noFKs()
+				m.ExpectQuery(queryMarChecks).
+					WithArgs("public", "users").
+					WillReturnRows(sqlmock.NewRows([]string{"table_name", "CONSTRAINT_NAME", "CHECK_CLAUSE", "ENFORCED"}))
+				m.ExpectQuery(sqltest.Escape("SHOW CREATE TABLE `public`.`users`")).
+					WillReturnRows(sqltest.Rows(`
++-------+-------------------------------------------------------------------------------+
+| Table | Create Table                                                                  |
++-------+-------------------------------------------------------------------------------+
+| users | CREATE TABLE users (id bigint NOT NULL) ENGINE=InnoDB WITH SYSTEM VERSIONING |
++-------+-------------------------------------------------------------------------------+
+`))
+			},
+			expect: func(require *require.Assertions, t *schema.Table, err error) {
+				require.NoError(err)
+				v, ok := systemVersioned(t.Attrs)
+				require.True(ok)
+				require.Equal("s", v.Start)
+				require.Equal("e", v.End)
+			},
+		},
 		{
 			name: "decimal types",
 			before: func(m mock) {
@@ -439,12 +481,14 @@ func TestDriver_InspectTable(t *testing.T) {
 `))
 				m.noIndexes()
 				m.noFKs()
+				m.ExpectQuery(sqltest.Escape("SHOW CREATE TABLE `public`.`users`")).
+					WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).AddRow("users", "CREATE TABLE `users` (\n  `c1` point NOT NULL /*!80003 SRID 4326 */,\n  `c2` multipoint NOT NULL\n) ENGINE=InnoDB"))
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
 				require.Equal("users", t.Name)
 				require.EqualValues([]*schema.Column{
-					{Name: "c1", Type: &schema.ColumnType{Raw: "point", Type: &schema.SpatialType{T: "point"}}},
+					{Name: "c1", Type: &schema.ColumnType{Raw: "point", Type: &schema.SpatialType{T: "point"}}, Attrs: []schema.Attr{&SRID{ID: 4326}}},
 					{Name: "c2", Type: &schema.ColumnType{Raw: "multipoint", Type: &schema.SpatialType{T: "multipoint"}}},
 					{Name: "c3", Type: &schema.ColumnType{Raw: "linestring", Type: &schema.SpatialType{T: "linestring"}}},
 					{Name: "c4", Type: &schema.ColumnType{Raw: "multilinestring", Type: &schema.SpatialType{T: "multilinestring"}}},
@@ -493,7 +537,7 @@ func TestDriver_InspectTable(t *testing.T) {
 +-------+---------------------------------------------------------------------------------------------------------------------------------------------+
 | Table | Create Table                                                                                                                                |
 +-------+---------------------------------------------------------------------------------------------------------------------------------------------+
-| users | CREATE TABLE users (id bigint NOT NULL AUTO_INCREMENT) ENGINE=InnoDB AUTO_INCREMENT=55834574848 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin |
+| users | CREATE TABLE users (id bigint NOT NULL AUTO_INCREMENT, KEY ` + "`lower_nick`" + ` ((lower(` + "`nickname`" + `))) USING HASH) ENGINE=InnoDB AUTO_INCREMENT=55834574848 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin |
 +-------+---------------------------------------------------------------------------------------------------------------------------------------------+
 `))
 			},
@@ -518,7 +562,7 @@ func TestDriver_InspectTable(t *testing.T) {
 				}
 				// lower(nickname)
 				indexes[1].Parts = []*schema.IndexPart{
-					{SeqNo: 1, X: &schema.RawExpr{X: "lower(nickname)"}},
+					{SeqNo: 1, X: &schema.RawExpr{X: "lower(`nickname`)"}},
 				}
 				// oid, uid
 				indexes[2].Parts = []*schema.IndexPart{
@@ -780,6 +824,56 @@ func TestDriver_InspectSchema(t *testing.T) {
 				require.EqualValues(petsFKs, pets.ForeignKeys)
 			},
 		},
+		{
+			name:   "table stats",
+			schema: "public",
+			opts:   &schema.InspectOptions{Stats: true},
+			before: func(m mock) {
+				m.version("8.0.13")
+				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= ?"))).
+					WithArgs("public").
+					WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| public      | utf8mb4                    | utf8mb4_unicode_ci     |
++-------------+----------------------------+------------------------+
+`))
+				m.tables("public", "users", "pets")
+				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "?, ?"))).
+					WithArgs("public", "users", "pets").
+					WillReturnRows(sqltest.Rows(`
++-------------+-------------+--------------+----------------+-------------+------------+----------------+----------------+--------------------+--------------------+
+| TABLE_NAME  | COLUMN_NAME | COLUMN_TYPE  | COLUMN_COMMENT | IS_NULLABLE | COLUMN_KEY | COLUMN_DEFAULT | EXTRA          | CHARACTER_SET_NAME | COLLATION_NAME     |
++-------------+-------------+--------------+----------------+-------------+------------+----------------+----------------+--------------------+--------------------+
+| users       | id          | int          |                | NO          | PRI        | NULL           |                | NULL               | NULL               |
+| pets        | id          | int          |                | NO          | PRI        | NULL           |                | NULL               | NULL               |
++-------------+-------------+--------------+----------------+-------------+------------+----------------+----------------+--------------------+--------------------+
+				`))
+				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesExprQuery, "?, ?"))).
+					WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "non_unique", "key_part", "expression"}))
+				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "?, ?"))).
+					WillReturnRows(sqlmock.NewRows([]string{"CONSTRAINT_NAME", "TABLE_NAME", "COLUMN_NAME", "TABLE_SCHEMA", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "REFERENCED_SCHEMA_NAME", "UPDATE_RULE", "DELETE_RULE"}))
+				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(statsQuery, "?, ?"))).
+					WithArgs("public", "users", "pets").
+					WillReturnRows(sqltest.Rows(`
++------------+------------+-------------+--------------+
+| TABLE_NAME | TABLE_ROWS | DATA_LENGTH | INDEX_LENGTH |
++------------+------------+-------------+--------------+
+| pets       | nil        | nil         | nil          |
+| users      | 1024       | 65536       | 16384        |
++------------+------------+-------------+--------------+
+`))
+			},
+			expect: func(require *require.Assertions, s *schema.Schema, err error) {
+				require.NoError(err)
+				var stats schema.TableStats
+				require.True(schema.FindAttr(s.Tables[0].Attrs, &stats))
+				require.Equal(schema.TableStats{Rows: 1024, DataSize: 65536, IndexSize: 16384}, stats)
+				require.True(schema.FindAttr(s.Tables[1].Attrs, &stats))
+				require.Equal(schema.TableStats{Rows: -1, DataSize: -1, IndexSize: -1}, stats)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -794,6 +888,120 @@ func TestDriver_InspectSchema(t *testing.T) {
 	}
 }
 
+func TestDriver_InspectTables(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("8.0.13")
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| public      | utf8mb4                    | utf8mb4_unicode_ci     |
++-------------+----------------------------+------------------------+
+`))
+	mk.tables("public", "users", "pets")
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "?, ?"))).
+		WithArgs("public", "users", "pets").
+		WillReturnRows(sqltest.Rows(`
++-------------+-------------+--------------+----------------+-------------+------------+----------------+----------------+--------------------+--------------------+
+| TABLE_NAME  | COLUMN_NAME | COLUMN_TYPE  | COLUMN_COMMENT | IS_NULLABLE | COLUMN_KEY | COLUMN_DEFAULT | EXTRA          | CHARACTER_SET_NAME | COLLATION_NAME     |
++-------------+-------------+--------------+----------------+-------------+------------+----------------+----------------+--------------------+--------------------+
+| users       | id          | int          |                | NO          | PRI        | NULL           |                | NULL               | NULL               |
+| pets        | id          | int          |                | NO          | PRI        | NULL           |                | NULL               | NULL               |
+| pets        | owner_id    | int          |                | YES         | NULL       | NULL           |                | NULL               | NULL               |
++-------------+-------------+--------------+----------------+-------------+------------+----------------+----------------+--------------------+--------------------+
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesExprQuery, "?, ?"))).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "non_unique", "key_part", "expression"}))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "?, ?"))).
+		WithArgs("public", "users", "pets").
+		WillReturnRows(sqltest.Rows(`
++------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------+
+| CONSTRAINT_NAME  | TABLE_NAME | COLUMN_NAME | TABLE_SCHEMA | REFERENCED_TABLE_NAME | REFERENCED_COLUMN_NAME | REFERENCED_SCHEMA_NAME | UPDATE_RULE | DELETE_RULE |
++------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------+
+| owner_id         | pets       | owner_id    | public       | users                 | id                     | public                 | NO ACTION   | CASCADE     |
++------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------+
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(statsQuery, "?, ?"))).
+		WithArgs("public", "users", "pets").
+		WillReturnRows(sqltest.Rows(`
++------------+------------+-------------+--------------+
+| TABLE_NAME | TABLE_ROWS | DATA_LENGTH | INDEX_LENGTH |
++------------+------------+-------------+--------------+
+| pets       | 10         | 16384       | 0            |
+| users      | 1024       | 65536       | 16384        |
++------------+------------+-------------+--------------+
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var tables []*schema.Table
+	err = drv.InspectTables(context.Background(), &schema.InspectRealmOption{Stats: true}, func(t *schema.Table) error {
+		tables = append(tables, t)
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Len(t, tables, 2)
+	users, pets := tables[0], tables[1]
+	require.Equal(t, "users", users.Name)
+	require.Equal(t, "pets", pets.Name)
+	s := users.Schema
+	require.Equal(t, "public", s.Name)
+	require.Empty(t, s.Tables, "streamed tables are not held by their schema")
+	require.True(t, s == pets.Schema)
+	require.Equal(t, []schema.Attr{&schema.Charset{V: "utf8mb4"}, &schema.Collation{V: "utf8mb4_unicode_ci"}}, s.Attrs)
+	require.NotNil(t, s.Realm)
+	require.Len(t, pets.ForeignKeys, 1)
+	// Tables in the same batch are linked.
+	require.True(t, users == pets.ForeignKeys[0].RefTable)
+	require.True(t, users.Columns[0] == pets.ForeignKeys[0].RefColumns[0])
+	var stats schema.TableStats
+	require.True(t, schema.FindAttr(users.Attrs, &stats))
+	require.Equal(t, schema.TableStats{Rows: 1024, DataSize: 65536, IndexSize: 16384}, stats)
+	require.True(t, schema.FindAttr(pets.Attrs, &stats))
+	require.Equal(t, schema.TableStats{Rows: 10, DataSize: 16384, IndexSize: 0}, stats)
+}
+
+func TestDriver_Options(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("5.6.35")
+	var stmts []string
+	drv, err := Open(db,
+		WithVersion("8.0.19"),
+		WithCollation("utf8mb4_bin"),
+		WithCatalogSchema("catalog"),
+		WithStatementLog(func(_ context.Context, stmt string, _ []interface{}) {
+			stmts = append(stmts, stmt)
+		}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "8.0.19", drv.version)
+	require.Equal(t, "utf8mb4_bin", drv.collate)
+	require.Equal(t, "utf8mb4", drv.charset)
+	require.Equal(t, []string{variablesQuery}, stmts)
+
+	mk.ExpectQuery(sqltest.Escape("SELECT `SCHEMA_NAME`, `DEFAULT_CHARACTER_SET_NAME`, `DEFAULT_COLLATION_NAME` from `catalog`.`SCHEMATA` WHERE `SCHEMA_NAME` = SCHEMA() ORDER BY `SCHEMA_NAME`")).
+		WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| public      | utf8mb4                    | utf8mb4_bin            |
++-------------+----------------------------+------------------------+
+`))
+	mk.ExpectQuery("FROM `catalog`.TABLES AS t1 JOIN `catalog`.COLLATIONS").
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows([]string{"schema", "table", "charset", "collate", "inc", "comment", "options"}))
+	s, err := drv.InspectSchema(context.Background(), "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "public", s.Name)
+	require.Equal(t, []schema.Attr{&schema.Charset{V: "utf8mb4"}, &schema.Collation{V: "utf8mb4_bin"}}, s.Realm.Attrs)
+	require.Len(t, stmts, 3)
+}
+
 func TestDriver_Realm(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -912,19 +1120,26 @@ func (m mock) noFKs() {
 }
 
 func (m mock) tableExists(schema, table string, exists bool) {
-	rows := sqlmock.NewRows([]string{"table_schema", "table_name", "table_collation", "character_set", "auto_increment", "table_comment", "create_options"})
+	rows := sqlmock.NewRows([]string{"table_schema", "table_name", "table_collation", "character_set", "auto_increment", "table_comment", "create_options", "engine"})
 	if exists {
-		rows.AddRow(schema, table, nil, nil, nil, nil, nil)
+		rows.AddRow(schema, table, nil, nil, nil, nil, nil, nil)
 	}
 	m.ExpectQuery(queryTable).
 		WithArgs(schema).
 		WillReturnRows(rows)
 }
 
+func (m mock) marTableExists(schema, table, ttype string) {
+	m.ExpectQuery(queryMarTable).
+		WithArgs(schema).
+		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "table_collation", "character_set", "auto_increment", "table_comment", "create_options", "engine", "table_type"}).
+			AddRow(schema, table, nil, nil, nil, nil, nil, nil, ttype))
+}
+
 func (m mock) tables(schema string, tables ...string) {
-	rows := sqlmock.NewRows([]string{"schema", "table", "charset", "collate", "inc", "comment", "options"})
+	rows := sqlmock.NewRows([]string{"schema", "table", "charset", "collate", "inc", "comment", "options", "engine"})
 	for _, t := range tables {
-		rows.AddRow(schema, t, nil, nil, nil, nil, nil)
+		rows.AddRow(schema, t, nil, nil, nil, nil, nil, nil)
 	}
 	m.ExpectQuery(queryTable).
 		WithArgs(schema).
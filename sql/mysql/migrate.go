@@ -6,20 +6,29 @@ package mysql
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/naming"
 	"ariga.io/atlas/sql/schema"
+
+	"github.com/go-sql-driver/mysql"
 )
 
 // A planApply provides migration capabilities for schema elements.
 type planApply struct{ conn }
 
 // PlanChanges returns a migration plan for the given schema changes.
-func (p *planApply) PlanChanges(_ context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+func (p *planApply) PlanChanges(ctx context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+	if p.namer != nil {
+		naming.Changes(p.namer, changes)
+	}
 	s := &state{
 		conn: p.conn,
 		Plan: migrate.Plan{
@@ -31,10 +40,19 @@ func (p *planApply) PlanChanges(_ context.Context, name string, changes []schema
 			// https://dev.mysql.com/doc/refman/8.0/en/implicit-commit.html
 			Transactional: false,
 		},
+		guards: migrate.GuardsFromContext(ctx),
 	}
 	if err := s.plan(changes); err != nil {
 		return nil, err
 	}
+	if err := s.ddlStrategy(); err != nil {
+		return nil, err
+	}
+	if migrate.ImpactFromContext(ctx) {
+		if err := s.estimate(ctx); err != nil {
+			return nil, err
+		}
+	}
 	for _, c := range s.Changes {
 		if c.Reverse == "" {
 			s.Reversible = false
@@ -51,25 +69,129 @@ func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change) e
 	return sqlx.ApplyChanges(ctx, changes, p)
 }
 
+// SingleConn reports if the driver was opened on a single database connection.
+// It is used by sqlx.ApplyChanges to check if savepoints can be used.
+func (p *planApply) SingleConn() bool {
+	return p.single
+}
+
+// MySQL error numbers that are converted to typed errors.
+// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+const (
+	errBadNull          = 1048 // ER_BAD_NULL_ERROR
+	errDupEntry         = 1062 // ER_DUP_ENTRY
+	errParse            = 1064 // ER_PARSE_ERROR
+	errInvalidUseOfNull = 1138 // ER_INVALID_USE_OF_NULL
+	errLockWaitTimeout  = 1205 // ER_LOCK_WAIT_TIMEOUT
+	errLockDeadlock     = 1213 // ER_LOCK_DEADLOCK
+	errNoReferencedRow  = 1216 // ER_NO_REFERENCED_ROW
+	errRowIsReferenced2 = 1451 // ER_ROW_IS_REFERENCED_2
+	errNoReferencedRow2 = 1452 // ER_NO_REFERENCED_ROW_2
+	errDupEntryWithKey  = 1586 // ER_DUP_ENTRY_WITH_KEY_NAME
+	errLockNoWait       = 3572 // ER_LOCK_NOWAIT
+	errCheckConstraint  = 3819 // ER_CHECK_CONSTRAINT_VIOLATED
+)
+
+var (
+	reConstraintName = regexp.MustCompile("(?:Check constraint '(.+)' is violated|for key '(.+)'|CONSTRAINT `([^`]+)` FOREIGN KEY)")
+	reParseNear      = regexp.MustCompile(`near '((?s).*)' at line \d+$`)
+)
+
+// ConvertError implements the sqlx.ErrorConverter interface, and converts
+// MySQL errors returned by executing the given statement to typed errors.
+func (p *planApply) ConvertError(stmt string, err error) error {
+	var me *mysql.MySQLError
+	if !errors.As(err, &me) {
+		return err
+	}
+	e := &schema.StmtError{Stmt: stmt, Err: err}
+	switch me.Number {
+	case errLockWaitTimeout, errLockDeadlock, errLockNoWait:
+		e.Err = &schema.LockError{Err: err}
+	case errBadNull, errInvalidUseOfNull, errDupEntry, errDupEntryWithKey, errRowIsReferenced2, errNoReferencedRow2, errNoReferencedRow, errCheckConstraint:
+		v := &schema.ConstraintViolationError{Err: err}
+		if m := reConstraintName.FindStringSubmatch(me.Message); m != nil {
+			v.Constraint = m[1] + m[2] + m[3]
+		}
+		e.Err = v
+	case errParse:
+		if m := reParseNear.FindStringSubmatch(me.Message); m != nil && m[1] != "" {
+			if i := strings.Index(stmt, m[1]); i != -1 {
+				e.Pos = i + 1
+			}
+		}
+	}
+	return e
+}
+
+// FormatStmt formats the given statement for readability. Keywords are uppercased,
+// the definitions of CREATE TABLE statements are written one per line, and ALTER TABLE
+// statements with multiple clauses are written one clause per line. For example:
+//
+//	FormatStmt("CREATE TABLE `t` (`id` int NOT NULL, PRIMARY KEY (`id`))")
+//
+func FormatStmt(stmt string) string {
+	return formatter.Format(stmt)
+}
+
+// SplitStmts splits the given MySQL script into statements.
+func SplitStmts(script string) ([]*migrate.Stmt, error) {
+	stmts, err := splitter.Split(script)
+	if err != nil {
+		return nil, fmt.Errorf("mysql: %w", err)
+	}
+	return stmts, nil
+}
+
+// SplitStmts implements the migrate.StmtSplitter interface.
+func (*Driver) SplitStmts(script string) ([]*migrate.Stmt, error) {
+	return SplitStmts(script)
+}
+
+var (
+	lexer     = sqlx.Lexer{Backslash: true, HashComments: true, DashSpace: true}
+	formatter = &sqlx.Formatter{Lexer: lexer}
+	splitter  = &sqlx.Splitter{Lexer: lexer, Delimiter: true}
+)
+
 // state represents the state of a planning. It is not part of
 // planApply so that multiple planning/applying can be called
 // in parallel.
 type state struct {
 	conn
 	migrate.Plan
+	// guards indicates if the planned statements are guarded
+	// with existence checks. See migrate.WithGuards.
+	guards bool
 }
 
 // plan builds the migration plan for applying the
 // given changes on the attached connection.
 func (s *state) plan(changes []schema.Change) error {
+	if s.vitess != nil {
+		var err error
+		if changes, err = s.vitessChanges(changes); err != nil {
+			return err
+		}
+	}
+	pre, changes, post := splitAccounts(changes)
+	for _, c := range pre {
+		if err := s.account(c); err != nil {
+			return err
+		}
+	}
 	planned, err := s.topLevel(changes)
 	if err != nil {
 		return err
 	}
+	if s.coalesce {
+		planned = sqlx.CoalesceChanges(planned)
+	}
 	planned, err = sqlx.DetachCycles(planned)
 	if err != nil {
 		return err
 	}
+	planned = sqlx.DetachAlteredReferences(planned)
 	for _, c := range planned {
 		switch c := c.(type) {
 		case *schema.AddTable:
@@ -79,6 +201,11 @@ func (s *state) plan(changes []schema.Change) error {
 		case *schema.DropTable:
 			s.dropTable(c)
 		case *schema.ModifyTable:
+			// MySQL does not support existence checks in ALTER TABLE
+			// statements (e.g. ADD COLUMN IF NOT EXISTS).
+			if s.guards {
+				return fmt.Errorf("existence guards are not supported for modifying table %q", c.T.Name)
+			}
 			if err := s.modifyTable(c); err != nil {
 				return err
 			}
@@ -86,6 +213,11 @@ func (s *state) plan(changes []schema.Change) error {
 			return fmt.Errorf("unsupported change %T", c)
 		}
 	}
+	for _, c := range post {
+		if err := s.account(c); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -95,10 +227,11 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 	for _, c := range changes {
 		switch c := c.(type) {
 		case *schema.AddSchema:
-			b := Build("CREATE DATABASE").Ident(c.S.Name)
-			if sqlx.Has(c.Extra, &schema.IfNotExists{}) {
+			b := s.build("CREATE DATABASE")
+			if s.guards || sqlx.Has(c.Extra, &schema.IfNotExists{}) {
 				b.P("IF NOT EXISTS")
 			}
+			b.Ident(c.S.Name)
 			// Schema was created with CHARSET and it is not the default database character set.
 			if a := (schema.Charset{}); sqlx.Has(c.S.Attrs, &a) && a.V != "" && a.V != s.charset {
 				b.P("CHARSET", a.V)
@@ -110,14 +243,15 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 			s.append(&migrate.Change{
 				Cmd:     b.String(),
 				Source:  c,
-				Reverse: Build("DROP DATABASE").Ident(c.S.Name).String(),
+				Reverse: s.build("DROP DATABASE").Ident(c.S.Name).String(),
 				Comment: fmt.Sprintf("add new schema named %q", c.S.Name),
 			})
 		case *schema.DropSchema:
-			b := Build("DROP DATABASE").Ident(c.S.Name)
-			if sqlx.Has(c.Extra, &schema.IfExists{}) {
+			b := s.build("DROP DATABASE")
+			if s.guards || sqlx.Has(c.Extra, &schema.IfExists{}) {
 				b.P("IF EXISTS")
 			}
+			b.Ident(c.S.Name)
 			s.append(&migrate.Change{
 				Cmd:     b.String(),
 				Source:  c,
@@ -137,7 +271,7 @@ func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 // modifySchema builds and appends the migrate.Changes for bringing
 // the schema into its modified state.
 func (s *state) modifySchema(modify *schema.ModifySchema) error {
-	b, r := Build(""), Build("")
+	b, r := s.build(""), s.build("")
 	for _, change := range modify.Changes {
 		switch change := change.(type) {
 		// Add schema attributes to an existing schema only if
@@ -181,7 +315,7 @@ func (s *state) modifySchema(modify *schema.ModifySchema) error {
 		}
 	}
 	if b.Len() > 0 {
-		bs := Build("ALTER DATABASE").Ident(modify.S.Name)
+		bs := s.build("ALTER DATABASE").Ident(modify.S.Name)
 		rs := bs.Clone()
 		bs.WriteString(b.String())
 		rs.WriteString(r.String())
@@ -200,11 +334,12 @@ func (s *state) modifySchema(modify *schema.ModifySchema) error {
 func (s *state) addTable(add *schema.AddTable) error {
 	var (
 		errors []string
-		b      = Build("CREATE TABLE").Table(add.T)
+		b      = s.build("CREATE TABLE")
 	)
-	if sqlx.Has(add.Extra, &schema.IfNotExists{}) {
+	if s.guards || sqlx.Has(add.Extra, &schema.IfNotExists{}) {
 		b.P("IF NOT EXISTS")
 	}
+	b.Table(add.T)
 	b.Wrap(func(b *sqlx.Builder) {
 		b.MapComma(add.T.Columns, func(i int, b *sqlx.Builder) {
 			if err := s.column(b, add.T, add.T.Columns[i]); err != nil {
@@ -240,6 +375,10 @@ func (s *state) addTable(add *schema.AddTable) error {
 				s.check(b, c)
 			}
 		}
+		if v, ok := systemVersioned(add.T.Attrs); ok && v.Start != "" {
+			b.Comma()
+			period(b, v)
+		}
 	})
 	if len(errors) > 0 {
 		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errors, ", "))
@@ -248,7 +387,7 @@ func (s *state) addTable(add *schema.AddTable) error {
 	s.append(&migrate.Change{
 		Cmd:     b.String(),
 		Source:  add,
-		Reverse: Build("DROP TABLE").Table(add.T).String(),
+		Reverse: s.build("DROP TABLE").Table(add.T).String(),
 		Comment: fmt.Sprintf("create %q table", add.T.Name),
 	})
 	return nil
@@ -257,10 +396,11 @@ func (s *state) addTable(add *schema.AddTable) error {
 // dropTable builds and appends the migrate.Change
 // for dropping a table from a schema.
 func (s *state) dropTable(drop *schema.DropTable) {
-	b := Build("DROP TABLE").Table(drop.T)
-	if sqlx.Has(drop.Extra, &schema.IfExists{}) {
+	b := s.build("DROP TABLE")
+	if s.guards || sqlx.Has(drop.Extra, &schema.IfExists{}) {
 		b.P("IF EXISTS")
 	}
+	b.Table(drop.T)
 	s.append(&migrate.Change{
 		Cmd:     b.String(),
 		Source:  drop,
@@ -271,14 +411,20 @@ func (s *state) dropTable(drop *schema.DropTable) {
 // modifyTable builds and appends the migrate.Changes for bringing
 // the table into its modified state.
 func (s *state) modifyTable(modify *schema.ModifyTable) error {
-	var changes [2][]schema.Change
-	for _, change := range skipAutoChanges(modify.Changes) {
+	// The changes are grouped into foreign-key drops, other drops
+	// and the rest of the changes, in order to execute the drops first.
+	var changes [3][]schema.Change
+	planned := planConvert(modify.T, skipAutoChanges(modify.Changes))
+	if err := s.checkKeyLen(modify.T, planned); err != nil {
+		return err
+	}
+	for _, change := range planned {
 		switch change := change.(type) {
 		// Constraints should be dropped before dropping columns, because if a column
 		// is a part of multi-column constraints (like, unique index), ALTER TABLE
 		// might fail if the intermediate state violates the constraints.
 		case *schema.DropIndex:
-			changes[0] = append(changes[0], change)
+			changes[1] = append(changes[1], change)
 		case *schema.ModifyForeignKey:
 			// Foreign-key modification is translated into 2 steps.
 			// Dropping the current foreign key and creating a new one.
@@ -294,26 +440,33 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 					},
 				})
 			}
-			changes[1] = append(changes[1], &schema.AddForeignKey{
+			changes[2] = append(changes[2], &schema.AddForeignKey{
 				F: change.To,
 			})
 		// Index modification requires rebuilding the index.
 		case *schema.ModifyIndex:
-			changes[0] = append(changes[0], &schema.DropIndex{
+			changes[1] = append(changes[1], &schema.DropIndex{
 				I: change.From,
 			})
-			changes[1] = append(changes[1], &schema.AddIndex{
+			changes[2] = append(changes[2], &schema.AddIndex{
 				I: change.To,
 			})
 		case *schema.DropAttr:
-			return fmt.Errorf("unsupported change type: %v", change.A)
+			if _, ok := change.A.(*SystemVersioned); !ok {
+				return fmt.Errorf("unsupported change type: %v", change.A)
+			}
+			changes[2] = append(changes[2], change)
 		default:
-			changes[1] = append(changes[1], change)
+			changes[2] = append(changes[2], change)
 		}
 	}
-	for i := range changes {
-		if len(changes[i]) > 0 {
-			if err := s.alterTable(modify.T, changes[i]); err != nil {
+	stmts := [][]schema.Change{append(changes[0], changes[1]...), changes[2]}
+	if s.coalesce {
+		stmts = [][]schema.Change{changes[0], append(changes[1], changes[2]...)}
+	}
+	for i := range stmts {
+		if len(stmts[i]) > 0 {
+			if err := s.alterTable(modify.T, stmts[i]); err != nil {
 				return err
 			}
 		}
@@ -326,9 +479,10 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 	var (
 		errors     []string
-		b          = Build("ALTER TABLE").Table(t)
-		reverse    = Build("")
+		b          = s.build("ALTER TABLE").Table(t)
+		reverse    = s.build("")
 		reversible = true
+		positioned = s.ordered && s.orderColumns(t, changes)
 	)
 	b.MapComma(changes, func(i int, b *sqlx.Builder) {
 		switch change := changes[i].(type) {
@@ -337,12 +491,20 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 			if err := s.column(b, t, change.C); err != nil {
 				errors = append(errors, err.Error())
 			}
+			if positioned {
+				columnPosition(b, t, change.C)
+			}
 			reverse.Comma().P("DROP COLUMN").Ident(change.C.Name)
 		case *schema.ModifyColumn:
 			b.P("MODIFY COLUMN")
 			if err := s.column(b, t, change.To); err != nil {
 				errors = append(errors, err.Error())
 			}
+			if change.Change.Is(schema.ChangePosition) {
+				columnPosition(b, t, change.To)
+				// Restoring the original order requires the position of all moved columns.
+				reversible = false
+			}
 			reverse.Comma().P("MODIFY COLUMN")
 			if err := s.column(reverse, t, change.From); err != nil {
 				errors = append(errors, err.Error())
@@ -350,6 +512,9 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 		case *schema.DropColumn:
 			b.P("DROP COLUMN").Ident(change.C.Name)
 			reversible = false
+		case *schema.RenameColumn:
+			b.P("RENAME COLUMN").Ident(change.From.Name).P("TO").Ident(change.To.Name)
+			reverse.Comma().P("RENAME COLUMN").Ident(change.To.Name).P("TO").Ident(change.From.Name)
 		case *schema.AddIndex:
 			b.P("ADD")
 			if change.I.Unique {
@@ -385,9 +550,20 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 			s.tableAttr(b, change, change.A)
 			// Unsupported reverse operation.
 			reversible = false
+		case *schema.DropAttr:
+			// Dropping the system versioning of a table drops its history.
+			b.P("DROP SYSTEM VERSIONING")
+			reversible = false
 		case *schema.ModifyAttr:
 			s.tableAttr(b, change, change.To)
 			s.tableAttr(reverse.Comma(), change, change.From)
+		case *convertTo:
+			b.P("CONVERT TO CHARACTER SET", change.To.(*schema.Charset).V)
+			reverse.Comma().P("CONVERT TO CHARACTER SET", change.From.(*schema.Charset).V)
+			if c := change.collate; c != nil {
+				b.P("COLLATE", c.To.(*schema.Collation).V)
+				reverse.P("COLLATE", c.From.(*schema.Collation).V)
+			}
 		case *schema.AddCheck:
 			s.check(b.P("ADD"), change.C)
 			// Reverse operation is supported if
@@ -435,7 +611,7 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 		Comment: fmt.Sprintf("modify %q table", t.Name),
 	}
 	if reversible {
-		b := Build("ALTER TABLE").Table(t)
+		b := s.build("ALTER TABLE").Table(t)
 		if _, err := b.ReadFrom(reverse); err != nil {
 			return fmt.Errorf("unexpected buffer read: %w", err)
 		}
@@ -445,12 +621,90 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 	return nil
 }
 
+// orderColumns sorts the added and modified columns by their position in the table,
+// keeping the slots of the other changes, and reports if the added columns must be
+// positioned explicitly, because they are not appended to the end of the table.
+func (s *state) orderColumns(t *schema.Table, changes []schema.Change) bool {
+	var (
+		slots   []int
+		columns []schema.Change
+		added   = make(map[string]bool)
+	)
+	for i, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddColumn:
+			added[c.C.Name] = true
+			slots, columns = append(slots, i), append(columns, c)
+		case *schema.ModifyColumn:
+			slots, columns = append(slots, i), append(columns, c)
+		}
+	}
+	sort.SliceStable(columns, func(i, j int) bool {
+		return columnIndex(t, columns[i]) < columnIndex(t, columns[j])
+	})
+	for i, c := range columns {
+		changes[slots[i]] = c
+	}
+	if len(added) == 0 {
+		return false
+	}
+	// Added columns are positioned explicitly only
+	// if an existing column is positioned after them.
+	for i := len(t.Columns) - 1; i >= 0; i-- {
+		if added[t.Columns[i].Name] {
+			continue
+		}
+		for _, c := range t.Columns[:i] {
+			if added[c.Name] {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// columnIndex returns the position of the column of the given change in the table.
+func columnIndex(t *schema.Table, c schema.Change) int {
+	var name string
+	switch c := c.(type) {
+	case *schema.AddColumn:
+		name = c.C.Name
+	case *schema.ModifyColumn:
+		name = c.To.Name
+	}
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return i
+		}
+	}
+	return len(t.Columns)
+}
+
+// columnPosition writes the FIRST or AFTER clause of the column, according to its position in the table.
+func columnPosition(b *sqlx.Builder, t *schema.Table, c *schema.Column) {
+	switch i := columnIndex(t, &schema.AddColumn{C: c}); {
+	case i == 0:
+		b.P("FIRST")
+	case i < len(t.Columns):
+		b.P("AFTER").Ident(t.Columns[i-1].Name)
+	}
+}
+
 func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error {
 	typ, err := FormatType(c.Type.Type)
 	if err != nil {
 		return fmt.Errorf("format type for column %q: %w", c.Name, err)
 	}
 	b.Ident(c.Name).P(typ)
+	if v, ok := systemVersioned(t.Attrs); ok && v.Start != "" {
+		switch c.Name {
+		case v.Start:
+			b.P("GENERATED ALWAYS AS ROW START")
+		case v.End:
+			b.P("GENERATED ALWAYS AS ROW END")
+		}
+	}
 	if !c.Type.Null {
 		b.P("NOT")
 	}
@@ -460,7 +714,7 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 	// versions < 10.4.3. See Driver.checks for full info.
 	if _, ok := c.Type.Type.(*schema.JSONType); ok && s.mariadb() && s.ltV("10.4.3") && !sqlx.Has(c.Attrs, &schema.Check{}) {
 		b.P("CHECK").Wrap(func(b *sqlx.Builder) {
-			b.WriteString(fmt.Sprintf("json_valid(`%s`)", c.Name))
+			b.WriteString(fmt.Sprintf("json_valid(%s)", s.build("").Ident(c.Name)))
 		})
 	}
 	for _, a := range c.Attrs {
@@ -485,6 +739,8 @@ func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) error
 			}
 		case *OnUpdate:
 			b.P("ON UPDATE", a.A)
+		case *SRID:
+			b.P("SRID", strconv.Itoa(a.ID))
 		case *AutoIncrement:
 			b.P("AUTO_INCREMENT")
 			// Auto increment with value should be configured on table options.
@@ -504,8 +760,9 @@ func (s *state) indexParts(b *sqlx.Builder, parts []*schema.IndexPart) {
 			switch part := parts[i]; {
 			case part.C != nil:
 				b.Ident(part.C.Name)
+			// Functional key parts must be wrapped with parentheses.
 			case part.X != nil:
-				b.WriteString(part.X.(*schema.RawExpr).X)
+				b.WriteString("(" + sqlx.TrimParens(part.X.(*schema.RawExpr).X) + ")")
 			}
 			if s := (&SubPart{}); sqlx.Has(parts[i].Attrs, s) {
 				b.WriteString(fmt.Sprintf("(%d)", s.Len))
@@ -521,6 +778,15 @@ func (s *state) indexParts(b *sqlx.Builder, parts []*schema.IndexPart) {
 func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) error {
 	return b.MapCommaErr(fks, func(i int, b *sqlx.Builder) error {
 		fk := fks[i]
+		// The MATCH clause and the SET DEFAULT action are parsed by MySQL, but
+		// InnoDB ignores the former (along with the ON DELETE and ON UPDATE
+		// clauses that follow it), and rejects the latter.
+		if fk.Match == schema.MatchFull || fk.Match == schema.MatchPartial {
+			return fmt.Errorf("foreign key constraint %q: MATCH %s is not supported by MySQL", fk.Symbol, fk.Match)
+		}
+		if fk.OnUpdate == schema.SetDefault || fk.OnDelete == schema.SetDefault {
+			return fmt.Errorf("foreign key constraint %q: SET DEFAULT action is not supported by MySQL", fk.Symbol)
+		}
 		if fk.Symbol != "" {
 			b.P("CONSTRAINT").Ident(fk.Symbol)
 		}
@@ -545,7 +811,7 @@ func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) error {
 		if fk.OnUpdate == schema.SetNull || fk.OnDelete == schema.SetNull {
 			for _, c := range fk.Columns {
 				if !c.Type.Null {
-					return fmt.Errorf("foreign key constraint was %[1]q SET NULL, but column %[1]q is NOT NULL", c.Name)
+					return fmt.Errorf("foreign key constraint was %q SET NULL, but column %q is NOT NULL", fk.Symbol, c.Name)
 				}
 			}
 		}
@@ -560,6 +826,10 @@ func (s *state) tableAttr(b *sqlx.Builder, c schema.Change, attrs ...schema.Attr
 		switch a := a.(type) {
 		case *CreateOptions:
 			b.P(a.V)
+		case *Engine:
+			b.P("ENGINE", a.V)
+		case *RowFormat:
+			b.P("ROW_FORMAT", a.V)
 		case *AutoIncrement:
 			// Update the AUTO_INCREMENT if it is an update change or it is not the default.
 			if _, ok := c.(*schema.ModifyAttr); ok || a.V > 1 {
@@ -574,10 +844,28 @@ func (s *state) tableAttr(b *sqlx.Builder, c schema.Change, attrs ...schema.Attr
 			b.P("COLLATE", a.V)
 		case *schema.Comment:
 			b.P("COMMENT", quote(a.Text))
+		case *SystemVersioned:
+			if _, ok := c.(*schema.AddTable); ok {
+				b.P("WITH SYSTEM VERSIONING")
+				break
+			}
+			// Period columns are added with the versioning.
+			if a.Start != "" {
+				period(b.P("ADD"), a)
+				b.Comma()
+			}
+			b.P("ADD SYSTEM VERSIONING")
 		}
 	}
 }
 
+// period writes the system-time period of the given system-versioned table.
+func period(b *sqlx.Builder, v *SystemVersioned) {
+	b.P("PERIOD FOR SYSTEM_TIME").Wrap(func(b *sqlx.Builder) {
+		b.Ident(v.Start).Comma().Ident(v.End)
+	})
+}
+
 // character returns the table character-set from its attributes
 // or from the default defined in the schema or the database.
 func (s *state) character(t *schema.Table) string {
@@ -643,6 +931,23 @@ func Build(phrase string) *sqlx.Builder {
 	return b.P(phrase)
 }
 
+// build is like Build, but identifiers are quoted only
+// when it is needed, if the driver was configured so.
+func (s *state) build(phrase string) *sqlx.Builder {
+	b := Build(phrase)
+	if s.minQuote {
+		b.MustQuote = mustQuote
+	}
+	return b
+}
+
+// mustQuote reports if the given identifier must be quoted. Unquoted
+// identifiers may also contain the '$' character and start with a digit,
+// but such identifiers are quoted to avoid confusion with other tokens.
+func mustQuote(s string) bool {
+	return sqlx.ReservedWord(s) || !sqlx.SimpleIdent(s, nil)
+}
+
 // skipAutoChanges filters unnecessary changes that are automatically
 // happened by the database when ALTER TABLE is executed.
 func skipAutoChanges(changes []schema.Change) []schema.Change {
@@ -673,6 +978,143 @@ func skipAutoChanges(changes []schema.Change) []schema.Change {
 	return planned
 }
 
+// convertTo describes a change of the table character set that
+// converts all its columns using the CONVERT TO CHARACTER SET clause.
+type convertTo struct {
+	*schema.ModifyAttr // Charset change.
+	// Collation change, if the collation
+	// was changed together with the charset.
+	collate *schema.ModifyAttr
+}
+
+// planConvert replaces the table-level CHARSET modification with a conversion
+// of the table and all its columns, in case none of the columns in the desired
+// state pin a different character set. Otherwise, only the table default is
+// changed (i.e. "ALTER TABLE t CHARSET x"), and columns that were changed are
+// modified explicitly.
+func planConvert(t *schema.Table, changes []schema.Change) []schema.Change {
+	charset, collate := -1, -1
+	for i, c := range changes {
+		m, ok := c.(*schema.ModifyAttr)
+		if !ok {
+			continue
+		}
+		switch m.To.(type) {
+		case *schema.Charset:
+			if _, ok := m.From.(*schema.Charset); ok {
+				charset = i
+			}
+		case *schema.Collation:
+			if _, ok := m.From.(*schema.Collation); ok {
+				collate = i
+			}
+		}
+	}
+	if charset == -1 {
+		return changes
+	}
+	convert := &convertTo{ModifyAttr: changes[charset].(*schema.ModifyAttr)}
+	for _, c := range t.Columns {
+		if a := (schema.Charset{}); c.Type != nil && supportsCharset(c.Type.Type) && sqlx.Has(c.Attrs, &a) && a.V != convert.To.(*schema.Charset).V {
+			return changes
+		}
+	}
+	planned := make([]schema.Change, 0, len(changes))
+	for i, c := range changes {
+		switch i {
+		case charset:
+			planned = append(planned, convert)
+		case collate:
+			convert.collate = c.(*schema.ModifyAttr)
+		default:
+			planned = append(planned, c)
+		}
+	}
+	return planned
+}
+
+// charsetMaxLen holds the maximum number of bytes
+// per character of the common character sets.
+var charsetMaxLen = map[string]int{
+	"ascii":   1,
+	"big5":    2,
+	"binary":  1,
+	"gb18030": 4,
+	"gbk":     2,
+	"latin1":  1,
+	"latin2":  1,
+	"sjis":    2,
+	"ucs2":    2,
+	"utf16":   4,
+	"utf16le": 4,
+	"utf32":   4,
+	"utf8":    3,
+	"utf8mb3": 3,
+	"utf8mb4": 4,
+}
+
+// checkKeyLen checks that the indexes of the table do not exceed the
+// maximum key length after their columns are converted to a different
+// character set. For example, converting a "VARCHAR(255)" column that
+// is a part of an index from utf8 to utf8mb4 in MySQL 5.6.
+func (s *state) checkKeyLen(t *schema.Table, changes []schema.Change) error {
+	converted := make(map[string]bool)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *convertTo:
+			for _, c := range t.Columns {
+				converted[c.Name] = true
+			}
+		case *schema.ModifyColumn:
+			if c.Change.Is(schema.ChangeCharset) {
+				converted[c.To.Name] = true
+			}
+		}
+	}
+	if len(converted) == 0 {
+		return nil
+	}
+	indexes := t.Indexes
+	if t.PrimaryKey != nil {
+		indexes = append([]*schema.Index{t.PrimaryKey}, indexes...)
+	}
+	for _, idx := range indexes {
+		var total int
+		check := false
+		for _, p := range idx.Parts {
+			if p.C == nil || p.C.Type == nil {
+				continue
+			}
+			st, ok := p.C.Type.Type.(*schema.StringType)
+			if !ok {
+				continue
+			}
+			n := st.Size
+			if sub := (&SubPart{}); sqlx.Has(p.Attrs, sub) {
+				n = sub.Len
+			}
+			charset := s.character(t)
+			if a := (schema.Charset{}); sqlx.Has(p.C.Attrs, &a) {
+				charset = a.V
+			}
+			l, ok := charsetMaxLen[charset]
+			if !ok || n == 0 {
+				continue
+			}
+			if n*l > s.maxKeyLen() && converted[p.C.Name] {
+				return fmt.Errorf("alter table %q: key part %q of index %q exceeds the maximum key length of %d bytes (%d bytes after converting to %s)", t.Name, p.C.Name, idx.Name, s.maxKeyLen(), n*l, charset)
+			}
+			total += n * l
+			check = check || converted[p.C.Name]
+		}
+		// The total length of InnoDB index keys is limited to 3072 bytes.
+		if check && total > 3072 {
+			return fmt.Errorf("alter table %q: index %q exceeds the maximum key length of 3072 bytes (%d bytes after conversion)", t.Name, idx.Name, total)
+		}
+	}
+	return nil
+}
+
 // checks writes the CHECK constraint to the builder.
 func (s *state) check(b *sqlx.Builder, c *schema.Check) {
 	expr := c.Expr
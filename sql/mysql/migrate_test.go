@@ -6,13 +6,16 @@ package mysql
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"ariga.io/atlas/sql/internal/sqltest"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/naming"
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	mysqldrv "github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,9 +28,9 @@ func TestMigrate_ApplyChanges(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mk.ExpectExec(sqltest.Escape("DROP TABLE `users`")).
 		WillReturnResult(sqlmock.NewResult(0, 0))
-	mk.ExpectExec(sqltest.Escape("DROP TABLE `public`.`pets` IF EXISTS")).
+	mk.ExpectExec(sqltest.Escape("DROP TABLE IF EXISTS `public`.`pets`")).
 		WillReturnResult(sqlmock.NewResult(0, 0))
-	mk.ExpectExec(sqltest.Escape("CREATE TABLE `pets` IF NOT EXISTS (`a` int NOT NULL DEFAULT (int(rand())), `b` bigint NOT NULL DEFAULT 1, `c` bigint NULL, PRIMARY KEY (`a`, `b`), UNIQUE INDEX `b_c_unique` (`b`, `c`) COMMENT \"comment\")")).
+	mk.ExpectExec(sqltest.Escape("CREATE TABLE IF NOT EXISTS `pets` (`a` int NOT NULL DEFAULT (int(rand())), `b` bigint NOT NULL DEFAULT 1, `c` bigint NULL, PRIMARY KEY (`a`, `b`), UNIQUE INDEX `b_c_unique` (`b`, `c`) COMMENT \"comment\")")).
 		WillReturnResult(sqlmock.NewResult(0, 0))
 	mk.ExpectExec(sqltest.Escape("ALTER TABLE `users` DROP INDEX `id_spouse_id`")).
 		WillReturnResult(sqlmock.NewResult(0, 0))
@@ -272,6 +275,34 @@ func TestPlanChanges(t *testing.T) {
 				Changes:    []*migrate.Change{{Cmd: "CREATE DATABASE `test` CHARSET latin", Reverse: "DROP DATABASE `test`"}},
 			},
 		},
+		{
+			input: []schema.Change{
+				&schema.AddSchema{S: schema.New("test").SetCharset("latin1").SetCollation("latin1_swedish_ci"), Extra: []schema.Clause{&schema.IfNotExists{}}},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes:    []*migrate.Change{{Cmd: "CREATE DATABASE IF NOT EXISTS `test` CHARSET latin1 COLLATE latin1_swedish_ci", Reverse: "DROP DATABASE `test`"}},
+			},
+		},
+		// Tables with the same name in different databases.
+		{
+			input: func() []schema.Change {
+				var (
+					app  = schema.NewTable("users").SetSchema(schema.New("app")).AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("auth_id", "int"))
+					auth = schema.NewTable("users").SetSchema(schema.New("auth")).AddColumns(schema.NewIntColumn("id", "int"))
+					fk   = schema.NewForeignKey("auth_fk").AddColumns(app.Columns[1]).SetRefTable(auth).AddRefColumns(auth.Columns[0])
+				)
+				app.AddForeignKeys(fk)
+				return []schema.Change{&schema.AddTable{T: app}, &schema.AddTable{T: auth}}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "CREATE TABLE `auth`.`users` (`id` int NOT NULL)", Reverse: "DROP TABLE `auth`.`users`"},
+					{Cmd: "CREATE TABLE `app`.`users` (`id` int NOT NULL, `auth_id` int NOT NULL, CONSTRAINT `auth_fk` FOREIGN KEY (`auth_id`) REFERENCES `auth`.`users` (`id`))", Reverse: "DROP TABLE `app`.`users`"},
+				},
+			},
+		},
 		// Default database charset can be omitted.
 		{
 			input: []schema.Change{
@@ -469,6 +500,32 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			input: []schema.Change{
+				&schema.ModifyTable{
+					T: &schema.Table{Name: "users"},
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &Engine{V: "InnoDB"},
+							To:   &Engine{V: "MyISAM"},
+						},
+						&schema.ModifyAttr{
+							From: &RowFormat{V: "DYNAMIC"},
+							To:   &RowFormat{V: "COMPRESSED"},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `users` ENGINE MyISAM, ROW_FORMAT COMPRESSED",
+						Reverse: "ALTER TABLE `users` ENGINE InnoDB, ROW_FORMAT DYNAMIC",
+					},
+				},
+			},
+		},
 		{
 			input: []schema.Change{
 				func() schema.Change {
@@ -576,6 +633,75 @@ func TestPlanChanges(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			input: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").AddColumns(schema.NewIntColumn("nick", "int")),
+					Changes: []schema.Change{
+						&schema.RenameColumn{From: schema.NewIntColumn("alias", "int"), To: schema.NewIntColumn("nick", "int")},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `users` RENAME COLUMN `alias` TO `nick`",
+						Reverse: "ALTER TABLE `users` RENAME COLUMN `nick` TO `alias`",
+					},
+				},
+			},
+		},
+		{
+			input: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").AddColumns(schema.NewStringColumn("name", "varchar(255)")),
+					Changes: []schema.Change{
+						&schema.AddIndex{I: schema.NewIndex("idx").AddParts(
+							&schema.IndexPart{X: &schema.RawExpr{X: "lower(`name`)"}, Desc: true},
+							&schema.IndexPart{X: &schema.RawExpr{X: "(upper(`name`))"}},
+						)},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `users` ADD INDEX `idx` ((lower(`name`)) DESC, (upper(`name`)))",
+						Reverse: "ALTER TABLE `users` DROP INDEX `idx`",
+					},
+				},
+			},
+		},
+		{
+			input: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("places"),
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   &schema.Column{Name: "location", Type: &schema.ColumnType{Type: &schema.SpatialType{T: "point"}}},
+							To:     &schema.Column{Name: "location", Type: &schema.ColumnType{Type: &schema.SpatialType{T: "point"}}, Attrs: []schema.Attr{&SRID{ID: 4326}}},
+							Change: schema.ChangeAttr,
+						},
+						&schema.ModifyColumn{
+							From:   &schema.Column{Name: "updated_at", Type: &schema.ColumnType{Type: &schema.TimeType{T: "timestamp"}}, Default: &schema.RawExpr{X: "CURRENT_TIMESTAMP"}},
+							To:     &schema.Column{Name: "updated_at", Type: &schema.ColumnType{Type: &schema.TimeType{T: "timestamp"}}, Default: &schema.RawExpr{X: "CURRENT_TIMESTAMP"}, Attrs: []schema.Attr{&OnUpdate{A: "CURRENT_TIMESTAMP"}}},
+							Change: schema.ChangeAttr,
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `places` MODIFY COLUMN `location` point NOT NULL SRID 4326, MODIFY COLUMN `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP",
+						Reverse: "ALTER TABLE `places` MODIFY COLUMN `location` point NOT NULL, MODIFY COLUMN `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP",
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		db, _, err := newMigrate("8.0.16")
@@ -610,3 +736,518 @@ func newMigrate(version string) (migrate.PlanApplier, *mock, error) {
 	}
 	return drv, mk, nil
 }
+
+func TestPlanChanges_CoalesceAlter(t *testing.T) {
+	var (
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("name", "int"))
+		pets  = schema.NewTable("pets").AddColumns(schema.NewIntColumn("owner_id", "int"))
+		from  = schema.NewForeignKey("owner").SetTable(pets).AddColumns(pets.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])
+		to    = schema.NewForeignKey("owner").SetTable(pets).AddColumns(pets.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]).SetOnDelete(schema.Cascade)
+		idx1  = schema.NewIndex("name").AddColumns(users.Columns[1])
+		idx2  = schema.NewUniqueIndex("name").AddColumns(users.Columns[1])
+	)
+	changes := []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.ModifyIndex{From: idx1, To: idx2, Change: schema.ChangeUnique}}},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.ModifyForeignKey{From: from, To: to, Change: schema.ChangeDeleteAction}}},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: schema.NewIntColumn("age", "int")}}},
+	}
+	for b, cmds := range map[bool][]string{
+		false: {
+			"ALTER TABLE `users` DROP INDEX `name`",
+			"ALTER TABLE `users` ADD UNIQUE INDEX `name` (`name`)",
+			"ALTER TABLE `users` ADD COLUMN `age` int NOT NULL",
+			"ALTER TABLE `pets` DROP FOREIGN KEY `owner`",
+			"ALTER TABLE `pets` ADD CONSTRAINT `owner` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`) ON DELETE CASCADE",
+		},
+		true: {
+			"ALTER TABLE `users` DROP INDEX `name`, ADD UNIQUE INDEX `name` (`name`), ADD COLUMN `age` int NOT NULL",
+			"ALTER TABLE `pets` DROP FOREIGN KEY `owner`",
+			"ALTER TABLE `pets` ADD CONSTRAINT `owner` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`) ON DELETE CASCADE",
+		},
+	} {
+		db, mk, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{mk}.version("8.0.19")
+		drv, err := Open(db, WithCoalesceAlter(b))
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, len(cmds))
+		for i, c := range plan.Changes {
+			require.Equal(t, cmds[i], c.Cmd)
+		}
+	}
+}
+
+func TestPlanChanges_ColumnOrder(t *testing.T) {
+	users := schema.NewTable("users").
+		SetSchema(schema.New("test")).
+		AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewIntColumn("age", "int"),
+			schema.NewIntColumn("name", "int"),
+			schema.NewIntColumn("rank", "int"),
+		)
+	tests := []struct {
+		changes []schema.Change
+		want    *migrate.Plan
+	}{
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{T: users, Changes: []schema.Change{
+					&schema.ModifyColumn{From: users.Columns[3], To: users.Columns[3], Change: schema.ChangePosition},
+					&schema.ModifyColumn{From: users.Columns[1], To: users.Columns[1], Change: schema.ChangePosition},
+				}},
+			},
+			want: &migrate.Plan{
+				Transactional: false,
+				Changes: []*migrate.Change{
+					{Cmd: "ALTER TABLE `test`.`users` MODIFY COLUMN `age` int NOT NULL AFTER `id`, MODIFY COLUMN `rank` int NOT NULL AFTER `name`"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{T: users, Changes: []schema.Change{
+					&schema.ModifyColumn{From: users.Columns[0], To: users.Columns[0], Change: schema.ChangePosition},
+				}},
+			},
+			want: &migrate.Plan{
+				Transactional: false,
+				Changes: []*migrate.Change{
+					{Cmd: "ALTER TABLE `test`.`users` MODIFY COLUMN `id` int NOT NULL FIRST"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{T: users, Changes: []schema.Change{
+					&schema.AddColumn{C: users.Columns[2]},
+					&schema.AddColumn{C: users.Columns[1]},
+				}},
+			},
+			want: &migrate.Plan{
+				Reversible:    true,
+				Transactional: false,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `test`.`users` ADD COLUMN `age` int NOT NULL AFTER `id`, ADD COLUMN `name` int NOT NULL AFTER `age`",
+						Reverse: "ALTER TABLE `test`.`users` DROP COLUMN `age`, DROP COLUMN `name`",
+					},
+				},
+			},
+		},
+		// Columns that are appended to the end of the table are not positioned.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{T: users, Changes: []schema.Change{
+					&schema.AddColumn{C: users.Columns[3]},
+				}},
+			},
+			want: &migrate.Plan{
+				Reversible:    true,
+				Transactional: false,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `test`.`users` ADD COLUMN `rank` int NOT NULL",
+						Reverse: "ALTER TABLE `test`.`users` DROP COLUMN `rank`",
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		db, mk, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{mk}.version("8.0.19")
+		drv, err := Open(db, WithColumnOrder(true))
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "plan", tt.changes)
+		require.NoError(t, err)
+		require.Equal(t, tt.want.Reversible, plan.Reversible)
+		require.Equal(t, tt.want.Transactional, plan.Transactional)
+		require.Len(t, plan.Changes, len(tt.want.Changes))
+		for i, c := range plan.Changes {
+			require.Equal(t, tt.want.Changes[i].Cmd, c.Cmd)
+			require.Equal(t, tt.want.Changes[i].Reverse, c.Reverse)
+		}
+	}
+}
+
+func TestPlanChanges_MinimalQuoting(t *testing.T) {
+	order := schema.NewTable("order").
+		SetSchema(schema.New("shop")).
+		AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewIntColumn("userId", "int"),
+			schema.NewIntColumn("group", "int"),
+			schema.NewIntColumn("unit price", "int"),
+		)
+	order.AddIndexes(schema.NewIndex("order_user_id").AddColumns(order.Columns[1]))
+	changes := []schema.Change{
+		&schema.AddTable{T: order},
+		&schema.ModifyTable{T: order, Changes: []schema.Change{
+			&schema.RenameColumn{From: schema.NewIntColumn("user`id", "int"), To: order.Columns[1]},
+		}},
+	}
+	for _, tt := range []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			want: []string{
+				"CREATE TABLE `shop`.`order` (`id` int NOT NULL, `userId` int NOT NULL, `group` int NOT NULL, `unit price` int NOT NULL, INDEX `order_user_id` (`userId`))",
+				"ALTER TABLE `shop`.`order` RENAME COLUMN `user``id` TO `userId`",
+			},
+		},
+		{
+			opts: []Option{WithMinimalQuoting(true)},
+			want: []string{
+				"CREATE TABLE shop.`order` (id int NOT NULL, userId int NOT NULL, `group` int NOT NULL, `unit price` int NOT NULL, INDEX order_user_id (userId))",
+				"ALTER TABLE shop.`order` RENAME COLUMN `user``id` TO userId",
+			},
+		},
+	} {
+		db, mk, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{mk}.version("8.0.19")
+		drv, err := Open(db, tt.opts...)
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, len(tt.want))
+		for i, c := range plan.Changes {
+			require.Equal(t, tt.want[i], c.Cmd)
+		}
+	}
+}
+
+func TestPlanChanges_Naming(t *testing.T) {
+	users := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "varchar(255)"))
+	users.AddIndexes(schema.NewIndex("").AddColumns(users.Columns[1]))
+	pets := schema.NewTable("pets").
+		AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("owner_id", "int"))
+	changes := []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{
+			&schema.AddIndex{I: schema.NewIndex("pets_owner").AddColumns(pets.Columns[1])},
+			&schema.AddForeignKey{F: schema.NewForeignKey("").SetTable(pets).AddColumns(pets.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0])},
+		}},
+	}
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("8.0.19")
+	drv, err := Open(db, WithNaming(naming.Ent{}))
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"CREATE TABLE `users` (`id` int NOT NULL, `name` varchar(255) NOT NULL, INDEX `users_name` (`name`))",
+		"ALTER TABLE `pets` ADD INDEX `pets_owner` (`owner_id`), ADD CONSTRAINT `pets_users_owner_id` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`)",
+	}, func() []string {
+		var cmds []string
+		for _, c := range plan.Changes {
+			cmds = append(cmds, c.Cmd)
+		}
+		return cmds
+	}())
+}
+
+func TestPlanApply_ConvertError(t *testing.T) {
+	p := &planApply{}
+	err := p.ConvertError("ALTER TABLE `t` ADD COLUMN `c` int", &mysqldrv.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded; try restarting transaction"})
+	require.True(t, schema.IsLockError(err))
+	require.EqualError(t, err, "Error 1205: Lock wait timeout exceeded; try restarting transaction")
+
+	err = p.ConvertError("ALTER TABLE `t` ADD UNIQUE INDEX `c` (`c`)", &mysqldrv.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 't.c'"})
+	var cv *schema.ConstraintViolationError
+	require.True(t, errors.As(err, &cv))
+	require.Equal(t, "t.c", cv.Constraint)
+
+	err = p.ConvertError("ALTER TABLE `t` ADD CONSTRAINT `fk` FOREIGN KEY (`c`) REFERENCES `u` (`id`)", &mysqldrv.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`test`.`#sql-1`, CONSTRAINT `fk` FOREIGN KEY (`c`) REFERENCES `u` (`id`))"})
+	require.True(t, errors.As(err, &cv))
+	require.Equal(t, "fk", cv.Constraint)
+
+	stmt := "CREATE TABLE `t` (`c` int NOT NULLL)"
+	err = p.ConvertError(stmt, &mysqldrv.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near 'NULLL)' at line 1"})
+	var se *schema.StmtError
+	require.True(t, errors.As(err, &se))
+	require.Equal(t, stmt, se.Stmt)
+	require.Equal(t, 31, se.Pos)
+	require.False(t, schema.IsLockError(err))
+	require.False(t, schema.IsConstraintViolationError(err))
+
+	plain := errors.New("driver: bad connection")
+	require.Equal(t, plain, p.ConvertError(stmt, plain))
+}
+
+func TestPlanChanges_ConvertCharset(t *testing.T) {
+	users := func(cs ...schema.Attr) *schema.Table {
+		t := schema.NewTable("users").
+			SetCharset("utf8mb4").
+			AddColumns(
+				schema.NewIntColumn("id", "bigint"),
+				&schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}}, Attrs: cs},
+			)
+		t.AddIndexes(schema.NewIndex("name").AddColumns(t.Columns[1]))
+		return t
+	}
+	tests := []struct {
+		version string
+		input   *schema.ModifyTable
+		wantCmd string
+		wantRev string
+		wantErr string
+	}{
+		{
+			version: "8.0.16",
+			input: &schema.ModifyTable{
+				T: users(),
+				Changes: []schema.Change{
+					&schema.ModifyAttr{From: &schema.Charset{V: "utf8"}, To: &schema.Charset{V: "utf8mb4"}},
+				},
+			},
+			wantCmd: "ALTER TABLE `users` CONVERT TO CHARACTER SET utf8mb4",
+			wantRev: "ALTER TABLE `users` CONVERT TO CHARACTER SET utf8",
+		},
+		{
+			version: "8.0.16",
+			input: &schema.ModifyTable{
+				T: users(),
+				Changes: []schema.Change{
+					&schema.ModifyAttr{From: &schema.Charset{V: "utf8"}, To: &schema.Charset{V: "utf8mb4"}},
+					&schema.ModifyAttr{From: &schema.Collation{V: "utf8_general_ci"}, To: &schema.Collation{V: "utf8mb4_bin"}},
+				},
+			},
+			wantCmd: "ALTER TABLE `users` CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_bin",
+			wantRev: "ALTER TABLE `users` CONVERT TO CHARACTER SET utf8 COLLATE utf8_general_ci",
+		},
+		// Columns that pin a different charset prevent the conversion.
+		{
+			version: "8.0.16",
+			input: &schema.ModifyTable{
+				T: users(&schema.Charset{V: "latin1"}),
+				Changes: []schema.Change{
+					&schema.ModifyAttr{From: &schema.Charset{V: "utf8"}, To: &schema.Charset{V: "utf8mb4"}},
+				},
+			},
+			wantCmd: "ALTER TABLE `users` CHARSET utf8mb4",
+			wantRev: "ALTER TABLE `users` CHARSET utf8",
+		},
+		{
+			version: "5.6.35",
+			input: &schema.ModifyTable{
+				T: users(),
+				Changes: []schema.Change{
+					&schema.ModifyAttr{From: &schema.Charset{V: "utf8"}, To: &schema.Charset{V: "utf8mb4"}},
+				},
+			},
+			wantErr: "alter table \"users\": key part \"name\" of index \"name\" exceeds the maximum key length of 767 bytes (1020 bytes after converting to utf8mb4)",
+		},
+		{
+			version: "5.6.35",
+			input: func() *schema.ModifyTable {
+				t := users(&schema.Charset{V: "utf8mb4"})
+				return &schema.ModifyTable{
+					T: t,
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   &schema.Column{Name: "name", Type: t.Columns[1].Type, Attrs: []schema.Attr{&schema.Charset{V: "latin1"}}},
+							To:     t.Columns[1],
+							Change: schema.ChangeCharset,
+						},
+					},
+				}
+			}(),
+			wantErr: "alter table \"users\": key part \"name\" of index \"name\" exceeds the maximum key length of 767 bytes (1020 bytes after converting to utf8mb4)",
+		},
+		{
+			version: "5.6.35",
+			input: func() *schema.ModifyTable {
+				t := users(&schema.Charset{V: "utf8mb4"})
+				t.Indexes[0].Parts[0].Attrs = append(t.Indexes[0].Parts[0].Attrs, &SubPart{Len: 191})
+				return &schema.ModifyTable{
+					T: t,
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   &schema.Column{Name: "name", Type: t.Columns[1].Type, Attrs: []schema.Attr{&schema.Charset{V: "latin1"}}},
+							To:     t.Columns[1],
+							Change: schema.ChangeCharset,
+						},
+					},
+				}
+			}(),
+			wantCmd: "ALTER TABLE `users` MODIFY COLUMN `name` varchar(255) NOT NULL",
+			wantRev: "ALTER TABLE `users` MODIFY COLUMN `name` varchar(255) NOT NULL CHARSET latin1",
+		},
+	}
+	for _, tt := range tests {
+		db, _, err := newMigrate(tt.version)
+		require.NoError(t, err)
+		plan, err := db.PlanChanges(context.Background(), "plan", []schema.Change{tt.input})
+		if tt.wantErr != "" {
+			require.EqualError(t, err, tt.wantErr)
+			continue
+		}
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 1)
+		require.Equal(t, tt.wantCmd, plan.Changes[0].Cmd)
+		require.Equal(t, tt.wantRev, plan.Changes[0].Reverse)
+	}
+}
+
+func TestPlanChanges_SystemVersioning(t *testing.T) {
+	users := func(v *SystemVersioned) *schema.Table {
+		t := schema.NewTable("users").
+			AddColumns(schema.NewIntColumn("id", "bigint")).
+			AddAttrs(v)
+		if v.Start != "" {
+			t.AddColumns(
+				schema.NewTimeColumn(v.Start, "timestamp", schema.TimePrecision(6)),
+				schema.NewTimeColumn(v.End, "timestamp", schema.TimePrecision(6)),
+			)
+		}
+		return t
+	}
+	tests := []struct {
+		input   schema.Change
+		wantCmd string
+		wantRev string
+	}{
+		{
+			input:   &schema.AddTable{T: users(&SystemVersioned{})},
+			wantCmd: "CREATE TABLE `users` (`id` bigint NOT NULL) WITH SYSTEM VERSIONING",
+			wantRev: "DROP TABLE `users`",
+		},
+		{
+			input:   &schema.AddTable{T: users(&SystemVersioned{Start: "s", End: "e"})},
+			wantCmd: "CREATE TABLE `users` (`id` bigint NOT NULL, `s` timestamp(6) GENERATED ALWAYS AS ROW START NOT NULL, `e` timestamp(6) GENERATED ALWAYS AS ROW END NOT NULL, PERIOD FOR SYSTEM_TIME (`s`, `e`)) WITH SYSTEM VERSIONING",
+			wantRev: "DROP TABLE `users`",
+		},
+		{
+			input: &schema.ModifyTable{
+				T:       users(&SystemVersioned{}),
+				Changes: []schema.Change{&schema.AddAttr{A: &SystemVersioned{}}},
+			},
+			wantCmd: "ALTER TABLE `users` ADD SYSTEM VERSIONING",
+		},
+		{
+			input: func() schema.Change {
+				v := &SystemVersioned{Start: "s", End: "e"}
+				t := users(v)
+				return &schema.ModifyTable{
+					T: t,
+					Changes: []schema.Change{
+						&schema.AddColumn{C: t.Columns[1]},
+						&schema.AddColumn{C: t.Columns[2]},
+						&schema.AddAttr{A: v},
+					},
+				}
+			}(),
+			wantCmd: "ALTER TABLE `users` ADD COLUMN `s` timestamp(6) GENERATED ALWAYS AS ROW START NOT NULL, ADD COLUMN `e` timestamp(6) GENERATED ALWAYS AS ROW END NOT NULL, ADD PERIOD FOR SYSTEM_TIME (`s`, `e`), ADD SYSTEM VERSIONING",
+		},
+		{
+			input: &schema.ModifyTable{
+				T:       schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint")),
+				Changes: []schema.Change{&schema.DropAttr{A: &SystemVersioned{}}},
+			},
+			wantCmd: "ALTER TABLE `users` DROP SYSTEM VERSIONING",
+		},
+	}
+	for _, tt := range tests {
+		db, _, err := newMigrate("10.7.1-MariaDB")
+		require.NoError(t, err)
+		plan, err := db.PlanChanges(context.Background(), "plan", []schema.Change{tt.input})
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 1)
+		require.Equal(t, tt.wantCmd, plan.Changes[0].Cmd)
+		require.Equal(t, tt.wantRev, plan.Changes[0].Reverse)
+	}
+}
+
+func TestFormatStmt(t *testing.T) {
+	require.Equal(t, "CREATE TABLE `t` (\n  `a` varchar(10) DEFAULT 'it\\'s, ok',\n  `b` int\n)", FormatStmt("create table `t` (`a` varchar(10) default 'it\\'s, ok', `b` int)"))
+}
+
+func TestSplitStmts(t *testing.T) {
+	stmts, err := SplitStmts("DELIMITER //\nCREATE TRIGGER `t` BEFORE INSERT ON `users` FOR EACH ROW BEGIN SET NEW.`a` = 1; END //\nDELIMITER ;\n# comment\nDROP TABLE `users`;")
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Equal(t, "CREATE TRIGGER `t` BEFORE INSERT ON `users` FOR EACH ROW BEGIN SET NEW.`a` = 1; END", stmts[0].Text)
+	require.Equal(t, "DROP TABLE `users`", stmts[1].Text)
+	require.Equal(t, []string{"# comment"}, stmts[1].Comments)
+	_, err = SplitStmts("SELECT 'a")
+	require.EqualError(t, err, "mysql: unterminated string at line 1, column 8")
+}
+
+func TestPlanChanges_ForeignKeyUnsupported(t *testing.T) {
+	users := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "int"))
+	pets := schema.NewTable("pets").
+		AddColumns(schema.NewIntColumn("owner_id", "int"))
+	for _, tt := range []struct {
+		fk      *schema.ForeignKey
+		wantErr string
+	}{
+		{
+			fk:      schema.NewForeignKey("owner").SetOnDelete(schema.SetDefault),
+			wantErr: `foreign key constraint "owner": SET DEFAULT action is not supported by MySQL`,
+		},
+		{
+			fk:      schema.NewForeignKey("owner").SetMatch(schema.MatchFull),
+			wantErr: `foreign key constraint "owner": MATCH FULL is not supported by MySQL`,
+		},
+		{
+			fk:      schema.NewForeignKey("owner").SetOnUpdate(schema.SetNull),
+			wantErr: `foreign key constraint was "owner" SET NULL, but column "owner_id" is NOT NULL`,
+		},
+	} {
+		db, mk, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{mk}.version("8.0.19")
+		drv, err := Open(db)
+		require.NoError(t, err)
+		tt.fk.SetTable(pets).AddColumns(pets.Columns...).SetRefTable(users).AddRefColumns(users.Columns...)
+		_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+			&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.AddForeignKey{F: tt.fk}}},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), tt.wantErr)
+	}
+}
+
+func TestPlanChanges_Guards(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("8.0.19")
+	drv, err := Open(db, WithAccounts(true))
+	require.NoError(t, err)
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+	plan, err := drv.PlanChanges(migrate.WithGuards(context.Background()), "plan", []schema.Change{
+		&schema.AddSchema{S: schema.New("audit")},
+		&schema.DropTable{T: schema.NewTable("tmp")},
+		&schema.AddTable{T: users},
+		&AddUser{U: &User{Name: "app"}},
+		&DropRole{R: &Role{Name: "old"}},
+	})
+	require.NoError(t, err)
+	var cmds []string
+	for _, c := range plan.Changes {
+		cmds = append(cmds, c.Cmd)
+	}
+	require.Equal(t, []string{
+		"DROP ROLE IF EXISTS 'old'",
+		"CREATE DATABASE IF NOT EXISTS `audit`",
+		"DROP TABLE IF EXISTS `tmp`",
+		"CREATE TABLE IF NOT EXISTS `users` (`id` int NOT NULL)",
+		"CREATE USER IF NOT EXISTS 'app'@'%'",
+	}, cmds)
+
+	_, err = drv.PlanChanges(migrate.WithGuards(context.Background()), "plan", []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.AddColumn{C: schema.NewNullStringColumn("name", "text")},
+		}},
+	})
+	require.EqualError(t, err, `existence guards are not supported for modifying table "users"`)
+}
@@ -3,6 +3,8 @@ package mysql
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"ariga.io/atlas/schema/schemaspec"
 	"ariga.io/atlas/schema/schemaspec/schemahcl"
@@ -85,6 +87,29 @@ func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, er
 	if err := convertCharset(spec, &t.Attrs); err != nil {
 		return nil, err
 	}
+	if attr, ok := spec.Attr("engine"); ok {
+		s, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		t.AddAttrs(&Engine{V: s})
+	}
+	if attr, ok := spec.Attr("row_format"); ok {
+		s, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		t.AddAttrs(&RowFormat{V: strings.ToUpper(s)})
+	}
+	if attr, ok := spec.Attr("system_versioned"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			t.AddAttrs(&SystemVersioned{})
+		}
+	}
 	return t, err
 }
 
@@ -120,6 +145,13 @@ func convertColumn(spec *sqlspec.Column, _ *schema.Table) (*schema.Column, error
 		}
 		c.AddAttrs(&OnUpdate{A: exp.X})
 	}
+	if attr, ok := spec.Attr("srid"); ok {
+		id, err := attr.Int()
+		if err != nil {
+			return nil, err
+		}
+		c.AddAttrs(&SRID{ID: id})
+	}
 	return c, err
 }
 
@@ -162,6 +194,15 @@ func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
 	if c, ok := hasCollate(t.Attrs, t.Schema.Attrs); ok {
 		ts.Extra.Attrs = append(ts.Extra.Attrs, specutil.StrAttr("collation", c))
 	}
+	if e := (Engine{}); sqlx.Has(t.Attrs, &e) {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, specutil.StrAttr("engine", e.V))
+	}
+	if r := (RowFormat{}); sqlx.Has(t.Attrs, &r) {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, specutil.StrAttr("row_format", r.V))
+	}
+	if _, ok := systemVersioned(t.Attrs); ok {
+		ts.Extra.Attrs = append(ts.Extra.Attrs, specutil.BoolAttr("system_versioned", true))
+	}
 	return ts, nil
 }
 
@@ -180,6 +221,9 @@ func columnSpec(c *schema.Column, t *schema.Table) (*sqlspec.Column, error) {
 	if o := (OnUpdate{}); sqlx.Has(c.Attrs, &o) {
 		col.Extra.Attrs = append(col.Extra.Attrs, specutil.RawAttr("on_update", o.A))
 	}
+	if s := (SRID{}); sqlx.Has(c.Attrs, &s) {
+		col.Extra.Attrs = append(col.Extra.Attrs, specutil.LitAttr("srid", strconv.Itoa(s.ID)))
+	}
 	return col, nil
 }
 
@@ -250,6 +294,21 @@ func hasCollate(attr []schema.Attr, parent []schema.Attr) (string, bool) {
 	return "", false
 }
 
+// registerSpec exposes the custom type to schema specs, if it is not already
+// defined there. For example, "double precision" is named "double_precision".
+func registerSpec(name string) error {
+	for _, s := range TypeRegistry.Specs() {
+		if strings.EqualFold(s.T, name) {
+			return nil
+		}
+	}
+	if err := TypeRegistry.Register(specutil.AliasTypeSpec(strings.ReplaceAll(name, " ", "_"), name)); err != nil {
+		return fmt.Errorf("mysql: registering custom type %q: %w", name, err)
+	}
+	hclState = schemahcl.New(schemahcl.WithTypes(TypeRegistry.Specs()))
+	return nil
+}
+
 // TypeRegistry contains the supported TypeSpecs for the mysql driver.
 var TypeRegistry = specutil.NewRegistry(
 	specutil.WithFormatter(FormatType),
@@ -461,6 +461,31 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestSpec_TableOptions(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `table "users" {
+  schema           = schema.test
+  engine           = "MyISAM"
+  row_format       = "COMPRESSED"
+  system_versioned = true
+  column "a" {
+    null = false
+    type = text
+  }
+}
+schema "test" {
+}
+`
+	)
+	err := UnmarshalHCL([]byte(f), &s)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Attr{&Engine{V: "MyISAM"}, &RowFormat{V: "COMPRESSED"}, &SystemVersioned{}}, s.Tables[0].Attrs)
+	buf, err := MarshalSpec(&s, hclState)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+}
+
 func TestMarshalSpec_Check(t *testing.T) {
 	s := schema.New("test").
 		AddTables(
@@ -579,6 +604,31 @@ schema "test" {
 	require.EqualValues(t, exp, buf)
 }
 
+func TestSpec_SRID(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `table "places" {
+  schema = schema.test
+  column "location" {
+    null = false
+    type = point
+    srid = 4326
+  }
+}
+schema "test" {
+}
+`
+	)
+	err := UnmarshalHCL([]byte(f), &s)
+	require.NoError(t, err)
+	c, ok := s.Tables[0].Column("location")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&SRID{ID: 4326}}, c.Attrs)
+	buf, err := MarshalHCL(&s)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+}
+
 func TestMarshalSpec_TimePrecision(t *testing.T) {
 	s := schema.New("test").
 		AddTables(
@@ -0,0 +1,88 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"reflect"
+
+	"ariga.io/atlas/sql/typedoc"
+)
+
+// Database flavors that are used by the availability of the types in TypeDocs.
+const (
+	FlavorMySQL   = "mysql"
+	FlavorMariaDB = "mariadb"
+)
+
+// Parameters that are shared by the types in TypeDocs.
+var (
+	unsignedParam = &typedoc.Param{Name: "unsigned", Kind: reflect.Bool}
+	widthParam    = &typedoc.Param{Name: "size", Kind: reflect.Int, Max: 255}
+	fspParam      = &typedoc.Param{Name: "precision", Kind: reflect.Int, Max: 6}
+	valuesParam   = &typedoc.Param{Name: "values", Kind: reflect.Slice, Required: true}
+	decimalParams = []*typedoc.Param{
+		unsignedParam,
+		{Name: "precision", Kind: reflect.Int, Max: 65},
+		{Name: "scale", Kind: reflect.Int, Max: 30},
+	}
+	floatParams = []*typedoc.Param{
+		unsignedParam,
+		{Name: "precision", Kind: reflect.Int, Max: 53},
+		{Name: "scale", Kind: reflect.Int, Max: 30},
+	}
+)
+
+// TypeDocs describes the column types that are supported by MySQL and MariaDB.
+var TypeDocs = &typedoc.Dialect{
+	Name: "mysql",
+	Types: []*typedoc.Type{
+		{Name: TypeBool, Aliases: []string{TypeBoolean}, Category: typedoc.CategoryBool, Doc: "A synonym for tinyint(1)."},
+		{Name: TypeBit, Category: typedoc.CategoryBit, Params: []*typedoc.Param{{Name: "size", Kind: reflect.Int, Max: 64}}, Doc: "A bit-value type with 1 to 64 bits."},
+		{Name: TypeTinyInt, Category: typedoc.CategoryInteger, Params: []*typedoc.Param{unsignedParam, widthParam}, Doc: "A 1-byte integer."},
+		{Name: TypeSmallInt, Category: typedoc.CategoryInteger, Params: []*typedoc.Param{unsignedParam, widthParam}, Doc: "A 2-byte integer."},
+		{Name: TypeMediumInt, Category: typedoc.CategoryInteger, Params: []*typedoc.Param{unsignedParam, widthParam}, Doc: "A 3-byte integer."},
+		{Name: TypeInt, Aliases: []string{"integer"}, Category: typedoc.CategoryInteger, Params: []*typedoc.Param{unsignedParam, widthParam}, Doc: "A 4-byte integer."},
+		{Name: TypeBigInt, Category: typedoc.CategoryInteger, Params: []*typedoc.Param{unsignedParam, widthParam}, Doc: "An 8-byte integer."},
+		{Name: TypeDecimal, Aliases: []string{TypeNumeric, "dec", "fixed"}, Category: typedoc.CategoryDecimal, Params: decimalParams, Doc: "An exact fixed-point number."},
+		{Name: TypeFloat, Category: typedoc.CategoryFloat, Params: floatParams, Doc: "A single-precision floating-point number."},
+		{Name: TypeDouble, Aliases: []string{"double precision", TypeReal}, Category: typedoc.CategoryFloat, Params: floatParams, Doc: "A double-precision floating-point number. REAL is a synonym for FLOAT if the REAL_AS_FLOAT SQL mode is enabled."},
+		{Name: TypeDate, Category: typedoc.CategoryTime, Doc: "A date in the range of 1000-01-01 to 9999-12-31."},
+		{Name: TypeDateTime, Category: typedoc.CategoryTime, Params: []*typedoc.Param{fspParam}, Doc: "A date and time combination."},
+		{Name: TypeTimestamp, Category: typedoc.CategoryTime, Params: []*typedoc.Param{fspParam}, Doc: "A date and time combination that is stored in UTC."},
+		{Name: TypeTime, Category: typedoc.CategoryTime, Params: []*typedoc.Param{fspParam}, Doc: "A time of day or a time interval."},
+		{Name: TypeYear, Category: typedoc.CategoryTime, Doc: "A year in a 4-digit format."},
+		{Name: TypeChar, Aliases: []string{"character"}, Category: typedoc.CategoryString, Params: []*typedoc.Param{{Name: "size", Kind: reflect.Int, Max: 255}}, Doc: "A fixed-length string."},
+		{Name: TypeVarchar, Aliases: []string{"character varying"}, Category: typedoc.CategoryString, Params: []*typedoc.Param{{Name: "size", Kind: reflect.Int, Required: true, Max: 65535}}, Doc: "A variable-length string."},
+		{Name: TypeBinary, Category: typedoc.CategoryBinary, Params: []*typedoc.Param{{Name: "size", Kind: reflect.Int, Max: 255}}, Doc: "A fixed-length binary string."},
+		{Name: TypeVarBinary, Category: typedoc.CategoryBinary, Params: []*typedoc.Param{{Name: "size", Kind: reflect.Int, Required: true, Max: 65535}}, Doc: "A variable-length binary string."},
+		{Name: TypeTinyBlob, Category: typedoc.CategoryBinary, Doc: "A binary string with a maximum length of 255 bytes."},
+		{Name: TypeBlob, Category: typedoc.CategoryBinary, Params: []*typedoc.Param{{Name: "size", Kind: reflect.Int, Max: 65535}}, Doc: "A binary string with a maximum length of 64KB."},
+		{Name: TypeMediumBlob, Category: typedoc.CategoryBinary, Doc: "A binary string with a maximum length of 16MB."},
+		{Name: TypeLongBlob, Category: typedoc.CategoryBinary, Doc: "A binary string with a maximum length of 4GB."},
+		{Name: TypeTinyText, Category: typedoc.CategoryString, Doc: "A string with a maximum length of 255 bytes."},
+		{Name: TypeText, Category: typedoc.CategoryString, Params: []*typedoc.Param{{Name: "size", Kind: reflect.Int, Max: 65535}}, Doc: "A string with a maximum length of 64KB."},
+		{Name: TypeMediumText, Category: typedoc.CategoryString, Doc: "A string with a maximum length of 16MB."},
+		{Name: TypeLongText, Category: typedoc.CategoryString, Doc: "A string with a maximum length of 4GB."},
+		{Name: TypeEnum, Category: typedoc.CategoryEnum, Params: []*typedoc.Param{valuesParam}, Doc: "A string with a value chosen from a list of permitted values."},
+		{Name: TypeSet, Category: typedoc.CategoryEnum, Params: []*typedoc.Param{valuesParam}, Doc: "A string with zero or more values chosen from a list of permitted values."},
+		{
+			Name:     TypeJSON,
+			Category: typedoc.CategoryJSON,
+			Availability: []*typedoc.Availability{
+				{Flavor: FlavorMySQL, Since: "5.7.8"},
+				{Flavor: FlavorMariaDB, Since: "10.2.7"},
+			},
+			Doc: "A JSON document. In MariaDB, JSON is an alias for LONGTEXT with a JSON_VALID check.",
+		},
+		{Name: TypeGeometry, Category: typedoc.CategorySpatial, Doc: "A spatial value of any type."},
+		{Name: TypePoint, Category: typedoc.CategorySpatial, Doc: "A single location in a coordinate space."},
+		{Name: TypeLineString, Category: typedoc.CategorySpatial, Doc: "A curve with linear interpolation between points."},
+		{Name: TypePolygon, Category: typedoc.CategorySpatial, Doc: "A planar surface representing a multisided geometry."},
+		{Name: TypeMultiPoint, Category: typedoc.CategorySpatial, Doc: "A collection of points."},
+		{Name: TypeMultiLineString, Category: typedoc.CategorySpatial, Doc: "A collection of line strings."},
+		{Name: TypeMultiPolygon, Category: typedoc.CategorySpatial, Doc: "A collection of polygons."},
+		{Name: TypeGeometryCollection, Aliases: []string{TypeGeoCollection}, Category: typedoc.CategorySpatial, Doc: "A collection of spatial values of any type. The geomcollection name is available in MySQL 8.0.11 and above."},
+	},
+}
@@ -0,0 +1,31 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeDocs(t *testing.T) {
+	for _, s := range TypeRegistry.Specs() {
+		// Custom types are not documented.
+		if _, ok, _ := customTypes.Parse(s.T); ok {
+			continue
+		}
+		_, ok := TypeDocs.Lookup(s.T)
+		require.Truef(t, ok, "type %q is not documented", s.T)
+	}
+	names := make(map[string]bool)
+	for _, typ := range TypeDocs.Types {
+		require.NotEmpty(t, typ.Category, typ.Name)
+		for _, n := range append([]string{typ.Name}, typ.Aliases...) {
+			require.Falsef(t, names[strings.ToLower(n)], "name %q is documented twice", n)
+			names[strings.ToLower(n)] = true
+		}
+	}
+}
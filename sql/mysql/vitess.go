@@ -0,0 +1,184 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// Vitess configures the Vitess (and PlanetScale) compatibility mode of the driver.
+// In this mode, keyspaces are inspected as schemas, each one separately, as vtgate
+// routes INFORMATION_SCHEMA queries to the keyspace in their predicate, and the
+// planner avoids statements that are not supported by Vitess.
+type Vitess struct {
+	// ForeignKeys indicates that the cluster supports foreign keys (e.g. Vitess 18
+	// or above with foreign_key_mode=managed). If false, foreign keys are not
+	// inspected, and they are omitted from the planned statements.
+	ForeignKeys bool
+
+	// DDLStrategy, if not empty, is set as the @@ddl_strategy of the session at
+	// the beginning of each plan (e.g. "vitess" or "vitess --postpone-completion"),
+	// in order to apply the schema changes using online DDL, as done by PlanetScale
+	// deploy requests. Note that session variables are set on the connection, and
+	// such plans should be applied using a single connection (e.g. *sql.Conn).
+	DDLStrategy string
+}
+
+// WithVitess enables the Vitess compatibility mode of the driver with the given
+// configuration. The mode is enabled automatically, with its default configuration,
+// if the server version reported by vtgate contains the "Vitess" suffix.
+func WithVitess(v Vitess) Option {
+	return func(o *options) {
+		o.vitess = &v
+	}
+}
+
+// Vitess returns true if the driver is connected to a Vitess
+// cluster (e.g. PlanetScale), or configured to act as such.
+func (d *Driver) Vitess() bool {
+	return d.vitess != nil
+}
+
+// vitessVersion reports if the given version was reported by vtgate
+// (e.g. "8.0.30-Vitess"), and returns the version of MySQL it mimics.
+func vitessVersion(v string) (string, bool) {
+	i := strings.Index(v, "-Vitess")
+	if i == -1 {
+		return v, false
+	}
+	return v[:i], true
+}
+
+// vitessSchemas returns the keyspaces of the cluster as schemas. Keyspaces do not
+// have character set and collation of their own, and the ones of the server are used.
+func (i *inspect) vitessSchemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
+	var names []string
+	if opts != nil {
+		names = opts.Schemas
+	}
+	// The current keyspace of the connection.
+	if len(names) == 1 && names[0] == "" {
+		rows, err := i.QueryContext(ctx, "SELECT DATABASE()")
+		if err != nil {
+			return nil, fmt.Errorf("mysql: querying current keyspace: %w", err)
+		}
+		var name sql.NullString
+		if err := sqlx.ScanOne(rows, &name); err != nil {
+			return nil, fmt.Errorf("mysql: scanning current keyspace: %w", err)
+		}
+		if !name.Valid {
+			return nil, nil
+		}
+		names = []string{name.String}
+	}
+	rows, err := i.QueryContext(ctx, "SHOW DATABASES")
+	if err != nil {
+		return nil, fmt.Errorf("mysql: querying keyspaces: %w", err)
+	}
+	keyspaces, err := sqlx.ScanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("mysql: scanning keyspaces: %w", err)
+	}
+	var schemas []*schema.Schema
+	for _, name := range keyspaces {
+		switch {
+		case len(names) == 0 && systemSchema(name):
+		case len(names) > 0 && !contains(names, name):
+		default:
+			schemas = append(schemas, &schema.Schema{
+				Name: name,
+				Attrs: []schema.Attr{
+					&schema.Charset{V: i.charset},
+					&schema.Collation{V: i.collate},
+				},
+			})
+		}
+	}
+	return schemas, nil
+}
+
+// vitessChanges returns the changes without the ones that are not supported by
+// the Vitess configuration of the driver. An error is returned for changes that
+// cannot be omitted, such as creating keyspaces.
+func (s *state) vitessChanges(changes []schema.Change) ([]schema.Change, error) {
+	planned := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			return nil, fmt.Errorf("mysql: creating keyspace %q is not supported by Vitess", c.S.Name)
+		case *schema.DropSchema:
+			return nil, fmt.Errorf("mysql: dropping keyspace %q is not supported by Vitess", c.S.Name)
+		case *schema.AddTable:
+			if !s.vitess.ForeignKeys && len(c.T.ForeignKeys) > 0 {
+				t := *c.T
+				t.ForeignKeys = nil
+				c = &schema.AddTable{T: &t, Extra: c.Extra}
+			}
+			planned = append(planned, c)
+		case *schema.ModifyTable:
+			if !s.vitess.ForeignKeys {
+				var rest []schema.Change
+				for _, m := range c.Changes {
+					switch m.(type) {
+					case *schema.AddForeignKey, *schema.DropForeignKey, *schema.ModifyForeignKey:
+					default:
+						rest = append(rest, m)
+					}
+				}
+				if len(rest) == 0 {
+					continue
+				}
+				c = &schema.ModifyTable{T: c.T, Changes: rest}
+			}
+			planned = append(planned, c)
+		default:
+			planned = append(planned, c)
+		}
+	}
+	return planned, nil
+}
+
+// ddlStrategy prepends the statement for setting the DDL
+// strategy of the session to the plan, if it was configured.
+func (s *state) ddlStrategy() error {
+	if s.vitess == nil || s.vitess.DDLStrategy == "" || len(s.Changes) == 0 {
+		return nil
+	}
+	v, err := sqlx.SingleQuote(s.vitess.DDLStrategy)
+	if err != nil {
+		return fmt.Errorf("mysql: quoting ddl strategy %q: %w", s.vitess.DDLStrategy, err)
+	}
+	s.Changes = append([]*migrate.Change{{
+		Cmd:     "SET @@ddl_strategy = " + v,
+		Reverse: "SET @@ddl_strategy = 'direct'",
+		Comment: "set the online DDL strategy of the session",
+	}}, s.Changes...)
+	return nil
+}
+
+// systemSchema reports if the given schema is a MySQL system schema.
+func systemSchema(name string) bool {
+	switch name {
+	case "mysql", "information_schema", "performance_schema", "sys":
+		return true
+	}
+	return false
+}
+
+func contains(s []string, v string) bool {
+	for i := range s {
+		if s[i] == v {
+			return true
+		}
+	}
+	return false
+}
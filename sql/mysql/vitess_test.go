@@ -0,0 +1,191 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_Vitess(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.30-Vitess")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	require.True(t, drv.Vitess())
+	require.Equal(t, "8.0.30", drv.version)
+	require.True(t, drv.supportsIndexExpr())
+	require.False(t, drv.vitess.ForeignKeys)
+
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.30")
+	drv, err = Open(db, WithVitess(Vitess{ForeignKeys: true}))
+	require.NoError(t, err)
+	require.True(t, drv.Vitess())
+	require.True(t, drv.vitess.ForeignKeys)
+
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.30")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	require.False(t, drv.Vitess())
+}
+
+func TestDriver_InspectVitess(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("8.0.30-Vitess")
+	mk.ExpectQuery(sqltest.Escape("SHOW DATABASES")).
+		WillReturnRows(sqltest.Rows(`
++--------------------+
+| Database           |
++--------------------+
+| commerce           |
+| customer           |
+| information_schema |
+| mysql              |
++--------------------+
+`))
+	// Keyspaces are queried one by one, and the names of
+	// the underlying databases are mapped to the keyspaces.
+	mk.ExpectQuery(queryTable).
+		WithArgs("commerce").
+		WillReturnRows(sqltest.Rows(`
++--------------+------------+--------------------+-----------------+----------------+---------------+----------------+--------+
+| TABLE_SCHEMA | TABLE_NAME | CHARACTER_SET_NAME | TABLE_COLLATION | AUTO_INCREMENT | TABLE_COMMENT | CREATE_OPTIONS | ENGINE |
++--------------+------------+--------------------+-----------------+----------------+---------------+----------------+--------+
+| vt_commerce  | product    | nil                | nil             | nil            | nil           | nil            | InnoDB |
++--------------+------------+--------------------+-----------------+----------------+---------------+----------------+--------+
+`))
+	mk.tables("customer")
+	mk.ExpectQuery(queryColumns).
+		WithArgs("commerce", "product").
+		WillReturnRows(sqltest.Rows(`
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+
+| table_name | column_name | column_type | column_comment | is_nullable | column_key | column_default | extra | character_set_name | collation_name |
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+
+| product    | sku         | varchar(64) |                | NO          | PRI        | NULL           |       | utf8               | utf8_general_ci |
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+
+`))
+	mk.ExpectQuery(queryIndexesExpr).
+		WithArgs("commerce", "product").
+		WillReturnRows(sqltest.Rows(`
++------------+------------+-------------+------------+--------------+------------+------+---------+----------+------------+
+| TABLE_NAME | INDEX_NAME | COLUMN_NAME | NON_UNIQUE | SEQ_IN_INDEX | INDEX_TYPE | DESC | COMMENT | SUB_PART | EXPRESSION |
++------------+------------+-------------+------------+--------------+------------+------+---------+----------+------------+
+| product    | PRIMARY    | sku         | 0          | 1            | BTREE      | 0    |         | NULL     | NULL       |
++------------+------------+-------------+------------+--------------+------------+------+---------+----------+------------+
+`))
+	// Foreign keys are not inspected.
+	mk.ExpectQuery(queryMyChecks).
+		WithArgs("commerce", "product").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "CONSTRAINT_NAME", "CHECK_CLAUSE", "ENFORCED"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, realm.Schemas, 2)
+	require.Equal(t, "commerce", realm.Schemas[0].Name)
+	require.Equal(t, []schema.Attr{&schema.Charset{V: "utf8"}, &schema.Collation{V: "utf8_general_ci"}}, realm.Schemas[0].Attrs)
+	require.Len(t, realm.Schemas[0].Tables, 1)
+	require.Equal(t, "product", realm.Schemas[0].Tables[0].Name)
+	require.Equal(t, "sku", realm.Schemas[0].Tables[0].PrimaryKey.Parts[0].C.Name)
+	require.Equal(t, "customer", realm.Schemas[1].Name)
+	require.Empty(t, realm.Schemas[1].Tables)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mk = mock{m}
+	mk.version("8.0.30-Vitess")
+	mk.ExpectQuery(sqltest.Escape("SELECT DATABASE()")).
+		WillReturnRows(sqltest.Rows(`
++------------+
+| DATABASE() |
++------------+
+| customer   |
++------------+
+`))
+	mk.ExpectQuery(sqltest.Escape("SHOW DATABASES")).
+		WillReturnRows(sqltest.Rows(`
++--------------------+
+| Database           |
++--------------------+
+| commerce           |
+| customer           |
++--------------------+
+`))
+	mk.tables("customer")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	s, err := drv.InspectSchema(context.Background(), "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "customer", s.Name)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestPlanChanges_Vitess(t *testing.T) {
+	users := &schema.Table{
+		Name: "users",
+		Columns: []*schema.Column{
+			{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeBigInt}}},
+		},
+	}
+	users.PrimaryKey = &schema.Index{Parts: []*schema.IndexPart{{C: users.Columns[0]}}}
+	posts := &schema.Table{
+		Name: "posts",
+		Columns: []*schema.Column{
+			{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeBigInt}}},
+			{Name: "author_id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeBigInt}, Null: true}},
+		},
+	}
+	posts.PrimaryKey = &schema.Index{Parts: []*schema.IndexPart{{C: posts.Columns[0]}}}
+	posts.ForeignKeys = []*schema.ForeignKey{
+		{Symbol: "author", Table: posts, Columns: posts.Columns[1:], RefTable: users, RefColumns: users.Columns},
+	}
+	changes := []schema.Change{
+		&schema.AddTable{T: posts},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddForeignKey{F: &schema.ForeignKey{Symbol: "self", Table: users, Columns: users.Columns, RefTable: users, RefColumns: users.Columns}}}},
+	}
+
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.30-Vitess")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, "CREATE TABLE `posts` (`id` bigint NOT NULL, `author_id` bigint NULL, PRIMARY KEY (`id`))", plan.Changes[0].Cmd)
+	// The desired state was not changed.
+	require.Len(t, posts.ForeignKeys, 1)
+
+	_, err = drv.PlanChanges(context.Background(), "", []schema.Change{&schema.AddSchema{S: &schema.Schema{Name: "commerce"}}})
+	require.EqualError(t, err, `mysql: creating keyspace "commerce" is not supported by Vitess`)
+
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.30-Vitess")
+	drv, err = Open(db, WithVitess(Vitess{ForeignKeys: true, DDLStrategy: "vitess --postpone-completion"}))
+	require.NoError(t, err)
+	plan, err = drv.PlanChanges(context.Background(), "", changes)
+	require.NoError(t, err)
+	require.False(t, plan.Transactional)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, "SET @@ddl_strategy = 'vitess --postpone-completion'", plan.Changes[0].Cmd)
+	require.Equal(t, "SET @@ddl_strategy = 'direct'", plan.Changes[0].Reverse)
+	require.Equal(t, "ALTER TABLE `users` ADD CONSTRAINT `self` FOREIGN KEY (`id`) REFERENCES `users` (`id`)", plan.Changes[1].Cmd)
+	require.Equal(t, "CREATE TABLE `posts` (`id` bigint NOT NULL, `author_id` bigint NULL, PRIMARY KEY (`id`), CONSTRAINT `author` FOREIGN KEY (`author_id`) REFERENCES `users` (`id`))", plan.Changes[2].Cmd)
+}
@@ -0,0 +1,275 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package naming generates the names of indexes, foreign keys and checks that are not
+// named by the desired state, using the naming conventions of the ORM that created the
+// database. Naming the desired state before diffing it with an adopted database allows
+// matching its existing objects by their names, instead of planning their renames.
+//
+//	naming.Realm(naming.Rails{}, desired)
+//	changes, err := drv.RealmDiff(current, desired)
+//	if err != nil {
+//		return err
+//	}
+//
+package naming
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// A Strategy generates the names of schema objects. An empty name
+// means the object is left unnamed, and it is named by the database.
+type Strategy interface {
+	// IndexName returns the name of the given table index.
+	IndexName(t *schema.Table, idx *schema.Index) string
+	// ForeignKeyName returns the name (symbol) of the given table foreign key.
+	ForeignKeyName(t *schema.Table, fk *schema.ForeignKey) string
+	// CheckName returns the name of the given table check constraint.
+	CheckName(t *schema.Table, c *schema.Check) string
+}
+
+// Realm names the unnamed indexes, foreign keys and checks of all tables in the realm.
+func Realm(s Strategy, r *schema.Realm) {
+	for _, sc := range r.Schemas {
+		for _, t := range sc.Tables {
+			Table(s, t)
+		}
+	}
+}
+
+// Table names the unnamed indexes, foreign keys and checks of the table.
+func Table(s Strategy, t *schema.Table) {
+	for _, idx := range t.Indexes {
+		nameIndex(s, t, idx)
+	}
+	for _, fk := range t.ForeignKeys {
+		nameForeignKey(s, t, fk)
+	}
+	for _, a := range t.Attrs {
+		if c, ok := a.(*schema.Check); ok {
+			nameCheck(s, t, c)
+		}
+	}
+}
+
+// Changes names the unnamed objects that are added by the given changes. It allows
+// planning changes that were computed without naming the desired state first, and
+// it is called by the PlanApplier of drivers that were opened with WithNaming.
+func Changes(s Strategy, changes []schema.Change) {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			Table(s, c.T)
+		case *schema.ModifyTable:
+			for _, tc := range c.Changes {
+				switch tc := tc.(type) {
+				case *schema.AddIndex:
+					nameIndex(s, c.T, tc.I)
+				case *schema.AddForeignKey:
+					nameForeignKey(s, c.T, tc.F)
+				case *schema.AddCheck:
+					nameCheck(s, c.T, tc.C)
+				}
+			}
+		}
+	}
+}
+
+func nameIndex(s Strategy, t *schema.Table, idx *schema.Index) {
+	if idx.Name == "" {
+		idx.Name = s.IndexName(t, idx)
+	}
+}
+
+func nameForeignKey(s Strategy, t *schema.Table, fk *schema.ForeignKey) {
+	if fk.Symbol == "" {
+		fk.Symbol = s.ForeignKeyName(t, fk)
+	}
+}
+
+func nameCheck(s Strategy, t *schema.Table, c *schema.Check) {
+	if c.Name == "" {
+		c.Name = s.CheckName(t, c)
+	}
+}
+
+type (
+	// Ent names objects the way Ent does. Indexes are named "<table>_<columns>",
+	// and foreign keys "<table>_<ref_table>_<columns>", both in lower case. Names
+	// that are longer than 64 characters are shortened using their MD5 checksum.
+	// Checks are left unnamed.
+	Ent struct{}
+
+	// Rails names objects the way Ruby on Rails (Active Record) does. Indexes are named
+	// "index_<table>_on_<columns>", foreign keys "fk_rails_<hash>" and checks "chk_rails_<hash>",
+	// where the hash is the first 10 characters of a SHA256 checksum.
+	Rails struct{}
+
+	// Django names objects the way the Django schema editor does: "<table>_<columns>_<hash>",
+	// followed by a suffix that depends on the object type (e.g. "_uniq"), where the hash is
+	// the first 8 characters of an MD5 checksum. Names that are longer than MaxLen are shortened.
+	// Checks are named after the first column they refer to, and are left unnamed otherwise.
+	Django struct {
+		// MaxLen is the maximum length of identifiers in the database. For
+		// example, 63 in PostgreSQL and 64 in MySQL. Defaults to 200.
+		MaxLen int
+	}
+)
+
+// IndexName implements the Strategy interface.
+func (Ent) IndexName(t *schema.Table, idx *schema.Index) string {
+	cs := columns(idx.Parts)
+	if len(cs) == 0 {
+		return ""
+	}
+	return entSymbol(t.Name + "_" + strings.Join(cs, "_"))
+}
+
+// ForeignKeyName implements the Strategy interface.
+func (Ent) ForeignKeyName(t *schema.Table, fk *schema.ForeignKey) string {
+	if fk.RefTable == nil || len(fk.Columns) == 0 {
+		return ""
+	}
+	return entSymbol(t.Name + "_" + fk.RefTable.Name + "_" + strings.Join(columnNames(fk.Columns), "_"))
+}
+
+// CheckName implements the Strategy interface.
+func (Ent) CheckName(*schema.Table, *schema.Check) string {
+	return ""
+}
+
+// entSymbol returns the lower-cased form of the given symbol, shortened to 64 characters if needed.
+func entSymbol(s string) string {
+	s = strings.ToLower(s)
+	if len(s) > 64 {
+		s = fmt.Sprintf("%s_%x", s[:31], md5.Sum([]byte(s)))
+	}
+	return s
+}
+
+// IndexName implements the Strategy interface.
+func (Rails) IndexName(t *schema.Table, idx *schema.Index) string {
+	cs := columns(idx.Parts)
+	if len(cs) == 0 {
+		return ""
+	}
+	return "index_" + t.Name + "_on_" + strings.Join(cs, "_and_")
+}
+
+// ForeignKeyName implements the Strategy interface.
+func (Rails) ForeignKeyName(t *schema.Table, fk *schema.ForeignKey) string {
+	if len(fk.Columns) == 0 {
+		return ""
+	}
+	return "fk_rails_" + railsHash(t.Name+"_"+fk.Columns[0].Name+"_fk")
+}
+
+// CheckName implements the Strategy interface.
+func (Rails) CheckName(t *schema.Table, c *schema.Check) string {
+	return "chk_rails_" + railsHash(t.Name+"_"+c.Expr+"_chk")
+}
+
+// railsHash returns the first 10 characters of the SHA256 checksum of s.
+func railsHash(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])[:10]
+}
+
+// IndexName implements the Strategy interface.
+func (d Django) IndexName(t *schema.Table, idx *schema.Index) string {
+	cs := columns(idx.Parts)
+	if len(cs) == 0 {
+		return ""
+	}
+	suffix := ""
+	if idx.Unique {
+		suffix = "_uniq"
+	}
+	return d.name(t.Name, cs, suffix)
+}
+
+// ForeignKeyName implements the Strategy interface.
+func (d Django) ForeignKeyName(t *schema.Table, fk *schema.ForeignKey) string {
+	if fk.RefTable == nil || len(fk.Columns) == 0 || len(fk.RefColumns) == 0 {
+		return ""
+	}
+	return d.name(t.Name, columnNames(fk.Columns), "_fk_"+fk.RefTable.Name+"_"+fk.RefColumns[0].Name)
+}
+
+// CheckName implements the Strategy interface.
+func (d Django) CheckName(t *schema.Table, c *schema.Check) string {
+	for _, col := range t.Columns {
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(col.Name) + `\b`).MatchString(c.Expr) {
+			return d.name(t.Name, []string{col.Name}, "_check")
+		}
+	}
+	return ""
+}
+
+// name implements the _create_index_name method of the Django schema editor.
+func (d Django) name(table string, columns []string, suffix string) string {
+	maxLen := d.MaxLen
+	if maxLen <= 0 {
+		maxLen = 200
+	}
+	h := md5.New()
+	h.Write([]byte(table))
+	for _, c := range columns {
+		h.Write([]byte(c))
+	}
+	hashSuffix := hex.EncodeToString(h.Sum(nil))[:8] + suffix
+	name := fmt.Sprintf("%s_%s_%s", table, strings.Join(columns, "_"), hashSuffix)
+	if len(name) <= maxLen {
+		return name
+	}
+	// Shorten a long suffix, and truncate the table and the columns parts.
+	if len(hashSuffix) > maxLen/3 {
+		hashSuffix = hashSuffix[:maxLen/3]
+	}
+	other, cs := (maxLen-len(hashSuffix))/2-1, strings.Join(columns, "_")
+	name = fmt.Sprintf("%s_%s_%s", truncate(table, other), truncate(cs, other), hashSuffix)
+	// Names cannot start with an underscore or a digit.
+	if name[0] == '_' || name[0] >= '0' && name[0] <= '9' {
+		name = "D" + name[:len(name)-1]
+	}
+	return name
+}
+
+// columns returns the names of the columns of the given index parts,
+// or nil if one of them is not a column (e.g. an expression).
+func columns(parts []*schema.IndexPart) []string {
+	names := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p.C == nil {
+			return nil
+		}
+		names = append(names, p.C.Name)
+	}
+	return names
+}
+
+// columnNames returns the names of the given columns.
+func columnNames(cs []*schema.Column) []string {
+	names := make([]string, len(cs))
+	for i, c := range cs {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// truncate returns the first n characters of s.
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
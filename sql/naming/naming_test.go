@@ -0,0 +1,104 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package naming_test
+
+import (
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/naming"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategies(t *testing.T) {
+	for _, tt := range []struct {
+		s                       naming.Strategy
+		uniq, idx, fk, chk, pre string
+	}{
+		{
+			s:    naming.Ent{},
+			uniq: "users_email",
+			idx:  "users_age_email",
+			fk:   "users_orgs_org_id",
+			chk:  "",
+			pre:  "named",
+		},
+		{
+			s:    naming.Rails{},
+			uniq: "index_users_on_email",
+			idx:  "index_users_on_age_and_email",
+			fk:   "fk_rails_e73753bccb",
+			chk:  "chk_rails_a4b2d327da",
+			pre:  "named",
+		},
+		{
+			s:    naming.Django{},
+			uniq: "users_email_0ea73cca_uniq",
+			idx:  "users_age_email_88317a5a",
+			fk:   "users_org_id_e531df03_fk_orgs_id",
+			chk:  "users_age_dfb2a4ce_check",
+			pre:  "named",
+		},
+	} {
+		r, users := realm()
+		naming.Realm(tt.s, r)
+		require.Equal(t, tt.uniq, users.Indexes[0].Name)
+		require.Equal(t, tt.idx, users.Indexes[1].Name)
+		require.Equal(t, tt.pre, users.Indexes[2].Name, "named objects are kept")
+		require.Equal(t, "", users.Indexes[3].Name, "expression indexes are not named")
+		require.Equal(t, tt.fk, users.ForeignKeys[0].Symbol)
+		require.Equal(t, tt.chk, users.Attrs[0].(*schema.Check).Name)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	_, users := realm()
+	idx := schema.NewIndex("").AddColumns(users.Columns[1])
+	c := &schema.Check{Expr: "age > 0"}
+	naming.Changes(naming.Rails{}, []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.AddIndex{I: idx},
+			&schema.AddCheck{C: c},
+		}},
+	})
+	require.Equal(t, "index_users_on_email", idx.Name)
+	require.Equal(t, "chk_rails_a4b2d327da", c.Name)
+	require.Empty(t, users.Indexes[0].Name, "only added objects are named")
+}
+
+func TestLongNames(t *testing.T) {
+	var (
+		long = schema.NewTable(strings.Repeat("a", 40)).AddColumns(schema.NewIntColumn(strings.Repeat("b", 30), "int"))
+		idx  = schema.NewIndex("").AddColumns(long.Columns[0])
+	)
+	long.AddIndexes(idx)
+	require.Equal(t, "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa_008e73375d18d0ccfe4f4872277987fc", naming.Ent{}.IndexName(long, idx))
+
+	_, users := realm()
+	require.Equal(t, "users_org_id_e531df03_f", naming.Django{MaxLen: 30}.ForeignKeyName(users, users.ForeignKeys[0]))
+}
+
+func realm() (*schema.Realm, *schema.Table) {
+	var (
+		orgs  = schema.NewTable("orgs").AddColumns(schema.NewIntColumn("id", "int"))
+		users = schema.NewTable("users").AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewStringColumn("email", "varchar(255)"),
+			schema.NewIntColumn("age", "int"),
+			schema.NewIntColumn("org_id", "int"),
+		)
+	)
+	users.AddIndexes(
+		schema.NewUniqueIndex("").AddColumns(users.Columns[1]),
+		schema.NewIndex("").AddColumns(users.Columns[2], users.Columns[1]),
+		schema.NewIndex("named").AddColumns(users.Columns[3]),
+		schema.NewIndex("").AddExprs(&schema.RawExpr{X: "lower(email)"}),
+	)
+	users.AddForeignKeys(schema.NewForeignKey("").AddColumns(users.Columns[3]).SetRefTable(orgs).AddRefColumns(orgs.Columns[0]))
+	users.AddChecks(&schema.Check{Expr: "age > 0"})
+	return schema.NewRealm(schema.New("public").AddTables(orgs, users)), users
+}
@@ -0,0 +1,297 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package oracle
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// FormatType converts schema type to its column form in the database.
+// An error is returned if the type cannot be recognized.
+//
+// Integer types that are not part of Oracle (e.g. "bigint") are formatted as
+// NUMBER with the precision that is required for holding their values (e.g.
+// NUMBER(19)), and "int", "integer" and "smallint" are formatted as-is, as
+// they are ANSI aliases of NUMBER(38) in Oracle. Note that TIMESTAMP types with
+// zero precision are formatted without precision, which defaults to 6.
+func FormatType(t schema.Type) (string, error) {
+	var f string
+	switch t := t.(type) {
+	case *schema.BoolType:
+		f = strings.ToUpper(TypeBoolean)
+	case *schema.BinaryType:
+		switch f = strings.ToUpper(t.T); strings.ToLower(t.T) {
+		case TypeRaw:
+			if t.Size == 0 {
+				return "", fmt.Errorf("oracle: missing size for RAW type")
+			}
+			f = fmt.Sprintf("RAW(%d)", t.Size)
+		case "", "binary", "varbinary":
+			f = "RAW(2000)"
+			if t.Size > 0 {
+				f = fmt.Sprintf("RAW(%d)", t.Size)
+			}
+		case "tinyblob", "mediumblob", "longblob", "bytea":
+			f = "BLOB"
+		}
+	case *schema.DecimalType:
+		f = strings.ToUpper(t.T)
+		switch strings.ToLower(t.T) {
+		case TypeNumber, "decimal", "numeric", "dec":
+			f = strings.ToUpper(TypeNumber)
+		}
+		switch {
+		case t.Precision > 0 && t.Scale != 0:
+			f = fmt.Sprintf("%s(%d,%d)", f, t.Precision, t.Scale)
+		case t.Precision > 0:
+			f = fmt.Sprintf("%s(%d)", f, t.Precision)
+		}
+	case *schema.EnumType:
+		return "", fmt.Errorf("oracle: enum types are not supported, use a CHECK constraint instead")
+	case *schema.FloatType:
+		switch f = strings.ToUpper(t.T); strings.ToLower(t.T) {
+		case TypeFloat:
+			if t.Precision > 0 {
+				f = fmt.Sprintf("FLOAT(%d)", t.Precision)
+			}
+		case "real":
+			f = "BINARY_FLOAT"
+		case "double", "double precision":
+			f = "BINARY_DOUBLE"
+		}
+	case *schema.IntegerType:
+		switch f = strings.ToUpper(t.T); strings.ToLower(t.T) {
+		case "tinyint":
+			f = "NUMBER(3)"
+		case "mediumint":
+			f = "NUMBER(7)"
+		case "bigint":
+			f = "NUMBER(19)"
+		}
+	case *schema.JSONType:
+		f = strings.ToUpper(TypeJSON)
+	case *schema.StringType:
+		switch f = strings.ToUpper(t.T); strings.ToLower(t.T) {
+		case TypeVarchar2, TypeNVarchar2, TypeChar, TypeNChar:
+			if t.Size > 0 {
+				f = fmt.Sprintf("%s(%d)", f, t.Size)
+			}
+		case "varchar", "character varying":
+			f = "VARCHAR2(4000)"
+			if t.Size > 0 {
+				f = fmt.Sprintf("VARCHAR2(%d)", t.Size)
+			}
+		case "text", "tinytext", "mediumtext", "longtext":
+			f = "CLOB"
+		}
+	case *schema.TimeType:
+		switch f = strings.ToUpper(t.T); strings.ToLower(t.T) {
+		case TypeTimestamp, TypeTimestampTZ, TypeTimestampLZ:
+			if t.Precision > 0 {
+				f = strings.Replace(f, "TIMESTAMP", fmt.Sprintf("TIMESTAMP(%d)", t.Precision), 1)
+			}
+		case "datetime":
+			f = "TIMESTAMP"
+		case "timestamptz":
+			f = "TIMESTAMP WITH TIME ZONE"
+		}
+	case *schema.UnsupportedType:
+		// Types that are unknown to the driver are passed as is.
+		if t.T == "" {
+			return "", fmt.Errorf("oracle: missing unsupported type definition")
+		}
+		f = t.T
+	default:
+		return "", fmt.Errorf("oracle: invalid schema type: %T", t)
+	}
+	return f, nil
+}
+
+// mustFormat calls to FormatType and panics in case of error.
+func mustFormat(t schema.Type) string {
+	s, err := FormatType(t)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// ParseType returns the schema.Type value represented by the given raw type.
+// The raw value is expected to follow the format in Oracle documentation.
+// https://docs.oracle.com/en/database/oracle/oracle-database/19/sqlrf/Data-Types.html
+//
+// NUMBER types are parsed as schema.DecimalType, where a NUMBER column without
+// precision is represented with a zero precision, and NUMBER(*,s) with the maximum
+// precision (38). INTEGER and SMALLINT are parsed as schema.IntegerType.
+func ParseType(raw string) (schema.Type, error) {
+	var (
+		mods         []string
+		name, suffix = strings.ToLower(strings.TrimSpace(raw)), ""
+	)
+	// Split the type name, its modifiers and its suffix.
+	// For example: "timestamp(6) with time zone".
+	if i, j := strings.IndexByte(name, '('), strings.IndexByte(name, ')'); i > 0 && j > i {
+		mods = strings.Split(name[i+1:j], ",")
+		name, suffix = strings.TrimSpace(name[:i]), strings.TrimSpace(name[j+1:])
+	}
+	for i := range mods {
+		mods[i] = strings.TrimSpace(mods[i])
+	}
+	if strings.HasPrefix(name, TypeTimestamp+" ") {
+		name, suffix = TypeTimestamp, strings.TrimPrefix(name, TypeTimestamp+" ")
+	}
+	switch name {
+	case TypeNumber, "decimal", "numeric", "dec":
+		t := &schema.DecimalType{T: TypeNumber}
+		if len(mods) > 0 {
+			p, err := parsePrecision(mods[0])
+			if err != nil {
+				return nil, fmt.Errorf("oracle: parse precision %q: %w", mods[0], err)
+			}
+			t.Precision = p
+		}
+		if len(mods) > 1 {
+			s, err := strconv.Atoi(mods[1])
+			if err != nil {
+				return nil, fmt.Errorf("oracle: parse scale %q: %w", mods[1], err)
+			}
+			t.Scale = s
+		}
+		return t, nil
+	case TypeInteger, "int", TypeSmallInt:
+		return &schema.IntegerType{T: name}, nil
+	case TypeFloat:
+		t := &schema.FloatType{T: TypeFloat}
+		if len(mods) > 0 {
+			p, err := strconv.Atoi(mods[0])
+			if err != nil {
+				return nil, fmt.Errorf("oracle: parse precision %q: %w", mods[0], err)
+			}
+			t.Precision = p
+		}
+		return t, nil
+	case TypeBinaryFloat, TypeBinaryDbl:
+		return &schema.FloatType{T: name}, nil
+	case TypeChar, TypeNChar, TypeVarchar2, TypeNVarchar2:
+		t := &schema.StringType{T: name}
+		if len(mods) > 0 {
+			// The length semantics (e.g. "10 CHAR") is not part of the type.
+			n := strings.TrimSuffix(strings.TrimSuffix(mods[0], " char"), " byte")
+			size, err := strconv.Atoi(strings.TrimSpace(n))
+			if err != nil {
+				return nil, fmt.Errorf("oracle: parse size %q: %w", mods[0], err)
+			}
+			t.Size = size
+		}
+		return t, nil
+	case TypeClob, TypeNClob, TypeLong:
+		return &schema.StringType{T: name}, nil
+	case TypeRaw:
+		t := &schema.BinaryType{T: name}
+		if len(mods) > 0 {
+			size, err := strconv.Atoi(mods[0])
+			if err != nil {
+				return nil, fmt.Errorf("oracle: parse size %q: %w", mods[0], err)
+			}
+			t.Size = size
+		}
+		return t, nil
+	case TypeBlob, "long raw":
+		return &schema.BinaryType{T: name}, nil
+	case TypeDate:
+		return &schema.TimeType{T: name}, nil
+	case TypeTimestamp:
+		t := &schema.TimeType{T: TypeTimestamp}
+		if suffix != "" {
+			t.T += " " + suffix
+		}
+		if len(mods) > 0 {
+			p, err := strconv.Atoi(mods[0])
+			if err != nil {
+				return nil, fmt.Errorf("oracle: parse precision %q: %w", mods[0], err)
+			}
+			t.Precision = p
+		}
+		return t, nil
+	case TypeBoolean:
+		return &schema.BoolType{T: name}, nil
+	case TypeJSON:
+		return &schema.JSONType{T: name}, nil
+	default:
+		return &schema.UnsupportedType{T: raw}, nil
+	}
+}
+
+// parsePrecision parses the precision of NUMBER types,
+// where "*" stands for the maximum precision.
+func parsePrecision(s string) (int, error) {
+	if s == "*" {
+		return 38, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// columnDesc represents a column descriptor as it is
+// described in the ALL_TAB_COLUMNS view.
+type columnDesc struct {
+	typ       string // DATA_TYPE
+	size      int64  // DATA_LENGTH
+	charSize  int64  // CHAR_LENGTH
+	precision int64  // DATA_PRECISION
+	scale     int64  // DATA_SCALE
+	// Reports if the DATA_PRECISION and DATA_SCALE columns are not NULL.
+	hasPrecision, hasScale bool
+}
+
+// reTypeMod matches the modifiers that are part of the
+// DATA_TYPE column. For example, "TIMESTAMP(6)".
+var reTypeMod = regexp.MustCompile(`\(\d+\)`)
+
+func columnType(c *columnDesc) schema.Type {
+	typ := strings.ToLower(reTypeMod.ReplaceAllString(c.typ, ""))
+	switch typ {
+	case TypeNumber:
+		switch {
+		// INTEGER and SMALLINT are stored as NUMBER without
+		// precision, and with zero scale (i.e. NUMBER(*,0)).
+		case !c.hasPrecision && c.hasScale && c.scale == 0:
+			return &schema.IntegerType{T: TypeInteger}
+		case !c.hasPrecision && !c.hasScale:
+			return &schema.DecimalType{T: TypeNumber}
+		case !c.hasPrecision:
+			return &schema.DecimalType{T: TypeNumber, Precision: 38, Scale: int(c.scale)}
+		default:
+			return &schema.DecimalType{T: TypeNumber, Precision: int(c.precision), Scale: int(c.scale)}
+		}
+	case TypeFloat:
+		return &schema.FloatType{T: typ, Precision: int(c.precision)}
+	case TypeBinaryFloat, TypeBinaryDbl:
+		return &schema.FloatType{T: typ}
+	case TypeChar, TypeNChar, TypeVarchar2, TypeNVarchar2:
+		return &schema.StringType{T: typ, Size: int(c.charSize)}
+	case TypeClob, TypeNClob, TypeLong:
+		return &schema.StringType{T: typ}
+	case TypeRaw:
+		return &schema.BinaryType{T: typ, Size: int(c.size)}
+	case TypeBlob, "long raw":
+		return &schema.BinaryType{T: typ}
+	case TypeDate:
+		return &schema.TimeType{T: typ}
+	case TypeTimestamp, TypeTimestampTZ, TypeTimestampLZ:
+		// The fractional seconds precision is stored in DATA_SCALE.
+		return &schema.TimeType{T: typ, Precision: int(c.scale)}
+	case TypeBoolean:
+		return &schema.BoolType{T: typ}
+	case TypeJSON:
+		return &schema.JSONType{T: typ}
+	default:
+		return &schema.UnsupportedType{T: typ}
+	}
+}
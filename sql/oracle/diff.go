@@ -0,0 +1,169 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package oracle
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A diff provides an Oracle implementation for sqlx.DiffDriver.
+type diff struct{ conn }
+
+// SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
+func (d *diff) SchemaAttrDiff(_, _ *schema.Schema) []schema.Change {
+	// Schemas are database users in Oracle, and they have no attributes.
+	return nil
+}
+
+// TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
+func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
+	var changes []schema.Change
+	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	return append(changes, sqlx.CheckDiff(from, to)...), nil
+}
+
+// ColumnChange returns the schema changes (if any) for migrating one column to the other.
+func (d *diff) ColumnChange(from, to *schema.Column) (schema.ChangeKind, error) {
+	change := sqlx.CommentChange(from.Attrs, to.Attrs)
+	if from.Type.Null != to.Type.Null {
+		change |= schema.ChangeNull
+	}
+	changed, err := d.typeChanged(from, to)
+	if err != nil {
+		return schema.NoChange, err
+	}
+	if changed {
+		change |= schema.ChangeType
+	}
+	if d.defaultChanged(from, to) {
+		change |= schema.ChangeDefault
+	}
+	if identityChanged(from.Attrs, to.Attrs) {
+		change |= schema.ChangeAttr
+	}
+	return change, nil
+}
+
+// typeChanged reports if the column type was changed. Types are compared by their
+// canonical form, as some types have multiple names in Oracle (e.g. INTEGER and
+// NUMBER(38)).
+func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
+	if from.Type.Type == nil || to.Type.Type == nil {
+		return false, fmt.Errorf("oracle: missing type information for column %q", from.Name)
+	}
+	t1, err := d.typeName(from)
+	if err != nil {
+		return false, err
+	}
+	t2, err := d.typeName(to)
+	if err != nil {
+		return false, err
+	}
+	return canonicalType(t1) != canonicalType(t2), nil
+}
+
+// canonicalType returns the canonical form of the given formatted type.
+func canonicalType(t string) string {
+	t = strings.ToUpper(t)
+	switch t {
+	case "INTEGER", "INT", "SMALLINT", "NUMBER(*,0)":
+		return "NUMBER(38)"
+	case "FLOAT", "DOUBLE PRECISION":
+		return "FLOAT(126)"
+	case "REAL":
+		return "FLOAT(63)"
+	}
+	// The default fractional seconds precision of timestamps is 6.
+	if strings.HasPrefix(t, "TIMESTAMP") && !strings.HasPrefix(t, "TIMESTAMP(") {
+		return "TIMESTAMP(6)" + strings.TrimPrefix(t, "TIMESTAMP")
+	}
+	return t
+}
+
+// defaultChanged reports if the default value of a column was changed.
+func (d *diff) defaultChanged(from, to *schema.Column) bool {
+	d1, ok1 := defaultValue(from)
+	d2, ok2 := defaultValue(to)
+	if ok1 != ok2 {
+		return true
+	}
+	d1, d2 = sqlx.TrimParens(d1), sqlx.TrimParens(d2)
+	// Literals are compared as-is, and expressions
+	// are compared without whitespace and case.
+	if sqlx.IsQuoted(d1, '\'') || sqlx.IsQuoted(d2, '\'') {
+		return d1 != d2
+	}
+	return !strings.EqualFold(strings.Map(dropSpace, d1), strings.Map(dropSpace, d2))
+}
+
+// dropSpace is a strings.Map function that drops whitespaces.
+func dropSpace(r rune) rune {
+	if unicode.IsSpace(r) {
+		return -1
+	}
+	return r
+}
+
+// identityChanged reports if the identity attribute of a column was changed.
+func identityChanged(from, to []schema.Attr) bool {
+	i1, ok1 := identity(from)
+	i2, ok2 := identity(to)
+	return ok1 != ok2 || ok1 && !strings.EqualFold(i1.Generation, i2.Generation)
+}
+
+// IsGeneratedIndexName reports if the index name was generated by the database
+// for unnamed PRIMARY KEY and UNIQUE constraints (e.g. SYS_C0012345).
+func (d *diff) IsGeneratedIndexName(_ *schema.Table, idx *schema.Index) bool {
+	return isSystemName(idx.Name)
+}
+
+// IndexAttrChanged reports if the index attributes were changed.
+func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
+	var t1, t2 IndexType
+	if sqlx.Has(from, &t1) != sqlx.Has(to, &t2) || !strings.EqualFold(t1.T, t2.T) {
+		return true
+	}
+	var c1, c2 ConType
+	return sqlx.Has(from, &c1) != sqlx.Has(to, &c2) || c1.T != c2.T
+}
+
+// IndexPartAttrChanged reports if the index-part attributes were changed.
+func (*diff) IndexPartAttrChanged(_, _ *schema.IndexPart) bool {
+	return false
+}
+
+// ReferenceChanged reports if the foreign key referential action was changed.
+func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
+	// According to Oracle, if an action is not explicitly
+	// specified, it defaults to "NO ACTION".
+	if from == "" {
+		from = schema.NoAction
+	}
+	if to == "" {
+		to = schema.NoAction
+	}
+	return from != to
+}
+
+// isSystemName reports if the given constraint or index
+// name was generated by the database.
+func isSystemName(name string) bool {
+	if !strings.HasPrefix(name, "SYS_C") || len(name) == len("SYS_C") {
+		return false
+	}
+	for _, r := range name[len("SYS_C"):] {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
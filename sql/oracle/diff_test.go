@@ -0,0 +1,93 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package oracle
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff_TableDiff(t *testing.T) {
+	type testcase struct {
+		name        string
+		from, to    *schema.Table
+		wantChanges []schema.Change
+		wantErr     bool
+	}
+	tests := []testcase{
+		{
+			name: "integer equals number(38)",
+			from: &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeInteger}}}}},
+			to:   &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.DecimalType{T: TypeNumber, Precision: 38}}}}},
+		},
+		{
+			name: "default timestamp precision",
+			from: &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.TimeType{T: TypeTimestamp}}}}},
+			to:   &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.TimeType{T: TypeTimestamp, Precision: 6}}}}},
+		},
+		{
+			name: "default expression case",
+			from: &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.TimeType{T: TypeDate}}, Default: &schema.RawExpr{X: "sysdate"}}}},
+			to:   &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.TimeType{T: TypeDate}}, Default: &schema.RawExpr{X: "SYSDATE"}}}},
+		},
+		func() testcase {
+			var (
+				from = &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeInteger}}, Attrs: []schema.Attr{&Identity{Generation: "ALWAYS"}}}}}
+				to   = &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeInteger}}, Attrs: []schema.Attr{&Identity{Generation: "BY DEFAULT"}}}}}
+			)
+			return testcase{
+				name: "identity generation",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeAttr},
+				},
+			}
+		}(),
+		func() testcase {
+			var (
+				from = &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar2, Size: 10}}}}}
+				to   = &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar2, Size: 10}}, Attrs: []schema.Attr{&CharSemantics{}}}}}
+			)
+			return testcase{
+				name: "char semantics",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeType},
+				},
+			}
+		}(),
+		{
+			name:    "missing type",
+			from:    &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{}}}},
+			to:      &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeInteger}}}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.version("19.0.0.0.0")
+		drv, err := Open(db)
+		require.NoError(t, err)
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := drv.TableDiff(tt.from, tt.to)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.EqualValues(t, tt.wantChanges, changes)
+		})
+	}
+}
+
+func TestDiff_IsGeneratedIndexName(t *testing.T) {
+	d := &diff{}
+	require.True(t, d.IsGeneratedIndexName(nil, &schema.Index{Name: "SYS_C0012345"}))
+	require.False(t, d.IsGeneratedIndexName(nil, &schema.Index{Name: "SYS_C"}))
+	require.False(t, d.IsGeneratedIndexName(nil, &schema.Index{Name: "USERS_PK"}))
+}
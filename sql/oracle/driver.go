@@ -0,0 +1,218 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlmetrics"
+	"ariga.io/atlas/sql/sqltrace"
+
+	"golang.org/x/mod/semver"
+)
+
+type (
+	// Driver represents an Oracle driver for introspecting database schemas,
+	// generating diff between schema elements and apply migrations changes.
+	// The driver supports Oracle Database 12c Release 1 (12.1) and above.
+	//
+	// A Driver is safe for concurrent use by multiple goroutines, as long as its
+	// underlying connection is (e.g. *sql.DB). Inspections, planning and applying
+	// of drivers that were opened on a single connection (i.e. *sql.Tx or *sql.Conn)
+	// are serialized. Note that diffing normalizes the given schema elements, and
+	// the same elements should not be diffed concurrently.
+	Driver struct {
+		conn
+		schema.Differ
+		schema.Inspector
+		migrate.PlanApplier
+	}
+
+	// Option allows configuring the Driver using functional options.
+	Option func(*options)
+
+	// options holds the configuration of the Driver.
+	options struct {
+		trace    *sqltrace.Config
+		metrics  sqlmetrics.Recorder
+		version  string
+		log      sqlx.LogFunc
+		readOnly bool
+	}
+
+	// database connection and its information.
+	conn struct {
+		schema.ExecQuerier
+		// System variables that are set on `Open`.
+		version string
+		// The schema of the connection (i.e. CURRENT_SCHEMA),
+		// which is inspected if no schema name is provided.
+		schema string
+		// Opened on a single database connection (i.e. *sql.Tx or *sql.Conn),
+		// before it was wrapped by the options of the driver (e.g. WithReadOnly).
+		single bool
+	}
+)
+
+// Open opens a new Oracle driver.
+func Open(db schema.ExecQuerier, opts ...Option) (*Driver, error) {
+	var (
+		o      options
+		single = sqlx.SingleConn(db)
+	)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.readOnly {
+		db = sqlx.ReadOnlyExecQuerier(db)
+	}
+	if o.log != nil {
+		db = sqlx.LogExecQuerier(db, o.log)
+	}
+	var tracer *sqltrace.Tracer
+	if o.trace != nil {
+		tracer = sqltrace.New("oracle", *o.trace)
+		db = tracer.ExecQuerier(db)
+	}
+	var metrics *sqlmetrics.Metrics
+	if o.metrics != nil {
+		metrics = sqlmetrics.New("oracle", o.metrics)
+		db = metrics.ExecQuerier(db)
+	}
+	c := conn{ExecQuerier: db, single: single}
+	rows, err := db.QueryContext(context.Background(), paramsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("oracle: query database version: %w", err)
+	}
+	if err := sqlx.ScanOne(rows, &c.version, &c.schema); err != nil {
+		return nil, fmt.Errorf("oracle: scan database version: %w", err)
+	}
+	if o.version != "" {
+		c.version = o.version
+	}
+	c.version = shortVersion(c.version)
+	if !c.gteV("12.1.0") {
+		return nil, fmt.Errorf("oracle: unsupported database version %q (expect 12.1 and above)", c.version)
+	}
+	drv := &Driver{
+		conn:        c,
+		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
+		Inspector:   &inspect{c},
+		PlanApplier: &planApply{c},
+	}
+	if single {
+		var mu sync.Mutex
+		drv.Inspector = sqlx.SerialInspector(drv.Inspector, &mu)
+		drv.PlanApplier = sqlx.SerialPlanApplier(drv.PlanApplier, &mu)
+	}
+	if tracer != nil {
+		drv.Differ = tracer.Differ(drv.Differ)
+		drv.Inspector = tracer.Inspector(drv.Inspector)
+		drv.PlanApplier = tracer.PlanApplier(drv.PlanApplier)
+	}
+	if metrics != nil {
+		drv.Differ = metrics.Differ(drv.Differ)
+		drv.Inspector = metrics.Inspector(drv.Inspector)
+		drv.PlanApplier = metrics.PlanApplier(drv.PlanApplier)
+	}
+	return drv, nil
+}
+
+// WithTracing enables OpenTelemetry tracing for the driver. Spans are created
+// for schema inspection, diff computation and each executed statement.
+func WithTracing(cfg sqltrace.Config) Option {
+	return func(o *options) {
+		o.trace = &cfg
+	}
+}
+
+// WithMetrics reports the events of schema inspections, diff computations, planning,
+// and each executed statement of the driver to the given Recorder. For example, the
+// sqlmetrics.Collector exposes them as Prometheus metrics.
+func WithMetrics(r sqlmetrics.Recorder) Option {
+	return func(o *options) {
+		o.metrics = r
+	}
+}
+
+// WithVersion overrides the Oracle version that is detected on Open (e.g. "19.0.0").
+func WithVersion(v string) Option {
+	return func(o *options) {
+		o.version = v
+	}
+}
+
+// WithReadOnly opens the driver in read-only mode. In this mode, all statements that
+// may write to the database are rejected with a schema.ReadOnlyError before they are
+// sent to it, and the driver can be used only for inspecting the database. Note that
+// this is not a replacement for connecting with a read-only credential, but a guard
+// against writes that are attempted by the driver or by its users.
+func WithReadOnly(b bool) Option {
+	return func(o *options) {
+		o.readOnly = b
+	}
+}
+
+// WithStatementLog calls f with each statement (and its arguments) that is
+// executed or queried by the driver, before it is sent to the database.
+func WithStatementLog(f func(ctx context.Context, stmt string, args []interface{})) Option {
+	return func(o *options) {
+		o.log = f
+	}
+}
+
+// supportsBool reports if the connected database supports
+// the BOOLEAN type in tables (23c).
+func (c *conn) supportsBool() bool {
+	return c.gteV("23.0.0")
+}
+
+// gteV reports if the connection version is >= w.
+func (c *conn) gteV(w string) bool {
+	return semver.Compare("v"+c.version, "v"+w) >= 0
+}
+
+// shortVersion trims the Oracle version (e.g. "19.0.0.0.0")
+// to its first three parts, in order to compare it as semver.
+func shortVersion(v string) string {
+	parts := strings.Split(v, ".")
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+	return strings.Join(parts, ".")
+}
+
+// Oracle built-in data types.
+// https://docs.oracle.com/en/database/oracle/oracle-database/19/sqlrf/Data-Types.html
+const (
+	TypeNumber      = "number"
+	TypeFloat       = "float"
+	TypeBinaryFloat = "binary_float"
+	TypeBinaryDbl   = "binary_double"
+	TypeInteger     = "integer"
+	TypeSmallInt    = "smallint"
+	TypeChar        = "char"
+	TypeNChar       = "nchar"
+	TypeVarchar2    = "varchar2"
+	TypeNVarchar2   = "nvarchar2"
+	TypeClob        = "clob"
+	TypeNClob       = "nclob"
+	TypeLong        = "long"
+	TypeRaw         = "raw"
+	TypeBlob        = "blob"
+	TypeDate        = "date"
+	TypeTimestamp   = "timestamp"
+	TypeTimestampTZ = "timestamp with time zone"
+	TypeTimestampLZ = "timestamp with local time zone"
+	TypeBoolean     = "boolean"
+	TypeJSON        = "json"
+	TypeRowID       = "rowid"
+)
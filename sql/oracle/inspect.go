@@ -0,0 +1,493 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// An inspect provides an Oracle implementation for schema.Inspector.
+type inspect struct{ conn }
+
+var _ schema.Inspector = (*inspect)(nil)
+
+// InspectRealm returns schema descriptions of all resources in the given realm.
+// Schemas that are maintained by Oracle (e.g. SYS) are inspected only if they
+// are requested explicitly.
+func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	schemas, err := i.schemas(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	realm := &schema.Realm{Schemas: schemas}
+	for _, s := range schemas {
+		if err := i.inspectTables(ctx, s, nil); err != nil {
+			return nil, err
+		}
+		s.Realm = realm
+	}
+	sqlx.LinkSchemaTables(schemas)
+	return realm, nil
+}
+
+// InspectSchema returns schema descriptions of the tables in the given schema.
+// If the schema name is empty, the current schema of the connection is used.
+func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
+	if name == "" {
+		name = i.schema
+	}
+	schemas, err := i.schemas(ctx, &schema.InspectRealmOption{Schemas: []string{name}})
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, &schema.NotExistError{
+			Err: fmt.Errorf("oracle: schema %q was not found", name),
+		}
+	}
+	s := schemas[0]
+	if err := i.inspectTables(ctx, s, opts); err != nil {
+		return nil, err
+	}
+	sqlx.LinkSchemaTables(schemas)
+	s.Realm = &schema.Realm{Schemas: schemas}
+	return s, nil
+}
+
+// inspectTables inspects and appends the tables of the given schema.
+func (i *inspect) inspectTables(ctx context.Context, s *schema.Schema, opts *schema.InspectOptions) error {
+	tables, err := i.tables(ctx, s.Name, opts)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		t.Schema = s
+		if err := i.columns(ctx, t); err != nil {
+			return err
+		}
+		if err := i.indexes(ctx, t); err != nil {
+			return err
+		}
+		if err := i.fks(ctx, t); err != nil {
+			return err
+		}
+		if err := i.checks(ctx, t); err != nil {
+			return err
+		}
+		s.Tables = append(s.Tables, t)
+	}
+	return nil
+}
+
+// schemas returns the list of the schemas in the database.
+func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
+	var (
+		args  []interface{}
+		query = schemasQuery
+	)
+	if opts != nil && len(opts.Schemas) > 0 {
+		query, args = inStrings(opts.Schemas, schemasQueryArgs, args)
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("oracle: querying schemas: %w", err)
+	}
+	names, err := sqlx.ScanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("oracle: scanning schema names: %w", err)
+	}
+	schemas := make([]*schema.Schema, 0, len(names))
+	for _, name := range names {
+		schemas = append(schemas, &schema.Schema{Name: name})
+	}
+	return schemas, nil
+}
+
+// tables returns the tables of the given schema.
+func (i *inspect) tables(ctx context.Context, ns string, opts *schema.InspectOptions) ([]*schema.Table, error) {
+	query, args := tablesQuery, []interface{}{ns}
+	if opts != nil && len(opts.Tables) > 0 {
+		query, args = inStrings(opts.Tables, tablesQueryArgs, args)
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("oracle: querying schema tables: %w", err)
+	}
+	defer rows.Close()
+	var tables []*schema.Table
+	for rows.Next() {
+		var (
+			name    string
+			comment sql.NullString
+		)
+		if err := rows.Scan(&name, &comment); err != nil {
+			return nil, fmt.Errorf("oracle: scanning table: %w", err)
+		}
+		t := &schema.Table{Name: name}
+		if sqlx.ValidString(comment) {
+			t.Attrs = append(t.Attrs, &schema.Comment{Text: comment.String})
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// columns queries and appends the columns of the given table.
+func (i *inspect) columns(ctx context.Context, t *schema.Table) error {
+	rows, err := i.QueryContext(ctx, columnsQuery, t.Schema.Name, t.Name)
+	if err != nil {
+		return fmt.Errorf("oracle: querying %q columns: %w", t.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := addColumn(t, rows); err != nil {
+			return fmt.Errorf("oracle: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+// addColumn scans the current row and adds a new column from it to the table.
+func addColumn(t *schema.Table, rows *sql.Rows) error {
+	var (
+		size, charSize, precision, scale                      sql.NullInt64
+		name, typ, charUsed, nullable, defaults, gen, comment sql.NullString
+	)
+	if err := rows.Scan(&name, &typ, &size, &charSize, &charUsed, &precision, &scale, &nullable, &defaults, &gen, &comment); err != nil {
+		return err
+	}
+	c := &schema.Column{
+		Name: name.String,
+		Type: &schema.ColumnType{
+			Raw:  typ.String,
+			Null: nullable.String == "Y",
+			Type: columnType(&columnDesc{
+				typ:          typ.String,
+				size:         size.Int64,
+				charSize:     charSize.Int64,
+				precision:    precision.Int64,
+				scale:        scale.Int64,
+				hasPrecision: precision.Valid,
+				hasScale:     scale.Valid,
+			}),
+		},
+	}
+	// The length of NCHAR and NVARCHAR2 is always measured in characters.
+	if s := strings.ToLower(typ.String); charUsed.String == "C" && (s == TypeChar || s == TypeVarchar2) {
+		c.Attrs = append(c.Attrs, &CharSemantics{})
+	}
+	switch {
+	// The DEFAULT of identity columns is the sequence they use.
+	case sqlx.ValidString(gen):
+		c.Attrs = append(c.Attrs, &Identity{Generation: gen.String})
+	case sqlx.ValidString(defaults):
+		if x := strings.TrimSpace(defaults.String); x != "" && !strings.EqualFold(x, "NULL") {
+			c.Default = defaultExpr(x)
+		}
+	}
+	if sqlx.ValidString(comment) {
+		c.Attrs = append(c.Attrs, &schema.Comment{Text: comment.String})
+	}
+	t.Columns = append(t.Columns, c)
+	return nil
+}
+
+// defaultExpr returns the schema expression of the given DEFAULT clause.
+func defaultExpr(x string) schema.Expr {
+	switch {
+	case sqlx.IsLiteralNumber(x), sqlx.IsQuoted(x, '\''):
+		return &schema.Literal{V: x}
+	default:
+		return &schema.RawExpr{X: x}
+	}
+}
+
+// indexes queries and appends the indexes of the given table.
+func (i *inspect) indexes(ctx context.Context, t *schema.Table) error {
+	rows, err := i.QueryContext(ctx, indexesQuery, t.Schema.Name, t.Name)
+	if err != nil {
+		return fmt.Errorf("oracle: querying %q indexes: %w", t.Name, err)
+	}
+	defer rows.Close()
+	if err := addIndexes(t, rows); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+// reQuotedIdent matches a quoted identifier in index expressions.
+var reQuotedIdent = regexp.MustCompile(`^"([^"]+)"$`)
+
+// addIndexes scans the rows and adds the indexes to the table.
+func addIndexes(t *schema.Table, rows *sql.Rows) error {
+	names := make(map[string]*schema.Index)
+	for rows.Next() {
+		var (
+			name, typ, uniq                string
+			contype, column, descend, expr sql.NullString
+		)
+		if err := rows.Scan(&name, &typ, &uniq, &contype, &column, &descend, &expr); err != nil {
+			return fmt.Errorf("oracle: scanning indexes for table %q: %w", t.Name, err)
+		}
+		idx, ok := names[name]
+		if !ok {
+			idx = &schema.Index{
+				Name:   name,
+				Unique: uniq == "UNIQUE",
+				Table:  t,
+			}
+			if strings.HasSuffix(typ, "BITMAP") {
+				idx.Attrs = append(idx.Attrs, &IndexType{T: "BITMAP"})
+			}
+			names[name] = idx
+			switch contype.String {
+			case "P":
+				t.PrimaryKey = idx
+			case "U":
+				idx.Attrs = append(idx.Attrs, &ConType{T: "U"})
+				fallthrough
+			default:
+				t.Indexes = append(t.Indexes, idx)
+			}
+		}
+		part := &schema.IndexPart{SeqNo: len(idx.Parts) + 1, Desc: descend.String == "DESC"}
+		// Descending and function-based key parts are stored as expressions
+		// on hidden columns. Descending columns are quoted identifiers.
+		if sqlx.ValidString(expr) {
+			x := strings.TrimSpace(expr.String)
+			if m := reQuotedIdent.FindStringSubmatch(x); m != nil {
+				column.String = m[1]
+			} else {
+				part.X = &schema.RawExpr{X: x}
+				column.String = ""
+			}
+		}
+		if column.String != "" {
+			part.C, ok = t.Column(column.String)
+			if !ok {
+				return fmt.Errorf("oracle: column %q was not found for index %q", column.String, idx.Name)
+			}
+			part.C.Indexes = append(part.C.Indexes, idx)
+		} else if part.X == nil {
+			return fmt.Errorf("oracle: invalid part for index %q", idx.Name)
+		}
+		idx.Parts = append(idx.Parts, part)
+	}
+	return nil
+}
+
+// fks queries and appends the foreign keys of the given table.
+func (i *inspect) fks(ctx context.Context, t *schema.Table) error {
+	rows, err := i.QueryContext(ctx, fksQuery, t.Schema.Name, t.Name)
+	if err != nil {
+		return fmt.Errorf("oracle: querying %q foreign keys: %w", t.Name, err)
+	}
+	defer rows.Close()
+	if err := sqlx.ScanFKs(t, rows); err != nil {
+		return fmt.Errorf("oracle: %w", err)
+	}
+	return rows.Err()
+}
+
+// reNotNull matches the system-generated CHECK constraints of NOT NULL columns.
+var reNotNull = regexp.MustCompile(`^"[^"]+" IS NOT NULL$`)
+
+// checks queries and appends the check constraints of the given table.
+func (i *inspect) checks(ctx context.Context, t *schema.Table) error {
+	rows, err := i.QueryContext(ctx, checksQuery, t.Schema.Name, t.Name)
+	if err != nil {
+		return fmt.Errorf("oracle: querying %q check constraints: %w", t.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, clause, generated sql.NullString
+		if err := rows.Scan(&name, &clause, &generated); err != nil {
+			return fmt.Errorf("oracle: scanning check: %w", err)
+		}
+		x := strings.TrimSpace(clause.String)
+		// NOT NULL constraints are reported by the column nullability.
+		if generated.String == "GENERATED NAME" && reNotNull.MatchString(x) {
+			continue
+		}
+		t.Attrs = append(t.Attrs, &schema.Check{Name: name.String, Expr: x})
+	}
+	return rows.Err()
+}
+
+// inStrings writes the "IN" (or "=") clause of the given strings
+// to the query, and appends them to the positional arguments.
+func inStrings(s []string, query string, args []interface{}) (string, []interface{}) {
+	var b strings.Builder
+	switch len(s) {
+	case 1:
+		args = append(args, s[0])
+		b.WriteString("= :")
+		b.WriteString(strconv.Itoa(len(args)))
+	default:
+		b.WriteString("IN (")
+		for i := range s {
+			args = append(args, s[i])
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(len(args)))
+		}
+		b.WriteByte(')')
+	}
+	return fmt.Sprintf(query, b.String()), args
+}
+
+type (
+	// Identity defines an identity column (12c and above).
+	// Generation is either "ALWAYS" or "BY DEFAULT".
+	Identity struct {
+		schema.Attr
+		Generation string
+	}
+
+	// CharSemantics is a column attribute for CHAR and VARCHAR2 columns that
+	// measure their length in characters, and not in bytes (e.g. VARCHAR2(10 CHAR)).
+	CharSemantics struct {
+		schema.Attr
+	}
+
+	// IndexType represents an index type other than the default B-tree (e.g. BITMAP).
+	IndexType struct {
+		schema.Attr
+		T string
+	}
+
+	// ConType describes the type of the constraint that an index was created for.
+	// For example, "U" for UNIQUE constraints, which are dropped using DROP CONSTRAINT.
+	ConType struct {
+		schema.Attr
+		T string
+	}
+)
+
+const (
+	// Query to get the database version and the current schema.
+	paramsQuery = `SELECT VERSION, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM PRODUCT_COMPONENT_VERSION WHERE PRODUCT LIKE 'Oracle Database%'`
+
+	// Query to list the schemas (users) that are not maintained by Oracle.
+	schemasQuery = `SELECT USERNAME FROM ALL_USERS WHERE ORACLE_MAINTAINED = 'N' ORDER BY USERNAME`
+
+	// Query to list specific schemas.
+	schemasQueryArgs = `SELECT USERNAME FROM ALL_USERS WHERE USERNAME %s ORDER BY USERNAME`
+
+	// Query to list the tables of a schema. Nested, secondary, overflow and dropped
+	// (i.e. in the recycle bin) tables are not managed directly and are skipped.
+	tablesQuery = `
+SELECT
+	t.TABLE_NAME,
+	c.COMMENTS
+FROM ALL_TABLES t
+LEFT JOIN ALL_TAB_COMMENTS c ON c.OWNER = t.OWNER AND c.TABLE_NAME = t.TABLE_NAME
+WHERE
+	t.OWNER = :1
+	AND t.NESTED = 'NO'
+	AND t.SECONDARY = 'N'
+	AND t.DROPPED = 'NO'
+	AND (t.IOT_TYPE IS NULL OR t.IOT_TYPE = 'IOT')
+ORDER BY t.TABLE_NAME
+`
+
+	tablesQueryArgs = `
+SELECT
+	t.TABLE_NAME,
+	c.COMMENTS
+FROM ALL_TABLES t
+LEFT JOIN ALL_TAB_COMMENTS c ON c.OWNER = t.OWNER AND c.TABLE_NAME = t.TABLE_NAME
+WHERE
+	t.OWNER = :1
+	AND t.NESTED = 'NO'
+	AND t.SECONDARY = 'N'
+	AND t.DROPPED = 'NO'
+	AND (t.IOT_TYPE IS NULL OR t.IOT_TYPE = 'IOT')
+	AND t.TABLE_NAME %s
+ORDER BY t.TABLE_NAME
+`
+
+	// Query to list table columns. Hidden columns are not part of ALL_TAB_COLUMNS.
+	columnsQuery = `
+SELECT
+	c.COLUMN_NAME,
+	c.DATA_TYPE,
+	c.DATA_LENGTH,
+	c.CHAR_LENGTH,
+	c.CHAR_USED,
+	c.DATA_PRECISION,
+	c.DATA_SCALE,
+	c.NULLABLE,
+	c.DATA_DEFAULT,
+	i.GENERATION_TYPE,
+	m.COMMENTS
+FROM ALL_TAB_COLUMNS c
+LEFT JOIN ALL_TAB_IDENTITY_COLS i ON i.OWNER = c.OWNER AND i.TABLE_NAME = c.TABLE_NAME AND i.COLUMN_NAME = c.COLUMN_NAME
+LEFT JOIN ALL_COL_COMMENTS m ON m.OWNER = c.OWNER AND m.TABLE_NAME = c.TABLE_NAME AND m.COLUMN_NAME = c.COLUMN_NAME
+WHERE c.OWNER = :1 AND c.TABLE_NAME = :2
+ORDER BY c.COLUMN_ID
+`
+
+	// Query to list table indexes, including the ones that were
+	// created for PRIMARY KEY and UNIQUE constraints.
+	indexesQuery = `
+SELECT
+	i.INDEX_NAME,
+	i.INDEX_TYPE,
+	i.UNIQUENESS,
+	c.CONSTRAINT_TYPE,
+	ic.COLUMN_NAME,
+	ic.DESCEND,
+	e.COLUMN_EXPRESSION
+FROM ALL_INDEXES i
+JOIN ALL_IND_COLUMNS ic ON ic.INDEX_OWNER = i.OWNER AND ic.INDEX_NAME = i.INDEX_NAME
+LEFT JOIN ALL_IND_EXPRESSIONS e ON e.INDEX_OWNER = ic.INDEX_OWNER AND e.INDEX_NAME = ic.INDEX_NAME AND e.COLUMN_POSITION = ic.COLUMN_POSITION
+LEFT JOIN ALL_CONSTRAINTS c ON c.OWNER = i.TABLE_OWNER AND c.TABLE_NAME = i.TABLE_NAME AND c.INDEX_NAME = i.INDEX_NAME AND c.CONSTRAINT_TYPE IN ('P', 'U')
+WHERE i.TABLE_OWNER = :1 AND i.TABLE_NAME = :2 AND i.INDEX_TYPE <> 'LOB'
+ORDER BY i.INDEX_NAME, ic.COLUMN_POSITION
+`
+
+	// Query to list table foreign keys. Oracle does not support ON UPDATE actions.
+	fksQuery = `
+SELECT
+	c.CONSTRAINT_NAME,
+	c.TABLE_NAME,
+	cc.COLUMN_NAME,
+	c.OWNER,
+	rc.TABLE_NAME AS REFERENCED_TABLE_NAME,
+	rcc.COLUMN_NAME AS REFERENCED_COLUMN_NAME,
+	rc.OWNER AS REFERENCED_SCHEMA_NAME,
+	'NO ACTION' AS UPDATE_RULE,
+	c.DELETE_RULE
+FROM ALL_CONSTRAINTS c
+JOIN ALL_CONS_COLUMNS cc ON cc.OWNER = c.OWNER AND cc.CONSTRAINT_NAME = c.CONSTRAINT_NAME
+JOIN ALL_CONSTRAINTS rc ON rc.OWNER = c.R_OWNER AND rc.CONSTRAINT_NAME = c.R_CONSTRAINT_NAME
+JOIN ALL_CONS_COLUMNS rcc ON rcc.OWNER = rc.OWNER AND rcc.CONSTRAINT_NAME = rc.CONSTRAINT_NAME AND rcc.POSITION = cc.POSITION
+WHERE c.CONSTRAINT_TYPE = 'R' AND c.OWNER = :1 AND c.TABLE_NAME = :2
+ORDER BY c.CONSTRAINT_NAME, cc.POSITION
+`
+
+	// Query to list table check constraints. The system-generated constraints
+	// of NOT NULL columns are filtered on scan, as SEARCH_CONDITION is a LONG.
+	checksQuery = `
+SELECT
+	CONSTRAINT_NAME,
+	SEARCH_CONDITION,
+	GENERATED
+FROM ALL_CONSTRAINTS
+WHERE CONSTRAINT_TYPE = 'C' AND OWNER = :1 AND TABLE_NAME = :2
+ORDER BY CONSTRAINT_NAME
+`
+)
@@ -0,0 +1,238 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package oracle
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectSchema(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("19.0.0.0.0")
+	mk.ExpectQuery(sqltest.Escape(`SELECT USERNAME FROM ALL_USERS WHERE USERNAME = :1 ORDER BY USERNAME`)).
+		WithArgs("APP").
+		WillReturnRows(sqltest.Rows(`
+ USERNAME
+----------
+ APP
+`))
+	mk.ExpectQuery(sqltest.Escape(tablesQuery)).
+		WithArgs("APP").
+		WillReturnRows(sqltest.Rows(`
+ TABLE_NAME | COMMENTS
+------------+----------
+ USERS      | app users
+`))
+	mk.ExpectQuery(sqltest.Escape(columnsQuery)).
+		WithArgs("APP", "USERS").
+		WillReturnRows(sqltest.Rows(`
+ COLUMN_NAME | DATA_TYPE                   | DATA_LENGTH | CHAR_LENGTH | CHAR_USED | DATA_PRECISION | DATA_SCALE | NULLABLE | DATA_DEFAULT | GENERATION_TYPE | COMMENTS
+-------------+-----------------------------+-------------+-------------+-----------+----------------+------------+----------+--------------+-----------------+----------
+ ID          | NUMBER                      | 22          | 0           | nil       | nil            | 0          | N        | nil          | BY DEFAULT      | nil
+ NAME        | VARCHAR2                    | 400         | 100         | C         | nil            | nil        | Y        | 'a8m'        | nil             | user name
+ CODE        | CHAR                        | 2           | 2           | B         | nil            | nil        | N        | nil          | nil             | nil
+ AMOUNT      | NUMBER                      | 22          | 0           | nil       | 10             | 2          | Y        | 0            | nil             | nil
+ RATIO       | NUMBER                      | 22          | 0           | nil       | nil            | nil        | Y        | nil          | nil             | nil
+ SCORE       | FLOAT                       | 22          | 0           | nil       | 126            | nil        | Y        | nil          | nil             | nil
+ DATA        | BLOB                        | 4000        | 0           | nil       | nil            | nil        | Y        | nil          | nil             | nil
+ TOKEN       | RAW                         | 16          | 0           | nil       | nil            | nil        | Y        | SYS_GUID()   | nil             | nil
+ CREATED     | TIMESTAMP(6) WITH TIME ZONE | 13          | 0           | nil       | nil            | 6          | N        | SYSTIMESTAMP | nil             | nil
+ BIRTHDAY    | DATE                        | 7           | 0           | nil       | nil            | nil        | Y        | nil          | nil             | nil
+ GEO         | SDO_GEOMETRY                | 1           | 0           | nil       | nil            | nil        | Y        | nil          | nil             | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(indexesQuery)).
+		WithArgs("APP", "USERS").
+		WillReturnRows(sqltest.Rows(`
+ INDEX_NAME   | INDEX_TYPE            | UNIQUENESS | CONSTRAINT_TYPE | COLUMN_NAME  | DESCEND | COLUMN_EXPRESSION
+--------------+-----------------------+------------+-----------------+--------------+---------+-------------------
+ SYS_C0012345 | NORMAL                | UNIQUE     | P               | ID           | ASC     | nil
+ USERS_CODE   | BITMAP                | NONUNIQUE  | nil             | CODE         | ASC     | nil
+ USERS_LOWER  | FUNCTION-BASED NORMAL | NONUNIQUE  | nil             | SYS_NC00012$ | ASC     | LOWER("NAME")
+ USERS_NAME   | FUNCTION-BASED NORMAL | UNIQUE     | U               | SYS_NC00013$ | DESC    | "NAME"
+`))
+	mk.ExpectQuery(sqltest.Escape(fksQuery)).
+		WithArgs("APP", "USERS").
+		WillReturnRows(sqltest.Rows(`
+ CONSTRAINT_NAME | TABLE_NAME | COLUMN_NAME | OWNER | REFERENCED_TABLE_NAME | REFERENCED_COLUMN_NAME | REFERENCED_SCHEMA_NAME | UPDATE_RULE | DELETE_RULE
+-----------------+------------+-------------+-------+-----------------------+------------------------+------------------------+-------------+-------------
+ USERS_ID_FK     | USERS      | ID          | APP   | USERS                 | ID                     | APP                    | NO ACTION   | CASCADE
+`))
+	mk.ExpectQuery(sqltest.Escape(checksQuery)).
+		WithArgs("APP", "USERS").
+		WillReturnRows(sqltest.Rows(`
+ CONSTRAINT_NAME | SEARCH_CONDITION     | GENERATED
+-----------------+----------------------+----------------
+ SYS_C0012344    | "ID" IS NOT NULL     | GENERATED NAME
+ USERS_AMOUNT    | AMOUNT > 0           | USER NAME
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s, err := drv.InspectSchema(context.Background(), "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "APP", s.Name)
+	tbl, ok := s.Table("USERS")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "app users"}, &schema.Check{Name: "USERS_AMOUNT", Expr: "AMOUNT > 0"}}, tbl.Attrs)
+
+	columns := []*schema.Column{
+		{Name: "ID", Type: &schema.ColumnType{Raw: "NUMBER", Type: &schema.IntegerType{T: TypeInteger}}, Attrs: []schema.Attr{&Identity{Generation: "BY DEFAULT"}}},
+		{Name: "NAME", Type: &schema.ColumnType{Raw: "VARCHAR2", Null: true, Type: &schema.StringType{T: TypeVarchar2, Size: 100}}, Default: &schema.Literal{V: "'a8m'"}, Attrs: []schema.Attr{&CharSemantics{}, &schema.Comment{Text: "user name"}}},
+		{Name: "CODE", Type: &schema.ColumnType{Raw: "CHAR", Type: &schema.StringType{T: TypeChar, Size: 2}}},
+		{Name: "AMOUNT", Type: &schema.ColumnType{Raw: "NUMBER", Null: true, Type: &schema.DecimalType{T: TypeNumber, Precision: 10, Scale: 2}}, Default: &schema.Literal{V: "0"}},
+		{Name: "RATIO", Type: &schema.ColumnType{Raw: "NUMBER", Null: true, Type: &schema.DecimalType{T: TypeNumber}}},
+		{Name: "SCORE", Type: &schema.ColumnType{Raw: "FLOAT", Null: true, Type: &schema.FloatType{T: TypeFloat, Precision: 126}}},
+		{Name: "DATA", Type: &schema.ColumnType{Raw: "BLOB", Null: true, Type: &schema.BinaryType{T: TypeBlob}}},
+		{Name: "TOKEN", Type: &schema.ColumnType{Raw: "RAW", Null: true, Type: &schema.BinaryType{T: TypeRaw, Size: 16}}, Default: &schema.RawExpr{X: "SYS_GUID()"}},
+		{Name: "CREATED", Type: &schema.ColumnType{Raw: "TIMESTAMP(6) WITH TIME ZONE", Type: &schema.TimeType{T: TypeTimestampTZ, Precision: 6}}, Default: &schema.RawExpr{X: "SYSTIMESTAMP"}},
+		{Name: "BIRTHDAY", Type: &schema.ColumnType{Raw: "DATE", Null: true, Type: &schema.TimeType{T: TypeDate}}},
+		{Name: "GEO", Type: &schema.ColumnType{Raw: "SDO_GEOMETRY", Null: true, Type: &schema.UnsupportedType{T: "sdo_geometry"}}},
+	}
+	require.Len(t, tbl.Columns, len(columns))
+	for i, c := range columns {
+		require.EqualValues(t, c.Name, tbl.Columns[i].Name)
+		require.EqualValues(t, c.Type, tbl.Columns[i].Type)
+		require.EqualValues(t, c.Default, tbl.Columns[i].Default)
+		require.EqualValues(t, c.Attrs, tbl.Columns[i].Attrs)
+	}
+
+	require.NotNil(t, tbl.PrimaryKey)
+	require.Equal(t, "SYS_C0012345", tbl.PrimaryKey.Name)
+	require.Equal(t, tbl.Columns[0], tbl.PrimaryKey.Parts[0].C)
+	require.Len(t, tbl.Indexes, 3)
+	require.Equal(t, "USERS_CODE", tbl.Indexes[0].Name)
+	require.Equal(t, []schema.Attr{&IndexType{T: "BITMAP"}}, tbl.Indexes[0].Attrs)
+	require.Equal(t, "USERS_LOWER", tbl.Indexes[1].Name)
+	require.Equal(t, &schema.RawExpr{X: `LOWER("NAME")`}, tbl.Indexes[1].Parts[0].X)
+	require.Equal(t, "USERS_NAME", tbl.Indexes[2].Name)
+	require.True(t, tbl.Indexes[2].Unique)
+	require.Equal(t, []schema.Attr{&ConType{T: "U"}}, tbl.Indexes[2].Attrs)
+	require.Equal(t, tbl.Columns[1], tbl.Indexes[2].Parts[0].C)
+	require.True(t, tbl.Indexes[2].Parts[0].Desc)
+
+	require.Len(t, tbl.ForeignKeys, 1)
+	fk := tbl.ForeignKeys[0]
+	require.Equal(t, "USERS_ID_FK", fk.Symbol)
+	require.Equal(t, tbl, fk.RefTable)
+	require.Equal(t, schema.Cascade, fk.OnDelete)
+	require.Equal(t, schema.NoAction, fk.OnUpdate)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDriver_InspectSchema_NotExist(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("19.0.0.0.0")
+	mk.ExpectQuery(sqltest.Escape(`SELECT USERNAME FROM ALL_USERS WHERE USERNAME = :1 ORDER BY USERNAME`)).
+		WithArgs("OTHER").
+		WillReturnRows(sqlmock.NewRows([]string{"USERNAME"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	_, err = drv.InspectSchema(context.Background(), "OTHER", nil)
+	require.True(t, schema.IsNotExistError(err))
+}
+
+func TestOpen_Version(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("11.2.0.4.0")
+	_, err = Open(db)
+	require.EqualError(t, err, `oracle: unsupported database version "11.2.0" (expect 12.1 and above)`)
+
+	mock{m}.version("11.2.0.4.0")
+	drv, err := Open(db, WithVersion("23.3.0.23.9"))
+	require.NoError(t, err)
+	require.Equal(t, "23.3.0", drv.version)
+	require.True(t, drv.supportsBool())
+}
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want schema.Type
+	}{
+		{raw: "NUMBER", want: &schema.DecimalType{T: TypeNumber}},
+		{raw: "NUMBER(10)", want: &schema.DecimalType{T: TypeNumber, Precision: 10}},
+		{raw: "NUMBER(10, 2)", want: &schema.DecimalType{T: TypeNumber, Precision: 10, Scale: 2}},
+		{raw: "NUMBER(*,2)", want: &schema.DecimalType{T: TypeNumber, Precision: 38, Scale: 2}},
+		{raw: "INTEGER", want: &schema.IntegerType{T: TypeInteger}},
+		{raw: "FLOAT(63)", want: &schema.FloatType{T: TypeFloat, Precision: 63}},
+		{raw: "BINARY_DOUBLE", want: &schema.FloatType{T: TypeBinaryDbl}},
+		{raw: "VARCHAR2(100 CHAR)", want: &schema.StringType{T: TypeVarchar2, Size: 100}},
+		{raw: "nvarchar2(10)", want: &schema.StringType{T: TypeNVarchar2, Size: 10}},
+		{raw: "CLOB", want: &schema.StringType{T: TypeClob}},
+		{raw: "RAW(16)", want: &schema.BinaryType{T: TypeRaw, Size: 16}},
+		{raw: "DATE", want: &schema.TimeType{T: TypeDate}},
+		{raw: "TIMESTAMP", want: &schema.TimeType{T: TypeTimestamp}},
+		{raw: "TIMESTAMP(3) WITH TIME ZONE", want: &schema.TimeType{T: TypeTimestampTZ, Precision: 3}},
+		{raw: "TIMESTAMP WITH LOCAL TIME ZONE", want: &schema.TimeType{T: TypeTimestampLZ}},
+		{raw: "BOOLEAN", want: &schema.BoolType{T: TypeBoolean}},
+		{raw: "XMLTYPE", want: &schema.UnsupportedType{T: "XMLTYPE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			typ, err := ParseType(tt.raw)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, typ)
+		})
+	}
+	_, err := ParseType("NUMBER(x)")
+	require.Error(t, err)
+}
+
+func TestFormatType(t *testing.T) {
+	tests := []struct {
+		typ  schema.Type
+		want string
+	}{
+		{typ: &schema.DecimalType{T: TypeNumber}, want: "NUMBER"},
+		{typ: &schema.DecimalType{T: "decimal", Precision: 10, Scale: 2}, want: "NUMBER(10,2)"},
+		{typ: &schema.IntegerType{T: "bigint"}, want: "NUMBER(19)"},
+		{typ: &schema.IntegerType{T: TypeInteger}, want: "INTEGER"},
+		{typ: &schema.FloatType{T: "double"}, want: "BINARY_DOUBLE"},
+		{typ: &schema.FloatType{T: TypeFloat, Precision: 63}, want: "FLOAT(63)"},
+		{typ: &schema.StringType{T: "varchar"}, want: "VARCHAR2(4000)"},
+		{typ: &schema.StringType{T: TypeVarchar2, Size: 255}, want: "VARCHAR2(255)"},
+		{typ: &schema.StringType{T: "text"}, want: "CLOB"},
+		{typ: &schema.BinaryType{T: "varbinary", Size: 16}, want: "RAW(16)"},
+		{typ: &schema.BinaryType{T: "longblob"}, want: "BLOB"},
+		{typ: &schema.TimeType{T: TypeTimestampTZ, Precision: 3}, want: "TIMESTAMP(3) WITH TIME ZONE"},
+		{typ: &schema.TimeType{T: "datetime"}, want: "TIMESTAMP"},
+		{typ: &schema.JSONType{T: "jsonb"}, want: "JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			f, err := FormatType(tt.typ)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, f)
+		})
+	}
+	_, err := FormatType(&schema.BinaryType{T: TypeRaw})
+	require.Error(t, err)
+	_, err = FormatType(&schema.EnumType{Values: []string{"a"}})
+	require.Error(t, err)
+}
+
+type mock struct {
+	sqlmock.Sqlmock
+}
+
+func (m mock) version(version string) {
+	m.ExpectQuery(sqltest.Escape(paramsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ VERSION    | CURRENT_SCHEMA
+------------+----------------
+ ` + version + ` | APP
+`))
+}
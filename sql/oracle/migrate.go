@@ -0,0 +1,769 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A planApply provides migration capabilities for schema elements.
+type planApply struct{ conn }
+
+// PlanChanges returns a migration plan for the given schema changes. Note that
+// DDL statements are committed implicitly in Oracle, and the returned plan is
+// never transactional.
+func (p *planApply) PlanChanges(ctx context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+	s := &state{
+		conn: p.conn,
+		Plan: migrate.Plan{
+			Name:       name,
+			Reversible: true,
+		},
+	}
+	if err := s.plan(changes); err != nil {
+		return nil, err
+	}
+	for _, c := range s.Changes {
+		if c.Reverse == "" {
+			s.Reversible = false
+		}
+	}
+	return &s.Plan, nil
+}
+
+// ApplyChanges applies the changes on the database. An error is returned
+// if the driver is unable to produce a plan to do so, or one of the statements
+// is failed or unsupported.
+func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change) error {
+	return sqlx.ApplyChanges(ctx, changes, p)
+}
+
+// SingleConn reports if the driver was opened on a single database connection.
+// It is used by sqlx.ApplyChanges to check if savepoints can be used.
+func (p *planApply) SingleConn() bool {
+	return p.single
+}
+
+// state represents the state of a planning. It is not part of
+// planApply so that multiple planning/applying can be called
+// in parallel.
+type state struct {
+	conn
+	migrate.Plan
+}
+
+// plan builds the migration plan of the changes. An error is
+// returned if one of the changes is not supported.
+func (s *state) plan(changes []schema.Change) error {
+	planned, err := s.topLevel(changes)
+	if err != nil {
+		return err
+	}
+	if planned, err = sqlx.DetachCycles(planned); err != nil {
+		return err
+	}
+	planned = sqlx.DetachAlteredReferences(planned)
+	for _, c := range planned {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			err = s.addTable(c)
+		case *schema.DropTable:
+			err = s.dropTable(c)
+		case *schema.ModifyTable:
+			err = s.modifyTable(c)
+		default:
+			err = fmt.Errorf("unsupported change %T", c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topLevel rejects the schema changes, as schemas are database
+// users in Oracle, and returns the rest of the changes.
+func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
+	planned := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			return nil, fmt.Errorf("oracle: cannot create schema %q: schemas are database users and must be created by an administrator", c.S.Name)
+		case *schema.DropSchema:
+			return nil, fmt.Errorf("oracle: cannot drop schema %q: schemas are database users and must be dropped by an administrator", c.S.Name)
+		case *schema.ModifySchema:
+			// Schemas have no attributes.
+			if len(c.Changes) > 0 {
+				return nil, fmt.Errorf("oracle: unsupported ModifySchema change: %T", c.Changes[0])
+			}
+		default:
+			planned = append(planned, c)
+		}
+	}
+	return planned, nil
+}
+
+// addTable builds the statements for creating a table, its indexes and its comments.
+func (s *state) addTable(add *schema.AddTable) error {
+	var (
+		errs []string
+		b    = Build("CREATE TABLE")
+	)
+	if sqlx.Has(add.Extra, &schema.IfNotExists{}) {
+		if !s.supportsIfExists() {
+			return fmt.Errorf("oracle: version %q does not support CREATE TABLE IF NOT EXISTS", s.version)
+		}
+		b.P("IF NOT EXISTS")
+	}
+	b.Table(add.T)
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(add.T.Columns, func(i int, b *sqlx.Builder) {
+			if err := s.column(b, add.T.Columns[i]); err != nil {
+				errs = append(errs, err.Error())
+			}
+		})
+		if pk := add.T.PrimaryKey; pk != nil {
+			b.Comma()
+			constraint(b, pk.Name).P("PRIMARY KEY")
+			indexParts(b, pk.Parts)
+		}
+		for _, idx := range add.T.Indexes {
+			if isConstraint(idx) {
+				b.Comma()
+				constraint(b, idx.Name).P("UNIQUE")
+				indexParts(b, idx.Parts)
+			}
+		}
+		for _, fk := range add.T.ForeignKeys {
+			b.Comma()
+			if err := s.fk(b, fk); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		for _, attr := range add.T.Attrs {
+			if c, ok := attr.(*schema.Check); ok {
+				b.Comma()
+				check(b, c)
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errs, ", "))
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  add,
+		Comment: fmt.Sprintf("create %q table", add.T.Name),
+		Reverse: Build("DROP TABLE").Table(add.T).String(),
+	})
+	for _, idx := range add.T.Indexes {
+		if !isConstraint(idx) {
+			s.addIndex(add, add.T, idx)
+		}
+	}
+	var c schema.Comment
+	if sqlx.Has(add.T.Attrs, &c) && c.Text != "" {
+		s.append(tableComment(add.T, c.Text, ""))
+	}
+	for _, col := range add.T.Columns {
+		if sqlx.Has(col.Attrs, &c) && c.Text != "" {
+			s.append(columnComment(add.T, col, c.Text, ""))
+		}
+	}
+	return nil
+}
+
+// dropTable builds the statement for dropping a table from a schema.
+func (s *state) dropTable(drop *schema.DropTable) error {
+	b := Build("DROP TABLE")
+	if sqlx.Has(drop.Extra, &schema.IfExists{}) {
+		if !s.supportsIfExists() {
+			return fmt.Errorf("oracle: version %q does not support DROP TABLE IF EXISTS", s.version)
+		}
+		b.P("IF EXISTS")
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.Table(drop.T).String(),
+		Source:  drop,
+		Comment: fmt.Sprintf("drop %q table", drop.T.Name),
+	})
+	return nil
+}
+
+// modifyTable builds the statements that bring the table into its modified state.
+// Unlike other databases, Oracle does not allow mixing column and constraint clauses
+// in one ALTER TABLE statement. Hence, the changes are planned in the following order:
+// dropping constraints and indexes, renaming columns, dropping, adding and modifying
+// columns, and then adding constraints, indexes and comments.
+func (s *state) modifyTable(modify *schema.ModifyTable) error {
+	var (
+		t                    = modify.T
+		drops, adds          []*migrate.Change
+		renames, comments    []*migrate.Change
+		dropC, addC, modifyC []schema.Change
+	)
+	for _, change := range modify.Changes {
+		switch change := change.(type) {
+		case *schema.AddAttr, *schema.ModifyAttr:
+			from, to, err := commentChange(change)
+			if err != nil {
+				return err
+			}
+			comments = append(comments, tableComment(t, to, from))
+		case *schema.DropAttr:
+			return fmt.Errorf("unsupported change type: %T", change)
+		case *schema.AddColumn:
+			if c := (schema.Comment{}); sqlx.Has(change.C.Attrs, &c) && c.Text != "" {
+				comments = append(comments, columnComment(t, change.C, c.Text, ""))
+			}
+			addC = append(addC, change)
+		case *schema.DropColumn:
+			dropC = append(dropC, change)
+		case *schema.ModifyColumn:
+			if change.Change.Is(schema.ChangeComment) {
+				from, to, err := commentChange(sqlx.CommentDiff(change.From.Attrs, change.To.Attrs))
+				if err != nil {
+					return err
+				}
+				comments = append(comments, columnComment(t, change.To, to, from))
+				// If only the comment of the column was changed.
+				if change.Change&^schema.ChangeComment == schema.NoChange {
+					continue
+				}
+			}
+			modifyC = append(modifyC, change)
+		case *schema.RenameColumn:
+			renames = append(renames, &migrate.Change{
+				Cmd:     Build("ALTER TABLE").Table(t).P("RENAME COLUMN").Ident(change.From.Name).P("TO").Ident(change.To.Name).String(),
+				Source:  change,
+				Reverse: Build("ALTER TABLE").Table(t).P("RENAME COLUMN").Ident(change.To.Name).P("TO").Ident(change.From.Name).String(),
+				Comment: fmt.Sprintf("rename column %q to %q in table: %q", change.From.Name, change.To.Name, t.Name),
+			})
+		case *schema.AddIndex:
+			adds = append(adds, s.indexChange(change, t, change.I))
+		case *schema.DropIndex:
+			drops = append(drops, reverseChange(s.indexChange(change, t, change.I)))
+		case *schema.ModifyIndex:
+			// Index modification requires rebuilding the index.
+			drops = append(drops, reverseChange(s.indexChange(change, t, change.From)))
+			adds = append(adds, s.indexChange(change, t, change.To))
+		case *schema.AddForeignKey:
+			c, err := s.fkChange(change, t, change.F)
+			if err != nil {
+				return err
+			}
+			adds = append(adds, c)
+		case *schema.DropForeignKey:
+			c, err := s.fkChange(change, t, change.F)
+			if err != nil {
+				return err
+			}
+			drops = append(drops, reverseChange(c))
+		case *schema.ModifyForeignKey:
+			// Foreign-key modification is translated into 2 steps.
+			// Dropping the current foreign key and creating a new one.
+			from, err := s.fkChange(change, t, change.From)
+			if err != nil {
+				return err
+			}
+			to, err := s.fkChange(change, t, change.To)
+			if err != nil {
+				return err
+			}
+			drops, adds = append(drops, reverseChange(from)), append(adds, to)
+		case *schema.AddCheck:
+			adds = append(adds, checkChange(change, t, change.C))
+		case *schema.DropCheck:
+			drops = append(drops, reverseChange(checkChange(change, t, change.C)))
+		case *schema.ModifyCheck:
+			drops = append(drops, reverseChange(checkChange(change, t, change.From)))
+			adds = append(adds, checkChange(change, t, change.To))
+		default:
+			return fmt.Errorf("unsupported change type: %T", change)
+		}
+	}
+	s.append(drops...)
+	s.append(renames...)
+	if len(dropC) > 0 {
+		s.dropColumns(t, dropC)
+	}
+	if len(addC) > 0 {
+		if err := s.addColumns(t, addC); err != nil {
+			return err
+		}
+	}
+	if len(modifyC) > 0 {
+		if err := s.modifyColumns(t, modifyC); err != nil {
+			return err
+		}
+	}
+	s.append(adds...)
+	s.append(comments...)
+	return nil
+}
+
+// dropColumns builds the statement for dropping the given columns.
+func (s *state) dropColumns(t *schema.Table, changes []schema.Change) {
+	b := Build("ALTER TABLE").Table(t).P("DROP")
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(changes, func(i int, b *sqlx.Builder) {
+			b.Ident(changes[i].(*schema.DropColumn).C.Name)
+		})
+	})
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  &schema.ModifyTable{T: t, Changes: changes},
+		Comment: fmt.Sprintf("drop columns from table: %q", t.Name),
+	})
+}
+
+// addColumns builds the statement for adding the given columns.
+func (s *state) addColumns(t *schema.Table, changes []schema.Change) error {
+	var (
+		errs    []string
+		b       = Build("ALTER TABLE").Table(t).P("ADD")
+		reverse = Build("ALTER TABLE").Table(t).P("DROP")
+	)
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(changes, func(i int, b *sqlx.Builder) {
+			if err := s.column(b, changes[i].(*schema.AddColumn).C); err != nil {
+				errs = append(errs, err.Error())
+			}
+		})
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("alter table %q: %s", t.Name, strings.Join(errs, ", "))
+	}
+	reverse.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(changes, func(i int, b *sqlx.Builder) {
+			b.Ident(changes[i].(*schema.AddColumn).C.Name)
+		})
+	})
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  &schema.ModifyTable{T: t, Changes: changes},
+		Comment: fmt.Sprintf("add columns to table: %q", t.Name),
+		Reverse: reverse.String(),
+	})
+	return nil
+}
+
+// modifyColumns builds the statements for modifying the given columns. Dropping the
+// identity of a column is executed in a separate statement, as it cannot be combined
+// with other modifications of the column.
+func (s *state) modifyColumns(t *schema.Table, changes []schema.Change) error {
+	var (
+		errs    []string
+		modify  []*schema.ModifyColumn
+		kinds   []schema.ChangeKind
+		b       = Build("ALTER TABLE").Table(t).P("MODIFY")
+		reverse = Build("ALTER TABLE").Table(t).P("MODIFY")
+	)
+	for _, c := range changes {
+		c := c.(*schema.ModifyColumn)
+		k := c.Change &^ schema.ChangeComment
+		_, fromID := identity(c.From.Attrs)
+		_, toID := identity(c.To.Attrs)
+		if k.Is(schema.ChangeAttr) && fromID != toID {
+			if !fromID {
+				return fmt.Errorf("alter table %q: identity cannot be added to existing column %q", t.Name, c.To.Name)
+			}
+			s.append(&migrate.Change{
+				Cmd:     Build("ALTER TABLE").Table(t).P("MODIFY").Wrap(func(b *sqlx.Builder) { b.Ident(c.To.Name).P("DROP IDENTITY") }).String(),
+				Source:  c,
+				Comment: fmt.Sprintf("drop identity of column %q in table: %q", c.To.Name, t.Name),
+			})
+			k &^= schema.ChangeAttr
+		}
+		if k != schema.NoChange {
+			modify, kinds = append(modify, c), append(kinds, k)
+		}
+	}
+	if len(modify) == 0 {
+		return nil
+	}
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(modify, func(i int, b *sqlx.Builder) {
+			if err := s.alterColumn(b, kinds[i], modify[i].From, modify[i].To); err != nil {
+				errs = append(errs, err.Error())
+			}
+		})
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("alter table %q: %s", t.Name, strings.Join(errs, ", "))
+	}
+	reverse.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(modify, func(i int, b *sqlx.Builder) {
+			if err := s.alterColumn(b, kinds[i], modify[i].To, modify[i].From); err != nil {
+				errs = append(errs, err.Error())
+			}
+		})
+	})
+	change := &migrate.Change{
+		Cmd:     b.String(),
+		Source:  &schema.ModifyTable{T: t, Changes: changes},
+		Comment: fmt.Sprintf("modify columns of table: %q", t.Name),
+	}
+	if len(errs) == 0 {
+		change.Reverse = reverse.String()
+	}
+	s.append(change)
+	return nil
+}
+
+// column writes the definition of the column to the builder.
+func (s *state) column(b *sqlx.Builder, c *schema.Column) error {
+	t, err := s.typeName(c)
+	if err != nil {
+		return err
+	}
+	b.Ident(c.Name).P(t)
+	if id, ok := identity(c.Attrs); ok {
+		identityClause(b, id)
+	} else if x, ok := defaultValue(c); ok {
+		b.P("DEFAULT", x)
+	}
+	if !c.Type.Null {
+		b.P("NOT NULL")
+	}
+	for _, attr := range c.Attrs {
+		switch attr.(type) {
+		case *schema.Comment, *Identity, *CharSemantics:
+		default:
+			return fmt.Errorf("unexpected attribute %T for column %q", attr, c.Name)
+		}
+	}
+	return nil
+}
+
+// alterColumn writes the modification of the column to the builder. Only the modified
+// parts are written, as Oracle fails to modify a column to its current nullability.
+func (s *state) alterColumn(b *sqlx.Builder, k schema.ChangeKind, from, to *schema.Column) error {
+	b.Ident(to.Name)
+	if k.Is(schema.ChangeType) {
+		t, err := s.typeName(to)
+		if err != nil {
+			return err
+		}
+		b.P(t)
+	}
+	if k.Is(schema.ChangeDefault) {
+		x, ok := defaultValue(to)
+		if !ok {
+			x = "NULL"
+		}
+		b.P("DEFAULT", x)
+	}
+	// Dropping an identity is planned in a separate statement,
+	// and the change here is a modification of its generation.
+	if id, ok := identity(to.Attrs); ok && k.Is(schema.ChangeAttr) {
+		if _, ok := identity(from.Attrs); !ok {
+			return fmt.Errorf("identity cannot be added to existing column %q", to.Name)
+		}
+		identityClause(b, id)
+	}
+	if k.Is(schema.ChangeNull) {
+		if to.Type.Null {
+			b.P("NULL")
+		} else {
+			b.P("NOT NULL")
+		}
+	}
+	return nil
+}
+
+// identityClause writes the GENERATED AS IDENTITY clause of the given identity to the builder.
+func identityClause(b *sqlx.Builder, id *Identity) {
+	g := strings.ToUpper(id.Generation)
+	if g == "" {
+		g = "BY DEFAULT"
+	}
+	b.P("GENERATED", g, "AS IDENTITY")
+}
+
+// typeName returns the formatted type of the column. BOOLEAN columns are
+// formatted as NUMBER(1) in versions that do not support the BOOLEAN type
+// in tables, and the CHAR length semantics is appended to the size of the
+// character types that use it.
+func (c *conn) typeName(col *schema.Column) (string, error) {
+	if _, ok := col.Type.Type.(*schema.BoolType); ok && !c.supportsBool() {
+		return "NUMBER(1)", nil
+	}
+	t, err := FormatType(col.Type.Type)
+	if err != nil {
+		return "", err
+	}
+	if sqlx.Has(col.Attrs, &CharSemantics{}) && strings.HasSuffix(t, ")") {
+		t = strings.TrimSuffix(t, ")") + " CHAR)"
+	}
+	return t, nil
+}
+
+// supportsIfExists reports if the connected database supports
+// the IF [NOT] EXISTS clauses in DDL statements (23c).
+func (c *conn) supportsIfExists() bool {
+	return c.gteV("23.0.0")
+}
+
+// identity returns the identity attribute of the column, if it has one.
+func identity(attrs []schema.Attr) (*Identity, bool) {
+	for _, a := range attrs {
+		if id, ok := a.(*Identity); ok {
+			return id, true
+		}
+	}
+	return nil, false
+}
+
+// defaultValue returns the DEFAULT clause of the column, if it has one.
+func defaultValue(c *schema.Column) (string, bool) {
+	switch x := c.Default.(type) {
+	case *schema.Literal:
+		switch c.Type.Type.(type) {
+		case *schema.BoolType, *schema.DecimalType, *schema.IntegerType, *schema.FloatType:
+			return x.V, true
+		default:
+			return quote(x.V), true
+		}
+	case *schema.RawExpr:
+		return x.X, true
+	default:
+		return "", false
+	}
+}
+
+// indexChange returns the statement for creating the given index,
+// or its UNIQUE constraint. The reverse statement drops it.
+func (s *state) indexChange(source schema.Change, t *schema.Table, idx *schema.Index) *migrate.Change {
+	if isConstraint(idx) {
+		b := Build("ALTER TABLE").Table(t).P("ADD")
+		constraint(b, idx.Name).P("UNIQUE")
+		indexParts(b, idx.Parts)
+		return &migrate.Change{
+			Cmd:     b.String(),
+			Source:  source,
+			Comment: fmt.Sprintf("create unique constraint %q to table: %q", idx.Name, t.Name),
+			Reverse: Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(idx.Name).String(),
+		}
+	}
+	b := Build("CREATE")
+	switch {
+	case idx.Unique:
+		b.P("UNIQUE")
+	case sqlx.Has(idx.Attrs, &IndexType{}):
+		var it IndexType
+		sqlx.Has(idx.Attrs, &it)
+		b.P(strings.ToUpper(it.T))
+	}
+	b.P("INDEX").P(object(t, idx.Name)).P("ON").Table(t)
+	indexParts(b, idx.Parts)
+	return &migrate.Change{
+		Cmd:     b.String(),
+		Source:  source,
+		Comment: fmt.Sprintf("create index %q to table: %q", idx.Name, t.Name),
+		// Indexes are dropped with their qualified name, as
+		// the DROP command is not attached to ALTER TABLE.
+		Reverse: Build("DROP INDEX").P(object(t, idx.Name)).String(),
+	}
+}
+
+// addIndex appends the statement for creating the index of a new table.
+func (s *state) addIndex(source schema.Change, t *schema.Table, idx *schema.Index) {
+	s.append(s.indexChange(source, t, idx))
+}
+
+// fkChange returns the statement for adding the given foreign key.
+// The reverse statement drops it.
+func (s *state) fkChange(source schema.Change, t *schema.Table, fk *schema.ForeignKey) (*migrate.Change, error) {
+	b := Build("ALTER TABLE").Table(t).P("ADD")
+	if err := s.fk(b, fk); err != nil {
+		return nil, fmt.Errorf("alter table %q: %w", t.Name, err)
+	}
+	return &migrate.Change{
+		Cmd:     b.String(),
+		Source:  source,
+		Comment: fmt.Sprintf("create foreign key %q to table: %q", fk.Symbol, t.Name),
+		Reverse: Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(fk.Symbol).String(),
+	}, nil
+}
+
+// fk writes the definition of the foreign key to the builder. Oracle supports
+// only the CASCADE and SET NULL actions for ON DELETE, and no ON UPDATE actions.
+func (s *state) fk(b *sqlx.Builder, fk *schema.ForeignKey) error {
+	constraint(b, fk.Symbol).P("FOREIGN KEY")
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(fk.Columns, func(i int, b *sqlx.Builder) {
+			b.Ident(fk.Columns[i].Name)
+		})
+	})
+	b.P("REFERENCES").Table(fk.RefTable)
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(fk.RefColumns, func(i int, b *sqlx.Builder) {
+			b.Ident(fk.RefColumns[i].Name)
+		})
+	})
+	switch fk.OnUpdate {
+	case "", schema.NoAction:
+	default:
+		return fmt.Errorf("foreign key %q: unsupported ON UPDATE action %q", fk.Symbol, fk.OnUpdate)
+	}
+	switch fk.OnDelete {
+	case "", schema.NoAction:
+	case schema.Cascade, schema.SetNull:
+		b.P("ON DELETE", string(fk.OnDelete))
+	default:
+		return fmt.Errorf("foreign key %q: unsupported ON DELETE action %q", fk.Symbol, fk.OnDelete)
+	}
+	return nil
+}
+
+// checkChange returns the statement for adding the given check constraint. The
+// reverse statement drops it, and it is empty if the constraint has no name.
+func checkChange(source schema.Change, t *schema.Table, c *schema.Check) *migrate.Change {
+	b := Build("ALTER TABLE").Table(t).P("ADD")
+	check(b, c)
+	change := &migrate.Change{
+		Cmd:     b.String(),
+		Source:  source,
+		Comment: fmt.Sprintf("create check constraint %q to table: %q", c.Name, t.Name),
+	}
+	if c.Name != "" {
+		change.Reverse = Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(c.Name).String()
+	}
+	return change
+}
+
+// check writes the check constraint to the builder.
+func check(b *sqlx.Builder, c *schema.Check) {
+	constraint(b, c.Name).P("CHECK")
+	b.Wrap(func(b *sqlx.Builder) {
+		b.WriteString(c.Expr)
+	})
+}
+
+// reverseChange returns a change that executes the
+// reverse statement of c, and reverses it with c.
+func reverseChange(c *migrate.Change) *migrate.Change {
+	comment := c.Comment
+	if i := strings.IndexByte(comment, ' '); i != -1 && comment[:i] == "create" {
+		comment = "drop" + comment[i:]
+	}
+	return &migrate.Change{
+		Cmd:     c.Reverse,
+		Source:  c.Source,
+		Comment: strings.Replace(comment, " to table: ", " from table: ", 1),
+		Reverse: c.Cmd,
+	}
+}
+
+// constraint writes the CONSTRAINT clause of the given name, if
+// it was not generated by the database, to the builder.
+func constraint(b *sqlx.Builder, name string) *sqlx.Builder {
+	if name != "" && !isSystemName(name) {
+		b.P("CONSTRAINT").Ident(name)
+	}
+	return b
+}
+
+// isConstraint reports if the index is created by a UNIQUE constraint.
+func isConstraint(idx *schema.Index) bool {
+	var c ConType
+	return sqlx.Has(idx.Attrs, &c) && c.T == "U"
+}
+
+func indexParts(b *sqlx.Builder, parts []*schema.IndexPart) {
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(parts, func(i int, b *sqlx.Builder) {
+			switch part := parts[i]; {
+			case part.C != nil:
+				b.Ident(part.C.Name)
+			case part.X != nil:
+				b.WriteString(part.X.(*schema.RawExpr).X)
+			}
+			if parts[i].Desc {
+				b.P("DESC")
+			}
+		})
+	})
+}
+
+func tableComment(t *schema.Table, to, from string) *migrate.Change {
+	b := Build("COMMENT ON TABLE").Table(t).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Comment: fmt.Sprintf("set comment to table: %q", t.Name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+func columnComment(t *schema.Table, c *schema.Column, to, from string) *migrate.Change {
+	b := Build("COMMENT ON COLUMN").P(object(t, t.Name) + "." + ident(c.Name)).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Comment: fmt.Sprintf("set comment to column: %q on table: %q", c.Name, t.Name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+func commentChange(c schema.Change) (from, to string, err error) {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		toC, ok := c.A.(*schema.Comment)
+		if ok {
+			to = toC.Text
+			return
+		}
+		err = fmt.Errorf("unexpected AddAttr.(%T) for comment change", c.A)
+	case *schema.ModifyAttr:
+		fromC, ok1 := c.From.(*schema.Comment)
+		toC, ok2 := c.To.(*schema.Comment)
+		if ok1 && ok2 {
+			from, to = fromC.Text, toC.Text
+			return
+		}
+		err = fmt.Errorf("unsupported ModifyAttr(%T, %T) change", c.From, c.To)
+	default:
+		err = fmt.Errorf("unexpected change %T", c)
+	}
+	return
+}
+
+func (s *state) append(c ...*migrate.Change) {
+	s.Changes = append(s.Changes, c...)
+}
+
+// Build instantiates a new builder and writes the given phrase to it.
+func Build(phrase string) *sqlx.Builder {
+	b := &sqlx.Builder{QuoteChar: '"'}
+	return b.P(phrase)
+}
+
+// object returns the name of a schema object that belongs to the schema
+// of the given table (e.g. an index), qualified with the schema name if
+// it is known.
+func object(t *schema.Table, name string) string {
+	if t.Schema != nil && t.Schema.Name != "" {
+		return ident(t.Schema.Name) + "." + ident(name)
+	}
+	return ident(name)
+}
+
+// ident returns the quoted form of the given identifier.
+func ident(s string) string {
+	return `"` + s + `"`
+}
+
+func quote(s string) string {
+	if sqlx.IsQuoted(s, '\'') {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,278 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package oracle
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChanges(t *testing.T) {
+	app := &schema.Schema{Name: "APP"}
+	users := &schema.Table{
+		Name:   "USERS",
+		Schema: app,
+		Columns: []*schema.Column{
+			{Name: "ID", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Generation: "ALWAYS"}}},
+			{Name: "NAME", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar2, Size: 100}, Null: true}, Attrs: []schema.Attr{&CharSemantics{}, &schema.Comment{Text: "user's name"}}},
+			{Name: "ACTIVE", Type: &schema.ColumnType{Type: &schema.BoolType{T: TypeBoolean}}, Default: &schema.Literal{V: "1"}},
+		},
+		Attrs: []schema.Attr{&schema.Check{Name: "USERS_NAME", Expr: "LENGTH(NAME) > 0"}},
+	}
+	users.PrimaryKey = &schema.Index{Name: "USERS_PK", Parts: []*schema.IndexPart{{C: users.Columns[0]}}}
+	users.Indexes = []*schema.Index{
+		{Name: "USERS_NAME_UQ", Unique: true, Table: users, Parts: []*schema.IndexPart{{C: users.Columns[1]}}, Attrs: []schema.Attr{&ConType{T: "U"}}},
+		{Name: "USERS_ACTIVE", Table: users, Parts: []*schema.IndexPart{{C: users.Columns[2], Desc: true}}, Attrs: []schema.Attr{&IndexType{T: "BITMAP"}}},
+	}
+	posts := &schema.Table{
+		Name:   "POSTS",
+		Schema: app,
+		Columns: []*schema.Column{
+			{Name: "ID", Type: &schema.ColumnType{Type: &schema.DecimalType{T: TypeNumber, Precision: 10}}},
+			{Name: "AUTHOR_ID", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}, Null: true}},
+		},
+	}
+	posts.PrimaryKey = &schema.Index{Name: "SYS_C0012345", Parts: []*schema.IndexPart{{C: posts.Columns[0]}}}
+	authorFK := &schema.ForeignKey{Symbol: "AUTHOR_FK", Table: posts, Columns: posts.Columns[1:], RefTable: users, RefColumns: users.Columns[:1], OnDelete: schema.SetNull}
+
+	tests := []struct {
+		changes  []schema.Change
+		version  string
+		wantPlan *migrate.Plan
+		wantErr  bool
+	}{
+		{
+			changes: []schema.Change{&schema.AddSchema{S: &schema.Schema{Name: "OTHER"}}},
+			wantErr: true,
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: users}},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "APP"."USERS" ("ID" NUMBER(19) GENERATED ALWAYS AS IDENTITY NOT NULL, "NAME" VARCHAR2(100 CHAR), "ACTIVE" NUMBER(1) DEFAULT 1 NOT NULL, CONSTRAINT "USERS_PK" PRIMARY KEY ("ID"), CONSTRAINT "USERS_NAME_UQ" UNIQUE ("NAME"), CONSTRAINT "USERS_NAME" CHECK (LENGTH(NAME) > 0))`,
+						Reverse: `DROP TABLE "APP"."USERS"`,
+					},
+					{
+						Cmd:     `CREATE BITMAP INDEX "APP"."USERS_ACTIVE" ON "APP"."USERS" ("ACTIVE" DESC)`,
+						Reverse: `DROP INDEX "APP"."USERS_ACTIVE"`,
+					},
+					{
+						Cmd:     `COMMENT ON COLUMN "APP"."USERS"."NAME" IS 'user''s name'`,
+						Reverse: `COMMENT ON COLUMN "APP"."USERS"."NAME" IS ''`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: users, Extra: []schema.Clause{&schema.IfNotExists{}}}},
+			wantErr: true,
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: &schema.Table{Name: "T", Schema: app, Columns: []*schema.Column{{Name: "B", Type: &schema.ColumnType{Type: &schema.BoolType{T: TypeBoolean}}}}}, Extra: []schema.Clause{&schema.IfNotExists{}}}},
+			version: "23.3.0",
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE IF NOT EXISTS "APP"."T" ("B" BOOLEAN NOT NULL)`,
+						Reverse: `DROP TABLE "APP"."T"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: posts}},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "APP"."POSTS" ("ID" NUMBER(10) NOT NULL, "AUTHOR_ID" NUMBER(19), PRIMARY KEY ("ID"))`,
+						Reverse: `DROP TABLE "APP"."POSTS"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.DropTable{T: posts}},
+			wantPlan: &migrate.Plan{
+				Changes: []*migrate.Change{
+					{Cmd: `DROP TABLE "APP"."POSTS"`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: posts,
+					Changes: []schema.Change{
+						&schema.AddForeignKey{F: authorFK},
+						&schema.AddColumn{C: &schema.Column{Name: "TITLE", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}}, Default: &schema.Literal{V: "untitled"}}},
+						&schema.AddColumn{C: &schema.Column{Name: "BODY", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeClob}, Null: true}, Attrs: []schema.Attr{&schema.Comment{Text: "post body"}}}},
+						&schema.AddIndex{I: &schema.Index{Name: "POSTS_AUTHOR", Parts: []*schema.IndexPart{{C: posts.Columns[1]}}}},
+						&schema.AddCheck{C: &schema.Check{Name: "POSTS_ID", Expr: "ID > 0"}},
+						&schema.AddAttr{A: &schema.Comment{Text: "blog posts"}},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "APP"."POSTS" ADD ("TITLE" VARCHAR2(255) DEFAULT 'untitled' NOT NULL, "BODY" CLOB)`,
+						Reverse: `ALTER TABLE "APP"."POSTS" DROP ("TITLE", "BODY")`,
+					},
+					{
+						Cmd:     `ALTER TABLE "APP"."POSTS" ADD CONSTRAINT "AUTHOR_FK" FOREIGN KEY ("AUTHOR_ID") REFERENCES "APP"."USERS" ("ID") ON DELETE SET NULL`,
+						Reverse: `ALTER TABLE "APP"."POSTS" DROP CONSTRAINT "AUTHOR_FK"`,
+					},
+					{
+						Cmd:     `CREATE INDEX "APP"."POSTS_AUTHOR" ON "APP"."POSTS" ("AUTHOR_ID")`,
+						Reverse: `DROP INDEX "APP"."POSTS_AUTHOR"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "APP"."POSTS" ADD CONSTRAINT "POSTS_ID" CHECK (ID > 0)`,
+						Reverse: `ALTER TABLE "APP"."POSTS" DROP CONSTRAINT "POSTS_ID"`,
+					},
+					{
+						Cmd:     `COMMENT ON COLUMN "APP"."POSTS"."BODY" IS 'post body'`,
+						Reverse: `COMMENT ON COLUMN "APP"."POSTS"."BODY" IS ''`,
+					},
+					{
+						Cmd:     `COMMENT ON TABLE "APP"."POSTS" IS 'blog posts'`,
+						Reverse: `COMMENT ON TABLE "APP"."POSTS" IS ''`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.DropIndex{I: users.Indexes[0]},
+						&schema.DropCheck{C: &schema.Check{Name: "USERS_NAME", Expr: "LENGTH(NAME) > 0"}},
+						&schema.DropColumn{C: users.Columns[2]},
+						&schema.RenameColumn{From: &schema.Column{Name: "NAME"}, To: &schema.Column{Name: "FULL_NAME"}},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "APP"."USERS" DROP CONSTRAINT "USERS_NAME_UQ"`,
+						Reverse: `ALTER TABLE "APP"."USERS" ADD CONSTRAINT "USERS_NAME_UQ" UNIQUE ("NAME")`,
+					},
+					{
+						Cmd:     `ALTER TABLE "APP"."USERS" DROP CONSTRAINT "USERS_NAME"`,
+						Reverse: `ALTER TABLE "APP"."USERS" ADD CONSTRAINT "USERS_NAME" CHECK (LENGTH(NAME) > 0)`,
+					},
+					{
+						Cmd:     `ALTER TABLE "APP"."USERS" RENAME COLUMN "NAME" TO "FULL_NAME"`,
+						Reverse: `ALTER TABLE "APP"."USERS" RENAME COLUMN "FULL_NAME" TO "NAME"`,
+					},
+					{
+						Cmd: `ALTER TABLE "APP"."USERS" DROP ("ACTIVE")`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   users.Columns[1],
+							To:     &schema.Column{Name: "NAME", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar2, Size: 200}}, Default: &schema.Literal{V: "unknown"}},
+							Change: schema.ChangeType | schema.ChangeNull | schema.ChangeDefault | schema.ChangeComment,
+						},
+						&schema.ModifyColumn{
+							From:   users.Columns[0],
+							To:     &schema.Column{Name: "ID", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+							Change: schema.ChangeAttr,
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Changes: []*migrate.Change{
+					{
+						Cmd: `ALTER TABLE "APP"."USERS" MODIFY ("ID" DROP IDENTITY)`,
+					},
+					{
+						Cmd:     `ALTER TABLE "APP"."USERS" MODIFY ("NAME" VARCHAR2(200) DEFAULT 'unknown' NOT NULL)`,
+						Reverse: `ALTER TABLE "APP"."USERS" MODIFY ("NAME" VARCHAR2(100 CHAR) DEFAULT NULL NULL)`,
+					},
+					{
+						Cmd:     `COMMENT ON COLUMN "APP"."USERS"."NAME" IS ''`,
+						Reverse: `COMMENT ON COLUMN "APP"."USERS"."NAME" IS 'user''s name'`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: posts,
+					Changes: []schema.Change{
+						&schema.AddForeignKey{F: &schema.ForeignKey{Symbol: "FK", Table: posts, Columns: posts.Columns[1:], RefTable: users, RefColumns: users.Columns[:1], OnUpdate: schema.Cascade}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: posts,
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   posts.Columns[0],
+							To:     &schema.Column{Name: "ID", Type: posts.Columns[0].Type, Attrs: []schema.Attr{&Identity{}}},
+							Change: schema.ChangeAttr,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			db, m, err := sqlmock.New()
+			require.NoError(t, err)
+			v := tt.version
+			if v == "" {
+				v = "19.0.0.0.0"
+			}
+			mock{m}.version(v)
+			drv, err := Open(db)
+			require.NoError(t, err)
+			plan, err := drv.PlanChanges(context.Background(), "plan", tt.changes)
+			if tt.wantErr {
+				require.Error(t, err, "expect plan to fail")
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, plan)
+			require.Equal(t, tt.wantPlan.Reversible, plan.Reversible)
+			require.Equal(t, tt.wantPlan.Transactional, plan.Transactional)
+			require.Len(t, plan.Changes, len(tt.wantPlan.Changes))
+			for i, c := range plan.Changes {
+				require.Equal(t, tt.wantPlan.Changes[i].Cmd, c.Cmd)
+				require.Equal(t, tt.wantPlan.Changes[i].Reverse, c.Reverse)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package ownership splits schema changes by the ownership boundaries of the teams or
+// services that share a database (e.g. in monorepos). Each team declares the schemas
+// and tables it owns, and plans only the changes within its boundary, while the changes
+// of objects that are owned by others are reported as drift, and are not applied.
+//
+//	bs := ownership.Boundaries{
+//		{Owner: "billing", Schemas: []string{"billing"}, Tables: []string{"public.invoice_*"}},
+//		{Owner: "users", Tables: []string{"public.users", "public.profiles"}},
+//	}
+//	diff, err := bs.Diff(drv, "billing", current, desired)
+//	if err != nil {
+//		return err
+//	}
+//	for _, d := range diff.Drift {
+//		log.Printf("skipped change of %q (owned by %q)", d.Table, d.Owner)
+//	}
+//	return drv.ApplyChanges(ctx, diff.Changes)
+//
+package ownership
+
+import (
+	"fmt"
+	"path"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A Boundary declares the objects that are owned by a team or a service.
+	Boundary struct {
+		// Owner of the objects, e.g. the name of the team or the service.
+		Owner string
+		// Schemas holds the patterns (see path.Match) of the schemas that are owned
+		// entirely, including the schemas themselves and all of their tables.
+		Schemas []string
+		// Tables holds the patterns (see path.Match) of the owned tables. Patterns are
+		// matched against the table name and its qualified form (e.g. "public.users").
+		// Patterns without meta characters act as an explicit list of tables.
+		Tables []string
+	}
+
+	// Boundaries holds the ownership boundaries of a database. An object that is matched
+	// by more than one boundary is owned by the first one.
+	Boundaries []*Boundary
+
+	// Diff holds the changes within an ownership boundary, and the
+	// changes of objects that are outside of it.
+	Diff struct {
+		// Changes within the boundary.
+		Changes []schema.Change
+		// Drift holds the changes outside of the boundary, that should not be
+		// applied by its owner. They are reported, and left to their owners.
+		Drift []*Drift
+	}
+
+	// Drift describes a change of an object that is outside of an ownership boundary.
+	Drift struct {
+		// Change outside of the boundary.
+		Change schema.Change
+		// Schema and Table that are modified by the change. Table
+		// is qualified with its schema name, if it is known.
+		Schema, Table string
+		// Owner of the object, or empty if it is not owned by any boundary.
+		Owner string
+	}
+)
+
+// OwnsSchema reports if the boundary owns the given schema.
+func (b *Boundary) OwnsSchema(s *schema.Schema) bool {
+	return s != nil && match(b.Schemas, s.Name)
+}
+
+// OwnsTable reports if the boundary owns the given table.
+func (b *Boundary) OwnsTable(t *schema.Table) bool {
+	if b.OwnsSchema(t.Schema) {
+		return true
+	}
+	names := []string{t.Name}
+	if t.Schema != nil && t.Schema.Name != "" {
+		names = append(names, t.Schema.Name+"."+t.Name)
+	}
+	return match(b.Tables, names...)
+}
+
+// Owner returns the boundary of the given owner, or nil if it was not declared.
+func (bs Boundaries) Owner(name string) *Boundary {
+	for _, b := range bs {
+		if b.Owner == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// Diff computes the changes between the current and the desired states of the database,
+// and splits them by the boundary of the given owner. See Split for more info.
+func (bs Boundaries) Diff(d schema.Differ, owner string, current, desired *schema.Realm) (*Diff, error) {
+	changes, err := d.RealmDiff(current, desired)
+	if err != nil {
+		return nil, err
+	}
+	return bs.Split(owner, changes)
+}
+
+// Split splits the given changes by the boundary of the given owner. Changes of schemas
+// and tables within the boundary are returned in Diff.Changes, and all other changes are
+// reported in Diff.Drift, along with the owners of the objects they modify. Note that
+// changes of unowned objects are considered as drift as well.
+func (bs Boundaries) Split(owner string, changes []schema.Change) (*Diff, error) {
+	b := bs.Owner(owner)
+	if b == nil {
+		return nil, fmt.Errorf("ownership: boundary %q was not declared", owner)
+	}
+	diff := &Diff{}
+	for _, c := range changes {
+		var (
+			s *schema.Schema
+			t *schema.Table
+		)
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			s = c.S
+		case *schema.DropSchema:
+			s = c.S
+		case *schema.ModifySchema:
+			s = c.S
+		case *schema.AddTable:
+			t = c.T
+		case *schema.DropTable:
+			t = c.T
+		case *schema.ModifyTable:
+			t = c.T
+		}
+		switch {
+		case s != nil && bs.schemaOwner(s) == b, t != nil && bs.tableOwner(t) == b:
+			diff.Changes = append(diff.Changes, c)
+			continue
+		}
+		d := &Drift{Change: c}
+		switch {
+		case s != nil:
+			d.Schema = s.Name
+			if o := bs.schemaOwner(s); o != nil {
+				d.Owner = o.Owner
+			}
+		case t != nil:
+			d.Table = t.Name
+			if t.Schema != nil && t.Schema.Name != "" {
+				d.Schema, d.Table = t.Schema.Name, t.Schema.Name+"."+t.Name
+			}
+			if o := bs.tableOwner(t); o != nil {
+				d.Owner = o.Owner
+			}
+		}
+		diff.Drift = append(diff.Drift, d)
+	}
+	return diff, nil
+}
+
+// schemaOwner returns the first boundary that owns the schema, or nil.
+func (bs Boundaries) schemaOwner(s *schema.Schema) *Boundary {
+	for _, b := range bs {
+		if b.OwnsSchema(s) {
+			return b
+		}
+	}
+	return nil
+}
+
+// tableOwner returns the first boundary that owns the table, or nil.
+func (bs Boundaries) tableOwner(t *schema.Table) *Boundary {
+	for _, b := range bs {
+		if b.OwnsTable(t) {
+			return b
+		}
+	}
+	return nil
+}
+
+// match reports if one of the names matches one of the patterns.
+func match(patterns []string, names ...string) bool {
+	for _, p := range patterns {
+		for _, n := range names {
+			if ok, _ := path.Match(p, n); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
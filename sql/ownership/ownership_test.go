@@ -0,0 +1,68 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package ownership_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/ownership"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoundaries_Split(t *testing.T) {
+	var (
+		public   = schema.New("public")
+		billing  = schema.New("billing")
+		users    = schema.NewTable("users").SetSchema(public)
+		invoices = schema.NewTable("invoice_items").SetSchema(public)
+		payments = schema.NewTable("payments").SetSchema(billing)
+		logs     = schema.NewTable("logs").SetSchema(public)
+		bs       = ownership.Boundaries{
+			{Owner: "billing", Schemas: []string{"billing*"}, Tables: []string{"public.invoice_*"}},
+			{Owner: "users", Tables: []string{"users", "profiles"}},
+		}
+		changes = []schema.Change{
+			&schema.AddSchema{S: schema.New("billing_archive")},
+			&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: schema.NewIntColumn("age", "int")}}},
+			&schema.AddTable{T: invoices},
+			&schema.DropTable{T: logs},
+			&schema.ModifyTable{T: payments},
+			&schema.DropSchema{S: schema.New("legacy")},
+		}
+	)
+	require.True(t, bs[0].OwnsTable(payments))
+	require.True(t, bs[0].OwnsTable(invoices))
+	require.False(t, bs[0].OwnsTable(users))
+	require.True(t, bs[1].OwnsTable(users))
+
+	diff, err := bs.Split("billing", changes)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{changes[0], changes[2], changes[4]}, diff.Changes)
+	require.Equal(t, []*ownership.Drift{
+		{Change: changes[1], Schema: "public", Table: "public.users", Owner: "users"},
+		{Change: changes[3], Schema: "public", Table: "public.logs"},
+		{Change: changes[5], Schema: "legacy"},
+	}, diff.Drift)
+
+	diff, err = bs.Diff(differ{changes: changes}, "users", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{changes[1]}, diff.Changes)
+	require.Len(t, diff.Drift, 5)
+	require.Equal(t, "billing", diff.Drift[0].Owner)
+
+	_, err = bs.Split("search", changes)
+	require.EqualError(t, err, `ownership: boundary "search" was not declared`)
+}
+
+type differ struct {
+	schema.Differ
+	changes []schema.Change
+}
+
+func (d differ) RealmDiff(_, _ *schema.Realm) ([]schema.Change, error) {
+	return d.changes, nil
+}
@@ -0,0 +1,378 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package policy evaluates schema changes against a set of rules that
+// declare which classes of changes are allowed in an environment. For
+// example, a production policy that forbids dropping tables and columns,
+// and requires a review for column type changes:
+//
+//	prod := &policy.Policy{
+//		Name: "production",
+//		Rules: []*policy.Rule{
+//			{Name: "tmp-tables", Action: policy.Allow, Classes: policy.Drops, Tables: []string{"tmp_*"}},
+//			{Name: "no-drops", Action: policy.Deny, Classes: policy.Drops},
+//			{Name: "type-review", Action: policy.Review, Classes: []policy.Class{policy.ChangeColumnType}},
+//		},
+//	}
+//	if vs := prod.CheckPlan(plan); vs.Denied() {
+//		return vs
+//	}
+//
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A Policy holds the rules of one environment. Changes are matched against
+	// the rules in their order, and the first rule that matches a change decides
+	// if it is allowed. Changes that do not match any rule are allowed.
+	Policy struct {
+		// Name of the policy, usually the environment it is used for.
+		Name  string
+		Rules []*Rule
+	}
+
+	// A Rule matches changes by their class and the tables they modify.
+	Rule struct {
+		// Name identifies the rule in violations.
+		Name string
+		// Action that is taken for matched changes.
+		Action Action
+		// Classes of changes that are matched by the rule. An empty list matches all classes.
+		Classes []Class
+		// Tables holds the patterns (see path.Match) of the tables that are matched by the
+		// rule. Patterns are matched against the table name and its qualified form (e.g.
+		// "public.users"). An empty list matches all changes, including schema changes.
+		Tables []string
+		// Message is an optional explanation that is added to violations.
+		Message string
+	}
+
+	// Action describes what a rule does with the changes it matches.
+	Action uint8
+
+	// Class describes a class of schema changes, such as dropping
+	// a table, or changing the type of column.
+	Class string
+
+	// A Violation describes a change that was matched by a Deny or a Review rule.
+	Violation struct {
+		Policy string
+		Rule   *Rule
+		Class  Class
+		// Table that is modified by the change, qualified with its
+		// schema name if it is known. Empty for schema changes.
+		Table string
+		// Change that caused the violation. For changes of a table,
+		// this is the change that is held by the schema.ModifyTable.
+		Change schema.Change
+		// Planned holds the planned change that was caused by Change,
+		// if the violation was reported by Policy.CheckPlan.
+		Planned *migrate.Change
+	}
+
+	// Violations is a list of violations that implements the error interface.
+	Violations []*Violation
+)
+
+// List of rule actions.
+const (
+	// Deny forbids the matched changes. It is the default action of rules.
+	Deny Action = iota
+	// Review reports the matched changes as changes that require a review.
+	Review
+	// Allow allows the matched changes, and stops their evaluation.
+	// It is used for exempting changes from the rules that follow it.
+	Allow
+)
+
+// List of change classes.
+const (
+	AddSchema           Class = "add_schema"
+	DropSchema          Class = "drop_schema"
+	ModifySchema        Class = "modify_schema"
+	AddTable            Class = "add_table"
+	DropTable           Class = "drop_table"
+	ModifyTable         Class = "modify_table" // Table attributes (e.g. comment or charset).
+	AddColumn           Class = "add_column"
+	DropColumn          Class = "drop_column"
+	RenameColumn        Class = "rename_column"
+	ChangeColumnType    Class = "change_column_type"
+	SetColumnNotNull    Class = "set_column_not_null"
+	ChangeColumnDefault Class = "change_column_default"
+	ModifyColumn        Class = "modify_column" // Other column changes (e.g. comment or collation).
+	AddIndex            Class = "add_index"
+	DropIndex           Class = "drop_index"
+	ModifyIndex         Class = "modify_index"
+	AddForeignKey       Class = "add_foreign_key"
+	DropForeignKey      Class = "drop_foreign_key"
+	ModifyForeignKey    Class = "modify_foreign_key"
+	AddCheck            Class = "add_check"
+	DropCheck           Class = "drop_check"
+	ModifyCheck         Class = "modify_check"
+	Unknown             Class = "unknown" // Changes that are not known to this package.
+)
+
+// Drops holds the classes of changes that drop schema objects.
+var Drops = []Class{DropSchema, DropTable, DropColumn, DropIndex, DropForeignKey, DropCheck}
+
+// String implements the fmt.Stringer interface.
+func (a Action) String() string {
+	switch a {
+	case Deny:
+		return "deny"
+	case Review:
+		return "review"
+	case Allow:
+		return "allow"
+	default:
+		return fmt.Sprintf("Action(%d)", a)
+	}
+}
+
+// Check evaluates the given changes against the policy, and returns
+// the violations they caused, or nil if all changes are allowed.
+func (p *Policy) Check(changes []schema.Change) Violations {
+	var vs Violations
+	for _, c := range changes {
+		vs = append(vs, p.check(c)...)
+	}
+	return vs
+}
+
+// CheckPlan evaluates the changes of the given plan against the policy, based on the
+// schema changes that caused them (i.e. migrate.Change.Source). Planned changes that
+// do not have a source are ignored, as they can not be classified.
+func (p *Policy) CheckPlan(plan *migrate.Plan) Violations {
+	var vs Violations
+	for _, c := range plan.Changes {
+		if c.Source == nil {
+			continue
+		}
+		for _, v := range p.check(c.Source) {
+			v.Planned = c
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// check evaluates one top-level change.
+func (p *Policy) check(c schema.Change) Violations {
+	var vs Violations
+	eval := func(c schema.Change, class Class, t *schema.Table) {
+		var names []string
+		if t != nil {
+			names = append(names, t.Name)
+			if t.Schema != nil && t.Schema.Name != "" {
+				names = append(names, t.Schema.Name+"."+t.Name)
+			}
+		}
+		r := p.match(class, names)
+		if r == nil || r.Action == Allow {
+			return
+		}
+		v := &Violation{Policy: p.Name, Rule: r, Class: class, Change: c}
+		if len(names) > 0 {
+			v.Table = names[len(names)-1]
+		}
+		vs = append(vs, v)
+	}
+	switch c := c.(type) {
+	case *schema.AddSchema:
+		eval(c, AddSchema, nil)
+	case *schema.DropSchema:
+		eval(c, DropSchema, nil)
+	case *schema.ModifySchema:
+		eval(c, ModifySchema, nil)
+	case *schema.AddTable:
+		eval(c, AddTable, c.T)
+	case *schema.DropTable:
+		eval(c, DropTable, c.T)
+	case *schema.ModifyTable:
+		for _, c1 := range c.Changes {
+			for _, class := range tableClasses(c1) {
+				eval(c1, class, c.T)
+			}
+		}
+	default:
+		eval(c, Unknown, nil)
+	}
+	return vs
+}
+
+// match returns the first rule that matches the change, or nil.
+func (p *Policy) match(class Class, tables []string) *Rule {
+	for _, r := range p.Rules {
+		if r.matchClass(class) && r.matchTable(tables) {
+			return r
+		}
+	}
+	return nil
+}
+
+func (r *Rule) matchClass(class Class) bool {
+	if len(r.Classes) == 0 {
+		return true
+	}
+	for _, c := range r.Classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchTable(tables []string) bool {
+	if len(r.Tables) == 0 {
+		return true
+	}
+	for _, p := range r.Tables {
+		for _, t := range tables {
+			if ok, _ := path.Match(p, t); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tableClasses returns the classes of a change that is held by a schema.ModifyTable.
+// Column modifications may have more than one class, as they can change multiple
+// properties of the column at once.
+func tableClasses(c schema.Change) []Class {
+	switch c := c.(type) {
+	case *schema.AddColumn:
+		return []Class{AddColumn}
+	case *schema.DropColumn:
+		return []Class{DropColumn}
+	case *schema.RenameColumn:
+		return []Class{RenameColumn}
+	case *schema.ModifyColumn:
+		var classes []Class
+		k := c.Change
+		if k.Is(schema.ChangeType) {
+			classes = append(classes, ChangeColumnType)
+			k &= ^schema.ChangeType
+		}
+		if k.Is(schema.ChangeNull) {
+			if c.From.Type != nil && c.From.Type.Null && c.To.Type != nil && !c.To.Type.Null {
+				classes = append(classes, SetColumnNotNull)
+			} else {
+				classes = append(classes, ModifyColumn)
+			}
+			k &= ^schema.ChangeNull
+		}
+		if k.Is(schema.ChangeDefault) {
+			classes = append(classes, ChangeColumnDefault)
+			k &= ^schema.ChangeDefault
+		}
+		if k != schema.NoChange || len(classes) == 0 {
+			classes = append(classes, ModifyColumn)
+		}
+		return dedup(classes)
+	case *schema.AddIndex:
+		return []Class{AddIndex}
+	case *schema.DropIndex:
+		return []Class{DropIndex}
+	case *schema.ModifyIndex:
+		return []Class{ModifyIndex}
+	case *schema.AddForeignKey:
+		return []Class{AddForeignKey}
+	case *schema.DropForeignKey:
+		return []Class{DropForeignKey}
+	case *schema.ModifyForeignKey:
+		return []Class{ModifyForeignKey}
+	case *schema.AddCheck:
+		return []Class{AddCheck}
+	case *schema.DropCheck:
+		return []Class{DropCheck}
+	case *schema.ModifyCheck:
+		return []Class{ModifyCheck}
+	case *schema.AddAttr, *schema.DropAttr, *schema.ModifyAttr:
+		return []Class{ModifyTable}
+	default:
+		return []Class{Unknown}
+	}
+}
+
+func dedup(classes []Class) []Class {
+	seen := make(map[Class]bool, len(classes))
+	uniq := classes[:0]
+	for _, c := range classes {
+		if !seen[c] {
+			seen[c] = true
+			uniq = append(uniq, c)
+		}
+	}
+	return uniq
+}
+
+// Error implements the error interface.
+func (v *Violation) Error() string {
+	var b strings.Builder
+	b.WriteString("policy")
+	if v.Policy != "" {
+		fmt.Fprintf(&b, " %q", v.Policy)
+	}
+	if v.Rule.Name != "" {
+		fmt.Fprintf(&b, " rule %q", v.Rule.Name)
+	}
+	fmt.Fprintf(&b, ": %s", v.Class)
+	if v.Table != "" {
+		fmt.Fprintf(&b, " on table %q", v.Table)
+	}
+	if v.Rule.Action == Deny {
+		b.WriteString(" is denied")
+	} else {
+		b.WriteString(" requires review")
+	}
+	if v.Rule.Message != "" {
+		fmt.Fprintf(&b, " (%s)", v.Rule.Message)
+	}
+	return b.String()
+}
+
+// Denied reports if one of the violations was caused by a Deny rule.
+func (vs Violations) Denied() bool {
+	for _, v := range vs {
+		if v.Rule.Action == Deny {
+			return true
+		}
+	}
+	return false
+}
+
+// Error implements the error interface.
+func (vs Violations) Error() string {
+	errs := make([]string, len(vs))
+	for i, v := range vs {
+		errs[i] = v.Error()
+	}
+	return strings.Join(errs, "; ")
+}
+
+// Nolint returns the violations that are not suppressed by the nolint directive in
+// the given directives (e.g. of a migration file, or one of its statements). Violations
+// are suppressed by the name of their rule, or by their class.
+//
+//	-- atlas:nolint no-drops
+//	DROP TABLE tmp_users;
+//
+func (vs Violations) Nolint(d migrate.Directives) Violations {
+	var kept Violations
+	for _, v := range vs {
+		if !d.Nolint(v.Rule.Name) && !d.Nolint(string(v.Class)) {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,87 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package policy_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/policy"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	var (
+		public = schema.New("public")
+		users  = schema.NewTable("users").SetSchema(public)
+		tmp    = schema.NewTable("tmp_users").SetSchema(public)
+		name   = schema.NewStringColumn("name", "varchar")
+		age    = &schema.ModifyColumn{
+			From:   schema.NewNullIntColumn("age", "int"),
+			To:     schema.NewIntColumn("age", "bigint"),
+			Change: schema.ChangeType | schema.ChangeNull | schema.ChangeComment,
+		}
+		prod = &policy.Policy{
+			Name: "production",
+			Rules: []*policy.Rule{
+				{Name: "tmp-tables", Action: policy.Allow, Classes: policy.Drops, Tables: []string{"public.tmp_*"}},
+				{Name: "no-drops", Classes: policy.Drops, Message: "use a deprecation window"},
+				{Name: "type-review", Action: policy.Review, Classes: []policy.Class{policy.ChangeColumnType, policy.SetColumnNotNull}},
+			},
+		}
+		changes = []schema.Change{
+			&schema.DropTable{T: tmp},
+			&schema.AddTable{T: schema.NewTable("pets")},
+			&schema.ModifyTable{T: users, Changes: []schema.Change{
+				&schema.DropColumn{C: name},
+				age,
+				&schema.AddIndex{I: schema.NewIndex("name")},
+			}},
+			&schema.DropSchema{S: schema.New("old")},
+		}
+	)
+	vs := prod.Check(changes)
+	require.Len(t, vs, 4)
+	require.True(t, vs.Denied())
+
+	require.Equal(t, policy.DropColumn, vs[0].Class)
+	require.Equal(t, "public.users", vs[0].Table)
+	require.Equal(t, changes[2].(*schema.ModifyTable).Changes[0], vs[0].Change)
+	require.Equal(t, `policy "production" rule "no-drops": drop_column on table "public.users" is denied (use a deprecation window)`, vs[0].Error())
+
+	require.Equal(t, policy.ChangeColumnType, vs[1].Class)
+	require.Equal(t, policy.SetColumnNotNull, vs[2].Class)
+	require.Equal(t, `policy "production" rule "type-review": set_column_not_null on table "public.users" requires review`, vs[2].Error())
+
+	require.Equal(t, policy.DropSchema, vs[3].Class)
+	require.Empty(t, vs[3].Table)
+
+	vs = prod.Check(changes[1:2])
+	require.Nil(t, vs)
+	require.False(t, vs.Denied())
+	vs = prod.Check([]schema.Change{&schema.ModifyTable{T: users, Changes: []schema.Change{age}}})
+	require.False(t, vs.Denied())
+	require.Len(t, vs, 2)
+
+	plan := &migrate.Plan{
+		Changes: []*migrate.Change{
+			{Cmd: "DROP TABLE tmp_users", Source: changes[0]},
+			{Cmd: "ALTER TABLE users DROP COLUMN name", Source: &schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropColumn{C: name}}}},
+			{Cmd: "VACUUM"},
+		},
+	}
+	vs = prod.CheckPlan(plan)
+	require.Len(t, vs, 1)
+	require.Equal(t, plan.Changes[1], vs[0].Planned)
+	require.EqualError(t, vs, `policy "production" rule "no-drops": drop_column on table "public.users" is denied (use a deprecation window)`)
+
+	vs = prod.Check(changes)
+	require.Len(t, vs.Nolint(migrate.ParseDirectives("-- atlas:nolint no-drops")), 2)
+	require.Len(t, vs.Nolint(migrate.ParseDirectives("-- atlas:nolint set_column_not_null")), 3)
+	require.Len(t, vs.Nolint(migrate.ParseDirectives("-- atlas:txmode file")), 4)
+	require.Empty(t, vs.Nolint(migrate.ParseDirectives("-- atlas:nolint")))
+}
@@ -0,0 +1,172 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// Aurora describes the Amazon Aurora PostgreSQL cluster the driver is connected to.
+// Aurora clusters report the version of PostgreSQL they are compatible with, and
+// they are detected on Open by the existence of the aurora_version function, in
+// case the driver was opened with the WithAurora option.
+// https://docs.aws.amazon.com/AmazonRDS/latest/AuroraUserGuide/AuroraPostgreSQL.Updates.html
+type Aurora struct {
+	// Version is the Aurora PostgreSQL version (e.g. "14.6.1").
+	Version string
+	// Extensions lists the extensions that are available in the
+	// cluster, as reported by the rds.extensions parameter.
+	Extensions []string
+}
+
+// Aurora returns the Aurora cluster information, and a flag that
+// indicates if the driver is connected to an Aurora PostgreSQL cluster.
+func (d *Driver) Aurora() (Aurora, bool) {
+	if d.aurora == nil {
+		return Aurora{}, false
+	}
+	return *d.aurora, true
+}
+
+// Supports reports if the given extension is available in the cluster. Extensions
+// are assumed to be available in case the cluster does not report them.
+func (a Aurora) Supports(ext string) bool {
+	if len(a.Extensions) == 0 {
+		return true
+	}
+	for _, e := range a.Extensions {
+		if strings.EqualFold(e, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// auroraVersion returns the Aurora information of the database that is connected to the given
+// connection, or nil if it is not an Aurora cluster. The function existence is checked first,
+// as calling an undefined function fails the query (and the transaction it is executed in).
+func auroraVersion(ctx context.Context, db schema.ExecQuerier) (*Aurora, error) {
+	rows, err := db.QueryContext(ctx, auroraQuery)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: querying aurora_version: %w", err)
+	}
+	var (
+		exists bool
+		exts   string
+	)
+	if err := sqlx.ScanOne(rows, &exists, &exts); err != nil {
+		return nil, fmt.Errorf("postgres: scanning aurora_version: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+	a := &Aurora{}
+	if rows, err = db.QueryContext(ctx, "SELECT aurora_version()"); err != nil {
+		return nil, fmt.Errorf("postgres: querying aurora_version: %w", err)
+	}
+	if err := sqlx.ScanOne(rows, &a.Version); err != nil {
+		return nil, fmt.Errorf("postgres: scanning aurora_version: %w", err)
+	}
+	for _, e := range strings.Split(exts, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			a.Extensions = append(a.Extensions, e)
+		}
+	}
+	return a, nil
+}
+
+var (
+	// extTypes maps the column types that are provided by extensions to their extension.
+	extTypes = map[string]string{
+		"citext":    "citext",
+		"cube":      "cube",
+		"hstore":    "hstore",
+		"ltree":     "ltree",
+		"lquery":    "ltree",
+		"isbn":      "isn",
+		"issn":      "isn",
+		"ean13":     "isn",
+		"geometry":  "postgis",
+		"geography": "postgis",
+		"raster":    "postgis_raster",
+		"vector":    "vector",
+	}
+	// extIndexTypes maps the index access methods that are provided by extensions to their extension.
+	extIndexTypes = map[string]string{
+		"bloom": "bloom",
+		"rum":   "rum",
+	}
+)
+
+// checkExtensions returns an error if one of the changes uses a column type or an index
+// access method that is provided by an extension that is not available in the cluster.
+func (s *state) checkExtensions(changes []schema.Change) error {
+	if s.aurora == nil || len(s.aurora.Extensions) == 0 {
+		return nil
+	}
+	var (
+		columns []*schema.Column
+		indexes []*schema.Index
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			columns = append(columns, c.T.Columns...)
+			indexes = append(indexes, c.T.Indexes...)
+		case *schema.ModifyTable:
+			for _, c := range c.Changes {
+				switch c := c.(type) {
+				case *schema.AddColumn:
+					columns = append(columns, c.C)
+				case *schema.ModifyColumn:
+					columns = append(columns, c.To)
+				case *schema.AddIndex:
+					indexes = append(indexes, c.I)
+				case *schema.ModifyIndex:
+					indexes = append(indexes, c.To)
+				}
+			}
+		}
+	}
+	for _, c := range columns {
+		if c.Type == nil || c.Type.Type == nil {
+			continue
+		}
+		f, err := FormatType(c.Type.Type)
+		if err != nil {
+			continue
+		}
+		if ext, ok := extTypes[extTypeName(f)]; ok && !s.aurora.Supports(ext) {
+			return fmt.Errorf("postgres: column %q: extension %q is not supported by the Aurora cluster", c.Name, ext)
+		}
+	}
+	for _, idx := range indexes {
+		var t IndexType
+		if !sqlx.Has(idx.Attrs, &t) {
+			continue
+		}
+		if ext, ok := extIndexTypes[strings.ToLower(t.T)]; ok && !s.aurora.Supports(ext) {
+			return fmt.Errorf("postgres: index %q: extension %q is not supported by the Aurora cluster", idx.Name, ext)
+		}
+	}
+	return nil
+}
+
+// extTypeName returns the base name of the given type definition,
+// without its schema qualifier, modifiers and array dimensions.
+func extTypeName(t string) string {
+	if i := strings.IndexAny(t, "(["); i != -1 {
+		t = t[:i]
+	}
+	if i := strings.LastIndexByte(t, '.'); i != -1 {
+		t = t[i+1:]
+	}
+	return strings.ToLower(strings.Trim(strings.TrimSpace(t), `"`))
+}
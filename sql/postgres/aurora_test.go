@@ -0,0 +1,96 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_Aurora(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.aurora("14.6.1", "btree_gin, citext,hstore")
+	drv, err := Open(db, WithAurora(true))
+	require.NoError(t, err)
+	a, ok := drv.Aurora()
+	require.True(t, ok)
+	require.Equal(t, Aurora{Version: "14.6.1", Extensions: []string{"btree_gin", "citext", "hstore"}}, a)
+	require.True(t, a.Supports("CITEXT"))
+	require.False(t, a.Supports("vector"))
+	require.True(t, Aurora{}.Supports("vector"))
+	require.NoError(t, m.ExpectationsWereMet())
+
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("140006")
+	m.ExpectQuery(sqltest.Escape(auroraQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"aurora", "extensions"}).AddRow(false, ""))
+	drv, err = Open(db, WithAurora(true))
+	require.NoError(t, err)
+	_, ok = drv.Aurora()
+	require.False(t, ok)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	// Aurora is not detected by default.
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("140006")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	_, ok = drv.Aurora()
+	require.False(t, ok)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestPlanChanges_AuroraExtensions(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.aurora("14.6.1", "citext")
+	drv, err := Open(db, WithAurora(true))
+	require.NoError(t, err)
+
+	users := schema.NewTable("users").
+		AddColumns(
+			schema.NewColumn("email").SetType(&UserDefinedType{T: "citext"}),
+		)
+	_, err = drv.PlanChanges(context.Background(), "", []schema.Change{&schema.AddTable{T: users}})
+	require.NoError(t, err)
+
+	_, err = drv.PlanChanges(context.Background(), "", []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddColumn{C: schema.NewColumn("embedding").SetType(&UserDefinedType{T: "vector(3)"})},
+			},
+		},
+	})
+	require.EqualError(t, err, `postgres: column "embedding": extension "vector" is not supported by the Aurora cluster`)
+
+	_, err = drv.PlanChanges(context.Background(), "", []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddIndex{I: schema.NewIndex("users_email").AddColumns(users.Columns[0]).AddAttrs(&IndexType{T: "bloom"})},
+			},
+		},
+	})
+	require.EqualError(t, err, `postgres: index "users_email": extension "bloom" is not supported by the Aurora cluster`)
+}
+
+func (m mock) aurora(version, extensions string) {
+	m.ExpectQuery(sqltest.Escape(paramsQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"setting"}).AddRow("en_US.utf8").AddRow("en_US.utf8").AddRow("140006"))
+	m.ExpectQuery(sqltest.Escape(auroraQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"aurora", "extensions"}).AddRow(true, extensions))
+	m.ExpectQuery(sqltest.Escape("SELECT aurora_version()")).
+		WillReturnRows(sqlmock.NewRows([]string{"aurora_version"}).AddRow(version))
+}
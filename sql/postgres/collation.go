@@ -0,0 +1,219 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A Collation describes a collation object that was created in a schema using the CREATE
+// COLLATION command. For example, a nondeterministic ICU collation for case-insensitive
+// comparison. Collation objects are held in the attributes of their schema, and columns
+// that use them (i.e. the schema.Collation attribute) are qualified with the schema name
+// when they are planned.
+type Collation struct {
+	schema.Attr
+	Name string
+	// Provider of the collation: "libc" (the default) or "icu".
+	Provider string
+	// Locale of the collation. For example, "und-u-ks-level2" or "en_US.utf8".
+	Locale string
+	// Nondeterministic indicates if strings that are not byte-wise equal may be
+	// considered equal by the collation (PostgreSQL 12 and above).
+	Nondeterministic bool
+}
+
+// Query for the collation objects of a schema. The ICU locale was moved to
+// colliculocale in PostgreSQL 15, and to colllocale in PostgreSQL 17.
+const collationsQuery = `
+SELECT
+	c.collname,
+	c.collprovider,
+	%s AS locale,
+	%s AS deterministic
+FROM
+	pg_catalog.pg_collation AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.collnamespace
+WHERE
+	n.nspname = $1
+ORDER BY
+	c.collname
+`
+
+// collationsQueryFor returns the query for inspecting collations of the given server version.
+func collationsQueryFor(version string) string {
+	locale, deterministic := "c.collcollate", "c.collisdeterministic"
+	switch {
+	case version >= "17":
+		locale = "COALESCE(c.colllocale, c.collcollate)"
+	case version >= "15":
+		locale = "COALESCE(c.colliculocale, c.collcollate)"
+	case version < "12":
+		deterministic = "true"
+	}
+	return fmt.Sprintf(collationsQuery, locale, deterministic)
+}
+
+// inspectCollations adds the collation objects of the schema to its attributes.
+func (i *inspect) inspectCollations(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.QueryContext(ctx, collationsQueryFor(i.version), s.Name)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q collations: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name, provider, locale string
+			deterministic          bool
+		)
+		if err := rows.Scan(&name, &provider, &locale, &deterministic); err != nil {
+			return fmt.Errorf("postgres: scanning collations: %w", err)
+		}
+		c := &Collation{Name: name, Provider: "libc", Locale: locale, Nondeterministic: !deterministic}
+		switch provider {
+		case "i":
+			c.Provider = "icu"
+		case "b":
+			c.Provider = "builtin"
+		}
+		s.Attrs = append(s.Attrs, c)
+	}
+	return rows.Close()
+}
+
+// collationDiff returns the changes of the collation objects of the schema.
+func collationDiff(from, to *schema.Schema) []schema.Change {
+	var changes []schema.Change
+	for _, c1 := range collations(from.Attrs) {
+		c2, ok := collation(to.Attrs, c1.Name)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.DropAttr{A: c1})
+		case c1.provider() != c2.provider() || c1.Locale != c2.Locale || c1.Nondeterministic != c2.Nondeterministic:
+			changes = append(changes, &schema.ModifyAttr{From: c1, To: c2})
+		}
+	}
+	for _, c2 := range collations(to.Attrs) {
+		if _, ok := collation(from.Attrs, c2.Name); !ok {
+			changes = append(changes, &schema.AddAttr{A: c2})
+		}
+	}
+	return changes
+}
+
+// collations returns the collation objects in the given attributes.
+func collations(attrs []schema.Attr) []*Collation {
+	var cs []*Collation
+	for _, a := range attrs {
+		if c, ok := a.(*Collation); ok {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// collation returns the collation object with the given name, if exists.
+func collation(attrs []schema.Attr, name string) (*Collation, bool) {
+	for _, c := range collations(attrs) {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// provider returns the provider of the collation, or its default.
+func (c *Collation) provider() string {
+	if c.Provider == "" {
+		return "libc"
+	}
+	return strings.ToLower(c.Provider)
+}
+
+// collationChange builds the statements of a collation object change in the given schema,
+// and reports if the change was a collation change. Dropped collations are deferred to the
+// end of the plan, as they may be used by the columns that are modified by it.
+func (s *state) collationChange(sc *schema.Schema, c schema.Change) bool {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		coll, ok := c.A.(*Collation)
+		if !ok {
+			return false
+		}
+		s.append(s.createCollation(sc, coll, c))
+	case *schema.DropAttr:
+		coll, ok := c.A.(*Collation)
+		if !ok {
+			return false
+		}
+		s.deferred = append(s.deferred, s.dropCollation(sc, coll, c))
+	case *schema.ModifyAttr:
+		from, ok1 := c.From.(*Collation)
+		to, ok2 := c.To.(*Collation)
+		if !ok1 || !ok2 {
+			return false
+		}
+		// Collations cannot be altered, and they are recreated.
+		s.append(s.dropCollation(sc, from, c), s.createCollation(sc, to, c))
+	default:
+		return false
+	}
+	return true
+}
+
+// createCollation returns the change for creating the collation in the schema.
+func (s *state) createCollation(sc *schema.Schema, c *Collation, source schema.Change) *migrate.Change {
+	b := s.build("CREATE COLLATION").QualifiedIdent(sc.Name, c.Name)
+	b.Wrap(func(b *sqlx.Builder) {
+		b.P("provider =", c.provider()).Comma().P("locale =", quote(c.Locale))
+		if c.Nondeterministic {
+			b.Comma().P("deterministic = false")
+		}
+	})
+	return &migrate.Change{
+		Cmd:     b.String(),
+		Source:  source,
+		Comment: fmt.Sprintf("create collation %q", c.Name),
+		Reverse: s.build("DROP COLLATION").QualifiedIdent(sc.Name, c.Name).String(),
+	}
+}
+
+// dropCollation returns the change for dropping the collation from the schema.
+func (s *state) dropCollation(sc *schema.Schema, c *Collation, source schema.Change) *migrate.Change {
+	return &migrate.Change{
+		Cmd:     s.build("DROP COLLATION").QualifiedIdent(sc.Name, c.Name).String(),
+		Source:  source,
+		Comment: fmt.Sprintf("drop collation %q", c.Name),
+		Reverse: s.createCollation(sc, c, source).Cmd,
+	}
+}
+
+// collate writes the COLLATE clause of the column to the builder. Collations that
+// are defined as objects in the schema of the table are qualified with its name.
+func (s *state) collate(b *sqlx.Builder, t *schema.Table, name string) {
+	b.P("COLLATE")
+	if t.Schema != nil && t.Schema.Name != "" {
+		if _, ok := collation(t.Schema.Attrs, name); ok {
+			b.QualifiedIdent(t.Schema.Name, name)
+			return
+		}
+	}
+	b.Ident(name)
+}
+
+// collationChanged reports if the COLLATE clause of the column was changed. Columns
+// that do not define it explicitly use the default collation of their type, and the
+// change is detected only if it is defined on both sides.
+func collationChanged(from, to []schema.Attr) bool {
+	var c1, c2 schema.Collation
+	ok1, ok2 := sqlx.Has(from, &c1), sqlx.Has(to, &c2)
+	return ok1 && ok2 && c1.V != c2.V
+}
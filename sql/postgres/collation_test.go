@@ -0,0 +1,132 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectCollations(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("150000")
+	drv, err := Open(db, WithCollationObjects(true))
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("test").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ test        |
+`))
+	mk.ExpectQuery(sqltest.Escape(collationsQueryFor("15.00.00"))).
+		WithArgs("test").
+		WillReturnRows(sqltest.Rows(`
+ collname    | collprovider | locale          | deterministic
+-------------+--------------+-----------------+---------------
+ german      | c            | de_DE.utf8      | t
+ nocase      | i            | und-u-ks-level2 | f
+`))
+	mk.tables("test")
+	s, err := drv.InspectSchema(context.Background(), "test", &schema.InspectOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{
+		&Collation{Name: "german", Provider: "libc", Locale: "de_DE.utf8"},
+		&Collation{Name: "nocase", Provider: "icu", Locale: "und-u-ks-level2", Nondeterministic: true},
+	}, s.Attrs)
+
+	require.Contains(t, collationsQueryFor("11.00.00"), "true AS deterministic")
+	require.Contains(t, collationsQueryFor("13.00.00"), "c.collcollate AS locale")
+	require.Contains(t, collationsQueryFor("17.00.00"), "c.colllocale")
+}
+
+func TestDiff_Collations(t *testing.T) {
+	var (
+		nocase  = &Collation{Name: "nocase", Provider: "icu", Locale: "und-u-ks-level2", Nondeterministic: true}
+		german  = &Collation{Name: "german", Locale: "de_DE.utf8"}
+		german2 = &Collation{Name: "german", Provider: "libc", Locale: "de_DE.utf8"}
+		numeric = &Collation{Name: "numeric", Provider: "icu", Locale: "en-u-kn-true"}
+		from    = &schema.Schema{Name: "public", Attrs: []schema.Attr{nocase, german}}
+		to      = &schema.Schema{Name: "public", Attrs: []schema.Attr{german2, numeric, &Collation{Name: "nocase", Provider: "icu", Locale: "und-u-ks-level2"}}}
+	)
+	d := &diff{}
+	require.Equal(t, []schema.Change{
+		&schema.ModifyAttr{From: nocase, To: to.Attrs[2]},
+		&schema.AddAttr{A: numeric},
+	}, d.SchemaAttrDiff(from, to))
+	require.Empty(t, d.SchemaAttrDiff(to, to))
+
+	var (
+		c1 = schema.NewStringColumn("name", "text").AddAttrs(&schema.Collation{V: "nocase"})
+		c2 = schema.NewStringColumn("name", "text").AddAttrs(&schema.Collation{V: "german"})
+		c3 = schema.NewStringColumn("name", "text")
+	)
+	k, err := d.ColumnChange(c1, c2)
+	require.NoError(t, err)
+	require.Equal(t, schema.ChangeCollation, k)
+	k, err = d.ColumnChange(c1, c3)
+	require.NoError(t, err)
+	require.Equal(t, schema.NoChange, k)
+}
+
+func TestPlanChanges_Collations(t *testing.T) {
+	var (
+		nocase = &Collation{Name: "nocase", Provider: "icu", Locale: "und-u-ks-level2", Nondeterministic: true}
+		german = &Collation{Name: "german", Locale: "de_DE.utf8"}
+		public = schema.New("public").AddAttrs(nocase)
+		users  = schema.NewTable("users").SetSchema(public).AddColumns(
+			schema.NewStringColumn("name", "text").AddAttrs(&schema.Collation{V: "nocase"}),
+			schema.NewStringColumn("title", "text").AddAttrs(&schema.Collation{V: "C"}),
+		)
+	)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifySchema{S: public, Changes: []schema.Change{
+			&schema.DropAttr{A: german},
+			&schema.AddAttr{A: nocase},
+		}},
+		&schema.AddTable{T: users},
+	})
+	require.NoError(t, err)
+	require.True(t, plan.Reversible)
+	for i, c := range []struct{ cmd, reverse string }{
+		{`CREATE COLLATION "public"."nocase" (provider = icu, locale = 'und-u-ks-level2', deterministic = false)`, `DROP COLLATION "public"."nocase"`},
+		{`CREATE TABLE "public"."users" ("name" text NOT NULL COLLATE "public"."nocase", "title" text NOT NULL COLLATE "C")`, `DROP TABLE "public"."users"`},
+		{`DROP COLLATION "public"."german"`, `CREATE COLLATION "public"."german" (provider = libc, locale = 'de_DE.utf8')`},
+	} {
+		require.Equal(t, c.cmd, plan.Changes[i].Cmd)
+		require.Equal(t, c.reverse, plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 3)
+
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddSchema{S: schema.New("app").AddAttrs(german)},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.ModifyColumn{
+				From:   schema.NewStringColumn("title", "text").AddAttrs(&schema.Collation{V: "C"}),
+				To:     users.Columns[0],
+				Change: schema.ChangeCollation,
+			},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE SCHEMA "app"`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE COLLATION "app"."german" (provider = libc, locale = 'de_DE.utf8')`, plan.Changes[1].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" ALTER COLUMN "name" TYPE text COLLATE "public"."nocase"`, plan.Changes[2].Cmd)
+}
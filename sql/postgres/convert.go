@@ -11,16 +11,44 @@ import (
 	"strconv"
 	"strings"
 
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
 )
 
+// customTypes holds the types that were registered using RegisterType.
+var customTypes sqlx.CustomTypes
+
+// RegisterType registers a custom type, or an alias of a built-in type, in the driver. The parse
+// function is called with the raw definition of columns whose type is named as the registered
+// type (e.g. "vector" or "vector(3)"), on inspection and when parsing schema specs. The format
+// function is optional, and reports if it handles the given type and returns its raw definition.
+// Types that are handled by the format function are also compared by their formatted form.
+//
+//	postgres.RegisterType("citext", func(string) (schema.Type, error) {
+//		return &postgres.UserDefinedType{T: "citext"}, nil
+//	}, nil)
+//
+// Types are expected to be registered on init, before the driver is used.
+func RegisterType(name string, parse func(raw string) (schema.Type, error), format func(schema.Type) (string, bool)) error {
+	if name == "" || parse == nil {
+		return fmt.Errorf("postgres: missing name or parse function for custom type %q", name)
+	}
+	customTypes.Register(&sqlx.CustomType{Name: name, Parse: parse, Format: format})
+	return registerSpec(name)
+}
+
 // FormatType converts schema type to its column form in the database.
 // An error is returned if the type cannot be recognized.
 func FormatType(t schema.Type) (string, error) {
+	if f, ok := customTypes.Format(t); ok {
+		return f, nil
+	}
 	var f string
 	switch t := t.(type) {
 	case *ArrayType:
 		f = strings.ToLower(t.T)
+	case *RangeType:
+		f = strings.ToLower(t.T)
 	case *BitType:
 		f = strings.ToLower(t.T)
 		// BIT without a length is equivalent to BIT(1).
@@ -39,6 +67,12 @@ func FormatType(t schema.Type) (string, error) {
 		}
 		f = t.T
 	case *schema.IntegerType:
+		// PostgreSQL does not support unsigned integer types. Hence,
+		// instead of losing the constraint silently, users are expected
+		// to convert these types using ConvertUnsigned.
+		if t.Unsigned {
+			return "", fmt.Errorf("postgres: unsigned integer types are not supported: %q (see ConvertUnsigned)", t.T)
+		}
 		switch f = strings.ToLower(t.T); f {
 		case TypeSmallInt, TypeInteger, TypeBigInt:
 		case TypeInt2:
@@ -134,14 +168,109 @@ func FormatType(t schema.Type) (string, error) {
 		f = strings.ToLower(t.T)
 	case *UserDefinedType:
 		f = strings.ToLower(t.T)
+	// Types that are unknown to the driver are passed as is.
 	case *schema.UnsupportedType:
-		return "", fmt.Errorf("postgres: unsupported type: %q", t.T)
+		if t.T == "" {
+			return "", errors.New("postgres: missing unsupported type definition")
+		}
+		f = t.T
 	default:
 		return "", fmt.Errorf("postgres: invalid schema type: %T", t)
 	}
 	return f, nil
 }
 
+// UnsignedMode defines how unsigned integer types
+// are converted to PostgreSQL types by ConvertUnsigned.
+type UnsignedMode uint8
+
+// List of unsigned conversion modes.
+const (
+	// UnsignedWiden converts unsigned integer types to the narrowest PostgreSQL
+	// type that can hold all their values. For example, "int unsigned" is
+	// converted to "bigint", and "bigint unsigned" to "numeric(20)".
+	UnsignedWiden UnsignedMode = iota + 1
+
+	// UnsignedCheck converts unsigned integer types to the PostgreSQL integer type
+	// of the same size, and adds a CHECK constraint that rejects negative values.
+	// Note that the upper half of the unsigned range cannot be stored in this mode.
+	UnsignedCheck
+
+	// UnsignedWidenCheck widens unsigned integer types as UnsignedWiden does, and
+	// adds a CHECK constraint that limits the column values to the original range.
+	UnsignedWidenCheck
+)
+
+// ConvertUnsigned converts the unsigned integer columns of the given table
+// (e.g. a table that was inspected from a MySQL database) to types that are
+// supported by PostgreSQL. The table is modified in place, and an error is
+// returned if the mode or one of the unsigned types is not recognized.
+//
+//	for _, t := range s.Tables {
+//		if err := postgres.ConvertUnsigned(t, postgres.UnsignedWidenCheck); err != nil {
+//			return err
+//		}
+//	}
+//
+func ConvertUnsigned(t *schema.Table, mode UnsignedMode) error {
+	if mode < UnsignedWiden || mode > UnsignedWidenCheck {
+		return fmt.Errorf("postgres: unknown unsigned mode: %d", mode)
+	}
+	for _, c := range t.Columns {
+		it, ok := c.Type.Type.(*schema.IntegerType)
+		if !ok || !it.Unsigned {
+			continue
+		}
+		u, ok := unsignedTypes[strings.ToLower(it.T)]
+		if !ok {
+			return fmt.Errorf("postgres: unexpected unsigned integer type %q for column %q", it.T, c.Name)
+		}
+		var expr string
+		switch mode {
+		case UnsignedWiden:
+			c.Type.Type = u.widen()
+		case UnsignedCheck:
+			c.Type.Type = &schema.IntegerType{T: u.same}
+			expr = Build("").Ident(c.Name).P(">= 0").String()
+		case UnsignedWidenCheck:
+			c.Type.Type = u.widen()
+			expr = Build("").Ident(c.Name).P("BETWEEN 0 AND", strconv.FormatUint(u.max, 10)).String()
+		}
+		c.Type.Raw = mustFormat(c.Type.Type)
+		if expr != "" {
+			t.Attrs = append(t.Attrs, &schema.Check{
+				Name: fmt.Sprintf("%s_%s_unsigned", t.Name, c.Name),
+				Expr: expr,
+			})
+		}
+	}
+	return nil
+}
+
+// unsignedType describes the PostgreSQL types that are used for emulating an unsigned integer type.
+type unsignedType struct {
+	same, wide string
+	max        uint64
+}
+
+// widen returns the PostgreSQL type that can hold all values of the unsigned type.
+func (u unsignedType) widen() schema.Type {
+	if u.wide == TypeNumeric {
+		return &schema.DecimalType{T: TypeNumeric, Precision: 20}
+	}
+	return &schema.IntegerType{T: u.wide}
+}
+
+// unsignedTypes maps the MySQL integer types to their PostgreSQL emulation.
+var unsignedTypes = map[string]unsignedType{
+	"tinyint":   {same: TypeSmallInt, wide: TypeSmallInt, max: 1<<8 - 1},
+	"smallint":  {same: TypeSmallInt, wide: TypeInteger, max: 1<<16 - 1},
+	"mediumint": {same: TypeInteger, wide: TypeInteger, max: 1<<24 - 1},
+	"int":       {same: TypeInteger, wide: TypeBigInt, max: 1<<32 - 1},
+	"integer":   {same: TypeInteger, wide: TypeBigInt, max: 1<<32 - 1},
+	"bigint":    {same: TypeBigInt, wide: TypeNumeric, max: 1<<64 - 1},
+}
+
 // mustFormat calls to FormatType and panics in case of error.
 func mustFormat(t schema.Type) string {
 	s, err := FormatType(t)
@@ -155,14 +284,17 @@ func mustFormat(t schema.Type) string {
 // The raw value is expected to follow the format in PostgreSQL information schema
 // or as an input for the CREATE TABLE statement.
 func ParseType(typ string) (schema.Type, error) {
+	if t, ok, err := customTypes.Parse(typ); ok {
+		return t, err
+	}
 	d, err := parseColumn(typ)
 	if err != nil {
 		return nil, err
 	}
 	// Normalize PostgreSQL array data types from "CREATE TABLE" format to
 	// "INFORMATION_SCHEMA" format (i.e. as it is inspected from the database).
-	if t, ok := arrayType(typ); ok {
-		d = &columnDesc{typ: TypeArray, udt: t}
+	if t, dims, ok := arrayType(typ); ok {
+		d = &columnDesc{typ: TypeArray, udt: t, dims: int64(dims)}
 	}
 	t := columnType(d)
 	// If the type is unknown (to us), we fallback to user-defined but expect
@@ -174,16 +306,21 @@ func ParseType(typ string) (schema.Type, error) {
 }
 
 // reArray parses array declaration. See: https://postgresql.org/docs/current/arrays.html.
-var reArray = regexp.MustCompile(`(?i)(\w+)\s*(?:(?:\[\d*])+|\s+ARRAY\s*(?:\[\d*])*)`)
+var reArray = regexp.MustCompile(`(?i)^\s*([^\[]+?)\s*((?:\[\d*]\s*)+|\s+ARRAY\s*(?:\[\d*])?)\s*$`)
 
-// arrayType reports if the given string is an array type (e.g. int[], text[2]),
-// and returns its "udt_name" as it was inspected from the database.
-func arrayType(t string) (string, bool) {
+// arrayType reports if the given string is an array type (e.g. int[], text[2][2]), and
+// returns its element type (i.e. "udt_name" without the '_' prefix as it was inspected
+// from the database), and the number of its declared dimensions.
+func arrayType(t string) (string, int, bool) {
 	matches := reArray.FindStringSubmatch(t)
-	if len(matches) != 2 {
-		return "", false
+	if len(matches) != 3 {
+		return "", 0, false
+	}
+	dims := strings.Count(matches[2], "[")
+	if dims == 0 {
+		dims = 1
 	}
-	return matches[1], true
+	return matches[1], dims, true
 }
 
 // columnDesc represents a column descriptor.
@@ -196,6 +333,7 @@ type columnDesc struct {
 	scale         int64
 	typtype       string
 	typid         int64
+	dims          int64
 	parts         []string
 }
 
@@ -8,9 +8,9 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
-	"unicode"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
@@ -19,10 +19,28 @@ import (
 // A diff provides a PostgreSQL implementation for sqlx.DiffDriver.
 type diff struct{ conn }
 
+// ColumnOrder reports if the order of table columns is significant.
+func (d *diff) ColumnOrder() bool {
+	return d.ordered
+}
+
+// FoldName implements the sqlx.NameFolder interface. If the driver was configured using
+// the WithLowerCaseNames option, names are folded to lower case, like PostgreSQL folds
+// unquoted identifiers. Otherwise, names are compared as-is, like quoted identifiers.
+func (d *diff) FoldName(_ schema.ObjectKind, name string) string {
+	if !d.foldNames {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
 // SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
-func (d *diff) SchemaAttrDiff(_, _ *schema.Schema) []schema.Change {
-	// No special schema attribute diffing for PostgreSQL.
-	return nil
+func (d *diff) SchemaAttrDiff(from, to *schema.Schema) []schema.Change {
+	var changes []schema.Change
+	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	return append(changes, collationDiff(from, to)...)
 }
 
 // TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
@@ -31,8 +49,32 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
 		changes = append(changes, change)
 	}
+	if tablespaceChanged(from.Attrs, to.Attrs) {
+		changes = append(changes, &schema.ModifyAttr{
+			From: tablespace(from.Attrs),
+			To:   tablespace(to.Attrs),
+		})
+	}
+	if tableParamsChanged(from.Attrs, to.Attrs) {
+		changes = append(changes, &schema.ModifyAttr{
+			From: tableParams(from.Attrs),
+			To:   tableParams(to.Attrs),
+		})
+	}
+	if inheritsChanged(from, to) {
+		changes = append(changes, &schema.ModifyAttr{
+			From: inherits(from.Attrs),
+			To:   inherits(to.Attrs),
+		})
+	}
+	if d.redshift {
+		changes = append(changes, redshiftAttrDiff(from, to)...)
+	}
 	return append(changes, sqlx.CheckDiff(from, to, func(c1, c2 *schema.Check) bool {
-		return sqlx.Has(c1.Attrs, &NoInherit{}) == sqlx.Has(c2.Attrs, &NoInherit{})
+		return sqlx.Has(c1.Attrs, &NoInherit{}) == sqlx.Has(c2.Attrs, &NoInherit{}) &&
+			// A validated constraint cannot be marked as NOT VALID again.
+			(!sqlx.Has(c1.Attrs, &NotValid{}) || sqlx.Has(c2.Attrs, &NotValid{})) &&
+			normalizeCheck(c1.Expr) == normalizeCheck(c2.Expr)
 	})...), nil
 }
 
@@ -56,9 +98,12 @@ func (d *diff) ColumnChange(from, to *schema.Column) (schema.ChangeKind, error)
 	if changed {
 		change |= schema.ChangeDefault
 	}
-	if identityChanged(from.Attrs, to.Attrs) {
+	if d.identityChanged(from.Attrs, to.Attrs) {
 		change |= schema.ChangeAttr
 	}
+	if collationChanged(from.Attrs, to.Attrs) {
+		change |= schema.ChangeCollation
+	}
 	return change, nil
 }
 
@@ -70,7 +115,7 @@ func (d *diff) defaultChanged(from, to *schema.Column) (bool, error) {
 	if ok1 != ok2 {
 		return true, nil
 	}
-	if trimCast(d1) == trimCast(d2) {
+	if NormalizeDefault(d1) == NormalizeDefault(d2) {
 		return false, nil
 	}
 	// Use database comparison in case of mismatch (e.g. `SELECT ARRAY[1] = '{1}'::int[]`).
@@ -101,26 +146,162 @@ func (d *diff) IsGeneratedIndexName(t *schema.Table, idx *schema.Index) bool {
 }
 
 // IndexAttrChanged reports if the index attributes were changed.
-// The default type is BTREE if no type was specified.
 func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
-	t1 := &IndexType{T: "BTREE"}
-	if sqlx.Has(from, t1) {
-		t1.T = strings.ToUpper(t1.T)
-	}
-	t2 := &IndexType{T: "BTREE"}
-	if sqlx.Has(to, t2) {
-		t2.T = strings.ToUpper(t2.T)
-	}
-	if t1.T != t2.T {
+	return indexAttrChanged(from, to) || tablespaceChanged(from, to)
+}
+
+// indexAttrChanged reports if the index attributes that require
+// rebuilding the index were changed. The default type is BTREE if
+// no type was specified.
+func indexAttrChanged(from, to []schema.Attr) bool {
+	t1, t2 := indexType(from), indexType(to)
+	if t1 != t2 {
 		return true
 	}
 	var p1, p2 IndexPredicate
 	if sqlx.Has(from, &p1) != sqlx.Has(to, &p2) || p1.P != p2.P {
 		return true
 	}
+	return indexParamsChanged(t1, from, to)
+}
+
+// indexType returns the access method of the index in its
+// normalized form (e.g. SPGIST for SP-GiST), or BTREE if
+// no type was specified.
+func indexType(attrs []schema.Attr) string {
+	t := &IndexType{T: "BTREE"}
+	sqlx.Has(attrs, t)
+	return strings.ReplaceAll(strings.ToUpper(t.T), "-", "")
+}
+
+// indexParamsChanged reports if the storage parameters of an index with
+// the given access method were changed, after applying their defaults.
+func indexParamsChanged(typ string, from, to []schema.Attr) bool {
+	p1, p2 := indexParams(typ, from), indexParams(typ, to)
+	return p1.FillFactor != p2.FillFactor || p1.PagesPerRange != p2.PagesPerRange ||
+		p1.AutoSummarize != p2.AutoSummarize || *p1.FastUpdate != *p2.FastUpdate
+}
+
+// indexParams returns the storage parameters of an index with
+// the given access method, with the defaults of the method.
+func indexParams(typ string, attrs []schema.Attr) *IndexStorageParams {
+	p := &IndexStorageParams{}
+	sqlx.Has(attrs, p)
+	if p.FillFactor == 0 {
+		switch typ {
+		case "BTREE", "GIST":
+			p.FillFactor = 90
+		case "HASH":
+			p.FillFactor = 75
+		case "SPGIST":
+			p.FillFactor = 80
+		}
+	}
+	if p.PagesPerRange == 0 && typ == "BRIN" {
+		p.PagesPerRange = 128
+	}
+	if p.FastUpdate == nil {
+		on := true
+		p.FastUpdate = &on
+	}
+	return p
+}
+
+// defaultTablespace is the tablespace of elements
+// that were not configured with an explicit one.
+const defaultTablespace = "pg_default"
+
+// tablespace returns the tablespace attribute from the
+// given attributes, or the default tablespace if missing.
+func tablespace(attrs []schema.Attr) *Tablespace {
+	t := &Tablespace{N: defaultTablespace}
+	sqlx.Has(attrs, t)
+	return t
+}
+
+// inherits returns the Inherits attribute from the given
+// attributes, or an empty one if the table has no parents.
+func inherits(attrs []schema.Attr) *Inherits {
+	a := &Inherits{}
+	sqlx.Has(attrs, a)
+	return a
+}
+
+// inheritsChanged reports if the parent tables were changed. Parents that are
+// defined without a schema are expected to be in the schema of their child,
+// and their schema is compared only if it is known on both sides.
+func inheritsChanged(from, to *schema.Table) bool {
+	p1, p2 := inherits(from.Attrs).T, inherits(to.Attrs).T
+	if len(p1) != len(p2) {
+		return true
+	}
+	for i := range p1 {
+		s1, s2 := parentSchema(from, p1[i]), parentSchema(to, p2[i])
+		if p1[i].Name != p2[i].Name || s1 != "" && s2 != "" && s1 != s2 {
+			return true
+		}
+	}
+	return false
+}
+
+// parentSchema returns the schema name of the parent table of t.
+func parentSchema(t, parent *schema.Table) string {
+	switch {
+	case parent.Schema != nil:
+		return parent.Schema.Name
+	case t.Schema != nil:
+		return t.Schema.Name
+	default:
+		return ""
+	}
+}
+
+// parentName returns the qualified name of the parent table of t.
+func parentName(t, parent *schema.Table) string {
+	if s := parentSchema(t, parent); s != "" {
+		return s + "." + parent.Name
+	}
+	return parent.Name
+}
+
+// qualified returns the qualified name of the table.
+func qualified(t *schema.Table) string {
+	if t.Schema != nil {
+		return t.Schema.Name + "." + t.Name
+	}
+	return t.Name
+}
+
+// tableParams returns the storage parameters attribute from the
+// given attributes, or an empty one if missing.
+func tableParams(attrs []schema.Attr) *TableStorageParams {
+	p := &TableStorageParams{}
+	for i := range attrs {
+		if a, ok := attrs[i].(*TableStorageParams); ok {
+			p = a
+		}
+	}
+	return p
+}
+
+// tableParamsChanged reports if the storage parameters of the table were changed.
+func tableParamsChanged(from, to []schema.Attr) bool {
+	p1, p2 := tableParams(from), tableParams(to)
+	for _, n := range tableParamNames {
+		v1, ok1 := p1.Value(n)
+		v2, ok2 := p2.Value(n)
+		if ok1 != ok2 || v1 != v2 {
+			return true
+		}
+	}
 	return false
 }
 
+// tablespaceChanged reports if the tablespace was changed.
+func tablespaceChanged(from, to []schema.Attr) bool {
+	return tablespace(from).N != tablespace(to).N
+}
+
 // IndexPartAttrChanged reports if the index-part attributes were changed.
 func (*diff) IndexPartAttrChanged(from, to *schema.IndexPart) bool {
 	p1 := &IndexColumnProperty{NullsFirst: from.Desc, NullsLast: !from.Desc}
@@ -143,11 +324,28 @@ func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
 	return from != to
 }
 
+// MatchChanged reports if the foreign key match type was changed.
+func (*diff) MatchChanged(from, to schema.ReferenceMatch) bool {
+	// MATCH SIMPLE is the default match type.
+	if from == "" {
+		from = schema.MatchSimple
+	}
+	if to == "" {
+		to = schema.MatchSimple
+	}
+	return from != to
+}
+
 func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	fromT, toT := from.Type.Type, to.Type.Type
 	if fromT == nil || toT == nil {
 		return false, fmt.Errorf("postgres: missing type information for column %q", from.Name)
 	}
+	// Custom types are compared by their formatted form.
+	if f1, ok := customTypes.Format(fromT); ok {
+		f2, ok := customTypes.Format(toT)
+		return !ok || !strings.EqualFold(f1, f2), nil
+	}
 	// Skip checking SERIAL types as they are not real types in the database, but more
 	// like a convenience way for creating integers types with AUTO_INCREMENT property.
 	if s, ok := to.Type.Type.(*SerialType); ok {
@@ -166,6 +364,13 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 		}
 		return i.T != it, nil
 	}
+	// User-defined range types are parsed as user-defined types,
+	// but are inspected from the database as range types.
+	if r, ok := fromT.(*RangeType); ok {
+		if u, ok := toT.(*UserDefinedType); ok {
+			return !strings.EqualFold(r.T, u.T), nil
+		}
+	}
 	if reflect.TypeOf(fromT) != reflect.TypeOf(toT) {
 		return true, nil
 	}
@@ -190,21 +395,49 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	case *XMLType:
 		toT := toT.(*XMLType)
 		changed = fromT.T != toT.T
+	case *RangeType:
+		toT := toT.(*RangeType)
+		changed = !strings.EqualFold(fromT.T, toT.T)
 	case *ArrayType:
 		toT := toT.(*ArrayType)
-		changed = fromT.T != toT.T
+		fromE, fromD, _ := arrayType(fromT.T)
+		toE, toD, _ := arrayType(toT.T)
+		// The number of dimensions is not enforced by the database,
+		// but it is recorded for columns that were declared with it.
+		if fromD != toD {
+			return true, nil
+		}
+		changed = fromT.T != toT.T && elemType(fromE) != elemType(toE)
 		// Array types can be defined differently, but they may represent the same type.
 		// Therefore, in case of mismatch, we verify it using the database engine.
 		if changed {
 			equals, err := d.typesEqual(fromT.T, toT.T)
 			return !equals, err
 		}
+	// Types that are unknown to the driver are compared by their raw form.
+	case *schema.UnsupportedType:
+		toT := toT.(*schema.UnsupportedType)
+		changed = !strings.EqualFold(fromT.T, toT.T)
 	default:
-		return false, &sqlx.UnsupportedTypeError{Type: fromT}
+		return false, &schema.UnsupportedTypeError{Type: fromT}
 	}
 	return changed, nil
 }
 
+// elemType returns the canonical form of the given array element type.
+// For example, "int4", "int" and "integer" are formatted as "integer".
+func elemType(e string) string {
+	t, err := ParseType(e)
+	if err != nil {
+		return strings.ToLower(e)
+	}
+	f, err := FormatType(t)
+	if err != nil {
+		return strings.ToLower(e)
+	}
+	return f
+}
+
 // Normalize implements the sqlx.Normalizer interface.
 func (d *diff) Normalize(from, to *schema.Table) {
 	d.normalize(from)
@@ -300,13 +533,15 @@ const (
 )
 
 // identityChanged reports if one of the identity attributes was changed.
-func identityChanged(from, to []schema.Attr) bool {
+// The START value is ignored in case the SequenceStartIgnore mode is set.
+func (d *diff) identityChanged(from, to []schema.Attr) bool {
 	i1, ok1 := identity(from)
 	i2, ok2 := identity(to)
 	if !ok1 && !ok2 || ok1 != ok2 {
 		return ok1 != ok2
 	}
-	return i1.Generation != i2.Generation || i1.Sequence.Start != i2.Sequence.Start || i1.Sequence.Increment != i2.Sequence.Increment
+	return i1.Generation != i2.Generation || i1.Sequence.Increment != i2.Sequence.Increment ||
+		d.seqStart != SequenceStartIgnore && i1.Sequence.Start != i2.Sequence.Start
 }
 
 func identity(attrs []schema.Attr) (*Identity, bool) {
@@ -330,15 +565,72 @@ func identity(attrs []schema.Attr) (*Identity, bool) {
 	return i, true
 }
 
-func trimCast(s string) string {
-	i := strings.LastIndex(s, "::")
-	if i == -1 {
-		return s
+var (
+	// reCast matches a type cast at the end of an expression. For example, "::text",
+	// "::character varying(255)", "::timestamp with time zone" or "::public.enum[]".
+	reCast = regexp.MustCompile(`(?i)::(?:"[^"]+"|[a-z_][a-z0-9_ .]*)(?:\(\d+(?:,\s*\d+)?\))?(?:\[\])*$`)
+	// reLiteralCast matches a type cast of a literal in a normalized expression. For
+	// example, "0 :: numeric" or "'a' :: character varying".
+	reLiteralCast = regexp.MustCompile(`('(?:[^']|'')*'|\d+(?: \. \d+)?) :: [a-z_][a-z0-9_]*(?: varying| precision| with(?:out)? time zone)?(?: \[ \])*`)
+	// reTimeFunc matches functions and keywords that are synonyms for getting the current time.
+	// For example, "now()", "CURRENT_TIMESTAMP" and "transaction_timestamp()".
+	reTimeFunc = regexp.MustCompile(`(?i)^(?:(current_timestamp|localtimestamp|current_date|current_time|localtime)(?:\(\s*(\d*)\s*\))?|(now|transaction_timestamp)\(\s*\))$`)
+)
+
+// normalizeCheck returns a canonical form of the given CHECK expression. In addition to
+// the standard normalization, the type casts of literals that are added by PostgreSQL
+// are removed. For example, "((price > (0)::numeric))" is normalized to "price > 0".
+func normalizeCheck(x string) string {
+	return reLiteralCast.ReplaceAllString(sqlx.NormalizeExpr(x), "$1")
+}
+
+// NormalizeDefault returns a canonical form of the given DEFAULT expression. It can
+// be used for comparing DEFAULT values that are semantically identical but written
+// in a different form. For example, "'a'::text" and "'a'", "now()" and "CURRENT_TIMESTAMP",
+// or "'-1'::integer" and "-1".
+func NormalizeDefault(x string) string {
+	for {
+		x = sqlx.TrimParens(x)
+		loc := reCast.FindStringIndex(x)
+		if loc == nil {
+			break
+		}
+		x = x[:loc[0]]
+	}
+	if m := reTimeFunc.FindStringSubmatch(x); m != nil {
+		switch strings.ToLower(m[1] + m[3]) {
+		case "current_date":
+			return "CURRENT_DATE"
+		case "current_time":
+			x = "CURRENT_TIME"
+		case "localtime":
+			x = "LOCALTIME"
+		case "localtimestamp":
+			x = "LOCALTIMESTAMP"
+		default:
+			x = "CURRENT_TIMESTAMP"
+		}
+		if p := m[2]; p != "" {
+			x += "(" + p + ")"
+		}
+		return x
+	}
+	switch u := strings.ToUpper(x); u {
+	case "NULL", "TRUE", "FALSE":
+		return u
 	}
-	for _, r := range s[i+2:] {
-		if r != ' ' && !unicode.IsLetter(r) {
-			return s
+	if sqlx.IsQuoted(x, '"', '\'') {
+		v, err := sqlx.Unquote(x)
+		if err != nil {
+			return x
+		}
+		// Numeric literals are quoted in case they are negative or casted (e.g. '-1'::integer).
+		if v != "" && strings.ContainsRune("+-.0123456789", rune(v[0])) && sqlx.IsLiteralNumber(v) {
+			return v
+		}
+		if q, err := sqlx.SingleQuote(v); err == nil {
+			return q
 		}
 	}
-	return s[:i]
+	return x
 }
@@ -89,6 +89,27 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "check expressions formatted differently",
+			from: &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t1_c1_check", Expr: "((price > (0)::numeric) AND ((name)::text <> ''::text))"}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t1_c1_check", Expr: `"price" > 0 and name::text != ''`}}},
+		},
+		{
+			name: "validate check",
+			from: &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t1_c1_check", Expr: "(c1 > 1)", Attrs: []schema.Attr{&NotValid{}}}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t1_c1_check", Expr: "c1 > 1"}}},
+			wantChanges: []schema.Change{
+				&schema.ModifyCheck{
+					From: &schema.Check{Name: "t1_c1_check", Expr: "(c1 > 1)", Attrs: []schema.Attr{&NotValid{}}},
+					To:   &schema.Check{Name: "t1_c1_check", Expr: "c1 > 1"},
+				},
+			},
+		},
+		{
+			name: "check marked as not valid",
+			from: &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t1_c1_check", Expr: "(c1 > 1)"}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t1_c1_check", Expr: "(c1 > 1)", Attrs: []schema.Attr{&NotValid{}}}}},
+		},
 		{
 			name: "add comment",
 			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}},
@@ -110,6 +131,72 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "modify tablespace",
+			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&Tablespace{N: "slow"}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&Tablespace{N: "fast"}}},
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &Tablespace{N: "slow"},
+					To:   &Tablespace{N: "fast"},
+				},
+			},
+		},
+		{
+			name: "default tablespace",
+			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&Tablespace{N: "slow"}}},
+			to:   &schema.Table{Name: "t1"},
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &Tablespace{N: "slow"},
+					To:   &Tablespace{N: "pg_default"},
+				},
+			},
+		},
+		func() testcase {
+			var (
+				ff, sf = int64(70), 0.01
+				from   = &TableStorageParams{FillFactor: &ff}
+				to     = &TableStorageParams{AutovacuumVacuumScaleFactor: &sf}
+			)
+			return testcase{
+				name: "modify storage params",
+				from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{from}},
+				to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{to}},
+				wantChanges: []schema.Change{
+					&schema.ModifyAttr{From: from, To: to},
+				},
+			}
+		}(),
+		func() testcase {
+			ff1, ff2 := int64(70), int64(70)
+			return testcase{
+				name: "same storage params",
+				from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&TableStorageParams{FillFactor: &ff1}}},
+				to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&TableStorageParams{FillFactor: &ff2}}},
+			}
+		}(),
+		{
+			name: "explicit default tablespace",
+			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&Tablespace{N: "pg_default"}}},
+		},
+		{
+			name: "modify inherits",
+			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&Inherits{T: []*schema.Table{{Name: "p1", Schema: &schema.Schema{Name: "public"}}}}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&Inherits{T: []*schema.Table{{Name: "p2"}}}}},
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &Inherits{T: []*schema.Table{{Name: "p1", Schema: &schema.Schema{Name: "public"}}}},
+					To:   &Inherits{T: []*schema.Table{{Name: "p2"}}},
+				},
+			},
+		},
+		{
+			name: "same inherits",
+			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&Inherits{T: []*schema.Table{{Name: "p1", Schema: &schema.Schema{Name: "public"}}}}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&Inherits{T: []*schema.Table{{Name: "p1"}}}}},
+		},
 		{
 			name: "modify comment",
 			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&schema.Comment{Text: "t1"}}},
@@ -258,6 +345,45 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			}
 		}(),
+		func() testcase {
+			var (
+				ref = &schema.Table{
+					Name:    "t2",
+					Schema:  &schema.Schema{Name: "public"},
+					Columns: []*schema.Column{{Name: "id", Type: &schema.ColumnType{Raw: "int", Type: &schema.IntegerType{T: "int"}}}},
+				}
+				from = &schema.Table{
+					Name:    "t1",
+					Schema:  &schema.Schema{Name: "public"},
+					Columns: []*schema.Column{{Name: "t2_id", Type: &schema.ColumnType{Raw: "int", Type: &schema.IntegerType{T: "int"}}}},
+				}
+				to = &schema.Table{
+					Name:    "t1",
+					Schema:  &schema.Schema{Name: "public"},
+					Columns: []*schema.Column{{Name: "t2_id", Type: &schema.ColumnType{Raw: "int", Type: &schema.IntegerType{T: "int"}}}},
+				}
+			)
+			from.ForeignKeys = []*schema.ForeignKey{
+				{Symbol: "fk1", Table: from, Columns: from.Columns, RefTable: ref, RefColumns: ref.Columns},
+				{Symbol: "fk2", Table: from, Columns: from.Columns, RefTable: ref, RefColumns: ref.Columns, OnDelete: schema.SetNull},
+			}
+			to.ForeignKeys = []*schema.ForeignKey{
+				{Symbol: "fk1", Table: to, Columns: to.Columns, RefTable: ref, RefColumns: ref.Columns, Match: schema.MatchSimple},
+				{Symbol: "fk2", Table: to, Columns: to.Columns, RefTable: ref, RefColumns: ref.Columns, OnDelete: schema.SetDefault, Match: schema.MatchFull},
+			}
+			return testcase{
+				name: "foreign-keys match and actions",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyForeignKey{
+						From:   from.ForeignKeys[1],
+						To:     to.ForeignKeys[1],
+						Change: schema.ChangeDeleteAction | schema.ChangeMatch,
+					},
+				},
+			}
+		}(),
 	}
 	for _, tt := range tests {
 		db, m, err := sqlmock.New()
@@ -273,6 +399,32 @@ func TestDiff_TableDiff(t *testing.T) {
 	}
 }
 
+func TestDiff_IndexAttrChanged(t *testing.T) {
+	var (
+		d   = &diff{}
+		on  = true
+		off = false
+	)
+	for _, tt := range []struct {
+		from, to []schema.Attr
+		changed  bool
+	}{
+		{from: nil, to: []schema.Attr{&IndexType{T: "btree"}}},
+		{from: []schema.Attr{&IndexType{T: "spgist"}}, to: []schema.Attr{&IndexType{T: "SP-GiST"}}},
+		{from: []schema.Attr{&IndexType{T: "gin"}}, to: []schema.Attr{&IndexType{T: "GIST"}}, changed: true},
+		{from: []schema.Attr{&IndexType{T: "brin"}}, to: []schema.Attr{&IndexType{T: "BRIN"}, &IndexStorageParams{PagesPerRange: 128}}},
+		{from: []schema.Attr{&IndexType{T: "brin"}}, to: []schema.Attr{&IndexType{T: "BRIN"}, &IndexStorageParams{PagesPerRange: 32}}, changed: true},
+		{from: []schema.Attr{&IndexType{T: "brin"}}, to: []schema.Attr{&IndexType{T: "BRIN"}, &IndexStorageParams{AutoSummarize: true}}, changed: true},
+		{from: []schema.Attr{&IndexType{T: "gin"}}, to: []schema.Attr{&IndexType{T: "GIN"}, &IndexStorageParams{FastUpdate: &on}}},
+		{from: []schema.Attr{&IndexType{T: "gin"}}, to: []schema.Attr{&IndexType{T: "GIN"}, &IndexStorageParams{FastUpdate: &off}}, changed: true},
+		{from: []schema.Attr{&IndexStorageParams{FillFactor: 90}}, to: nil},
+		{from: []schema.Attr{&IndexStorageParams{FillFactor: 70}}, to: nil, changed: true},
+		{from: []schema.Attr{&IndexType{T: "hash"}}, to: []schema.Attr{&IndexType{T: "hash"}, &IndexStorageParams{FillFactor: 75}}},
+	} {
+		require.Equal(t, tt.changed, d.IndexAttrChanged(tt.from, tt.to))
+	}
+}
+
 func TestDiff_SchemaDiff(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -305,4 +457,100 @@ func TestDiff_SchemaDiff(t *testing.T) {
 		&schema.DropTable{T: from.Tables[1]},
 		&schema.AddTable{T: to.Tables[1]},
 	}, changes)
+
+	from, to = schema.New("public").SetComment("a"), schema.New("public").SetComment("b")
+	changes, err = drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.ModifySchema{S: to, Changes: []schema.Change{&schema.ModifyAttr{From: &schema.Comment{Text: "a"}, To: &schema.Comment{Text: "b"}}}},
+	}, changes)
+}
+
+func TestDiff_LowerCaseNames(t *testing.T) {
+	users := schema.NewTable("Users").AddColumns(schema.NewIntColumn("ID", "int"))
+	users.AddIndexes(schema.NewIndex("Users_ID").AddColumns(users.Columns...))
+	from := schema.New("public").AddTables(
+		schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int")),
+	)
+	from.Tables[0].AddIndexes(schema.NewIndex("users_id").AddColumns(from.Tables[0].Columns...))
+	to := schema.New("Public").AddTables(users)
+	for _, b := range []bool{false, true} {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.version("130000")
+		drv, err := Open(db, WithLowerCaseNames(b))
+		require.NoError(t, err)
+		changes, err := drv.RealmDiff(schema.NewRealm(from), schema.NewRealm(to))
+		require.NoError(t, err)
+		if b {
+			require.Empty(t, changes)
+			continue
+		}
+		require.Equal(t, []schema.Change{
+			&schema.DropSchema{S: from},
+			&schema.AddSchema{S: to},
+			&schema.AddTable{T: users},
+		}, changes)
+	}
+}
+
+func TestDiff_SequenceStart(t *testing.T) {
+	from := &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 1, Increment: 1}}}}
+	to := &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 100}}}}
+	d := &diff{conn{seqStart: SequenceStartIgnore}}
+	change, err := d.ColumnChange(from, to)
+	require.NoError(t, err)
+	require.Equal(t, schema.NoChange, change)
+	d.seqStart = SequenceStartSet
+	change, err = d.ColumnChange(from, to)
+	require.NoError(t, err)
+	require.Equal(t, schema.ChangeAttr, change)
+}
+
+func TestDiff_ArrayRangeTypes(t *testing.T) {
+	d := &diff{}
+	for _, tt := range []struct {
+		from, to schema.Type
+		changed  bool
+	}{
+		{from: &ArrayType{T: "int4[]"}, to: &ArrayType{T: "integer[]"}},
+		{from: &ArrayType{T: "int4[]"}, to: &ArrayType{T: "int[]"}},
+		{from: &ArrayType{T: "text[][]"}, to: &ArrayType{T: "text[][]"}},
+		{from: &ArrayType{T: "timestamptz[]"}, to: &ArrayType{T: "timestamp with time zone[]"}},
+		{from: &ArrayType{T: "text[]"}, to: &ArrayType{T: "text[][]"}, changed: true},
+		{from: &RangeType{T: "tstzrange"}, to: &RangeType{T: "TSTZRANGE"}},
+		{from: &RangeType{T: "tstzrange"}, to: &RangeType{T: "tsrange"}, changed: true},
+		{from: &RangeType{T: "floatrange"}, to: &UserDefinedType{T: "floatrange"}},
+		{from: &RangeType{T: "floatrange"}, to: &UserDefinedType{T: "ltree"}, changed: true},
+	} {
+		changed, err := d.typeChanged(
+			&schema.Column{Name: "c", Type: &schema.ColumnType{Type: tt.from}},
+			&schema.Column{Name: "c", Type: &schema.ColumnType{Type: tt.to}},
+		)
+		require.NoError(t, err)
+		require.Equal(t, tt.changed, changed, "%v -> %v", tt.from, tt.to)
+	}
+}
+
+func TestNormalizeDefault(t *testing.T) {
+	for x, want := range map[string]string{
+		"'a'::text":                      "'a'",
+		"'a'::character varying":         "'a'",
+		`"a"`:                            "'a'",
+		"('a'::character varying)::text": "'a'",
+		"'-1'::integer":                  "-1",
+		"(-1)":                           "-1",
+		"'{}'::jsonb":                    "'{}'",
+		"'{a}'::text[]":                  "'{a}'",
+		"'x'::\"MyEnum\"":                "'x'",
+		"now()":                          "CURRENT_TIMESTAMP",
+		"CURRENT_TIMESTAMP":              "CURRENT_TIMESTAMP",
+		"transaction_timestamp()":        "CURRENT_TIMESTAMP",
+		"CURRENT_TIMESTAMP(3)":           "CURRENT_TIMESTAMP(3)",
+		"'2021-01-01'::date":             "'2021-01-01'",
+		"nextval('t_seq'::regclass)":     "nextval('t_seq'::regclass)",
+		"true":                           "TRUE",
+	} {
+		require.Equal(t, want, NormalizeDefault(x), x)
+	}
 }
@@ -7,10 +7,14 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/naming"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlmetrics"
+	"ariga.io/atlas/sql/sqltrace"
 
 	"golang.org/x/mod/semver"
 )
@@ -18,6 +22,12 @@ import (
 type (
 	// Driver represents a PostgreSQL driver for introspecting database schemas,
 	// generating diff between schema elements and apply migrations changes.
+	//
+	// A Driver is safe for concurrent use by multiple goroutines, as long as its
+	// underlying connection is (e.g. *sql.DB). Inspections, planning and applying
+	// of drivers that were opened on a single connection (i.e. *sql.Tx or *sql.Conn)
+	// are serialized. Note that diffing normalizes the given schema elements, and
+	// the same elements should not be diffed concurrently.
 	Driver struct {
 		conn
 		schema.Differ
@@ -25,6 +35,38 @@ type (
 		migrate.PlanApplier
 	}
 
+	// Option allows configuring the Driver using functional options.
+	Option func(*options)
+
+	// options holds the configuration of the Driver.
+	options struct {
+		trace      *sqltrace.Config
+		metrics    sqlmetrics.Recorder
+		seqStart   SequenceStartMode
+		idxRebuild IndexRebuildMode
+		version    string
+		collate    string
+		searchPath []string
+		catalog    string
+		log        sqlx.LogFunc
+		readOnly   bool
+		coalesce   bool
+		notValid   bool
+		ordered    bool
+		minQuote   bool
+		fold       bool
+		collations bool
+		aurora     bool
+		namer      naming.Strategy
+		parsers    []sqlx.TypeParser
+	}
+
+	// SequenceStartMode controls how the START value of identity sequences is diffed and planned.
+	SequenceStartMode uint8
+
+	// IndexRebuildMode controls how modified indexes are rebuilt by the PlanApplier.
+	IndexRebuildMode uint8
+
 	// database connection and its information.
 	conn struct {
 		schema.ExecQuerier
@@ -32,12 +74,98 @@ type (
 		collate string
 		ctype   string
 		version string
+		// Options that control the diff and the planning.
+		seqStart     SequenceStartMode
+		indexRebuild IndexRebuildMode
+		// The schemas that are used for resolving unqualified
+		// names, instead of the search_path of the connection.
+		searchPath []string
+		// Merge the changes of the same table into one ALTER statement.
+		coalesce bool
+		// Add constraints to existing tables as NOT VALID, and validate them separately.
+		notValid bool
+		// Treat the order of table columns as significant.
+		ordered bool
+		// Quote only the identifiers that must be quoted.
+		minQuote bool
+		// Compare object names case-insensitively.
+		foldNames bool
+		// Inspect the collation objects of the schemas.
+		collations bool
+		// Strategy for naming the unnamed objects of the planned changes.
+		namer naming.Strategy
+		// Parsers for column types that are not recognized by the driver.
+		parsers []sqlx.TypeParser
+		// Connected to an Amazon Redshift cluster.
+		redshift bool
+		// Connected to an Amazon Aurora PostgreSQL cluster.
+		aurora *Aurora
+		// Opened on a single database connection (i.e. *sql.Tx or *sql.Conn),
+		// before it was wrapped by the options of the driver (e.g. WithReadOnly).
+		single bool
 	}
 )
 
+// List of sequence START modes.
+const (
+	// SequenceStartRestart is the default mode. The START values of identity
+	// columns are compared, and changed sequences are restarted with their new
+	// START value (i.e. "SET START WITH n ... RESTART").
+	SequenceStartRestart SequenceStartMode = iota
+
+	// SequenceStartIgnore ignores the START value of existing identity columns.
+	// New columns are still created with their configured START value.
+	SequenceStartIgnore
+
+	// SequenceStartSet compares the START values of identity columns, and changes
+	// the sequence definition without restarting it. That is, the current value of
+	// the sequence is not affected, and only a future "RESTART" will use the new value.
+	SequenceStartSet
+)
+
+// List of index rebuild modes.
+const (
+	// IndexRebuildDrop is the default mode. Modified indexes are dropped and created
+	// again. Note that writes to the table are blocked while the index is built, and
+	// queries cannot use the index until it is created.
+	IndexRebuildDrop IndexRebuildMode = iota
+
+	// IndexRebuildConcurrently rebuilds modified indexes without blocking the queries
+	// and the writes to the table. Indexes whose storage parameters were changed only,
+	// are altered and rebuilt using REINDEX CONCURRENTLY (PostgreSQL 12 and above). The
+	// rest are replaced by an index that is created concurrently under a temporary name,
+	// followed by dropping the old index concurrently and renaming the new one. Since
+	// CONCURRENTLY cannot be executed inside a transaction block, plans that rebuild
+	// indexes in this mode are not transactional.
+	IndexRebuildConcurrently
+)
+
 // Open opens a new PostgreSQL driver.
-func Open(db schema.ExecQuerier) (*Driver, error) {
-	c := conn{ExecQuerier: db}
+func Open(db schema.ExecQuerier, opts ...Option) (*Driver, error) {
+	var (
+		o      options
+		single = sqlx.SingleConn(db)
+	)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.readOnly {
+		db = sqlx.ReadOnlyExecQuerier(db)
+	}
+	if o.log != nil {
+		db = sqlx.LogExecQuerier(db, o.log)
+	}
+	var tracer *sqltrace.Tracer
+	if o.trace != nil {
+		tracer = sqltrace.New("postgresql", *o.trace)
+		db = tracer.ExecQuerier(db)
+	}
+	var metrics *sqlmetrics.Metrics
+	if o.metrics != nil {
+		metrics = sqlmetrics.New("postgresql", o.metrics)
+		db = metrics.ExecQuerier(db)
+	}
+	c := conn{ExecQuerier: db, single: single, seqStart: o.seqStart, indexRebuild: o.idxRebuild, searchPath: o.searchPath, coalesce: o.coalesce, notValid: o.notValid, ordered: o.ordered, minQuote: o.minQuote, foldNames: o.fold, collations: o.collations, namer: o.namer, parsers: o.parsers}
 	rows, err := db.QueryContext(context.Background(), paramsQuery)
 	if err != nil {
 		return nil, fmt.Errorf("postgres: scanning system variables: %w", err)
@@ -50,19 +178,253 @@ func Open(db schema.ExecQuerier) (*Driver, error) {
 		return nil, fmt.Errorf("postgres: unexpected number of rows: %d", len(params))
 	}
 	c.collate, c.ctype, c.version = params[0], params[1], params[2]
-	if len(c.version) != 6 {
+	if o.collate != "" {
+		c.collate = o.collate
+	}
+	if o.version != "" {
+		c.version = o.version
+	}
+	// Redshift is based on PostgreSQL 8.0.2, and reports a 5-digit version number.
+	if len(c.version) == 5 {
+		if c.redshift, err = redshiftVersion(context.Background(), db); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case c.redshift:
+		c.version = fmt.Sprintf("%s.%s.%s", c.version[:1], c.version[1:3], c.version[3:])
+	case len(c.version) != 6:
 		return nil, fmt.Errorf("postgres: malformed version: %s", c.version)
+	default:
+		c.version = fmt.Sprintf("%s.%s.%s", c.version[:2], c.version[2:4], c.version[4:])
 	}
-	c.version = fmt.Sprintf("%s.%s.%s", c.version[:2], c.version[2:4], c.version[4:])
-	if semver.Compare("v"+c.version, "v10.0.0") != -1 {
+	if !c.redshift && semver.Compare("v"+c.version, "v10.0.0") != -1 {
 		return nil, fmt.Errorf("postgres: unsupported postgres version: %s", c.version)
 	}
-	return &Driver{
+	// Redshift lacks the functions that are used for detecting Aurora.
+	if o.aurora && !c.redshift {
+		if c.aurora, err = auroraVersion(context.Background(), db); err != nil {
+			return nil, err
+		}
+	}
+	ic := c
+	if o.catalog != "" {
+		ic.ExecQuerier = sqlx.CatalogQuerier(c.ExecQuerier, "information_schema", `"`+o.catalog+`"`)
+	}
+	drv := &Driver{
 		conn:        c,
 		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
-		Inspector:   &inspect{c},
+		Inspector:   &inspect{ic},
 		PlanApplier: &planApply{c},
-	}, nil
+	}
+	if single {
+		var mu sync.Mutex
+		drv.Inspector = sqlx.SerialInspector(drv.Inspector, &mu)
+		drv.PlanApplier = sqlx.SerialPlanApplier(drv.PlanApplier, &mu)
+	}
+	if tracer != nil {
+		drv.Differ = tracer.Differ(drv.Differ)
+		drv.Inspector = tracer.Inspector(drv.Inspector)
+		drv.PlanApplier = tracer.PlanApplier(drv.PlanApplier)
+	}
+	if metrics != nil {
+		drv.Differ = metrics.Differ(drv.Differ)
+		drv.Inspector = metrics.Inspector(drv.Inspector)
+		drv.PlanApplier = metrics.PlanApplier(drv.PlanApplier)
+	}
+	return drv, nil
+}
+
+// InspectTables implements the schema.TablesInspector interface. It streams the tables
+// of the connected database to fn, instead of loading the realm as a whole. See the
+// schema.TablesInspector for more info.
+func (d *Driver) InspectTables(ctx context.Context, opts *schema.InspectRealmOption, fn func(*schema.Table) error) error {
+	return schema.InspectTables(ctx, d.Inspector, opts, fn)
+}
+
+// WithTracing enables OpenTelemetry tracing for the driver. Spans are created
+// for schema inspection, diff computation and each executed statement.
+func WithTracing(cfg sqltrace.Config) Option {
+	return func(o *options) {
+		o.trace = &cfg
+	}
+}
+
+// WithMetrics reports the events of schema inspections, diff computations, planning,
+// and each executed statement of the driver to the given Recorder. For example, the
+// sqlmetrics.Collector exposes them as Prometheus metrics.
+func WithMetrics(r sqlmetrics.Recorder) Option {
+	return func(o *options) {
+		o.metrics = r
+	}
+}
+
+// WithSequenceStart configures how the START value of identity sequences is compared
+// by the Differ and applied by the PlanApplier. See SequenceStartMode for details.
+func WithSequenceStart(m SequenceStartMode) Option {
+	return func(o *options) {
+		o.seqStart = m
+	}
+}
+
+// WithIndexRebuild configures how modified indexes are rebuilt by
+// the PlanApplier. See IndexRebuildMode for details.
+func WithIndexRebuild(m IndexRebuildMode) Option {
+	return func(o *options) {
+		o.idxRebuild = m
+	}
+}
+
+// WithVersion overrides the server version that is detected on Open. The version
+// is expected to be in the format of the server_version_num setting (e.g. "130004").
+func WithVersion(v string) Option {
+	return func(o *options) {
+		o.version = v
+	}
+}
+
+// WithCollation overrides the default collation of the database (lc_collate) that
+// is used for omitting default attributes on inspection and for comparing columns
+// that do not define their collation explicitly.
+func WithCollation(c string) Option {
+	return func(o *options) {
+		o.collate = c
+	}
+}
+
+// WithSearchPath sets the schemas that are used for resolving unqualified names,
+// instead of the search_path of the connection. For example, the first schema is
+// inspected by InspectSchema when it is called with an empty name, and tables that
+// are not attached to a schema are qualified with it by the PlanApplier.
+func WithSearchPath(schemas ...string) Option {
+	return func(o *options) {
+		// Copy the schemas, as the connection is shared by concurrent operations.
+		o.searchPath = append([]string(nil), schemas...)
+	}
+}
+
+// WithCatalogSchema configures the driver to inspect the database using the given
+// schema instead of the information_schema. For example, a schema that holds views
+// with the same structure, or a copy of the catalog tables.
+func WithCatalogSchema(name string) Option {
+	return func(o *options) {
+		o.catalog = name
+	}
+}
+
+// WithCoalesceAlter configures the PlanApplier to merge the changes of the same
+// table into one ALTER TABLE statement, in order to reduce the number of times
+// large tables are locked and rewritten.
+func WithCoalesceAlter(b bool) Option {
+	return func(o *options) {
+		o.coalesce = b
+	}
+}
+
+// WithNotValidConstraints configures the PlanApplier to add named CHECK and FOREIGN KEY
+// constraints to existing tables as NOT VALID, and validate them in separate statements.
+// Unlike adding a validated constraint, validating an existing one does not hold an
+// ACCESS EXCLUSIVE lock on the table while its rows are scanned.
+func WithNotValidConstraints(b bool) Option {
+	return func(o *options) {
+		o.notValid = b
+	}
+}
+
+// WithColumnOrder configures the Differ to treat the order of table columns as
+// significant, and the PlanApplier to rewrite tables whose columns were moved, or
+// added before existing columns, as PostgreSQL cannot reorder columns in place.
+// Such tables are copied to a new table while they are locked for reads and writes,
+// and tables that are referenced by foreign keys of other tables cannot be rewritten.
+func WithColumnOrder(b bool) Option {
+	return func(o *options) {
+		o.ordered = b
+	}
+}
+
+// WithMinimalQuoting configures the PlanApplier to quote identifiers only when it is
+// needed, that is, when they are reserved words, or contain characters other than ASCII
+// lowercase letters, digits and underscores. Note that identifiers with uppercase letters
+// are always quoted, as PostgreSQL folds unquoted identifiers to lowercase. By default,
+// all identifiers are quoted.
+func WithMinimalQuoting(b bool) Option {
+	return func(o *options) {
+		o.minQuote = b
+	}
+}
+
+// WithCollationObjects configures the Inspector to inspect the collation objects of the
+// schemas (i.e. CREATE COLLATION), and add them to the schema attributes as *Collation.
+// Collations are diffed and planned regardless of this option, and it is required only
+// when the desired state declares them. By default, collation objects are not inspected.
+func WithCollationObjects(b bool) Option {
+	return func(o *options) {
+		o.collations = b
+	}
+}
+
+// WithAurora configures the driver to detect on Open if it is connected to an Amazon Aurora
+// PostgreSQL cluster, and to check the extensions it supports in planning. The detection runs
+// additional queries that may be rejected by PostgreSQL-compatible servers and proxies, and a
+// failing query aborts the transaction the driver was opened on. Hence, it is disabled by
+// default. See Driver.Aurora for more info.
+func WithAurora(b bool) Option {
+	return func(o *options) {
+		o.aurora = b
+	}
+}
+
+// WithLowerCaseNames configures the Differ to compare object names case-insensitively, the
+// way PostgreSQL folds unquoted identifiers to lower case. It is useful when the desired state
+// is defined with mixed-case names (e.g. by an ORM), while the objects were created using their
+// unquoted (i.e. lower case) form. By default, names are compared as-is, like quoted identifiers,
+// and objects whose names differ only in case are dropped and recreated.
+//
+// Note that objects that are created by the PlanApplier keep the case of their desired names.
+func WithLowerCaseNames(b bool) Option {
+	return func(o *options) {
+		o.fold = b
+	}
+}
+
+// WithNaming configures the PlanApplier to name the indexes, foreign keys and checks that
+// are added unnamed by the planned changes, using the given naming strategy. For example,
+// naming.Rails{}. Note that the objects are named in place, that is, the given changes are
+// modified. By default, unnamed objects are left for the database to name.
+func WithNaming(s naming.Strategy) Option {
+	return func(o *options) {
+		o.namer = s
+	}
+}
+
+// WithTypeParser registers a parser for column types that are not recognized by the
+// driver on inspection (e.g. types that are added by extensions or plugins). Parsers
+// are called in the order they were registered, and should return one of the types
+// that are supported by the driver. Types that are not handled by any of the parsers
+// are kept as schema.UnsupportedType, and compared and planned by their raw form.
+func WithTypeParser(p func(raw string) (schema.Type, bool)) Option {
+	return func(o *options) {
+		o.parsers = append(o.parsers, p)
+	}
+}
+
+// WithReadOnly opens the driver in read-only mode. In this mode, all statements that
+// may write to the database are rejected with a schema.ReadOnlyError before they are
+// sent to it, and the driver can be used only for inspecting the database. Note that
+// this is not a replacement for connecting with a read-only credential, but a guard
+// against writes that are attempted by the driver or by its users.
+func WithReadOnly(b bool) Option {
+	return func(o *options) {
+		o.readOnly = b
+	}
+}
+
+// WithStatementLog calls f with each statement (and its arguments) that is
+// executed or queried by the driver, before it is sent to the database.
+func WithStatementLog(f func(ctx context.Context, stmt string, args []interface{})) Option {
+	return func(o *options) {
+		o.log = f
+	}
 }
 
 // Standard column types (and their aliases) as defined in
@@ -125,6 +487,19 @@ const (
 	TypeSerial4     = "serial4"     // serial
 	TypeSerial8     = "serial8"     // bigserial
 
+	TypeInt4Range      = "int4range"
+	TypeInt4MultiRange = "int4multirange"
+	TypeInt8Range      = "int8range"
+	TypeInt8MultiRange = "int8multirange"
+	TypeNumRange       = "numrange"
+	TypeNumMultiRange  = "nummultirange"
+	TypeTSRange        = "tsrange"
+	TypeTSMultiRange   = "tsmultirange"
+	TypeTSTZRange      = "tstzrange"
+	TypeTSTZMultiRange = "tstzmultirange"
+	TypeDateRange      = "daterange"
+	TypeDateMultiRange = "datemultirange"
+
 	TypeArray       = "array"
 	TypeXML         = "xml"
 	TypeJSON        = "json"
@@ -134,3 +509,9 @@ const (
 	TypeInterval    = "interval"
 	TypeUserDefined = "user-defined"
 )
+
+// supportsReindexConcurrently reports if the server
+// supports REINDEX CONCURRENTLY (PostgreSQL 12 and above).
+func (c *conn) supportsReindexConcurrently() bool {
+	return !c.redshift && c.version >= "12"
+}
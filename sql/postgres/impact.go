@@ -0,0 +1,227 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// Queries to get the approximate number of rows in a table, based on
+// the statistics that are collected by VACUUM and ANALYZE.
+const (
+	rowsQuery       = "SELECT reltuples::bigint FROM pg_catalog.pg_class WHERE oid = to_regclass(quote_ident($1))"
+	rowsQuerySchema = "SELECT reltuples::bigint FROM pg_catalog.pg_class WHERE oid = to_regclass(quote_ident($1) || '.' || quote_ident($2))"
+)
+
+// estimate sets the impact of the planned changes that were caused
+// by the given top-level change, if it modifies an existing table.
+func (s *state) estimate(ctx context.Context, c schema.Change, planned []*migrate.Change) error {
+	var t *schema.Table
+	switch c := c.(type) {
+	case *schema.DropTable:
+		t = c.T
+	case *schema.ModifyTable:
+		t = c.T
+	default:
+		// New tables are empty.
+		return nil
+	}
+	rows, err := s.tableRows(ctx, t)
+	if err != nil {
+		return err
+	}
+	name := t.Name
+	if ns := s.schemaOf(t); ns != "" {
+		name = ns + "." + name
+	}
+	for _, c := range planned {
+		i := &migrate.Impact{Table: name, Rows: rows}
+		s.impact(i, c)
+		c.Impact = i
+	}
+	return nil
+}
+
+// tableRows returns the approximate number of rows in the table, or -1 if
+// the table was never vacuumed or analyzed, or it does not exist.
+func (s *state) tableRows(ctx context.Context, t *schema.Table) (int64, error) {
+	// Tables that were inspected with their statistics
+	// do not require an additional query.
+	var stats schema.TableStats
+	if sqlx.Has(t.Attrs, &stats) {
+		return stats.Rows, nil
+	}
+	query, args := rowsQuery, []interface{}{t.Name}
+	if ns := s.schemaOf(t); ns != "" {
+		query, args = rowsQuerySchema, []interface{}{ns, t.Name}
+	}
+	rows, err := s.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: querying table %q statistics: %w", t.Name, err)
+	}
+	var n sql.NullInt64
+	switch err := sqlx.ScanOne(rows, &n); {
+	case errors.Is(err, sql.ErrNoRows):
+		return -1, nil
+	case err != nil:
+		return 0, fmt.Errorf("postgres: scanning table %q statistics: %w", t.Name, err)
+	case !n.Valid || n.Int64 < 0:
+		return -1, nil
+	}
+	return n.Int64, nil
+}
+
+// impact estimates the impact of a planned change according to the lock levels
+// that are acquired by PostgreSQL, and the forms of ALTER TABLE that rewrite the
+// table. See: https://www.postgresql.org/docs/current/sql-altertable.html
+func (s *state) impact(i *migrate.Impact, c *migrate.Change) {
+	var reasons []string
+	switch src := c.Source.(type) {
+	case *schema.DropTable:
+		i.Lock, reasons = migrate.LockExclusive, append(reasons, "drop table")
+	case *schema.ModifyTable:
+		if s.ordered && sqlx.ReorderColumns(src.T, src.Changes) {
+			i.Rewrite, i.Lock = true, migrate.LockExclusive
+			reasons = append(reasons, "reorder columns")
+			break
+		}
+		for _, change := range src.Changes {
+			lock, r := s.alterImpact(i, change)
+			if lock > i.Lock {
+				i.Lock = lock
+			}
+			if r != "" {
+				reasons = append(reasons, r)
+			}
+		}
+	case *schema.RenameColumn:
+		i.Lock = migrate.LockExclusive
+	case nil:
+		switch {
+		case strings.HasPrefix(c.Cmd, "CREATE INDEX"), strings.HasPrefix(c.Cmd, "CREATE UNIQUE INDEX"):
+			i.Scan, i.Lock = true, migrate.LockWrite
+			reasons = append(reasons, "build index")
+		case strings.HasPrefix(c.Cmd, "DROP INDEX"), strings.HasPrefix(c.Cmd, "ALTER INDEX"), strings.HasPrefix(c.Cmd, "ALTER TABLE"):
+			i.Lock = migrate.LockExclusive
+		}
+	// Validation of NOT VALID constraints acquires a SHARE UPDATE
+	// EXCLUSIVE lock that does not block reads and writes.
+	default:
+		i.Scan = true
+		reasons = append(reasons, "validate constraint")
+	}
+	i.Reason = strings.Join(reasons, ", ")
+}
+
+// alterImpact returns the lock level and the rewrite or scan reason of one
+// ALTER TABLE action.
+func (s *state) alterImpact(i *migrate.Impact, c schema.Change) (migrate.LockLevel, string) {
+	switch c := c.(type) {
+	case *schema.AddColumn:
+		switch x := c.C.Default; {
+		// Since PostgreSQL 11, columns are added with non-volatile
+		// defaults without rewriting the table.
+		case s.majorV() < 11 && x != nil:
+			i.Rewrite = true
+			return migrate.LockExclusive, fmt.Sprintf("add column %q with default value", c.C.Name)
+		case x != nil && volatile(x), isSerial(c.C) || sqlx.Has(c.C.Attrs, &Identity{}):
+			i.Rewrite = true
+			return migrate.LockExclusive, fmt.Sprintf("add column %q with volatile default value", c.C.Name)
+		}
+	case *schema.ModifyColumn:
+		var reasons []string
+		if c.Change.Is(schema.ChangeType) && !binaryCoercible(c.From.Type.Type, c.To.Type.Type) {
+			i.Rewrite = true
+			reasons = append(reasons, fmt.Sprintf("change type of column %q", c.To.Name))
+		}
+		if c.Change.Is(schema.ChangeNull) && c.From.Type.Null && !c.To.Type.Null {
+			i.Scan = true
+			reasons = append(reasons, fmt.Sprintf("set column %q not null", c.To.Name))
+		}
+		return migrate.LockExclusive, strings.Join(reasons, ", ")
+	case *schema.AddForeignKey:
+		// Adding a foreign key acquires a SHARE ROW EXCLUSIVE
+		// lock that blocks only writes on the table.
+		if _, ok := s.validateLater(c); !ok {
+			i.Scan = true
+			return migrate.LockWrite, fmt.Sprintf("validate foreign key %q", c.F.Symbol)
+		}
+		return migrate.LockWrite, ""
+	case *schema.AddCheck:
+		if _, ok := s.validateLater(c); !ok {
+			i.Scan = true
+			return migrate.LockExclusive, fmt.Sprintf("validate check %q", c.C.Name)
+		}
+	case *schema.ModifyCheck:
+		i.Scan = true
+		return migrate.LockExclusive, fmt.Sprintf("validate check %q", c.To.Name)
+	case *schema.AddAttr, *schema.ModifyAttr:
+		if _, to, ok := tablespaceChange(c); ok {
+			i.Rewrite = true
+			return migrate.LockExclusive, fmt.Sprintf("move table to tablespace %q", to.N)
+		}
+	}
+	return migrate.LockExclusive, ""
+}
+
+// majorV returns the major version of the connected database.
+func (s *state) majorV() int {
+	v, _ := strconv.Atoi(strings.SplitN(s.version, ".", 2)[0])
+	return v
+}
+
+// stableDefaults holds the default expressions that are evaluated once
+// for all rows when a column is added. Note that PostgreSQL evaluates
+// all non-volatile expressions once, and this list is not exhaustive.
+var stableDefaults = map[string]bool{
+	"now()":             true,
+	"current_timestamp": true,
+	"current_date":      true,
+	"current_time":      true,
+	"localtimestamp":    true,
+	"localtime":         true,
+	"current_user":      true,
+	"session_user":      true,
+}
+
+// volatile reports if the given default expression may be volatile, and
+// therefore requires rewriting the table when a column is added with it.
+func volatile(x schema.Expr) bool {
+	switch x := x.(type) {
+	case *schema.Literal:
+		return false
+	case *schema.RawExpr:
+		return !stableDefaults[strings.ToLower(strings.TrimSpace(x.X))]
+	default:
+		return true
+	}
+}
+
+// binaryCoercible reports if a column type can be changed without rewriting
+// the table. For example, increasing the size of a varchar column, or changing
+// it to text.
+func binaryCoercible(from, to schema.Type) bool {
+	f, ok1 := from.(*schema.StringType)
+	t, ok2 := to.(*schema.StringType)
+	if !ok1 || !ok2 || f.T != TypeCharVar && f.T != TypeVarChar && f.T != TypeText {
+		return false
+	}
+	switch t.T {
+	case TypeText:
+		return true
+	case TypeCharVar, TypeVarChar:
+		return f.T != TypeText && (t.Size == 0 || f.Size != 0 && t.Size >= f.Size)
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChanges_Impact(t *testing.T) {
+	var (
+		public  = schema.New("public")
+		users   = schema.NewTable("users").SetSchema(public)
+		pets    = schema.NewTable("pets").SetSchema(public)
+		name    = schema.NewStringColumn("name", "varchar", schema.StringSize(255))
+		active  = schema.NewBoolColumn("active", "boolean").SetDefault(&schema.Literal{V: "true"})
+		uid     = schema.NewColumn("uid").SetType(&UUIDType{T: "uuid"}).SetDefault(&schema.RawExpr{X: "gen_random_uuid()"})
+		changes = []schema.Change{
+			&schema.ModifyTable{
+				T: users,
+				Changes: []schema.Change{
+					&schema.AddColumn{C: active},
+					&schema.ModifyColumn{
+						From:   schema.NewIntColumn("age", "integer"),
+						To:     schema.NewIntColumn("age", "bigint"),
+						Change: schema.ChangeType,
+					},
+					&schema.AddIndex{I: schema.NewIndex("users_name").AddColumns(name)},
+				},
+			},
+			&schema.ModifyTable{
+				T: pets,
+				Changes: []schema.Change{
+					&schema.AddColumn{C: uid},
+					&schema.ModifyColumn{
+						From:   schema.NewNullStringColumn("name", "character varying", schema.StringSize(100)),
+						To:     schema.NewStringColumn("name", "text"),
+						Change: schema.ChangeType | schema.ChangeNull,
+					},
+				},
+			},
+			&schema.AddTable{T: schema.NewTable("owners").SetSchema(public).AddColumns(schema.NewIntColumn("id", "int"))},
+			&schema.DropTable{T: schema.NewTable("cars").SetSchema(public)},
+		}
+	)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+
+	// Impact is not estimated by default.
+	plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	for _, c := range plan.Changes {
+		require.Nil(t, c.Impact)
+	}
+
+	m.ExpectQuery(sqltest.Escape(rowsQuerySchema)).
+		WithArgs("public", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(1000))
+	m.ExpectQuery(sqltest.Escape(rowsQuerySchema)).
+		WithArgs("public", "pets").
+		WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(-1))
+	m.ExpectQuery(sqltest.Escape(rowsQuerySchema)).
+		WithArgs("public", "cars").
+		WillReturnRows(sqlmock.NewRows([]string{"reltuples"}))
+	plan, err = drv.PlanChanges(migrate.WithImpact(context.Background()), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 5)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	require.Equal(t, &migrate.Impact{Table: "public.users", Rows: 1000, Rewrite: true, Lock: migrate.LockExclusive, Reason: `change type of column "age"`}, plan.Changes[0].Impact)
+	require.True(t, plan.Changes[0].Impact.Blocking())
+	require.Equal(t, `table "public.users" (~1000 rows): rewrite, exclusive lock (change type of column "age")`, plan.Changes[0].Impact.String())
+	require.Equal(t, &migrate.Impact{Table: "public.users", Rows: 1000, Scan: true, Lock: migrate.LockWrite, Reason: "build index"}, plan.Changes[1].Impact)
+	require.Equal(t, &migrate.Impact{Table: "public.pets", Rows: -1, Rewrite: true, Scan: true, Lock: migrate.LockExclusive, Reason: `add column "uid" with volatile default value, set column "name" not null`}, plan.Changes[2].Impact)
+	require.Nil(t, plan.Changes[3].Impact)
+	require.Equal(t, &migrate.Impact{Table: "public.cars", Rows: -1, Lock: migrate.LockExclusive, Reason: "drop table"}, plan.Changes[4].Impact)
+	require.False(t, plan.Changes[4].Impact.Blocking())
+
+	// Columns with default values are added by rewriting the table before PostgreSQL 11.
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("100000")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(rowsQuery)).
+		WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(10))
+	plan, err = drv.PlanChanges(migrate.WithImpact(context.Background()), "plan", []schema.Change{
+		&schema.ModifyTable{T: schema.NewTable("users"), Changes: []schema.Change{&schema.AddColumn{C: active}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &migrate.Impact{Table: "users", Rows: 10, Rewrite: true, Lock: migrate.LockExclusive, Reason: `add column "active" with default value`}, plan.Changes[0].Impact)
+
+	// Tables that were inspected with their statistics are not queried.
+	plan, err = drv.PlanChanges(migrate.WithImpact(context.Background()), "plan", []schema.Change{
+		&schema.DropTable{T: schema.NewTable("logs").AddAttrs(&schema.TableStats{Rows: 500, DataSize: 8192})},
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, &migrate.Impact{Table: "logs", Rows: 500, Lock: migrate.LockExclusive, Reason: "drop table"}, plan.Changes[0].Impact)
+}
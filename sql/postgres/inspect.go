@@ -7,6 +7,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -39,18 +40,70 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 			}
 			s.Tables = append(s.Tables, t)
 		}
+		if opts != nil && opts.Stats {
+			if err := i.stats(ctx, s); err != nil {
+				return nil, err
+			}
+		}
 		s.Realm = realm
 	}
 	sqlx.LinkSchemaTables(schemas)
+	linkInherits(schemas)
 	return realm, nil
 }
 
+// InspectTables implements the schema.TablesInspector interface. Tables are inspected one
+// by one, and passed to fn without being added to their schema.
+func (i *inspect) InspectTables(ctx context.Context, opts *schema.InspectRealmOption, fn func(*schema.Table) error) error {
+	schemas, err := i.schemas(ctx, opts)
+	if err != nil {
+		return err
+	}
+	realm := &schema.Realm{Schemas: schemas, Attrs: []schema.Attr{&schema.Collation{V: i.collate}, &CType{V: i.ctype}}}
+	for _, s := range schemas {
+		s.Realm = realm
+		names, err := i.tableNames(ctx, s.Name, nil)
+		if err != nil {
+			return err
+		}
+		// Statistics are loaded once per schema, on stub
+		// tables, and copied to the inspected tables.
+		var stats *schema.Schema
+		if opts != nil && opts.Stats {
+			stats = schema.New(s.Name)
+			for _, name := range names {
+				stats.AddTables(schema.NewTable(name))
+			}
+			if err := i.stats(ctx, stats); err != nil {
+				return err
+			}
+		}
+		for _, name := range names {
+			t, err := i.inspectTable(ctx, name, &schema.InspectTableOptions{Schema: s.Name}, s)
+			if err != nil {
+				return err
+			}
+			if stats != nil {
+				if st, ok := stats.Table(name); ok {
+					t.Attrs = append(t.Attrs, st.Attrs...)
+				}
+			}
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // InspectSchema returns schema descriptions of the tables in the given schema.
 // If the schema name is empty, the result will be the attached schema.
 func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (s *schema.Schema, err error) {
 	var schemas []*schema.Schema
-	switch name {
-	case "":
+	if name == "" && len(i.searchPath) > 0 {
+		name = i.searchPath[0]
+	}
+	if name == "" {
 		rows, err := i.QueryContext(ctx, "SELECT CURRENT_SCHEMA()")
 		if err != nil {
 			return nil, fmt.Errorf("postgres: query attached schema: %w", err)
@@ -58,15 +111,15 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 		if err := sqlx.ScanOne(rows, &name); err != nil {
 			return nil, fmt.Errorf("postgres: scan attached schema: %w", err)
 		}
-		schemas = append(schemas, &schema.Schema{Name: name})
-	default:
-		if schemas, err = i.schemas(ctx, &schema.InspectRealmOption{Schemas: []string{name}}); err != nil {
-			return nil, err
-		}
-		if len(schemas) == 0 {
-			return nil, &schema.NotExistError{
-				Err: fmt.Errorf("postgres: schema %q was not found", name),
-			}
+	}
+	// The attached schema is inspected like any other
+	// schema, in order to load its attributes (e.g. comment).
+	if schemas, err = i.schemas(ctx, &schema.InspectRealmOption{Schemas: []string{name}}); err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, &schema.NotExistError{
+			Err: fmt.Errorf("postgres: schema %q was not found", name),
 		}
 	}
 	names, err := i.tableNames(ctx, name, opts)
@@ -81,12 +134,54 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 		}
 		s.Tables = append(s.Tables, t)
 	}
+	if opts != nil && opts.Stats {
+		if err := i.stats(ctx, s); err != nil {
+			return nil, err
+		}
+	}
 	sqlx.LinkSchemaTables(schemas)
+	linkInherits(schemas)
 	s.Realm = &schema.Realm{Schemas: schemas, Attrs: []schema.Attr{&schema.Collation{V: i.collate}, &CType{V: i.ctype}}}
 	return s, nil
 }
 
+// stats queries and sets the approximate statistics of the schema tables. The number
+// of rows is an estimation that is updated by VACUUM and ANALYZE, and it is unknown
+// (-1) for tables that were never vacuumed or analyzed. Redshift is not supported.
+func (i *inspect) stats(ctx context.Context, s *schema.Schema) error {
+	if i.redshift || len(s.Tables) == 0 {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, statsQuery, s.Name)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q statistics: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name             string
+			n, data, indexes int64
+		)
+		if err := rows.Scan(&name, &n, &data, &indexes); err != nil {
+			return fmt.Errorf("postgres: scanning table statistics: %w", err)
+		}
+		// Tables that were excluded from the inspection are skipped.
+		t, ok := s.Table(name)
+		if !ok {
+			continue
+		}
+		if n < 0 {
+			n = -1
+		}
+		t.Attrs = append(t.Attrs, &schema.TableStats{Rows: n, DataSize: data, IndexSize: indexes})
+	}
+	return rows.Err()
+}
+
 func (i *inspect) inspectTable(ctx context.Context, name string, opts *schema.InspectTableOptions, top *schema.Schema) (*schema.Table, error) {
+	if i.redshift {
+		return i.inspectRedshiftTable(ctx, name, opts, top)
+	}
 	t, err := i.table(ctx, name, opts)
 	if err != nil {
 		return nil, err
@@ -116,18 +211,22 @@ func (i *inspect) table(ctx context.Context, name string, opts *schema.InspectTa
 		args  = []interface{}{name}
 		query = tableQuery
 	)
-	if opts != nil && opts.Schema != "" {
+	switch {
+	case opts != nil && opts.Schema != "":
 		query = tableSchemaQuery
 		args = append(args, opts.Schema)
+	case len(i.searchPath) > 0:
+		query = tableSchemaQuery
+		args = append(args, i.searchPath[0])
 	}
 	var (
-		tSchema, comment sql.NullString
-		rows, err        = i.QueryContext(ctx, query, args...)
+		tSchema, comment, tablespace, inherits, options sql.NullString
+		rows, err                                       = i.QueryContext(ctx, query, args...)
 	)
 	if err != nil {
 		return nil, err
 	}
-	if err := sqlx.ScanOne(rows, &tSchema, &comment); err != nil {
+	if err := sqlx.ScanOne(rows, &tSchema, &comment, &tablespace, &inherits, &options); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, &schema.NotExistError{
 				Err: fmt.Errorf("postgres: table %q was not found", name),
@@ -141,12 +240,69 @@ func (i *inspect) table(ctx context.Context, name string, opts *schema.InspectTa
 			Text: comment.String,
 		})
 	}
+	if sqlx.ValidString(tablespace) {
+		t.Attrs = append(t.Attrs, &Tablespace{
+			N: tablespace.String,
+		})
+	}
+	if sqlx.ValidString(inherits) {
+		var names [][2]string
+		if err := json.Unmarshal([]byte(inherits.String), &names); err != nil {
+			return nil, fmt.Errorf("postgres: scan inherited tables of %q: %w", name, err)
+		}
+		// Parent tables are linked to their schema
+		// elements after the schema is inspected.
+		a := &Inherits{}
+		for _, n := range names {
+			a.T = append(a.T, &schema.Table{Name: n[1], Schema: &schema.Schema{Name: n[0]}})
+		}
+		t.Attrs = append(t.Attrs, a)
+	}
+	if sqlx.ValidString(options) {
+		p, err := tableStorageParams(options.String)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: parsing storage parameters of table %q: %w", name, err)
+		}
+		if p != nil {
+			t.Attrs = append(t.Attrs, p)
+		}
+	}
 	return t, nil
 }
 
+// linkInherits links the parent tables of the Inherits
+// attributes to the inspected tables, if they exist.
+func linkInherits(schemas []*schema.Schema) {
+	byName := make(map[string]*schema.Table)
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			byName[qualified(t)] = t
+		}
+	}
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			for _, a := range t.Attrs {
+				a, ok := a.(*Inherits)
+				if !ok {
+					continue
+				}
+				for i, p := range a.T {
+					if p, ok := byName[qualified(p)]; ok {
+						a.T[i] = p
+					}
+				}
+			}
+		}
+	}
+}
+
 // columns queries and appends the columns of the given table.
 func (i *inspect) columns(ctx context.Context, t *schema.Table) error {
-	rows, err := i.QueryContext(ctx, columnsQuery, t.Schema.Name, t.Name)
+	query := columnsQuery
+	if i.redshift {
+		query = redshiftColumnsQuery
+	}
+	rows, err := i.QueryContext(ctx, query, t.Schema.Name, t.Name)
 	if err != nil {
 		return fmt.Errorf("postgres: querying %q columns: %w", t.Name, err)
 	}
@@ -168,12 +324,12 @@ func (i *inspect) columns(ctx context.Context, t *schema.Table) error {
 // addColumn scans the current row and adds a new column from it to the table.
 func (i *inspect) addColumn(t *schema.Table, rows *sql.Rows) error {
 	var (
-		typid, maxlen, precision, timeprecision, scale, seqstart, seqinc                               sql.NullInt64
+		typid, maxlen, precision, timeprecision, scale, seqstart, seqinc, dims                         sql.NullInt64
 		name, typ, nullable, defaults, udt, identity, generation, charset, collation, comment, typtype sql.NullString
 	)
 	if err := rows.Scan(
 		&name, &typ, &nullable, &defaults, &maxlen, &precision, &timeprecision, &scale, &charset,
-		&collation, &udt, &identity, &seqstart, &seqinc, &generation, &comment, &typtype, &typid,
+		&collation, &udt, &identity, &seqstart, &seqinc, &generation, &comment, &typtype, &typid, &dims,
 	); err != nil {
 		return err
 	}
@@ -193,7 +349,27 @@ func (i *inspect) addColumn(t *schema.Table, rows *sql.Rows) error {
 		scale:         scale.Int64,
 		typtype:       typtype.String,
 		typid:         typid.Int64,
+		dims:          dims.Int64,
 	})
+	var raw string
+	switch t := c.Type.Type.(type) {
+	case *schema.UnsupportedType:
+		raw = t.T
+	case *UserDefinedType:
+		raw = t.T
+	}
+	if raw != "" {
+		ct, ok, err := customTypes.Parse(raw)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			ct, ok = sqlx.ParseCustomType(raw, i.parsers)
+		}
+		if ok {
+			c.Type.Type = ct
+		}
+	}
 	if sqlx.ValidString(defaults) {
 		c.Default = defaultExpr(c, defaults.String)
 	}
@@ -264,19 +440,30 @@ func columnType(c *columnDesc) schema.Type {
 		typ = &UUIDType{T: t}
 	case TypeXML:
 		typ = &XMLType{T: t}
+	case TypeInt4Range, TypeInt4MultiRange, TypeInt8Range, TypeInt8MultiRange, TypeNumRange, TypeNumMultiRange,
+		TypeTSRange, TypeTSMultiRange, TypeTSTZRange, TypeTSTZMultiRange, TypeDateRange, TypeDateMultiRange:
+		typ = &RangeType{T: t}
 	case TypeArray:
 		// Note that for ARRAY types, the 'udt_name' column holds the array type
 		// prefixed with '_'. For example, for 'integer[]' the result is '_int',
 		// and for 'text[N][M]' the result is also '_text'. That's because, the
-		// database ignores any size or multi-dimensions constraints.
-		typ = &ArrayType{T: strings.TrimPrefix(c.udt, "_") + "[]"}
+		// database ignores any size or multi-dimensions constraints. However,
+		// the number of the declared dimensions is kept in the catalog.
+		dims := int(c.dims)
+		if dims < 1 {
+			dims = 1
+		}
+		typ = &ArrayType{T: strings.TrimPrefix(c.udt, "_") + strings.Repeat("[]", dims)}
 	case TypeUserDefined:
 		typ = &UserDefinedType{T: c.udt}
 		// The `typtype` column is set to 'e' for enum types, and the
 		// values are filled in batch after the rows above is closed.
 		// https://www.postgresql.org/docs/current/catalog-pg-type.html
-		if c.typtype == "e" {
+		switch c.typtype {
+		case "e":
 			typ = &enumType{T: c.udt, ID: c.typid}
+		case "r", "m":
+			typ = &RangeType{T: c.udt}
 		}
 	default:
 		typ = &schema.UnsupportedType{T: t}
@@ -349,12 +536,13 @@ func (i *inspect) addIndexes(t *schema.Table, rows *sql.Rows) error {
 	names := make(map[string]*schema.Index)
 	for rows.Next() {
 		var (
-			name, typ                            string
-			uniq, primary                        bool
-			desc, nullsfirst, nullslast          sql.NullBool
-			column, contype, pred, expr, comment sql.NullString
+			name, typ                                        string
+			uniq, primary                                    bool
+			desc, nullsfirst, nullslast                      sql.NullBool
+			column, contype, pred, expr, comment, tablespace sql.NullString
+			options                                          sql.NullString
 		)
-		if err := rows.Scan(&name, &typ, &column, &primary, &uniq, &contype, &pred, &expr, &desc, &nullsfirst, &nullslast, &comment); err != nil {
+		if err := rows.Scan(&name, &typ, &column, &primary, &uniq, &contype, &pred, &expr, &desc, &nullsfirst, &nullslast, &comment, &tablespace, &options); err != nil {
 			return fmt.Errorf("postgres: scanning indexes for table %q: %w", t.Name, err)
 		}
 		idx, ok := names[name]
@@ -376,6 +564,18 @@ func (i *inspect) addIndexes(t *schema.Table, rows *sql.Rows) error {
 			if sqlx.ValidString(pred) {
 				idx.Attrs = append(idx.Attrs, &IndexPredicate{P: pred.String})
 			}
+			if sqlx.ValidString(tablespace) {
+				idx.Attrs = append(idx.Attrs, &Tablespace{N: tablespace.String})
+			}
+			if sqlx.ValidString(options) {
+				p, err := indexStorageParams(options.String)
+				if err != nil {
+					return fmt.Errorf("postgres: parsing storage parameters of index %q: %w", name, err)
+				}
+				if p != nil {
+					idx.Attrs = append(idx.Attrs, p)
+				}
+			}
 			names[name] = idx
 			if primary {
 				t.PrimaryKey = idx
@@ -409,6 +609,181 @@ func (i *inspect) addIndexes(t *schema.Table, rows *sql.Rows) error {
 	return nil
 }
 
+// indexStorageParams parses the reloptions of an index (e.g. "{fillfactor=70,fastupdate=off}")
+// into its storage parameters. Nil is returned if no supported parameter was set.
+func indexStorageParams(options string) (*IndexStorageParams, error) {
+	var (
+		set bool
+		p   IndexStorageParams
+	)
+	for _, o := range strings.Split(strings.Trim(options, "{}"), ",") {
+		o = strings.Trim(strings.TrimSpace(o), `"`)
+		i := strings.IndexByte(o, '=')
+		if i == -1 {
+			continue
+		}
+		var (
+			err  error
+			k, v = o[:i], o[i+1:]
+		)
+		switch strings.ToLower(k) {
+		case "fillfactor":
+			p.FillFactor, err = strconv.ParseInt(v, 10, 64)
+		case "pages_per_range":
+			p.PagesPerRange, err = strconv.ParseInt(v, 10, 64)
+		case "autosummarize":
+			p.AutoSummarize, err = parseBool(v)
+		case "fastupdate":
+			var b bool
+			b, err = parseBool(v)
+			p.FastUpdate = &b
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %q: %w", k, err)
+		}
+		set = true
+	}
+	if !set {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+// tableParamNames lists the supported table storage parameters, in the order they are written.
+var tableParamNames = []string{
+	"fillfactor", "toast_tuple_target", "parallel_workers", "autovacuum_enabled",
+	"autovacuum_vacuum_threshold", "autovacuum_vacuum_scale_factor",
+	"autovacuum_vacuum_insert_threshold", "autovacuum_vacuum_insert_scale_factor",
+	"autovacuum_analyze_threshold", "autovacuum_analyze_scale_factor",
+	"autovacuum_vacuum_cost_delay", "autovacuum_vacuum_cost_limit",
+	"autovacuum_freeze_min_age", "autovacuum_freeze_max_age", "autovacuum_freeze_table_age",
+}
+
+// ints returns the integer parameters of the table, keyed by their name.
+func (p *TableStorageParams) ints() map[string]**int64 {
+	return map[string]**int64{
+		"fillfactor":                         &p.FillFactor,
+		"toast_tuple_target":                 &p.ToastTupleTarget,
+		"parallel_workers":                   &p.ParallelWorkers,
+		"autovacuum_vacuum_threshold":        &p.AutovacuumVacuumThreshold,
+		"autovacuum_vacuum_insert_threshold": &p.AutovacuumVacuumInsertThreshold,
+		"autovacuum_analyze_threshold":       &p.AutovacuumAnalyzeThreshold,
+		"autovacuum_vacuum_cost_limit":       &p.AutovacuumVacuumCostLimit,
+		"autovacuum_freeze_min_age":          &p.AutovacuumFreezeMinAge,
+		"autovacuum_freeze_max_age":          &p.AutovacuumFreezeMaxAge,
+		"autovacuum_freeze_table_age":        &p.AutovacuumFreezeTableAge,
+	}
+}
+
+// floats returns the floating-point parameters of the table, keyed by their name.
+func (p *TableStorageParams) floats() map[string]**float64 {
+	return map[string]**float64{
+		"autovacuum_vacuum_scale_factor":        &p.AutovacuumVacuumScaleFactor,
+		"autovacuum_vacuum_insert_scale_factor": &p.AutovacuumVacuumInsertScaleFactor,
+		"autovacuum_analyze_scale_factor":       &p.AutovacuumAnalyzeScaleFactor,
+		"autovacuum_vacuum_cost_delay":          &p.AutovacuumVacuumCostDelay,
+	}
+}
+
+// Set sets the parameter with the given name from its string value
+// (e.g. "0.05" or "off"). An error is returned if the parameter is not
+// supported, or its value is invalid.
+func (p *TableStorageParams) Set(name, value string) error {
+	name = strings.ToLower(name)
+	if v, ok := p.ints()[name]; ok {
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for %q: %w", name, err)
+		}
+		*v = &i
+		return nil
+	}
+	if v, ok := p.floats()[name]; ok {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for %q: %w", name, err)
+		}
+		*v = &f
+		return nil
+	}
+	if name == "autovacuum_enabled" {
+		b, err := parseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %q: %w", name, err)
+		}
+		p.AutovacuumEnabled = &b
+		return nil
+	}
+	return fmt.Errorf("unsupported storage parameter %q", name)
+}
+
+// Value returns the value of the parameter with the given name,
+// formatted as it is written in the WITH clause, if it is set.
+func (p *TableStorageParams) Value(name string) (string, bool) {
+	if v, ok := p.ints()[name]; ok && *v != nil {
+		return strconv.FormatInt(**v, 10), true
+	}
+	if v, ok := p.floats()[name]; ok && *v != nil {
+		return strconv.FormatFloat(**v, 'g', -1, 64), true
+	}
+	if name == "autovacuum_enabled" && p.AutovacuumEnabled != nil {
+		return strconv.FormatBool(*p.AutovacuumEnabled), true
+	}
+	return "", false
+}
+
+// tableStorageParams parses the reloptions of a table (e.g. "{fillfactor=70,autovacuum_enabled=off}")
+// into its storage parameters. Nil is returned if no supported parameter was set.
+func tableStorageParams(options string) (*TableStorageParams, error) {
+	var (
+		set bool
+		p   TableStorageParams
+	)
+	for _, o := range strings.Split(strings.Trim(options, "{}"), ",") {
+		o = strings.Trim(strings.TrimSpace(o), `"`)
+		i := strings.IndexByte(o, '=')
+		if i == -1 {
+			continue
+		}
+		// Unsupported parameters (e.g. user_catalog_table) are ignored.
+		if !supportedTableParam(o[:i]) {
+			continue
+		}
+		if err := p.Set(o[:i], o[i+1:]); err != nil {
+			return nil, err
+		}
+		set = true
+	}
+	if !set {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+// supportedTableParam reports if the given table storage parameter is supported.
+func supportedTableParam(name string) bool {
+	for _, n := range tableParamNames {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBool parses the boolean value of a parameter,
+// as it is accepted by PostgreSQL (e.g. on, off, yes).
+func parseBool(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "on", "true", "yes", "1", "t", "y":
+		return true, nil
+	case "off", "false", "no", "0", "f", "n":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean: %q", v)
+}
+
 // fks queries and appends the foreign keys of the given table.
 func (i *inspect) fks(ctx context.Context, t *schema.Table) error {
 	rows, err := i.QueryContext(ctx, fksQuery, t.Schema.Name, t.Name)
@@ -440,10 +815,10 @@ func (i *inspect) addChecks(t *schema.Table, rows *sql.Rows) error {
 	names := make(map[string]*schema.Check)
 	for rows.Next() {
 		var (
-			noInherit                     bool
+			noInherit, validated          bool
 			name, column, clause, indexes string
 		)
-		if err := rows.Scan(&name, &clause, &column, &indexes, &noInherit); err != nil {
+		if err := rows.Scan(&name, &clause, &column, &indexes, &noInherit, &validated); err != nil {
 			return fmt.Errorf("postgres: scanning check: %w", err)
 		}
 		if _, ok := t.Column(column); !ok {
@@ -455,6 +830,9 @@ func (i *inspect) addChecks(t *schema.Table, rows *sql.Rows) error {
 			if noInherit {
 				check.Attrs = append(check.Attrs, &NoInherit{})
 			}
+			if !validated {
+				check.Attrs = append(check.Attrs, &NotValid{})
+			}
 			names[name] = check
 			t.Attrs = append(t.Attrs, check)
 		}
@@ -467,11 +845,14 @@ func (i *inspect) addChecks(t *schema.Table, rows *sql.Rows) error {
 // schemas returns the list of the schemas in the database.
 func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
 	var (
-		args  []interface{}
-		query = schemasQuery
+		args             []interface{}
+		query, queryArgs = schemasQuery, schemasQueryArgs
 	)
+	if i.redshift {
+		query, queryArgs = redshiftSchemasQuery, redshiftSchemasQueryArgs
+	}
 	if opts != nil && len(opts.Schemas) > 0 {
-		query, args = inStrings(opts.Schemas, schemasQueryArgs, args)
+		query, args = inStrings(opts.Schemas, queryArgs, args)
 	}
 	rows, err := i.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -480,13 +861,30 @@ func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption)
 	defer rows.Close()
 	var schemas []*schema.Schema
 	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
+		var (
+			name    string
+			comment sql.NullString
+		)
+		if err := rows.Scan(&name, &comment); err != nil {
 			return nil, err
 		}
-		schemas = append(schemas, &schema.Schema{
+		s := &schema.Schema{
 			Name: name,
-		})
+		}
+		if sqlx.ValidString(comment) {
+			s.Attrs = append(s.Attrs, &schema.Comment{Text: comment.String})
+		}
+		schemas = append(schemas, s)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if i.collations && !i.redshift {
+		for _, s := range schemas {
+			if err := i.inspectCollations(ctx, s); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return schemas, nil
 }
@@ -563,7 +961,7 @@ func canConvert(t *schema.ColumnType, x string) (string, bool) {
 		if sqlx.IsLiteralNumber(x) {
 			return x, true
 		}
-	case *ArrayType, *schema.BinaryType, *schema.JSONType, *NetworkType, *schema.SpatialType, *schema.StringType, *schema.TimeType, *UUIDType, *XMLType:
+	case *ArrayType, *RangeType, *schema.BinaryType, *schema.JSONType, *NetworkType, *schema.SpatialType, *schema.StringType, *schema.TimeType, *UUIDType, *XMLType:
 		return q, true
 	}
 	return "", false
@@ -598,6 +996,13 @@ type (
 		T string
 	}
 
+	// RangeType defines a range or a multirange type. Both built-in and user-defined.
+	// https://www.postgresql.org/docs/current/rangetypes.html
+	RangeType struct {
+		schema.Type
+		T string
+	}
+
 	// BitType defines a bit type.
 	// https://www.postgresql.org/docs/current/datatype-bit.html
 	BitType struct {
@@ -666,6 +1071,25 @@ type (
 		T string // BTREE, BRIN, HASH, GiST, SP-GiST, GIN.
 	}
 
+	// IndexStorageParams describes the storage parameters of an index (i.e. its WITH
+	// clause). Zero values stand for the defaults of the index access method.
+	// https://www.postgresql.org/docs/current/sql-createindex.html#SQL-CREATEINDEX-STORAGE-PARAMETERS
+	IndexStorageParams struct {
+		schema.Attr
+		// FillFactor is the percentage of the index pages that are filled when
+		// it is built. Supported by the BTREE, HASH, GiST and SP-GiST methods.
+		FillFactor int64
+		// PagesPerRange is the number of table blocks that make up one block
+		// range of a BRIN index. Defaults to 128.
+		PagesPerRange int64
+		// AutoSummarize enables the summarization of the previous block
+		// range of a BRIN index, whenever an insertion is detected on the next one.
+		AutoSummarize bool
+		// FastUpdate controls the pending list of GIN indexes. A nil value
+		// stands for the default, which is on.
+		FastUpdate *bool
+	}
+
 	// IndexPredicate describes a partial index predicate.
 	// https://www.postgresql.org/docs/current/catalog-pg-index.html
 	IndexPredicate struct {
@@ -673,6 +1097,45 @@ type (
 		P string
 	}
 
+	// Inherits describes the parent tables of a table (i.e. the INHERITS clause).
+	// Columns and CHECK constraints of parent tables are inherited by their children.
+	// https://www.postgresql.org/docs/current/ddl-inherit.html
+	Inherits struct {
+		schema.Attr
+		T []*schema.Table
+	}
+
+	// Tablespace describes the tablespace of a table or an index. Elements without
+	// this attribute are stored in the default tablespace of the database.
+	// https://www.postgresql.org/docs/current/manage-ag-tablespaces.html
+	Tablespace struct {
+		schema.Attr
+		N string
+	}
+
+	// TableStorageParams describes the storage parameters of a table (i.e. its WITH clause),
+	// such as the fillfactor and the per-table autovacuum settings. Nil fields are not set on
+	// the table, and their values are taken from the server configuration.
+	// https://www.postgresql.org/docs/current/sql-createtable.html#SQL-CREATETABLE-STORAGE-PARAMETERS
+	TableStorageParams struct {
+		schema.Attr
+		FillFactor                        *int64
+		ToastTupleTarget                  *int64
+		ParallelWorkers                   *int64
+		AutovacuumEnabled                 *bool
+		AutovacuumVacuumThreshold         *int64
+		AutovacuumVacuumScaleFactor       *float64
+		AutovacuumVacuumInsertThreshold   *int64
+		AutovacuumVacuumInsertScaleFactor *float64
+		AutovacuumAnalyzeThreshold        *int64
+		AutovacuumAnalyzeScaleFactor      *float64
+		AutovacuumVacuumCostDelay         *float64
+		AutovacuumVacuumCostLimit         *int64
+		AutovacuumFreezeMinAge            *int64
+		AutovacuumFreezeMaxAge            *int64
+		AutovacuumFreezeTableAge          *int64
+	}
+
 	// IndexColumnProperty describes an index column property.
 	// https://www.postgresql.org/docs/current/functions-info.html#FUNCTIONS-INFO-INDEX-COLUMN-PROPS
 	IndexColumnProperty struct {
@@ -689,6 +1152,14 @@ type (
 		schema.Attr
 	}
 
+	// NotValid attribute defines the NOT VALID flag for CHECK constraint. Existing
+	// rows are not checked when such a constraint is added, and it is validated
+	// separately using the "VALIDATE CONSTRAINT" command.
+	// https://www.postgresql.org/docs/current/sql-altertable.html
+	NotValid struct {
+		schema.Attr
+	}
+
 	// CheckColumns attribute hold the column named used by the CHECK constraints.
 	// This attribute is added on inspection for internal usage and has no meaning
 	// on migration.
@@ -702,11 +1173,14 @@ const (
 	// Query to list runtime parameters.
 	paramsQuery = `SELECT setting FROM pg_settings WHERE name IN ('lc_collate', 'lc_ctype', 'server_version_num') ORDER BY name`
 
+	// Query to detect Amazon Aurora clusters and their available extensions.
+	auroraQuery = `SELECT to_regproc('aurora_version') IS NOT NULL, COALESCE(current_setting('rds.extensions', true), '')`
+
 	// Query to list database schemas.
-	schemasQuery = "SELECT schema_name FROM information_schema.schemata WHERE schema_name NOT IN ('information_schema', 'pg_catalog', 'pg_toast') AND schema_name NOT LIKE 'pg_%temp_%' ORDER BY schema_name"
+	schemasQuery = "SELECT schema_name, obj_description(to_regnamespace(quote_ident(schema_name))::oid, 'pg_namespace') AS comment FROM information_schema.schemata WHERE schema_name NOT IN ('information_schema', 'pg_catalog', 'pg_toast') AND schema_name NOT LIKE 'pg_%temp_%' ORDER BY schema_name"
 
 	// Query to list specific database schemas.
-	schemasQueryArgs = "SELECT schema_name FROM information_schema.schemata WHERE schema_name %s ORDER BY schema_name"
+	schemasQueryArgs = "SELECT schema_name, obj_description(to_regnamespace(quote_ident(schema_name))::oid, 'pg_namespace') AS comment FROM information_schema.schemata WHERE schema_name %s ORDER BY schema_name"
 
 	// Query to list schema tables.
 	tablesQuery = "SELECT table_name FROM information_schema.tables WHERE table_type = 'BASE TABLE' AND table_schema = $1 ORDER BY table_name"
@@ -718,11 +1192,22 @@ const (
 	tableQuery = `
 SELECT
 	t1.table_schema,
-	pg_catalog.obj_description(t2.oid, 'pg_class') AS COMMENT
+	pg_catalog.obj_description(t2.oid, 'pg_class') AS COMMENT,
+	t3.spcname AS tablespace,
+	(
+		SELECT json_agg(json_build_array(n.nspname, p.relname) ORDER BY i.inhseqno)
+		FROM pg_catalog.pg_inherits AS i
+		JOIN pg_catalog.pg_class AS p ON p.oid = i.inhparent
+		JOIN pg_catalog.pg_namespace AS n ON n.oid = p.relnamespace
+		WHERE i.inhrelid = t2.oid
+	) AS inherits,
+	t2.reloptions AS options
 FROM
 	information_schema.tables AS t1
 	INNER JOIN pg_catalog.pg_class AS t2
 	ON t1.table_name = t2.relname
+	LEFT JOIN pg_catalog.pg_tablespace AS t3
+	ON t3.oid = t2.reltablespace
 WHERE
 	t1.table_type = 'BASE TABLE'
 	AND t1.table_name = $1
@@ -731,15 +1216,43 @@ WHERE
 	tableSchemaQuery = `
 SELECT
 	t1.TABLE_SCHEMA,
-	pg_catalog.obj_description(t2.oid, 'pg_class') AS COMMENT
+	pg_catalog.obj_description(t2.oid, 'pg_class') AS COMMENT,
+	t3.spcname AS tablespace,
+	(
+		SELECT json_agg(json_build_array(n.nspname, p.relname) ORDER BY i.inhseqno)
+		FROM pg_catalog.pg_inherits AS i
+		JOIN pg_catalog.pg_class AS p ON p.oid = i.inhparent
+		JOIN pg_catalog.pg_namespace AS n ON n.oid = p.relnamespace
+		WHERE i.inhrelid = t2.oid
+	) AS inherits,
+	t2.reloptions AS options
 FROM
 	INFORMATION_SCHEMA.TABLES AS t1
 	JOIN pg_catalog.pg_class AS t2
 	ON t1.table_name = t2.relname
+	LEFT JOIN pg_catalog.pg_tablespace AS t3
+	ON t3.oid = t2.reltablespace
 WHERE
 	t1.TABLE_TYPE = 'BASE TABLE'
 	AND t1.TABLE_NAME = $1
 	AND t1.TABLE_SCHEMA = $2
+`
+	// Query to list the approximate statistics of the schema tables.
+	statsQuery = `
+SELECT
+	c.relname,
+	c.reltuples::bigint,
+	pg_catalog.pg_table_size(c.oid),
+	pg_catalog.pg_indexes_size(c.oid)
+FROM
+	pg_catalog.pg_class AS c
+	JOIN pg_catalog.pg_namespace AS n
+	ON n.oid = c.relnamespace
+WHERE
+	n.nspname = $1
+	AND c.relkind IN ('r', 'p')
+ORDER BY
+	c.relname
 `
 	// Query to list table columns.
 	columnsQuery = `
@@ -759,15 +1272,23 @@ SELECT
 	t1.identity_start,
 	t1.identity_increment,
 	t1.identity_generation,
-	col_description(to_regclass("table_schema" || '.' || "table_name")::oid, "ordinal_position") AS comment,
+	col_description(to_regclass(quote_ident("table_schema") || '.' || quote_ident("table_name"))::oid, "ordinal_position") AS comment,
 	t2.typtype,
-	t2.oid
+	t2.oid,
+	t3.attndims AS array_dims
 FROM
 	"information_schema"."columns" AS t1
+	LEFT JOIN pg_catalog.pg_namespace AS t4
+	ON t4.nspname = t1.udt_schema
 	LEFT JOIN pg_catalog.pg_type AS t2
-	ON t1.udt_name = t2.typname
+	ON t2.typnamespace = t4.oid AND t2.typname = t1.udt_name
+	LEFT JOIN pg_catalog.pg_attribute AS t3
+	ON t3.attrelid = to_regclass(quote_ident("table_schema") || '.' || quote_ident("table_name"))::oid
+	AND t3.attname = t1.column_name
 WHERE
 	TABLE_SCHEMA = $1 AND TABLE_NAME = $2
+ORDER BY
+	t1.ordinal_position
 `
 
 	// Query to list table indexes.
@@ -784,7 +1305,9 @@ SELECT
 	pg_index_column_has_property(idx.indexrelid, a.attnum, 'desc') AS desc,
 	pg_index_column_has_property(idx.indexrelid, a.attnum, 'nulls_first') AS nulls_first,
 	pg_index_column_has_property(idx.indexrelid, a.attnum, 'nulls_last') AS nulls_last,
-	obj_description(to_regclass($1 || i.relname)::oid) AS comment
+	obj_description(i.oid, 'pg_class') AS comment,
+	ts.spcname AS tablespace,
+	i.reloptions AS options
 FROM
 	pg_index idx
 	JOIN pg_class i
@@ -795,8 +1318,10 @@ FROM
 	ON a.attrelid = idx.indexrelid
 	JOIN pg_am am
 	ON am.oid = i.relam
+	LEFT JOIN pg_tablespace ts
+	ON ts.oid = i.reltablespace
 WHERE
-	idx.indrelid = to_regclass($1 || '.' || $2)::oid
+	idx.indrelid = to_regclass(quote_ident($1) || '.' || quote_ident($2))::oid
 	AND COALESCE(c.contype, '') <> 'f'
 ORDER BY
 	index_name, a.attnum
@@ -811,12 +1336,14 @@ SELECT
     t3.column_name AS referenced_column_name,
     t3.table_schema AS referenced_schema_name,
     t4.update_rule,
-    t4.delete_rule
+    t4.delete_rule,
+    t4.match_option
 FROM
     information_schema.table_constraints t1
     JOIN information_schema.key_column_usage t2
     ON t1.constraint_name = t2.constraint_name
     AND t1.table_schema = t2.constraint_schema
+    AND t1.table_name = t2.table_name
     JOIN information_schema.constraint_column_usage t3
     ON t1.constraint_name = t3.constraint_name
     AND t1.table_schema = t3.constraint_schema
@@ -836,10 +1363,11 @@ ORDER BY
 	checksQuery = `
 SELECT
 	t1.conname AS constraint_name,
-	pg_get_expr(t1.conbin, to_regclass($1 || '.' || $2)::oid) as expression,
+	pg_get_expr(t1.conbin, to_regclass(quote_ident($1) || '.' || quote_ident($2))::oid) as expression,
 	t2.attname as column_name,
 	t1.conkey as column_indexes,
-	t1.connoinherit as no_inherit
+	t1.connoinherit as no_inherit,
+	t1.convalidated as validated
 FROM
 	pg_catalog.pg_constraint t1
 	JOIN pg_attribute t2
@@ -847,7 +1375,7 @@ FROM
 	AND t2.attnum = ANY (t1.conkey)
 WHERE
 	t1.contype = 'c'
-	AND t1.conrelid = to_regclass($1 || '.' || $2)::oid
+	AND t1.conrelid = to_regclass(quote_ident($1) || '.' || quote_ident($2))::oid
 ORDER BY
 	t1.conname, array_position(t1.conkey, t2.attnum)
 `
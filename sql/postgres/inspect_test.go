@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -30,37 +31,40 @@ func TestDriver_InspectTable(t *testing.T) {
 				m.ExpectQuery(sqltest.Escape(columnsQuery)).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
- column_name |          data_type          | is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | character_set_name | collation_name |  udt_name   | is_identity | identity_start | identity_increment | identity_generation | comment | typtype |  oid  
--------------+-----------------------------+-------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+--------------------+----------------+-------------+-------------+----------------+--------------------+---------------------+---------+---------+-------
- id          | bigint                      | NO          |                                 |                          |                64 |                    |             0 |                    |                | int8        | YES         |      100       |          1         |    BY DEFAULT       |         | b       |    20
- rank        | integer                     | YES         |                                 |                          |                32 |                    |             0 |                    |                | int4        | NO          |                |                    |                     | rank    | b       |    23
- c1          | smallint                    | NO          |           1000                  |                          |                16 |                    |             0 |                    |                | int2        | NO          |                |                    |                     |         | b       |    21
- c2          | bit                         | NO          |                                 |                        1 |                   |                    |               |                    |                | bit         | NO          |                |                    |                     |         | b       |  1560
- c3          | bit varying                 | NO          |                                 |                       10 |                   |                    |               |                    |                | varbit      | NO          |                |                    |                     |         | b       |  1562
- c4          | boolean                     | NO          |                                 |                          |                   |                    |               |                    |                | bool        | NO          |                |                    |                     |         | b       |    16
- c5          | bytea                       | NO          |                                 |                          |                   |                    |               |                    |                | bytea       | NO          |                |                    |                     |         | b       |    17
- c6          | character                   | NO          |                                 |                      100 |                   |                    |               |                    |                | bpchar      | NO          |                |                    |                     |         | b       |  1042
- c7          | character varying           | NO          |                                 |                          |                   |                    |               |                    |                | varchar     | NO          |                |                    |                     |         | b       |  1043
- c8          | cidr                        | NO          |                                 |                          |                   |                    |               |                    |                | cidr        | NO          |                |                    |                     |         | b       |   650
- c9          | circle                      | NO          |                                 |                          |                   |                    |               |                    |                | circle      | NO          |                |                    |                     |         | b       |   718
- c10         | date                        | NO          |                                 |                          |                   |                    |               |                    |                | date        | NO          |                |                    |                     |         | b       |  1082
- c11         | time with time zone         | NO          |                                 |                          |                   |                    |               |                    |                | timetz      | NO          |                |                    |                     |         | b       |  1266
- c12         | double precision            | NO          |                                 |                          |                53 |                    |               |                    |                | float8      | NO          |                |                    |                     |         | b       |   701
- c13         | real                        | NO          |           random()              |                          |                24 |                    |               |                    |                | float4      | NO          |                |                    |                     |         | b       |   700
- c14         | json                        | NO          |           '{}'::json            |                          |                   |                    |               |                    |                | json        | NO          |                |                    |                     |         | b       |   114
- c15         | jsonb                       | NO          |           '{}'::jsonb           |                          |                   |                    |               |                    |                | jsonb       | NO          |                |                    |                     |         | b       |  3802
- c16         | money                       | NO          |                                 |                          |                   |                    |               |                    |                | money       | NO          |                |                    |                     |         | b       |   790
- c17         | numeric                     | NO          |                                 |                          |                   |                    |               |                    |                | numeric     | NO          |                |                    |                     |         | b       |  1700
- c18         | numeric                     | NO          |                                 |                          |                 4 |                    |             4 |                    |                | numeric     | NO          |                |                    |                     |         | b       |  1700
- c19         | integer                     | NO          | nextval('t1_c19_seq'::regclass) |                          |                32 |                    |             0 |                    |                | int4        | NO          |                |                    |                     |         | b       |    23
- c20         | uuid                        | NO          |                                 |                          |                   |                    |               |                    |                | uuid        | NO          |                |                    |                     |         | b       |  2950
- c21         | xml                         | NO          |                                 |                          |                   |                    |               |                    |                | xml         | NO          |                |                    |                     |         | b       |   142
- c22         | ARRAY                       | YES         |                                 |                          |                   |                    |               |                    |                | _int4       | NO          |                |                    |                     |         | b       |  1007
- c23         | USER-DEFINED                | YES         |                                 |                          |                   |                    |               |                    |                | ltree       | NO          |                |                    |                     |         | b       | 16535
- c24         | USER-DEFINED                | NO          |                                 |                          |                   |                    |               |                    |                | state       | NO          |                |                    |                     |         | e       | 16774
- c25         | timestamp without time zone | NO          |            now()                |                          |                   |                  4 |               |                    |                | timestamp   | NO          |                |                    |                     |         | b       |  1114
- c26         | timestamp with time zone    | NO          |                                 |                          |                   |                  6 |               |                    |                | timestamptz | NO          |                |                    |                     |         | b       |  1184
- c27         | time without time zone      | NO          |                                 |                          |                   |                  6 |               |                    |                | time        | NO          |                |                    |                     |         | b       |  1266
+ column_name |          data_type          | is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | character_set_name | collation_name |  udt_name   | is_identity | identity_start | identity_increment | identity_generation | comment | typtype |  oid | array_dims
+-------------+-----------------------------+-------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+--------------------+----------------+-------------+-------------+----------------+--------------------+---------------------+---------+---------+-------+------------
+ id          | bigint                      | NO          |                                 |                          |                64 |                    |             0 |                    |                | int8        | YES         |      100       |          1         |    BY DEFAULT       |         | b       |    20 |
+ rank        | integer                     | YES         |                                 |                          |                32 |                    |             0 |                    |                | int4        | NO          |                |                    |                     | rank    | b       |    23 |
+ c1          | smallint                    | NO          |           1000                  |                          |                16 |                    |             0 |                    |                | int2        | NO          |                |                    |                     |         | b       |    21 |
+ c2          | bit                         | NO          |                                 |                        1 |                   |                    |               |                    |                | bit         | NO          |                |                    |                     |         | b       |  1560 |
+ c3          | bit varying                 | NO          |                                 |                       10 |                   |                    |               |                    |                | varbit      | NO          |                |                    |                     |         | b       |  1562 |
+ c4          | boolean                     | NO          |                                 |                          |                   |                    |               |                    |                | bool        | NO          |                |                    |                     |         | b       |    16 |
+ c5          | bytea                       | NO          |                                 |                          |                   |                    |               |                    |                | bytea       | NO          |                |                    |                     |         | b       |    17 |
+ c6          | character                   | NO          |                                 |                      100 |                   |                    |               |                    |                | bpchar      | NO          |                |                    |                     |         | b       |  1042 |
+ c7          | character varying           | NO          |                                 |                          |                   |                    |               |                    |                | varchar     | NO          |                |                    |                     |         | b       |  1043 |
+ c8          | cidr                        | NO          |                                 |                          |                   |                    |               |                    |                | cidr        | NO          |                |                    |                     |         | b       |   650 |
+ c9          | circle                      | NO          |                                 |                          |                   |                    |               |                    |                | circle      | NO          |                |                    |                     |         | b       |   718 |
+ c10         | date                        | NO          |                                 |                          |                   |                    |               |                    |                | date        | NO          |                |                    |                     |         | b       |  1082 |
+ c11         | time with time zone         | NO          |                                 |                          |                   |                    |               |                    |                | timetz      | NO          |                |                    |                     |         | b       |  1266 |
+ c12         | double precision            | NO          |                                 |                          |                53 |                    |               |                    |                | float8      | NO          |                |                    |                     |         | b       |   701 |
+ c13         | real                        | NO          |           random()              |                          |                24 |                    |               |                    |                | float4      | NO          |                |                    |                     |         | b       |   700 |
+ c14         | json                        | NO          |           '{}'::json            |                          |                   |                    |               |                    |                | json        | NO          |                |                    |                     |         | b       |   114 |
+ c15         | jsonb                       | NO          |           '{}'::jsonb           |                          |                   |                    |               |                    |                | jsonb       | NO          |                |                    |                     |         | b       |  3802 |
+ c16         | money                       | NO          |                                 |                          |                   |                    |               |                    |                | money       | NO          |                |                    |                     |         | b       |   790 |
+ c17         | numeric                     | NO          |                                 |                          |                   |                    |               |                    |                | numeric     | NO          |                |                    |                     |         | b       |  1700 |
+ c18         | numeric                     | NO          |                                 |                          |                 4 |                    |             4 |                    |                | numeric     | NO          |                |                    |                     |         | b       |  1700 |
+ c19         | integer                     | NO          | nextval('t1_c19_seq'::regclass) |                          |                32 |                    |             0 |                    |                | int4        | NO          |                |                    |                     |         | b       |    23 |
+ c20         | uuid                        | NO          |                                 |                          |                   |                    |               |                    |                | uuid        | NO          |                |                    |                     |         | b       |  2950 |
+ c21         | xml                         | NO          |                                 |                          |                   |                    |               |                    |                | xml         | NO          |                |                    |                     |         | b       |   142 |
+ c22         | ARRAY                       | YES         |                                 |                          |                   |                    |               |                    |                | _int4       | NO          |                |                    |                     |         | b       |  1007 |          1
+ c23         | USER-DEFINED                | YES         |                                 |                          |                   |                    |               |                    |                | ltree       | NO          |                |                    |                     |         | b       | 16535 |
+ c24         | USER-DEFINED                | NO          |                                 |                          |                   |                    |               |                    |                | state       | NO          |                |                    |                     |         | e       | 16774 |
+ c25         | timestamp without time zone | NO          |            now()                |                          |                   |                  4 |               |                    |                | timestamp   | NO          |                |                    |                     |         | b       |  1114 |
+ c26         | timestamp with time zone    | NO          |                                 |                          |                   |                  6 |               |                    |                | timestamptz | NO          |                |                    |                     |         | b       |  1184 |
+ c27         | time without time zone      | NO          |                                 |                          |                   |                  6 |               |                    |                | time        | NO          |                |                    |                     |         | b       |  1266 |
+ c28         | ARRAY                       | YES         |                                 |                          |                   |                    |               |                    |                | _text       | NO          |                |                    |                     |         | b       |  1009 |          2
+ c29         | tstzrange                   | NO          |                                 |                          |                   |                    |               |                    |                | tstzrange   | NO          |                |                    |                     |         | r       |  3910 |
+ c30         | USER-DEFINED                | NO          |                                 |                          |                   |                    |               |                    |                | floatrange  | NO          |                |                    |                     |         | r       | 16800 |
 `))
 				m.ExpectQuery(sqltest.Escape(`SELECT enumtypid, enumlabel FROM pg_enum WHERE enumtypid IN ($1)`)).
 					WithArgs(16774).
@@ -107,32 +111,43 @@ func TestDriver_InspectTable(t *testing.T) {
 					{Name: "c25", Type: &schema.ColumnType{Raw: "timestamp without time zone", Type: &schema.TimeType{T: "timestamp without time zone", Precision: 4}}, Default: &schema.RawExpr{X: "now()"}},
 					{Name: "c26", Type: &schema.ColumnType{Raw: "timestamp with time zone", Type: &schema.TimeType{T: "timestamp with time zone", Precision: 6}}},
 					{Name: "c27", Type: &schema.ColumnType{Raw: "time without time zone", Type: &schema.TimeType{T: "time without time zone", Precision: 6}}},
+					{Name: "c28", Type: &schema.ColumnType{Raw: "ARRAY", Null: true, Type: &ArrayType{T: "text[][]"}}},
+					{Name: "c29", Type: &schema.ColumnType{Raw: "tstzrange", Type: &RangeType{T: "tstzrange"}}},
+					{Name: "c30", Type: &schema.ColumnType{Raw: "USER-DEFINED", Type: &RangeType{T: "floatrange"}}},
 				}, t.Columns)
 			},
 		},
 		{
 			name: "table indexes",
 			before: func(m mock) {
-				m.tableExists("public", "users", true)
+				m.ExpectQuery(sqltest.Escape(tableSchemaQuery)).
+					WithArgs("users", "public").
+					WillReturnRows(sqltest.Rows(`
+ table_schema | table_comment | tablespace | inherits                 | options
+--------------+---------------+------------+--------------------------+-----------------------------------------------------------------
+ public       |               | fast       | [["public", "entities"]] | {fillfactor=70,autovacuum_enabled=off,user_catalog_table=false}
+`))
 				m.ExpectQuery(sqltest.Escape(columnsQuery)).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
- column_name |      data_type      | is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | character_set_name | collation_name | udt_name | is_identity | identity_start | identity_increment | identity_generation | comment | typtype |  oid
--------------+---------------------+-------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+--------------------+----------------+----------+-------------+----------------+--------------------+---------------------+---------+---------+-------
- id          | bigint              | NO          |                                 |                          |                64 |                    |             0 |                    |                | int8     | NO          |                |                    |                     |         | b       |    20
- c1          | smallint            | NO          |                                 |                          |                16 |                    |             0 |                    |                | int2     | NO          |                |                    |                     |         | b       |    21
+ column_name |      data_type      | is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | character_set_name | collation_name | udt_name | is_identity | identity_start | identity_increment | identity_generation | comment | typtype |  oid | array_dims
+-------------+---------------------+-------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+--------------------+----------------+----------+-------------+----------------+--------------------+---------------------+---------+---------+-------+------------
+ id          | bigint              | NO          |                                 |                          |                64 |                    |             0 |                    |                | int8     | NO          |                |                    |                     |         | b       |    20 |
+ c1          | smallint            | NO          |                                 |                          |                16 |                    |             0 |                    |                | int2     | NO          |                |                    |                     |         | b       |    21 |
 `))
 				m.ExpectQuery(sqltest.Escape(indexesQuery)).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-    index_name   | index_type  | column_name | primary | unique | constraint_type | predicate             |   expression              | desc | nulls_first | nulls_last | comment
------------------+-------------+-------------+---------+--------+-----------------+-----------------------+---------------------------+------+-------------+------------+-----------
- idx             | hash        | left        | f       | f      |                 |                       | "left"((c11)::text, 100)  | t    | t           | f          | boring
- idx1            | btree       | left        | f       | f      |                 | (id <> NULL::integer) | "left"((c11)::text, 100)  | t    | t           | f          |
- t1_c1_key       | btree       | c1          | f       | t      | u               |                       |                           | t    | t           | f          |
- t1_pkey         | btree       | id          | t       | t      | p               |                       |                           | t    | f           | f          |
- idx4            | btree       | c1          | f       | t      |                 |                       |                           | f    | f           | f          |
- idx4            | btree       | id          | f       | t      |                 |                       |                           | f    | f           | t          |
+    index_name   | index_type  | column_name | primary | unique | constraint_type | predicate             |   expression              | desc | nulls_first | nulls_last | comment   | tablespace | options
+-----------------+-------------+-------------+---------+--------+-----------------+-----------------------+---------------------------+------+-------------+------------+-----------+------------+----------------------------------------
+ idx             | hash        | left        | f       | f      |                 |                       | "left"((c11)::text, 100)  | t    | t           | f          | boring    |            | {fillfactor=70}
+ idx1            | btree       | left        | f       | f      |                 | (id <> NULL::integer) | "left"((c11)::text, 100)  | t    | t           | f          |           |            |
+ t1_c1_key       | btree       | c1          | f       | t      | u               |                       |                           | t    | t           | f          |           |            |
+ t1_pkey         | btree       | id          | t       | t      | p               |                       |                           | t    | f           | f          |           |            |
+ idx4            | btree       | c1          | f       | t      |                 |                       |                           | f    | f           | f          |           | fast       |
+ idx4            | btree       | id          | f       | t      |                 |                       |                           | f    | f           | t          |           | fast       |
+ idx5            | brin        | c1          | f       | f      |                 |                       |                           | f    | f           | f          |           |            | {pages_per_range=32,autosummarize=on}
+ idx6            | gin         | c1          | f       | f      |                 |                       |                           | f    | f           | f          |           |            | {fastupdate=off}
 
 `))
 				m.noFKs()
@@ -141,15 +156,19 @@ func TestDriver_InspectTable(t *testing.T) {
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
 				require.Equal("users", t.Name)
+				ff, off := int64(70), false
+				require.Equal([]schema.Attr{&Tablespace{N: "fast"}, &Inherits{T: []*schema.Table{{Name: "entities", Schema: &schema.Schema{Name: "public"}}}}, &TableStorageParams{FillFactor: &ff, AutovacuumEnabled: &off}}, t.Attrs)
 				columns := []*schema.Column{
 					{Name: "id", Type: &schema.ColumnType{Raw: "bigint", Type: &schema.IntegerType{T: "bigint"}}},
 					{Name: "c1", Type: &schema.ColumnType{Raw: "smallint", Type: &schema.IntegerType{T: "smallint"}}},
 				}
 				indexes := []*schema.Index{
-					{Name: "idx", Table: t, Attrs: []schema.Attr{&IndexType{T: "hash"}, &schema.Comment{Text: "boring"}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `"left"((c11)::text, 100)`}, Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
+					{Name: "idx", Table: t, Attrs: []schema.Attr{&IndexType{T: "hash"}, &schema.Comment{Text: "boring"}, &IndexStorageParams{FillFactor: 70}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `"left"((c11)::text, 100)`}, Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
 					{Name: "idx1", Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexPredicate{P: `(id <> NULL::integer)`}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `"left"((c11)::text, 100)`}, Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
 					{Name: "t1_c1_key", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &ConType{T: "u"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1], Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
-					{Name: "idx4", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}, {SeqNo: 2, C: columns[0], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}}},
+					{Name: "idx4", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &Tablespace{N: "fast"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}, {SeqNo: 2, C: columns[0], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}}},
+					{Name: "idx5", Table: t, Attrs: []schema.Attr{&IndexType{T: "brin"}, &IndexStorageParams{PagesPerRange: 32, AutoSummarize: true}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}}},
+					{Name: "idx6", Table: t, Attrs: []schema.Attr{&IndexType{T: "gin"}, &IndexStorageParams{FastUpdate: new(bool)}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}}},
 				}
 				pk := &schema.Index{
 					Name:   "t1_pkey",
@@ -172,23 +191,23 @@ func TestDriver_InspectTable(t *testing.T) {
 				m.ExpectQuery(sqltest.Escape(columnsQuery)).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
- column_name |      data_type      | is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | character_set_name | collation_name | udt_name | is_identity | identity_start | identity_increment | identity_generation | comment | typtype |  oid
--------------+---------------------+-------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+--------------------+----------------+----------+-------------+----------------+--------------------+---------------------+---------+---------+-------
- id          | integer             | NO          |                                 |                          |                32 |                    |             0 |                    |                | int      | NO          |                |                    |                     |         | b       |    20
- oid         | integer             | NO          |                                 |                          |                32 |                    |             0 |                    |                | int      | NO          |                |                    |                     |         | b       |    21
- uid         | integer             | NO          |                                 |                          |                32 |                    |             0 |                    |                | int      | NO          |                |                    |                     |         | b       |    21
+ column_name |      data_type      | is_nullable |         column_default          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | character_set_name | collation_name | udt_name | is_identity | identity_start | identity_increment | identity_generation | comment | typtype |  oid | array_dims
+-------------+---------------------+-------------+---------------------------------+--------------------------+-------------------+--------------------+---------------+--------------------+----------------+----------+-------------+----------------+--------------------+---------------------+---------+---------+-------+------------
+ id          | integer             | NO          |                                 |                          |                32 |                    |             0 |                    |                | int      | NO          |                |                    |                     |         | b       |    20 |
+ oid         | integer             | NO          |                                 |                          |                32 |                    |             0 |                    |                | int      | NO          |                |                    |                     |         | b       |    21 |
+ uid         | integer             | NO          |                                 |                          |                32 |                    |             0 |                    |                | int      | NO          |                |                    |                     |         | b       |    21 |
 `))
 				m.noIndexes()
 				m.ExpectQuery(sqltest.Escape(fksQuery)).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
- constraint_name | table_name | column_name | table_schema | referenced_table_name | referenced_column_name | referenced_schema_name | update_rule | delete_rule
------------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------
- multi_column    | users      | id          | public       | t1                    | gid                    | public                 | NO ACTION   | CASCADE
- multi_column    | users      | id          | public       | t1                    | xid                    | public                 | NO ACTION   | CASCADE
- multi_column    | users      | oid         | public       | t1                    | gid                    | public                 | NO ACTION   | CASCADE
- multi_column    | users      | oid         | public       | t1                    | xid                    | public                 | NO ACTION   | CASCADE
- self_reference  | users      | uid         | public       | users                 | id                     | public                 | NO ACTION   | CASCADE
+ constraint_name | table_name | column_name | table_schema | referenced_table_name | referenced_column_name | referenced_schema_name | update_rule | delete_rule | match_option
+-----------------+------------+-------------+--------------+-----------------------+------------------------+------------------------+-------------+-------------+--------------
+ multi_column    | users      | id          | public       | t1                    | gid                    | public                 | NO ACTION   | SET DEFAULT | FULL
+ multi_column    | users      | id          | public       | t1                    | xid                    | public                 | NO ACTION   | SET DEFAULT | FULL
+ multi_column    | users      | oid         | public       | t1                    | gid                    | public                 | NO ACTION   | SET DEFAULT | FULL
+ multi_column    | users      | oid         | public       | t1                    | xid                    | public                 | NO ACTION   | SET DEFAULT | FULL
+ self_reference  | users      | uid         | public       | users                 | id                     | public                 | NO ACTION   | CASCADE     | NONE
 
 `))
 				m.noChecks()
@@ -198,7 +217,7 @@ func TestDriver_InspectTable(t *testing.T) {
 				require.Equal("users", t.Name)
 				require.Equal("public", t.Schema.Name)
 				fks := []*schema.ForeignKey{
-					{Symbol: "multi_column", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}}, RefColumns: []*schema.Column{{Name: "gid"}, {Name: "xid"}}},
+					{Symbol: "multi_column", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.SetDefault, Match: schema.MatchFull, RefTable: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}}, RefColumns: []*schema.Column{{Name: "gid"}, {Name: "xid"}}},
 					{Symbol: "self_reference", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: t},
 				}
 				columns := []*schema.Column{
@@ -221,27 +240,27 @@ func TestDriver_InspectTable(t *testing.T) {
 					WithArgs("public", "users").
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
- column_name | data_type | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | character_set_name | collation_name | udt_name | is_identity | identity_start | identity_increment | identity_generation | comment | typtype | oid
--------------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+--------------------+----------------+----------+-------------+----------------+--------------------+---------------------+---------+---------+-----
- c1          | integer   | NO          |                |                          |                32 |                    |             0 |                    |                | int4     | NO          |                |                    |                     |         | b       |  23
- c2          | integer   | NO          |                |                          |                32 |                    |             0 |                    |                | int4     | NO          |                |                    |                     |         | b       |  23
- c3          | integer   | NO          |                |                          |                32 |                    |             0 |                    |                | int4     | NO          |                |                    |                     |         | b       |  23
+ column_name | data_type | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | character_set_name | collation_name | udt_name | is_identity | identity_start | identity_increment | identity_generation | comment | typtype | oid | array_dims
+-------------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+--------------------+----------------+----------+-------------+----------------+--------------------+---------------------+---------+---------+-----+------------
+ c1          | integer   | NO          |                |                          |                32 |                    |             0 |                    |                | int4     | NO          |                |                    |                     |         | b       |  23 |
+ c2          | integer   | NO          |                |                          |                32 |                    |             0 |                    |                | int4     | NO          |                |                    |                     |         | b       |  23 |
+ c3          | integer   | NO          |                |                          |                32 |                    |             0 |                    |                | int4     | NO          |                |                    |                     |         | b       |  23 |
 `))
 				m.noIndexes()
 				m.noFKs()
 				m.ExpectQuery(sqltest.Escape(checksQuery)).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
- constraint_name    |       expression        | column_name | column_indexes | no_inherit
---------------------+-------------------------+-------------+----------------+----------------
- boring             | (c1 > 1)                | c1          | {1}            | t
- users_c2_check     | (c2 > 0)                | c2          | {2}            | f
- users_c2_check1    | (c2 > 0)                | c2          | {2}            | f
- users_check        | ((c2 + c1) > 2)         | c2          | {2,1}          | f
- users_check        | ((c2 + c1) > 2)         | c1          | {2,1}          | f
- users_check1       | (((c2 + c1) + c3) > 10) | c2          | {2,1,3}        | f
- users_check1       | (((c2 + c1) + c3) > 10) | c1          | {2,1,3}        | f
- users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1,3}        | f
+ constraint_name    |       expression        | column_name | column_indexes | no_inherit | validated
+--------------------+-------------------------+-------------+----------------+------------+-----------
+ boring             | (c1 > 1)                | c1          | {1}            | t          | t
+ users_c2_check     | (c2 > 0)                | c2          | {2}            | f          | t
+ users_c2_check1    | (c2 > 0)                | c2          | {2}            | f          | f
+ users_check        | ((c2 + c1) > 2)         | c2          | {2,1}          | f          | t
+ users_check        | ((c2 + c1) > 2)         | c1          | {2,1}          | f          | t
+ users_check1       | (((c2 + c1) + c3) > 10) | c2          | {2,1,3}        | f          | t
+ users_check1       | (((c2 + c1) + c3) > 10) | c1          | {2,1,3}        | f          | t
+ users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1,3}        | f          | t
 `))
 				m.noChecks()
 			},
@@ -257,7 +276,7 @@ func TestDriver_InspectTable(t *testing.T) {
 				require.EqualValues([]schema.Attr{
 					&schema.Check{Name: "boring", Expr: "(c1 > 1)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c1"}}, &NoInherit{}}},
 					&schema.Check{Name: "users_c2_check", Expr: "(c2 > 0)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2"}}}},
-					&schema.Check{Name: "users_c2_check1", Expr: "(c2 > 0)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2"}}}},
+					&schema.Check{Name: "users_c2_check1", Expr: "(c2 > 0)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2"}}, &NotValid{}}},
 					&schema.Check{Name: "users_check", Expr: "((c2 + c1) > 2)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2", "c1"}}}},
 					&schema.Check{Name: "users_check1", Expr: "(((c2 + c1) + c3) > 10)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2", "c1", "c3"}}}},
 				}, t.Attrs)
@@ -275,9 +294,9 @@ func TestDriver_InspectTable(t *testing.T) {
 			mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
 				WithArgs("public").
 				WillReturnRows(sqltest.Rows(`
-    schema_name
---------------------
- public
+ schema_name | comment
+-------------+---------
+ public      |
 `))
 			mk.tables("public", "users")
 			tt.before(mk)
@@ -288,6 +307,57 @@ func TestDriver_InspectTable(t *testing.T) {
 	}
 }
 
+func TestDriver_InspectTypeParser(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db, WithTypeParser(func(raw string) (schema.Type, bool) {
+		if raw != "citext" {
+			return nil, false
+		}
+		return &schema.StringType{T: TypeText}, true
+	}))
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      |
+`))
+	mk.tables("public", "users")
+	mk.tableExists("public", "users", true)
+	mk.ExpectQuery(sqltest.Escape(columnsQuery)).
+		WithArgs("public", "users").
+		WillReturnRows(sqltest.Rows(`
+ column_name |  data_type   | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | character_set_name | collation_name | udt_name | is_identity | identity_start | identity_increment | identity_generation | comment | typtype |  oid  | array_dims
+-------------+--------------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+--------------------+----------------+----------+-------------+----------------+--------------------+---------------------+---------+---------+-------+------------
+ c1          | USER-DEFINED | NO          |                |                          |                   |                    |               |                    |                | citext   | NO          |                |                    |                     |         | b       | 16600 |
+ c2          | USER-DEFINED | NO          |                |                          |                   |                    |               |                    |                | ltree    | NO          |                |                    |                     |         | b       | 16535 |
+ c3          | interval     | NO          |                |                          |                   |                    |               |                    |                | interval | NO          |                |                    |                     |         | b       |  1186 |
+`))
+	mk.noIndexes()
+	mk.noFKs()
+	mk.noChecks()
+	s, err := drv.InspectSchema(context.Background(), "public", nil)
+	require.NoError(t, err)
+	require.EqualValues(t, []*schema.Column{
+		{Name: "c1", Type: &schema.ColumnType{Raw: "USER-DEFINED", Type: &schema.StringType{T: TypeText}}},
+		{Name: "c2", Type: &schema.ColumnType{Raw: "USER-DEFINED", Type: &UserDefinedType{T: "ltree"}}},
+		{Name: "c3", Type: &schema.ColumnType{Raw: "interval", Type: &schema.UnsupportedType{T: "interval"}}},
+	}, s.Tables[0].Columns)
+
+	// Unknown types are compared and planned by their raw form.
+	to := schema.NewTable("users").AddColumns(schema.NewColumn("c3").SetType(&schema.UnsupportedType{T: "INTERVAL"}))
+	changes, err := drv.TableDiff(&schema.Table{Name: "users", Columns: s.Tables[0].Columns[2:]}, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	f, err := FormatType(&schema.UnsupportedType{T: "interval"})
+	require.NoError(t, err)
+	require.Equal(t, "interval", f)
+}
+
 func TestDriver_InspectSchema(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -297,9 +367,16 @@ func TestDriver_InspectSchema(t *testing.T) {
 	require.NoError(t, err)
 	mk.ExpectQuery(sqltest.Escape("SELECT CURRENT_SCHEMA()")).
 		WillReturnRows(sqltest.Rows(`
-    schema_name
---------------------
+ schema_name
+-------------
  test
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("test").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ test        | the test schema
 `))
 	mk.tables("test")
 	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{})
@@ -308,7 +385,8 @@ func TestDriver_InspectSchema(t *testing.T) {
 		r := &schema.Realm{
 			Schemas: []*schema.Schema{
 				{
-					Name: "test",
+					Name:  "test",
+					Attrs: []schema.Attr{&schema.Comment{Text: "the test schema"}},
 				},
 			},
 			// Server default configuration.
@@ -326,6 +404,153 @@ func TestDriver_InspectSchema(t *testing.T) {
 	}(), s)
 }
 
+func TestDriver_InspectStats(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s := schema.New("public").AddTables(schema.NewTable("users"), schema.NewTable("pets"))
+	mk.ExpectQuery(sqltest.Escape(statsQuery)).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ relname | reltuples | pg_table_size | pg_indexes_size
+---------+-----------+---------------+-----------------
+ logs    | 10        | 8192          | 0
+ pets    | -1        | 8192          | 16384
+ users   | 1000      | 65536         | 32768
+`))
+	err = drv.Inspector.(*inspect).stats(context.Background(), s)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{&schema.TableStats{Rows: 1000, DataSize: 65536, IndexSize: 32768}}, s.Tables[0].Attrs)
+	require.Equal(t, []schema.Attr{&schema.TableStats{Rows: -1, DataSize: 8192, IndexSize: 16384}}, s.Tables[1].Attrs)
+}
+
+func TestDriver_InspectTables(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ public      |
+`))
+	mk.tables("public", "pets", "users")
+	mk.ExpectQuery(sqltest.Escape(statsQuery)).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ relname | reltuples | pg_table_size | pg_indexes_size
+---------+-----------+---------------+-----------------
+ pets    | -1        | 8192          | 16384
+ users   | 1000      | 65536         | 32768
+`))
+	for _, name := range []string{"pets", "users"} {
+		mk.tableExists("public", name, true)
+		mk.ExpectQuery(sqltest.Escape(columnsQuery)).
+			WithArgs("public", name).
+			WillReturnRows(sqlmock.NewRows([]string{"column_name"}))
+		mk.noIndexes()
+		mk.noFKs()
+		mk.noChecks()
+	}
+	var (
+		names []string
+		stats []schema.TableStats
+	)
+	err = drv.InspectTables(context.Background(), &schema.InspectRealmOption{Schemas: []string{"public"}, Stats: true}, func(tt *schema.Table) error {
+		require.Equal(t, "public", tt.Schema.Name)
+		require.Empty(t, tt.Schema.Tables, "streamed tables are not held by their schema")
+		require.NotNil(t, tt.Schema.Realm)
+		var s schema.TableStats
+		require.True(t, schema.FindAttr(tt.Attrs, &s))
+		names, stats = append(names, tt.Name), append(stats, s)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"pets", "users"}, names)
+	require.Equal(t, []schema.TableStats{{Rows: -1, DataSize: 8192, IndexSize: 16384}, {Rows: 1000, DataSize: 65536, IndexSize: 32768}}, stats)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestLinkInherits(t *testing.T) {
+	var (
+		parent = schema.NewTable("entities")
+		child  = schema.NewTable("users").AddAttrs(&Inherits{T: []*schema.Table{
+			{Name: "entities", Schema: &schema.Schema{Name: "public"}},
+			{Name: "external", Schema: &schema.Schema{Name: "other"}},
+		}})
+		s = schema.New("public").AddTables(parent, child)
+	)
+	linkInherits([]*schema.Schema{s})
+	a := inherits(child.Attrs)
+	require.True(t, a.T[0] == parent, "parent should be linked to the inspected table")
+	require.Equal(t, "other.external", qualified(a.T[1]))
+}
+
+func TestDriver_Options(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("100000")
+	var stmts []string
+	drv, err := Open(db,
+		WithVersion("130004"),
+		WithCollation("C"),
+		WithSearchPath("app", "public"),
+		WithCatalogSchema("catalog"),
+		WithStatementLog(func(_ context.Context, stmt string, _ []interface{}) {
+			stmts = append(stmts, stmt)
+		}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "13.00.04", drv.version)
+	require.Equal(t, "C", drv.collate)
+	require.Equal(t, []string{paramsQuery}, stmts)
+
+	mk.ExpectQuery(sqltest.Escape(`SELECT schema_name, obj_description(to_regnamespace(quote_ident(schema_name))::oid, 'pg_namespace') AS comment FROM "catalog".schemata WHERE schema_name = $1 ORDER BY schema_name`)).
+		WithArgs("app").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | comment
+-------------+---------
+ app         |
+`))
+	mk.ExpectQuery(sqltest.Escape(`SELECT table_name FROM "catalog".tables WHERE table_type = 'BASE TABLE' AND table_schema = $1 ORDER BY table_name`)).
+		WithArgs("app").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}))
+	s, err := drv.InspectSchema(context.Background(), "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "app", s.Name)
+	require.Equal(t, []schema.Attr{&schema.Collation{V: "C"}, &CType{V: "en_US.utf8"}}, s.Realm.Attrs)
+	require.Len(t, stmts, 3)
+}
+
+func TestDriver_ReadOnly(t *testing.T) {
+	for _, q := range []string{
+		paramsQuery, schemasQuery, tablesQuery, tableQuery, tableSchemaQuery, columnsQuery, indexesQuery,
+		fksQuery, checksQuery, rowsQuery, rowsQuerySchema, publicationsQuery, subscriptionsQuery,
+		fmt.Sprintf(schemasQueryArgs, "IN ($1, $2)"), fmt.Sprintf(tablesQueryArgs, "IN ($2)"),
+		fmt.Sprintf(publicationsInspectQuery, publicationsFilter),
+	} {
+		require.True(t, sqlx.ReadOnlyStmt(q), q)
+	}
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db, WithReadOnly(true))
+	require.NoError(t, err)
+	err = drv.ApplyChanges(context.Background(), []schema.Change{&schema.AddTable{T: schema.NewTable("users")}})
+	require.True(t, schema.IsReadOnlyError(err), err)
+	_, err = drv.QueryContext(context.Background(), "WITH d AS (DELETE FROM users RETURNING *) SELECT * FROM d")
+	require.True(t, schema.IsReadOnlyError(err), err)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
 func TestDriver_Realm(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -335,10 +560,10 @@ func TestDriver_Realm(t *testing.T) {
 	require.NoError(t, err)
 	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
 		WillReturnRows(sqltest.Rows(`
-    schema_name
---------------------
- test
- public
+ schema_name | comment
+-------------+---------
+ test        |
+ public      |
 `))
 	mk.tables("test")
 	mk.tables("public")
@@ -372,10 +597,10 @@ func TestDriver_Realm(t *testing.T) {
 	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "IN ($1, $2)"))).
 		WithArgs("test", "public").
 		WillReturnRows(sqltest.Rows(`
-    schema_name
---------------------
- test
- public
+ schema_name | comment
+-------------+---------
+ test        |
+ public      |
 `))
 	mk.tables("test")
 	mk.tables("public")
@@ -409,9 +634,9 @@ func TestDriver_Realm(t *testing.T) {
 	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
 		WithArgs("test").
 		WillReturnRows(sqltest.Rows(`
-    schema_name
---------------------
- test
+ schema_name | comment
+-------------+---------
+ test        |
 `))
 	mk.tables("test")
 	realm, err = drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Schemas: []string{"test"}})
@@ -454,9 +679,9 @@ func (m mock) version(version string) {
 }
 
 func (m mock) tableExists(schema, table string, exists bool) {
-	rows := sqlmock.NewRows([]string{"table_schema", "table_comment"})
+	rows := sqlmock.NewRows([]string{"table_schema", "table_comment", "tablespace", "inherits", "options"})
 	if exists {
-		rows.AddRow(schema, nil)
+		rows.AddRow(schema, nil, nil, nil, nil)
 	}
 	m.ExpectQuery(sqltest.Escape(tableSchemaQuery)).
 		WithArgs(table, schema).
@@ -475,7 +700,7 @@ func (m mock) noFKs() {
 
 func (m mock) noChecks() {
 	m.ExpectQuery(sqltest.Escape(checksQuery)).
-		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "expression", "column_name", "column_indexes"}))
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "expression", "column_name", "column_indexes", "no_inherit", "validated"}))
 }
 
 func (m mock) tables(schema string, names ...string) {
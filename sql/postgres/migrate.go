@@ -6,13 +6,19 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/naming"
 	"ariga.io/atlas/sql/schema"
+
+	"github.com/lib/pq"
 )
 
 // A planApply provides migration capabilities for schema elements.
@@ -20,6 +26,9 @@ type planApply struct{ conn }
 
 // PlanChanges returns a migration plan for the given schema changes.
 func (p *planApply) PlanChanges(ctx context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+	if p.namer != nil {
+		naming.Changes(p.namer, changes)
+	}
 	s := &state{
 		conn: p.conn,
 		Plan: migrate.Plan{
@@ -46,23 +55,110 @@ func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change) e
 	return sqlx.ApplyChanges(ctx, changes, p)
 }
 
+// SingleConn reports if the driver was opened on a single database connection.
+// It is used by sqlx.ApplyChanges to check if savepoints can be used.
+func (p *planApply) SingleConn() bool {
+	return p.single
+}
+
+// ConvertError implements the sqlx.ErrorConverter interface, and converts
+// PostgreSQL errors returned by executing the given statement to typed errors.
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+func (p *planApply) ConvertError(stmt string, err error) error {
+	var pe *pq.Error
+	if !errors.As(err, &pe) {
+		return err
+	}
+	e := &schema.StmtError{Stmt: stmt, Err: err}
+	if pos, err := strconv.Atoi(pe.Position); err == nil {
+		e.Pos = pos
+	}
+	switch {
+	// lock_not_available and deadlock_detected.
+	case pe.Code == "55P03" || pe.Code == "40P01":
+		e.Err = &schema.LockError{Err: err}
+	// Class 23 - Integrity Constraint Violation.
+	case pe.Code.Class() == "23":
+		e.Err = &schema.ConstraintViolationError{Constraint: pe.Constraint, Err: err}
+	}
+	return e
+}
+
+// FormatStmt formats the given statement for readability. Keywords are uppercased,
+// the definitions of CREATE TABLE statements are written one per line, and ALTER TABLE
+// statements with multiple clauses are written one clause per line. For example:
+//
+//	FormatStmt(`ALTER TABLE "t" ADD COLUMN "c" int, DROP COLUMN "d"`)
+//
+func FormatStmt(stmt string) string {
+	return formatter.Format(stmt)
+}
+
+// SplitStmts splits the given PostgreSQL script into statements.
+func SplitStmts(script string) ([]*migrate.Stmt, error) {
+	stmts, err := splitter.Split(script)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	return stmts, nil
+}
+
+// SplitStmts implements the migrate.StmtSplitter interface.
+func (*Driver) SplitStmts(script string) ([]*migrate.Stmt, error) {
+	return SplitStmts(script)
+}
+
+var (
+	lexer     = sqlx.Lexer{Dollar: true, NestedComments: true, EscapeStrings: true}
+	formatter = &sqlx.Formatter{Lexer: lexer}
+	splitter  = &sqlx.Splitter{Lexer: lexer}
+)
+
 // state represents the state of a planning. It is not part of
 // planApply so that multiple planning/applying can be called
 // in parallel.
 type state struct {
 	conn
 	migrate.Plan
+	// guards indicates if the planned statements are guarded
+	// with existence checks. See migrate.WithGuards.
+	guards bool
+	// deferred holds the changes that are planned after all
+	// others. For example, dropping collation objects.
+	deferred []*migrate.Change
 }
 
 // Exec executes the changes on the database. An error is returned
 // if one of the operations fail, or a change is not supported.
 func (s *state) plan(ctx context.Context, changes []schema.Change) error {
-	planned := s.topLevel(changes)
-	planned, err := sqlx.DetachCycles(planned)
+	if err := checkUnsigned(changes); err != nil {
+		return err
+	}
+	if err := s.checkExtensions(changes); err != nil {
+		return err
+	}
+	s.guards = migrate.GuardsFromContext(ctx)
+	pre, changes, post := splitPublications(changes)
+	for _, c := range pre {
+		if err := s.publication(c); err != nil {
+			return err
+		}
+	}
+	planned, err := s.topLevel(skipInherited(changes))
 	if err != nil {
 		return err
 	}
+	if s.coalesce {
+		planned = sqlx.CoalesceChanges(planned)
+	}
+	if planned, err = sqlx.DetachCycles(planned); err != nil {
+		return err
+	}
+	planned = sqlx.DetachAlteredReferences(planned)
+	planned = inheritOrder(planned)
+	estimate := migrate.ImpactFromContext(ctx)
 	for _, c := range planned {
+		n := len(s.Changes)
 		switch c := c.(type) {
 		case *schema.AddTable:
 			err = s.addTable(ctx, c)
@@ -73,59 +169,242 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) error {
 		default:
 			err = fmt.Errorf("unsupported change %T", c)
 		}
+		if err == nil && estimate {
+			err = s.estimate(ctx, c, s.Changes[n:])
+		}
 		if err != nil {
 			return err
 		}
 	}
+	s.append(s.deferred...)
+	for _, c := range post {
+		if err := s.publication(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkUnsigned returns an error if one of the changes adds or modifies a column
+// with an unsigned integer type, as it is not supported by PostgreSQL.
+func checkUnsigned(changes []schema.Change) error {
+	var columns []*schema.Column
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			columns = append(columns, c.T.Columns...)
+		case *schema.ModifyTable:
+			for _, c := range c.Changes {
+				switch c := c.(type) {
+				case *schema.AddColumn:
+					columns = append(columns, c.C)
+				case *schema.ModifyColumn:
+					columns = append(columns, c.To)
+				}
+			}
+		}
+	}
+	for _, c := range columns {
+		if t, ok := c.Type.Type.(*schema.IntegerType); ok && t.Unsigned {
+			_, err := FormatType(t)
+			return fmt.Errorf("column %q: %w", c.Name, err)
+		}
+	}
 	return nil
 }
 
+// skipInherited skips column changes of child tables that are applied by PostgreSQL
+// on the children when the same change is applied on their parent table. For example,
+// a column that is added to a parent table is also added to all of its children.
+func skipInherited(changes []schema.Change) []schema.Change {
+	var (
+		added   = make(map[string]bool)
+		dropped = make(map[string]bool)
+	)
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok {
+			continue
+		}
+		for _, c := range m.Changes {
+			switch c := c.(type) {
+			case *schema.AddColumn:
+				added[qualified(m.T)+"."+c.C.Name] = true
+			case *schema.DropColumn:
+				dropped[qualified(m.T)+"."+c.C.Name] = true
+			}
+		}
+	}
+	if len(added) == 0 && len(dropped) == 0 {
+		return changes
+	}
+	// inherited reports if the column change is applied by one of the parents.
+	inherited := func(changed map[string]bool, t *schema.Table, c *schema.Column) bool {
+		for _, p := range inherits(t.Attrs).T {
+			if changed[parentName(t, p)+"."+c.Name] {
+				return true
+			}
+		}
+		return false
+	}
+	planned := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok || !sqlx.Has(m.T.Attrs, &Inherits{}) {
+			planned = append(planned, c)
+			continue
+		}
+		filtered := make([]schema.Change, 0, len(m.Changes))
+		for _, c := range m.Changes {
+			switch c := c.(type) {
+			case *schema.AddColumn:
+				if inherited(added, m.T, c.C) {
+					continue
+				}
+			case *schema.DropColumn:
+				if inherited(dropped, m.T, c.C) {
+					continue
+				}
+			}
+			filtered = append(filtered, c)
+		}
+		if len(filtered) > 0 {
+			planned = append(planned, &schema.ModifyTable{T: m.T, Changes: filtered})
+		}
+	}
+	return planned
+}
+
+// inheritOrder orders the creation of child tables after the creation
+// of their parents, and their deletion before their parents are dropped.
+func inheritOrder(changes []schema.Change) []schema.Change {
+	var (
+		adds    = make(map[string]int)
+		drops   = make(map[string]int)
+		visited = make(map[int]bool)
+		planned = make([]schema.Change, 0, len(changes))
+	)
+	for i, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			adds[qualified(c.T)] = i
+		case *schema.DropTable:
+			drops[qualified(c.T)] = i
+		}
+	}
+	var visit func(int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		switch c := changes[i].(type) {
+		case *schema.AddTable:
+			for _, p := range inherits(c.T.Attrs).T {
+				if j, ok := adds[parentName(c.T, p)]; ok {
+					visit(j)
+				}
+			}
+		case *schema.DropTable:
+			for j, c2 := range changes {
+				d, ok := c2.(*schema.DropTable)
+				if !ok {
+					continue
+				}
+				for _, p := range inherits(d.T.Attrs).T {
+					if k, ok := drops[parentName(d.T, p)]; ok && k == i {
+						visit(j)
+					}
+				}
+			}
+		}
+		planned = append(planned, changes[i])
+	}
+	for i := range changes {
+		visit(i)
+	}
+	return planned
+}
+
 // topLevel executes first the changes for creating or dropping schemas (top-level schema elements).
-func (s *state) topLevel(changes []schema.Change) []schema.Change {
+func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 	planned := make([]schema.Change, 0, len(changes))
 	for _, c := range changes {
 		switch c := c.(type) {
 		case *schema.AddSchema:
-			b := Build("CREATE SCHEMA").Ident(c.S.Name)
-			if sqlx.Has(c.Extra, &schema.IfNotExists{}) {
+			b := s.build("CREATE SCHEMA")
+			if s.guards || sqlx.Has(c.Extra, &schema.IfNotExists{}) {
 				b.P("IF NOT EXISTS")
 			}
+			b.Ident(c.S.Name)
 			s.append(&migrate.Change{
 				Cmd:     b.String(),
 				Source:  c,
-				Reverse: Build("DROP SCHEMA").Ident(c.S.Name).String(),
+				Reverse: s.build("DROP SCHEMA").Ident(c.S.Name).String(),
 				Comment: fmt.Sprintf("Add new schema named %q", c.S.Name),
 			})
+			if x := (schema.Comment{}); sqlx.Has(c.S.Attrs, &x) && x.Text != "" {
+				s.append(s.schemaComment(c.S, x.Text, ""))
+			}
+			for _, coll := range collations(c.S.Attrs) {
+				s.append(s.createCollation(c.S, coll, c))
+			}
 		case *schema.DropSchema:
-			b := Build("DROP SCHEMA").Ident(c.S.Name)
-			if sqlx.Has(c.Extra, &schema.IfExists{}) {
-				b.P("IF NOT EXISTS")
+			b := s.build("DROP SCHEMA")
+			if s.guards || sqlx.Has(c.Extra, &schema.IfExists{}) {
+				b.P("IF EXISTS")
 			}
+			b.Ident(c.S.Name)
 			s.append(&migrate.Change{
 				Cmd:     b.String(),
 				Source:  c,
 				Comment: fmt.Sprintf("Drop schema named %q", c.S.Name),
 			})
+		case *schema.ModifySchema:
+			if err := s.modifySchema(c); err != nil {
+				return nil, err
+			}
 		default:
 			planned = append(planned, c)
 		}
 	}
-	return planned
+	return planned, nil
+}
+
+// modifySchema builds and appends the migrate.Changes for bringing
+// the schema into its modified state.
+func (s *state) modifySchema(modify *schema.ModifySchema) error {
+	for _, change := range modify.Changes {
+		if s.collationChange(modify.S, change) {
+			continue
+		}
+		from, to, err := commentChange(change)
+		if err != nil {
+			return fmt.Errorf("unsupported ModifySchema change: %w", err)
+		}
+		c := s.schemaComment(modify.S, to, from)
+		c.Source = modify
+		s.append(c)
+	}
+	return nil
 }
 
 // addTable builds and executes the query for creating a table in a schema.
 func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
+	if s.redshift {
+		return s.addRedshiftTable(add)
+	}
 	// Create enum types before using them in the `CREATE TABLE` statement.
-	if err := s.addTypes(ctx, add.T.Columns...); err != nil {
+	if err := s.addTypes(ctx, add.T, add.T.Columns...); err != nil {
 		return err
 	}
-	b := Build("CREATE TABLE").Table(add.T)
-	if sqlx.Has(add.Extra, &schema.IfNotExists{}) {
+	b := s.build("CREATE TABLE")
+	if s.guards || sqlx.Has(add.Extra, &schema.IfNotExists{}) {
 		b.P("IF NOT EXISTS")
 	}
-	b.Wrap(func(b *sqlx.Builder) {
+	b.Table(add.T).Wrap(func(b *sqlx.Builder) {
 		b.MapComma(add.T.Columns, func(i int, b *sqlx.Builder) {
-			s.column(b, add.T.Columns[i])
+			s.column(b, add.T, add.T.Columns[i])
 		})
 		if pk := add.T.PrimaryKey; pk != nil {
 			b.Comma().P("PRIMARY KEY")
@@ -142,11 +421,22 @@ func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 			}
 		}
 	})
+	if p := inherits(add.T.Attrs).T; len(p) > 0 {
+		b.P("INHERITS").Wrap(func(b *sqlx.Builder) {
+			b.MapComma(p, func(i int, b *sqlx.Builder) {
+				b.Table(p[i])
+			})
+		})
+	}
+	tableParamsClause(b, "WITH", &TableStorageParams{}, tableParams(add.T.Attrs))
+	if t := (Tablespace{}); sqlx.Has(add.T.Attrs, &t) {
+		b.P("TABLESPACE").Ident(t.N)
+	}
 	s.append(&migrate.Change{
 		Cmd:     b.String(),
 		Source:  add,
 		Comment: fmt.Sprintf("create %q table", add.T.Name),
-		Reverse: Build("DROP TABLE").Table(add.T).String(),
+		Reverse: s.build("DROP TABLE").Table(add.T).String(),
 	})
 	s.addIndexes(add.T, add.T.Indexes...)
 	s.addComments(add.T)
@@ -155,10 +445,11 @@ func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 
 // dropTable builds and executes the query for dropping a table from a schema.
 func (s *state) dropTable(drop *schema.DropTable) {
-	b := Build("DROP TABLE").Table(drop.T)
-	if sqlx.Has(drop.Extra, &schema.IfExists{}) {
+	b := s.build("DROP TABLE")
+	if s.guards || sqlx.Has(drop.Extra, &schema.IfExists{}) {
 		b.P("IF EXISTS")
 	}
+	b.Table(drop.T)
 	s.append(&migrate.Change{
 		Cmd:     b.String(),
 		Source:  drop,
@@ -168,14 +459,38 @@ func (s *state) dropTable(drop *schema.DropTable) {
 
 // modifyTable builds the statements that bring the table into its modified state.
 func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) error {
+	if s.redshift {
+		return s.modifyRedshiftTable(modify)
+	}
+	if s.ordered && sqlx.ReorderColumns(modify.T, modify.Changes) {
+		return s.rewriteTable(ctx, modify)
+	}
 	var (
 		changes     []schema.Change
 		addI, dropI []*schema.Index
 		comments    []*migrate.Change
+		seqs        []*migrate.Change
+		spaces      []*migrate.Change
+		renames     []*migrate.Change
+		validates   []*migrate.Change
+		rebuilds    []*schema.ModifyIndex
 	)
 	for _, change := range skipAutoChanges(modify.Changes) {
 		switch change := change.(type) {
 		case *schema.AddAttr, *schema.ModifyAttr:
+			// Tablespace moves, inheritance and storage parameters
+			// changes are executed as part of the ALTER TABLE command.
+			if _, _, ok := tablespaceChange(change); ok {
+				changes = append(changes, change)
+				continue
+			}
+			if m, ok := change.(*schema.ModifyAttr); ok {
+				switch m.To.(type) {
+				case *Inherits, *TableStorageParams:
+					changes = append(changes, change)
+					continue
+				}
+			}
 			from, to, err := commentChange(change)
 			if err != nil {
 				return err
@@ -203,7 +518,21 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 					continue
 				}
 			}
+			if tablespaceChanged(change.From.Attrs, change.To.Attrs) {
+				spaces = append(spaces, s.indexTablespace(modify.T, change.To, tablespace(change.To.Attrs).N, tablespace(change.From.Attrs).N))
+				// Moving an index to another tablespace does not require rebuilding it.
+				if k.Is(schema.ChangeAttr) && !indexAttrChanged(change.From.Attrs, change.To.Attrs) {
+					k &= ^schema.ChangeAttr
+				}
+				if k.Is(schema.NoChange) {
+					continue
+				}
+			}
 			// Index modification requires rebuilding the index.
+			if s.indexRebuild == IndexRebuildConcurrently && change.From.Name != "" && change.From.Name == change.To.Name {
+				rebuilds = append(rebuilds, change)
+				continue
+			}
 			addI = append(addI, change.To)
 			dropI = append(dropI, change.From)
 		case *schema.ModifyForeignKey:
@@ -215,7 +544,7 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 				F: change.To,
 			})
 		case *schema.AddColumn:
-			if err := s.addTypes(ctx, change.C); err != nil {
+			if err := s.addTypes(ctx, modify.T, change.C); err != nil {
 				return err
 			}
 			if c := (schema.Comment{}); sqlx.Has(change.C.Attrs, &c) {
@@ -240,7 +569,7 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 			switch {
 			// Enum was changed.
 			case ok1 && ok2 && from.T == to.T:
-				if err := s.alterType(from, to); err != nil {
+				if err := s.alterType(modify.T, from, to); err != nil {
 					return err
 				}
 				// If only the enum values were changed,
@@ -250,22 +579,78 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 				}
 			// Enum was added (and column type was changed).
 			case !ok1 && ok2:
-				if err := s.addTypes(ctx, change.To); err != nil {
+				if err := s.addTypes(ctx, modify.T, change.To); err != nil {
 					return err
 				}
 			}
+			if k.Is(schema.ChangeAttr) {
+				pre, post := s.identitySeq(modify.T, change)
+				s.append(pre...)
+				seqs = append(seqs, post...)
+				// The IDENTITY attribute was dropped by a separate statement. See identitySeq.
+				if _, ok := identityToSerial(change); ok {
+					if change = (&schema.ModifyColumn{From: change.From, To: change.To, Change: change.Change & ^schema.ChangeAttr}); change.Change.Is(schema.NoChange) {
+						continue
+					}
+				}
+			}
 			changes = append(changes, change)
+		case *schema.ModifyCheck:
+			// Validating a NOT VALID constraint is executed in a separate statement,
+			// as it scans the table and does not block concurrent writes.
+			if !validateOnly(change) {
+				changes = append(changes, change)
+				continue
+			}
+			validates = append(validates, &migrate.Change{
+				Cmd:     s.build("ALTER TABLE").Table(modify.T).P("VALIDATE CONSTRAINT").Ident(change.To.Name).String(),
+				Source:  change,
+				Comment: fmt.Sprintf("validate %q constraint of table: %q", change.To.Name, modify.T.Name),
+			})
+		// Columns are renamed in separate statements, because PostgreSQL
+		// does not allow combining RENAME with other ALTER TABLE actions.
+		case *schema.RenameColumn:
+			renames = append(renames, &migrate.Change{
+				Cmd:     s.build("ALTER TABLE").Table(modify.T).P("RENAME COLUMN").Ident(change.From.Name).P("TO").Ident(change.To.Name).String(),
+				Source:  change,
+				Reverse: s.build("ALTER TABLE").Table(modify.T).P("RENAME COLUMN").Ident(change.To.Name).P("TO").Ident(change.From.Name).String(),
+				Comment: fmt.Sprintf("rename column %q to %q in table: %q", change.From.Name, change.To.Name, modify.T.Name),
+			})
 		default:
 			changes = append(changes, change)
 		}
 	}
+	s.append(renames...)
 	s.dropIndexes(modify.T, dropI...)
-	if len(changes) > 0 {
-		if err := s.alterTable(modify.T, changes); err != nil {
+	alter, guarded := changes, []schema.Change(nil)
+	if s.guards {
+		alter, guarded = guardedConstraints(changes)
+	}
+	if len(alter) > 0 {
+		if err := s.alterTable(modify.T, alter); err != nil {
 			return err
 		}
 	}
+	for _, c := range guarded {
+		if err := s.alterTable(modify.T, []schema.Change{c}); err != nil {
+			return err
+		}
+		s.guardConstraint(modify.T, s.Changes[len(s.Changes)-1], c)
+	}
+	for _, c := range changes {
+		if name, ok := s.validateLater(c); ok {
+			validates = append(validates, &migrate.Change{
+				Cmd:     s.build("ALTER TABLE").Table(modify.T).P("VALIDATE CONSTRAINT").Ident(name).String(),
+				Source:  c,
+				Comment: fmt.Sprintf("validate %q constraint of table: %q", name, modify.T.Name),
+			})
+		}
+	}
+	s.append(validates...)
+	s.append(seqs...)
 	s.addIndexes(modify.T, addI...)
+	s.rebuildIndexes(modify.T, rebuilds...)
+	s.append(spaces...)
 	s.append(comments...)
 	return nil
 }
@@ -274,44 +659,85 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 	var (
 		errors     []string
-		b          = Build("ALTER TABLE").Table(t)
-		reverse    = Build("")
+		b          = s.build("ALTER TABLE").Table(t)
+		reverse    = s.build("")
 		reversible = true
 	)
 	b.MapComma(changes, func(i int, b *sqlx.Builder) {
 		switch change := changes[i].(type) {
 		case *schema.AddColumn:
 			b.P("ADD COLUMN")
-			s.column(b, change.C)
+			if s.guards {
+				b.P("IF NOT EXISTS")
+			}
+			s.column(b, t, change.C)
 			reverse.Comma().P("DROP COLUMN").Ident(change.C.Name)
 		case *schema.DropColumn:
-			b.P("DROP COLUMN").Ident(change.C.Name)
+			s.dropClause(b, "COLUMN").Ident(change.C.Name)
 			reversible = false
 		case *schema.ModifyColumn:
-			if err := s.alterColumn(b, change.Change, change.To); err != nil {
+			if err := s.alterColumn(b, t, change.Change, change.From, change.To); err != nil {
 				errors = append(errors, err.Error())
 			}
-			if err := s.alterColumn(reverse, change.Change, change.From); err != nil {
+			if err := s.alterColumn(reverse, t, change.Change, change.To, change.From); err != nil {
 				errors = append(errors, err.Error())
 			}
 		case *schema.AddForeignKey:
 			b.P("ADD")
 			s.fks(b, change.F)
+			if _, ok := s.validateLater(change); ok {
+				b.P("NOT VALID")
+			}
 			reverse.Comma().P("DROP CONSTRAINT").Ident(change.F.Symbol)
 		case *schema.DropForeignKey:
-			b.P("DROP CONSTRAINT").Ident(change.F.Symbol)
+			s.dropClause(b, "CONSTRAINT").Ident(change.F.Symbol)
 			reverse.P("ADD")
 			s.fks(reverse, change.F)
 		case *schema.AddCheck:
-			check(b.P("ADD"), change.C)
+			addCheck(b, change.C)
+			if _, ok := s.validateLater(change); ok {
+				b.P("NOT VALID")
+			}
 			// Reverse operation is supported if
 			// the constraint name is not generated.
 			if reversible = change.C.Name != ""; reversible {
 				reverse.Comma().P("DROP CONSTRAINT").Ident(change.C.Name)
 			}
 		case *schema.DropCheck:
-			b.P("DROP CONSTRAINT").Ident(change.C.Name)
-			check(reverse.Comma().P("ADD"), change.C)
+			s.dropClause(b, "CONSTRAINT").Ident(change.C.Name)
+			addCheck(reverse.Comma(), change.C)
+		case *schema.ModifyAttr:
+			if from, to, ok := tablespaceChange(change); ok {
+				b.P("SET TABLESPACE").Ident(to.N)
+				reverse.Comma().P("SET TABLESPACE").Ident(from.N)
+				break
+			}
+			if from, ok := change.From.(*TableStorageParams); ok {
+				to, ok := change.To.(*TableStorageParams)
+				if !ok {
+					errors = append(errors, fmt.Sprintf("unexpected table attribute change: %T", change.To))
+					break
+				}
+				tableParamsClause(b, "SET", from, to)
+				tableParamsClause(reverse.Comma(), "SET", to, from)
+				break
+			}
+			from, ok1 := change.From.(*Inherits)
+			to, ok2 := change.To.(*Inherits)
+			if !ok1 || !ok2 {
+				errors = append(errors, fmt.Sprintf("unexpected table attribute change: %T", change.To))
+				break
+			}
+			inheritClauses(b, t, from, to)
+			inheritClauses(reverse.Comma(), t, to, from)
+		case *schema.AddAttr:
+			from, to, ok := tablespaceChange(change)
+			if !ok {
+				errors = append(errors, fmt.Sprintf("unexpected table attribute: %T", change.A))
+				break
+			}
+			b.P("SET TABLESPACE").Ident(to.N)
+			reverse.Comma().P("SET TABLESPACE").Ident(from.N)
 		case *schema.ModifyCheck:
 			switch {
 			case change.From.Name == "":
@@ -321,10 +747,10 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 			case change.From.Expr != change.To.Expr,
 				sqlx.Has(change.From.Attrs, &NoInherit{}) && !sqlx.Has(change.To.Attrs, &NoInherit{}),
 				!sqlx.Has(change.From.Attrs, &NoInherit{}) && sqlx.Has(change.To.Attrs, &NoInherit{}):
-				b.P("DROP CONSTRAINT").Ident(change.From.Name).Comma().P("ADD")
-				check(b, change.To)
-				reverse.Comma().P("DROP CONSTRAINT").Ident(change.To.Name).Comma().P("ADD")
-				check(reverse, change.From)
+				s.dropClause(b, "CONSTRAINT").Ident(change.From.Name).Comma()
+				addCheck(b, change.To)
+				reverse.Comma().P("DROP CONSTRAINT").Ident(change.To.Name).Comma()
+				addCheck(reverse, change.From)
 			default:
 				errors = append(errors, "unknown check constraints change")
 			}
@@ -342,7 +768,7 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 		Comment: fmt.Sprintf("Modify %q table", t.Name),
 	}
 	if reversible {
-		b := Build("ALTER TABLE").Table(t)
+		b := s.build("ALTER TABLE").Table(t)
 		if _, err := b.ReadFrom(reverse); err != nil {
 			return fmt.Errorf("unexpected buffer read: %w", err)
 		}
@@ -369,8 +795,17 @@ func (s *state) addComments(t *schema.Table) {
 	}
 }
 
-func (*state) tableComment(t *schema.Table, to, from string) *migrate.Change {
-	b := Build("COMMENT ON TABLE").Table(t).P("IS")
+func (s *state) schemaComment(sc *schema.Schema, to, from string) *migrate.Change {
+	b := s.build("COMMENT ON SCHEMA").Ident(sc.Name).P("IS")
+	return &migrate.Change{
+		Cmd:     b.Clone().P(quote(to)).String(),
+		Comment: fmt.Sprintf("set comment to schema: %q", sc.Name),
+		Reverse: b.Clone().P(quote(from)).String(),
+	}
+}
+
+func (s *state) tableComment(t *schema.Table, to, from string) *migrate.Change {
+	b := s.build("COMMENT ON TABLE").Table(t).P("IS")
 	return &migrate.Change{
 		Cmd:     b.Clone().P(quote(to)).String(),
 		Comment: fmt.Sprintf("set comment to table: %q", t.Name),
@@ -378,8 +813,8 @@ func (*state) tableComment(t *schema.Table, to, from string) *migrate.Change {
 	}
 }
 
-func (*state) columnComment(t *schema.Table, c *schema.Column, to, from string) *migrate.Change {
-	b := Build("COMMENT ON COLUMN").Table(t)
+func (s *state) columnComment(t *schema.Table, c *schema.Column, to, from string) *migrate.Change {
+	b := s.build("COMMENT ON COLUMN").Table(t)
 	b.WriteByte('.')
 	b.Ident(c.Name).P("IS")
 	return &migrate.Change{
@@ -389,8 +824,20 @@ func (*state) columnComment(t *schema.Table, c *schema.Column, to, from string)
 	}
 }
 
-func (*state) indexComment(t *schema.Table, idx *schema.Index, to, from string) *migrate.Change {
-	b := Build("COMMENT ON INDEX").Ident(idx.Name).P("IS")
+func (s *state) indexTablespace(t *schema.Table, idx *schema.Index, to, from string) *migrate.Change {
+	build := func(name string) string {
+		// Indexes are printed with their qualified name, as they are not attached to ALTER TABLE.
+		return s.object(s.build("ALTER INDEX"), t, idx.Name).P("SET TABLESPACE").Ident(name).String()
+	}
+	return &migrate.Change{
+		Cmd:     build(to),
+		Comment: fmt.Sprintf("move index %q to tablespace %q", idx.Name, to),
+		Reverse: build(from),
+	}
+}
+
+func (s *state) indexComment(t *schema.Table, idx *schema.Index, to, from string) *migrate.Change {
+	b := s.object(s.build("COMMENT ON INDEX"), t, idx.Name).P("IS")
 	return &migrate.Change{
 		Cmd:     b.Clone().P(quote(to)).String(),
 		Comment: fmt.Sprintf("set comment to index: %q on table: %q", idx.Name, t.Name),
@@ -399,7 +846,7 @@ func (*state) indexComment(t *schema.Table, idx *schema.Index, to, from string)
 }
 
 func (s *state) dropIndexes(t *schema.Table, indexes ...*schema.Index) {
-	rs := &state{conn: s.conn}
+	rs := &state{conn: s.conn, guards: s.guards}
 	rs.addIndexes(t, indexes...)
 	for i, idx := range indexes {
 		s.append(&migrate.Change{
@@ -410,7 +857,7 @@ func (s *state) dropIndexes(t *schema.Table, indexes ...*schema.Index) {
 	}
 }
 
-func (s *state) addTypes(ctx context.Context, columns ...*schema.Column) error {
+func (s *state) addTypes(ctx context.Context, t *schema.Table, columns ...*schema.Column) error {
 	for _, c := range columns {
 		e, ok := c.Type.Type.(*schema.EnumType)
 		if !ok {
@@ -420,27 +867,31 @@ func (s *state) addTypes(ctx context.Context, columns ...*schema.Column) error {
 			return fmt.Errorf("missing enum name for column %q", c.Name)
 		}
 		c.Type.Raw = e.T
-		if exists, err := s.enumExists(ctx, e.T); err != nil {
+		if exists, err := s.enumExists(ctx, t, e.T); err != nil {
 			return err
 		} else if exists {
 			continue
 		}
-		b := Build("CREATE TYPE").Ident(e.T).P("AS ENUM")
+		b := s.enumType(s.build("CREATE TYPE"), t, e).P("AS ENUM")
 		b.Wrap(func(b *sqlx.Builder) {
 			b.MapComma(e.Values, func(i int, b *sqlx.Builder) {
 				b.WriteString("'" + e.Values[i] + "'")
 			})
 		})
+		cmd := b.String()
+		if s.guards {
+			cmd = guardDo(fmt.Sprintf("to_regtype(%s) IS NULL", quote(s.enumType(Build(""), t, e).String())), cmd)
+		}
 		s.append(&migrate.Change{
-			Cmd:     b.String(),
+			Cmd:     cmd,
 			Comment: fmt.Sprintf("create enum type %q", e.T),
-			Reverse: Build("DROP TYPE").Ident(e.T).String(),
+			Reverse: s.enumType(s.build("DROP TYPE"), t, e).String(),
 		})
 	}
 	return nil
 }
 
-func (s *state) alterType(from, to *schema.EnumType) error {
+func (s *state) alterType(t *schema.Table, from, to *schema.EnumType) error {
 	if len(from.Values) > len(to.Values) {
 		return fmt.Errorf("dropping enum (%q) value is not supported", from.T)
 	}
@@ -451,15 +902,92 @@ func (s *state) alterType(from, to *schema.EnumType) error {
 	}
 	for _, v := range to.Values[len(from.Values):] {
 		s.append(&migrate.Change{
-			Cmd:     Build("ALTER TYPE").Ident(from.T).P("ADD VALUE", quote(v)).String(),
+			Cmd:     s.enumType(s.build("ALTER TYPE"), t, from).P("ADD VALUE", quote(v)).String(),
 			Comment: fmt.Sprintf("add value to enum type: %q", from.T),
 		})
 	}
 	return nil
 }
 
-func (s *state) enumExists(ctx context.Context, name string) (bool, error) {
-	rows, err := s.QueryContext(ctx, "SELECT * FROM pg_type WHERE typname = $1 AND typtype = 'e'", name)
+// dropClause writes the DROP clause of the given object kind (e.g. COLUMN) to the
+// builder of an ALTER TABLE statement, guarded with IF EXISTS if it was requested.
+func (s *state) dropClause(b *sqlx.Builder, kind string) *sqlx.Builder {
+	b.P("DROP", kind)
+	if s.guards {
+		b.P("IF EXISTS")
+	}
+	return b
+}
+
+// guardedConstraints splits the given table changes into the changes that are guarded
+// by their clauses, and named constraint additions that do not support IF NOT EXISTS,
+// and are executed in separate statements, guarded by catalog checks.
+func guardedConstraints(changes []schema.Change) (alter, guarded []schema.Change) {
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddForeignKey:
+			if c.F.Symbol != "" {
+				guarded = append(guarded, c)
+				continue
+			}
+		case *schema.AddCheck:
+			if c.C.Name != "" {
+				guarded = append(guarded, c)
+				continue
+			}
+		}
+		alter = append(alter, c)
+	}
+	return alter, guarded
+}
+
+// guardConstraint wraps the planned statement that adds the given constraint
+// with a DO block, that executes it only if the constraint does not exist.
+func (s *state) guardConstraint(t *schema.Table, planned *migrate.Change, c schema.Change) {
+	var name string
+	switch c := c.(type) {
+	case *schema.AddForeignKey:
+		name = c.F.Symbol
+	case *schema.AddCheck:
+		name = c.C.Name
+	}
+	cond := fmt.Sprintf(
+		"NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conrelid = to_regclass(%s) AND conname = %s)",
+		quote(strings.TrimSpace(s.build("").Table(t).String())), quote(name),
+	)
+	planned.Cmd = guardDo(cond, planned.Cmd)
+}
+
+// guardDo returns a DO block that executes the given statement only if the
+// condition holds. It is used for statements that do not support IF NOT EXISTS.
+// The block body is quoted with a dollar tag that does not appear in the statement
+// (e.g. a CHECK expression or a default value that contains $$).
+func guardDo(cond, stmt string) string {
+	body := fmt.Sprintf("BEGIN IF %s THEN %s; END IF; END", cond, stmt)
+	tag := "$atlas$"
+	for strings.Contains(body, tag) {
+		tag = tag[:len(tag)-1] + "_$"
+	}
+	return fmt.Sprintf("DO %s %s %s", tag, body, tag)
+}
+
+// enumType writes the name of the enum type to the builder. Types that are not
+// qualified explicitly are qualified with the schema of the table, if it is known.
+func (s *state) enumType(b *sqlx.Builder, t *schema.Table, e *schema.EnumType) *sqlx.Builder {
+	if strings.Contains(e.T, ".") {
+		return b.Ident(e.T)
+	}
+	return s.object(b, t, e.T)
+}
+
+func (s *state) enumExists(ctx context.Context, t *schema.Table, name string) (bool, error) {
+	query, args := "SELECT * FROM pg_type WHERE typname = $1 AND typtype = 'e'", []interface{}{name}
+	// Types that have the same name may exist in different schemas.
+	if ns := s.schemaOf(t); ns != "" && !strings.Contains(name, ".") {
+		query = "SELECT * FROM pg_type t1 JOIN pg_namespace t2 ON t1.typnamespace = t2.oid WHERE t1.typname = $1 AND t1.typtype = 'e' AND t2.nspname = $2"
+		args = append(args, ns)
+	}
+	rows, err := s.QueryContext(ctx, query, args...)
 	if err != nil {
 		return false, fmt.Errorf("check index existence: %w", err)
 	}
@@ -469,41 +997,153 @@ func (s *state) enumExists(ctx context.Context, name string) (bool, error) {
 
 func (s *state) addIndexes(t *schema.Table, indexes ...*schema.Index) {
 	for _, idx := range indexes {
-		b := Build("CREATE")
-		if idx.Unique {
-			b.P("UNIQUE")
-		}
-		b.P("INDEX")
-		if idx.Name != "" {
-			b.Ident(idx.Name)
-		}
-		b.P("ON").Table(t)
-		s.indexParts(b, idx.Parts)
-		s.indexAttrs(b, idx.Attrs)
 		s.append(&migrate.Change{
-			Cmd:     b.String(),
+			Cmd:     s.createIndex(t, idx, idx.Name, false).String(),
 			Comment: fmt.Sprintf("Create index %q to table: %q", idx.Name, t.Name),
 			Reverse: func() string {
-				b := Build("DROP INDEX")
 				// Unlike MySQL, the DROP command is not attached to ALTER TABLE.
 				// Therefore, we print indexes with their qualified name, because
 				// the connection that executes the statements may not be attached
 				// to the this schema.
-				if t.Schema != nil {
-					b.WriteByte(b.QuoteChar)
-					b.WriteString(t.Schema.Name)
-					b.WriteByte(b.QuoteChar)
-					b.WriteByte('.')
+				b := s.build("DROP INDEX")
+				if s.guards {
+					b.P("IF EXISTS")
 				}
-				b.Ident(idx.Name)
-				return b.String()
+				return s.object(b, t, idx.Name).String()
 			}(),
 		})
 	}
 }
 
-func (s *state) column(b *sqlx.Builder, c *schema.Column) {
-	b.Ident(c.Name).P(mustFormat(c.Type.Type))
+// createIndex returns the CREATE INDEX statement of the given index,
+// that is created with the given name, optionally without locking the
+// table against writes (i.e. CONCURRENTLY).
+func (s *state) createIndex(t *schema.Table, idx *schema.Index, name string, concurrently bool) *sqlx.Builder {
+	b := s.build("CREATE")
+	if idx.Unique {
+		b.P("UNIQUE")
+	}
+	b.P("INDEX")
+	if concurrently {
+		b.P("CONCURRENTLY")
+	}
+	if name != "" {
+		if s.guards {
+			b.P("IF NOT EXISTS")
+		}
+		b.Ident(name)
+	}
+	b.P("ON").Table(t)
+	// Avoid appending the default method.
+	if t := (IndexType{}); sqlx.Has(idx.Attrs, &t) && indexType(idx.Attrs) != "BTREE" {
+		b.P("USING").P(strings.ReplaceAll(t.T, "-", ""))
+	}
+	s.indexParts(b, idx.Parts)
+	s.indexAttrs(b, idx.Attrs)
+	return b
+}
+
+// rebuildIndexes rebuilds the modified indexes of the table without blocking
+// queries and writes to it. See IndexRebuildConcurrently for more info.
+func (s *state) rebuildIndexes(t *schema.Table, changes ...*schema.ModifyIndex) {
+	for _, c := range changes {
+		// CONCURRENTLY cannot be executed inside a transaction block.
+		s.Transactional = false
+		if s.paramsOnlyChange(c) {
+			s.append(&migrate.Change{
+				Cmd:     s.alterIndexParams(t, c.To, c.From, c.To).String(),
+				Source:  c,
+				Comment: fmt.Sprintf("set storage parameters of index %q on table: %q", c.To.Name, t.Name),
+				Reverse: s.alterIndexParams(t, c.To, c.To, c.From).String(),
+			})
+			s.append(&migrate.Change{
+				Cmd:     s.object(s.build("REINDEX INDEX CONCURRENTLY"), t, c.To.Name).String(),
+				Source:  c,
+				Comment: fmt.Sprintf("rebuild index %q on table: %q", c.To.Name, t.Name),
+			})
+			continue
+		}
+		tmp := "new_" + c.To.Name
+		s.append(&migrate.Change{
+			Cmd:     s.createIndex(t, c.To, tmp, true).String(),
+			Source:  c,
+			Comment: fmt.Sprintf("create index %q concurrently to replace index %q on table: %q", tmp, c.From.Name, t.Name),
+			Reverse: s.object(s.build("DROP INDEX CONCURRENTLY"), t, tmp).String(),
+		})
+		s.append(&migrate.Change{
+			Cmd:     s.object(s.build("DROP INDEX CONCURRENTLY"), t, c.From.Name).String(),
+			Source:  c,
+			Comment: fmt.Sprintf("drop index %q concurrently from table: %q", c.From.Name, t.Name),
+			Reverse: s.createIndex(t, c.From, c.From.Name, true).String(),
+		})
+		s.append(&migrate.Change{
+			Cmd:     s.object(s.build("ALTER INDEX"), t, tmp).P("RENAME TO").Ident(c.To.Name).String(),
+			Source:  c,
+			Comment: fmt.Sprintf("rename index %q to %q", tmp, c.To.Name),
+			Reverse: s.object(s.build("ALTER INDEX"), t, c.To.Name).P("RENAME TO").Ident(tmp).String(),
+		})
+	}
+}
+
+// paramsOnlyChange reports if only the storage parameters of the index were
+// changed, and the index can be rebuilt in place using REINDEX CONCURRENTLY.
+func (s *state) paramsOnlyChange(c *schema.ModifyIndex) bool {
+	var p1, p2 IndexPredicate
+	return s.supportsReindexConcurrently() && !c.Change.Is(schema.ChangeUnique) && !c.Change.Is(schema.ChangeParts) &&
+		indexType(c.From.Attrs) == indexType(c.To.Attrs) && sqlx.Has(c.From.Attrs, &p1) == sqlx.Has(c.To.Attrs, &p2) && p1.P == p2.P
+}
+
+// alterIndexParams returns the ALTER INDEX statement that changes the storage
+// parameters of the index from the ones of the "from" index to the "to" index.
+func (s *state) alterIndexParams(t *schema.Table, idx, from, to *schema.Index) *sqlx.Builder {
+	var (
+		set, reset []string
+		p1, p2     IndexStorageParams
+	)
+	sqlx.Has(from.Attrs, &p1)
+	sqlx.Has(to.Attrs, &p2)
+	param := func(name string, changed, isSet bool, v string) {
+		switch {
+		case !changed:
+		case isSet:
+			set = append(set, name+" = "+v)
+		default:
+			reset = append(reset, name)
+		}
+	}
+	param("fillfactor", p1.FillFactor != p2.FillFactor, p2.FillFactor > 0, strconv.FormatInt(p2.FillFactor, 10))
+	param("pages_per_range", p1.PagesPerRange != p2.PagesPerRange, p2.PagesPerRange > 0, strconv.FormatInt(p2.PagesPerRange, 10))
+	param("autosummarize", p1.AutoSummarize != p2.AutoSummarize, p2.AutoSummarize, "on")
+	fastUpdate := func(p *IndexStorageParams) string {
+		switch {
+		case p.FastUpdate == nil:
+			return ""
+		case *p.FastUpdate:
+			return "on"
+		default:
+			return "off"
+		}
+	}
+	param("fastupdate", fastUpdate(&p1) != fastUpdate(&p2), p2.FastUpdate != nil, fastUpdate(&p2))
+	b := s.object(s.build("ALTER INDEX"), t, idx.Name)
+	if len(set) > 0 {
+		b.P("SET").Wrap(func(b *sqlx.Builder) {
+			b.P(strings.Join(set, ", "))
+		})
+	}
+	if len(reset) > 0 {
+		if len(set) > 0 {
+			b.Comma()
+		}
+		b.P("RESET").Wrap(func(b *sqlx.Builder) {
+			b.P(strings.Join(reset, ", "))
+		})
+	}
+	return b
+}
+
+func (s *state) column(b *sqlx.Builder, t *schema.Table, c *schema.Column) {
+	b.Ident(c.Name).P(s.typeName(t, c))
 	if !c.Type.Null {
 		b.P("NOT")
 	}
@@ -513,17 +1153,23 @@ func (s *state) column(b *sqlx.Builder, c *schema.Column) {
 		switch a := attr.(type) {
 		case *schema.Comment:
 		case *schema.Collation:
-			b.P("COLLATE").Ident(a.V)
+			s.collate(b, t, a.V)
 		case *Identity:
 			// Handled below.
 		default:
 			panic(fmt.Sprintf("unexpected column attribute: %T", attr))
 		}
 	}
-	id, ok := identity(c.Attrs)
-	if !ok {
-		return
+	switch id, ok := identity(c.Attrs); {
+	case ok && s.redshift:
+		redshiftIdentityClause(b, id)
+	case ok:
+		identityClause(b, id)
 	}
+}
+
+// identityClause writes the GENERATED AS IDENTITY clause of the given identity to the builder.
+func identityClause(b *sqlx.Builder, id *Identity) {
 	b.P("GENERATED", id.Generation, "AS IDENTITY")
 	if id.Sequence.Start != defaultSeqStart || id.Sequence.Increment != defaultSeqIncrement {
 		b.Wrap(func(b *sqlx.Builder) {
@@ -556,36 +1202,67 @@ func (s *state) columnDefault(b *sqlx.Builder, c *schema.Column) {
 	}
 }
 
-func (s *state) alterColumn(b *sqlx.Builder, k schema.ChangeKind, c *schema.Column) error {
+func (s *state) alterColumn(b *sqlx.Builder, t *schema.Table, k schema.ChangeKind, from, to *schema.Column) error {
 	for !k.Is(schema.NoChange) {
-		b.P("ALTER COLUMN").Ident(c.Name)
-		switch {
-		case k.Is(schema.ChangeType):
-			b.P("TYPE").P(mustFormat(c.Type.Type))
-			if collate := (schema.Collation{}); sqlx.Has(c.Attrs, &collate) {
-				b.P("COLLATE", collate.V)
+		b.P("ALTER COLUMN").Ident(to.Name)
+		switch _, fromID := identity(from.Attrs); {
+		// Changing the collation of a column requires restating its type.
+		case k.Is(schema.ChangeType), k.Is(schema.ChangeCollation):
+			b.P("TYPE").P(s.typeName(t, to))
+			if collate := (schema.Collation{}); sqlx.Has(to.Attrs, &collate) {
+				s.collate(b, t, collate.V)
 			}
-			k &= ^schema.ChangeType
-		case k.Is(schema.ChangeNull) && c.Type.Null:
+			k &= ^(schema.ChangeType | schema.ChangeCollation)
+		// The IDENTITY attribute is dropped before the DEFAULT value is
+		// set, as identity columns cannot have a DEFAULT value. For example,
+		// when reverting the conversion of a serial column to identity. Note
+		// that conversions to serial drop it in a separate statement.
+		case k.Is(schema.ChangeAttr) && fromID && !sqlx.Has(to.Attrs, &Identity{}):
+			b.P("DROP IDENTITY")
+			k &= ^schema.ChangeAttr
+		case k.Is(schema.ChangeNull) && to.Type.Null:
 			b.P("DROP NOT NULL")
 			k &= ^schema.ChangeNull
-		case k.Is(schema.ChangeNull) && !c.Type.Null:
+		case k.Is(schema.ChangeNull) && !to.Type.Null:
 			b.P("SET NOT NULL")
 			k &= ^schema.ChangeNull
-		case k.Is(schema.ChangeDefault) && c.Default == nil:
+		case k.Is(schema.ChangeDefault) && to.Default == nil:
 			b.P("DROP DEFAULT")
 			k &= ^schema.ChangeDefault
-		case k.Is(schema.ChangeDefault) && c.Default != nil:
-			s.columnDefault(b.P("SET"), c)
+		case k.Is(schema.ChangeDefault) && to.Default != nil:
+			// The DEFAULT value of serial columns is set by the differ ("nextval"),
+			// and it is skipped on column creation. Hence, we set it manually on
+			// conversion from other types (e.g. identity).
+			if x, ok := to.Default.(*schema.RawExpr); ok && isSerial(to) {
+				b.P("SET DEFAULT", x.X)
+			} else {
+				s.columnDefault(b.P("SET"), to)
+			}
 			k &= ^schema.ChangeDefault
 		case k.Is(schema.ChangeAttr):
-			id, ok := identity(c.Attrs)
+			id, ok := identity(to.Attrs)
 			if !ok {
-				return fmt.Errorf("unexpected attribute change (expect IDENTITY): %v", c.Attrs)
+				return fmt.Errorf("unexpected attribute change (expect IDENTITY): %v", to.Attrs)
+			}
+			if !fromID {
+				identityClause(b.P("ADD"), id)
+				k &= ^schema.ChangeAttr
+				break
+			}
+			prev, _ := identity(from.Attrs)
+			if prev.Generation != id.Generation {
+				b.P("SET GENERATED", id.Generation)
 			}
 			// The syntax for altering identity columns is identical to sequence_options.
 			// https://www.postgresql.org/docs/current/sql-altersequence.html
-			b.P("SET START WITH", strconv.FormatInt(id.Sequence.Start, 10), "SET INCREMENT BY", strconv.FormatInt(id.Sequence.Increment, 10), "RESTART")
+			switch start, inc := prev.Sequence.Start != id.Sequence.Start, prev.Sequence.Increment != id.Sequence.Increment; {
+			case s.seqStart == SequenceStartIgnore && inc:
+				b.P("SET INCREMENT BY", strconv.FormatInt(id.Sequence.Increment, 10))
+			case s.seqStart == SequenceStartSet && (start || inc):
+				b.P("SET START WITH", strconv.FormatInt(id.Sequence.Start, 10), "SET INCREMENT BY", strconv.FormatInt(id.Sequence.Increment, 10))
+			case s.seqStart == SequenceStartRestart && (start || inc):
+				b.P("SET START WITH", strconv.FormatInt(id.Sequence.Start, 10), "SET INCREMENT BY", strconv.FormatInt(id.Sequence.Increment, 10), "RESTART")
+			}
 			k &= ^schema.ChangeAttr
 		case k.Is(schema.ChangeComment):
 			// Handled separately on modifyTable.
@@ -600,6 +1277,90 @@ func (s *state) alterColumn(b *sqlx.Builder, k schema.ChangeKind, c *schema.Colu
 	return nil
 }
 
+// identitySeq returns the statements that are executed before and after altering a
+// column from serial to identity or vice versa. Before converting an identity column
+// to serial, its IDENTITY attribute is dropped, and a sequence is created and linked
+// to the column. The identity is dropped first, because PostgreSQL names its implicit
+// sequence like the serial one (i.e. <table>_<column>_seq), and drops it with it.
+// After the conversion, the sequence of the column is set to continue from the current
+// maximum value, and in case of serial to identity conversion, the unused serial
+// sequence is dropped.
+func (s *state) identitySeq(t *schema.Table, c *schema.ModifyColumn) (pre, post []*migrate.Change) {
+	_, fromID := identity(c.From.Attrs)
+	_, toID := identity(c.To.Attrs)
+	switch seq, ok := identityToSerial(c); {
+	case ok:
+		id, _ := identity(c.From.Attrs)
+		reverse := s.build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(c.To.Name).P("ADD")
+		identityClause(reverse, id)
+		pre = append(pre, &migrate.Change{
+			Cmd:     s.build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(c.To.Name).P("DROP IDENTITY").String(),
+			Source:  c,
+			Reverse: reverse.String(),
+			Comment: fmt.Sprintf("drop identity of column %q", c.To.Name),
+		}, &migrate.Change{
+			Cmd:     fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s OWNED BY %s.%s", seq, strings.TrimSpace(s.build("").Table(t).String()), strings.TrimSpace(s.build("").Ident(c.To.Name).String())),
+			Source:  c,
+			Comment: fmt.Sprintf("create sequence for serial column %q", c.To.Name),
+		})
+	case !fromID && toID && isSerial(c.From):
+		if seq, ok := serialSeq(c.From); ok {
+			post = append(post, &migrate.Change{
+				Cmd:     fmt.Sprintf("DROP SEQUENCE IF EXISTS %s", seq),
+				Source:  c,
+				Comment: fmt.Sprintf("drop serial sequence of column %q", c.To.Name),
+			})
+		}
+	default:
+		return nil, nil
+	}
+	table := strings.TrimSpace(s.build("").Table(t).String())
+	column := strings.TrimSpace(s.build("").Ident(c.To.Name).String())
+	post = append(post, &migrate.Change{
+		Cmd:     fmt.Sprintf("SELECT setval(pg_get_serial_sequence(%s, %s), COALESCE(MAX(%s), 0) + 1, false) FROM %s", quote(table), quote(c.To.Name), column, table),
+		Source:  c,
+		Comment: fmt.Sprintf("set the sequence of column %q to continue from its maximum value", c.To.Name),
+	})
+	return pre, post
+}
+
+// identityToSerial reports if the column is converted from identity to serial,
+// and returns the name of its serial sequence.
+func identityToSerial(c *schema.ModifyColumn) (string, bool) {
+	_, fromID := identity(c.From.Attrs)
+	_, toID := identity(c.To.Attrs)
+	if !fromID || toID || !isSerial(c.To) {
+		return "", false
+	}
+	return serialSeq(c.To)
+}
+
+// reNextval matches the DEFAULT value of serial columns.
+var reNextval = regexp.MustCompile(`^nextval\('(.+)'(?:::regclass)?\)$`)
+
+// isSerial reports if the column is a serial column, either
+// defined as a serial type or inspected from the database.
+func isSerial(c *schema.Column) bool {
+	if _, ok := c.Type.Type.(*SerialType); ok {
+		return true
+	}
+	_, ok := serialSeq(c)
+	return ok
+}
+
+// serialSeq returns the sequence name from the DEFAULT value of a serial column.
+func serialSeq(c *schema.Column) (string, bool) {
+	x, ok := c.Default.(*schema.RawExpr)
+	if !ok {
+		return "", false
+	}
+	m := reNextval.FindStringSubmatch(x.X)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
 func (s *state) indexParts(b *sqlx.Builder, parts []*schema.IndexPart) {
 	b.Wrap(func(b *sqlx.Builder) {
 		b.MapComma(parts, func(i int, b *sqlx.Builder) {
@@ -640,22 +1401,51 @@ func (s *state) partAttrs(b *sqlx.Builder, p *schema.IndexPart) {
 }
 
 func (s *state) indexAttrs(b *sqlx.Builder, attrs []schema.Attr) {
-	// Avoid appending the default method.
-	if t := (IndexType{}); sqlx.Has(attrs, &t) && strings.ToLower(t.T) != "btree" {
-		b.P("USING").P(t.T)
+	if p := (IndexStorageParams{}); sqlx.Has(attrs, &p) {
+		indexParamsClause(b, &p)
+	}
+	if t := (Tablespace{}); sqlx.Has(attrs, &t) {
+		b.P("TABLESPACE").Ident(t.N)
 	}
 	if p := (IndexPredicate{}); sqlx.Has(attrs, &p) {
 		b.P("WHERE").P(p.P)
 	}
 	for _, attr := range attrs {
 		switch attr.(type) {
-		case *schema.Comment, *ConType, *IndexType, *IndexPredicate:
+		case *schema.Comment, *ConType, *IndexType, *IndexPredicate, *Tablespace, *IndexStorageParams:
 		default:
 			panic(fmt.Sprintf("unexpected index attribute: %T", attr))
 		}
 	}
 }
 
+// indexParamsClause writes the WITH clause of the given
+// index storage parameters, if any of them was set.
+func indexParamsClause(b *sqlx.Builder, p *IndexStorageParams) {
+	var params []string
+	if p.FillFactor > 0 {
+		params = append(params, fmt.Sprintf("fillfactor = %d", p.FillFactor))
+	}
+	if p.PagesPerRange > 0 {
+		params = append(params, fmt.Sprintf("pages_per_range = %d", p.PagesPerRange))
+	}
+	if p.AutoSummarize {
+		params = append(params, "autosummarize = on")
+	}
+	if p.FastUpdate != nil {
+		v := "on"
+		if !*p.FastUpdate {
+			v = "off"
+		}
+		params = append(params, "fastupdate = "+v)
+	}
+	if len(params) > 0 {
+		b.P("WITH").Wrap(func(b *sqlx.Builder) {
+			b.WriteString(strings.Join(params, ", "))
+		})
+	}
+}
+
 func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) {
 	b.MapComma(fks, func(i int, b *sqlx.Builder) {
 		fk := fks[i]
@@ -674,6 +1464,9 @@ func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) {
 				b.Ident(fk.RefColumns[i].Name)
 			})
 		})
+		if fk.Match != "" {
+			b.P("MATCH", string(fk.Match))
+		}
 		if fk.OnUpdate != "" {
 			b.P("ON UPDATE", string(fk.OnUpdate))
 		}
@@ -693,6 +1486,60 @@ func Build(phrase string) *sqlx.Builder {
 	return b.P(phrase)
 }
 
+// build is like Build, but tables that are not attached to
+// a schema are qualified with the first schema in the search_path
+// (if it was configured), in order to keep the planned statements
+// unambiguous, regardless of the search_path of the executing connection.
+//
+// Identifiers are quoted only when it is needed, if the driver was configured so.
+func (s *state) build(phrase string) *sqlx.Builder {
+	b := Build(phrase)
+	if len(s.searchPath) > 0 {
+		b.Schema = s.searchPath[0]
+	}
+	if s.minQuote {
+		b.MustQuote = mustQuote
+	}
+	return b
+}
+
+// mustQuote reports if the given identifier must be quoted. Unquoted identifiers
+// are folded to lowercase by PostgreSQL, and therefore, identifiers that contain
+// uppercase letters must be quoted in order to keep their case.
+func mustQuote(s string) bool {
+	return sqlx.ReservedWord(s) || !sqlx.SimpleIdent(s, unicode.IsLower)
+}
+
+// schemaOf returns the name of the schema that holds the objects of the
+// given table (e.g. indexes and types), or an empty string if it is unknown.
+func (s *state) schemaOf(t *schema.Table) string {
+	switch {
+	case t.Schema != nil && t.Schema.Name != "":
+		return t.Schema.Name
+	case len(s.searchPath) > 0:
+		return s.searchPath[0]
+	default:
+		return ""
+	}
+}
+
+// object writes the name of a schema object that belongs to the schema
+// of the given table (e.g. an index), qualified with the schema name if
+// it is known.
+func (s *state) object(b *sqlx.Builder, t *schema.Table, name string) *sqlx.Builder {
+	return b.QualifiedIdent(s.schemaOf(t), name)
+}
+
+// typeName returns the formatted type of the column. Enum types are
+// qualified with the schema of the table, as they are created in it.
+func (s *state) typeName(t *schema.Table, c *schema.Column) string {
+	e, ok := c.Type.Type.(*schema.EnumType)
+	if !ok || strings.Contains(e.T, ".") || s.schemaOf(t) == "" {
+		return mustFormat(c.Type.Type)
+	}
+	return s.enumType(Build(""), t, e).String()
+}
+
 // skipAutoChanges filters unnecessary changes that are automatically
 // happened by the database when ALTER TABLE is executed.
 func skipAutoChanges(changes []schema.Change) []schema.Change {
@@ -755,6 +1602,78 @@ func commentChange(c schema.Change) (from, to string, err error) {
 	return
 }
 
+// inheritClauses writes the INHERIT and NO INHERIT clauses
+// for changing the parents of table t from one to the other.
+func inheritClauses(b *sqlx.Builder, t *schema.Table, from, to *Inherits) {
+	var (
+		clauses []func(*sqlx.Builder)
+		exists  = make(map[string]bool)
+	)
+	for _, p := range to.T {
+		exists[parentName(t, p)] = true
+	}
+	for _, p := range from.T {
+		p := p
+		if !exists[parentName(t, p)] {
+			clauses = append(clauses, func(b *sqlx.Builder) { b.P("NO INHERIT").Table(p) })
+		}
+		delete(exists, parentName(t, p))
+	}
+	for _, p := range to.T {
+		p := p
+		if exists[parentName(t, p)] {
+			clauses = append(clauses, func(b *sqlx.Builder) { b.P("INHERIT").Table(p) })
+		}
+	}
+	b.MapComma(clauses, func(i int, b *sqlx.Builder) {
+		clauses[i](b)
+	})
+}
+
+// tableParamsClause writes the clauses for changing the table storage parameters from
+// one state to the other. Parameters that were set or changed are written using the
+// given clause (WITH or SET), and parameters that were removed are reset.
+func tableParamsClause(b *sqlx.Builder, clause string, from, to *TableStorageParams) {
+	var set, reset []string
+	for _, n := range tableParamNames {
+		v1, ok1 := from.Value(n)
+		v2, ok2 := to.Value(n)
+		switch {
+		case ok2 && (!ok1 || v1 != v2):
+			set = append(set, fmt.Sprintf("%s = %s", n, v2))
+		case ok1 && !ok2:
+			reset = append(reset, n)
+		}
+	}
+	if len(set) > 0 {
+		b.P(clause).Wrap(func(b *sqlx.Builder) {
+			b.WriteString(strings.Join(set, ", "))
+		})
+	}
+	if len(reset) > 0 {
+		if len(set) > 0 {
+			b.Comma()
+		}
+		b.P("RESET").Wrap(func(b *sqlx.Builder) {
+			b.WriteString(strings.Join(reset, ", "))
+		})
+	}
+}
+
+// tablespaceChange returns the tablespaces of the given change if it is a
+// tablespace change. A missing current tablespace is the default one.
+func tablespaceChange(c schema.Change) (from, to *Tablespace, ok bool) {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		to, ok = c.A.(*Tablespace)
+		from = &Tablespace{N: defaultTablespace}
+	case *schema.ModifyAttr:
+		to, ok = c.To.(*Tablespace)
+		from, _ = c.From.(*Tablespace)
+	}
+	return from, to, ok && from != nil
+}
+
 // checks writes the CHECK constraint to the builder.
 func check(b *sqlx.Builder, c *schema.Check) {
 	expr := c.Expr
@@ -771,6 +1690,39 @@ func check(b *sqlx.Builder, c *schema.Check) {
 	}
 }
 
+// addCheck writes the ADD CHECK clause to the builder. Unlike CREATE TABLE,
+// constraints that are added to existing tables can be marked as NOT VALID.
+func addCheck(b *sqlx.Builder, c *schema.Check) {
+	check(b.P("ADD"), c)
+	if sqlx.Has(c.Attrs, &NotValid{}) {
+		b.P("NOT VALID")
+	}
+}
+
+// validateLater reports if the given constraint should be added as NOT VALID, and
+// returns its name for validating it in a separate statement. Constraints without
+// an explicit name cannot be validated later, and therefore, are validated on add.
+func (s *state) validateLater(c schema.Change) (string, bool) {
+	if !s.notValid {
+		return "", false
+	}
+	switch c := c.(type) {
+	case *schema.AddCheck:
+		return c.C.Name, c.C.Name != "" && !sqlx.Has(c.C.Attrs, &NotValid{})
+	case *schema.AddForeignKey:
+		return c.F.Symbol, c.F.Symbol != ""
+	}
+	return "", false
+}
+
+// validateOnly reports if the given change only validates a NOT VALID constraint.
+func validateOnly(c *schema.ModifyCheck) bool {
+	return c.From.Name != "" && c.From.Name == c.To.Name &&
+		sqlx.Has(c.From.Attrs, &NotValid{}) && !sqlx.Has(c.To.Attrs, &NotValid{}) &&
+		sqlx.Has(c.From.Attrs, &NoInherit{}) == sqlx.Has(c.To.Attrs, &NoInherit{}) &&
+		normalizeCheck(c.From.Expr) == normalizeCheck(c.To.Expr)
+}
+
 func quote(s string) string {
 	if sqlx.IsQuoted(s, '\'') {
 		return s
@@ -6,13 +6,16 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"ariga.io/atlas/sql/internal/sqltest"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/naming"
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -120,6 +123,31 @@ func TestPlanChanges(t *testing.T) {
 				Transactional: true,
 				Changes:       []*migrate.Change{{Cmd: `CREATE SCHEMA "test"`, Reverse: `DROP SCHEMA "test"`}}},
 		},
+		{
+			changes: []schema.Change{
+				&schema.AddSchema{S: schema.New("test").SetComment("test schema")},
+			},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE SCHEMA "test"`, Reverse: `DROP SCHEMA "test"`},
+					{Cmd: `COMMENT ON SCHEMA "test" IS 'test schema'`, Reverse: `COMMENT ON SCHEMA "test" IS ''`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifySchema{S: schema.New("test"), Changes: []schema.Change{
+					&schema.ModifyAttr{From: &schema.Comment{Text: "a"}, To: &schema.Comment{Text: "b"}},
+				}},
+			},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes:       []*migrate.Change{{Cmd: `COMMENT ON SCHEMA "test" IS 'b'`, Reverse: `COMMENT ON SCHEMA "test" IS 'a'`}},
+			},
+		},
 		{
 			changes: []schema.Change{
 				&schema.DropSchema{S: &schema.Schema{Name: "atlas"}},
@@ -278,6 +306,34 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: &schema.Table{Name: "users"},
+					Changes: []schema.Change{
+						&schema.AddCheck{
+							C: &schema.Check{Name: "id_positive", Expr: `("id" > 0)`, Attrs: []schema.Attr{&NotValid{}}},
+						},
+						&schema.ModifyCheck{
+							From: &schema.Check{Name: "id_nonzero", Expr: `("id" <> 0)`, Attrs: []schema.Attr{&NotValid{}}},
+							To:   &schema.Check{Name: "id_nonzero", Expr: `"id" != 0`},
+						},
+					},
+				},
+			},
+			plan: &migrate.Plan{
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "users" ADD CONSTRAINT "id_positive" CHECK ("id" > 0) NOT VALID`,
+						Reverse: `ALTER TABLE "users" DROP CONSTRAINT "id_positive"`,
+					},
+					{
+						Cmd: `ALTER TABLE "users" VALIDATE CONSTRAINT "id_nonzero"`,
+					},
+				},
+			},
+		},
 		{
 			changes: []schema.Change{
 				func() schema.Change {
@@ -538,6 +594,24 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("nick", "int")),
+					Changes: []schema.Change{
+						&schema.RenameColumn{From: schema.NewIntColumn("alias", "int"), To: schema.NewIntColumn("nick", "int")},
+						&schema.DropColumn{C: schema.NewIntColumn("age", "int")},
+					},
+				},
+			},
+			plan: &migrate.Plan{
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TABLE "public"."users" RENAME COLUMN "alias" TO "nick"`, Reverse: `ALTER TABLE "public"."users" RENAME COLUMN "nick" TO "alias"`},
+					{Cmd: `ALTER TABLE "public"."users" DROP COLUMN "age"`},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		db, mk, err := sqlmock.New()
@@ -559,3 +633,799 @@ func TestPlanChanges(t *testing.T) {
 		}
 	}
 }
+
+func TestPlanChanges_SequenceStart(t *testing.T) {
+	from := &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{}}}
+	to := &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Sequence: &Sequence{Start: 1024, Increment: 2}}}}
+	for mode, cmd := range map[SequenceStartMode]string{
+		SequenceStartRestart: `ALTER TABLE "users" ALTER COLUMN "id" SET START WITH 1024 SET INCREMENT BY 2 RESTART`,
+		SequenceStartSet:     `ALTER TABLE "users" ALTER COLUMN "id" SET START WITH 1024 SET INCREMENT BY 2`,
+		SequenceStartIgnore:  `ALTER TABLE "users" ALTER COLUMN "id" SET INCREMENT BY 2`,
+	} {
+		db, mk, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{mk}.version("130000")
+		drv, err := Open(db, WithSequenceStart(mode))
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+			&schema.ModifyTable{
+				T:       schema.NewTable("users").AddColumns(to),
+				Changes: []schema.Change{&schema.ModifyColumn{From: from, To: to, Change: schema.ChangeAttr}},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 1)
+		require.Equal(t, cmd, plan.Changes[0].Cmd)
+	}
+}
+
+func TestPlanChanges_Tablespace(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		id    = schema.NewIntColumn("id", "int")
+		users = schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(id).AddAttrs(&Tablespace{N: "fast"})
+		from  = schema.NewIndex("users_id").AddColumns(id).AddAttrs(&Tablespace{N: "slow"})
+		to    = schema.NewIndex("users_id").AddColumns(id).AddAttrs(&Tablespace{N: "fast"})
+	)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.ModifyAttr{From: &Tablespace{N: "slow"}, To: &Tablespace{N: "fast"}},
+				&schema.ModifyIndex{From: from, To: to, Change: schema.ChangeAttr},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" integer NOT NULL) TABLESPACE "fast"`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" SET TABLESPACE "fast"`, plan.Changes[1].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" SET TABLESPACE "slow"`, plan.Changes[1].Reverse)
+	require.Equal(t, `ALTER INDEX "public"."users_id" SET TABLESPACE "fast"`, plan.Changes[2].Cmd)
+	require.Equal(t, `ALTER INDEX "public"."users_id" SET TABLESPACE "slow"`, plan.Changes[2].Reverse)
+
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddIndex{I: to},
+	})
+	require.Error(t, err)
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddIndex{I: to}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE INDEX "users_id" ON "public"."users" ("id") TABLESPACE "fast"`, plan.Changes[0].Cmd)
+}
+
+func TestPlanChanges_IndexMethods(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		off   bool
+		id    = schema.NewIntColumn("id", "int")
+		tags  = schema.NewColumn("tags").SetType(&ArrayType{T: "text[]", Type: &schema.StringType{T: "text"}})
+		users = schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(id, tags)
+	)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddIndex{I: schema.NewIndex("users_id_brin").AddColumns(id).AddAttrs(&IndexType{T: "BRIN"}, &IndexStorageParams{PagesPerRange: 32, AutoSummarize: true})},
+				&schema.AddIndex{I: schema.NewIndex("users_tags").AddColumns(tags).AddAttrs(&IndexType{T: "GIN"}, &IndexStorageParams{FastUpdate: &off})},
+				&schema.AddIndex{I: schema.NewIndex("users_id_spgist").AddColumns(id).AddAttrs(&IndexType{T: "SP-GiST"}, &IndexStorageParams{FillFactor: 70}, &IndexPredicate{P: "id > 0"})},
+				&schema.AddIndex{I: schema.NewIndex("users_id").AddColumns(id).AddAttrs(&IndexType{T: "BTREE"}, &IndexStorageParams{FillFactor: 100}, &Tablespace{N: "fast"})},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, `CREATE INDEX "users_id_brin" ON "public"."users" USING BRIN ("id") WITH (pages_per_range = 32, autosummarize = on)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE INDEX "users_tags" ON "public"."users" USING GIN ("tags") WITH (fastupdate = off)`, plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE INDEX "users_id_spgist" ON "public"."users" USING SPGiST ("id") WITH (fillfactor = 70) WHERE id > 0`, plan.Changes[2].Cmd)
+	require.Equal(t, `CREATE INDEX "users_id" ON "public"."users" ("id") WITH (fillfactor = 100) TABLESPACE "fast"`, plan.Changes[3].Cmd)
+}
+
+func TestPlanChanges_IndexRebuild(t *testing.T) {
+	var (
+		id    = schema.NewIntColumn("id", "int")
+		name  = schema.NewStringColumn("name", "text")
+		users = schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(id, name)
+		// Index parts were changed.
+		partsFrom = schema.NewIndex("users_name").AddColumns(name)
+		partsTo   = schema.NewUniqueIndex("users_name").AddColumns(name, id)
+		// Index storage parameters were changed.
+		paramsFrom = schema.NewIndex("users_id").AddColumns(id).AddAttrs(&IndexStorageParams{FillFactor: 70})
+		paramsTo   = schema.NewIndex("users_id").AddColumns(id)
+		changes    = []schema.Change{
+			&schema.ModifyTable{
+				T: users,
+				Changes: []schema.Change{
+					&schema.ModifyIndex{From: partsFrom, To: partsTo, Change: schema.ChangeUnique | schema.ChangeParts},
+					&schema.ModifyIndex{From: paramsFrom, To: paramsTo, Change: schema.ChangeAttr},
+				},
+			},
+		}
+	)
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db, WithIndexRebuild(IndexRebuildConcurrently))
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.False(t, plan.Transactional)
+	require.False(t, plan.Reversible)
+	require.Equal(t, []*migrate.Change{
+		{Cmd: `CREATE UNIQUE INDEX CONCURRENTLY "new_users_name" ON "public"."users" ("name", "id")`, Reverse: `DROP INDEX CONCURRENTLY "public"."new_users_name"`},
+		{Cmd: `DROP INDEX CONCURRENTLY "public"."users_name"`, Reverse: `CREATE INDEX CONCURRENTLY "users_name" ON "public"."users" ("name")`},
+		{Cmd: `ALTER INDEX "public"."new_users_name" RENAME TO "users_name"`, Reverse: `ALTER INDEX "public"."users_name" RENAME TO "new_users_name"`},
+		{Cmd: `ALTER INDEX "public"."users_id" RESET (fillfactor)`, Reverse: `ALTER INDEX "public"."users_id" SET (fillfactor = 70)`},
+		{Cmd: `REINDEX INDEX CONCURRENTLY "public"."users_id"`},
+	}, func() []*migrate.Change {
+		for _, c := range plan.Changes {
+			c.Source, c.Comment = nil, ""
+		}
+		return plan.Changes
+	}())
+
+	// REINDEX CONCURRENTLY is not supported before PostgreSQL 12.
+	db, mk, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("110000")
+	drv, err = Open(db, WithIndexRebuild(IndexRebuildConcurrently))
+	require.NoError(t, err)
+	plan, err = drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 6)
+	require.Equal(t, `CREATE INDEX CONCURRENTLY "new_users_id" ON "public"."users" ("id")`, plan.Changes[3].Cmd)
+
+	// Default mode.
+	db, mk, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	plan, err = drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.True(t, plan.Transactional)
+	require.Equal(t, `DROP INDEX "public"."users_name"`, plan.Changes[0].Cmd)
+}
+
+func TestPlanChanges_TableStorageParams(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		ff, th, off = int64(70), int64(1000), false
+		sf1, sf2    = 0.2, 0.01
+		from        = &TableStorageParams{FillFactor: &ff, AutovacuumVacuumScaleFactor: &sf1}
+		to          = &TableStorageParams{AutovacuumEnabled: &off, AutovacuumVacuumScaleFactor: &sf2, AutovacuumVacuumThreshold: &th}
+		users       = schema.NewTable("users").SetSchema(schema.New("public")).AddColumns(schema.NewIntColumn("id", "int")).AddAttrs(from)
+	)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.ModifyAttr{From: from, To: to},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" integer NOT NULL) WITH (fillfactor = 70, autovacuum_vacuum_scale_factor = 0.2)`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" SET (autovacuum_enabled = false, autovacuum_vacuum_threshold = 1000, autovacuum_vacuum_scale_factor = 0.01), RESET (fillfactor)`, plan.Changes[1].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" SET (fillfactor = 70, autovacuum_vacuum_scale_factor = 0.2), RESET (autovacuum_enabled, autovacuum_vacuum_threshold)`, plan.Changes[1].Reverse)
+}
+
+func TestPlanChanges_Inherits(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		public = schema.New("public")
+		parent = schema.NewTable("entities").SetSchema(public).AddColumns(schema.NewIntColumn("id", "int"))
+		child  = schema.NewTable("users").SetSchema(public).AddColumns(schema.NewIntColumn("id", "int")).AddAttrs(&Inherits{T: []*schema.Table{parent}})
+	)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: child},
+		&schema.AddTable{T: parent},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `CREATE TABLE "public"."entities" ("id" integer NOT NULL)`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TABLE "public"."users" ("id" integer NOT NULL) INHERITS ("public"."entities")`, plan.Changes[1].Cmd)
+
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.DropTable{T: parent},
+		&schema.DropTable{T: child},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `DROP TABLE "public"."users"`, plan.Changes[0].Cmd)
+	require.Equal(t, `DROP TABLE "public"."entities"`, plan.Changes[1].Cmd)
+
+	// Columns that are added to the parent are added to its children.
+	name := schema.NewStringColumn("name", "text")
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: parent, Changes: []schema.Change{&schema.AddColumn{C: name}}},
+		&schema.ModifyTable{T: child, Changes: []schema.Change{&schema.AddColumn{C: name}, &schema.AddColumn{C: schema.NewStringColumn("email", "text")}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, `ALTER TABLE "public"."entities" ADD COLUMN "name" text NOT NULL`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" ADD COLUMN "email" text NOT NULL`, plan.Changes[1].Cmd)
+
+	other := schema.NewTable("records").SetSchema(public)
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: child, Changes: []schema.Change{&schema.ModifyAttr{From: &Inherits{T: []*schema.Table{parent}}, To: &Inherits{T: []*schema.Table{other}}}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "public"."users" NO INHERIT "public"."entities", INHERIT "public"."records"`, plan.Changes[0].Cmd)
+	require.Equal(t, `ALTER TABLE "public"."users" NO INHERIT "public"."records", INHERIT "public"."entities"`, plan.Changes[0].Reverse)
+}
+
+func TestPlanChanges_CoalesceAlter(t *testing.T) {
+	var (
+		users   = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		pets    = schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "int"))
+		changes = []schema.Change{
+			&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: schema.NewIntColumn("a", "int")}}},
+			&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.AddColumn{C: schema.NewIntColumn("a", "int")}}},
+			&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropColumn{C: schema.NewIntColumn("b", "int")}}},
+		}
+	)
+	for b, cmds := range map[bool][]string{
+		false: {
+			`ALTER TABLE "users" ADD COLUMN "a" integer NOT NULL`,
+			`ALTER TABLE "pets" ADD COLUMN "a" integer NOT NULL`,
+			`ALTER TABLE "users" DROP COLUMN "b"`,
+		},
+		true: {
+			`ALTER TABLE "users" ADD COLUMN "a" integer NOT NULL, DROP COLUMN "b"`,
+			`ALTER TABLE "pets" ADD COLUMN "a" integer NOT NULL`,
+		},
+	} {
+		db, mk, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{mk}.version("130000")
+		drv, err := Open(db, WithCoalesceAlter(b))
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, len(cmds))
+		for i, c := range plan.Changes {
+			require.Equal(t, cmds[i], c.Cmd)
+		}
+	}
+}
+
+func TestPlanChanges_NotValidConstraints(t *testing.T) {
+	var (
+		users   = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+		pets    = schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("owner_id", "int"))
+		changes = []schema.Change{
+			&schema.ModifyTable{T: pets, Changes: []schema.Change{
+				&schema.AddForeignKey{F: schema.NewForeignKey("owner_fk").SetTable(pets).AddColumns(pets.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0])},
+				&schema.AddCheck{C: &schema.Check{Name: "id_positive", Expr: "id > 0"}},
+				&schema.AddCheck{C: &schema.Check{Expr: "owner_id > 0"}},
+				&schema.AddCheck{C: &schema.Check{Name: "id_even", Expr: "id % 2 = 0", Attrs: []schema.Attr{&NotValid{}}}},
+			}},
+		}
+	)
+	for b, cmds := range map[bool][]string{
+		false: {
+			`ALTER TABLE "pets" ADD CONSTRAINT "owner_fk" FOREIGN KEY ("owner_id") REFERENCES "users" ("id"), ADD CONSTRAINT "id_positive" CHECK (id > 0), ADD CHECK (owner_id > 0), ADD CONSTRAINT "id_even" CHECK (id % 2 = 0) NOT VALID`,
+		},
+		true: {
+			`ALTER TABLE "pets" ADD CONSTRAINT "owner_fk" FOREIGN KEY ("owner_id") REFERENCES "users" ("id") NOT VALID, ADD CONSTRAINT "id_positive" CHECK (id > 0) NOT VALID, ADD CHECK (owner_id > 0), ADD CONSTRAINT "id_even" CHECK (id % 2 = 0) NOT VALID`,
+			`ALTER TABLE "pets" VALIDATE CONSTRAINT "owner_fk"`,
+			`ALTER TABLE "pets" VALIDATE CONSTRAINT "id_positive"`,
+		},
+	} {
+		db, mk, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{mk}.version("130000")
+		drv, err := Open(db, WithNotValidConstraints(b))
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, len(cmds))
+		for i, c := range plan.Changes {
+			require.Equal(t, cmds[i], c.Cmd)
+		}
+	}
+}
+
+func TestPlanChanges_ColumnOrder(t *testing.T) {
+	var (
+		from = schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			AddColumns(
+				schema.NewIntColumn("id", "int").SetDefault(&schema.RawExpr{X: "nextval('users_id_seq'::regclass)"}),
+				schema.NewStringColumn("name", "text"),
+				schema.NewIntColumn("age", "int"),
+			)
+		to = schema.NewTable("users").
+			SetSchema(schema.New("public")).
+			AddColumns(
+				schema.NewIntColumn("id", "int").SetDefault(&schema.RawExpr{X: "nextval('users_id_seq'::regclass)"}),
+				schema.NewIntColumn("age", "int"),
+				schema.NewStringColumn("name", "text"),
+			)
+	)
+	from.SetPrimaryKey(schema.NewPrimaryKey(from.Columns[0]))
+	to.SetPrimaryKey(schema.NewPrimaryKey(to.Columns[0]))
+	from.AddIndexes(schema.NewIndex("users_name").AddColumns(from.Columns[1]))
+	to.AddIndexes(schema.NewIndex("users_name").AddColumns(to.Columns[2]))
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db, WithColumnOrder(true))
+	require.NoError(t, err)
+	changes, err := drv.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyColumn{From: from.Columns[1], To: to.Columns[2], Change: schema.ChangePosition}}, changes)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: to, Changes: changes}})
+	require.NoError(t, err)
+	require.False(t, plan.Reversible)
+	for i, cmd := range []string{
+		`CREATE TABLE "public"."new_users" ("id" integer NOT NULL DEFAULT nextval('users_id_seq'::regclass), "age" integer NOT NULL, "name" text NOT NULL, PRIMARY KEY ("id"))`,
+		`INSERT INTO "public"."new_users" ("id", "age", "name") SELECT "id", "age", "name" FROM "public"."users"`,
+		`ALTER SEQUENCE users_id_seq OWNED BY "public"."new_users"."id"`,
+		`DROP TABLE "public"."users"`,
+		`ALTER TABLE "public"."new_users" RENAME TO "users"`,
+		`ALTER TABLE "public"."users" RENAME CONSTRAINT "new_users_pkey" TO "users_pkey"`,
+		`CREATE INDEX "users_name" ON "public"."users" ("name")`,
+	} {
+		require.Equal(t, cmd, plan.Changes[i].Cmd)
+	}
+	require.Len(t, plan.Changes, 7)
+	require.Contains(t, plan.Changes[1].Comment, "full table rewrite")
+
+	// Tables that are referenced by other tables cannot be rewritten.
+	pets := schema.NewTable("pets").AddColumns(schema.NewIntColumn("owner_id", "int"))
+	pets.AddForeignKeys(schema.NewForeignKey("owner").AddColumns(pets.Columns[0]).SetRefTable(to).AddRefColumns(to.Columns[0]))
+	to.Schema.AddTables(to, pets)
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: to, Changes: changes}})
+	require.EqualError(t, err, `postgres: cannot reorder the columns of table "users", as it is referenced by foreign key "owner" of table "pets"`)
+}
+
+func TestPlanChanges_SearchPath(t *testing.T) {
+	var (
+		state = &schema.EnumType{T: "state", Values: []string{"on", "off"}}
+		users = schema.NewTable("users").
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewColumn("state").SetType(state))
+		pets = schema.NewTable("pets").
+			SetSchema(schema.New("other")).
+			AddColumns(schema.NewIntColumn("id", "int"), schema.NewColumn("state").SetType(state)).
+			AddIndexes(schema.NewIndex("pets_id").AddColumns(schema.NewIntColumn("id", "int")))
+	)
+	users.AddForeignKeys(schema.NewForeignKey("pet_fk").AddColumns(users.Columns[0]).SetRefTable(pets).AddRefColumns(pets.Columns[0]))
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db, WithSearchPath("app", "public"))
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t1 JOIN pg_namespace t2 ON t1.typnamespace = t2.oid WHERE t1.typname = $1 AND t1.typtype = 'e' AND t2.nspname = $2")).
+		WithArgs("state", "other").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	m.ExpectQuery(sqltest.Escape("SELECT * FROM pg_type t1 JOIN pg_namespace t2 ON t1.typnamespace = t2.oid WHERE t1.typname = $1 AND t1.typtype = 'e' AND t2.nspname = $2")).
+		WithArgs("state", "app").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: pets},
+		&schema.AddTable{T: users},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*migrate.Change{
+		{Cmd: `CREATE TYPE "other"."state" AS ENUM ('on', 'off')`, Reverse: `DROP TYPE "other"."state"`},
+		{Cmd: `CREATE TABLE "other"."pets" ("id" integer NOT NULL, "state" "other"."state" NOT NULL)`, Reverse: `DROP TABLE "other"."pets"`},
+		{Cmd: `CREATE INDEX "pets_id" ON "other"."pets" ("id")`, Reverse: `DROP INDEX "other"."pets_id"`},
+		{Cmd: `CREATE TYPE "app"."state" AS ENUM ('on', 'off')`, Reverse: `DROP TYPE "app"."state"`},
+		{Cmd: `CREATE TABLE "app"."users" ("id" integer NOT NULL, "state" "app"."state" NOT NULL, CONSTRAINT "pet_fk" FOREIGN KEY ("id") REFERENCES "other"."pets" ("id"))`, Reverse: `DROP TABLE "app"."users"`},
+	}, func() []*migrate.Change {
+		for _, c := range plan.Changes {
+			c.Source, c.Comment = nil, ""
+		}
+		return plan.Changes
+	}())
+}
+
+func TestPlanChanges_MinimalQuoting(t *testing.T) {
+	order := schema.NewTable("order").
+		SetSchema(schema.New("public")).
+		AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewIntColumn("userId", "int"),
+			schema.NewIntColumn("user", "int"),
+		)
+	order.AddIndexes(schema.NewIndex("order_user").AddColumns(order.Columns[2]))
+	changes := []schema.Change{
+		&schema.AddTable{T: order},
+		&schema.ModifyTable{T: order, Changes: []schema.Change{
+			&schema.DropIndex{I: order.Indexes[0]},
+			&schema.RenameColumn{From: schema.NewIntColumn(`user"id`, "int"), To: order.Columns[1]},
+		}},
+	}
+	for _, tt := range []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			want: []string{
+				`CREATE TABLE "public"."order" ("id" integer NOT NULL, "userId" integer NOT NULL, "user" integer NOT NULL)`,
+				`CREATE INDEX "order_user" ON "public"."order" ("user")`,
+				`ALTER TABLE "public"."order" RENAME COLUMN "user""id" TO "userId"`,
+				`DROP INDEX "public"."order_user"`,
+			},
+		},
+		{
+			opts: []Option{WithMinimalQuoting(true)},
+			want: []string{
+				`CREATE TABLE public."order" (id integer NOT NULL, "userId" integer NOT NULL, "user" integer NOT NULL)`,
+				`CREATE INDEX order_user ON public."order" ("user")`,
+				`ALTER TABLE public."order" RENAME COLUMN "user""id" TO "userId"`,
+				`DROP INDEX public.order_user`,
+			},
+		},
+	} {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.version("130000")
+		drv, err := Open(db, tt.opts...)
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, len(tt.want))
+		for i, c := range plan.Changes {
+			require.Equal(t, tt.want[i], c.Cmd)
+		}
+	}
+}
+
+func TestPlanChanges_Identity(t *testing.T) {
+	var (
+		bigint = &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}
+		serial = &schema.Column{Name: "id", Type: bigint, Default: &schema.RawExpr{X: "nextval('users_id_seq'::regclass)"}}
+		byDef  = &schema.Column{Name: "id", Type: bigint, Attrs: []schema.Attr{&Identity{Generation: "BY DEFAULT"}}}
+		always = &schema.Column{Name: "id", Type: bigint, Attrs: []schema.Attr{&Identity{Generation: "ALWAYS", Sequence: &Sequence{Start: 10}}}}
+		plain  = &schema.Column{Name: "id", Type: bigint}
+		setval = `SELECT setval(pg_get_serial_sequence('"users"', 'id'), COALESCE(MAX("id"), 0) + 1, false) FROM "users"`
+	)
+	tests := []struct {
+		from, to *schema.Column
+		change   schema.ChangeKind
+		want     []*migrate.Change
+	}{
+		{
+			from:   byDef,
+			to:     always,
+			change: schema.ChangeAttr,
+			want: []*migrate.Change{
+				{
+					Cmd:     `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED ALWAYS SET START WITH 10 SET INCREMENT BY 1 RESTART`,
+					Reverse: `ALTER TABLE "users" ALTER COLUMN "id" SET GENERATED BY DEFAULT SET START WITH 1 SET INCREMENT BY 1 RESTART`,
+				},
+			},
+		},
+		{
+			from:   plain,
+			to:     always,
+			change: schema.ChangeAttr,
+			want: []*migrate.Change{
+				{
+					Cmd:     `ALTER TABLE "users" ALTER COLUMN "id" ADD GENERATED ALWAYS AS IDENTITY (START WITH 10)`,
+					Reverse: `ALTER TABLE "users" ALTER COLUMN "id" DROP IDENTITY`,
+				},
+			},
+		},
+		{
+			from:   serial,
+			to:     byDef,
+			change: schema.ChangeAttr | schema.ChangeDefault,
+			want: []*migrate.Change{
+				{
+					Cmd:     `ALTER TABLE "users" ALTER COLUMN "id" DROP DEFAULT, ALTER COLUMN "id" ADD GENERATED BY DEFAULT AS IDENTITY`,
+					Reverse: `ALTER TABLE "users" ALTER COLUMN "id" DROP IDENTITY, ALTER COLUMN "id" SET DEFAULT nextval('users_id_seq'::regclass)`,
+				},
+				{Cmd: `DROP SEQUENCE IF EXISTS users_id_seq`},
+				{Cmd: setval},
+			},
+		},
+		{
+			from:   byDef,
+			to:     &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &SerialType{T: "bigserial"}}, Default: &schema.RawExpr{X: "nextval('users_id_seq'::regclass)"}},
+			change: schema.ChangeAttr | schema.ChangeDefault,
+			want: []*migrate.Change{
+				{
+					Cmd:     `ALTER TABLE "users" ALTER COLUMN "id" DROP IDENTITY`,
+					Reverse: `ALTER TABLE "users" ALTER COLUMN "id" ADD GENERATED BY DEFAULT AS IDENTITY`,
+				},
+				{Cmd: `CREATE SEQUENCE IF NOT EXISTS users_id_seq OWNED BY "users"."id"`},
+				{
+					Cmd:     `ALTER TABLE "users" ALTER COLUMN "id" SET DEFAULT nextval('users_id_seq'::regclass)`,
+					Reverse: `ALTER TABLE "users" ALTER COLUMN "id" DROP DEFAULT`,
+				},
+				{Cmd: setval},
+			},
+		},
+	}
+	for _, tt := range tests {
+		db, mk, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{mk}.version("130000")
+		drv, err := Open(db)
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+			&schema.ModifyTable{
+				T:       schema.NewTable("users").AddColumns(tt.to),
+				Changes: []schema.Change{&schema.ModifyColumn{From: tt.from, To: tt.to, Change: tt.change}},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, len(tt.want))
+		for i, c := range plan.Changes {
+			require.Equal(t, tt.want[i].Cmd, c.Cmd)
+			require.Equal(t, tt.want[i].Reverse, c.Reverse)
+		}
+	}
+}
+
+func TestPlanChanges_Naming(t *testing.T) {
+	users := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text"))
+	users.AddIndexes(schema.NewIndex("").AddColumns(users.Columns[1]))
+	pets := schema.NewTable("pets").
+		AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("owner_id", "int"))
+	changes := []schema.Change{
+		&schema.AddTable{T: users},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{
+			&schema.AddIndex{I: schema.NewIndex("pets_owner").AddColumns(pets.Columns[1])},
+			&schema.AddForeignKey{F: schema.NewForeignKey("").SetTable(pets).AddColumns(pets.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0])},
+			&schema.AddCheck{C: schema.NewCheck().SetExpr("owner_id > 0")},
+		}},
+	}
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db, WithNaming(naming.Rails{}))
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`CREATE TABLE "users" ("id" integer NOT NULL, "name" text NOT NULL)`,
+		`CREATE INDEX "index_users_on_name" ON "users" ("name")`,
+		`ALTER TABLE "pets" ADD CONSTRAINT "fk_rails_1ff5d16ccb" FOREIGN KEY ("owner_id") REFERENCES "users" ("id"), ADD CONSTRAINT "chk_rails_f8479e4691" CHECK (owner_id > 0)`,
+		`CREATE INDEX "pets_owner" ON "pets" ("owner_id")`,
+	}, func() []string {
+		var cmds []string
+		for _, c := range plan.Changes {
+			cmds = append(cmds, c.Cmd)
+		}
+		return cmds
+	}())
+}
+
+func TestPlanApply_ConvertError(t *testing.T) {
+	p := &planApply{}
+	err := p.ConvertError(`ALTER TABLE "t" ADD COLUMN "c" int`, &pq.Error{Code: "55P03", Message: "canceling statement due to lock timeout"})
+	require.True(t, schema.IsLockError(err))
+	require.EqualError(t, err, "pq: canceling statement due to lock timeout")
+
+	err = p.ConvertError(`ALTER TABLE "t" ADD CONSTRAINT "c_check" CHECK ("c" > 0)`, &pq.Error{Code: "23514", Constraint: "c_check", Message: `check constraint "c_check" of relation "t" is violated by some row`})
+	var cv *schema.ConstraintViolationError
+	require.True(t, errors.As(err, &cv))
+	require.Equal(t, "c_check", cv.Constraint)
+
+	stmt := `CREATE TABLE "t" ("c" intt)`
+	err = p.ConvertError(stmt, &pq.Error{Code: "42704", Position: "23", Message: `type "intt" does not exist`})
+	var se *schema.StmtError
+	require.True(t, errors.As(err, &se))
+	require.Equal(t, stmt, se.Stmt)
+	require.Equal(t, 23, se.Pos)
+	require.False(t, schema.IsLockError(err))
+	require.False(t, schema.IsConstraintViolationError(err))
+}
+
+func TestPlanApply_Savepoints(t *testing.T) {
+	ctx := migrate.WithSavepoints(context.Background(), migrate.SavepointAbort)
+	changes := []schema.Change{&schema.AddTable{T: schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))}}
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	// Savepoints cannot be used on connection pools.
+	require.EqualError(t, drv.ApplyChanges(ctx, changes), "sql/sqlx: savepoints require a driver that is opened on a single connection (e.g. *sql.Tx)")
+
+	m.ExpectBegin()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err = Open(tx)
+	require.NoError(t, err)
+	m.ExpectExec(sqltest.Escape("SAVEPOINT atlas_apply")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape(`CREATE TABLE "users" ("id" integer NOT NULL)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("RELEASE SAVEPOINT atlas_apply")).WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, drv.ApplyChanges(ctx, changes))
+	require.NoError(t, m.ExpectationsWereMet())
+
+	// Options that wrap the connection do not hide the transaction.
+	var logged []string
+	mock{m}.version("130000")
+	drv, err = Open(tx, WithStatementLog(func(_ context.Context, stmt string, _ []interface{}) {
+		logged = append(logged, stmt)
+	}))
+	require.NoError(t, err)
+	m.ExpectExec(sqltest.Escape("SAVEPOINT atlas_apply")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape(`CREATE TABLE "users" ("id" integer NOT NULL)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("RELEASE SAVEPOINT atlas_apply")).WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, drv.ApplyChanges(ctx, changes))
+	require.Equal(t, []string{"SAVEPOINT atlas_apply", `CREATE TABLE "users" ("id" integer NOT NULL)`, "RELEASE SAVEPOINT atlas_apply"}, logged[len(logged)-3:])
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestPlanChanges_Unsigned(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	newT := func() *schema.Table {
+		return schema.NewTable("users").
+			AddColumns(
+				&schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint", Unsigned: true}}},
+				&schema.Column{Name: "age", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "tinyint", Unsigned: true}}},
+				&schema.Column{Name: "rank", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "int"}}},
+			)
+	}
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: newT()}})
+	require.EqualError(t, err, `column "id": postgres: unsigned integer types are not supported: "bigint" (see ConvertUnsigned)`)
+
+	for mode, cmd := range map[UnsignedMode]string{
+		UnsignedWiden:      `CREATE TABLE "users" ("id" numeric(20) NOT NULL, "age" smallint NOT NULL, "rank" integer NOT NULL)`,
+		UnsignedCheck:      `CREATE TABLE "users" ("id" bigint NOT NULL, "age" smallint NOT NULL, "rank" integer NOT NULL, CONSTRAINT "users_id_unsigned" CHECK ("id" >= 0), CONSTRAINT "users_age_unsigned" CHECK ("age" >= 0))`,
+		UnsignedWidenCheck: `CREATE TABLE "users" ("id" numeric(20) NOT NULL, "age" smallint NOT NULL, "rank" integer NOT NULL, CONSTRAINT "users_id_unsigned" CHECK ("id" BETWEEN 0 AND 18446744073709551615), CONSTRAINT "users_age_unsigned" CHECK ("age" BETWEEN 0 AND 255))`,
+	} {
+		tt := newT()
+		require.NoError(t, ConvertUnsigned(tt, mode))
+		plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: tt}})
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 1)
+		require.Equal(t, cmd, plan.Changes[0].Cmd)
+	}
+	require.Error(t, ConvertUnsigned(newT(), 0))
+}
+
+func TestFormatStmt(t *testing.T) {
+	require.Equal(t, "ALTER TABLE \"t\"\n  ADD COLUMN \"a\" text DEFAULT $$a, b$$,\n  DROP COLUMN \"b\"", FormatStmt(`ALTER TABLE "t" ADD COLUMN "a" text DEFAULT $$a, b$$, DROP COLUMN "b"`))
+}
+
+func TestSplitStmts(t *testing.T) {
+	stmts, err := SplitStmts("CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;\nDROP TABLE \"t\";")
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Equal(t, "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql", stmts[0].Text)
+	require.Equal(t, `DROP TABLE "t"`, stmts[1].Text)
+	_, err = SplitStmts("SELECT $$a")
+	require.EqualError(t, err, "postgres: unterminated string at line 1, column 8")
+}
+
+func TestPlanChanges_ForeignKeyMatch(t *testing.T) {
+	users := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("tenant_id", "int"))
+	pets := schema.NewTable("pets").
+		AddColumns(schema.NewIntColumn("owner_id", "int"), schema.NewNullIntColumn("tenant_id", "int"))
+	from := schema.NewForeignKey("owner").SetTable(pets).AddColumns(pets.Columns...).SetRefTable(users).AddRefColumns(users.Columns...)
+	to := schema.NewForeignKey("owner").SetTable(pets).AddColumns(pets.Columns...).SetRefTable(users).AddRefColumns(users.Columns...).
+		SetMatch(schema.MatchFull).
+		SetOnDelete(schema.SetDefault)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{
+			&schema.ModifyForeignKey{From: from, To: to, Change: schema.ChangeDeleteAction | schema.ChangeMatch},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `ALTER TABLE "pets" DROP CONSTRAINT "owner", ADD CONSTRAINT "owner" FOREIGN KEY ("owner_id", "tenant_id") REFERENCES "users" ("id", "tenant_id") MATCH FULL ON DELETE SET DEFAULT`, plan.Changes[0].Cmd)
+}
+
+func TestPlanChanges_ForeignKeyRenamedColumn(t *testing.T) {
+	users := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("tenant", "int"))
+	pets := schema.NewTable("pets").
+		AddColumns(schema.NewIntColumn("owner_id", "int"), schema.NewIntColumn("tenant", "int"))
+	from := schema.NewForeignKey("owner").SetTable(pets).AddColumns(pets.Columns...).SetRefTable(users).AddRefColumns(users.Columns...)
+	to := schema.NewForeignKey("owner").SetTable(pets).AddColumns(schema.NewIntColumn("user_id", "int"), pets.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns...)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{
+			&schema.RenameColumn{From: pets.Columns[0], To: to.Columns[0]},
+			&schema.ModifyForeignKey{From: from, To: to, Change: schema.ChangeColumn},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	for i, c := range []struct{ cmd, reverse string }{
+		{
+			cmd:     `ALTER TABLE "pets" DROP CONSTRAINT "owner"`,
+			reverse: `ALTER TABLE "pets" ADD CONSTRAINT "owner" FOREIGN KEY ("owner_id", "tenant") REFERENCES "users" ("id", "tenant")`,
+		},
+		{
+			cmd:     `ALTER TABLE "pets" RENAME COLUMN "owner_id" TO "user_id"`,
+			reverse: `ALTER TABLE "pets" RENAME COLUMN "user_id" TO "owner_id"`,
+		},
+		{
+			cmd:     `ALTER TABLE "pets" ADD CONSTRAINT "owner" FOREIGN KEY ("user_id", "tenant") REFERENCES "users" ("id", "tenant")`,
+			reverse: `ALTER TABLE "pets" DROP CONSTRAINT "owner"`,
+		},
+	} {
+		require.Equal(t, c.cmd, plan.Changes[i].Cmd)
+		require.Equal(t, c.reverse, plan.Changes[i].Reverse)
+	}
+}
+
+func TestPlanChanges_Guards(t *testing.T) {
+	public := schema.New("public")
+	users := schema.NewTable("users").SetSchema(public).
+		AddColumns(schema.NewIntColumn("id", "int"))
+	pets := schema.NewTable("pets").SetSchema(public).
+		AddColumns(schema.NewIntColumn("owner_id", "int"), schema.NewIntColumn("age", "int"))
+	pets.AddIndexes(schema.NewIndex("pets_age").AddColumns(pets.Columns[1]))
+	fk := schema.NewForeignKey("owner").SetTable(pets).AddColumns(pets.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(migrate.WithGuards(context.Background()), "plan", []schema.Change{
+		&schema.AddSchema{S: schema.New("audit")},
+		&schema.DropTable{T: schema.NewTable("tmp").SetSchema(public)},
+		&schema.AddTable{T: users},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{
+			&schema.AddColumn{C: schema.NewNullStringColumn("name", "text")},
+			&schema.DropColumn{C: schema.NewIntColumn("weight", "int")},
+			&schema.DropIndex{I: pets.Indexes[0]},
+			&schema.AddForeignKey{F: fk},
+			&schema.AddCheck{C: schema.NewCheck().SetName("age_check").SetExpr("age > 0")},
+			&schema.AddCheck{C: schema.NewCheck().SetName("name_check").SetExpr("name <> $$ $atlas$ $$")},
+		}},
+	})
+	require.NoError(t, err)
+	var cmds []string
+	for _, c := range plan.Changes {
+		cmds = append(cmds, c.Cmd)
+	}
+	require.Equal(t, []string{
+		`CREATE SCHEMA IF NOT EXISTS "audit"`,
+		`DROP TABLE IF EXISTS "public"."tmp"`,
+		`CREATE TABLE IF NOT EXISTS "public"."users" ("id" integer NOT NULL)`,
+		`DROP INDEX IF EXISTS "public"."pets_age"`,
+		`ALTER TABLE "public"."pets" ADD COLUMN IF NOT EXISTS "name" text NULL, DROP COLUMN IF EXISTS "weight"`,
+		`DO $atlas$ BEGIN IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conrelid = to_regclass('"public"."pets"') AND conname = 'owner') THEN ALTER TABLE "public"."pets" ADD CONSTRAINT "owner" FOREIGN KEY ("owner_id") REFERENCES "public"."users" ("id"); END IF; END $atlas$`,
+		`DO $atlas$ BEGIN IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conrelid = to_regclass('"public"."pets"') AND conname = 'age_check') THEN ALTER TABLE "public"."pets" ADD CONSTRAINT "age_check" CHECK (age > 0); END IF; END $atlas$`,
+		`DO $atlas_$ BEGIN IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conrelid = to_regclass('"public"."pets"') AND conname = 'name_check') THEN ALTER TABLE "public"."pets" ADD CONSTRAINT "name_check" CHECK (name <> $$ $atlas$ $$); END IF; END $atlas_$`,
+	}, cmds)
+	require.Equal(t, `CREATE INDEX IF NOT EXISTS "pets_age" ON "public"."pets" ("age")`, plan.Changes[3].Reverse)
+	require.Equal(t, `ALTER TABLE "public"."pets" DROP CONSTRAINT "owner"`, plan.Changes[5].Reverse)
+}
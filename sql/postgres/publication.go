@@ -0,0 +1,332 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A Publication describes a logical replication publication (i.e. CREATE PUBLICATION).
+	// Publications are database-level objects, and they can be attached to the attributes
+	// of the realm. See Driver.InspectPublications and PublicationDiff.
+	Publication struct {
+		schema.Attr
+		Name string
+		// AllTables indicates if the publication publishes all tables of the database.
+		AllTables bool
+		// Tables that are published, if AllTables is false.
+		Tables []*PublicationTable
+		// Operations that are published (i.e. the "publish" parameter). For example,
+		// "insert" or "update". An empty list means all operations, the default.
+		Operations []string
+	}
+
+	// PublicationTable describes a table that is published by a publication.
+	PublicationTable struct {
+		Schema, Name string
+		// Where holds the row filter of the table, if exists (PostgreSQL 15 and above).
+		Where string
+	}
+
+	// AddPublication describes a publication creation change.
+	AddPublication struct {
+		schema.Change
+		P *Publication
+	}
+
+	// DropPublication describes a publication removal change.
+	DropPublication struct {
+		schema.Change
+		P *Publication
+	}
+
+	// ModifyPublication describes a change of the tables or the operations of a publication.
+	ModifyPublication struct {
+		schema.Change
+		From, To *Publication
+	}
+)
+
+// publicationOps holds the operations that are published by default.
+var publicationOps = []string{"insert", "update", "delete", "truncate"}
+
+// Queries for inspecting the publications of the database. Row filters
+// (i.e. pg_publication_rel.prqual) were added in PostgreSQL 15.
+const (
+	publicationsInspectQuery = `
+SELECT
+	p.pubname,
+	p.puballtables,
+	p.pubinsert,
+	p.pubupdate,
+	p.pubdelete,
+	p.pubtruncate,
+	n.nspname,
+	c.relname,
+	%s AS row_filter
+FROM
+	pg_catalog.pg_publication AS p
+	LEFT JOIN pg_catalog.pg_publication_rel AS pr ON pr.prpubid = p.oid
+	LEFT JOIN pg_catalog.pg_class AS c ON c.oid = pr.prrelid
+	LEFT JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+ORDER BY
+	p.pubname, n.nspname, c.relname
+`
+	publicationsFilter = "pg_catalog.pg_get_expr(pr.prqual, pr.prrelid)"
+)
+
+// InspectPublications returns the publications of the database.
+func (d *Driver) InspectPublications(ctx context.Context) ([]*Publication, error) {
+	if d.redshift {
+		return nil, fmt.Errorf("postgres: publications are not supported by Redshift")
+	}
+	filter := "NULL"
+	if d.supportsRowFilters() {
+		filter = publicationsFilter
+	}
+	rows, err := d.QueryContext(ctx, fmt.Sprintf(publicationsInspectQuery, filter))
+	if err != nil {
+		return nil, fmt.Errorf("postgres: querying publications: %w", err)
+	}
+	defer rows.Close()
+	var pubs []*Publication
+	for rows.Next() {
+		var (
+			name                      string
+			all, ins, upd, del, trunc bool
+			ns, table, where          sql.NullString
+		)
+		if err := rows.Scan(&name, &all, &ins, &upd, &del, &trunc, &ns, &table, &where); err != nil {
+			return nil, fmt.Errorf("postgres: scanning publications: %w", err)
+		}
+		if len(pubs) == 0 || pubs[len(pubs)-1].Name != name {
+			p := &Publication{Name: name, AllTables: all}
+			if !ins || !upd || !del || !trunc {
+				for i, ok := range []bool{ins, upd, del, trunc} {
+					if ok {
+						p.Operations = append(p.Operations, publicationOps[i])
+					}
+				}
+			}
+			pubs = append(pubs, p)
+		}
+		if table.Valid {
+			p := pubs[len(pubs)-1]
+			p.Tables = append(p.Tables, &PublicationTable{Schema: ns.String, Name: table.String, Where: where.String})
+		}
+	}
+	return pubs, rows.Close()
+}
+
+// PublicationDiff returns the changes for migrating the publications
+// of a database from one state to the other.
+func PublicationDiff(from, to []*Publication) []schema.Change {
+	var changes []schema.Change
+	for _, p1 := range from {
+		p2 := publication(to, p1.Name)
+		switch {
+		case p2 == nil:
+			changes = append(changes, &DropPublication{P: p1})
+		// Publications cannot be changed from (or to) FOR ALL TABLES.
+		case p1.AllTables != p2.AllTables:
+			changes = append(changes, &DropPublication{P: p1}, &AddPublication{P: p2})
+		case p1.tables() != p2.tables() || p1.operations() != p2.operations():
+			changes = append(changes, &ModifyPublication{From: p1, To: p2})
+		}
+	}
+	for _, p2 := range to {
+		if publication(from, p2.Name) == nil {
+			changes = append(changes, &AddPublication{P: p2})
+		}
+	}
+	return changes
+}
+
+// publication returns the publication with the given name, or nil.
+func publication(pubs []*Publication, name string) *Publication {
+	for _, p := range pubs {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// tables returns a canonical representation of the published tables.
+func (p *Publication) tables() string {
+	ts := make([]string, len(p.Tables))
+	for i, t := range p.Tables {
+		ts[i] = fmt.Sprintf("%s.%s %s", t.Schema, t.Name, strings.TrimSpace(t.Where))
+	}
+	sort.Strings(ts)
+	return strings.Join(ts, ",")
+}
+
+// operations returns the "publish" parameter of the publication.
+func (p *Publication) operations() string {
+	ops := p.Operations
+	if len(ops) == 0 {
+		ops = publicationOps
+	}
+	// Operations are written in their canonical order.
+	var sorted []string
+	for _, o := range publicationOps {
+		for _, o2 := range ops {
+			if strings.EqualFold(o, strings.TrimSpace(o2)) {
+				sorted = append(sorted, o)
+				break
+			}
+		}
+	}
+	return strings.Join(sorted, ", ")
+}
+
+// splitPublications splits the publication changes from the given changes. Dropped
+// publications are planned before all other changes, and created or modified ones
+// after them, as they may publish tables that are created by the changes.
+func splitPublications(changes []schema.Change) (pre, rest, post []schema.Change) {
+	for _, c := range changes {
+		switch c.(type) {
+		case *DropPublication:
+			pre = append(pre, c)
+		case *AddPublication, *ModifyPublication:
+			post = append(post, c)
+		default:
+			rest = append(rest, c)
+		}
+	}
+	return pre, rest, post
+}
+
+// publication builds the statements of a publication change.
+func (s *state) publication(c schema.Change) error {
+	if s.redshift {
+		return fmt.Errorf("publications are not supported by Redshift")
+	}
+	switch c := c.(type) {
+	case *AddPublication:
+		cmd, err := s.createPublication(c.P)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     cmd,
+			Source:  c,
+			Comment: fmt.Sprintf("create publication %q", c.P.Name),
+			Reverse: s.build("DROP PUBLICATION").Ident(c.P.Name).String(),
+		})
+	case *DropPublication:
+		reverse, err := s.createPublication(c.P)
+		if err != nil {
+			return err
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.build("DROP PUBLICATION").Ident(c.P.Name).String(),
+			Source:  c,
+			Comment: fmt.Sprintf("drop publication %q", c.P.Name),
+			Reverse: reverse,
+		})
+	case *ModifyPublication:
+		if c.From.AllTables != c.To.AllTables {
+			return fmt.Errorf("publication %q cannot be changed from (or to) FOR ALL TABLES", c.To.Name)
+		}
+		if !c.To.AllTables && c.From.tables() != c.To.tables() {
+			cmd, err := s.alterPublicationTables(c.From, c.To)
+			if err != nil {
+				return err
+			}
+			reverse, err := s.alterPublicationTables(c.To, c.From)
+			if err != nil {
+				return err
+			}
+			s.append(&migrate.Change{
+				Cmd:     cmd,
+				Source:  c,
+				Comment: fmt.Sprintf("set the tables of publication %q", c.To.Name),
+				Reverse: reverse,
+			})
+		}
+		if from, to := c.From.operations(), c.To.operations(); from != to {
+			s.append(&migrate.Change{
+				Cmd:     s.build("ALTER PUBLICATION").Ident(c.To.Name).P("SET", fmt.Sprintf("(publish = %s)", quote(to))).String(),
+				Source:  c,
+				Comment: fmt.Sprintf("set the operations of publication %q", c.To.Name),
+				Reverse: s.build("ALTER PUBLICATION").Ident(c.To.Name).P("SET", fmt.Sprintf("(publish = %s)", quote(from))).String(),
+			})
+		}
+	}
+	return nil
+}
+
+// createPublication returns the CREATE PUBLICATION statement of the publication.
+func (s *state) createPublication(p *Publication) (string, error) {
+	b := s.build("CREATE PUBLICATION").Ident(p.Name)
+	switch {
+	case p.AllTables:
+		b.P("FOR ALL TABLES")
+	case len(p.Tables) > 0:
+		b.P("FOR TABLE")
+		if err := s.publicationTables(b, p); err != nil {
+			return "", err
+		}
+	}
+	if ops := p.operations(); ops != strings.Join(publicationOps, ", ") {
+		b.P("WITH", fmt.Sprintf("(publish = %s)", quote(ops)))
+	}
+	return b.String(), nil
+}
+
+// alterPublicationTables returns the ALTER PUBLICATION statement that sets the tables
+// of the publication. Publications without tables are altered by dropping their tables,
+// as SET TABLE requires at least one table.
+func (s *state) alterPublicationTables(from, to *Publication) (string, error) {
+	b := s.build("ALTER PUBLICATION").Ident(to.Name)
+	if len(to.Tables) == 0 {
+		b.P("DROP TABLE").MapComma(from.Tables, func(i int, b *sqlx.Builder) {
+			b.QualifiedIdent(from.Tables[i].Schema, from.Tables[i].Name)
+		})
+		return b.String(), nil
+	}
+	b.P("SET TABLE")
+	if err := s.publicationTables(b, to); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// publicationTables writes the published tables, and their row filters, to the builder.
+func (s *state) publicationTables(b *sqlx.Builder, p *Publication) error {
+	for _, t := range p.Tables {
+		if t.Where != "" && !s.supportsRowFilters() {
+			return fmt.Errorf("row filter of table %q in publication %q requires PostgreSQL 15 or above", t.Name, p.Name)
+		}
+	}
+	b.MapComma(p.Tables, func(i int, b *sqlx.Builder) {
+		t := p.Tables[i]
+		b.QualifiedIdent(t.Schema, t.Name)
+		if w := strings.TrimSpace(t.Where); w != "" {
+			if !strings.HasPrefix(w, "(") || !strings.HasSuffix(w, ")") {
+				w = "(" + w + ")"
+			}
+			b.P("WHERE", w)
+		}
+	})
+	return nil
+}
+
+// supportsRowFilters reports if the server supports row filters
+// in publications (PostgreSQL 15 and above).
+func (c *conn) supportsRowFilters() bool {
+	return !c.redshift && c.version >= "15"
+}
@@ -0,0 +1,126 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectPublications(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("150000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(publicationsInspectQuery, publicationsFilter))).
+		WillReturnRows(sqltest.Rows(`
+ pubname | puballtables | pubinsert | pubupdate | pubdelete | pubtruncate | nspname | relname | row_filter
+---------+--------------+-----------+-----------+-----------+-------------+---------+---------+-------------------
+ all     | t            | t         | t         | t         | t           |         |         |
+ app     | f            | t         | t         | t         | t           | public  | pets    |
+ app     | f            | t         | t         | t         | t           | public  | users   | (active = true)
+ inserts | f            | t         | f         | f         | f           |         |         |
+`))
+	pubs, err := drv.InspectPublications(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []*Publication{
+		{Name: "all", AllTables: true},
+		{Name: "app", Tables: []*PublicationTable{{Schema: "public", Name: "pets"}, {Schema: "public", Name: "users", Where: "(active = true)"}}},
+		{Name: "inserts", Operations: []string{"insert"}},
+	}, pubs)
+
+	// Row filters are not inspected before PostgreSQL 15.
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(publicationsInspectQuery, "NULL"))).
+		WillReturnRows(sqlmock.NewRows([]string{"pubname", "puballtables", "pubinsert", "pubupdate", "pubdelete", "pubtruncate", "nspname", "relname", "row_filter"}))
+	pubs, err = drv.InspectPublications(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, pubs)
+}
+
+func TestPublicationDiff(t *testing.T) {
+	var (
+		from = []*Publication{
+			{Name: "app", Tables: []*PublicationTable{{Schema: "public", Name: "users"}}},
+			{Name: "all", AllTables: true},
+			{Name: "logs", Tables: []*PublicationTable{{Schema: "public", Name: "logs"}}},
+			{Name: "ops", Operations: []string{"insert", "update", "delete", "truncate"}},
+		}
+		to = []*Publication{
+			{Name: "app", Tables: []*PublicationTable{{Schema: "public", Name: "users", Where: "active"}, {Schema: "public", Name: "pets"}}},
+			{Name: "all", Tables: []*PublicationTable{{Schema: "public", Name: "users"}}},
+			{Name: "ops"},
+			{Name: "cdc", AllTables: true, Operations: []string{"update", "insert"}},
+		}
+	)
+	require.Equal(t, []schema.Change{
+		&ModifyPublication{From: from[0], To: to[0]},
+		&DropPublication{P: from[1]},
+		&AddPublication{P: to[1]},
+		&DropPublication{P: from[2]},
+		&AddPublication{P: to[3]},
+	}, PublicationDiff(from, to))
+	require.Empty(t, PublicationDiff(to, to))
+}
+
+func TestPlanChanges_Publications(t *testing.T) {
+	var (
+		public = schema.New("public")
+		pets   = schema.NewTable("pets").SetSchema(public).AddColumns(schema.NewIntColumn("id", "int"))
+		app    = &Publication{Name: "app", Tables: []*PublicationTable{{Schema: "public", Name: "users"}}}
+		app2   = &Publication{Name: "app", Tables: []*PublicationTable{{Schema: "public", Name: "users", Where: "active"}, {Schema: "public", Name: "pets"}}, Operations: []string{"insert", "update"}}
+	)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("150000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&AddPublication{P: &Publication{Name: "cdc", AllTables: true, Operations: []string{"insert"}}},
+		&schema.AddTable{T: pets},
+		&ModifyPublication{From: app, To: app2},
+		&DropPublication{P: &Publication{Name: "logs"}},
+	})
+	require.NoError(t, err)
+	require.True(t, plan.Reversible)
+	for i, c := range []struct{ cmd, reverse string }{
+		{`DROP PUBLICATION "logs"`, `CREATE PUBLICATION "logs"`},
+		{`CREATE TABLE "public"."pets" ("id" integer NOT NULL)`, `DROP TABLE "public"."pets"`},
+		{`CREATE PUBLICATION "cdc" FOR ALL TABLES WITH (publish = 'insert')`, `DROP PUBLICATION "cdc"`},
+		{`ALTER PUBLICATION "app" SET TABLE "public"."users" WHERE (active), "public"."pets"`, `ALTER PUBLICATION "app" SET TABLE "public"."users"`},
+		{`ALTER PUBLICATION "app" SET (publish = 'insert, update')`, `ALTER PUBLICATION "app" SET (publish = 'insert, update, delete, truncate')`},
+	} {
+		require.Equal(t, c.cmd, plan.Changes[i].Cmd)
+		require.Equal(t, c.reverse, plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 5)
+
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&ModifyPublication{From: app, To: &Publication{Name: "app"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `ALTER PUBLICATION "app" DROP TABLE "public"."users"`, plan.Changes[0].Cmd)
+
+	// Row filters require PostgreSQL 15.
+	db, m, err = sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err = Open(db)
+	require.NoError(t, err)
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&AddPublication{P: app2}})
+	require.EqualError(t, err, `row filter of table "users" in publication "app" requires PostgreSQL 15 or above`)
+}
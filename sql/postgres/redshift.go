@@ -0,0 +1,682 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// Amazon Redshift is based on PostgreSQL 8.0.2, and it is supported by this driver
+// as a flavor of PostgreSQL. Redshift tables do not have indexes, and they are
+// distributed and sorted by the DISTSTYLE, DISTKEY and SORTKEY table attributes.
+// Its catalog lacks most of the functions that are used by the PostgreSQL queries
+// (e.g. to_regclass), and its ALTER TABLE command supports one action per statement.
+// https://docs.aws.amazon.com/redshift/latest/dg/c_redshift-and-postgres-sql.html
+
+type (
+	// DistStyle describes the distribution style of a Redshift table,
+	// and its distribution key column (DISTKEY) in case of KEY style.
+	// https://docs.aws.amazon.com/redshift/latest/dg/c_choosing_dist_sort.html
+	DistStyle struct {
+		schema.Attr
+		S   string // AUTO, EVEN, KEY or ALL.
+		Key string // Column name, for KEY style.
+	}
+
+	// SortKey describes the sort key of a Redshift table.
+	// https://docs.aws.amazon.com/redshift/latest/dg/t_Sorting_data.html
+	SortKey struct {
+		schema.Attr
+		Columns     []string
+		Interleaved bool
+	}
+)
+
+// List of Redshift distribution styles.
+const (
+	DistAuto = "AUTO"
+	DistEven = "EVEN"
+	DistKey  = "KEY"
+	DistAll  = "ALL"
+)
+
+// Redshift returns true if the driver is connected to an Amazon Redshift cluster.
+func (d *Driver) Redshift() bool {
+	return d.redshift
+}
+
+// redshiftVersion reports if the database that is connected to the
+// given connection is Redshift, using the version() function.
+func redshiftVersion(ctx context.Context, db schema.ExecQuerier) (bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version()")
+	if err != nil {
+		return false, fmt.Errorf("postgres: querying version: %w", err)
+	}
+	var v string
+	if err := sqlx.ScanOne(rows, &v); err != nil {
+		return false, fmt.Errorf("postgres: scanning version: %w", err)
+	}
+	return strings.Contains(v, "Redshift"), nil
+}
+
+// inspectRedshiftTable is the Redshift version of inspectTable.
+func (i *inspect) inspectRedshiftTable(ctx context.Context, name string, opts *schema.InspectTableOptions, top *schema.Schema) (*schema.Table, error) {
+	t, err := i.redshiftTable(ctx, name, opts)
+	if err != nil {
+		return nil, err
+	}
+	if top != nil {
+		t.Schema = top
+	}
+	if err := i.columns(ctx, t); err != nil {
+		return nil, err
+	}
+	for _, c := range t.Columns {
+		redshiftIdentity(c)
+	}
+	if err := i.keys(ctx, t); err != nil {
+		return nil, err
+	}
+	if err := i.redshiftConstraints(ctx, t); err != nil {
+		return nil, err
+	}
+	if err := i.fks(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// redshiftTable returns the table from the database, or a NotExistError if the table was not found.
+func (i *inspect) redshiftTable(ctx context.Context, name string, opts *schema.InspectTableOptions) (*schema.Table, error) {
+	var (
+		args  = []interface{}{name}
+		query = redshiftTableQuery
+	)
+	switch {
+	case opts != nil && opts.Schema != "":
+		query = redshiftTableSchemaQuery
+		args = append(args, opts.Schema)
+	case len(i.searchPath) > 0:
+		query = redshiftTableSchemaQuery
+		args = append(args, i.searchPath[0])
+	}
+	var (
+		tSchema, comment sql.NullString
+		style            sql.NullInt64
+		rows, err        = i.QueryContext(ctx, query, args...)
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := sqlx.ScanOne(rows, &tSchema, &comment, &style); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &schema.NotExistError{
+				Err: fmt.Errorf("postgres: table %q was not found", name),
+			}
+		}
+		return nil, err
+	}
+	t := &schema.Table{Name: name, Schema: &schema.Schema{Name: tSchema.String}}
+	if sqlx.ValidString(comment) {
+		t.Attrs = append(t.Attrs, &schema.Comment{
+			Text: comment.String,
+		})
+	}
+	// The distribution key is filled when the
+	// column attributes are inspected (see keys).
+	if style.Valid {
+		t.Attrs = append(t.Attrs, &DistStyle{S: distStyle(style.Int64)})
+	}
+	return t, nil
+}
+
+// distStyle returns the distribution style of the given pg_class.reldiststyle
+// value. The automatic styles (e.g. AUTO(KEY)) are all reported as AUTO.
+func distStyle(v int64) string {
+	switch v {
+	case 0:
+		return DistEven
+	case 1:
+		return DistKey
+	case 8:
+		return DistAll
+	default:
+		return DistAuto
+	}
+}
+
+// keys queries and sets the distribution and the sort key columns of the given table.
+func (i *inspect) keys(ctx context.Context, t *schema.Table) error {
+	rows, err := i.QueryContext(ctx, redshiftKeysQuery, t.Schema.Name, t.Name)
+	if err != nil {
+		return fmt.Errorf("postgres: querying %q sort keys: %w", t.Name, err)
+	}
+	defer rows.Close()
+	var sk SortKey
+	for rows.Next() {
+		var (
+			name   string
+			isDist bool
+			ord    int64
+		)
+		if err := rows.Scan(&name, &isDist, &ord); err != nil {
+			return fmt.Errorf("postgres: scanning sort keys of %q: %w", t.Name, err)
+		}
+		if isDist {
+			for _, a := range t.Attrs {
+				if d, ok := a.(*DistStyle); ok {
+					d.Key = name
+				}
+			}
+		}
+		// Columns of interleaved sort keys are
+		// marked with negative positions.
+		if ord != 0 {
+			sk.Columns = append(sk.Columns, name)
+			sk.Interleaved = ord < 0
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(sk.Columns) > 0 {
+		t.Attrs = append(t.Attrs, &sk)
+	}
+	return nil
+}
+
+// redshiftConstraints queries and appends the PRIMARY KEY and UNIQUE constraints of the
+// given table. Redshift does not support indexes, and these constraints are informational
+// only (i.e. not enforced). They are inspected as indexes, similar to PostgreSQL.
+func (i *inspect) redshiftConstraints(ctx context.Context, t *schema.Table) error {
+	rows, err := i.QueryContext(ctx, redshiftConstraintsQuery, t.Schema.Name, t.Name)
+	if err != nil {
+		return fmt.Errorf("postgres: querying %q constraints: %w", t.Name, err)
+	}
+	defer rows.Close()
+	names := make(map[string]*schema.Index)
+	for rows.Next() {
+		var name, contype, column string
+		if err := rows.Scan(&name, &contype, &column); err != nil {
+			return fmt.Errorf("postgres: scanning constraints for table %q: %w", t.Name, err)
+		}
+		idx, ok := names[name]
+		if !ok {
+			idx = &schema.Index{
+				Name:   name,
+				Unique: true,
+				Table:  t,
+				Attrs:  []schema.Attr{&ConType{T: contype}},
+			}
+			names[name] = idx
+			if contype == "p" {
+				t.PrimaryKey = idx
+			} else {
+				t.Indexes = append(t.Indexes, idx)
+			}
+		}
+		c, ok := t.Column(column)
+		if !ok {
+			return fmt.Errorf("postgres: column %q was not found for constraint %q", column, name)
+		}
+		c.Indexes = append(c.Indexes, idx)
+		idx.Parts = append(idx.Parts, &schema.IndexPart{SeqNo: len(idx.Parts) + 1, C: c})
+	}
+	return rows.Err()
+}
+
+// reIdentity matches the DEFAULT expression that Redshift reports for identity columns.
+// For example, "identity"(100473, 0, '1,1'::text) for IDENTITY(1,1) columns, and
+// "identity"(100473, 0, ('1,1'::character varying)::text) for columns that were
+// defined as GENERATED BY DEFAULT AS IDENTITY(1,1).
+var reIdentity = regexp.MustCompile(`^"identity"\(\d+,\s*\d+,\s*(\(?)'(-?\d+),(-?\d+)'::`)
+
+// redshiftIdentity converts the identity DEFAULT expression
+// of the given column (if it has one) to an Identity attribute.
+func redshiftIdentity(c *schema.Column) {
+	x, ok := c.Default.(*schema.RawExpr)
+	if !ok {
+		return
+	}
+	matches := reIdentity.FindStringSubmatch(x.X)
+	if len(matches) != 4 {
+		return
+	}
+	id := &Identity{Generation: "ALWAYS", Sequence: &Sequence{}}
+	if matches[1] != "" {
+		id.Generation = "BY DEFAULT"
+	}
+	id.Sequence.Start, _ = strconv.ParseInt(matches[2], 10, 64)
+	id.Sequence.Increment, _ = strconv.ParseInt(matches[3], 10, 64)
+	c.Default = nil
+	c.Attrs = append(c.Attrs, id)
+}
+
+// redshiftAttrDiff returns the changes of the Redshift table attributes.
+func redshiftAttrDiff(from, to *schema.Table) []schema.Change {
+	var changes []schema.Change
+	if d1, d2 := dist(from.Attrs), dist(to.Attrs); d1.S != d2.S || d1.Key != d2.Key {
+		changes = append(changes, &schema.ModifyAttr{From: d1, To: d2})
+	}
+	var k1, k2 SortKey
+	switch ok1, ok2 := sqlx.Has(from.Attrs, &k1), sqlx.Has(to.Attrs, &k2); {
+	case ok1 && !ok2:
+		changes = append(changes, &schema.DropAttr{A: &k1})
+	case !ok1 && ok2:
+		changes = append(changes, &schema.AddAttr{A: &k2})
+	case ok1 && ok2 && (k1.Interleaved != k2.Interleaved || strings.Join(k1.Columns, ",") != strings.Join(k2.Columns, ",")):
+		changes = append(changes, &schema.ModifyAttr{From: &k1, To: &k2})
+	}
+	return changes
+}
+
+// dist returns the distribution style of a table, or
+// the default style (AUTO) if it was not set explicitly.
+func dist(attrs []schema.Attr) *DistStyle {
+	d := &DistStyle{S: DistAuto}
+	if sqlx.Has(attrs, d) {
+		d.S = strings.ToUpper(d.S)
+	}
+	if d.S == "" {
+		d.S = DistAuto
+	}
+	// A DISTKEY without a style implies KEY distribution.
+	if d.Key != "" && d.S == DistAuto {
+		d.S = DistKey
+	}
+	return d
+}
+
+// addRedshiftTable builds the CREATE TABLE statement of Redshift tables. PRIMARY KEY
+// and UNIQUE constraints are defined inline, as Redshift does not support indexes.
+func (s *state) addRedshiftTable(add *schema.AddTable) error {
+	for _, c := range add.T.Columns {
+		if _, ok := c.Type.Type.(*schema.EnumType); ok {
+			return fmt.Errorf("redshift: enum type of column %q is not supported", c.Name)
+		}
+	}
+	for _, idx := range add.T.Indexes {
+		if !idx.Unique {
+			return fmt.Errorf("redshift: index %q of table %q is not supported, use SORTKEY instead", idx.Name, add.T.Name)
+		}
+	}
+	b := s.build("CREATE TABLE").Table(add.T)
+	if sqlx.Has(add.Extra, &schema.IfNotExists{}) {
+		b.P("IF NOT EXISTS")
+	}
+	var errs []string
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(add.T.Columns, func(i int, b *sqlx.Builder) {
+			s.column(b, add.T, add.T.Columns[i])
+		})
+		if pk := add.T.PrimaryKey; pk != nil {
+			b.Comma().P("PRIMARY KEY")
+			s.indexParts(b, pk.Parts)
+		}
+		for _, idx := range add.T.Indexes {
+			b.Comma()
+			if idx.Name != "" {
+				b.P("CONSTRAINT").Ident(idx.Name)
+			}
+			b.P("UNIQUE")
+			s.indexParts(b, idx.Parts)
+		}
+		if len(add.T.ForeignKeys) > 0 {
+			b.Comma()
+			s.fks(b, add.T.ForeignKeys...)
+		}
+		for _, attr := range add.T.Attrs {
+			if c, ok := attr.(*schema.Check); ok {
+				errs = append(errs, fmt.Sprintf("CHECK constraint %q is not supported", c.Name))
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("redshift: create table %q: %s", add.T.Name, strings.Join(errs, ", "))
+	}
+	if sqlx.Has(add.T.Attrs, &DistStyle{}) {
+		d := dist(add.T.Attrs)
+		b.P("DISTSTYLE", d.S)
+		if d.Key != "" {
+			b.P("DISTKEY").Wrap(func(b *sqlx.Builder) {
+				b.Ident(d.Key)
+			})
+		}
+	}
+	if k := (SortKey{}); sqlx.Has(add.T.Attrs, &k) {
+		sortKey(b, &k)
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  add,
+		Comment: fmt.Sprintf("create %q table", add.T.Name),
+		Reverse: s.build("DROP TABLE").Table(add.T).String(),
+	})
+	s.addComments(add.T)
+	return nil
+}
+
+// modifyRedshiftTable builds the statements that bring a Redshift table into its
+// modified state. Redshift does not allow combining multiple actions in one ALTER
+// TABLE statement, and it supports only a subset of the PostgreSQL column changes.
+func (s *state) modifyRedshiftTable(modify *schema.ModifyTable) error {
+	var (
+		t        = modify.T
+		comments []*migrate.Change
+		alter    = func(change schema.Change, comment string, cmd, reverse *sqlx.Builder) {
+			c := &migrate.Change{Cmd: cmd.String(), Source: change, Comment: comment}
+			if reverse != nil {
+				c.Reverse = reverse.String()
+			}
+			s.append(c)
+		}
+	)
+	for _, change := range skipAutoChanges(modify.Changes) {
+		switch change := change.(type) {
+		case *schema.AddAttr, *schema.ModifyAttr, *schema.DropAttr:
+			if from, to, err := commentChange(change); err == nil {
+				comments = append(comments, s.tableComment(t, to, from))
+				continue
+			}
+			if err := s.alterRedshiftAttr(t, change, alter); err != nil {
+				return err
+			}
+		case *schema.AddColumn:
+			if _, ok := change.C.Type.Type.(*schema.EnumType); ok {
+				return fmt.Errorf("redshift: enum type of column %q is not supported", change.C.Name)
+			}
+			b := s.build("ALTER TABLE").Table(t).P("ADD COLUMN")
+			s.column(b, t, change.C)
+			alter(change, fmt.Sprintf("add column %q to table: %q", change.C.Name, t.Name), b,
+				s.build("ALTER TABLE").Table(t).P("DROP COLUMN").Ident(change.C.Name))
+			if c := (schema.Comment{}); sqlx.Has(change.C.Attrs, &c) {
+				comments = append(comments, s.columnComment(t, change.C, c.Text, ""))
+			}
+		case *schema.DropColumn:
+			alter(change, fmt.Sprintf("drop column %q from table: %q", change.C.Name, t.Name),
+				s.build("ALTER TABLE").Table(t).P("DROP COLUMN").Ident(change.C.Name), nil)
+		case *schema.RenameColumn:
+			alter(change, fmt.Sprintf("rename column %q to %q in table: %q", change.From.Name, change.To.Name, t.Name),
+				s.build("ALTER TABLE").Table(t).P("RENAME COLUMN").Ident(change.From.Name).P("TO").Ident(change.To.Name),
+				s.build("ALTER TABLE").Table(t).P("RENAME COLUMN").Ident(change.To.Name).P("TO").Ident(change.From.Name))
+		case *schema.ModifyColumn:
+			k := change.Change
+			if k.Is(schema.ChangeComment) {
+				from, to, err := commentChange(sqlx.CommentDiff(change.From.Attrs, change.To.Attrs))
+				if err != nil {
+					return err
+				}
+				comments = append(comments, s.columnComment(t, change.To, to, from))
+				k &= ^schema.ChangeComment
+			}
+			switch {
+			case k.Is(schema.NoChange):
+			// Increasing the size of VARCHAR columns is the only type change that is
+			// supported by Redshift, and it cannot be executed in a transaction block.
+			case k == schema.ChangeType && varcharType(change.From) && varcharType(change.To):
+				s.Transactional = false
+				alter(change, fmt.Sprintf("modify column %q of table: %q", change.To.Name, t.Name),
+					s.build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(change.To.Name).P("TYPE", s.typeName(t, change.To)),
+					s.build("ALTER TABLE").Table(t).P("ALTER COLUMN").Ident(change.From.Name).P("TYPE", s.typeName(t, change.From)))
+			default:
+				return fmt.Errorf("redshift: unsupported change of column %q in table %q: only increasing the size of VARCHAR columns is supported", change.To.Name, t.Name)
+			}
+		case *schema.AddIndex:
+			if !change.I.Unique {
+				return fmt.Errorf("redshift: index %q of table %q is not supported, use SORTKEY instead", change.I.Name, t.Name)
+			}
+			b := s.build("ALTER TABLE").Table(t).P("ADD")
+			if change.I.Name != "" {
+				b.P("CONSTRAINT").Ident(change.I.Name)
+			}
+			b.P("UNIQUE")
+			s.indexParts(b, change.I.Parts)
+			var reverse *sqlx.Builder
+			if change.I.Name != "" {
+				reverse = s.build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(change.I.Name)
+			}
+			alter(change, fmt.Sprintf("add unique constraint %q to table: %q", change.I.Name, t.Name), b, reverse)
+		case *schema.DropIndex:
+			if !change.I.Unique {
+				return fmt.Errorf("redshift: index %q of table %q is not supported", change.I.Name, t.Name)
+			}
+			alter(change, fmt.Sprintf("drop unique constraint %q from table: %q", change.I.Name, t.Name),
+				s.build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(change.I.Name), nil)
+		case *schema.AddForeignKey:
+			b := s.build("ALTER TABLE").Table(t).P("ADD")
+			s.fks(b, change.F)
+			alter(change, fmt.Sprintf("add foreign key %q to table: %q", change.F.Symbol, t.Name), b,
+				s.build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(change.F.Symbol))
+		case *schema.DropForeignKey:
+			reverse := s.build("ALTER TABLE").Table(t).P("ADD")
+			s.fks(reverse, change.F)
+			alter(change, fmt.Sprintf("drop foreign key %q from table: %q", change.F.Symbol, t.Name),
+				s.build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(change.F.Symbol), reverse)
+		case *schema.ModifyForeignKey:
+			b := s.build("ALTER TABLE").Table(t).P("ADD")
+			s.fks(b, change.To)
+			alter(change, fmt.Sprintf("drop foreign key %q from table: %q", change.From.Symbol, t.Name),
+				s.build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(change.From.Symbol), nil)
+			alter(change, fmt.Sprintf("add foreign key %q to table: %q", change.To.Symbol, t.Name), b, nil)
+		default:
+			return fmt.Errorf("redshift: unsupported change type: %T", change)
+		}
+	}
+	s.append(comments...)
+	return nil
+}
+
+// alterRedshiftAttr appends the ALTER TABLE statements for changing
+// the distribution style or the sort key of a Redshift table.
+func (s *state) alterRedshiftAttr(t *schema.Table, change schema.Change, alter func(schema.Change, string, *sqlx.Builder, *sqlx.Builder)) error {
+	var from, to schema.Attr
+	switch change := change.(type) {
+	case *schema.AddAttr:
+		to = change.A
+	case *schema.ModifyAttr:
+		from, to = change.From, change.To
+	case *schema.DropAttr:
+		from = change.A
+	}
+	switch a := to.(type) {
+	case *DistStyle:
+		b := s.build("ALTER TABLE").Table(t)
+		distAlter(b, dist([]schema.Attr{a}))
+		var reverse *sqlx.Builder
+		if from != nil {
+			reverse = s.build("ALTER TABLE").Table(t)
+			distAlter(reverse, dist([]schema.Attr{from}))
+		}
+		// Changing the distribution style or the sort key
+		// cannot be executed in a transaction block.
+		s.Transactional = false
+		alter(change, fmt.Sprintf("alter distribution style of table: %q", t.Name), b, reverse)
+		return nil
+	case *SortKey:
+		prev, _ := from.(*SortKey)
+		if a.Interleaved || prev != nil && prev.Interleaved {
+			return fmt.Errorf("redshift: interleaved sort key of table %q cannot be altered", t.Name)
+		}
+		reverse := s.build("ALTER TABLE").Table(t).P("ALTER SORTKEY NONE")
+		if prev != nil {
+			reverse = sortKey(s.build("ALTER TABLE").Table(t).P("ALTER"), prev)
+		}
+		s.Transactional = false
+		alter(change, fmt.Sprintf("alter sort key of table: %q", t.Name), sortKey(s.build("ALTER TABLE").Table(t).P("ALTER"), a), reverse)
+		return nil
+	}
+	if prev, ok := from.(*SortKey); ok && to == nil {
+		if prev.Interleaved {
+			return fmt.Errorf("redshift: interleaved sort key of table %q cannot be altered", t.Name)
+		}
+		s.Transactional = false
+		alter(change, fmt.Sprintf("drop sort key of table: %q", t.Name),
+			s.build("ALTER TABLE").Table(t).P("ALTER SORTKEY NONE"), sortKey(s.build("ALTER TABLE").Table(t).P("ALTER"), prev))
+		return nil
+	}
+	return fmt.Errorf("redshift: unsupported attribute change of table %q: %T", t.Name, change)
+}
+
+// distAlter writes the ALTER DISTSTYLE clause of the given style to the builder.
+func distAlter(b *sqlx.Builder, d *DistStyle) {
+	if d.S == DistKey {
+		b.P("ALTER DISTSTYLE KEY DISTKEY").Ident(d.Key)
+		return
+	}
+	b.P("ALTER DISTSTYLE", d.S)
+}
+
+// sortKey writes the SORTKEY clause of the given sort key to the builder.
+func sortKey(b *sqlx.Builder, k *SortKey) *sqlx.Builder {
+	if k.Interleaved {
+		b.P("INTERLEAVED")
+	}
+	return b.P("SORTKEY").Wrap(func(b *sqlx.Builder) {
+		b.MapComma(k.Columns, func(i int, b *sqlx.Builder) {
+			b.Ident(k.Columns[i])
+		})
+	})
+}
+
+// redshiftIdentityClause writes the Redshift IDENTITY clause of the given identity to
+// the builder. Unlike PostgreSQL, the seed and the step of the identity are required.
+func redshiftIdentityClause(b *sqlx.Builder, id *Identity) {
+	if id.Generation == "BY DEFAULT" {
+		b.P("GENERATED BY DEFAULT AS")
+	}
+	b.P(fmt.Sprintf("IDENTITY(%d, %d)", id.Sequence.Start, id.Sequence.Increment))
+}
+
+// varcharType reports if the column type is VARCHAR.
+func varcharType(c *schema.Column) bool {
+	t, ok := c.Type.Type.(*schema.StringType)
+	return ok && (t.T == TypeVarChar || t.T == TypeCharVar)
+}
+
+const (
+	// Query to list Redshift database schemas.
+	redshiftSchemasQuery = "SELECT n.nspname, d.description FROM pg_catalog.pg_namespace AS n LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = n.oid AND d.objsubid = 0 WHERE n.nspname <> 'information_schema' AND n.nspname NOT LIKE 'pg_%' ORDER BY n.nspname"
+
+	// Query to list specific Redshift database schemas.
+	redshiftSchemasQueryArgs = "SELECT n.nspname, d.description FROM pg_catalog.pg_namespace AS n LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = n.oid AND d.objsubid = 0 WHERE n.nspname %s ORDER BY n.nspname"
+
+	// Query to list Redshift table information.
+	redshiftTableQuery = `
+SELECT
+	n.nspname,
+	d.description,
+	c.reldiststyle
+FROM
+	pg_catalog.pg_class AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = c.oid AND d.objsubid = 0
+WHERE
+	c.relkind = 'r'
+	AND c.relname = $1
+	AND n.nspname = (CURRENT_SCHEMA())
+`
+	redshiftTableSchemaQuery = `
+SELECT
+	n.nspname,
+	d.description,
+	c.reldiststyle
+FROM
+	pg_catalog.pg_class AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	LEFT JOIN pg_catalog.pg_description AS d ON d.objoid = c.oid AND d.objsubid = 0
+WHERE
+	c.relkind = 'r'
+	AND c.relname = $1
+	AND n.nspname = $2
+`
+	// Query to list Redshift table columns. The result set is
+	// identical to the one of columnsQuery, and the identity
+	// attributes are extracted from the DEFAULT expressions.
+	redshiftColumnsQuery = `
+SELECT
+	t1.column_name,
+	t1.data_type,
+	t1.is_nullable,
+	t1.column_default,
+	t1.character_maximum_length,
+	t1.numeric_precision,
+	t1.datetime_precision,
+	t1.numeric_scale,
+	t1.character_set_name,
+	t1.collation_name,
+	t1.udt_name,
+	'NO' AS is_identity,
+	NULL AS identity_start,
+	NULL AS identity_increment,
+	NULL AS identity_generation,
+	t5.description AS comment,
+	t2.typtype,
+	t2.oid,
+	0 AS array_dims
+FROM
+	"information_schema"."columns" AS t1
+	LEFT JOIN pg_catalog.pg_namespace AS t4
+	ON t4.nspname = t1.udt_schema
+	LEFT JOIN pg_catalog.pg_type AS t2
+	ON t2.typnamespace = t4.oid AND t2.typname = t1.udt_name
+	JOIN pg_catalog.pg_namespace AS t6
+	ON t6.nspname = t1.table_schema
+	JOIN pg_catalog.pg_class AS t3
+	ON t3.relnamespace = t6.oid AND t3.relname = t1.table_name
+	LEFT JOIN pg_catalog.pg_description AS t5
+	ON t5.objoid = t3.oid AND t5.objsubid = t1.ordinal_position
+WHERE
+	t1.table_schema = $1 AND t1.table_name = $2
+ORDER BY
+	t1.ordinal_position
+`
+	// Query to list the distribution and sort key columns of a Redshift table.
+	redshiftKeysQuery = `
+SELECT
+	a.attname,
+	a.attisdistkey,
+	a.attsortkeyord
+FROM
+	pg_catalog.pg_attribute AS a
+	JOIN pg_catalog.pg_class AS c ON c.oid = a.attrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+WHERE
+	n.nspname = $1
+	AND c.relname = $2
+	AND a.attnum > 0
+	AND (a.attisdistkey OR a.attsortkeyord <> 0)
+ORDER BY
+	abs(a.attsortkeyord)
+`
+	// Query to list the PRIMARY KEY and UNIQUE constraints of a Redshift table.
+	// Note that Redshift does not support array_position, and the columns are
+	// ordered by their position in the table.
+	redshiftConstraintsQuery = `
+SELECT
+	t1.conname,
+	t1.contype,
+	t2.attname
+FROM
+	pg_catalog.pg_constraint AS t1
+	JOIN pg_catalog.pg_class AS c ON c.oid = t1.conrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_attribute AS t2 ON t2.attrelid = t1.conrelid AND t2.attnum = ANY (t1.conkey)
+WHERE
+	t1.contype IN ('p', 'u')
+	AND n.nspname = $1
+	AND c.relname = $2
+ORDER BY
+	t1.conname, t2.attnum
+`
+)
@@ -0,0 +1,227 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_Redshift(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.redshift()
+	drv, err := Open(db)
+	require.NoError(t, err)
+	require.True(t, drv.Redshift())
+	require.Equal(t, "8.00.02", drv.version)
+
+	// Non-Redshift databases with a 5-digit version are rejected.
+	db2, m2, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m2}.version("90600")
+	m2.ExpectQuery(sqltest.Escape("SELECT version()")).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 9.6.0"))
+	_, err = Open(db2)
+	require.EqualError(t, err, "postgres: malformed version: 90600")
+}
+
+func TestDriver_InspectRedshift(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.redshift()
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(redshiftSchemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | description
+---------+-------------
+ public  | nil
+`))
+	mk.tables("public", "events")
+	mk.ExpectQuery(sqltest.Escape(redshiftTableSchemaQuery)).
+		WithArgs("events", "public").
+		WillReturnRows(sqltest.Rows(`
+ nspname | description | reldiststyle
+---------+-------------+--------------
+ public  | events      | 1
+`))
+	mk.ExpectQuery(sqltest.Escape(redshiftColumnsQuery)).
+		WithArgs("public", "events").
+		WillReturnRows(sqltest.Rows(`
+ column_name | data_type                   | is_nullable | column_default                          | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | character_set_name | collation_name | udt_name  | is_identity | identity_start | identity_increment | identity_generation | comment | typtype | oid  | array_dims
+-------------+-----------------------------+-------------+-----------------------------------------+--------------------------+-------------------+--------------------+---------------+--------------------+----------------+-----------+-------------+----------------+--------------------+---------------------+---------+---------+------+------------
+ id          | bigint                      | NO          | "identity"(100473, 0, '1,1'::text)      | nil                      | 64                | nil                | 0             | nil                | nil            | int8      | NO          | nil            | nil                | nil                 | nil     | b       | 20   | 0
+ user_id     | integer                     | NO          | nil                                     | nil                      | 32                | nil                | 0             | nil                | nil            | int4      | NO          | nil            | nil                | nil                 | nil     | b       | 23   | 0
+ name        | character varying           | YES         | nil                                     | 256                      | nil               | nil                | nil           | nil                | nil            | varchar   | NO          | nil            | nil                | nil                 | nil     | b       | 1043 | 0
+ created_at  | timestamp without time zone | NO          | nil                                     | nil                      | nil               | 6                  | nil           | nil                | nil            | timestamp | NO          | nil            | nil                | nil                 | nil     | b       | 1114 | 0
+`))
+	mk.ExpectQuery(sqltest.Escape(redshiftKeysQuery)).
+		WithArgs("public", "events").
+		WillReturnRows(sqltest.Rows(`
+ attname    | attisdistkey | attsortkeyord
+------------+--------------+---------------
+ user_id    | true         | 0
+ created_at | false        | 1
+ id         | false        | 2
+`))
+	mk.ExpectQuery(sqltest.Escape(redshiftConstraintsQuery)).
+		WithArgs("public", "events").
+		WillReturnRows(sqltest.Rows(`
+ conname     | contype | attname
+-------------+---------+---------
+ events_pkey | p       | id
+ events_name | u       | name
+`))
+	mk.noFKs()
+	s, err := drv.InspectSchema(context.Background(), "public", nil)
+	require.NoError(t, err)
+	events, ok := s.Table("events")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{
+		&schema.Comment{Text: "events"},
+		&DistStyle{S: DistKey, Key: "user_id"},
+		&SortKey{Columns: []string{"created_at", "id"}},
+	}, events.Attrs)
+	id, ok := events.Column("id")
+	require.True(t, ok)
+	require.Nil(t, id.Default)
+	require.Equal(t, []schema.Attr{&Identity{Generation: "ALWAYS", Sequence: &Sequence{Start: 1, Increment: 1}}}, id.Attrs)
+	require.Equal(t, "events_pkey", events.PrimaryKey.Name)
+	require.Len(t, events.Indexes, 1)
+	require.True(t, events.Indexes[0].Unique)
+	require.Equal(t, "name", events.Indexes[0].Parts[0].C.Name)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRedshiftIdentity(t *testing.T) {
+	c := &schema.Column{Name: "id", Default: &schema.RawExpr{X: `"identity"(100473, 0, ('10,5'::character varying)::text)`}}
+	redshiftIdentity(c)
+	require.Nil(t, c.Default)
+	require.Equal(t, []schema.Attr{&Identity{Generation: "BY DEFAULT", Sequence: &Sequence{Start: 10, Increment: 5}}}, c.Attrs)
+
+	c = &schema.Column{Name: "c", Default: &schema.RawExpr{X: "getdate()"}}
+	redshiftIdentity(c)
+	require.Equal(t, &schema.RawExpr{X: "getdate()"}, c.Default)
+	require.Empty(t, c.Attrs)
+}
+
+func TestDiff_Redshift(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.redshift()
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		from = &schema.Table{Name: "t", Attrs: []schema.Attr{&DistStyle{S: "auto"}, &SortKey{Columns: []string{"a"}}}}
+		to   = &schema.Table{Name: "t", Attrs: []schema.Attr{&DistStyle{Key: "a"}, &SortKey{Columns: []string{"a", "b"}}}}
+	)
+	changes, err := drv.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifyAttr{From: &DistStyle{S: DistAuto}, To: &DistStyle{S: DistKey, Key: "a"}},
+		&schema.ModifyAttr{From: &SortKey{Columns: []string{"a"}}, To: &SortKey{Columns: []string{"a", "b"}}},
+	}, changes)
+
+	// Missing distribution style defaults to AUTO.
+	changes, err = drv.TableDiff(&schema.Table{Name: "t"}, &schema.Table{Name: "t", Attrs: []schema.Attr{&DistStyle{S: DistAuto}}})
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestPlanChanges_Redshift(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.redshift()
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		id     = schema.NewIntColumn("id", "bigint").AddAttrs(&Identity{Generation: "ALWAYS"})
+		userID = schema.NewIntColumn("user_id", "integer")
+		name   = schema.NewStringColumn("name", "varchar", schema.StringSize(256))
+		events = schema.NewTable("events").
+			SetSchema(schema.New("public")).
+			AddColumns(id, userID, name).
+			SetPrimaryKey(schema.NewPrimaryKey(id)).
+			AddIndexes(schema.NewUniqueIndex("events_name").AddColumns(name)).
+			AddAttrs(&DistStyle{S: DistKey, Key: "user_id"}, &SortKey{Columns: []string{"id"}})
+	)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: events}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, `CREATE TABLE "public"."events" ("id" bigint NOT NULL IDENTITY(1, 1), "user_id" integer NOT NULL, "name" character varying(256) NOT NULL, PRIMARY KEY ("id"), CONSTRAINT "events_name" UNIQUE ("name")) DISTSTYLE KEY DISTKEY ("user_id") SORTKEY ("id")`, plan.Changes[0].Cmd)
+	require.True(t, plan.Transactional)
+
+	wider := schema.NewStringColumn("name", "varchar", schema.StringSize(512))
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{
+			T: events,
+			Changes: []schema.Change{
+				&schema.AddColumn{C: schema.NewNullStringColumn("city", "varchar", schema.StringSize(64))},
+				&schema.DropColumn{C: schema.NewIntColumn("legacy", "integer")},
+				&schema.ModifyColumn{From: name, To: wider, Change: schema.ChangeType},
+				&schema.ModifyAttr{From: &DistStyle{S: DistKey, Key: "user_id"}, To: &DistStyle{S: DistEven}},
+				&schema.ModifyAttr{From: &SortKey{Columns: []string{"id"}}, To: &SortKey{Columns: []string{"id", "user_id"}}},
+				&schema.AddAttr{A: &schema.Comment{Text: "events"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, plan.Transactional)
+	require.Len(t, plan.Changes, 6)
+	for i, c := range []struct{ cmd, reverse string }{
+		{`ALTER TABLE "public"."events" ADD COLUMN "city" character varying(64) NULL`, `ALTER TABLE "public"."events" DROP COLUMN "city"`},
+		{`ALTER TABLE "public"."events" DROP COLUMN "legacy"`, ""},
+		{`ALTER TABLE "public"."events" ALTER COLUMN "name" TYPE character varying(512)`, `ALTER TABLE "public"."events" ALTER COLUMN "name" TYPE character varying(256)`},
+		{`ALTER TABLE "public"."events" ALTER DISTSTYLE EVEN`, `ALTER TABLE "public"."events" ALTER DISTSTYLE KEY DISTKEY "user_id"`},
+		{`ALTER TABLE "public"."events" ALTER SORTKEY ("id", "user_id")`, `ALTER TABLE "public"."events" ALTER SORTKEY ("id")`},
+		{`COMMENT ON TABLE "public"."events" IS 'events'`, `COMMENT ON TABLE "public"."events" IS ''`},
+	} {
+		require.Equal(t, c.cmd, plan.Changes[i].Cmd)
+		require.Equal(t, c.reverse, plan.Changes[i].Reverse)
+	}
+
+	// Unsupported changes.
+	for _, c := range []schema.Change{
+		&schema.AddIndex{I: schema.NewIndex("events_user").AddColumns(userID)},
+		&schema.ModifyColumn{From: userID, To: schema.NewNullIntColumn("user_id", "integer"), Change: schema.ChangeNull},
+		&schema.AddCheck{C: schema.NewCheck().SetName("positive").SetExpr("id > 0")},
+		&schema.ModifyAttr{From: &SortKey{Columns: []string{"id"}}, To: &SortKey{Columns: []string{"id"}, Interleaved: true}},
+	} {
+		_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: events, Changes: []schema.Change{c}}})
+		require.Error(t, err)
+	}
+	_, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddTable{T: schema.NewTable("t").AddColumns(userID).AddIndexes(schema.NewIndex("t_user").AddColumns(userID))},
+	})
+	require.Error(t, err)
+}
+
+func TestRedshift_ReadOnly(t *testing.T) {
+	for _, q := range []string{
+		redshiftSchemasQuery, redshiftTableQuery, redshiftTableSchemaQuery, redshiftColumnsQuery,
+		redshiftKeysQuery, redshiftConstraintsQuery, fmt.Sprintf(redshiftSchemasQueryArgs, "IN ($1, $2)"),
+	} {
+		require.True(t, sqlx.ReadOnlyStmt(q), q)
+	}
+}
+
+func (m mock) redshift() {
+	m.version("80002")
+	m.ExpectQuery(sqltest.Escape("SELECT version()")).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).
+			AddRow("PostgreSQL 8.0.2 on i686-pc-linux-gnu, compiled by GCC gcc (GCC) 3.4.2 20041017 (Red Hat 3.4.2-6.fc3), Redshift 1.0.48042"))
+}
@@ -0,0 +1,286 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A ReplicationWarning describes a change that may break logical replication
+	// of a table that is published by the database, or that is replicated to it
+	// by a subscription.
+	ReplicationWarning struct {
+		// Table that is affected by the change, qualified with its schema name.
+		Table string
+		// Change that caused the warning. For changes of a table,
+		// this is the change that is held by the schema.ModifyTable.
+		Change schema.Change
+		// Publications or subscriptions of the table.
+		Publications, Subscriptions []string
+		// Message describes the problem.
+		Message string
+	}
+
+	// replTable holds the replication information of a table.
+	replTable struct {
+		pubs, subs []string
+		// Publications that publish updates or deletes, which require a replica identity.
+		identityPubs []string
+		// Replica identity of the table (i.e. pg_class.relreplident),
+		// and the name of the index for USING INDEX identities.
+		ident, identIdx string
+	}
+)
+
+// Queries for the publications and the subscriptions of the tables in the database.
+const (
+	publicationsQuery = `
+SELECT
+	pt.schemaname,
+	pt.tablename,
+	pt.pubname,
+	p.pubupdate OR p.pubdelete AS identity,
+	c.relreplident,
+	i.relname AS identity_index
+FROM
+	pg_catalog.pg_publication_tables AS pt
+	JOIN pg_catalog.pg_publication AS p ON p.pubname = pt.pubname
+	JOIN pg_catalog.pg_namespace AS n ON n.nspname = pt.schemaname
+	JOIN pg_catalog.pg_class AS c ON c.relnamespace = n.oid AND c.relname = pt.tablename
+	LEFT JOIN pg_catalog.pg_index AS x ON x.indrelid = c.oid AND x.indisreplident
+	LEFT JOIN pg_catalog.pg_class AS i ON i.oid = x.indexrelid
+ORDER BY
+	pt.schemaname, pt.tablename, pt.pubname
+`
+	subscriptionsQuery = `
+SELECT
+	n.nspname,
+	c.relname,
+	s.subname
+FROM
+	pg_catalog.pg_subscription_rel AS sr
+	JOIN pg_catalog.pg_subscription AS s ON s.oid = sr.srsubid
+	JOIN pg_catalog.pg_class AS c ON c.oid = sr.srrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+ORDER BY
+	n.nspname, c.relname, s.subname
+`
+)
+
+// CheckReplication checks if the given changes may break the logical replication of the
+// database, and returns a warning for each problematic change. Note that DDL commands are
+// not replicated by PostgreSQL, and schema changes of replicated tables should be applied
+// on both sides, in an order that keeps the replication working. For example, columns are
+// added to subscribers before they are added to publishers.
+//
+// On publishers, the checks detect tables that lose their replica identity, which fails
+// UPDATE and DELETE statements on tables that are published with these operations. On
+// subscribers, they detect changes that fail the replication of incoming rows. Reading
+// the subscriptions of the database requires superuser privileges.
+func (d *Driver) CheckReplication(ctx context.Context, changes []schema.Change) ([]*ReplicationWarning, error) {
+	var modified bool
+	for _, c := range changes {
+		switch c.(type) {
+		case *schema.ModifyTable, *schema.DropTable:
+			modified = true
+		}
+	}
+	// Changes of new tables and schemas do not affect replication.
+	if !modified {
+		return nil, nil
+	}
+	tables, err := d.replTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ws []*ReplicationWarning
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.DropTable:
+			name, r := d.replTable(tables, c.T)
+			if r == nil {
+				continue
+			}
+			if len(r.pubs) > 0 {
+				ws = append(ws, r.warn(name, c, "table is dropped from its publications, and is no longer replicated to their subscribers"))
+			}
+			if len(r.subs) > 0 {
+				ws = append(ws, r.warn(name, c, "table is replicated by subscriptions, and its incoming changes will fail to be applied"))
+			}
+		case *schema.ModifyTable:
+			name, r := d.replTable(tables, c.T)
+			if r == nil {
+				continue
+			}
+			for _, change := range c.Changes {
+				for _, m := range r.check(c.T, change) {
+					ws = append(ws, r.warn(name, change, m))
+				}
+			}
+		}
+	}
+	return ws, nil
+}
+
+// replTables returns the replication information of the database tables.
+func (d *Driver) replTables(ctx context.Context) (map[string]*replTable, error) {
+	tables := make(map[string]*replTable)
+	table := func(ns, name string) *replTable {
+		k := ns + "." + name
+		if tables[k] == nil {
+			tables[k] = &replTable{}
+		}
+		return tables[k]
+	}
+	rows, err := d.QueryContext(ctx, publicationsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: querying publications: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns, name, pub, ident string
+			identity             bool
+			idx                  sql.NullString
+		)
+		if err := rows.Scan(&ns, &name, &pub, &identity, &ident, &idx); err != nil {
+			return nil, fmt.Errorf("postgres: scanning publications: %w", err)
+		}
+		t := table(ns, name)
+		t.pubs = append(t.pubs, pub)
+		if identity {
+			t.identityPubs = append(t.identityPubs, pub)
+		}
+		t.ident, t.identIdx = ident, idx.String
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	rows, err = d.QueryContext(ctx, subscriptionsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: querying subscriptions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ns, name, sub string
+		if err := rows.Scan(&ns, &name, &sub); err != nil {
+			return nil, fmt.Errorf("postgres: scanning subscriptions: %w", err)
+		}
+		t := table(ns, name)
+		t.subs = append(t.subs, sub)
+	}
+	return tables, rows.Close()
+}
+
+// replTable returns the qualified name of the table, and its replication
+// information, or nil if the table is neither published nor subscribed.
+func (d *Driver) replTable(tables map[string]*replTable, t *schema.Table) (string, *replTable) {
+	ns := "public"
+	switch {
+	case t.Schema != nil && t.Schema.Name != "":
+		ns = t.Schema.Name
+	case len(d.searchPath) > 0:
+		ns = d.searchPath[0]
+	}
+	name := ns + "." + t.Name
+	return name, tables[name]
+}
+
+// check returns the problems that are caused by a change of a replicated table.
+func (r *replTable) check(t *schema.Table, c schema.Change) []string {
+	var (
+		msgs       []string
+		published  = len(r.pubs) > 0
+		subscribed = len(r.subs) > 0
+	)
+	switch c := c.(type) {
+	case *schema.AddColumn:
+		if published {
+			msgs = append(msgs, fmt.Sprintf("column %q must be added to the subscribers before it is added to the publisher", c.C.Name))
+		}
+		if subscribed && !c.C.Type.Null && c.C.Default == nil {
+			msgs = append(msgs, fmt.Sprintf("column %q is not nullable and has no default value, but it does not exist on the publisher", c.C.Name))
+		}
+	case *schema.DropColumn:
+		if published && r.identityColumn(t, c.C) {
+			msgs = append(msgs, fmt.Sprintf("dropping column %q drops the replica identity of the table, and fails updates and deletes on publications %q", c.C.Name, r.identityPubs))
+		}
+		if subscribed {
+			msgs = append(msgs, fmt.Sprintf("column %q must be dropped from the publisher before it is dropped from the subscriber", c.C.Name))
+		}
+	case *schema.RenameColumn:
+		msgs = append(msgs, fmt.Sprintf("columns are replicated by name, and column %q must be renamed on both sides", c.From.Name))
+	case *schema.ModifyColumn:
+		if c.Change.Is(schema.ChangeType) {
+			msgs = append(msgs, fmt.Sprintf("type of column %q must be compatible with the type on the other side of the replication", c.To.Name))
+		}
+		if subscribed && c.Change.Is(schema.ChangeNull) && !c.To.Type.Null {
+			msgs = append(msgs, fmt.Sprintf("column %q is set to not null, and incoming rows with null values will fail to be applied", c.To.Name))
+		}
+	case *schema.DropIndex:
+		if published && r.identityIndex(c.I) {
+			msgs = append(msgs, fmt.Sprintf("index %q is the replica identity of the table, and dropping it fails updates and deletes on publications %q", c.I.Name, r.identityPubs))
+		}
+	case *schema.ModifyIndex:
+		if published && r.identityIndex(c.From) {
+			msgs = append(msgs, fmt.Sprintf("index %q is the replica identity of the table, and rebuilding it resets the identity of the table", c.From.Name))
+		}
+	case *schema.AddIndex:
+		if subscribed && c.I.Unique {
+			msgs = append(msgs, fmt.Sprintf("unique index %q may conflict with incoming rows", c.I.Name))
+		}
+	case *schema.AddCheck:
+		if subscribed {
+			msgs = append(msgs, fmt.Sprintf("check %q may reject incoming rows", c.C.Name))
+		}
+	}
+	return msgs
+}
+
+// identityColumn reports if the given column is part of the replica identity of
+// the table, in case updates or deletes are published for it. The default replica
+// identity is the primary key of the table.
+func (r *replTable) identityColumn(t *schema.Table, c *schema.Column) bool {
+	if len(r.identityPubs) == 0 {
+		return false
+	}
+	var idx *schema.Index
+	switch r.ident {
+	case "d":
+		idx = t.PrimaryKey
+	case "i":
+		idx, _ = t.Index(r.identIdx)
+	}
+	if idx == nil {
+		return false
+	}
+	for _, p := range idx.Parts {
+		if p.C != nil && p.C.Name == c.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// identityIndex reports if the given index is the replica identity of the table,
+// in case updates or deletes are published for it.
+func (r *replTable) identityIndex(idx *schema.Index) bool {
+	return len(r.identityPubs) > 0 && r.ident == "i" && r.identIdx == idx.Name
+}
+
+func (r *replTable) warn(name string, c schema.Change, msg string) *ReplicationWarning {
+	return &ReplicationWarning{
+		Table:         name,
+		Change:        c,
+		Publications:  r.pubs,
+		Subscriptions: r.subs,
+		Message:       msg,
+	}
+}
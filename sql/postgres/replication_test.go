@@ -0,0 +1,87 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_CheckReplication(t *testing.T) {
+	var (
+		public = schema.New("public")
+		id     = schema.NewIntColumn("id", "bigint")
+		code   = schema.NewStringColumn("code", "text")
+		users  = schema.NewTable("users").SetSchema(public).AddColumns(id)
+		codes  = schema.NewTable("codes").SetSchema(public).AddColumns(code)
+		events = schema.NewTable("events").SetSchema(public)
+		logs   = schema.NewTable("logs").SetSchema(public)
+		codeI  = schema.NewUniqueIndex("codes_code").AddColumns(code)
+	)
+	users.SetPrimaryKey(schema.NewPrimaryKey(id))
+	codes.AddIndexes(codeI)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+
+	// No queries are executed for new tables.
+	ws, err := drv.CheckReplication(context.Background(), []schema.Change{&schema.AddTable{T: schema.NewTable("pets")}})
+	require.NoError(t, err)
+	require.Empty(t, ws)
+
+	m.ExpectQuery(sqltest.Escape(publicationsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schemaname | tablename | pubname | identity | relreplident | identity_index
+------------+-----------+---------+----------+--------------+----------------
+ public     | codes     | app     | t        | i            | codes_code
+ public     | logs      | logs    | f        | d            |
+ public     | users     | app     | t        | d            |
+ public     | users     | cdc     | f        | d            |
+`))
+	m.ExpectQuery(sqltest.Escape(subscriptionsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ nspname | relname | subname
+---------+---------+---------
+ public  | events  | upstream
+`))
+	ws, err = drv.CheckReplication(context.Background(), []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.DropColumn{C: id},
+			&schema.AddColumn{C: schema.NewNullStringColumn("name", "text")},
+		}},
+		&schema.ModifyTable{T: codes, Changes: []schema.Change{&schema.DropIndex{I: codeI}}},
+		&schema.ModifyTable{T: logs, Changes: []schema.Change{&schema.DropColumn{C: schema.NewIntColumn("id", "int")}}},
+		&schema.ModifyTable{T: events, Changes: []schema.Change{
+			&schema.AddColumn{C: schema.NewIntColumn("version", "int")},
+			&schema.AddIndex{I: schema.NewUniqueIndex("events_version")},
+		}},
+		&schema.DropTable{T: events},
+		&schema.ModifyTable{T: schema.NewTable("other").SetSchema(public), Changes: []schema.Change{&schema.DropColumn{C: id}}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	var msgs []string
+	for _, w := range ws {
+		msgs = append(msgs, w.Table+": "+w.Message)
+	}
+	require.Equal(t, []string{
+		`public.users: dropping column "id" drops the replica identity of the table, and fails updates and deletes on publications ["app"]`,
+		`public.users: column "name" must be added to the subscribers before it is added to the publisher`,
+		`public.codes: index "codes_code" is the replica identity of the table, and dropping it fails updates and deletes on publications ["app"]`,
+		`public.events: column "version" is not nullable and has no default value, but it does not exist on the publisher`,
+		`public.events: unique index "events_version" may conflict with incoming rows`,
+		`public.events: table is replicated by subscriptions, and its incoming changes will fail to be applied`,
+	}, msgs)
+	require.Equal(t, []string{"app", "cdc"}, ws[0].Publications)
+	require.Equal(t, []string{"upstream"}, ws[3].Subscriptions)
+}
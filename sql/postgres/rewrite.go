@@ -0,0 +1,167 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// rewriteTable brings the columns of the table to their declared order, as PostgreSQL
+// cannot reorder columns in place. The table is created with a temporary name, its rows
+// are copied to it, and the existing table is dropped and replaced by the new one.
+//
+// Note that the whole table is copied while an ACCESS EXCLUSIVE lock is held on it, and
+// applying the plan fails (and is rolled back) if other objects depend on the table (e.g. views).
+func (s *state) rewriteTable(ctx context.Context, modify *schema.ModifyTable) error {
+	t := modify.T
+	if err := checkReferenced(t); err != nil {
+		return err
+	}
+	n := len(s.Changes)
+	newT := *t
+	newT.Name = "new_" + t.Name
+	newT.Indexes = nil
+	newT.ForeignKeys = make([]*schema.ForeignKey, len(t.ForeignKeys))
+	for i, fk := range t.ForeignKeys {
+		fk := *fk
+		// Self-referencing foreign keys should reference the new table.
+		if fk.RefTable == t {
+			fk.RefTable = &newT
+		}
+		newT.ForeignKeys[i] = &fk
+	}
+	if err := s.addTable(ctx, &schema.AddTable{T: &newT}); err != nil {
+		return err
+	}
+	var (
+		override    bool
+		fromC, toC  []string
+		owned, seqs []*schema.Column
+		renames     = make(map[string]string)
+		added       = make(map[string]bool)
+	)
+	for _, c := range modify.Changes {
+		switch c := c.(type) {
+		case *schema.AddColumn:
+			added[c.C.Name] = true
+		case *schema.RenameColumn:
+			renames[c.To.Name] = c.From.Name
+		}
+	}
+	for _, c := range t.Columns {
+		var id Identity
+		switch _, ok := serialSeq(c); {
+		// Sequences of serial columns that were inspected from the database
+		// are moved to the new table, as they are owned by the current one.
+		case ok:
+			owned = append(owned, c)
+		case isSerial(c):
+			seqs = append(seqs, c)
+		case sqlx.Has(c.Attrs, &id):
+			seqs = append(seqs, c)
+			override = override || id.Generation == "ALWAYS"
+		}
+		if added[c.Name] {
+			continue
+		}
+		name, ok := renames[c.Name]
+		if !ok {
+			name = c.Name
+		}
+		toC, fromC = append(toC, c.Name), append(fromC, name)
+	}
+	b := s.build("INSERT INTO").Table(&newT).Wrap(func(b *sqlx.Builder) {
+		b.MapComma(toC, func(i int, b *sqlx.Builder) {
+			b.Ident(toC[i])
+		})
+	})
+	if override {
+		b.P("OVERRIDING SYSTEM VALUE")
+	}
+	b.P("SELECT").MapComma(fromC, func(i int, b *sqlx.Builder) {
+		b.Ident(fromC[i])
+	})
+	s.append(&migrate.Change{
+		Cmd:     b.P("FROM").Table(t).String(),
+		Comment: fmt.Sprintf("copy rows from table %q to new temporary table %q to reorder its columns (full table rewrite)", t.Name, newT.Name),
+	})
+	for _, c := range owned {
+		seq, _ := serialSeq(c)
+		s.append(&migrate.Change{
+			Cmd:     fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.%s", seq, s.build("").Table(&newT), s.build("").Ident(c.Name)),
+			Comment: fmt.Sprintf("move sequence %q to new temporary table %q", seq, newT.Name),
+		})
+	}
+	s.append(&migrate.Change{
+		Cmd:     s.build("DROP TABLE").Table(t).String(),
+		Comment: fmt.Sprintf("drop %q table after copying rows", t.Name),
+	})
+	s.append(&migrate.Change{
+		Cmd:     s.build("ALTER TABLE").Table(&newT).P("RENAME TO").Ident(t.Name).String(),
+		Comment: fmt.Sprintf("rename temporary table %q to %q", newT.Name, t.Name),
+	})
+	if t.PrimaryKey != nil {
+		name := t.PrimaryKey.Name
+		if name == "" {
+			name = t.Name + "_pkey"
+		}
+		s.append(&migrate.Change{
+			Cmd:     s.build("ALTER TABLE").Table(t).P("RENAME CONSTRAINT").Ident(newT.Name + "_pkey").P("TO").Ident(name).String(),
+			Comment: fmt.Sprintf("rename primary key of table %q", t.Name),
+		})
+	}
+	// The sequences of the new table continue from the copied values.
+	for _, c := range seqs {
+		name, col := s.build("").Table(t).String(), s.build("").Ident(c.Name).String()
+		s.append(&migrate.Change{
+			Cmd:     fmt.Sprintf("SELECT setval(pg_get_serial_sequence(%s, %s), MAX(%s)) FROM %s HAVING MAX(%s) IS NOT NULL", quote(name), quote(c.Name), col, name, col),
+			Comment: fmt.Sprintf("set the sequence of column %q to the copied values", c.Name),
+		})
+	}
+	s.addIndexes(t, t.Indexes...)
+	var c schema.Comment
+	for _, idx := range t.Indexes {
+		if sqlx.Has(idx.Attrs, &c) && c.Text != "" {
+			s.append(s.indexComment(t, idx, c.Text, ""))
+		}
+	}
+	// All statements belong to the table rewrite, and cannot be reversed.
+	for _, c := range s.Changes[n:] {
+		c.Source, c.Reverse = modify, ""
+	}
+	return nil
+}
+
+// checkReferenced returns an error if the table is referenced by foreign
+// keys of other tables, as it cannot be dropped and replaced by a new one.
+func checkReferenced(t *schema.Table) error {
+	if t.Schema == nil {
+		return nil
+	}
+	var schemas []*schema.Schema
+	if r := t.Schema.Realm; r != nil {
+		schemas = r.Schemas
+	} else {
+		schemas = []*schema.Schema{t.Schema}
+	}
+	for _, s := range schemas {
+		for _, ref := range s.Tables {
+			if ref == t || ref.Name == t.Name && ref.Schema != nil && ref.Schema.Name == t.Schema.Name {
+				continue
+			}
+			for _, fk := range ref.ForeignKeys {
+				if fk.RefTable == t {
+					return fmt.Errorf("postgres: cannot reorder the columns of table %q, as it is referenced by foreign key %q of table %q", t.Name, fk.Symbol, ref.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
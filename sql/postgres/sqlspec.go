@@ -142,6 +142,13 @@ func Schema(spec *sqlspec.Schema, tables []*sqlspec.Table, enums []*Enum) (*sche
 	sch := &schema.Schema{
 		Name: spec.Name,
 	}
+	if c, ok := spec.Attr("comment"); ok {
+		s, err := c.String()
+		if err != nil {
+			return nil, err
+		}
+		sch.Attrs = append(sch.Attrs, &schema.Comment{Text: s})
+	}
 	m := make(map[*schema.Table]*sqlspec.Table)
 	for _, ts := range tables {
 		table, err := convertTable(ts, sch)
@@ -168,7 +175,120 @@ func Schema(spec *sqlspec.Schema, tables []*sqlspec.Table, enums []*Enum) (*sche
 // ForeignKeySpecs into ForeignKeys, as the target tables do not necessarily exist in the schema
 // at this point. Instead, the linking is done by the convertSchema function.
 func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, error) {
-	return specutil.Table(spec, parent, convertColumn, specutil.PrimaryKey, specutil.Index, specutil.Check)
+	t, err := specutil.Table(spec, parent, convertColumn, specutil.PrimaryKey, convertIndex, specutil.Check)
+	if err != nil {
+		return nil, err
+	}
+	if err := convertTablespace(spec, &t.Attrs); err != nil {
+		return nil, err
+	}
+	if err := convertTableParams(spec, &t.Attrs); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// convertTableParams converts the spec storage parameters of a table
+// (e.g. "autovacuum_enabled = false") to a schema attribute.
+func convertTableParams(spec specutil.Attrer, attrs *[]schema.Attr) error {
+	var (
+		set bool
+		p   TableStorageParams
+	)
+	for _, n := range tableParamNames {
+		attr, ok := spec.Attr(n)
+		if !ok {
+			continue
+		}
+		lit, ok := attr.V.(*schemaspec.LiteralValue)
+		if !ok {
+			return fmt.Errorf("postgres: expect literal value for table attribute %q", n)
+		}
+		if err := p.Set(n, lit.V); err != nil {
+			return fmt.Errorf("postgres: %w", err)
+		}
+		set = true
+	}
+	if set {
+		*attrs = append(*attrs, &p)
+	}
+	return nil
+}
+
+// convertIndex converts a sqlspec.Index into a schema.Index.
+func convertIndex(spec *sqlspec.Index, parent *schema.Table) (*schema.Index, error) {
+	idx, err := specutil.Index(spec, parent)
+	if err != nil {
+		return nil, err
+	}
+	if attr, ok := spec.Attr("type"); ok {
+		t, err := attr.String()
+		if err != nil {
+			return nil, err
+		}
+		idx.Attrs = append(idx.Attrs, &IndexType{T: t})
+	}
+	if err := convertIndexParams(spec, &idx.Attrs); err != nil {
+		return nil, err
+	}
+	if err := convertTablespace(spec, &idx.Attrs); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// convertIndexParams converts the spec storage parameters of an index to a schema attribute.
+func convertIndexParams(spec specutil.Attrer, attrs *[]schema.Attr) error {
+	var (
+		set bool
+		p   IndexStorageParams
+	)
+	for _, k := range []string{"fillfactor", "pages_per_range"} {
+		attr, ok := spec.Attr(k)
+		if !ok {
+			continue
+		}
+		v, err := attr.Int()
+		if err != nil {
+			return err
+		}
+		if k == "fillfactor" {
+			p.FillFactor = int64(v)
+		} else {
+			p.PagesPerRange = int64(v)
+		}
+		set = true
+	}
+	if attr, ok := spec.Attr("autosummarize"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return err
+		}
+		p.AutoSummarize, set = b, true
+	}
+	if attr, ok := spec.Attr("fastupdate"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return err
+		}
+		p.FastUpdate, set = &b, true
+	}
+	if set {
+		*attrs = append(*attrs, &p)
+	}
+	return nil
+}
+
+// convertTablespace converts the spec "tablespace" attribute to a schema element attribute.
+func convertTablespace(spec specutil.Attrer, attrs *[]schema.Attr) error {
+	if attr, ok := spec.Attr("tablespace"); ok {
+		s, err := attr.String()
+		if err != nil {
+			return err
+		}
+		*attrs = append(*attrs, &Tablespace{N: s})
+	}
+	return nil
 }
 
 // convertColumn converts a sqlspec.Column into a schema.Column.
@@ -296,14 +416,57 @@ func schemaSpec(schem *schema.Schema) (*doc, error) {
 
 // tableSpec converts from a concrete Postgres sqlspec.Table to a schema.Table.
 func tableSpec(tab *schema.Table) (*sqlspec.Table, error) {
-	return specutil.FromTable(
+	t, err := specutil.FromTable(
 		tab,
 		columnSpec,
 		specutil.FromPrimaryKey,
-		specutil.FromIndex,
+		indexSpec,
 		specutil.FromForeignKey,
 		specutil.FromCheck,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if ts := (Tablespace{}); sqlx.Has(tab.Attrs, &ts) {
+		t.Extra.Attrs = append(t.Extra.Attrs, specutil.StrAttr("tablespace", ts.N))
+	}
+	p := tableParams(tab.Attrs)
+	for _, n := range tableParamNames {
+		if v, ok := p.Value(n); ok {
+			t.Extra.Attrs = append(t.Extra.Attrs, specutil.LitAttr(n, v))
+		}
+	}
+	return t, nil
+}
+
+// indexSpec converts from a concrete Postgres schema.Index into a sqlspec.Index.
+func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
+	spec, err := specutil.FromIndex(idx)
+	if err != nil {
+		return nil, err
+	}
+	// Avoid printing the default method.
+	if t := (IndexType{}); sqlx.Has(idx.Attrs, &t) && indexType(idx.Attrs) != "BTREE" {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.StrAttr("type", strings.ToUpper(t.T)))
+	}
+	if p := (IndexStorageParams{}); sqlx.Has(idx.Attrs, &p) {
+		if p.FillFactor > 0 {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.LitAttr("fillfactor", strconv.FormatInt(p.FillFactor, 10)))
+		}
+		if p.PagesPerRange > 0 {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.LitAttr("pages_per_range", strconv.FormatInt(p.PagesPerRange, 10)))
+		}
+		if p.AutoSummarize {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("autosummarize", true))
+		}
+		if p.FastUpdate != nil {
+			spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("fastupdate", *p.FastUpdate))
+		}
+	}
+	if ts := (Tablespace{}); sqlx.Has(idx.Attrs, &ts) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.StrAttr("tablespace", ts.N))
+	}
+	return spec, nil
 }
 
 // columnSpec converts from a concrete Postgres schema.Column into a sqlspec.Column.
@@ -327,6 +490,21 @@ func columnTypeSpec(t schema.Type) (*sqlspec.Column, error) {
 	return &sqlspec.Column{Type: st}, nil
 }
 
+// registerSpec exposes the custom type to schema specs, if it is not already
+// defined there. For example, "double precision" is named "double_precision".
+func registerSpec(name string) error {
+	for _, s := range TypeRegistry.Specs() {
+		if strings.EqualFold(s.T, name) {
+			return nil
+		}
+	}
+	if err := TypeRegistry.Register(specutil.AliasTypeSpec(strings.ReplaceAll(name, " ", "_"), name)); err != nil {
+		return fmt.Errorf("postgres: registering custom type %q: %w", name, err)
+	}
+	hclState = schemahcl.New(schemahcl.WithTypes(TypeRegistry.Specs()))
+	return nil
+}
+
 // TypeRegistry contains the supported TypeSpecs for the Postgres driver.
 var TypeRegistry = specutil.NewRegistry(
 	specutil.WithFormatter(FormatType),
@@ -404,6 +582,18 @@ var TypeRegistry = specutil.NewRegistry(
 		specutil.TypeSpec(TypeJSONB),
 		specutil.TypeSpec(TypeUUID),
 		specutil.TypeSpec(TypeMoney),
+		specutil.TypeSpec(TypeInt4Range),
+		specutil.TypeSpec(TypeInt4MultiRange),
+		specutil.TypeSpec(TypeInt8Range),
+		specutil.TypeSpec(TypeInt8MultiRange),
+		specutil.TypeSpec(TypeNumRange),
+		specutil.TypeSpec(TypeNumMultiRange),
+		specutil.TypeSpec(TypeTSRange),
+		specutil.TypeSpec(TypeTSMultiRange),
+		specutil.TypeSpec(TypeTSTZRange),
+		specutil.TypeSpec(TypeTSTZMultiRange),
+		specutil.TypeSpec(TypeDateRange),
+		specutil.TypeSpec(TypeDateMultiRange),
 		specutil.TypeSpec("hstore"),
 		specutil.TypeSpec("sql", specutil.WithAttributes(&schemaspec.TypeAttr{Name: "def", Required: true, Kind: reflect.String})),
 	),
@@ -260,6 +260,125 @@ enum "account_type" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestSpec_Tablespace(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `table "users" {
+  schema     = schema.public
+  tablespace = "fast"
+  column "id" {
+    null = false
+    type = integer
+  }
+  index "users_id" {
+    columns    = [table.users.column.id]
+    tablespace = "slow"
+  }
+}
+schema "public" {
+}
+`
+	)
+	err := UnmarshalHCL([]byte(f), &s)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{&Tablespace{N: "fast"}}, s.Tables[0].Attrs)
+	require.Equal(t, []schema.Attr{&Tablespace{N: "slow"}}, s.Tables[0].Indexes[0].Attrs)
+	buf, err := MarshalSpec(&s, hclState)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+}
+
+func TestSpec_IndexMethod(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `table "logs" {
+  schema = schema.public
+  column "id" {
+    null = false
+    type = integer
+  }
+  column "tags" {
+    null = false
+    type = sql("text[]")
+  }
+  index "logs_id" {
+    columns         = [table.logs.column.id]
+    type            = "BRIN"
+    pages_per_range = 32
+    autosummarize   = true
+  }
+  index "logs_tags" {
+    columns    = [table.logs.column.tags]
+    type       = "GIN"
+    fastupdate = false
+  }
+  index "logs_id_hash" {
+    columns    = [table.logs.column.id]
+    type       = "HASH"
+    fillfactor = 60
+  }
+}
+schema "public" {
+}
+`
+	)
+	err := UnmarshalHCL([]byte(f), &s)
+	require.NoError(t, err)
+	off := false
+	require.Equal(t, []schema.Attr{&IndexType{T: "BRIN"}, &IndexStorageParams{PagesPerRange: 32, AutoSummarize: true}}, s.Tables[0].Indexes[0].Attrs)
+	require.Equal(t, []schema.Attr{&IndexType{T: "GIN"}, &IndexStorageParams{FastUpdate: &off}}, s.Tables[0].Indexes[1].Attrs)
+	require.Equal(t, []schema.Attr{&IndexType{T: "HASH"}, &IndexStorageParams{FillFactor: 60}}, s.Tables[0].Indexes[2].Attrs)
+	buf, err := MarshalSpec(&s, hclState)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+}
+
+func TestSpec_TableStorageParams(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `table "events" {
+  schema                         = schema.public
+  fillfactor                     = 70
+  autovacuum_enabled             = true
+  autovacuum_vacuum_scale_factor = 0.01
+  column "id" {
+    null = false
+    type = integer
+  }
+}
+schema "public" {
+}
+`
+	)
+	err := UnmarshalHCL([]byte(f), &s)
+	require.NoError(t, err)
+	var (
+		ff = int64(70)
+		on = true
+		sf = 0.01
+	)
+	require.Equal(t, []schema.Attr{&TableStorageParams{FillFactor: &ff, AutovacuumEnabled: &on, AutovacuumVacuumScaleFactor: &sf}}, s.Tables[0].Attrs)
+	buf, err := MarshalSpec(&s, hclState)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+}
+
+func TestSpec_SchemaComment(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `schema "public" {
+  comment = "default schema"
+}
+`
+	)
+	err := UnmarshalHCL([]byte(f), &s)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "default schema"}}, s.Attrs)
+	buf, err := MarshalSpec(&s, hclState)
+	require.NoError(t, err)
+	require.Equal(t, f, string(buf))
+}
+
 func TestMarshalSpec_TimePrecision(t *testing.T) {
 	s := schema.New("test").
 		AddTables(
@@ -562,11 +681,11 @@ func TestTypes(t *testing.T) {
 		},
 		{
 			typeExpr: `sql("text[][]")`,
-			expected: &ArrayType{T: "text[]"},
+			expected: &ArrayType{T: "text[][]"},
 		},
 		{
 			typeExpr: `sql("integer [3][3]")`,
-			expected: &ArrayType{T: "integer[]"},
+			expected: &ArrayType{T: "integer[][]"},
 		},
 		{
 			typeExpr: `sql("integer ARRAY[4]")`,
@@ -576,6 +695,18 @@ func TestTypes(t *testing.T) {
 			typeExpr: `sql("integer ARRAY")`,
 			expected: &ArrayType{T: "integer[]"},
 		},
+		{
+			typeExpr: `sql("character varying[]")`,
+			expected: &ArrayType{T: "character varying[]"},
+		},
+		{
+			typeExpr: "tstzrange",
+			expected: &RangeType{T: TypeTSTZRange},
+		},
+		{
+			typeExpr: "int8multirange",
+			expected: &RangeType{T: TypeInt8MultiRange},
+		},
 	} {
 		t.Run(tt.typeExpr, func(t *testing.T) {
 			var test schema.Schema
@@ -606,3 +737,72 @@ schema "test" {
 func TestRegistrySanity(t *testing.T) {
 	spectest.RegistrySanityTest(t, TypeRegistry, []string{"enum"})
 }
+
+type vectorType struct {
+	schema.Type
+	Dim int
+}
+
+func TestRegisterType(t *testing.T) {
+	err := RegisterType("vector", func(raw string) (schema.Type, error) {
+		var v vectorType
+		if _, err := fmt.Sscanf(raw, "vector(%d)", &v.Dim); err != nil && raw != "vector" {
+			return nil, err
+		}
+		return &v, nil
+	}, func(t schema.Type) (string, bool) {
+		v, ok := t.(*vectorType)
+		if !ok {
+			return "", false
+		}
+		if v.Dim == 0 {
+			return "vector", true
+		}
+		return fmt.Sprintf("vector(%d)", v.Dim), true
+	})
+	require.NoError(t, err)
+	require.Error(t, RegisterType("", nil, nil))
+
+	typ, err := ParseType("vector(3)")
+	require.NoError(t, err)
+	require.Equal(t, &vectorType{Dim: 3}, typ)
+	f, err := FormatType(typ)
+	require.NoError(t, err)
+	require.Equal(t, "vector(3)", f)
+	_, err = ParseType("vector(x)")
+	require.Error(t, err)
+
+	d := &diff{}
+	for _, tt := range []struct {
+		from, to schema.Type
+		changed  bool
+	}{
+		{from: &vectorType{Dim: 3}, to: &vectorType{Dim: 3}},
+		{from: &vectorType{Dim: 3}, to: &vectorType{Dim: 4}, changed: true},
+		{from: &vectorType{Dim: 3}, to: &UserDefinedType{T: "vector"}, changed: true},
+	} {
+		changed, err := d.typeChanged(
+			&schema.Column{Name: "c", Type: &schema.ColumnType{Type: tt.from}},
+			&schema.Column{Name: "c", Type: &schema.ColumnType{Type: tt.to}},
+		)
+		require.NoError(t, err)
+		require.Equal(t, tt.changed, changed)
+	}
+
+	var s schema.Schema
+	err = UnmarshalHCL([]byte(`
+schema "test" {}
+table "t" {
+	schema = schema.test
+	column "c1" {
+		type = vector
+	}
+	column "c2" {
+		type = sql("vector(3)")
+	}
+}
+`), &s)
+	require.NoError(t, err)
+	require.Equal(t, &vectorType{}, s.Tables[0].Columns[0].Type.Type)
+	require.Equal(t, &vectorType{Dim: 3}, s.Tables[0].Columns[1].Type.Type)
+}
@@ -0,0 +1,85 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"reflect"
+
+	"ariga.io/atlas/sql/typedoc"
+)
+
+// Parameters that are shared by the types in TypeDocs.
+var (
+	timePrecisionParam = &typedoc.Param{Name: "precision", Kind: reflect.Int, Max: 6}
+	charSizeParam      = &typedoc.Param{Name: "size", Kind: reflect.Int, Max: 10485760}
+	bitLenParam        = &typedoc.Param{Name: "len", Kind: reflect.Int64, Max: 83886080}
+)
+
+// TypeDocs describes the column types that are supported by PostgreSQL.
+var TypeDocs = &typedoc.Dialect{
+	Name: "postgres",
+	Types: []*typedoc.Type{
+		{Name: TypeBoolean, Aliases: []string{TypeBool}, Category: typedoc.CategoryBool, Doc: "A logical Boolean (true/false)."},
+		{Name: TypeSmallInt, Aliases: []string{TypeInt2}, Category: typedoc.CategoryInteger, Doc: "A signed 2-byte integer."},
+		{Name: TypeInteger, Aliases: []string{TypeInt, TypeInt4}, Category: typedoc.CategoryInteger, Doc: "A signed 4-byte integer."},
+		{Name: TypeBigInt, Aliases: []string{TypeInt8}, Category: typedoc.CategoryInteger, Doc: "A signed 8-byte integer."},
+		{Name: TypeSmallSerial, Aliases: []string{TypeSerial2}, Category: typedoc.CategoryInteger, Doc: "An autoincrementing 2-byte integer. Inspected as smallint with a sequence default."},
+		{Name: TypeSerial, Aliases: []string{TypeSerial4}, Category: typedoc.CategoryInteger, Doc: "An autoincrementing 4-byte integer. Inspected as integer with a sequence default."},
+		{Name: TypeBigSerial, Aliases: []string{TypeSerial8}, Category: typedoc.CategoryInteger, Doc: "An autoincrementing 8-byte integer. Inspected as bigint with a sequence default."},
+		{
+			Name:     TypeNumeric,
+			Aliases:  []string{TypeDecimal},
+			Category: typedoc.CategoryDecimal,
+			Params: []*typedoc.Param{
+				{Name: "precision", Kind: reflect.Int, Max: 1000},
+				{Name: "scale", Kind: reflect.Int, Max: 1000},
+			},
+			Doc: "An exact numeric of selectable precision.",
+		},
+		{Name: TypeReal, Aliases: []string{TypeFloat4}, Category: typedoc.CategoryFloat, Doc: "A single precision floating-point number (4 bytes)."},
+		{Name: TypeDouble, Aliases: []string{TypeFloat8, "float"}, Category: typedoc.CategoryFloat, Doc: "A double precision floating-point number (8 bytes)."},
+		{Name: TypeMoney, Category: typedoc.CategoryDecimal, Doc: "A currency amount."},
+		{Name: TypeCharacter, Aliases: []string{TypeChar}, Category: typedoc.CategoryString, Params: []*typedoc.Param{charSizeParam}, Doc: "A fixed-length, blank padded string."},
+		{Name: TypeCharVar, Aliases: []string{TypeVarChar}, Category: typedoc.CategoryString, Params: []*typedoc.Param{charSizeParam}, Doc: "A variable-length string with an optional limit."},
+		{Name: TypeText, Category: typedoc.CategoryString, Doc: "A variable-length string with an unlimited length."},
+		{Name: TypeBytea, Category: typedoc.CategoryBinary, Doc: "A variable-length binary string."},
+		{Name: TypeBit, Category: typedoc.CategoryBit, Params: []*typedoc.Param{bitLenParam}, Doc: "A fixed-length bit string."},
+		{Name: TypeBitVar, Aliases: []string{"varbit"}, Category: typedoc.CategoryBit, Params: []*typedoc.Param{bitLenParam}, Doc: "A variable-length bit string."},
+		{Name: TypeDate, Category: typedoc.CategoryTime, Doc: "A calendar date (year, month, day)."},
+		{Name: TypeTimeWOTZ, Aliases: []string{TypeTime}, Category: typedoc.CategoryTime, Params: []*typedoc.Param{timePrecisionParam}, Doc: "A time of day without a time zone."},
+		{Name: TypeTimeWTZ, Aliases: []string{"timetz"}, Category: typedoc.CategoryTime, Params: []*typedoc.Param{timePrecisionParam}, Doc: "A time of day, including a time zone."},
+		{Name: TypeTimestampWOTZ, Aliases: []string{TypeTimestamp}, Category: typedoc.CategoryTime, Params: []*typedoc.Param{timePrecisionParam}, Doc: "A date and time without a time zone."},
+		{Name: TypeTimestampWTZ, Aliases: []string{TypeTimestampTZ}, Category: typedoc.CategoryTime, Params: []*typedoc.Param{timePrecisionParam}, Doc: "A date and time, including a time zone."},
+		{Name: TypeInterval, Category: typedoc.CategoryTime, Params: []*typedoc.Param{timePrecisionParam}, Doc: "A time span."},
+		{Name: TypeJSON, Category: typedoc.CategoryJSON, Doc: "A textual JSON data."},
+		{Name: TypeJSONB, Category: typedoc.CategoryJSON, Availability: []*typedoc.Availability{{Since: "9.4"}}, Doc: "A binary JSON data, decomposed."},
+		{Name: TypeUUID, Category: typedoc.CategoryUUID, Doc: "A universally unique identifier."},
+		{Name: TypeXML, Category: typedoc.CategoryOther, Doc: "An XML data."},
+		{Name: TypeCIDR, Category: typedoc.CategoryNetwork, Doc: "An IPv4 or IPv6 network address."},
+		{Name: TypeInet, Category: typedoc.CategoryNetwork, Doc: "An IPv4 or IPv6 host address."},
+		{Name: TypeMACAddr, Category: typedoc.CategoryNetwork, Doc: "A MAC (Media Access Control) address."},
+		{Name: TypeMACAddr8, Category: typedoc.CategoryNetwork, Availability: []*typedoc.Availability{{Since: "10"}}, Doc: "A MAC (Media Access Control) address in EUI-64 format."},
+		{Name: TypePoint, Category: typedoc.CategorySpatial, Doc: "A geometric point on a plane."},
+		{Name: TypeLine, Category: typedoc.CategorySpatial, Doc: "An infinite line on a plane."},
+		{Name: TypeLseg, Category: typedoc.CategorySpatial, Doc: "A line segment on a plane."},
+		{Name: TypeBox, Category: typedoc.CategorySpatial, Doc: "A rectangular box on a plane."},
+		{Name: TypePath, Category: typedoc.CategorySpatial, Doc: "A geometric path on a plane."},
+		{Name: TypePolygon, Category: typedoc.CategorySpatial, Doc: "A polygon on a plane."},
+		{Name: TypeCircle, Category: typedoc.CategorySpatial, Doc: "A circle on a plane."},
+		{Name: TypeInt4Range, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "9.2"}}, Doc: "A range of integer."},
+		{Name: TypeInt8Range, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "9.2"}}, Doc: "A range of bigint."},
+		{Name: TypeNumRange, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "9.2"}}, Doc: "A range of numeric."},
+		{Name: TypeTSRange, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "9.2"}}, Doc: "A range of timestamp without time zone."},
+		{Name: TypeTSTZRange, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "9.2"}}, Doc: "A range of timestamp with time zone."},
+		{Name: TypeDateRange, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "9.2"}}, Doc: "A range of date."},
+		{Name: TypeInt4MultiRange, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "14"}}, Doc: "A multirange of integer."},
+		{Name: TypeInt8MultiRange, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "14"}}, Doc: "A multirange of bigint."},
+		{Name: TypeNumMultiRange, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "14"}}, Doc: "A multirange of numeric."},
+		{Name: TypeTSMultiRange, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "14"}}, Doc: "A multirange of timestamp without time zone."},
+		{Name: TypeTSTZMultiRange, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "14"}}, Doc: "A multirange of timestamp with time zone."},
+		{Name: TypeDateMultiRange, Category: typedoc.CategoryRange, Availability: []*typedoc.Availability{{Since: "14"}}, Doc: "A multirange of date."},
+		{Name: "hstore", Category: typedoc.CategoryOther, Doc: "A set of key/value pairs. Requires the hstore extension."},
+	},
+}
@@ -0,0 +1,335 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package redact provides an API for anonymizing schemas, in order to share
+// them (e.g. in bug reports) without exposing their design. The names of the
+// schemas, tables, columns, indexes and foreign keys are replaced with opaque
+// identifiers, and elements that may hold proprietary text are removed.
+//
+// The replaced names are recorded in a Mapping, that can be written to a file
+// and used later to restore the original names of the anonymized elements.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+)
+
+// List of element kinds that are recorded in a Mapping.
+const (
+	KindSchema     = "schema"
+	KindTable      = "table"
+	KindColumn     = "column"
+	KindIndex      = "index"
+	KindForeignKey = "foreign_key"
+)
+
+type (
+	// Mapping holds the original names of the elements that were anonymized.
+	Mapping struct {
+		Entries []*Entry `json:"entries"`
+	}
+
+	// An Entry maps the opaque name of an element to its original name.
+	Entry struct {
+		// Kind of the element. One of the Kind constants.
+		Kind string `json:"kind"`
+		// Schema and Table are the opaque names of the schema and the table
+		// that contain the element. Table is empty for schema-level elements.
+		Schema string `json:"schema,omitempty"`
+		Table  string `json:"table,omitempty"`
+		// Name is the opaque name of the element, and Original is its name
+		// before the anonymization.
+		Name     string `json:"name"`
+		Original string `json:"original"`
+	}
+)
+
+// Realm returns an anonymized copy of the given realm, and the mapping between
+// the opaque names of its elements and their original names. The given realm is
+// not modified. For example:
+//
+//	r, err := drv.InspectRealm(ctx, nil)
+//	if err != nil {
+//		return err
+//	}
+//	anon, m := redact.Realm(r)
+//	f, err := os.Create("mapping.json")
+//	if err != nil {
+//		return err
+//	}
+//	defer f.Close()
+//	if _, err := m.WriteTo(f); err != nil {
+//		return err
+//	}
+//
+// Besides renaming, the following elements are removed from the copy, as they may
+// contain identifiers or literals: comments, CHECK constraints, partial index
+// predicates, expression indexes, the CREATE statements recorded on inspection,
+// and column defaults that hold literals (e.g. 'active', or nextval('users_id_seq')).
+// Column types (including the values of ENUM types) are kept as is.
+func Realm(r *schema.Realm) (*schema.Realm, *Mapping) {
+	anon := schema.Clone(r)
+	if anon == nil {
+		return nil, &Mapping{}
+	}
+	a := &anonymizer{
+		Mapping: &Mapping{},
+		tables:  make(map[*schema.Table]*schema.Table),
+	}
+	// Map the tables of the given realm to their copies, as the driver
+	// attributes that reference tables (e.g. Inherits) are copied shallowly.
+	for i, s := range r.Schemas {
+		for j, t := range s.Tables {
+			a.tables[t] = anon.Schemas[i].Tables[j]
+		}
+	}
+	anon.Attrs = redactAttrs(anon.Attrs)
+	for _, s := range anon.Schemas {
+		a.schema(s)
+	}
+	return anon, a.Mapping
+}
+
+// anonymizer holds the state of an anonymization.
+type anonymizer struct {
+	*Mapping
+	tables map[*schema.Table]*schema.Table
+	// Counters of the named elements. Names of tables, indexes and foreign keys
+	// are unique in the realm, as some databases require uniqueness in schema scope.
+	ns, nt, ni, nfk int
+}
+
+// rename records the original name of the element and returns its new name.
+func (a *anonymizer) rename(e *Entry, prefix string, n int) string {
+	e.Original, e.Name = e.Name, fmt.Sprintf("%s%d", prefix, n)
+	a.Entries = append(a.Entries, e)
+	return e.Name
+}
+
+func (a *anonymizer) schema(s *schema.Schema) {
+	a.ns++
+	s.Name = a.rename(&Entry{Kind: KindSchema, Name: s.Name}, "schema", a.ns)
+	s.Attrs = redactAttrs(s.Attrs)
+	for _, t := range s.Tables {
+		a.nt++
+		t.Name = a.rename(&Entry{Kind: KindTable, Schema: s.Name, Name: t.Name}, "table", a.nt)
+	}
+	for _, t := range s.Tables {
+		a.table(t)
+	}
+}
+
+func (a *anonymizer) table(t *schema.Table) {
+	columns := make(map[string]string, len(t.Columns))
+	for i, c := range t.Columns {
+		name := a.rename(&Entry{Kind: KindColumn, Schema: t.Schema.Name, Table: t.Name, Name: c.Name}, "column", i+1)
+		columns[c.Name] = name
+		c.Name = name
+		c.Attrs = redactAttrs(c.Attrs)
+		if literalDefault(c.Default) {
+			c.Default = nil
+		}
+	}
+	indexes := make([]*schema.Index, 0, len(t.Indexes))
+	for _, idx := range t.Indexes {
+		if !exprIndex(idx) {
+			a.index(t, idx)
+			indexes = append(indexes, idx)
+			continue
+		}
+		for _, p := range idx.Parts {
+			if p.C != nil {
+				p.C.Indexes = removeIndex(p.C.Indexes, idx)
+			}
+		}
+	}
+	t.Indexes = indexes
+	if pk := t.PrimaryKey; pk != nil && !hasIndex(t, pk) {
+		a.index(t, pk)
+	}
+	for _, fk := range t.ForeignKeys {
+		if fk.Symbol != "" {
+			a.nfk++
+			fk.Symbol = a.rename(&Entry{Kind: KindForeignKey, Schema: t.Schema.Name, Table: t.Name, Name: fk.Symbol}, "fk", a.nfk)
+		}
+	}
+	attrs := redactAttrs(t.Attrs)
+	for i := range attrs {
+		switch at := attrs[i].(type) {
+		case *mysql.SystemVersioned:
+			attrs[i] = &mysql.SystemVersioned{Start: columns[at.Start], End: columns[at.End]}
+		case *postgres.Inherits:
+			parents := make([]*schema.Table, len(at.T))
+			for j, p := range at.T {
+				if parents[j] = p; a.tables[p] != nil {
+					parents[j] = a.tables[p]
+				}
+			}
+			attrs[i] = &postgres.Inherits{T: parents}
+		case *postgres.DistStyle:
+			attrs[i] = &postgres.DistStyle{S: at.S, Key: columns[at.Key]}
+		case *postgres.SortKey:
+			keys := make([]string, len(at.Columns))
+			for j, c := range at.Columns {
+				keys[j] = columns[c]
+			}
+			attrs[i] = &postgres.SortKey{Columns: keys, Interleaved: at.Interleaved}
+		}
+	}
+	t.Attrs = attrs
+}
+
+func (a *anonymizer) index(t *schema.Table, idx *schema.Index) {
+	idx.Attrs = redactAttrs(idx.Attrs)
+	for _, p := range idx.Parts {
+		p.Attrs = redactAttrs(p.Attrs)
+	}
+	// Primary keys that are named by the database are not renamed.
+	if idx.Name == "" || idx.Name == "PRIMARY" {
+		return
+	}
+	a.ni++
+	idx.Name = a.rename(&Entry{Kind: KindIndex, Schema: t.Schema.Name, Table: t.Name, Name: idx.Name}, "index", a.ni)
+}
+
+// Original returns the original name of the element with the given kind and opaque name.
+// The schemaName and table arguments are the opaque names of the schema and the table that
+// contain the element, and are empty for elements that are not contained in one.
+func (m *Mapping) Original(kind, schemaName, table, name string) (string, bool) {
+	for _, e := range m.Entries {
+		if e.Kind == kind && e.Schema == schemaName && e.Table == table && e.Name == name {
+			return e.Original, true
+		}
+	}
+	return "", false
+}
+
+// Restore renames the elements of the given realm, that was anonymized by Realm
+// (or derived from a realm that was), back to their original names. The realm is
+// modified in place, and elements that are not in the mapping (e.g. elements that
+// were added after the anonymization) keep their names.
+func (m *Mapping) Restore(r *schema.Realm) {
+	for _, s := range r.Schemas {
+		sName := s.Name
+		if name, ok := m.Original(KindSchema, "", "", sName); ok {
+			s.Name = name
+		}
+		for _, t := range s.Tables {
+			tName := t.Name
+			if name, ok := m.Original(KindTable, sName, "", tName); ok {
+				t.Name = name
+			}
+			for _, c := range t.Columns {
+				if name, ok := m.Original(KindColumn, sName, tName, c.Name); ok {
+					c.Name = name
+				}
+			}
+			indexes := t.Indexes
+			if pk := t.PrimaryKey; pk != nil && !hasIndex(t, pk) {
+				indexes = append(indexes[:len(indexes):len(indexes)], pk)
+			}
+			for _, idx := range indexes {
+				if name, ok := m.Original(KindIndex, sName, tName, idx.Name); ok {
+					idx.Name = name
+				}
+			}
+			for _, fk := range t.ForeignKeys {
+				if name, ok := m.Original(KindForeignKey, sName, tName, fk.Symbol); ok {
+					fk.Symbol = name
+				}
+			}
+		}
+	}
+}
+
+// WriteTo writes the mapping to w in JSON format. It implements the io.WriterTo interface.
+func (m *Mapping) WriteTo(w io.Writer) (int64, error) {
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(buf, '\n'))
+	return int64(n), err
+}
+
+// ReadMapping reads a mapping that was written by Mapping.WriteTo.
+func ReadMapping(r io.Reader) (*Mapping, error) {
+	m := &Mapping{}
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("redact: decoding mapping: %w", err)
+	}
+	for _, e := range m.Entries {
+		switch e.Kind {
+		case KindSchema, KindTable, KindColumn, KindIndex, KindForeignKey:
+		default:
+			return nil, fmt.Errorf("redact: unexpected element kind %q in mapping", e.Kind)
+		}
+	}
+	return m, nil
+}
+
+// redactAttrs returns the attributes without the ones that may contain
+// identifiers or literals, such as comments and CHECK constraints.
+func redactAttrs(attrs []schema.Attr) []schema.Attr {
+	var kept []schema.Attr
+	for _, a := range attrs {
+		switch a.(type) {
+		case *schema.Comment, *schema.Check, *postgres.CheckColumns,
+			*mysql.CreateStmt, *sqlite.CreateStmt, *sqlite.File,
+			*postgres.IndexPredicate, *sqlite.IndexPredicate:
+		default:
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// literalDefault reports if the default value of a column holds a literal.
+func literalDefault(x schema.Expr) bool {
+	switch x := x.(type) {
+	case *schema.Literal:
+		return true
+	case *schema.RawExpr:
+		return strings.ContainsAny(x.X, `'"`)
+	default:
+		return false
+	}
+}
+
+// exprIndex reports if the index has expression parts.
+func exprIndex(idx *schema.Index) bool {
+	for _, p := range idx.Parts {
+		if p.X != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func hasIndex(t *schema.Table, idx *schema.Index) bool {
+	for _, i := range t.Indexes {
+		if i == idx {
+			return true
+		}
+	}
+	return false
+}
+
+func removeIndex(indexes []*schema.Index, idx *schema.Index) []*schema.Index {
+	kept := make([]*schema.Index, 0, len(indexes))
+	for _, i := range indexes {
+		if i != idx {
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
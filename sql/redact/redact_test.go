@@ -0,0 +1,96 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package redact_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/redact"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealm(t *testing.T) {
+	users := schema.NewTable("users").
+		SetComment("customers of the shop").
+		AddColumns(
+			schema.NewIntColumn("id", "bigint"),
+			schema.NewStringColumn("email", "varchar(255)").SetComment("login email"),
+			schema.NewStringColumn("tier", "varchar(32)").SetDefault(&schema.Literal{V: "'gold'"}),
+			schema.NewTimeColumn("created_at", "timestamp").SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP"}),
+		).
+		AddChecks(schema.NewCheck().SetName("tier_check").SetExpr("tier IN ('gold', 'silver')")).
+		AddAttrs(&mysql.CreateStmt{S: "CREATE TABLE users (...)"})
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	users.AddIndexes(
+		schema.NewUniqueIndex("users_email").AddColumns(users.Columns[1]),
+		schema.NewIndex("users_lower_email").AddExprs(&schema.RawExpr{X: "lower(email)"}),
+	)
+	orders := schema.NewTable("orders").
+		AddColumns(
+			schema.NewIntColumn("id", "bigint"),
+			schema.NewIntColumn("user_id", "bigint"),
+		)
+	orders.AddForeignKeys(schema.NewForeignKey("orders_user").AddColumns(orders.Columns[1]).SetRefTable(users).AddRefColumns(users.Columns[0]))
+	r := schema.NewRealm(schema.New("shop").AddTables(users, orders))
+
+	anon, m := redact.Realm(r)
+	require.Equal(t, "users", r.Schemas[0].Tables[0].Name, "original realm should not be modified")
+	require.Len(t, r.Schemas[0].Tables[0].Indexes, 2)
+
+	s := anon.Schemas[0]
+	require.Equal(t, "schema1", s.Name)
+	require.Equal(t, "table1", s.Tables[0].Name)
+	require.Equal(t, "table2", s.Tables[1].Name)
+	u := s.Tables[0]
+	require.Empty(t, u.Attrs, "comment, check and create statement should be removed")
+	require.Equal(t, []string{"column1", "column2", "column3", "column4"}, columnNames(u))
+	require.Empty(t, u.Columns[1].Attrs)
+	require.Nil(t, u.Columns[2].Default)
+	require.Equal(t, &schema.RawExpr{X: "CURRENT_TIMESTAMP"}, u.Columns[3].Default)
+	require.Len(t, u.Indexes, 1, "expression index should be removed")
+	require.Equal(t, "index1", u.Indexes[0].Name)
+	require.Equal(t, []*schema.Index{u.Indexes[0]}, u.Columns[1].Indexes)
+	require.Equal(t, u.Columns[0], u.PrimaryKey.Parts[0].C)
+	o := s.Tables[1]
+	require.Equal(t, "fk1", o.ForeignKeys[0].Symbol)
+	require.Equal(t, u, o.ForeignKeys[0].RefTable)
+
+	name, ok := m.Original(redact.KindColumn, "schema1", "table1", "column2")
+	require.True(t, ok)
+	require.Equal(t, "email", name)
+	_, ok = m.Original(redact.KindColumn, "schema1", "table2", "column3")
+	require.False(t, ok)
+
+	// Round-trip the mapping and restore the names.
+	var buf bytes.Buffer
+	_, err := m.WriteTo(&buf)
+	require.NoError(t, err)
+	require.NotContains(t, buf.String(), "gold")
+	m, err = redact.ReadMapping(&buf)
+	require.NoError(t, err)
+	m.Restore(anon)
+	require.Equal(t, "shop", s.Name)
+	require.Equal(t, "users", u.Name)
+	require.Equal(t, "orders", o.Name)
+	require.Equal(t, []string{"id", "email", "tier", "created_at"}, columnNames(u))
+	require.Equal(t, "users_email", u.Indexes[0].Name)
+	require.Equal(t, "orders_user", o.ForeignKeys[0].Symbol)
+
+	_, err = redact.ReadMapping(strings.NewReader(`{"entries": [{"kind": "view", "name": "v1", "original": "v"}]}`))
+	require.EqualError(t, err, `redact: unexpected element kind "view" in mapping`)
+}
+
+func columnNames(t *schema.Table) []string {
+	names := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		names[i] = c.Name
+	}
+	return names
+}
@@ -0,0 +1,124 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import "reflect"
+
+// FindAttr finds the first attribute in the list that has the same type as the target,
+// and if so, sets the target to its value and returns true. The target must be a non-nil
+// pointer to an attribute struct, and false is returned otherwise.
+//
+// The helpers in this file are based on reflection rather than type parameters, because
+// the module supports go1.17, which has no generics. For example:
+//
+//	var c schema.Comment
+//	if schema.FindAttr(t.Attrs, &c) {
+//		fmt.Println(c.Text)
+//	}
+//
+func FindAttr(attrs []Attr, target Attr) bool {
+	tv := reflect.ValueOf(target)
+	if tv.Kind() != reflect.Ptr || tv.IsNil() {
+		return false
+	}
+	for _, a := range attrs {
+		if av := reflect.ValueOf(a); a != nil && av.Type() == tv.Type() && !av.IsNil() {
+			tv.Elem().Set(av.Elem())
+			return true
+		}
+	}
+	return false
+}
+
+// ReplaceAttr replaces the first attribute in the list that has the same type as v,
+// or appends v to the list if there is no such attribute. Like append, it returns the
+// updated list, and the backing array of the given list may be modified. For example:
+//
+//	t.Attrs = schema.ReplaceAttr(t.Attrs, &schema.Comment{Text: "users table"})
+//
+func ReplaceAttr(attrs []Attr, v Attr) []Attr {
+	t := reflect.TypeOf(v)
+	for i := range attrs {
+		if reflect.TypeOf(attrs[i]) == t {
+			attrs[i] = v
+			return attrs
+		}
+	}
+	return append(attrs, v)
+}
+
+// RemoveAttr returns a new list without the attributes that have the same type
+// as v. The given list is not modified, and v can be a nil pointer of the type
+// to remove. For example:
+//
+//	t.Attrs = schema.RemoveAttr(t.Attrs, (*schema.Comment)(nil))
+//
+func RemoveAttr(attrs []Attr, v Attr) []Attr {
+	var (
+		rest []Attr
+		t    = reflect.TypeOf(v)
+	)
+	for _, a := range attrs {
+		if reflect.TypeOf(a) != t {
+			rest = append(rest, a)
+		}
+	}
+	return rest
+}
+
+// FindChange finds the first change in the list that has the type of the
+// value the target points to, and if so, sets the target to it and returns
+// true. The target must be a non-nil pointer to a change, and false is returned
+// otherwise. See FindAttr for why the target is not a type parameter. For example:
+//
+//	var add *schema.AddColumn
+//	if schema.FindChange(m.Changes, &add) {
+//		fmt.Println(add.C.Name)
+//	}
+//
+func FindChange(changes []Change, target interface{}) bool {
+	tv := reflect.ValueOf(target)
+	if tv.Kind() != reflect.Ptr || tv.IsNil() {
+		return false
+	}
+	for _, c := range changes {
+		if c != nil && reflect.TypeOf(c) == tv.Elem().Type() {
+			tv.Elem().Set(reflect.ValueOf(c))
+			return true
+		}
+	}
+	return false
+}
+
+// FilterChanges returns a new list with the changes that the given function
+// reports true for. The given list is not modified. For example:
+//
+//	drops := schema.FilterChanges(m.Changes, func(c schema.Change) bool {
+//		_, ok := c.(*schema.DropColumn)
+//		return ok
+//	})
+//
+func FilterChanges(changes []Change, f func(Change) bool) []Change {
+	var matched []Change
+	for _, c := range changes {
+		if f(c) {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+// RemoveChanges returns a new list without the changes that have the same
+// type as v. The given list is not modified, and v can be a nil pointer of
+// the type to remove. For example:
+//
+//	changes = schema.RemoveChanges(changes, (*schema.DropTable)(nil))
+//
+func RemoveChanges(changes []Change, v Change) []Change {
+	t := reflect.TypeOf(v)
+	return FilterChanges(changes, func(c Change) bool {
+		return reflect.TypeOf(c) != t
+	})
+}
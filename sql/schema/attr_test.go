@@ -0,0 +1,67 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindAttr(t *testing.T) {
+	attrs := []schema.Attr{nil, &schema.Charset{V: "utf8mb4"}, &schema.Comment{Text: "a"}, &schema.Comment{Text: "b"}}
+	var c schema.Comment
+	require.True(t, schema.FindAttr(attrs, &c))
+	require.Equal(t, "a", c.Text)
+	var cl schema.Collation
+	require.False(t, schema.FindAttr(attrs, &cl))
+	require.False(t, schema.FindAttr(attrs, (*schema.Comment)(nil)))
+	require.False(t, schema.FindAttr(attrs, nil))
+}
+
+func TestReplaceAttr(t *testing.T) {
+	attrs := []schema.Attr{&schema.Charset{V: "utf8mb4"}, &schema.Comment{Text: "a"}}
+	attrs = schema.ReplaceAttr(attrs, &schema.Comment{Text: "b"})
+	require.Equal(t, []schema.Attr{&schema.Charset{V: "utf8mb4"}, &schema.Comment{Text: "b"}}, attrs)
+	attrs = schema.ReplaceAttr(attrs, &schema.Collation{V: "utf8mb4_bin"})
+	require.Equal(t, []schema.Attr{&schema.Charset{V: "utf8mb4"}, &schema.Comment{Text: "b"}, &schema.Collation{V: "utf8mb4_bin"}}, attrs)
+}
+
+func TestRemoveAttr(t *testing.T) {
+	attrs := []schema.Attr{&schema.Comment{Text: "a"}, &schema.Charset{V: "utf8mb4"}, &schema.Comment{Text: "b"}}
+	rest := schema.RemoveAttr(attrs, (*schema.Comment)(nil))
+	require.Equal(t, []schema.Attr{&schema.Charset{V: "utf8mb4"}}, rest)
+	require.Len(t, attrs, 3, "original list should not be modified")
+	require.Empty(t, schema.RemoveAttr(rest, &schema.Charset{}))
+}
+
+func TestChanges(t *testing.T) {
+	var (
+		add     = &schema.AddColumn{C: schema.NewIntColumn("id", "int")}
+		drop    = &schema.DropColumn{C: schema.NewIntColumn("name", "int")}
+		changes = []schema.Change{drop, add, &schema.DropColumn{C: schema.NewIntColumn("age", "int")}}
+	)
+	var found *schema.AddColumn
+	require.True(t, schema.FindChange(changes, &found))
+	require.True(t, found == add)
+	var idx *schema.AddIndex
+	require.False(t, schema.FindChange(changes, &idx))
+	require.Nil(t, idx)
+	require.False(t, schema.FindChange(changes, found))
+	require.False(t, schema.FindChange(changes, (**schema.AddColumn)(nil)))
+	require.False(t, schema.FindChange(changes, nil))
+
+	rest := schema.RemoveChanges(changes, (*schema.DropColumn)(nil))
+	require.Equal(t, []schema.Change{add}, rest)
+	require.Len(t, changes, 3)
+	drops := schema.FilterChanges(changes, func(c schema.Change) bool {
+		_, ok := c.(*schema.DropColumn)
+		return ok
+	})
+	require.Len(t, drops, 2)
+	require.True(t, drops[0] == drop)
+}
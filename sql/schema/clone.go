@@ -0,0 +1,260 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import "reflect"
+
+// Clone returns a deep copy of the given realm. The pointers between the elements of
+// the copied graph (e.g. the columns of an index, or the referenced table of a foreign
+// key) point to their copies, and therefore, the returned realm can be mutated without
+// affecting the original one. Foreign keys that reference tables outside the realm keep
+// referencing them.
+//
+// Attributes, types and expressions that are defined outside this package (e.g. by the
+// drivers) are copied shallowly. That is, the struct they point to is copied, but the
+// values it holds (e.g. slices or pointers) are shared with the original realm.
+func Clone(r *Realm) *Realm {
+	if r == nil {
+		return nil
+	}
+	c := &cloner{
+		tables:  make(map[*Table]*Table),
+		columns: make(map[*Column]*Column),
+		indexes: make(map[*Index]*Index),
+		fks:     make(map[*ForeignKey]*ForeignKey),
+	}
+	return c.realm(r)
+}
+
+// A RealmSnapshot holds a copy of a realm, taken at a specific point in time,
+// that can be restored into it after the realm was mutated. For example:
+//
+//	snap := schema.Snapshot(desired)
+//	for _, fix := range fixes {
+//		fix(desired)
+//		if err := validate(desired); err != nil {
+//			snap.Restore()
+//		}
+//	}
+type RealmSnapshot struct {
+	realm, copy *Realm
+}
+
+// Snapshot takes a snapshot of the given realm.
+func Snapshot(r *Realm) *RealmSnapshot {
+	return &RealmSnapshot{realm: r, copy: Clone(r)}
+}
+
+// Realm returns a copy of the realm, as it was when the snapshot was taken.
+func (s *RealmSnapshot) Realm() *Realm {
+	return Clone(s.copy)
+}
+
+// Restore resets the realm to the state it had when the snapshot was taken, and
+// can be called more than once. Note that the realm is restored in place, but its
+// schemas and their elements are replaced with new copies. Hence, pointers to the
+// elements of the realm that were taken before the call should not be used after.
+func (s *RealmSnapshot) Restore() {
+	if s.realm == nil {
+		return
+	}
+	r := Clone(s.copy)
+	*s.realm = *r
+	for _, sc := range s.realm.Schemas {
+		sc.Realm = s.realm
+	}
+}
+
+// cloner copies the elements of a realm, and maps
+// each element of the original graph to its copy.
+type cloner struct {
+	tables  map[*Table]*Table
+	columns map[*Column]*Column
+	indexes map[*Index]*Index
+	fks     map[*ForeignKey]*ForeignKey
+}
+
+func (c *cloner) realm(r *Realm) *Realm {
+	nr := &Realm{Attrs: cloneAttrs(r.Attrs)}
+	// Allocate all elements first, as they reference each other.
+	for _, s := range r.Schemas {
+		ns := &Schema{Name: s.Name, Realm: nr, Attrs: cloneAttrs(s.Attrs)}
+		nr.Schemas = append(nr.Schemas, ns)
+		for _, t := range s.Tables {
+			nt := c.alloc(t)
+			nt.Schema = ns
+			ns.Tables = append(ns.Tables, nt)
+		}
+	}
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			c.link(t)
+		}
+	}
+	return nr
+}
+
+// alloc allocates the copies of the table and its elements.
+func (c *cloner) alloc(t *Table) *Table {
+	nt := &Table{Name: t.Name, Attrs: cloneAttrs(t.Attrs)}
+	c.tables[t] = nt
+	for _, col := range t.Columns {
+		nc := &Column{Name: col.Name, Default: cloneExpr(col.Default), Attrs: cloneAttrs(col.Attrs)}
+		if col.Type != nil {
+			nc.Type = &ColumnType{Type: cloneType(col.Type.Type), Raw: col.Type.Raw, Null: col.Type.Null}
+		}
+		c.columns[col] = nc
+		nt.Columns = append(nt.Columns, nc)
+	}
+	for _, idx := range t.Indexes {
+		nt.Indexes = append(nt.Indexes, c.allocIndex(idx, nt))
+	}
+	if pk := t.PrimaryKey; pk != nil {
+		if hasIndex(t, pk) {
+			nt.PrimaryKey = c.indexes[pk]
+		} else {
+			nt.PrimaryKey = c.allocIndex(pk, nt)
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		nfk := &ForeignKey{Symbol: fk.Symbol, Table: nt, OnUpdate: fk.OnUpdate, OnDelete: fk.OnDelete, Match: fk.Match}
+		c.fks[fk] = nfk
+		nt.ForeignKeys = append(nt.ForeignKeys, nfk)
+	}
+	return nt
+}
+
+func (c *cloner) allocIndex(idx *Index, t *Table) *Index {
+	nidx := &Index{Name: idx.Name, Unique: idx.Unique, Table: t, Attrs: cloneAttrs(idx.Attrs)}
+	c.indexes[idx] = nidx
+	return nidx
+}
+
+// link sets the references between the copied elements of the table.
+func (c *cloner) link(t *Table) {
+	nt := c.tables[t]
+	for i, col := range t.Columns {
+		nc := nt.Columns[i]
+		for _, idx := range col.Indexes {
+			nc.Indexes = append(nc.Indexes, c.index(idx))
+		}
+		for _, fk := range col.ForeignKeys {
+			nc.ForeignKeys = append(nc.ForeignKeys, c.fk(fk))
+		}
+	}
+	for _, idx := range t.Indexes {
+		c.parts(idx)
+	}
+	if pk := t.PrimaryKey; pk != nil && !hasIndex(t, pk) {
+		c.parts(pk)
+	}
+	for _, fk := range t.ForeignKeys {
+		nfk := c.fks[fk]
+		nfk.RefTable = c.table(fk.RefTable)
+		for _, col := range fk.Columns {
+			nfk.Columns = append(nfk.Columns, c.column(col))
+		}
+		for _, col := range fk.RefColumns {
+			nfk.RefColumns = append(nfk.RefColumns, c.column(col))
+		}
+	}
+}
+
+func (c *cloner) parts(idx *Index) {
+	nidx := c.indexes[idx]
+	for _, p := range idx.Parts {
+		np := &IndexPart{SeqNo: p.SeqNo, Desc: p.Desc, X: cloneExpr(p.X), Attrs: cloneAttrs(p.Attrs)}
+		if p.C != nil {
+			np.C = c.column(p.C)
+		}
+		nidx.Parts = append(nidx.Parts, np)
+	}
+}
+
+// The methods below return the copy of the given element,
+// or the element itself if it does not belong to the realm.
+
+func (c *cloner) table(t *Table) *Table {
+	if nt, ok := c.tables[t]; ok {
+		return nt
+	}
+	return t
+}
+
+func (c *cloner) column(col *Column) *Column {
+	if nc, ok := c.columns[col]; ok {
+		return nc
+	}
+	return col
+}
+
+func (c *cloner) index(idx *Index) *Index {
+	if nidx, ok := c.indexes[idx]; ok {
+		return nidx
+	}
+	return idx
+}
+
+func (c *cloner) fk(fk *ForeignKey) *ForeignKey {
+	if nfk, ok := c.fks[fk]; ok {
+		return nfk
+	}
+	return fk
+}
+
+// hasIndex reports if the index is one of the table indexes.
+func hasIndex(t *Table, idx *Index) bool {
+	for _, i := range t.Indexes {
+		if i == idx {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneAttrs(attrs []Attr) []Attr {
+	if attrs == nil {
+		return nil
+	}
+	nattrs := make([]Attr, len(attrs))
+	for i, a := range attrs {
+		switch a := a.(type) {
+		case *Check:
+			nattrs[i] = &Check{Name: a.Name, Expr: a.Expr, Attrs: cloneAttrs(a.Attrs)}
+		default:
+			nattrs[i], _ = shallowCopy(a).(Attr)
+		}
+	}
+	return nattrs
+}
+
+func cloneType(t Type) Type {
+	switch t := t.(type) {
+	case *EnumType:
+		return &EnumType{T: t.T, Values: append([]string(nil), t.Values...)}
+	case *IntegerType:
+		return &IntegerType{T: t.T, Unsigned: t.Unsigned, Attrs: cloneAttrs(t.Attrs)}
+	default:
+		nt, _ := shallowCopy(t).(Type)
+		return nt
+	}
+}
+
+func cloneExpr(x Expr) Expr {
+	nx, _ := shallowCopy(x).(Expr)
+	return nx
+}
+
+// shallowCopy returns a copy of the struct that v points to,
+// or v itself, if it does not hold a pointer to a struct.
+func shallowCopy(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return v
+	}
+	nv := reflect.New(rv.Elem().Type())
+	nv.Elem().Set(rv.Elem())
+	return nv.Interface()
+}
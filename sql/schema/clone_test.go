@@ -0,0 +1,92 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	r := cloneRealm()
+	c := schema.Clone(r)
+	require.Equal(t, r, c)
+	require.Equal(t, schema.Hash(r), schema.Hash(c))
+
+	users, _ := c.Schemas[0].Table("users")
+	posts, _ := c.Schemas[1].Table("posts")
+	require.True(t, users.Schema == c.Schemas[0])
+	require.True(t, c.Schemas[0].Realm == c)
+	require.True(t, users.PrimaryKey == users.Indexes[0])
+	require.True(t, users.Indexes[0].Table == users)
+	require.True(t, users.Indexes[0].Parts[0].C == users.Columns[0])
+	require.True(t, users.Columns[0].Indexes[0] == users.Indexes[0])
+	require.True(t, posts.ForeignKeys[0].RefTable == users)
+	require.True(t, posts.ForeignKeys[0].RefColumns[0] == users.Columns[0])
+	require.True(t, posts.ForeignKeys[0].Columns[0] == posts.Columns[1])
+	require.True(t, posts.Columns[1].ForeignKeys[0] == posts.ForeignKeys[0])
+
+	// Mutating the copy does not affect the original realm.
+	users.Columns[1].Type.Type.(*schema.StringType).Size = 512
+	users.Columns[1].SetComment("changed")
+	users.Attrs[0].(*schema.Comment).Text = "changed"
+	users.Indexes[0].Parts[0].C.Name = "uid"
+	require.Equal(t, &schema.StringType{T: "varchar", Size: 255}, r.Schemas[0].Tables[0].Columns[1].Type.Type)
+	require.Empty(t, r.Schemas[0].Tables[0].Columns[1].Attrs)
+	require.Equal(t, &schema.Comment{Text: "users table"}, r.Schemas[0].Tables[0].Attrs[0])
+	require.Equal(t, "id", r.Schemas[0].Tables[0].Columns[0].Name)
+	require.Nil(t, schema.Clone(nil))
+}
+
+func TestSnapshot(t *testing.T) {
+	r := cloneRealm()
+	h := schema.Hash(r)
+	snap := schema.Snapshot(r)
+	r.Schemas[0].Tables[0].AddColumns(schema.NewIntColumn("age", "int"))
+	r.Schemas = r.Schemas[:1]
+	require.NotEqual(t, h, schema.Hash(r))
+	for i := 0; i < 2; i++ {
+		snap.Restore()
+		require.Equal(t, h, schema.Hash(r))
+		require.Len(t, r.Schemas, 2)
+		require.True(t, r.Schemas[1].Realm == r)
+		r.Schemas[1].Tables = nil
+	}
+	c := snap.Realm()
+	require.Equal(t, h, schema.Hash(c))
+	require.False(t, c == r)
+}
+
+func cloneRealm() *schema.Realm {
+	users := schema.NewTable("users").
+		SetComment("users table").
+		AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewStringColumn("name", "varchar", schema.StringSize(255)),
+		)
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	users.Indexes = append(users.Indexes, users.PrimaryKey)
+	users.Columns[0].Indexes = append(users.Columns[0].Indexes, users.PrimaryKey)
+	posts := schema.NewTable("posts").
+		AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewIntColumn("author_id", "int"),
+			schema.NewEnumColumn("status", schema.EnumValues("draft", "published")),
+		)
+	posts.AddForeignKeys(
+		schema.NewForeignKey("author").
+			AddColumns(posts.Columns[1]).
+			SetRefTable(users).
+			AddRefColumns(users.Columns[0]).
+			SetOnDelete(schema.Cascade),
+	)
+	return schema.NewRealm(
+		schema.New("public").AddTables(users),
+		schema.New("blog").AddTables(posts),
+	)
+}
@@ -0,0 +1,381 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type (
+	// A DriftReport describes how the current state of a realm
+	// drifted from its desired state. Unlike the changes returned
+	// by a Differ, it does not describe how to fix the drift.
+	DriftReport struct {
+		// Missing holds the objects that exist in the desired state,
+		// but are missing from the current state.
+		Missing []*DriftObject
+
+		// Extra holds the objects that exist in the current state,
+		// but do not exist in the desired state.
+		Extra []*DriftObject
+
+		// Modified holds the attributes of objects that
+		// exist in both states, but with different values.
+		Modified []*DriftAttr
+	}
+
+	// A DriftObject identifies a schema object in a DriftReport.
+	DriftObject struct {
+		Kind ObjectKind
+		// Path holds the names of the object and its parents. For
+		// example, ["public", "users", "id"] for a column.
+		Path []string
+	}
+
+	// A DriftAttr describes an attribute of an object that was changed.
+	DriftAttr struct {
+		DriftObject
+		// Attr names the attribute. For example, "type", "null" or "comment".
+		Attr string
+		// Current and Desired hold the values of the attribute in the
+		// current and the desired states. An empty value means unset.
+		Current, Desired string
+	}
+
+	// ObjectKind describes the kind of a schema object.
+	ObjectKind string
+)
+
+// List of object kinds.
+const (
+	ObjectSchema     ObjectKind = "schema"
+	ObjectTable      ObjectKind = "table"
+	ObjectColumn     ObjectKind = "column"
+	ObjectIndex      ObjectKind = "index"
+	ObjectPrimaryKey ObjectKind = "primary key"
+	ObjectForeignKey ObjectKind = "foreign key"
+	ObjectCheck      ObjectKind = "check"
+)
+
+// Drift compares the current state of a realm with its desired state and returns a
+// report of the objects that are missing, extra or modified in the current state.
+//
+// The comparison is structural and does not depend on a specific dialect. Types and
+// expressions are compared as they are stored in the elements. Therefore, the states
+// are expected to be normalized the same way, for example, both were inspected from
+// a database, or the desired state was normalized by the driver before comparing.
+func Drift(current, desired *Realm) *DriftReport {
+	r := &DriftReport{}
+	if current == nil {
+		current = &Realm{}
+	}
+	if desired == nil {
+		desired = &Realm{}
+	}
+	r.attrs(DriftObject{Kind: ObjectSchema}, current.Attrs, desired.Attrs)
+	for _, d := range desired.Schemas {
+		c, ok := current.Schema(d.Name)
+		if !ok {
+			r.missing(ObjectSchema, d.Name)
+			continue
+		}
+		r.schema(c, d)
+	}
+	for _, c := range current.Schemas {
+		if _, ok := desired.Schema(c.Name); !ok {
+			r.extra(ObjectSchema, c.Name)
+		}
+	}
+	return r
+}
+
+// Empty reports if the report has no drift.
+func (r *DriftReport) Empty() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Modified) == 0
+}
+
+// String returns a multiline description of the report.
+func (r *DriftReport) String() string {
+	var b strings.Builder
+	for _, o := range r.Missing {
+		fmt.Fprintf(&b, "missing %s\n", o)
+	}
+	for _, o := range r.Extra {
+		fmt.Fprintf(&b, "extra %s\n", o)
+	}
+	for _, a := range r.Modified {
+		fmt.Fprintf(&b, "modified %s: %s %q (desired %q)\n", &a.DriftObject, a.Attr, a.Current, a.Desired)
+	}
+	return b.String()
+}
+
+// Name returns the qualified name of the object. e.g. "public.users.id".
+func (o *DriftObject) Name() string {
+	return strings.Join(o.Path, ".")
+}
+
+// String implements the fmt.Stringer interface.
+func (o *DriftObject) String() string {
+	if len(o.Path) == 0 {
+		return string(o.Kind)
+	}
+	return fmt.Sprintf("%s %q", o.Kind, o.Name())
+}
+
+func (r *DriftReport) missing(k ObjectKind, path ...string) {
+	r.Missing = append(r.Missing, &DriftObject{Kind: k, Path: path})
+}
+
+func (r *DriftReport) extra(k ObjectKind, path ...string) {
+	r.Extra = append(r.Extra, &DriftObject{Kind: k, Path: path})
+}
+
+func (r *DriftReport) modified(o DriftObject, attr, current, desired string) {
+	if current != desired {
+		r.Modified = append(r.Modified, &DriftAttr{DriftObject: o, Attr: attr, Current: current, Desired: desired})
+	}
+}
+
+func (r *DriftReport) schema(current, desired *Schema) {
+	r.attrs(DriftObject{Kind: ObjectSchema, Path: []string{desired.Name}}, current.Attrs, desired.Attrs)
+	for _, d := range desired.Tables {
+		c, ok := current.Table(d.Name)
+		if !ok {
+			r.missing(ObjectTable, desired.Name, d.Name)
+			continue
+		}
+		r.table(desired.Name, c, d)
+	}
+	for _, c := range current.Tables {
+		if _, ok := desired.Table(c.Name); !ok {
+			r.extra(ObjectTable, desired.Name, c.Name)
+		}
+	}
+}
+
+func (r *DriftReport) table(s string, current, desired *Table) {
+	r.attrs(DriftObject{Kind: ObjectTable, Path: []string{s, desired.Name}}, current.Attrs, desired.Attrs)
+	for _, d := range desired.Columns {
+		c, ok := current.Column(d.Name)
+		if !ok {
+			r.missing(ObjectColumn, s, desired.Name, d.Name)
+			continue
+		}
+		r.column(DriftObject{Kind: ObjectColumn, Path: []string{s, desired.Name, d.Name}}, c, d)
+	}
+	for _, c := range current.Columns {
+		if _, ok := desired.Column(c.Name); !ok {
+			r.extra(ObjectColumn, s, desired.Name, c.Name)
+		}
+	}
+	switch c, d := current.PrimaryKey, desired.PrimaryKey; {
+	case c == nil && d != nil:
+		r.missing(ObjectPrimaryKey, s, desired.Name)
+	case c != nil && d == nil:
+		r.extra(ObjectPrimaryKey, s, desired.Name)
+	case c != nil && d != nil:
+		r.modified(DriftObject{Kind: ObjectPrimaryKey, Path: []string{s, desired.Name}}, "parts", driftParts(c), driftParts(d))
+	}
+	for _, d := range desired.Indexes {
+		c, ok := current.Index(d.Name)
+		if !ok {
+			r.missing(ObjectIndex, s, desired.Name, d.Name)
+			continue
+		}
+		o := DriftObject{Kind: ObjectIndex, Path: []string{s, desired.Name, d.Name}}
+		r.modified(o, "unique", strconv.FormatBool(c.Unique), strconv.FormatBool(d.Unique))
+		r.modified(o, "parts", driftParts(c), driftParts(d))
+		r.attrs(o, c.Attrs, d.Attrs)
+	}
+	for _, c := range current.Indexes {
+		if _, ok := desired.Index(c.Name); !ok {
+			r.extra(ObjectIndex, s, desired.Name, c.Name)
+		}
+	}
+	for _, d := range desired.ForeignKeys {
+		c, ok := current.ForeignKey(d.Symbol)
+		if !ok {
+			r.missing(ObjectForeignKey, s, desired.Name, d.Symbol)
+			continue
+		}
+		r.foreignKey(DriftObject{Kind: ObjectForeignKey, Path: []string{s, desired.Name, d.Symbol}}, c, d)
+	}
+	for _, c := range current.ForeignKeys {
+		if _, ok := desired.ForeignKey(c.Symbol); !ok {
+			r.extra(ObjectForeignKey, s, desired.Name, c.Symbol)
+		}
+	}
+	r.checks(s, desired.Name, current.Attrs, desired.Attrs)
+}
+
+func (r *DriftReport) column(o DriftObject, current, desired *Column) {
+	var (
+		ct, dt       = current.Type, desired.Type
+		cnull, dnull bool
+	)
+	if ct != nil {
+		cnull = ct.Null
+	}
+	if dt != nil {
+		dnull = dt.Null
+	}
+	if !driftTypesEqual(ct, dt) {
+		r.modified(o, "type", driftType(ct), driftType(dt))
+	}
+	r.modified(o, "null", strconv.FormatBool(cnull), strconv.FormatBool(dnull))
+	r.modified(o, "default", driftExpr(current.Default), driftExpr(desired.Default))
+	r.attrs(o, current.Attrs, desired.Attrs)
+}
+
+func (r *DriftReport) foreignKey(o DriftObject, current, desired *ForeignKey) {
+	r.modified(o, "columns", driftColumns(current.Columns), driftColumns(desired.Columns))
+	var cref, dref string
+	if current.RefTable != nil {
+		cref = current.RefTable.Name
+	}
+	if desired.RefTable != nil {
+		dref = desired.RefTable.Name
+	}
+	r.modified(o, "referenced table", cref, dref)
+	r.modified(o, "referenced columns", driftColumns(current.RefColumns), driftColumns(desired.RefColumns))
+	r.modified(o, "on update", string(current.OnUpdate), string(desired.OnUpdate))
+	r.modified(o, "on delete", string(current.OnDelete), string(desired.OnDelete))
+	r.modified(o, "match", string(current.Match), string(desired.Match))
+}
+
+// attrs compares the common attributes (comment, charset and collation) of an object.
+func (r *DriftReport) attrs(o DriftObject, current, desired []Attr) {
+	var (
+		cc, dc Comment
+		cs, ds Charset
+		cl, dl Collation
+	)
+	driftAttr(current, &cc)
+	driftAttr(desired, &dc)
+	driftAttr(current, &cs)
+	driftAttr(desired, &ds)
+	driftAttr(current, &cl)
+	driftAttr(desired, &dl)
+	r.modified(o, "comment", cc.Text, dc.Text)
+	r.modified(o, "charset", cs.V, ds.V)
+	r.modified(o, "collation", cl.V, dl.V)
+}
+
+// checks compares the CHECK constraints of a table. Named checks are matched
+// by their names, and unnamed checks are matched by their expressions.
+func (r *DriftReport) checks(s, t string, current, desired []Attr) {
+	find := func(attrs []Attr, c *Check) (*Check, bool) {
+		for _, a := range attrs {
+			if c2, ok := a.(*Check); ok && (c.Name != "" && c2.Name == c.Name || c.Name == "" && c2.Name == "" && c2.Expr == c.Expr) {
+				return c2, true
+			}
+		}
+		return nil, false
+	}
+	name := func(c *Check) string {
+		if c.Name != "" {
+			return c.Name
+		}
+		return c.Expr
+	}
+	for _, a := range desired {
+		d, ok := a.(*Check)
+		if !ok {
+			continue
+		}
+		c, ok := find(current, d)
+		if !ok {
+			r.missing(ObjectCheck, s, t, name(d))
+			continue
+		}
+		r.modified(DriftObject{Kind: ObjectCheck, Path: []string{s, t, name(d)}}, "expr", c.Expr, d.Expr)
+	}
+	for _, a := range current {
+		if c, ok := a.(*Check); ok {
+			if _, ok := find(desired, c); !ok {
+				r.extra(ObjectCheck, s, t, name(c))
+			}
+		}
+	}
+}
+
+// driftAttr sets v to the first attribute with the same type, if exists.
+func driftAttr(attrs []Attr, v Attr) {
+	for _, a := range attrs {
+		if reflect.TypeOf(a) == reflect.TypeOf(v) {
+			reflect.ValueOf(v).Elem().Set(reflect.ValueOf(a).Elem())
+			return
+		}
+	}
+}
+
+// driftTypesEqual reports if the two column types are equal. The underlying types
+// are compared, and the raw types are used only if one of the types is missing.
+func driftTypesEqual(t1, t2 *ColumnType) bool {
+	switch {
+	case t1 == nil || t2 == nil:
+		return t1 == t2
+	case t1.Type == nil || t2.Type == nil:
+		return strings.EqualFold(t1.Raw, t2.Raw)
+	default:
+		return reflect.DeepEqual(t1.Type, t2.Type)
+	}
+}
+
+// driftType returns the string representation of the column type.
+func driftType(t *ColumnType) string {
+	switch {
+	case t == nil:
+		return ""
+	case t.Raw != "":
+		return t.Raw
+	case t.Type == nil:
+		return ""
+	}
+	return fmt.Sprintf("%+v", reflect.Indirect(reflect.ValueOf(t.Type)).Interface())
+}
+
+// driftExpr returns the string representation of the expression.
+func driftExpr(x Expr) string {
+	switch x := x.(type) {
+	case nil:
+		return ""
+	case *Literal:
+		return x.V
+	case *RawExpr:
+		return x.X
+	default:
+		return fmt.Sprintf("%T", x)
+	}
+}
+
+// driftParts returns the string representation of index parts.
+func driftParts(idx *Index) string {
+	parts := make([]string, len(idx.Parts))
+	for i, p := range idx.Parts {
+		switch {
+		case p.C != nil:
+			parts[i] = p.C.Name
+		case p.X != nil:
+			parts[i] = driftExpr(p.X)
+		}
+		if p.Desc {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// driftColumns returns the names of the columns.
+func driftColumns(columns []*Column) string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,75 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrift(t *testing.T) {
+	desired := func() *schema.Realm {
+		id := schema.NewIntColumn("id", "bigint")
+		name := schema.NewStringColumn("name", "varchar", schema.StringSize(255)).SetComment("user name")
+		users := schema.NewTable("users").
+			AddColumns(id, name, schema.NewIntColumn("age", "int").SetDefault(&schema.Literal{V: "0"})).
+			SetPrimaryKey(schema.NewPrimaryKey(id)).
+			AddIndexes(schema.NewUniqueIndex("name").AddColumns(name)).
+			AddChecks(schema.NewCheck().SetName("positive_age").SetExpr("age > 0"))
+		return schema.NewRealm(schema.New("public").SetCharset("utf8mb4").AddTables(users))
+	}
+	r := schema.Drift(desired(), desired())
+	require.True(t, r.Empty())
+	require.Empty(t, r.String())
+
+	current := desired()
+	s := current.Schemas[0]
+	s.SetCharset("latin1")
+	users := s.Tables[0]
+	users.Columns[1].SetNull(true).UnsetComment()
+	users.Columns[1].Type.Type = &schema.StringType{T: "varchar", Size: 100}
+	users.Columns[2].SetDefault(nil)
+	users.Columns = append(users.Columns[:2], schema.NewBoolColumn("deleted", "bool"))
+	users.Indexes[0].SetUnique(false)
+	users.Attrs = nil
+	users.AddChecks(schema.NewCheck().SetExpr("id > 0"))
+	s.AddTables(schema.NewTable("logs"))
+
+	r = schema.Drift(current, desired())
+	require.False(t, r.Empty())
+	require.Equal(t, []*schema.DriftObject{
+		{Kind: schema.ObjectColumn, Path: []string{"public", "users", "age"}},
+		{Kind: schema.ObjectCheck, Path: []string{"public", "users", "positive_age"}},
+	}, r.Missing)
+	require.Equal(t, []*schema.DriftObject{
+		{Kind: schema.ObjectColumn, Path: []string{"public", "users", "deleted"}},
+		{Kind: schema.ObjectCheck, Path: []string{"public", "users", "id > 0"}},
+		{Kind: schema.ObjectTable, Path: []string{"public", "logs"}},
+	}, r.Extra)
+	require.Equal(t, "missing column \"public.users.age\"\n"+
+		"missing check \"public.users.positive_age\"\n"+
+		"extra column \"public.users.deleted\"\n"+
+		"extra check \"public.users.id > 0\"\n"+
+		"extra table \"public.logs\"\n"+
+		"modified schema \"public\": charset \"latin1\" (desired \"utf8mb4\")\n"+
+		"modified column \"public.users.name\": type \"{T:varchar Size:100}\" (desired \"{T:varchar Size:255}\")\n"+
+		"modified column \"public.users.name\": null \"true\" (desired \"false\")\n"+
+		"modified column \"public.users.name\": comment \"\" (desired \"user name\")\n"+
+		"modified index \"public.users.name\": unique \"false\" (desired \"true\")\n", r.String())
+	require.Equal(t, &schema.DriftAttr{
+		DriftObject: schema.DriftObject{Kind: schema.ObjectColumn, Path: []string{"public", "users", "name"}},
+		Attr:        "null",
+		Current:     "true",
+		Desired:     "false",
+	}, r.Modified[2])
+
+	r = schema.Drift(schema.NewRealm(schema.New("other")), desired())
+	require.Equal(t, []*schema.DriftObject{{Kind: schema.ObjectSchema, Path: []string{"public"}}}, r.Missing)
+	require.Equal(t, []*schema.DriftObject{{Kind: schema.ObjectSchema, Path: []string{"other"}}}, r.Extra)
+	require.Equal(t, "public", r.Missing[0].Name())
+}
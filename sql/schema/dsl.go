@@ -4,10 +4,6 @@
 
 package schema
 
-import (
-	"reflect"
-)
-
 // The functions and methods below provide a DSL for creating schema resources using
 // a fluent interface. Note that some methods create links between the schema elements.
 
@@ -19,33 +15,39 @@ func New(name string) *Schema {
 // SetCharset sets or appends the Charset attribute
 // to the schema with the given value.
 func (s *Schema) SetCharset(v string) *Schema {
-	replaceOrAppend(&s.Attrs, &Charset{V: v})
+	s.Attrs = ReplaceAttr(s.Attrs, &Charset{V: v})
 	return s
 }
 
 // UnsetCharset unsets the Charset attribute.
 func (s *Schema) UnsetCharset() *Schema {
-	del(&s.Attrs, &Charset{})
+	s.Attrs = RemoveAttr(s.Attrs, (*Charset)(nil))
 	return s
 }
 
 // SetCollation sets or appends the Collation attribute
 // to the schema with the given value.
 func (s *Schema) SetCollation(v string) *Schema {
-	replaceOrAppend(&s.Attrs, &Collation{V: v})
+	s.Attrs = ReplaceAttr(s.Attrs, &Collation{V: v})
 	return s
 }
 
 // UnsetCollation the Collation attribute.
 func (s *Schema) UnsetCollation() *Schema {
-	del(&s.Attrs, &Collation{})
+	s.Attrs = RemoveAttr(s.Attrs, (*Collation)(nil))
 	return s
 }
 
 // SetComment sets or appends the Comment attribute
 // to the schema with the given value.
 func (s *Schema) SetComment(v string) *Schema {
-	replaceOrAppend(&s.Attrs, &Comment{Text: v})
+	s.Attrs = ReplaceAttr(s.Attrs, &Comment{Text: v})
+	return s
+}
+
+// UnsetComment unsets the Comment attribute.
+func (s *Schema) UnsetComment() *Schema {
+	s.Attrs = RemoveAttr(s.Attrs, (*Comment)(nil))
 	return s
 }
 
@@ -79,29 +81,44 @@ func NewRealm(schemas ...*Schema) *Realm {
 	return r
 }
 
+// AddSchemas adds and links the given schemas to the realm.
+func (r *Realm) AddSchemas(schemas ...*Schema) *Realm {
+	for _, s := range schemas {
+		s.SetRealm(r)
+	}
+	r.Schemas = append(r.Schemas, schemas...)
+	return r
+}
+
+// AddAttrs adds additional attributes to the realm.
+func (r *Realm) AddAttrs(attrs ...Attr) *Realm {
+	r.Attrs = append(r.Attrs, attrs...)
+	return r
+}
+
 // SetCharset sets or appends the Charset attribute
 // to the realm with the given value.
 func (r *Realm) SetCharset(v string) *Realm {
-	replaceOrAppend(&r.Attrs, &Charset{V: v})
+	r.Attrs = ReplaceAttr(r.Attrs, &Charset{V: v})
 	return r
 }
 
 // UnsetCharset unsets the Charset attribute.
 func (r *Realm) UnsetCharset() *Realm {
-	del(&r.Attrs, &Charset{})
+	r.Attrs = RemoveAttr(r.Attrs, (*Charset)(nil))
 	return r
 }
 
 // SetCollation sets or appends the Collation attribute
 // to the realm with the given value.
 func (r *Realm) SetCollation(v string) *Realm {
-	replaceOrAppend(&r.Attrs, &Collation{V: v})
+	r.Attrs = ReplaceAttr(r.Attrs, &Collation{V: v})
 	return r
 }
 
 // UnsetCollation the Collation attribute.
 func (r *Realm) UnsetCollation() *Realm {
-	del(&r.Attrs, &Collation{})
+	r.Attrs = RemoveAttr(r.Attrs, (*Collation)(nil))
 	return r
 }
 
@@ -113,33 +130,39 @@ func NewTable(name string) *Table {
 // SetCharset sets or appends the Charset attribute
 // to the table with the given value.
 func (t *Table) SetCharset(v string) *Table {
-	replaceOrAppend(&t.Attrs, &Charset{V: v})
+	t.Attrs = ReplaceAttr(t.Attrs, &Charset{V: v})
 	return t
 }
 
 // UnsetCharset unsets the Charset attribute.
 func (t *Table) UnsetCharset() *Table {
-	del(&t.Attrs, &Charset{})
+	t.Attrs = RemoveAttr(t.Attrs, (*Charset)(nil))
 	return t
 }
 
 // SetCollation sets or appends the Collation attribute
 // to the table with the given value.
 func (t *Table) SetCollation(v string) *Table {
-	replaceOrAppend(&t.Attrs, &Collation{V: v})
+	t.Attrs = ReplaceAttr(t.Attrs, &Collation{V: v})
 	return t
 }
 
 // UnsetCollation the Collation attribute.
 func (t *Table) UnsetCollation() *Table {
-	del(&t.Attrs, &Collation{})
+	t.Attrs = RemoveAttr(t.Attrs, (*Collation)(nil))
 	return t
 }
 
 // SetComment sets or appends the Comment attribute
 // to the table with the given value.
 func (t *Table) SetComment(v string) *Table {
-	replaceOrAppend(&t.Attrs, &Comment{Text: v})
+	t.Attrs = ReplaceAttr(t.Attrs, &Comment{Text: v})
+	return t
+}
+
+// UnsetComment unsets the Comment attribute.
+func (t *Table) UnsetComment() *Table {
+	t.Attrs = RemoveAttr(t.Attrs, (*Comment)(nil))
 	return t
 }
 
@@ -424,8 +447,8 @@ func NewTimeColumn(name, typ string, opts ...TimeOption) *Column {
 }
 
 // NewNullTimeColumn creates a new nullable TimeType column.
-func NewNullTimeColumn(name, typ string) *Column {
-	return NewTimeColumn(name, typ).
+func NewNullTimeColumn(name, typ string, opts ...TimeOption) *Column {
+	return NewTimeColumn(name, typ, opts...).
 		SetNull(true)
 }
 
@@ -480,33 +503,39 @@ func (c *Column) SetDefault(x Expr) *Column {
 // SetCharset sets or appends the Charset attribute
 // to the column with the given value.
 func (c *Column) SetCharset(v string) *Column {
-	replaceOrAppend(&c.Attrs, &Charset{V: v})
+	c.Attrs = ReplaceAttr(c.Attrs, &Charset{V: v})
 	return c
 }
 
 // UnsetCharset unsets the Charset attribute.
 func (c *Column) UnsetCharset() *Column {
-	del(&c.Attrs, &Charset{})
+	c.Attrs = RemoveAttr(c.Attrs, (*Charset)(nil))
 	return c
 }
 
 // SetCollation sets or appends the Collation attribute
 // to the column with the given value.
 func (c *Column) SetCollation(v string) *Column {
-	replaceOrAppend(&c.Attrs, &Collation{V: v})
+	c.Attrs = ReplaceAttr(c.Attrs, &Collation{V: v})
 	return c
 }
 
 // UnsetCollation the Collation attribute.
 func (c *Column) UnsetCollation() *Column {
-	del(&c.Attrs, &Collation{})
+	c.Attrs = RemoveAttr(c.Attrs, (*Collation)(nil))
 	return c
 }
 
 // SetComment sets or appends the Comment attribute
 // to the column with the given value.
 func (c *Column) SetComment(v string) *Column {
-	replaceOrAppend(&c.Attrs, &Comment{Text: v})
+	c.Attrs = ReplaceAttr(c.Attrs, &Comment{Text: v})
+	return c
+}
+
+// UnsetComment unsets the Comment attribute.
+func (c *Column) UnsetComment() *Column {
+	c.Attrs = RemoveAttr(c.Attrs, (*Comment)(nil))
 	return c
 }
 
@@ -576,7 +605,7 @@ func (i *Index) SetTable(t *Table) *Index {
 // SetComment sets or appends the Comment attribute
 // to the index with the given value.
 func (i *Index) SetComment(v string) *Index {
-	replaceOrAppend(&i.Attrs, &Comment{Text: v})
+	i.Attrs = ReplaceAttr(i.Attrs, &Comment{Text: v})
 	return i
 }
 
@@ -715,27 +744,8 @@ func (f *ForeignKey) SetOnDelete(o ReferenceOption) *ForeignKey {
 	return f
 }
 
-// replaceOrAppend searches an attribute of the same type as v in
-// the list and replaces it. Otherwise, v is appended to the list.
-func replaceOrAppend(attrs *[]Attr, v Attr) {
-	t := reflect.TypeOf(v)
-	for i := range *attrs {
-		if reflect.TypeOf((*attrs)[i]) == t {
-			(*attrs)[i] = v
-			return
-		}
-	}
-	*attrs = append(*attrs, v)
-}
-
-// del searches an attribute of the same type as v in
-// the list and delete it.
-func del(attrs *[]Attr, v Attr) {
-	t := reflect.TypeOf(v)
-	for i := range *attrs {
-		if reflect.TypeOf((*attrs)[i]) == t {
-			*attrs = append((*attrs)[:i], (*attrs)[i+1:]...)
-			return
-		}
-	}
+// SetMatch sets the MATCH type of the foreign key.
+func (f *ForeignKey) SetMatch(m ReferenceMatch) *ForeignKey {
+	f.Match = m
+	return f
 }
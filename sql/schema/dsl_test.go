@@ -167,6 +167,33 @@ func TestSchema_SetComment(t *testing.T) {
 	s.SetComment("2")
 	require.Len(t, s.Attrs, 1)
 	require.Equal(t, &schema.Comment{Text: "2"}, s.Attrs[0])
+	s.UnsetComment()
+	require.Empty(t, s.Attrs)
+}
+
+func TestRealm_AddSchemas(t *testing.T) {
+	public, private := schema.New("public"), schema.New("private")
+	r := schema.NewRealm(public).
+		AddSchemas(private).
+		AddAttrs(&schema.Collation{V: "en_US.utf8"})
+	require.Equal(t, []*schema.Schema{public, private}, r.Schemas)
+	require.Equal(t, r, public.Realm)
+	require.Equal(t, r, private.Realm)
+	require.Equal(t, []schema.Attr{&schema.Collation{V: "en_US.utf8"}}, r.Attrs)
+}
+
+func TestColumn_UnsetComment(t *testing.T) {
+	c := schema.NewNullTimeColumn("created_at", "timestamp", schema.TimePrecision(6)).
+		SetComment("creation time")
+	require.Equal(t, &schema.ColumnType{Type: &schema.TimeType{T: "timestamp", Precision: 6}, Null: true}, c.Type)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "creation time"}}, c.Attrs)
+	c.UnsetComment()
+	require.Empty(t, c.Attrs)
+
+	tbl := schema.NewTable("users").SetComment("users table")
+	require.Len(t, tbl.Attrs, 1)
+	tbl.UnsetComment()
+	require.Empty(t, tbl.Attrs)
 }
 
 func TestCheck(t *testing.T) {
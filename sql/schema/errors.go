@@ -0,0 +1,112 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type (
+	// UnsupportedTypeError is returned by the drivers when they
+	// encounter a type they do not know how to handle.
+	UnsupportedTypeError struct {
+		Type Type
+	}
+
+	// A LockError wraps another error to retain its original text, and
+	// reports that a statement failed because a lock could not be acquired.
+	// For example, lock wait timeouts, deadlocks or a busy database file.
+	LockError struct {
+		Err error
+	}
+
+	// A ConstraintViolationError wraps another error to retain its original
+	// text, and reports that a statement failed because the data violates a
+	// constraint. For example, adding a unique index on duplicate values, or
+	// a foreign key with missing references.
+	ConstraintViolationError struct {
+		// Constraint is the name of the violated constraint,
+		// if it was reported by the database.
+		Constraint string
+		Err        error
+	}
+
+	// A StmtError wraps another error to retain its original text, and
+	// attaches to it the statement that failed to execute.
+	StmtError struct {
+		// Stmt is the statement that failed to execute.
+		Stmt string
+		// Pos is the 1-based character position in Stmt the error
+		// refers to, or 0 if it was not reported by the database.
+		Pos int
+		Err error
+	}
+
+	// A ReadOnlyError is returned by drivers that were opened in read-only
+	// mode, when a statement that may write to the database is executed.
+	ReadOnlyError struct {
+		// Stmt is the statement that was rejected.
+		Stmt string
+	}
+
+	// A ValidationError is returned by Validate, and holds all
+	// the violations that were found in the schema graph.
+	ValidationError struct {
+		Violations []string
+	}
+)
+
+func (e UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported type %T", e.Type)
+}
+
+func (e LockError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e LockError) Unwrap() error { return e.Err }
+
+func (e ConstraintViolationError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e ConstraintViolationError) Unwrap() error { return e.Err }
+
+func (e StmtError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e StmtError) Unwrap() error { return e.Err }
+
+func (e ReadOnlyError) Error() string {
+	return fmt.Sprintf("schema: statement rejected in read-only mode: %q", e.Stmt)
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("schema: invalid realm: %s", strings.Join(e.Violations, "; "))
+}
+
+// IsUnsupportedTypeError reports if an error is an UnsupportedTypeError.
+func IsUnsupportedTypeError(err error) bool {
+	var e *UnsupportedTypeError
+	return errors.As(err, &e)
+}
+
+// IsLockError reports if an error is a LockError.
+func IsLockError(err error) bool {
+	var e *LockError
+	return errors.As(err, &e)
+}
+
+// IsConstraintViolationError reports if an error is a ConstraintViolationError.
+func IsConstraintViolationError(err error) bool {
+	var e *ConstraintViolationError
+	return errors.As(err, &e)
+}
+
+// IsReadOnlyError reports if an error is a ReadOnlyError.
+func IsReadOnlyError(err error) bool {
+	var e *ReadOnlyError
+	return errors.As(err, &e)
+}
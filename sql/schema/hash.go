@@ -0,0 +1,234 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Hash returns a stable digest (hex-encoded SHA-256) of the given realm. Two realms
+// that describe the same schema graph produce the same hash, regardless of the order
+// of their schemas, tables, indexes, foreign keys and attributes. The order of columns
+// and index parts is part of the hash, as it is meaningful for the database.
+//
+// The raw representation of column types is used only for columns without a parsed
+// type. Therefore, realms are expected to be normalized the same way (e.g. both were
+// inspected from a database) in order to be compared by their hashes.
+func Hash(r *Realm) string {
+	h := sha256.New()
+	if r != nil {
+		(&hasher{w: h}).realm(r)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hasher writes the canonical representation of schema elements.
+type hasher struct {
+	w io.Writer
+}
+
+func (h *hasher) printf(format string, args ...interface{}) {
+	fmt.Fprintf(h.w, format, args...)
+}
+
+func (h *hasher) realm(r *Realm) {
+	h.attrs(r.Attrs)
+	schemas := make([]*Schema, len(r.Schemas))
+	copy(schemas, r.Schemas)
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+	for _, s := range schemas {
+		h.printf("schema %q\n", s.Name)
+		h.attrs(s.Attrs)
+		tables := make([]*Table, len(s.Tables))
+		copy(tables, s.Tables)
+		sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+		for _, t := range tables {
+			h.table(t)
+		}
+	}
+}
+
+func (h *hasher) table(t *Table) {
+	h.printf("table %q\n", t.Name)
+	h.attrs(t.Attrs)
+	for _, c := range t.Columns {
+		h.printf("column %q null=%t\n", c.Name, c.Type != nil && c.Type.Null)
+		switch {
+		case c.Type == nil:
+		case c.Type.Type != nil:
+			h.printf("type %s\n", canonical(c.Type.Type))
+		default:
+			h.printf("type %q\n", c.Type.Raw)
+		}
+		if c.Default != nil {
+			h.printf("default %s\n", canonical(c.Default))
+		}
+		h.attrs(c.Attrs)
+	}
+	if t.PrimaryKey != nil {
+		h.printf("primary key\n")
+		h.index(t.PrimaryKey)
+	}
+	indexes := make([]*Index, len(t.Indexes))
+	copy(indexes, t.Indexes)
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+	for _, idx := range indexes {
+		h.printf("index %q unique=%t\n", idx.Name, idx.Unique)
+		h.index(idx)
+	}
+	fks := make([]*ForeignKey, len(t.ForeignKeys))
+	copy(fks, t.ForeignKeys)
+	sort.Slice(fks, func(i, j int) bool { return fks[i].Symbol < fks[j].Symbol })
+	for _, fk := range fks {
+		h.printf("foreign key %q on update %q on delete %q\n", fk.Symbol, fk.OnUpdate, fk.OnDelete)
+		if fk.Match != "" {
+			h.printf("match %q\n", fk.Match)
+		}
+		for _, c := range fk.Columns {
+			h.printf("column %q\n", c.Name)
+		}
+		if fk.RefTable != nil {
+			h.printf("references %s\n", canonical(fk.RefTable))
+		}
+		for _, c := range fk.RefColumns {
+			h.printf("ref column %q\n", c.Name)
+		}
+	}
+}
+
+func (h *hasher) index(idx *Index) {
+	h.attrs(idx.Attrs)
+	for _, p := range idx.Parts {
+		h.printf("part desc=%t", p.Desc)
+		if p.C != nil {
+			h.printf(" column %q", p.C.Name)
+		}
+		if p.X != nil {
+			h.printf(" expr %s", canonical(p.X))
+		}
+		h.printf("\n")
+		h.attrs(p.Attrs)
+	}
+}
+
+// attrs writes the given attributes in a stable order. Table statistics
+// are skipped, as they describe the data rather than the schema.
+func (h *hasher) attrs(attrs []Attr) {
+	s := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		if _, ok := a.(*TableStats); !ok {
+			s = append(s, canonical(a))
+		}
+	}
+	sort.Strings(s)
+	for i := range s {
+		h.printf("attr %s\n", s[i])
+	}
+}
+
+// canonical returns the canonical representation of v. Unlike the "%+v" verb,
+// pointers are followed, map keys are sorted, and schema elements are written
+// as references (their names) in order to avoid cycles.
+func canonical(v interface{}) string {
+	var b bytes.Buffer
+	writeValue(&b, reflect.ValueOf(v))
+	return b.String()
+}
+
+func writeValue(b *bytes.Buffer, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		b.WriteString("nil")
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		if v.Kind() == reflect.Ptr && v.CanInterface() {
+			if ref, ok := elemRef(v.Interface()); ok {
+				b.WriteString(ref)
+				return
+			}
+		}
+		writeValue(b, v.Elem())
+	case reflect.Struct:
+		b.WriteString(v.Type().String())
+		b.WriteByte('{')
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(v.Type().Field(i).Name)
+			b.WriteByte(':')
+			writeValue(b, v.Field(i))
+		}
+		b.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			writeValue(b, v.Index(i))
+		}
+		b.WriteByte(']')
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			var kb bytes.Buffer
+			writeValue(&kb, it.Key())
+			keys = append(keys, kb.String())
+			values[kb.String()] = it.Value()
+		}
+		sort.Strings(keys)
+		b.WriteString("map[")
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(k)
+			b.WriteByte(':')
+			writeValue(b, values[k])
+		}
+		b.WriteByte(']')
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		b.WriteString(v.Type().String())
+	default:
+		fmt.Fprint(b, v)
+	}
+}
+
+// elemRef returns a reference to a schema element.
+func elemRef(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case *Realm:
+		return "realm", true
+	case *Schema:
+		return strconv.Quote(v.Name), true
+	case *Table:
+		if v.Schema != nil {
+			return strconv.Quote(v.Schema.Name) + "." + strconv.Quote(v.Name), true
+		}
+		return strconv.Quote(v.Name), true
+	case *Column:
+		return "column " + strconv.Quote(v.Name), true
+	case *Index:
+		return "index " + strconv.Quote(v.Name), true
+	case *ForeignKey:
+		return "foreign key " + strconv.Quote(v.Symbol), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	realm := func(reverse bool) *schema.Realm {
+		var (
+			id     = schema.NewIntColumn("id", "int")
+			name   = schema.NewStringColumn("name", "varchar", schema.StringSize(255))
+			owner  = schema.NewNullIntColumn("owner_id", "int")
+			users  = schema.NewTable("users").AddColumns(id, name).SetPrimaryKey(schema.NewPrimaryKey(id))
+			pets   = schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "int"), owner)
+			idx1   = schema.NewUniqueIndex("name").AddColumns(name)
+			idx2   = schema.NewIndex("name_id").AddParts(schema.NewColumnPart(name).SetDesc(true), schema.NewColumnPart(id))
+			check1 = &schema.Check{Name: "c1", Expr: "id > 0", Attrs: []schema.Attr{&schema.Comment{Text: "c"}}}
+			check2 = &schema.Check{Name: "c2", Expr: "id < 100"}
+			fk     = schema.NewForeignKey("owner").AddColumns(owner).SetRefTable(users).AddRefColumns(id)
+		)
+		pets.AddForeignKeys(fk)
+		if reverse {
+			users.AddIndexes(idx2, idx1).AddAttrs(check2, check1, &schema.Comment{Text: "users"})
+			return schema.NewRealm(schema.New("public").AddTables(pets, users), schema.New("other")).SetCharset("utf8")
+		}
+		users.AddIndexes(idx1, idx2).AddAttrs(&schema.Comment{Text: "users"}, check1, check2)
+		return schema.NewRealm(schema.New("other"), schema.New("public").AddTables(users, pets)).SetCharset("utf8")
+	}
+	h := schema.Hash(realm(false))
+	require.Len(t, h, 64)
+	require.Equal(t, h, schema.Hash(realm(false)))
+	require.Equal(t, h, schema.Hash(realm(true)), "order of collections should not affect the hash")
+	require.NotEqual(t, h, schema.Hash(nil))
+	require.Equal(t, schema.Hash(nil), schema.Hash(schema.NewRealm()))
+	r := realm(false)
+	r.Schemas[1].Tables[0].AddAttrs(&schema.TableStats{Rows: 100, DataSize: 8192})
+	require.Equal(t, h, schema.Hash(r), "table statistics should not affect the hash")
+
+	for _, change := range []func(*schema.Realm){
+		func(r *schema.Realm) { r.Schemas[1].Name = "private" },
+		func(r *schema.Realm) { r.Schemas[1].Tables[0].Columns[1].SetNull(true) },
+		func(r *schema.Realm) {
+			r.Schemas[1].Tables[0].Columns[1].Type.Type = &schema.StringType{T: "varchar", Size: 100}
+		},
+		func(r *schema.Realm) { r.Schemas[1].Tables[0].Columns[1].SetDefault(&schema.Literal{V: "'a8m'"}) },
+		func(r *schema.Realm) { r.Schemas[1].Tables[0].Indexes[1].Parts[0].Desc = false },
+		func(r *schema.Realm) { r.Schemas[1].Tables[0].Attrs[1].(*schema.Check).Attrs = nil },
+		func(r *schema.Realm) { r.Schemas[1].Tables[1].ForeignKeys[0].OnDelete = schema.Cascade },
+		func(r *schema.Realm) {
+			c := r.Schemas[1].Tables[0].Columns
+			c[0], c[1] = c[1], c[0]
+		},
+	} {
+		r := realm(false)
+		change(r)
+		require.NotEqual(t, h, schema.Hash(r))
+	}
+}
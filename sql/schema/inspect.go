@@ -38,6 +38,11 @@ type (
 	InspectOptions struct {
 		// Tables to inspect. Empty means all tables in the schema.
 		Tables []string
+
+		// Stats indicates if the inspected tables should be enriched with
+		// their approximate statistics (i.e. the TableStats attribute).
+		// Drivers that cannot read statistics from their catalog ignore it.
+		Stats bool
 	}
 
 	// InspectTableOptions describes options for TableInspector.
@@ -50,6 +55,11 @@ type (
 	InspectRealmOption struct {
 		// Schemas to inspect. Empty means all tables in the schema.
 		Schemas []string
+
+		// Stats indicates if the inspected tables should be enriched with
+		// their approximate statistics (i.e. the TableStats attribute).
+		// Drivers that cannot read statistics from their catalog ignore it.
+		Stats bool
 	}
 
 	// Inspector is the interface implemented by the different database
@@ -63,4 +73,36 @@ type (
 		// InspectRealm returns the description of the connected database.
 		InspectRealm(ctx context.Context, opts *InspectRealmOption) (*Realm, error)
 	}
+
+	// TablesInspector is the interface implemented by the drivers that can stream the tables
+	// of a realm, instead of materializing the whole realm before returning it. Streamed tables
+	// are linked to their schema, but the schema does not hold them. Hence, foreign keys that
+	// reference other tables may be linked to stub tables that hold only their name and schema.
+	// Note that table statistics (i.e. the Stats option) are not loaded by streamed inspection.
+	TablesInspector interface {
+		// InspectTables calls fn with each table of the realm once it was inspected. Returning
+		// an error from fn stops the inspection, and the error is returned to the caller.
+		InspectTables(ctx context.Context, opts *InspectRealmOption, fn func(*Table) error) error
+	}
 )
+
+// InspectTables calls fn with each table of the realm inspected by i. Tables are streamed
+// if the inspector implements the TablesInspector interface, and otherwise, they are taken
+// from the realm returned by InspectRealm.
+func InspectTables(ctx context.Context, i Inspector, opts *InspectRealmOption, fn func(*Table) error) error {
+	if ti, ok := i.(TablesInspector); ok {
+		return ti.InspectTables(ctx, opts, fn)
+	}
+	r, err := i.InspectRealm(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -91,6 +91,12 @@ type (
 		Change   ChangeKind
 	}
 
+	// RenameColumn describes a column rename change. Renames are
+	// not detected by the Differ, and should be provided by the user.
+	RenameColumn struct {
+		From, To *Column
+	}
+
 	// AddIndex describes an index creation change.
 	AddIndex struct {
 		I *Index
@@ -187,7 +193,9 @@ const (
 
 	// ChangeNull describe a change to the NULL constraint.
 	ChangeNull
-	// ChangeType describe a column type change.
+	// ChangeType describe a column type change. For foreign keys,
+	// it describes a type change of one of their (child or parent)
+	// columns.
 	ChangeType
 	// ChangeDefault describe a column default change.
 	ChangeDefault
@@ -214,6 +222,11 @@ const (
 	ChangeUpdateAction
 	// ChangeDeleteAction describes a change to the foreign-key delete action.
 	ChangeDeleteAction
+	// ChangeMatch describes a change to the foreign-key match type.
+	ChangeMatch
+	// ChangePosition describes a change to the column position in its table.
+	// It is reported only by drivers that treat the order of columns as significant.
+	ChangePosition
 )
 
 // Is reports whether c is match the given change kind.
@@ -258,6 +271,7 @@ func (*DropCheck) change()        {}
 func (*ModifyCheck) change()      {}
 func (*AddColumn) change()        {}
 func (*DropColumn) change()       {}
+func (*RenameColumn) change()     {}
 func (*ModifyColumn) change()     {}
 func (*AddForeignKey) change()    {}
 func (*DropForeignKey) change()   {}
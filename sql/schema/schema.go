@@ -82,6 +82,9 @@ type (
 		RefColumns []*Column
 		OnUpdate   ReferenceOption
 		OnDelete   ReferenceOption
+		// Match holds the MATCH type of the foreign key. An empty
+		// value means the default type, which is MATCH SIMPLE.
+		Match ReferenceMatch
 	}
 )
 
@@ -168,6 +171,17 @@ const (
 	SetDefault ReferenceOption = "SET DEFAULT"
 )
 
+// ReferenceMatch for foreign-key match types.
+type ReferenceMatch string
+
+// Match types specified by the MATCH subclause of the FOREIGN KEY clause.
+// They define how NULL values in multicolumn foreign keys are matched.
+const (
+	MatchSimple  ReferenceMatch = "SIMPLE"
+	MatchFull    ReferenceMatch = "FULL"
+	MatchPartial ReferenceMatch = "PARTIAL"
+)
+
 type (
 	// A Type represents a database type. The types below implements this
 	// interface and can be used for describing schemas.
@@ -247,6 +261,7 @@ type (
 	}
 
 	// UnsupportedType represents a type that is not supported by the drivers.
+	// Such types are kept in their raw form, and compared and planned by it.
 	UnsupportedType struct {
 		T string
 	}
@@ -298,6 +313,14 @@ type (
 		Expr  string // Actual CHECK.
 		Attrs []Attr // Additional attributes (e.g. ENFORCED).
 	}
+
+	// TableStats describes the approximate statistics of a table, as reported
+	// by the database catalog at inspection. Unknown values are set to -1.
+	TableStats struct {
+		Rows      int64 // Approximate number of rows.
+		DataSize  int64 // Size of the table data on disk, in bytes.
+		IndexSize int64 // Size of the table indexes on disk, in bytes.
+	}
 )
 
 // expressions.
@@ -318,7 +341,8 @@ func (*DecimalType) typ()     {}
 func (*UnsupportedType) typ() {}
 
 // attributes.
-func (*Check) attr()     {}
-func (*Comment) attr()   {}
-func (*Charset) attr()   {}
-func (*Collation) attr() {}
+func (*Check) attr()      {}
+func (*Comment) attr()    {}
+func (*Charset) attr()    {}
+func (*Collation) attr()  {}
+func (*TableStats) attr() {}
@@ -0,0 +1,148 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package schematest provides assertions for writing schema regression tests. States are
+// compared using the differ of a driver, and failures are reported as a readable changelog
+// of the differences, instead of a dump of the compared structs. Golden files are updated
+// by running the tests with the -schematest.update flag.
+//
+//	func TestSchema(t *testing.T) {
+//		realm, err := drv.InspectRealm(ctx, nil)
+//		require.NoError(t, err)
+//		schematest.RequireRealmEqual(t, drv, desired, realm)
+//		schematest.RequireGolden(t, mysql.MarshalHCL, "testdata/schema.hcl", realm)
+//	}
+//
+package schematest
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/schema/schemaspec"
+	"ariga.io/atlas/sql/changelog"
+	"ariga.io/atlas/sql/schema"
+)
+
+// update indicates if golden files should be written instead of compared.
+var update = flag.Bool("schematest.update", false, "update the golden files of schematest")
+
+// RequireRealmEqual fails the test if the differ reports changes for migrating
+// the actual realm to the expected one.
+func RequireRealmEqual(t testing.TB, d schema.Differ, expected, actual *schema.Realm) {
+	t.Helper()
+	changes, err := d.RealmDiff(actual, expected)
+	requireNoChanges(t, changes, err)
+}
+
+// RequireSchemaEqual fails the test if the differ reports changes for migrating
+// the actual schema to the expected one.
+func RequireSchemaEqual(t testing.TB, d schema.Differ, expected, actual *schema.Schema) {
+	t.Helper()
+	changes, err := d.SchemaDiff(actual, expected)
+	requireNoChanges(t, changes, err)
+}
+
+// RequireTableEqual fails the test if the differ reports changes for migrating
+// the actual table to the expected one.
+func RequireTableEqual(t testing.TB, d schema.Differ, expected, actual *schema.Table) {
+	t.Helper()
+	changes, err := d.TableDiff(actual, expected)
+	requireNoChanges(t, changes, err)
+}
+
+func requireNoChanges(t testing.TB, changes []schema.Change, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("schematest: diff failed: %v", err)
+		return
+	}
+	if len(changes) > 0 {
+		t.Fatalf("schematest: states are not equal, changes from the actual state to the expected:\n%s", DiffString(changes))
+	}
+}
+
+// DiffString returns a human-readable description of the given changes.
+func DiffString(changes []schema.Change) string {
+	var b strings.Builder
+	if err := (&changelog.Renderer{}).Render(&b, changes); err != nil {
+		return err.Error()
+	}
+	return b.String()
+}
+
+// RequireGolden marshals v (e.g. an inspected realm) using the given marshaler, and
+// compares the result with the content of the golden file in the given path. If the
+// tests are running with the -schematest.update flag, the golden file is written
+// instead. Failures are reported as a line diff of the golden file.
+func RequireGolden(t testing.TB, m schemaspec.Marshaler, path string, v interface{}) {
+	t.Helper()
+	actual, err := m.MarshalSpec(v)
+	if err != nil {
+		t.Fatalf("schematest: marshal %T: %v", v, err)
+		return
+	}
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("schematest: create golden file directory: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, actual, 0644); err != nil {
+			t.Fatalf("schematest: write golden file: %v", err)
+			return
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("schematest: read golden file (run with -schematest.update to create it): %v", err)
+		return
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Fatalf("schematest: %s does not match (run with -schematest.update to update it):\n%s", path, LineDiff(string(expected), string(actual)))
+	}
+}
+
+// LineDiff returns a line diff of the two texts. Removed lines
+// are prefixed with "-", added lines with "+", and others with " ".
+func LineDiff(from, to string) string {
+	l1, l2 := strings.Split(from, "\n"), strings.Split(to, "\n")
+	// Length of the longest common subsequence of l1[i:] and l2[j:].
+	lcs := make([][]int, len(l1)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(l2)+1)
+	}
+	for i := len(l1) - 1; i >= 0; i-- {
+		for j := len(l2) - 1; j >= 0; j-- {
+			switch {
+			case l1[i] == l2[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var b strings.Builder
+	i, j := 0, 0
+	for i < len(l1) || j < len(l2) {
+		switch {
+		case i < len(l1) && j < len(l2) && l1[i] == l2[j]:
+			b.WriteString(" " + l1[i] + "\n")
+			i, j = i+1, j+1
+		case j == len(l2) || i < len(l1) && lcs[i+1][j] >= lcs[i][j+1]:
+			b.WriteString("-" + l1[i] + "\n")
+			i++
+		default:
+			b.WriteString("+" + l2[j] + "\n")
+			j++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,99 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schematest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/schema/schemaspec"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireRealmEqual(t *testing.T) {
+	var (
+		users = schema.NewTable("users").SetSchema(schema.New("public"))
+		age   = schema.NewIntColumn("age", "int")
+		d     = &mockDiffer{}
+		tb    = &mockT{TB: t}
+	)
+	RequireRealmEqual(tb, d, schema.NewRealm(), schema.NewRealm())
+	require.Empty(t, tb.msg)
+
+	d.changes = []schema.Change{&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: age}}}}
+	RequireTableEqual(tb, d, users, users)
+	require.Contains(t, tb.msg, "states are not equal")
+	require.Contains(t, tb.msg, "added column")
+
+	d.err = fmt.Errorf("mismatched schema names")
+	RequireSchemaEqual(tb, d, users.Schema, users.Schema)
+	require.Equal(t, "schematest: diff failed: mismatched schema names", tb.msg)
+}
+
+func TestRequireGolden(t *testing.T) {
+	var (
+		tb   = &mockT{TB: t}
+		path = filepath.Join(t.TempDir(), "testdata", "schema.txt")
+		m    = schemaspec.MarshalerFunc(func(v interface{}) ([]byte, error) {
+			return []byte(fmt.Sprintf("schema %q {\n  comment = %q\n}\n", v.(*schema.Schema).Name, "v1")), nil
+		})
+	)
+	RequireGolden(tb, m, path, schema.New("public"))
+	require.Contains(t, tb.msg, "run with -schematest.update to create it")
+
+	*update = true
+	tb.msg = ""
+	RequireGolden(tb, m, path, schema.New("public"))
+	*update = false
+	require.Empty(t, tb.msg)
+	RequireGolden(tb, m, path, schema.New("public"))
+	require.Empty(t, tb.msg)
+
+	RequireGolden(tb, m, path, schema.New("main"))
+	require.Contains(t, tb.msg, "does not match")
+	require.Contains(t, tb.msg, "-schema \"public\" {\n+schema \"main\" {\n   comment = \"v1\"\n")
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "schema \"public\" {\n  comment = \"v1\"\n}\n", string(buf))
+}
+
+func TestLineDiff(t *testing.T) {
+	require.Equal(t, " a\n-b\n+c\n d\n", LineDiff("a\nb\nd", "a\nc\nd"))
+	require.Equal(t, " a\n+b\n", LineDiff("a", "a\nb"))
+}
+
+type (
+	mockT struct {
+		testing.TB
+		msg string
+	}
+	mockDiffer struct {
+		schema.Differ
+		changes []schema.Change
+		err     error
+	}
+)
+
+func (*mockT) Helper() {}
+
+func (t *mockT) Fatalf(format string, args ...interface{}) {
+	t.msg = fmt.Sprintf(format, args...)
+}
+
+func (d *mockDiffer) RealmDiff(_, _ *schema.Realm) ([]schema.Change, error) {
+	return d.changes, d.err
+}
+
+func (d *mockDiffer) SchemaDiff(_, _ *schema.Schema) ([]schema.Change, error) {
+	return d.changes, d.err
+}
+
+func (d *mockDiffer) TableDiff(_, _ *schema.Table) ([]schema.Change, error) {
+	return d.changes, d.err
+}
@@ -0,0 +1,223 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Validate checks the referential integrity of the given realm, and returns a
+// *ValidationError that holds all the violations that were found, or nil if the
+// realm is valid. It is useful for schemas that are built programmatically, as it
+// allows failing early instead of planning changes that produce broken SQL.
+//
+// The checks include missing (nil) elements and links, duplicate names, index parts
+// that reference columns of other tables, and foreign keys with unknown columns or
+// with columns that do not match the types of the referenced columns.
+func Validate(r *Realm) error {
+	if r == nil {
+		return &ValidationError{Violations: []string{"nil realm"}}
+	}
+	v := &validator{realm: r, tables: make(map[*Table]bool)}
+	for _, s := range r.Schemas {
+		if s != nil {
+			for _, t := range s.Tables {
+				v.tables[t] = t != nil
+			}
+		}
+	}
+	v.validate()
+	if len(v.Violations) == 0 {
+		return nil
+	}
+	return &v.ValidationError
+}
+
+// validator collects the violations of a realm.
+type validator struct {
+	ValidationError
+	realm  *Realm
+	tables map[*Table]bool
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.Violations = append(v.Violations, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) validate() {
+	names := make(map[string]bool)
+	for i, s := range v.realm.Schemas {
+		switch {
+		case s == nil:
+			v.addf("schema at position %d is nil", i)
+			continue
+		case names[s.Name]:
+			v.addf("schema %q is defined more than once", s.Name)
+		case s.Realm != nil && s.Realm != v.realm:
+			v.addf("schema %q is linked to another realm", s.Name)
+		}
+		names[s.Name] = true
+		v.schema(s)
+	}
+}
+
+func (v *validator) schema(s *Schema) {
+	names := make(map[string]bool)
+	for i, t := range s.Tables {
+		switch {
+		case t == nil:
+			v.addf("schema %q: table at position %d is nil", s.Name, i)
+			continue
+		case t.Name == "":
+			v.addf("schema %q: table at position %d has no name", s.Name, i)
+		case names[t.Name]:
+			v.addf("schema %q: table %q is defined more than once", s.Name, t.Name)
+		}
+		if t.Schema != nil && t.Schema != s {
+			v.addf("table %q is linked to another schema", t.Name)
+		}
+		names[t.Name] = true
+		v.table(t)
+	}
+}
+
+func (v *validator) table(t *Table) {
+	owned := make(map[*Column]bool, len(t.Columns))
+	names := make(map[string]bool, len(t.Columns))
+	for i, c := range t.Columns {
+		switch {
+		case c == nil:
+			v.addf("table %q: column at position %d is nil", t.Name, i)
+			continue
+		case c.Name == "":
+			v.addf("table %q: column at position %d has no name", t.Name, i)
+		case names[c.Name]:
+			v.addf("table %q: column %q is defined more than once", t.Name, c.Name)
+		}
+		if c.Type == nil || c.Type.Type == nil {
+			v.addf("table %q: column %q has no type", t.Name, c.Name)
+		}
+		owned[c], names[c.Name] = true, true
+	}
+	if t.PrimaryKey != nil {
+		v.index(t, t.PrimaryKey, owned, "primary key")
+	}
+	names = make(map[string]bool, len(t.Indexes))
+	for i, idx := range t.Indexes {
+		switch {
+		case idx == nil:
+			v.addf("table %q: index at position %d is nil", t.Name, i)
+			continue
+		case idx.Name == "":
+			v.addf("table %q: index at position %d has no name", t.Name, i)
+		case names[idx.Name]:
+			v.addf("table %q: index %q is defined more than once", t.Name, idx.Name)
+		}
+		names[idx.Name] = true
+		v.index(t, idx, owned, fmt.Sprintf("index %q", idx.Name))
+	}
+	names = make(map[string]bool, len(t.ForeignKeys))
+	for i, fk := range t.ForeignKeys {
+		if fk == nil {
+			v.addf("table %q: foreign key at position %d is nil", t.Name, i)
+			continue
+		}
+		if fk.Symbol != "" && names[fk.Symbol] {
+			v.addf("table %q: foreign key %q is defined more than once", t.Name, fk.Symbol)
+		}
+		names[fk.Symbol] = true
+		v.foreignKey(t, fk, owned)
+	}
+	for _, a := range t.Attrs {
+		if c, ok := a.(*Check); ok && c.Expr == "" {
+			v.addf("table %q: check %q has no expression", t.Name, c.Name)
+		}
+	}
+}
+
+func (v *validator) index(t *Table, idx *Index, owned map[*Column]bool, name string) {
+	if idx.Table != nil && idx.Table != t {
+		v.addf("table %q: %s is linked to another table", t.Name, name)
+	}
+	if len(idx.Parts) == 0 {
+		v.addf("table %q: %s has no parts", t.Name, name)
+	}
+	for i, p := range idx.Parts {
+		switch {
+		case p == nil:
+			v.addf("table %q: %s: part at position %d is nil", t.Name, name, i)
+		case p.C == nil && p.X == nil:
+			v.addf("table %q: %s: part at position %d has no column or expression", t.Name, name, i)
+		case p.C != nil && !owned[p.C]:
+			v.addf("table %q: %s: column %q is not a column of the table", t.Name, name, p.C.Name)
+		}
+	}
+}
+
+func (v *validator) foreignKey(t *Table, fk *ForeignKey, owned map[*Column]bool) {
+	name := fmt.Sprintf("foreign key %q", fk.Symbol)
+	if fk.Table != nil && fk.Table != t {
+		v.addf("table %q: %s is linked to another table", t.Name, name)
+	}
+	for _, c := range fk.Columns {
+		switch {
+		case c == nil:
+			v.addf("table %q: %s has a nil column", t.Name, name)
+		case !owned[c]:
+			v.addf("table %q: %s: column %q is not a column of the table", t.Name, name, c.Name)
+		}
+	}
+	if len(fk.Columns) == 0 {
+		v.addf("table %q: %s has no columns", t.Name, name)
+	}
+	switch ref := fk.RefTable; {
+	case ref == nil:
+		v.addf("table %q: %s has no referenced table", t.Name, name)
+		return
+	case !v.tables[ref]:
+		v.addf("table %q: %s references table %q that is not part of the realm", t.Name, name, ref.Name)
+	}
+	if len(fk.RefColumns) != len(fk.Columns) {
+		v.addf("table %q: %s has %d columns, but %d referenced columns", t.Name, name, len(fk.Columns), len(fk.RefColumns))
+	}
+	for i, c := range fk.RefColumns {
+		switch {
+		case c == nil:
+			v.addf("table %q: %s has a nil referenced column", t.Name, name)
+		case !hasColumn(fk.RefTable, c):
+			v.addf("table %q: %s: referenced column %q is not a column of table %q", t.Name, name, c.Name, fk.RefTable.Name)
+		case i < len(fk.Columns) && fk.Columns[i] != nil && !typesMatch(fk.Columns[i], c):
+			v.addf("table %q: %s: type of column %q does not match the type of referenced column %q", t.Name, name, fk.Columns[i].Name, c.Name)
+		}
+	}
+}
+
+// hasColumn reports if the given column is owned by the table.
+func hasColumn(t *Table, c *Column) bool {
+	for i := range t.Columns {
+		if t.Columns[i] == c {
+			return true
+		}
+	}
+	return false
+}
+
+// typesMatch reports if the types of the child and parent columns of a
+// foreign key are compatible. i.e. they are of the same kind and signedness.
+func typesMatch(c1, c2 *Column) bool {
+	if c1.Type == nil || c2.Type == nil || c1.Type.Type == nil || c2.Type.Type == nil {
+		// Reported by the table validation.
+		return true
+	}
+	t1, t2 := c1.Type.Type, c2.Type.Type
+	if reflect.TypeOf(t1) != reflect.TypeOf(t2) {
+		return false
+	}
+	if i1, ok := t1.(*IntegerType); ok {
+		return i1.Unsigned == t2.(*IntegerType).Unsigned
+	}
+	return true
+}
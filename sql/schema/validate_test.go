@@ -0,0 +1,69 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schema_test
+
+import (
+	"errors"
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	var (
+		id    = schema.NewIntColumn("id", "int")
+		users = schema.NewTable("users").
+			AddColumns(id).
+			SetPrimaryKey(schema.NewPrimaryKey(id))
+		ownerID = schema.NewIntColumn("owner_id", "int")
+		pets    = schema.NewTable("pets").
+			AddColumns(ownerID).
+			AddIndexes(schema.NewIndex("owner_id").AddColumns(ownerID)).
+			AddForeignKeys(schema.NewForeignKey("owner_id").AddColumns(ownerID).SetRefTable(users).AddRefColumns(id))
+		r = schema.NewRealm(schema.New("public").AddTables(users, pets))
+	)
+	require.NoError(t, schema.Validate(r))
+
+	var (
+		other   = schema.NewStringColumn("name", "varchar")
+		orphans = schema.NewTable("orphans").AddColumns(other)
+	)
+	pets.Columns = append(pets.Columns, schema.NewNullColumn("owner_id"), nil)
+	pets.AddIndexes(
+		schema.NewIndex("owner_id").AddColumns(other),
+		&schema.Index{Name: "empty"},
+	)
+	pets.AddForeignKeys(
+		schema.NewForeignKey("name").AddColumns(ownerID).SetRefTable(users).AddRefColumns(other),
+		schema.NewForeignKey("orphan").AddColumns(ownerID).SetRefTable(orphans).AddRefColumns(other),
+		&schema.ForeignKey{Symbol: "dangling", Columns: []*schema.Column{ownerID}},
+	)
+	pets.AddChecks(&schema.Check{Name: "positive"})
+	users.Columns[0].Type.Type = &schema.IntegerType{T: "int", Unsigned: true}
+	r.Schemas = append(r.Schemas, schema.New("public"), nil)
+
+	err := schema.Validate(r)
+	var verr *schema.ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Equal(t, []string{
+		`table "pets": column "owner_id" is defined more than once`,
+		`table "pets": column "owner_id" has no type`,
+		`table "pets": column at position 2 is nil`,
+		`table "pets": index "owner_id" is defined more than once`,
+		`table "pets": index "owner_id": column "name" is not a column of the table`,
+		`table "pets": index "empty" has no parts`,
+		`table "pets": foreign key "owner_id": type of column "owner_id" does not match the type of referenced column "id"`,
+		`table "pets": foreign key "name": referenced column "name" is not a column of table "users"`,
+		`table "pets": foreign key "orphan" references table "orphans" that is not part of the realm`,
+		`table "pets": foreign key "orphan": type of column "owner_id" does not match the type of referenced column "name"`,
+		`table "pets": foreign key "dangling" has no referenced table`,
+		`table "pets": check "positive" has no expression`,
+		`schema "public" is defined more than once`,
+		`schema at position 2 is nil`,
+	}, verr.Violations)
+	require.Error(t, schema.Validate(nil))
+}
@@ -0,0 +1,249 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package snowflake
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// FormatType converts schema type to its column form in the database.
+// An error is returned if the type cannot be recognized.
+//
+// Integer types are synonymous with NUMBER(38,0) in Snowflake and formatted as
+// such, and semi-structured types (VARIANT, OBJECT and ARRAY) are represented by
+// schema.JSONType. Character and binary types without size are formatted without
+// it, which defaults to their maximum size.
+func FormatType(t schema.Type) (string, error) {
+	var f string
+	switch t := t.(type) {
+	case *schema.BoolType:
+		f = strings.ToUpper(TypeBoolean)
+	case *schema.BinaryType:
+		switch f = strings.ToUpper(t.T); strings.ToLower(t.T) {
+		case TypeBinary, TypeVarbinary, "":
+			f = "BINARY"
+			if t.Size > 0 {
+				f = fmt.Sprintf("BINARY(%d)", t.Size)
+			}
+		case "tinyblob", "blob", "mediumblob", "longblob", "bytea", "raw":
+			f = "BINARY"
+		}
+	case *schema.DecimalType:
+		f = strings.ToUpper(TypeNumber)
+		switch {
+		case t.Precision > 0:
+			f = fmt.Sprintf("%s(%d,%d)", f, t.Precision, t.Scale)
+		case t.Scale > 0:
+			f = fmt.Sprintf("%s(%d,%d)", f, defaultPrecision, t.Scale)
+		}
+	case *schema.EnumType:
+		return "", fmt.Errorf("snowflake: enum types are not supported, use VARCHAR instead")
+	case *schema.FloatType:
+		// All floating-point types are 64-bit (i.e. DOUBLE) in Snowflake.
+		f = strings.ToUpper(TypeFloat)
+	case *schema.IntegerType:
+		f = fmt.Sprintf("NUMBER(%d,0)", defaultPrecision)
+	case *schema.JSONType:
+		switch f = strings.ToUpper(t.T); strings.ToLower(t.T) {
+		case TypeVariant, TypeObject, TypeArray:
+		default:
+			f = strings.ToUpper(TypeVariant)
+		}
+	case *schema.SpatialType:
+		switch f = strings.ToUpper(t.T); strings.ToLower(t.T) {
+		case TypeGeography, TypeGeometry:
+		default:
+			f = strings.ToUpper(TypeGeography)
+		}
+	case *schema.StringType:
+		switch f = strings.ToUpper(t.T); strings.ToLower(t.T) {
+		case TypeChar, TypeCharacter:
+			f = "CHAR"
+			if t.Size > 0 {
+				f = fmt.Sprintf("CHAR(%d)", t.Size)
+			}
+		default:
+			f = "VARCHAR"
+			if t.Size > 0 {
+				f = fmt.Sprintf("VARCHAR(%d)", t.Size)
+			}
+		}
+	case *schema.TimeType:
+		switch f = strings.ToUpper(t.T); strings.ToLower(t.T) {
+		case TypeDate:
+		case TypeDateTime, "timestamp without time zone":
+			f = strings.ToUpper(TypeTimestampNTZ)
+		case "timestamptz", "timestamp with time zone":
+			f = strings.ToUpper(TypeTimestampTZ)
+		case "timestamp with local time zone":
+			f = strings.ToUpper(TypeTimestampLTZ)
+		case TypeTime, TypeTimestamp, TypeTimestampLTZ, TypeTimestampNTZ, TypeTimestampTZ:
+		default:
+			return "", fmt.Errorf("snowflake: unsupported time type: %q", t.T)
+		}
+		if t.Precision > 0 && f != "DATE" {
+			f = fmt.Sprintf("%s(%d)", f, t.Precision)
+		}
+	case *schema.UnsupportedType:
+		// Types that are unknown to the driver are passed as is.
+		if t.T == "" {
+			return "", fmt.Errorf("snowflake: missing unsupported type definition")
+		}
+		f = t.T
+	default:
+		return "", fmt.Errorf("snowflake: invalid schema type: %T", t)
+	}
+	return f, nil
+}
+
+// mustFormat calls to FormatType and panics in case of error.
+func mustFormat(t schema.Type) string {
+	s, err := FormatType(t)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// ParseType returns the schema.Type value represented by the given raw type.
+// The raw value is expected to follow the format in Snowflake documentation.
+// https://docs.snowflake.com/en/sql-reference/intro-summary-data-types
+//
+// NUMBER(38,0) and the integer synonyms (e.g. INT) are parsed as schema.IntegerType,
+// and other NUMBER types as schema.DecimalType.
+func ParseType(raw string) (schema.Type, error) {
+	var (
+		mods []string
+		name = strings.ToLower(strings.TrimSpace(raw))
+	)
+	if i, j := strings.IndexByte(name, '('), strings.LastIndexByte(name, ')'); i > 0 && j > i {
+		mods = strings.Split(name[i+1:j], ",")
+		name = strings.TrimSpace(name[:i])
+	}
+	// Modifiers are parsed only for the types that are known to the driver.
+	ints, err := parseMods(mods)
+	if err != nil {
+		err = fmt.Errorf("snowflake: parse modifiers of type %q: %w", raw, err)
+	}
+	switch name {
+	case TypeNumber, TypeDecimal, TypeNumeric:
+		if err != nil {
+			return nil, err
+		}
+		p, s := defaultPrecision, 0
+		if len(ints) > 0 {
+			p = ints[0]
+		}
+		if len(ints) > 1 {
+			s = ints[1]
+		}
+		if s == 0 && p == defaultPrecision {
+			return &schema.IntegerType{T: TypeNumber}, nil
+		}
+		return &schema.DecimalType{T: TypeNumber, Precision: p, Scale: s}, nil
+	case TypeInt, TypeInteger, TypeBigInt, TypeSmallInt, TypeTinyInt, TypeByteInt:
+		return &schema.IntegerType{T: name}, nil
+	case TypeFloat, TypeFloat4, TypeFloat8, TypeDouble, TypeDoublePrec, TypeReal:
+		return &schema.FloatType{T: name}, nil
+	case TypeVarchar, TypeString, TypeText, TypeChar, TypeCharacter, "nvarchar", "nchar", "char varying", "nchar varying", "nvarchar2":
+		if err != nil {
+			return nil, err
+		}
+		t := &schema.StringType{T: name}
+		switch {
+		case len(ints) > 0:
+			t.Size = ints[0]
+		// CHAR without length is CHAR(1).
+		case name == TypeChar || name == TypeCharacter || name == "nchar":
+			t.Size = 1
+		}
+		return t, nil
+	case TypeBinary, TypeVarbinary:
+		if err != nil {
+			return nil, err
+		}
+		t := &schema.BinaryType{T: name}
+		if len(ints) > 0 {
+			t.Size = ints[0]
+		}
+		return t, nil
+	case TypeBoolean:
+		return &schema.BoolType{T: name}, nil
+	case TypeDate:
+		return &schema.TimeType{T: name}, nil
+	case TypeTime, TypeDateTime, TypeTimestamp, TypeTimestampLTZ, TypeTimestampNTZ, TypeTimestampTZ:
+		if err != nil {
+			return nil, err
+		}
+		t := &schema.TimeType{T: name}
+		if len(ints) > 0 {
+			t.Precision = ints[0]
+		}
+		return t, nil
+	case TypeVariant, TypeObject, TypeArray:
+		return &schema.JSONType{T: name}, nil
+	case TypeGeography, TypeGeometry:
+		return &schema.SpatialType{T: name}, nil
+	default:
+		return &schema.UnsupportedType{T: raw}, nil
+	}
+}
+
+// parseMods parses the numeric modifiers of a type (e.g. NUMBER(10,2)).
+func parseMods(mods []string) ([]int, error) {
+	ints := make([]int, len(mods))
+	for i := range mods {
+		n, err := strconv.Atoi(strings.TrimSpace(mods[i]))
+		if err != nil {
+			return nil, err
+		}
+		ints[i] = n
+	}
+	return ints, nil
+}
+
+// columnDesc represents a column descriptor as it is
+// described in the INFORMATION_SCHEMA.COLUMNS view.
+type columnDesc struct {
+	typ       string // DATA_TYPE
+	size      int64  // CHARACTER_MAXIMUM_LENGTH
+	precision int64  // NUMERIC_PRECISION
+	scale     int64  // NUMERIC_SCALE
+	timePrec  int64  // DATETIME_PRECISION
+}
+
+func columnType(c *columnDesc) schema.Type {
+	typ := strings.ToLower(c.typ)
+	switch typ {
+	case TypeNumber:
+		// Integer types are stored as NUMBER(38,0).
+		if c.scale == 0 && c.precision == defaultPrecision {
+			return &schema.IntegerType{T: TypeNumber}
+		}
+		return &schema.DecimalType{T: TypeNumber, Precision: int(c.precision), Scale: int(c.scale)}
+	case TypeFloat:
+		return &schema.FloatType{T: typ}
+	case TypeText:
+		return &schema.StringType{T: TypeVarchar, Size: int(c.size)}
+	case TypeBinary:
+		return &schema.BinaryType{T: typ, Size: int(c.size)}
+	case TypeBoolean:
+		return &schema.BoolType{T: typ}
+	case TypeDate:
+		return &schema.TimeType{T: typ}
+	case TypeTime, TypeTimestampLTZ, TypeTimestampNTZ, TypeTimestampTZ:
+		return &schema.TimeType{T: typ, Precision: int(c.timePrec)}
+	case TypeVariant, TypeObject, TypeArray:
+		return &schema.JSONType{T: typ}
+	case TypeGeography, TypeGeometry:
+		return &schema.SpatialType{T: typ}
+	default:
+		return &schema.UnsupportedType{T: typ}
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package snowflake
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A diff provides a Snowflake implementation for sqlx.DiffDriver.
+type diff struct{ conn }
+
+// SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
+// Views are schema attributes, and they are added, dropped or modified (i.e. replaced) by name.
+func (d *diff) SchemaAttrDiff(from, to *schema.Schema) []schema.Change {
+	var changes []schema.Change
+	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	fromV, toV := views(from.Attrs), views(to.Attrs)
+	for _, v1 := range fromV {
+		v2, ok := findView(toV, v1.Name)
+		switch {
+		case !ok:
+			changes = append(changes, &schema.DropAttr{A: v1})
+		case viewChanged(v1, v2):
+			changes = append(changes, &schema.ModifyAttr{From: v1, To: v2})
+		}
+	}
+	for _, v2 := range toV {
+		if _, ok := findView(fromV, v2.Name); !ok {
+			changes = append(changes, &schema.AddAttr{A: v2})
+		}
+	}
+	return changes
+}
+
+// TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
+func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
+	if sqlx.Has(from.Attrs, &Transient{}) != sqlx.Has(to.Attrs, &Transient{}) {
+		return nil, fmt.Errorf("snowflake: changing the transient property of table %q is not supported", to.Name)
+	}
+	if len(sqlx.CheckDiff(from, to)) > 0 {
+		return nil, fmt.Errorf("snowflake: CHECK constraints are not supported (table %q)", to.Name)
+	}
+	var changes []schema.Change
+	if change := sqlx.CommentDiff(from.Attrs, to.Attrs); change != nil {
+		changes = append(changes, change)
+	}
+	var c1, c2 ClusterBy
+	switch ok1, ok2 := sqlx.Has(from.Attrs, &c1), sqlx.Has(to.Attrs, &c2); {
+	case ok1 && !ok2:
+		changes = append(changes, &schema.DropAttr{A: &c1})
+	case !ok1 && ok2:
+		changes = append(changes, &schema.AddAttr{A: &c2})
+	case ok1 && ok2 && !exprsEqual(c1.Exprs, c2.Exprs):
+		changes = append(changes, &schema.ModifyAttr{From: &c1, To: &c2})
+	}
+	return changes, nil
+}
+
+// ColumnChange returns the schema changes (if any) for migrating one column to the other.
+func (d *diff) ColumnChange(from, to *schema.Column) (schema.ChangeKind, error) {
+	change := sqlx.CommentChange(from.Attrs, to.Attrs)
+	if from.Type.Null != to.Type.Null {
+		change |= schema.ChangeNull
+	}
+	changed, err := d.typeChanged(from, to)
+	if err != nil {
+		return schema.NoChange, err
+	}
+	if changed {
+		change |= schema.ChangeType
+	}
+	if d.defaultChanged(from, to) {
+		change |= schema.ChangeDefault
+	}
+	if identityChanged(from.Attrs, to.Attrs) || collationChanged(from.Attrs, to.Attrs) {
+		change |= schema.ChangeAttr
+	}
+	return change, nil
+}
+
+// typeChanged reports if the column type was changed. Types are compared by
+// their canonical form, as most types have synonyms in Snowflake (e.g. INT
+// and NUMBER(38,0), or TEXT and VARCHAR(16777216)).
+func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
+	if from.Type.Type == nil || to.Type.Type == nil {
+		return false, fmt.Errorf("snowflake: missing type information for column %q", from.Name)
+	}
+	t1, err := FormatType(from.Type.Type)
+	if err != nil {
+		return false, err
+	}
+	t2, err := FormatType(to.Type.Type)
+	if err != nil {
+		return false, err
+	}
+	return canonicalType(t1) != canonicalType(t2), nil
+}
+
+// canonicalType returns the canonical form of the given formatted type.
+func canonicalType(t string) string {
+	t = strings.ToUpper(t)
+	switch t {
+	case "NUMBER", "NUMBER(38)":
+		return "NUMBER(38,0)"
+	case "VARCHAR":
+		return fmt.Sprintf("VARCHAR(%d)", maxStringSize)
+	case "CHAR":
+		return "CHAR(1)"
+	case "BINARY":
+		return fmt.Sprintf("BINARY(%d)", maxBinarySize)
+	case "TIMESTAMP":
+		t = "TIMESTAMP_NTZ"
+	}
+	// The default fractional seconds precision of time types is 9.
+	switch t {
+	case "TIME", "TIMESTAMP_LTZ", "TIMESTAMP_NTZ", "TIMESTAMP_TZ":
+		return fmt.Sprintf("%s(%d)", t, defaultTimePrecision)
+	}
+	return t
+}
+
+// defaultChanged reports if the default value of a column was changed.
+func (d *diff) defaultChanged(from, to *schema.Column) bool {
+	d1, ok1 := defaultValue(from)
+	d2, ok2 := defaultValue(to)
+	if ok1 != ok2 {
+		return true
+	}
+	d1, d2 = sqlx.TrimParens(d1), sqlx.TrimParens(d2)
+	// Literals are compared as-is, and expressions
+	// are compared without whitespace and case.
+	if sqlx.IsQuoted(d1, '\'') || sqlx.IsQuoted(d2, '\'') {
+		return d1 != d2
+	}
+	return !strings.EqualFold(strings.Map(dropSpace, d1), strings.Map(dropSpace, d2))
+}
+
+// dropSpace is a strings.Map function that drops whitespaces.
+func dropSpace(r rune) rune {
+	if unicode.IsSpace(r) {
+		return -1
+	}
+	return r
+}
+
+// identityChanged reports if the identity attribute of a column was changed.
+func identityChanged(from, to []schema.Attr) bool {
+	i1, ok1 := identity(from)
+	i2, ok2 := identity(to)
+	return ok1 != ok2 || ok1 && (i1.Start != i2.Start || i1.Increment != i2.Increment)
+}
+
+// collationChanged reports if the collation of a column was changed.
+func collationChanged(from, to []schema.Attr) bool {
+	var c1, c2 schema.Collation
+	sqlx.Has(from, &c1)
+	sqlx.Has(to, &c2)
+	return !strings.EqualFold(c1.V, c2.V)
+}
+
+// IsGeneratedIndexName reports if the index name was generated by the database
+// for unnamed PRIMARY KEY and UNIQUE constraints (e.g. SYS_CONSTRAINT_<uuid>).
+func (d *diff) IsGeneratedIndexName(_ *schema.Table, idx *schema.Index) bool {
+	return isSystemName(idx.Name)
+}
+
+// IndexAttrChanged reports if the index attributes were changed.
+func (*diff) IndexAttrChanged(_, _ []schema.Attr) bool {
+	return false
+}
+
+// IndexPartAttrChanged reports if the index-part attributes were changed.
+func (*diff) IndexPartAttrChanged(_, _ *schema.IndexPart) bool {
+	return false
+}
+
+// ReferenceChanged reports if the foreign key referential action was changed.
+func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
+	// According to Snowflake, if an action is not explicitly
+	// specified, it defaults to "NO ACTION".
+	if from == "" {
+		from = schema.NoAction
+	}
+	if to == "" {
+		to = schema.NoAction
+	}
+	return from != to
+}
+
+// viewChanged reports if the definition of a view was changed.
+func viewChanged(from, to *View) bool {
+	return from.Secure != to.Secure || from.Comment != to.Comment ||
+		sqlx.NormalizeExpr(strings.TrimSuffix(from.Query, ";")) != sqlx.NormalizeExpr(strings.TrimSuffix(to.Query, ";"))
+}
+
+// exprsEqual reports if the two lists of expressions are equal.
+func exprsEqual(x1, x2 []string) bool {
+	if len(x1) != len(x2) {
+		return false
+	}
+	for i := range x1 {
+		if !strings.EqualFold(strings.Map(dropSpace, x1[i]), strings.Map(dropSpace, x2[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// views returns the views that are defined in the given attributes.
+func views(attrs []schema.Attr) []*View {
+	var vs []*View
+	for _, a := range attrs {
+		if v, ok := a.(*View); ok {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// findView returns the view with the given name.
+func findView(vs []*View, name string) (*View, bool) {
+	for _, v := range vs {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// isSystemName reports if the given constraint
+// name was generated by the database.
+func isSystemName(name string) bool {
+	return strings.HasPrefix(name, "SYS_CONSTRAINT_")
+}
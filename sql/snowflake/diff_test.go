@@ -0,0 +1,123 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package snowflake
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff_TableDiff(t *testing.T) {
+	type testcase struct {
+		name        string
+		from, to    *schema.Table
+		wantChanges []schema.Change
+		wantErr     bool
+	}
+	tests := []testcase{
+		{
+			name: "int equals number(38,0)",
+			from: &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeNumber}}}}},
+			to:   &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeInt}}}}},
+		},
+		{
+			name: "text equals varchar(16777216)",
+			from: &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar, Size: 16777216}}}}},
+			to:   &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeText}}}}},
+		},
+		{
+			name: "default timestamp precision",
+			from: &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.TimeType{T: TypeTimestampNTZ, Precision: 9}}}}},
+			to:   &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.TimeType{T: TypeTimestampNTZ}}}}},
+		},
+		{
+			name: "clustering key case",
+			from: &schema.Table{Name: "T", Attrs: []schema.Attr{&ClusterBy{Exprs: []string{"TO_DATE(C)"}}}},
+			to:   &schema.Table{Name: "T", Attrs: []schema.Attr{&ClusterBy{Exprs: []string{"to_date(c)"}}}},
+		},
+		{
+			name: "add clustering key",
+			from: &schema.Table{Name: "T"},
+			to:   &schema.Table{Name: "T", Attrs: []schema.Attr{&ClusterBy{Exprs: []string{"C"}}}},
+			wantChanges: []schema.Change{
+				&schema.AddAttr{A: &ClusterBy{Exprs: []string{"C"}}},
+			},
+		},
+		{
+			name:    "transient change",
+			from:    &schema.Table{Name: "T"},
+			to:      &schema.Table{Name: "T", Attrs: []schema.Attr{&Transient{}}},
+			wantErr: true,
+		},
+		func() testcase {
+			var (
+				from = &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar, Size: 10}}}}}
+				to   = &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar, Size: 10}}, Attrs: []schema.Attr{&schema.Collation{V: "en-ci"}}}}}
+			)
+			return testcase{
+				name: "collation",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[0], Change: schema.ChangeAttr},
+				},
+			}
+		}(),
+		{
+			name:    "missing type",
+			from:    &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{}}}},
+			to:      &schema.Table{Name: "T", Columns: []*schema.Column{{Name: "C", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeInt}}}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.params()
+		drv, err := Open(db)
+		require.NoError(t, err)
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := drv.TableDiff(tt.from, tt.to)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.EqualValues(t, tt.wantChanges, changes)
+		})
+	}
+}
+
+func TestDiff_SchemaDiff(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.params()
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		v1   = &View{Name: "V1", Query: "SELECT A FROM T"}
+		v2   = &View{Name: "V2", Query: "SELECT B FROM T"}
+		v3   = &View{Name: "V3", Query: "SELECT C FROM T"}
+		from = &schema.Schema{Name: "S", Attrs: []schema.Attr{v1, v2}}
+		to   = &schema.Schema{Name: "S", Attrs: []schema.Attr{&View{Name: "V1", Query: "select a from t"}, &View{Name: "V2", Query: "SELECT B, C FROM T"}, v3}}
+	)
+	changes, err := drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifySchema{
+			S: to,
+			Changes: []schema.Change{
+				&schema.ModifyAttr{From: v2, To: to.Attrs[1]},
+				&schema.AddAttr{A: v3},
+			},
+		},
+	}, changes)
+}
+
+func TestDiff_IsGeneratedIndexName(t *testing.T) {
+	d := &diff{}
+	require.True(t, d.IsGeneratedIndexName(nil, &schema.Index{Name: "SYS_CONSTRAINT_7a1b2c3d-1234"}))
+	require.False(t, d.IsGeneratedIndexName(nil, &schema.Index{Name: "USERS_PK"}))
+}
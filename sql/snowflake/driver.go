@@ -0,0 +1,210 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package snowflake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlmetrics"
+	"ariga.io/atlas/sql/sqltrace"
+)
+
+type (
+	// Driver represents a Snowflake driver for introspecting database schemas,
+	// generating diff between schema elements and apply migrations changes.
+	//
+	// A Realm in Snowflake is the database of the connection, and its schemas
+	// are the schemas of this database. Views are managed as schema attributes
+	// (see View), and clustering keys as table attributes (see ClusterBy).
+	//
+	// A Driver is safe for concurrent use by multiple goroutines, as long as its
+	// underlying connection is (e.g. *sql.DB). Inspections, planning and applying
+	// of drivers that were opened on a single connection (i.e. *sql.Tx or *sql.Conn)
+	// are serialized. Note that diffing normalizes the given schema elements, and
+	// the same elements should not be diffed concurrently.
+	Driver struct {
+		conn
+		schema.Differ
+		schema.Inspector
+		migrate.PlanApplier
+	}
+
+	// Option allows configuring the Driver using functional options.
+	Option func(*options)
+
+	// options holds the configuration of the Driver.
+	options struct {
+		trace    *sqltrace.Config
+		metrics  sqlmetrics.Recorder
+		log      sqlx.LogFunc
+		readOnly bool
+	}
+
+	// database connection and its information.
+	conn struct {
+		schema.ExecQuerier
+		// System variables that are set on `Open`.
+		version string
+		// The database of the connection (i.e. CURRENT_DATABASE),
+		// which is the realm that is inspected by the driver.
+		database string
+		// The schema of the connection (i.e. CURRENT_SCHEMA),
+		// which is inspected if no schema name is provided.
+		schema string
+	}
+)
+
+// Open opens a new Snowflake driver. The connection is expected to have
+// a current database, as schemas are inspected in the scope of this database.
+func Open(db schema.ExecQuerier, opts ...Option) (*Driver, error) {
+	var (
+		o      options
+		single = sqlx.SingleConn(db)
+	)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.readOnly {
+		db = sqlx.ReadOnlyExecQuerier(db)
+	}
+	if o.log != nil {
+		db = sqlx.LogExecQuerier(db, o.log)
+	}
+	var tracer *sqltrace.Tracer
+	if o.trace != nil {
+		tracer = sqltrace.New("snowflake", *o.trace)
+		db = tracer.ExecQuerier(db)
+	}
+	var metrics *sqlmetrics.Metrics
+	if o.metrics != nil {
+		metrics = sqlmetrics.New("snowflake", o.metrics)
+		db = metrics.ExecQuerier(db)
+	}
+	c := conn{ExecQuerier: db}
+	rows, err := db.QueryContext(context.Background(), paramsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("snowflake: query session parameters: %w", err)
+	}
+	var database, current sql.NullString
+	if err := sqlx.ScanOne(rows, &c.version, &database, &current); err != nil {
+		return nil, fmt.Errorf("snowflake: scan session parameters: %w", err)
+	}
+	if !database.Valid || database.String == "" {
+		return nil, fmt.Errorf("snowflake: connection has no current database")
+	}
+	c.database, c.schema = database.String, current.String
+	drv := &Driver{
+		conn:        c,
+		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
+		Inspector:   &inspect{c},
+		PlanApplier: &planApply{c},
+	}
+	if single {
+		var mu sync.Mutex
+		drv.Inspector = sqlx.SerialInspector(drv.Inspector, &mu)
+		drv.PlanApplier = sqlx.SerialPlanApplier(drv.PlanApplier, &mu)
+	}
+	if tracer != nil {
+		drv.Differ = tracer.Differ(drv.Differ)
+		drv.Inspector = tracer.Inspector(drv.Inspector)
+		drv.PlanApplier = tracer.PlanApplier(drv.PlanApplier)
+	}
+	if metrics != nil {
+		drv.Differ = metrics.Differ(drv.Differ)
+		drv.Inspector = metrics.Inspector(drv.Inspector)
+		drv.PlanApplier = metrics.PlanApplier(drv.PlanApplier)
+	}
+	return drv, nil
+}
+
+// WithTracing enables OpenTelemetry tracing for the driver. Spans are created
+// for schema inspection, diff computation and each executed statement.
+func WithTracing(cfg sqltrace.Config) Option {
+	return func(o *options) {
+		o.trace = &cfg
+	}
+}
+
+// WithMetrics reports the events of schema inspections, diff computations, planning,
+// and each executed statement of the driver to the given Recorder. For example, the
+// sqlmetrics.Collector exposes them as Prometheus metrics.
+func WithMetrics(r sqlmetrics.Recorder) Option {
+	return func(o *options) {
+		o.metrics = r
+	}
+}
+
+// WithReadOnly opens the driver in read-only mode. In this mode, all statements that
+// may write to the database are rejected with a schema.ReadOnlyError before they are
+// sent to it, and the driver can be used only for inspecting the database. Note that
+// this is not a replacement for connecting with a read-only role, but a guard
+// against writes that are attempted by the driver or by its users.
+func WithReadOnly(b bool) Option {
+	return func(o *options) {
+		o.readOnly = b
+	}
+}
+
+// WithStatementLog calls f with each statement (and its arguments) that is
+// executed or queried by the driver, before it is sent to the database.
+func WithStatementLog(f func(ctx context.Context, stmt string, args []interface{})) Option {
+	return func(o *options) {
+		o.log = f
+	}
+}
+
+// Snowflake data types.
+// https://docs.snowflake.com/en/sql-reference/intro-summary-data-types
+const (
+	TypeNumber       = "number"
+	TypeDecimal      = "decimal"
+	TypeNumeric      = "numeric"
+	TypeInt          = "int"
+	TypeInteger      = "integer"
+	TypeBigInt       = "bigint"
+	TypeSmallInt     = "smallint"
+	TypeTinyInt      = "tinyint"
+	TypeByteInt      = "byteint"
+	TypeFloat        = "float"
+	TypeFloat4       = "float4"
+	TypeFloat8       = "float8"
+	TypeDouble       = "double"
+	TypeDoublePrec   = "double precision"
+	TypeReal         = "real"
+	TypeVarchar      = "varchar"
+	TypeChar         = "char"
+	TypeCharacter    = "character"
+	TypeString       = "string"
+	TypeText         = "text"
+	TypeBinary       = "binary"
+	TypeVarbinary    = "varbinary"
+	TypeBoolean      = "boolean"
+	TypeDate         = "date"
+	TypeDateTime     = "datetime"
+	TypeTime         = "time"
+	TypeTimestamp    = "timestamp"
+	TypeTimestampLTZ = "timestamp_ltz"
+	TypeTimestampNTZ = "timestamp_ntz"
+	TypeTimestampTZ  = "timestamp_tz"
+	TypeVariant      = "variant"
+	TypeObject       = "object"
+	TypeArray        = "array"
+	TypeGeography    = "geography"
+	TypeGeometry     = "geometry"
+)
+
+// Default sizes and precisions of Snowflake types.
+const (
+	defaultPrecision     = 38
+	defaultTimePrecision = 9
+	maxStringSize        = 16777216
+	maxBinarySize        = 8388608
+)
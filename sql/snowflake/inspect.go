@@ -0,0 +1,580 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package snowflake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// An inspect provides a Snowflake implementation for schema.Inspector.
+type inspect struct{ conn }
+
+var _ schema.Inspector = (*inspect)(nil)
+
+// InspectRealm returns schema descriptions of all resources in the database
+// of the connection. The INFORMATION_SCHEMA is inspected only if it is requested
+// explicitly.
+func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	schemas, err := i.schemas(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	realm := &schema.Realm{Schemas: schemas}
+	for _, s := range schemas {
+		if err := i.inspectSchema(ctx, s, nil); err != nil {
+			return nil, err
+		}
+		s.Realm = realm
+	}
+	sqlx.LinkSchemaTables(schemas)
+	return realm, nil
+}
+
+// InspectSchema returns schema descriptions of the tables and views in the given
+// schema. If the schema name is empty, the current schema of the connection is used.
+// Views are not inspected if the inspection is limited to specific tables.
+func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
+	if name == "" {
+		name = i.schema
+	}
+	schemas, err := i.schemas(ctx, &schema.InspectRealmOption{Schemas: []string{name}})
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, &schema.NotExistError{
+			Err: fmt.Errorf("snowflake: schema %q was not found", name),
+		}
+	}
+	s := schemas[0]
+	if err := i.inspectSchema(ctx, s, opts); err != nil {
+		return nil, err
+	}
+	sqlx.LinkSchemaTables(schemas)
+	s.Realm = &schema.Realm{Schemas: schemas}
+	return s, nil
+}
+
+// inspectSchema inspects and appends the tables and views of the given schema.
+// As constraints are informational in Snowflake, and they are listed using the
+// SHOW commands, they are inspected for all tables of the schema at once.
+func (i *inspect) inspectSchema(ctx context.Context, s *schema.Schema, opts *schema.InspectOptions) error {
+	tables, err := i.tables(ctx, s.Name, opts)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		t.Schema = s
+	}
+	s.Tables = append(s.Tables, tables...)
+	if len(s.Tables) > 0 {
+		if err := i.columns(ctx, s); err != nil {
+			return err
+		}
+		if err := i.keys(ctx, s); err != nil {
+			return err
+		}
+		if err := i.fks(ctx, s); err != nil {
+			return err
+		}
+	}
+	if opts == nil || len(opts.Tables) == 0 {
+		if err := i.views(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemas returns the list of the schemas in the database.
+func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
+	var (
+		args  []interface{}
+		query = schemasQuery
+	)
+	if opts != nil && len(opts.Schemas) > 0 {
+		query, args = inStrings(opts.Schemas, schemasQueryArgs, args)
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("snowflake: querying schemas: %w", err)
+	}
+	defer rows.Close()
+	var schemas []*schema.Schema
+	for rows.Next() {
+		var (
+			name               string
+			transient, comment sql.NullString
+		)
+		if err := rows.Scan(&name, &transient, &comment); err != nil {
+			return nil, fmt.Errorf("snowflake: scanning schema: %w", err)
+		}
+		s := &schema.Schema{Name: name}
+		if transient.String == "YES" {
+			s.Attrs = append(s.Attrs, &Transient{})
+		}
+		if sqlx.ValidString(comment) {
+			s.Attrs = append(s.Attrs, &schema.Comment{Text: comment.String})
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas, rows.Err()
+}
+
+// tables returns the tables of the given schema.
+func (i *inspect) tables(ctx context.Context, ns string, opts *schema.InspectOptions) ([]*schema.Table, error) {
+	query, args := tablesQuery, []interface{}{ns}
+	if opts != nil && len(opts.Tables) > 0 {
+		query, args = inStrings(opts.Tables, tablesQueryArgs, args)
+	}
+	rows, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("snowflake: querying schema tables: %w", err)
+	}
+	defer rows.Close()
+	var tables []*schema.Table
+	for rows.Next() {
+		var (
+			name                        string
+			transient, cluster, comment sql.NullString
+		)
+		if err := rows.Scan(&name, &transient, &cluster, &comment); err != nil {
+			return nil, fmt.Errorf("snowflake: scanning table: %w", err)
+		}
+		t := &schema.Table{Name: name}
+		if transient.String == "YES" {
+			t.Attrs = append(t.Attrs, &Transient{})
+		}
+		if sqlx.ValidString(cluster) {
+			t.Attrs = append(t.Attrs, &ClusterBy{Exprs: clusterExprs(cluster.String)})
+		}
+		if sqlx.ValidString(comment) {
+			t.Attrs = append(t.Attrs, &schema.Comment{Text: comment.String})
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// columns queries and appends the columns of the schema tables.
+func (i *inspect) columns(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.QueryContext(ctx, columnsQuery, s.Name)
+	if err != nil {
+		return fmt.Errorf("snowflake: querying schema %q columns: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := addColumn(s, rows); err != nil {
+			return fmt.Errorf("snowflake: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+// addColumn scans the current row and adds a new column from it to its table.
+// Columns of tables that were not inspected (e.g. views) are skipped.
+func addColumn(s *schema.Schema, rows *sql.Rows) error {
+	var (
+		size, precision, scale, timePrec                                     sql.NullInt64
+		table, name, typ, nullable, defaults, collate, isID, start, incr, cm sql.NullString
+	)
+	if err := rows.Scan(&table, &name, &typ, &size, &precision, &scale, &timePrec, &nullable, &defaults, &collate, &isID, &start, &incr, &cm); err != nil {
+		return err
+	}
+	t, ok := s.Table(table.String)
+	if !ok {
+		return nil
+	}
+	c := &schema.Column{
+		Name: name.String,
+		Type: &schema.ColumnType{
+			Raw:  typ.String,
+			Null: nullable.String == "YES",
+			Type: columnType(&columnDesc{
+				typ:       typ.String,
+				size:      size.Int64,
+				precision: precision.Int64,
+				scale:     scale.Int64,
+				timePrec:  timePrec.Int64,
+			}),
+		},
+	}
+	switch {
+	case isID.String == "YES":
+		id := &Identity{Start: 1, Increment: 1}
+		if sqlx.ValidString(start) {
+			v, err := strconv.ParseInt(start.String, 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse identity start of column %q: %w", c.Name, err)
+			}
+			id.Start = v
+		}
+		if sqlx.ValidString(incr) {
+			v, err := strconv.ParseInt(incr.String, 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse identity increment of column %q: %w", c.Name, err)
+			}
+			id.Increment = v
+		}
+		c.Attrs = append(c.Attrs, id)
+	case sqlx.ValidString(defaults):
+		c.Default = defaultExpr(defaults.String)
+	}
+	if sqlx.ValidString(collate) {
+		c.Attrs = append(c.Attrs, &schema.Collation{V: collate.String})
+	}
+	if sqlx.ValidString(cm) {
+		c.Attrs = append(c.Attrs, &schema.Comment{Text: cm.String})
+	}
+	t.Columns = append(t.Columns, c)
+	return nil
+}
+
+// defaultExpr returns the schema expression of the given DEFAULT clause.
+func defaultExpr(x string) schema.Expr {
+	switch x = strings.TrimSpace(x); {
+	case sqlx.IsLiteralNumber(x), sqlx.IsLiteralBool(x), sqlx.IsQuoted(x, '\''):
+		return &schema.Literal{V: x}
+	default:
+		return &schema.RawExpr{X: x}
+	}
+}
+
+// keys queries and appends the PRIMARY KEY and UNIQUE constraints of the
+// schema tables. Snowflake does not support indexes on standard tables, and
+// UNIQUE constraints are represented as unique indexes.
+func (i *inspect) keys(ctx context.Context, s *schema.Schema) error {
+	pks, err := i.show(ctx, "PRIMARY KEYS", s)
+	if err != nil {
+		return err
+	}
+	uks, err := i.show(ctx, "UNIQUE KEYS", s)
+	if err != nil {
+		return err
+	}
+	for n, keys := range [][]map[string]string{pks, uks} {
+		for _, k := range keys {
+			t, ok := s.Table(k["table_name"])
+			if !ok {
+				continue
+			}
+			c, ok := t.Column(k["column_name"])
+			if !ok {
+				return fmt.Errorf("snowflake: column %q was not found for constraint %q", k["column_name"], k["constraint_name"])
+			}
+			name := k["constraint_name"]
+			idx, ok := t.Index(name)
+			switch {
+			case ok:
+			case t.PrimaryKey != nil && t.PrimaryKey.Name == name:
+				idx = t.PrimaryKey
+			default:
+				idx = &schema.Index{Name: name, Unique: true, Table: t}
+				if n == 0 {
+					t.PrimaryKey = idx
+				} else {
+					t.Indexes = append(t.Indexes, idx)
+				}
+			}
+			idx.Parts = append(idx.Parts, &schema.IndexPart{SeqNo: len(idx.Parts) + 1, C: c})
+			c.Indexes = append(c.Indexes, idx)
+		}
+	}
+	return nil
+}
+
+// fks queries and appends the foreign keys of the schema tables.
+func (i *inspect) fks(ctx context.Context, s *schema.Schema) error {
+	keys, err := i.show(ctx, "IMPORTED KEYS", s)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		t, ok := s.Table(k["fk_table_name"])
+		if !ok {
+			continue
+		}
+		name := k["fk_name"]
+		fk, ok := t.ForeignKey(name)
+		if !ok {
+			fk = &schema.ForeignKey{
+				Symbol:   name,
+				Table:    t,
+				OnUpdate: schema.ReferenceOption(k["update_rule"]),
+				OnDelete: schema.ReferenceOption(k["delete_rule"]),
+			}
+			switch ref, ok := s.Table(k["pk_table_name"]); {
+			case k["pk_schema_name"] == s.Name && ok:
+				fk.RefTable = ref
+			case k["pk_schema_name"] == s.Name:
+				fk.RefTable = &schema.Table{Name: k["pk_table_name"], Schema: s}
+			default:
+				fk.RefTable = &schema.Table{Name: k["pk_table_name"], Schema: &schema.Schema{Name: k["pk_schema_name"]}}
+			}
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		}
+		c, ok := t.Column(k["fk_column_name"])
+		if !ok {
+			return fmt.Errorf("snowflake: column %q was not found for fk %q", k["fk_column_name"], name)
+		}
+		fk.Columns = append(fk.Columns, c)
+		c.ForeignKeys = append(c.ForeignKeys, fk)
+		rc, ok := fk.RefTable.Column(k["pk_column_name"])
+		if !ok {
+			rc = &schema.Column{Name: k["pk_column_name"]}
+		}
+		fk.RefColumns = append(fk.RefColumns, rc)
+	}
+	return nil
+}
+
+// show executes the SHOW command of the given constraints in the schema, and
+// returns its rows as maps from column names to values, sorted by their table,
+// constraint name and position.
+func (i *inspect) show(ctx context.Context, keys string, s *schema.Schema) ([]map[string]string, error) {
+	rows, err := i.QueryContext(ctx, fmt.Sprintf("SHOW %s IN SCHEMA %s.%s", keys, ident(i.database), ident(s.Name)))
+	if err != nil {
+		return nil, fmt.Errorf("snowflake: querying schema %q %s: %w", s.Name, strings.ToLower(keys), err)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("snowflake: scanning schema %q %s: %w", s.Name, strings.ToLower(keys), err)
+	}
+	var result []map[string]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("snowflake: scanning schema %q %s: %w", s.Name, strings.ToLower(keys), err)
+		}
+		m := make(map[string]string, len(columns))
+		for i, c := range columns {
+			m[strings.ToLower(c)] = values[i].String
+		}
+		result = append(result, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		for _, k := range []string{"table_name", "constraint_name", "fk_table_name", "fk_name"} {
+			if result[i][k] != result[j][k] {
+				return result[i][k] < result[j][k]
+			}
+		}
+		s1, _ := strconv.Atoi(result[i]["key_sequence"])
+		s2, _ := strconv.Atoi(result[j]["key_sequence"])
+		return s1 < s2
+	})
+	return result, nil
+}
+
+// reViewQuery matches the beginning of the query in a view definition.
+var reViewQuery = regexp.MustCompile(`(?is)\sAS\s+(\(?\s*(SELECT|WITH)\b.*)$`)
+
+// views queries and appends the views of the given schema as schema attributes.
+func (i *inspect) views(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.QueryContext(ctx, viewsQuery, s.Name)
+	if err != nil {
+		return fmt.Errorf("snowflake: querying schema %q views: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name                 string
+			def, secure, comment sql.NullString
+		)
+		if err := rows.Scan(&name, &def, &secure, &comment); err != nil {
+			return fmt.Errorf("snowflake: scanning view: %w", err)
+		}
+		v := &View{Name: name, Secure: secure.String == "YES", Comment: comment.String}
+		// VIEW_DEFINITION holds the CREATE VIEW statement, or
+		// nothing in case the view is secure and not owned.
+		v.Query = strings.TrimSpace(def.String)
+		if m := reViewQuery.FindStringSubmatch(v.Query); m != nil {
+			v.Query = strings.TrimSpace(m[1])
+		}
+		v.Query = strings.TrimSpace(strings.TrimSuffix(v.Query, ";"))
+		s.Attrs = append(s.Attrs, v)
+	}
+	return rows.Err()
+}
+
+// clusterExprs returns the expressions of the given clustering key (e.g. "LINEAR(A, B)").
+func clusterExprs(key string) []string {
+	key = strings.TrimSpace(key)
+	if i := strings.IndexByte(key, '('); i != -1 && strings.EqualFold(strings.TrimSpace(key[:i]), "LINEAR") && strings.HasSuffix(key, ")") {
+		key = key[i+1 : len(key)-1]
+	}
+	var (
+		exprs []string
+		depth int
+		quote rune
+		start int
+	)
+	for i, r := range key {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			exprs = append(exprs, strings.TrimSpace(key[start:i]))
+			start = i + 1
+		}
+	}
+	if x := strings.TrimSpace(key[start:]); x != "" {
+		exprs = append(exprs, x)
+	}
+	return exprs
+}
+
+// inStrings writes the "IN" (or "=") clause of the given strings
+// to the query, and appends them to the positional arguments.
+func inStrings(s []string, query string, args []interface{}) (string, []interface{}) {
+	var b strings.Builder
+	switch len(s) {
+	case 1:
+		args = append(args, s[0])
+		b.WriteString("= ?")
+	default:
+		b.WriteString("IN (")
+		for i := range s {
+			args = append(args, s[i])
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte('?')
+		}
+		b.WriteByte(')')
+	}
+	return fmt.Sprintf(query, b.String()), args
+}
+
+type (
+	// Identity defines an identity (AUTOINCREMENT) column.
+	Identity struct {
+		schema.Attr
+		Start     int64
+		Increment int64
+	}
+
+	// Transient describes a transient table or schema. Transient objects
+	// have no Fail-safe period, and their type cannot be changed after
+	// they were created.
+	Transient struct {
+		schema.Attr
+	}
+
+	// ClusterBy describes the clustering key of a table.
+	// For example, CLUSTER BY (C1, TO_DATE(C2)).
+	ClusterBy struct {
+		schema.Attr
+		Exprs []string
+	}
+
+	// View describes a view in a schema. Views are attached to the
+	// attributes of their schema, and they are diffed and planned
+	// (i.e. replaced) by their query.
+	View struct {
+		schema.Attr
+		Name    string
+		Query   string
+		Secure  bool
+		Comment string
+	}
+)
+
+const (
+	// Query to get the database version, and the current database and schema.
+	paramsQuery = `SELECT CURRENT_VERSION(), CURRENT_DATABASE(), CURRENT_SCHEMA()`
+
+	// Query to list the schemas of the database, except the INFORMATION_SCHEMA.
+	schemasQuery = `SELECT SCHEMA_NAME, IS_TRANSIENT, COMMENT FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME <> 'INFORMATION_SCHEMA' ORDER BY SCHEMA_NAME`
+
+	// Query to list specific schemas.
+	schemasQueryArgs = `SELECT SCHEMA_NAME, IS_TRANSIENT, COMMENT FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME %s ORDER BY SCHEMA_NAME`
+
+	// Query to list the tables of a schema. Temporary tables are skipped.
+	tablesQuery = `
+SELECT
+	TABLE_NAME,
+	IS_TRANSIENT,
+	CLUSTERING_KEY,
+	COMMENT
+FROM INFORMATION_SCHEMA.TABLES
+WHERE
+	TABLE_SCHEMA = ?
+	AND TABLE_TYPE = 'BASE TABLE'
+ORDER BY TABLE_NAME
+`
+
+	tablesQueryArgs = `
+SELECT
+	TABLE_NAME,
+	IS_TRANSIENT,
+	CLUSTERING_KEY,
+	COMMENT
+FROM INFORMATION_SCHEMA.TABLES
+WHERE
+	TABLE_SCHEMA = ?
+	AND TABLE_TYPE = 'BASE TABLE'
+	AND TABLE_NAME %s
+ORDER BY TABLE_NAME
+`
+
+	// Query to list the columns of all tables and views in a schema.
+	columnsQuery = `
+SELECT
+	TABLE_NAME,
+	COLUMN_NAME,
+	DATA_TYPE,
+	CHARACTER_MAXIMUM_LENGTH,
+	NUMERIC_PRECISION,
+	NUMERIC_SCALE,
+	DATETIME_PRECISION,
+	IS_NULLABLE,
+	COLUMN_DEFAULT,
+	COLLATION_NAME,
+	IS_IDENTITY,
+	IDENTITY_START,
+	IDENTITY_INCREMENT,
+	COMMENT
+FROM INFORMATION_SCHEMA.COLUMNS
+WHERE TABLE_SCHEMA = ?
+ORDER BY TABLE_NAME, ORDINAL_POSITION
+`
+
+	// Query to list the views of a schema.
+	viewsQuery = `
+SELECT
+	TABLE_NAME,
+	VIEW_DEFINITION,
+	IS_SECURE,
+	COMMENT
+FROM INFORMATION_SCHEMA.VIEWS
+WHERE TABLE_SCHEMA = ?
+ORDER BY TABLE_NAME
+`
+)
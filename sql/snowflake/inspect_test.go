@@ -0,0 +1,240 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package snowflake
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectSchema(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.params()
+	mk.ExpectQuery(sqltest.Escape(`SELECT SCHEMA_NAME, IS_TRANSIENT, COMMENT FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ? ORDER BY SCHEMA_NAME`)).
+		WithArgs("PUBLIC").
+		WillReturnRows(sqltest.Rows(`
+ SCHEMA_NAME | IS_TRANSIENT | COMMENT
+-------------+--------------+---------
+ PUBLIC      | NO           | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(tablesQuery)).
+		WithArgs("PUBLIC").
+		WillReturnRows(sqltest.Rows(`
+ TABLE_NAME | IS_TRANSIENT | CLUSTERING_KEY                 | COMMENT
+------------+--------------+--------------------------------+----------
+ EVENTS     | YES          | LINEAR(TO_DATE(CREATED), KIND) | nil
+ USERS      | NO           | nil                            | app users
+`))
+	mk.ExpectQuery(sqltest.Escape(columnsQuery)).
+		WithArgs("PUBLIC").
+		WillReturnRows(sqltest.Rows(`
+ TABLE_NAME   | COLUMN_NAME | DATA_TYPE     | CHARACTER_MAXIMUM_LENGTH | NUMERIC_PRECISION | NUMERIC_SCALE | DATETIME_PRECISION | IS_NULLABLE | COLUMN_DEFAULT    | COLLATION_NAME | IS_IDENTITY | IDENTITY_START | IDENTITY_INCREMENT | COMMENT
+--------------+-------------+---------------+--------------------------+-------------------+---------------+--------------------+-------------+-------------------+----------------+-------------+----------------+--------------------+----------
+ ACTIVE_USERS | ID          | NUMBER        | nil                      | 38                | 0             | nil                | NO          | nil               | nil            | NO          | nil            | nil                | nil
+ EVENTS       | ID          | NUMBER        | nil                      | 38                | 0             | nil                | NO          | nil               | nil            | YES         | 1              | 1                  | nil
+ EVENTS       | USER_ID     | NUMBER        | nil                      | 38                | 0             | nil                | YES         | nil               | nil            | NO          | nil            | nil                | nil
+ EVENTS       | KIND        | TEXT          | 16777216                 | nil               | nil           | nil                | NO          | 'click'           | nil            | NO          | nil            | nil                | nil
+ EVENTS       | PAYLOAD     | VARIANT       | nil                      | nil               | nil           | nil                | YES         | nil               | nil            | NO          | nil            | nil                | event data
+ EVENTS       | CREATED     | TIMESTAMP_NTZ | nil                      | nil               | nil           | 9                  | NO          | CURRENT_TIMESTAMP() | nil          | NO          | nil            | nil                | nil
+ USERS        | ID          | NUMBER        | nil                      | 38                | 0             | nil                | NO          | nil               | nil            | YES         | 100            | 10                 | nil
+ USERS        | NAME        | TEXT          | 255                      | nil               | nil           | nil                | YES         | nil               | en-ci          | NO          | nil            | nil                | nil
+ USERS        | BALANCE     | NUMBER        | nil                      | 10                | 2             | nil                | YES         | 0                 | nil            | NO          | nil            | nil                | nil
+ USERS        | AREA        | GEOGRAPHY     | nil                      | nil               | nil           | nil                | YES         | nil               | nil            | NO          | nil            | nil                | nil
+`))
+	mk.ExpectQuery(sqltest.Escape(`SHOW PRIMARY KEYS IN SCHEMA "DB"."PUBLIC"`)).
+		WillReturnRows(sqltest.Rows(`
+ database_name | schema_name | table_name | column_name | key_sequence | constraint_name
+---------------+-------------+------------+-------------+--------------+-----------------
+ DB            | PUBLIC      | USERS      | ID          | 1            | USERS_PK
+ DB            | PUBLIC      | EVENTS     | ID          | 1            | SYS_CONSTRAINT_1
+`))
+	mk.ExpectQuery(sqltest.Escape(`SHOW UNIQUE KEYS IN SCHEMA "DB"."PUBLIC"`)).
+		WillReturnRows(sqltest.Rows(`
+ database_name | schema_name | table_name | column_name | key_sequence | constraint_name
+---------------+-------------+------------+-------------+--------------+-----------------
+ DB            | PUBLIC      | USERS      | AREA        | 2            | USERS_NAME_AREA
+ DB            | PUBLIC      | USERS      | NAME        | 1            | USERS_NAME_AREA
+`))
+	mk.ExpectQuery(sqltest.Escape(`SHOW IMPORTED KEYS IN SCHEMA "DB"."PUBLIC"`)).
+		WillReturnRows(sqltest.Rows(`
+ pk_schema_name | pk_table_name | pk_column_name | fk_schema_name | fk_table_name | fk_column_name | key_sequence | update_rule | delete_rule | fk_name
+----------------+---------------+----------------+----------------+---------------+----------------+--------------+-------------+-------------+-----------
+ PUBLIC         | USERS         | ID             | PUBLIC         | EVENTS        | USER_ID        | 1            | NO ACTION   | CASCADE     | EVENTS_FK
+`))
+	mk.ExpectQuery(sqltest.Escape(viewsQuery)).
+		WithArgs("PUBLIC").
+		WillReturnRows(sqltest.Rows(`
+ TABLE_NAME   | VIEW_DEFINITION                                                                   | IS_SECURE | COMMENT
+--------------+-----------------------------------------------------------------------------------+-----------+---------
+ ACTIVE_USERS | create or replace view ACTIVE_USERS as select ID from USERS where BALANCE > 0;   | YES       | nil
+`))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	s, err := drv.InspectSchema(context.Background(), "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "PUBLIC", s.Name)
+	require.Equal(t, []schema.Attr{&View{Name: "ACTIVE_USERS", Query: "select ID from USERS where BALANCE > 0", Secure: true}}, s.Attrs)
+	require.Len(t, s.Tables, 2)
+
+	events, ok := s.Table("EVENTS")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&Transient{}, &ClusterBy{Exprs: []string{"TO_DATE(CREATED)", "KIND"}}}, events.Attrs)
+	columns := []*schema.Column{
+		{Name: "ID", Type: &schema.ColumnType{Raw: "NUMBER", Type: &schema.IntegerType{T: TypeNumber}}, Attrs: []schema.Attr{&Identity{Start: 1, Increment: 1}}},
+		{Name: "USER_ID", Type: &schema.ColumnType{Raw: "NUMBER", Null: true, Type: &schema.IntegerType{T: TypeNumber}}},
+		{Name: "KIND", Type: &schema.ColumnType{Raw: "TEXT", Type: &schema.StringType{T: TypeVarchar, Size: 16777216}}, Default: &schema.Literal{V: "'click'"}},
+		{Name: "PAYLOAD", Type: &schema.ColumnType{Raw: "VARIANT", Null: true, Type: &schema.JSONType{T: TypeVariant}}, Attrs: []schema.Attr{&schema.Comment{Text: "event data"}}},
+		{Name: "CREATED", Type: &schema.ColumnType{Raw: "TIMESTAMP_NTZ", Type: &schema.TimeType{T: TypeTimestampNTZ, Precision: 9}}, Default: &schema.RawExpr{X: "CURRENT_TIMESTAMP()"}},
+	}
+	require.Len(t, events.Columns, len(columns))
+	for i, c := range columns {
+		require.EqualValues(t, c.Name, events.Columns[i].Name)
+		require.EqualValues(t, c.Type, events.Columns[i].Type)
+		require.EqualValues(t, c.Default, events.Columns[i].Default)
+		require.EqualValues(t, c.Attrs, events.Columns[i].Attrs)
+	}
+	require.Equal(t, "SYS_CONSTRAINT_1", events.PrimaryKey.Name)
+
+	users, ok := s.Table("USERS")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "app users"}}, users.Attrs)
+	require.Equal(t, []schema.Attr{&Identity{Start: 100, Increment: 10}}, users.Columns[0].Attrs)
+	require.Equal(t, []schema.Attr{&schema.Collation{V: "en-ci"}}, users.Columns[1].Attrs)
+	require.Equal(t, &schema.DecimalType{T: TypeNumber, Precision: 10, Scale: 2}, users.Columns[2].Type.Type)
+	require.Equal(t, &schema.SpatialType{T: TypeGeography}, users.Columns[3].Type.Type)
+	require.Equal(t, "USERS_PK", users.PrimaryKey.Name)
+	require.Equal(t, users.Columns[0], users.PrimaryKey.Parts[0].C)
+	require.Len(t, users.Indexes, 1)
+	require.True(t, users.Indexes[0].Unique)
+	require.Equal(t, users.Columns[1], users.Indexes[0].Parts[0].C)
+	require.Equal(t, users.Columns[3], users.Indexes[0].Parts[1].C)
+
+	require.Len(t, events.ForeignKeys, 1)
+	fk := events.ForeignKeys[0]
+	require.Equal(t, "EVENTS_FK", fk.Symbol)
+	require.Equal(t, users, fk.RefTable)
+	require.Equal(t, users.Columns[0], fk.RefColumns[0])
+	require.Equal(t, schema.Cascade, fk.OnDelete)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDriver_InspectSchema_NotExist(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.params()
+	mk.ExpectQuery(sqltest.Escape(`SELECT SCHEMA_NAME, IS_TRANSIENT, COMMENT FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ? ORDER BY SCHEMA_NAME`)).
+		WithArgs("OTHER").
+		WillReturnRows(sqlmock.NewRows([]string{"SCHEMA_NAME", "IS_TRANSIENT", "COMMENT"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	_, err = drv.InspectSchema(context.Background(), "OTHER", nil)
+	require.True(t, schema.IsNotExistError(err))
+}
+
+func TestOpen_NoDatabase(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(paramsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ CURRENT_VERSION() | CURRENT_DATABASE() | CURRENT_SCHEMA()
+-------------------+--------------------+------------------
+ 8.40.1            | nil                | nil
+`))
+	_, err = Open(db)
+	require.EqualError(t, err, "snowflake: connection has no current database")
+}
+
+func TestClusterExprs(t *testing.T) {
+	require.Equal(t, []string{"A"}, clusterExprs("LINEAR(A)"))
+	require.Equal(t, []string{"A", "SUBSTRING(B, 1, 2)", `"c,d"`}, clusterExprs(`LINEAR(A, SUBSTRING(B, 1, 2), "c,d")`))
+	require.Equal(t, []string{"A", "B"}, clusterExprs("A, B"))
+}
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want schema.Type
+	}{
+		{raw: "NUMBER", want: &schema.IntegerType{T: TypeNumber}},
+		{raw: "NUMBER(38,0)", want: &schema.IntegerType{T: TypeNumber}},
+		{raw: "NUMBER(10)", want: &schema.DecimalType{T: TypeNumber, Precision: 10}},
+		{raw: "DECIMAL(10, 2)", want: &schema.DecimalType{T: TypeNumber, Precision: 10, Scale: 2}},
+		{raw: "BIGINT", want: &schema.IntegerType{T: TypeBigInt}},
+		{raw: "DOUBLE PRECISION", want: &schema.FloatType{T: TypeDoublePrec}},
+		{raw: "VARCHAR(100)", want: &schema.StringType{T: TypeVarchar, Size: 100}},
+		{raw: "STRING", want: &schema.StringType{T: TypeString}},
+		{raw: "CHAR", want: &schema.StringType{T: TypeChar, Size: 1}},
+		{raw: "BINARY(16)", want: &schema.BinaryType{T: TypeBinary, Size: 16}},
+		{raw: "TIMESTAMP_TZ(3)", want: &schema.TimeType{T: TypeTimestampTZ, Precision: 3}},
+		{raw: "DATE", want: &schema.TimeType{T: TypeDate}},
+		{raw: "VARIANT", want: &schema.JSONType{T: TypeVariant}},
+		{raw: "ARRAY", want: &schema.JSONType{T: TypeArray}},
+		{raw: "GEOMETRY", want: &schema.SpatialType{T: TypeGeometry}},
+		{raw: "VECTOR(FLOAT, 256)", want: &schema.UnsupportedType{T: "VECTOR(FLOAT, 256)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			typ, err := ParseType(tt.raw)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, typ)
+		})
+	}
+	_, err := ParseType("NUMBER(x)")
+	require.Error(t, err)
+}
+
+func TestFormatType(t *testing.T) {
+	tests := []struct {
+		typ  schema.Type
+		want string
+	}{
+		{typ: &schema.IntegerType{T: TypeInt}, want: "NUMBER(38,0)"},
+		{typ: &schema.IntegerType{T: "bigint", Unsigned: true}, want: "NUMBER(38,0)"},
+		{typ: &schema.DecimalType{T: TypeDecimal, Precision: 10, Scale: 2}, want: "NUMBER(10,2)"},
+		{typ: &schema.DecimalType{T: TypeNumber}, want: "NUMBER"},
+		{typ: &schema.FloatType{T: "double"}, want: "FLOAT"},
+		{typ: &schema.StringType{T: "text"}, want: "VARCHAR"},
+		{typ: &schema.StringType{T: TypeVarchar, Size: 255}, want: "VARCHAR(255)"},
+		{typ: &schema.StringType{T: TypeChar, Size: 2}, want: "CHAR(2)"},
+		{typ: &schema.BinaryType{T: "bytea"}, want: "BINARY"},
+		{typ: &schema.BinaryType{T: TypeVarbinary, Size: 16}, want: "BINARY(16)"},
+		{typ: &schema.TimeType{T: "datetime"}, want: "TIMESTAMP_NTZ"},
+		{typ: &schema.TimeType{T: TypeTimestampLTZ, Precision: 3}, want: "TIMESTAMP_LTZ(3)"},
+		{typ: &schema.JSONType{T: "jsonb"}, want: "VARIANT"},
+		{typ: &schema.JSONType{T: TypeObject}, want: "OBJECT"},
+		{typ: &schema.SpatialType{T: TypeGeometry}, want: "GEOMETRY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			f, err := FormatType(tt.typ)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, f)
+		})
+	}
+	_, err := FormatType(&schema.EnumType{Values: []string{"a"}})
+	require.Error(t, err)
+}
+
+type mock struct {
+	sqlmock.Sqlmock
+}
+
+func (m mock) params() {
+	m.ExpectQuery(sqltest.Escape(paramsQuery)).
+		WillReturnRows(sqltest.Rows(`
+ CURRENT_VERSION() | CURRENT_DATABASE() | CURRENT_SCHEMA()
+-------------------+--------------------+------------------
+ 8.40.1            | DB                 | PUBLIC
+`))
+}
@@ -0,0 +1,802 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package snowflake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// A planApply provides migration capabilities for schema elements.
+type planApply struct{ conn }
+
+// PlanChanges returns a migration plan for the given schema changes. Note that
+// DDL statements are committed implicitly in Snowflake, and the returned plan is
+// never transactional.
+func (p *planApply) PlanChanges(ctx context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+	s := &state{
+		conn: p.conn,
+		Plan: migrate.Plan{
+			Name:       name,
+			Reversible: true,
+		},
+	}
+	if err := s.plan(changes); err != nil {
+		return nil, err
+	}
+	for _, c := range s.Changes {
+		if c.Reverse == "" {
+			s.Reversible = false
+		}
+	}
+	return &s.Plan, nil
+}
+
+// ApplyChanges applies the changes on the database. An error is returned
+// if the driver is unable to produce a plan to do so, or one of the statements
+// is failed or unsupported.
+func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change) error {
+	return sqlx.ApplyChanges(ctx, changes, p)
+}
+
+// state represents the state of a planning. It is not part of
+// planApply so that multiple planning/applying can be called
+// in parallel.
+type state struct {
+	conn
+	migrate.Plan
+	// Views are created after all tables were created and modified,
+	// and dropped before the tables they may depend on.
+	views []*migrate.Change
+}
+
+// plan builds the migration plan of the changes. An error is
+// returned if one of the changes is not supported.
+func (s *state) plan(changes []schema.Change) error {
+	planned, err := s.topLevel(changes)
+	if err != nil {
+		return err
+	}
+	if planned, err = sqlx.DetachCycles(planned); err != nil {
+		return err
+	}
+	for _, c := range planned {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			err = s.addTable(c)
+		case *schema.DropTable:
+			err = s.dropTable(c)
+		case *schema.ModifyTable:
+			err = s.modifyTable(c)
+		default:
+			err = fmt.Errorf("unsupported change %T", c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	s.append(s.views...)
+	return nil
+}
+
+// topLevel appends the schema changes and returns the rest of the changes.
+func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
+	planned := make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddSchema:
+			b := Build("CREATE")
+			if sqlx.Has(c.S.Attrs, &Transient{}) {
+				b.P("TRANSIENT")
+			}
+			b.P("SCHEMA")
+			if sqlx.Has(c.Extra, &schema.IfNotExists{}) {
+				b.P("IF NOT EXISTS")
+			}
+			b.Ident(c.S.Name)
+			if x := (schema.Comment{}); sqlx.Has(c.S.Attrs, &x) && x.Text != "" {
+				b.P("COMMENT =", quote(x.Text))
+			}
+			s.append(&migrate.Change{
+				Cmd:     b.String(),
+				Source:  c,
+				Reverse: Build("DROP SCHEMA").Ident(c.S.Name).String(),
+				Comment: fmt.Sprintf("Add new schema named %q", c.S.Name),
+			})
+			for _, v := range views(c.S.Attrs) {
+				s.views = append(s.views, viewChange(c, c.S, v))
+			}
+		case *schema.DropSchema:
+			b := Build("DROP SCHEMA")
+			if sqlx.Has(c.Extra, &schema.IfExists{}) {
+				b.P("IF EXISTS")
+			}
+			s.append(&migrate.Change{
+				Cmd:     b.Ident(c.S.Name).String(),
+				Source:  c,
+				Comment: fmt.Sprintf("Drop schema named %q", c.S.Name),
+			})
+		case *schema.ModifySchema:
+			if err := s.modifySchema(c); err != nil {
+				return nil, err
+			}
+		default:
+			planned = append(planned, c)
+		}
+	}
+	return planned, nil
+}
+
+// modifySchema builds and appends the migrate.Changes for bringing
+// the schema into its modified state.
+func (s *state) modifySchema(modify *schema.ModifySchema) error {
+	for _, change := range modify.Changes {
+		switch change := change.(type) {
+		case *schema.AddAttr:
+			switch a := change.A.(type) {
+			case *View:
+				s.views = append(s.views, viewChange(modify, modify.S, a))
+			case *schema.Comment:
+				s.append(schemaComment(modify, modify.S, a.Text, ""))
+			default:
+				return fmt.Errorf("unsupported schema attribute %T", a)
+			}
+		case *schema.DropAttr:
+			switch a := change.A.(type) {
+			case *View:
+				s.append(reverseChange(viewChange(modify, modify.S, a)))
+			case *schema.Comment:
+				s.append(schemaComment(modify, modify.S, "", a.Text))
+			default:
+				return fmt.Errorf("unsupported schema attribute %T", a)
+			}
+		case *schema.ModifyAttr:
+			switch to := change.To.(type) {
+			case *View:
+				from, ok := change.From.(*View)
+				if !ok {
+					return fmt.Errorf("unsupported ModifyAttr(%T, %T) change", change.From, change.To)
+				}
+				c := viewChange(modify, modify.S, to)
+				c.Reverse = viewChange(modify, modify.S, from).Cmd
+				s.views = append(s.views, c)
+			case *schema.Comment:
+				from, ok := change.From.(*schema.Comment)
+				if !ok {
+					return fmt.Errorf("unsupported ModifyAttr(%T, %T) change", change.From, change.To)
+				}
+				s.append(schemaComment(modify, modify.S, to.Text, from.Text))
+			default:
+				return fmt.Errorf("unsupported schema attribute %T", to)
+			}
+		default:
+			return fmt.Errorf("unsupported ModifySchema change: %T", change)
+		}
+	}
+	return nil
+}
+
+// addTable builds the statement for creating a table.
+func (s *state) addTable(add *schema.AddTable) error {
+	var (
+		errs []string
+		b    = Build("CREATE")
+	)
+	if sqlx.Has(add.T.Attrs, &Transient{}) {
+		b.P("TRANSIENT")
+	}
+	b.P("TABLE")
+	if sqlx.Has(add.Extra, &schema.IfNotExists{}) {
+		b.P("IF NOT EXISTS")
+	}
+	b.Table(add.T)
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(add.T.Columns, func(i int, b *sqlx.Builder) {
+			if err := s.column(b, add.T.Columns[i]); err != nil {
+				errs = append(errs, err.Error())
+			}
+		})
+		if pk := add.T.PrimaryKey; pk != nil {
+			b.Comma()
+			constraint(b, pk.Name).P("PRIMARY KEY")
+			indexParts(b, pk.Parts)
+		}
+		for _, idx := range add.T.Indexes {
+			if !idx.Unique {
+				errs = append(errs, fmt.Sprintf("non-unique index %q is not supported", idx.Name))
+				continue
+			}
+			b.Comma()
+			constraint(b, idx.Name).P("UNIQUE")
+			indexParts(b, idx.Parts)
+		}
+		for _, fk := range add.T.ForeignKeys {
+			b.Comma()
+			if err := s.fk(b, fk); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	})
+	for _, attr := range add.T.Attrs {
+		switch a := attr.(type) {
+		case *ClusterBy:
+			b.P("CLUSTER BY")
+			clusterExprsClause(b, a)
+		case *schema.Comment:
+			if a.Text != "" {
+				b.P("COMMENT =", quote(a.Text))
+			}
+		case *schema.Check:
+			errs = append(errs, fmt.Sprintf("CHECK constraint %q is not supported", a.Name))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errs, ", "))
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  add,
+		Comment: fmt.Sprintf("create %q table", add.T.Name),
+		Reverse: Build("DROP TABLE").Table(add.T).String(),
+	})
+	return nil
+}
+
+// dropTable builds the statement for dropping a table from a schema.
+func (s *state) dropTable(drop *schema.DropTable) error {
+	b := Build("DROP TABLE")
+	if sqlx.Has(drop.Extra, &schema.IfExists{}) {
+		b.P("IF EXISTS")
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.Table(drop.T).String(),
+		Source:  drop,
+		Comment: fmt.Sprintf("drop %q table", drop.T.Name),
+	})
+	return nil
+}
+
+// modifyTable builds the statements that bring the table into its modified state.
+// Snowflake does not allow mixing different actions in one ALTER TABLE statement.
+// Hence, the changes are planned in the following order: dropping constraints,
+// renaming columns, dropping, adding and altering columns, and then adding
+// constraints and changing the table attributes.
+func (s *state) modifyTable(modify *schema.ModifyTable) error {
+	var (
+		t                    = modify.T
+		drops, adds, attrs   []*migrate.Change
+		renames              []*migrate.Change
+		dropC, addC, modifyC []schema.Change
+	)
+	for _, change := range modify.Changes {
+		switch change := change.(type) {
+		case *schema.AddAttr, *schema.DropAttr, *schema.ModifyAttr:
+			c, err := tableAttrChange(t, change)
+			if err != nil {
+				return err
+			}
+			attrs = append(attrs, c)
+		case *schema.AddColumn:
+			addC = append(addC, change)
+		case *schema.DropColumn:
+			dropC = append(dropC, change)
+		case *schema.ModifyColumn:
+			modifyC = append(modifyC, change)
+		case *schema.RenameColumn:
+			renames = append(renames, &migrate.Change{
+				Cmd:     Build("ALTER TABLE").Table(t).P("RENAME COLUMN").Ident(change.From.Name).P("TO").Ident(change.To.Name).String(),
+				Source:  change,
+				Reverse: Build("ALTER TABLE").Table(t).P("RENAME COLUMN").Ident(change.To.Name).P("TO").Ident(change.From.Name).String(),
+				Comment: fmt.Sprintf("rename column %q to %q in table: %q", change.From.Name, change.To.Name, t.Name),
+			})
+		case *schema.AddIndex:
+			c, err := uniqueChange(change, t, change.I)
+			if err != nil {
+				return err
+			}
+			adds = append(adds, c)
+		case *schema.DropIndex:
+			c, err := uniqueChange(change, t, change.I)
+			if err != nil {
+				return err
+			}
+			drops = append(drops, reverseChange(c))
+		case *schema.ModifyIndex:
+			from, err := uniqueChange(change, t, change.From)
+			if err != nil {
+				return err
+			}
+			to, err := uniqueChange(change, t, change.To)
+			if err != nil {
+				return err
+			}
+			drops, adds = append(drops, reverseChange(from)), append(adds, to)
+		case *schema.AddForeignKey:
+			c, err := s.fkChange(change, t, change.F)
+			if err != nil {
+				return err
+			}
+			adds = append(adds, c)
+		case *schema.DropForeignKey:
+			c, err := s.fkChange(change, t, change.F)
+			if err != nil {
+				return err
+			}
+			drops = append(drops, reverseChange(c))
+		case *schema.ModifyForeignKey:
+			// Foreign-key modification is translated into 2 steps.
+			// Dropping the current foreign key and creating a new one.
+			from, err := s.fkChange(change, t, change.From)
+			if err != nil {
+				return err
+			}
+			to, err := s.fkChange(change, t, change.To)
+			if err != nil {
+				return err
+			}
+			drops, adds = append(drops, reverseChange(from)), append(adds, to)
+		case *schema.AddCheck, *schema.DropCheck, *schema.ModifyCheck:
+			return fmt.Errorf("snowflake: CHECK constraints are not supported (table %q)", t.Name)
+		default:
+			return fmt.Errorf("unsupported change type: %T", change)
+		}
+	}
+	s.append(drops...)
+	s.append(renames...)
+	if len(dropC) > 0 {
+		s.dropColumns(t, dropC)
+	}
+	if len(addC) > 0 {
+		if err := s.addColumns(t, addC); err != nil {
+			return err
+		}
+	}
+	if len(modifyC) > 0 {
+		if err := s.modifyColumns(t, modifyC); err != nil {
+			return err
+		}
+	}
+	s.append(adds...)
+	s.append(attrs...)
+	return nil
+}
+
+// dropColumns builds the statement for dropping the given columns.
+func (s *state) dropColumns(t *schema.Table, changes []schema.Change) {
+	b := Build("ALTER TABLE").Table(t).P("DROP COLUMN")
+	b.MapComma(changes, func(i int, b *sqlx.Builder) {
+		b.Ident(changes[i].(*schema.DropColumn).C.Name)
+	})
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  &schema.ModifyTable{T: t, Changes: changes},
+		Comment: fmt.Sprintf("drop columns from table: %q", t.Name),
+	})
+}
+
+// addColumns builds the statement for adding the given columns.
+func (s *state) addColumns(t *schema.Table, changes []schema.Change) error {
+	var (
+		errs    []string
+		b       = Build("ALTER TABLE").Table(t).P("ADD COLUMN")
+		reverse = Build("ALTER TABLE").Table(t).P("DROP COLUMN")
+	)
+	b.MapComma(changes, func(i int, b *sqlx.Builder) {
+		if err := s.column(b, changes[i].(*schema.AddColumn).C); err != nil {
+			errs = append(errs, err.Error())
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("alter table %q: %s", t.Name, strings.Join(errs, ", "))
+	}
+	reverse.MapComma(changes, func(i int, b *sqlx.Builder) {
+		b.Ident(changes[i].(*schema.AddColumn).C.Name)
+	})
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  &schema.ModifyTable{T: t, Changes: changes},
+		Comment: fmt.Sprintf("add columns to table: %q", t.Name),
+		Reverse: reverse.String(),
+	})
+	return nil
+}
+
+// modifyColumns builds the statement for modifying the given columns. All
+// modifications are written in one ALTER TABLE ... ALTER statement.
+func (s *state) modifyColumns(t *schema.Table, changes []schema.Change) error {
+	var (
+		errs    []string
+		b       = Build("ALTER TABLE").Table(t).P("ALTER")
+		reverse = Build("ALTER TABLE").Table(t).P("ALTER")
+	)
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(changes, func(i int, b *sqlx.Builder) {
+			c := changes[i].(*schema.ModifyColumn)
+			if err := s.alterColumn(b, c.Change, c.From, c.To); err != nil {
+				errs = append(errs, err.Error())
+			}
+		})
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("alter table %q: %s", t.Name, strings.Join(errs, ", "))
+	}
+	reverse.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(changes, func(i int, b *sqlx.Builder) {
+			c := changes[i].(*schema.ModifyColumn)
+			if err := s.alterColumn(b, c.Change, c.To, c.From); err != nil {
+				errs = append(errs, err.Error())
+			}
+		})
+	})
+	change := &migrate.Change{
+		Cmd:     b.String(),
+		Source:  &schema.ModifyTable{T: t, Changes: changes},
+		Comment: fmt.Sprintf("modify columns of table: %q", t.Name),
+	}
+	if len(errs) == 0 {
+		change.Reverse = reverse.String()
+	}
+	s.append(change)
+	return nil
+}
+
+// column writes the definition of the column to the builder.
+func (s *state) column(b *sqlx.Builder, c *schema.Column) error {
+	t, err := FormatType(c.Type.Type)
+	if err != nil {
+		return err
+	}
+	b.Ident(c.Name).P(t)
+	if x := (schema.Collation{}); sqlx.Has(c.Attrs, &x) && x.V != "" {
+		b.P("COLLATE", quote(x.V))
+	}
+	if id, ok := identity(c.Attrs); ok {
+		b.P(identityClause(id))
+	} else if x, ok := defaultValue(c); ok {
+		b.P("DEFAULT", x)
+	}
+	if !c.Type.Null {
+		b.P("NOT NULL")
+	}
+	if x := (schema.Comment{}); sqlx.Has(c.Attrs, &x) && x.Text != "" {
+		b.P("COMMENT", quote(x.Text))
+	}
+	for _, attr := range c.Attrs {
+		switch attr.(type) {
+		case *schema.Comment, *schema.Collation, *Identity:
+		default:
+			return fmt.Errorf("unexpected attribute %T for column %q", attr, c.Name)
+		}
+	}
+	return nil
+}
+
+// alterColumn writes the modification of the column to the builder. Snowflake supports
+// a limited set of column modifications: increasing the length or precision of a type,
+// changing the nullability, dropping the default value or setting it to a sequence, and
+// changing the comment. Other modifications require rewriting the table.
+func (s *state) alterColumn(b *sqlx.Builder, k schema.ChangeKind, from, to *schema.Column) error {
+	if k.Is(schema.ChangeAttr) {
+		return fmt.Errorf("changing the identity or collation of column %q is not supported", to.Name)
+	}
+	var clauses []string
+	if k.Is(schema.ChangeType) {
+		t, err := FormatType(to.Type.Type)
+		if err != nil {
+			return err
+		}
+		clauses = append(clauses, "SET DATA TYPE "+t)
+	}
+	if k.Is(schema.ChangeDefault) {
+		switch x, ok := defaultValue(to); {
+		case !ok:
+			clauses = append(clauses, "DROP DEFAULT")
+		case strings.HasSuffix(strings.ToUpper(x), ".NEXTVAL"):
+			clauses = append(clauses, "SET DEFAULT "+x)
+		default:
+			return fmt.Errorf("only sequence defaults can be set on existing column %q", to.Name)
+		}
+	}
+	if k.Is(schema.ChangeNull) {
+		if to.Type.Null {
+			clauses = append(clauses, "DROP NOT NULL")
+		} else {
+			clauses = append(clauses, "SET NOT NULL")
+		}
+	}
+	if k.Is(schema.ChangeComment) {
+		if x := (schema.Comment{}); sqlx.Has(to.Attrs, &x) && x.Text != "" {
+			clauses = append(clauses, "COMMENT "+quote(x.Text))
+		} else {
+			clauses = append(clauses, "UNSET COMMENT")
+		}
+	}
+	for i, c := range clauses {
+		if i > 0 {
+			b.Comma()
+		}
+		b.P("COLUMN").Ident(to.Name).P(c)
+	}
+	return nil
+}
+
+// identityClause returns the IDENTITY clause of the given identity.
+func identityClause(id *Identity) string {
+	start, incr := id.Start, id.Increment
+	if incr == 0 {
+		incr = 1
+	}
+	if start == 0 {
+		start = 1
+	}
+	return fmt.Sprintf("IDENTITY(%d,%d)", start, incr)
+}
+
+// identity returns the identity attribute of the column, if it has one.
+func identity(attrs []schema.Attr) (*Identity, bool) {
+	for _, a := range attrs {
+		if id, ok := a.(*Identity); ok {
+			return id, true
+		}
+	}
+	return nil, false
+}
+
+// defaultValue returns the DEFAULT clause of the column, if it has one.
+func defaultValue(c *schema.Column) (string, bool) {
+	switch x := c.Default.(type) {
+	case *schema.Literal:
+		switch c.Type.Type.(type) {
+		case *schema.BoolType, *schema.DecimalType, *schema.IntegerType, *schema.FloatType:
+			return x.V, true
+		default:
+			return quote(x.V), true
+		}
+	case *schema.RawExpr:
+		return x.X, true
+	default:
+		return "", false
+	}
+}
+
+// tableAttrChange returns the statement for changing a table attribute.
+func tableAttrChange(t *schema.Table, change schema.Change) (*migrate.Change, error) {
+	var from, to schema.Attr
+	switch change := change.(type) {
+	case *schema.AddAttr:
+		to = change.A
+	case *schema.DropAttr:
+		from = change.A
+	case *schema.ModifyAttr:
+		from, to = change.From, change.To
+	}
+	b := Build("ALTER TABLE").Table(t)
+	switch {
+	case isComment(from) || isComment(to):
+		var c1, c2 string
+		if c, ok := from.(*schema.Comment); ok {
+			c1 = c.Text
+		}
+		if c, ok := to.(*schema.Comment); ok {
+			c2 = c.Text
+		}
+		return &migrate.Change{
+			Cmd:     commentClause(b.Clone(), c2).String(),
+			Source:  change,
+			Comment: fmt.Sprintf("set comment to table: %q", t.Name),
+			Reverse: commentClause(b.Clone(), c1).String(),
+		}, nil
+	case isClusterBy(from) || isClusterBy(to):
+		return &migrate.Change{
+			Cmd:     clusterClause(b.Clone(), to).String(),
+			Source:  change,
+			Comment: fmt.Sprintf("set clustering key of table: %q", t.Name),
+			Reverse: clusterClause(b.Clone(), from).String(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported table attribute change %T", change)
+	}
+}
+
+// commentClause writes the SET or UNSET COMMENT clause to the builder.
+func commentClause(b *sqlx.Builder, text string) *sqlx.Builder {
+	if text == "" {
+		return b.P("UNSET COMMENT")
+	}
+	return b.P("SET COMMENT =", quote(text))
+}
+
+// clusterClause writes the CLUSTER BY or DROP CLUSTERING KEY clause to the builder.
+func clusterClause(b *sqlx.Builder, a schema.Attr) *sqlx.Builder {
+	c, ok := a.(*ClusterBy)
+	if !ok || len(c.Exprs) == 0 {
+		return b.P("DROP CLUSTERING KEY")
+	}
+	b.P("CLUSTER BY")
+	clusterExprsClause(b, c)
+	return b
+}
+
+// clusterExprsClause writes the expressions of the clustering key to the builder.
+func clusterExprsClause(b *sqlx.Builder, c *ClusterBy) {
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(c.Exprs, func(i int, b *sqlx.Builder) {
+			b.WriteString(c.Exprs[i])
+		})
+	})
+}
+
+func isComment(a schema.Attr) bool {
+	_, ok := a.(*schema.Comment)
+	return ok
+}
+
+func isClusterBy(a schema.Attr) bool {
+	_, ok := a.(*ClusterBy)
+	return ok
+}
+
+// schemaComment returns the statement for changing the comment of a schema.
+func schemaComment(source schema.Change, s *schema.Schema, to, from string) *migrate.Change {
+	b := Build("ALTER SCHEMA").Ident(s.Name)
+	return &migrate.Change{
+		Cmd:     commentClause(b.Clone(), to).String(),
+		Source:  source,
+		Comment: fmt.Sprintf("set comment to schema: %q", s.Name),
+		Reverse: commentClause(b.Clone(), from).String(),
+	}
+}
+
+// viewChange returns the statement for creating (or replacing) the given view.
+// The reverse statement drops it.
+func viewChange(source schema.Change, s *schema.Schema, v *View) *migrate.Change {
+	b := Build("CREATE OR REPLACE")
+	if v.Secure {
+		b.P("SECURE")
+	}
+	b.P("VIEW").P(object(s, v.Name))
+	if v.Comment != "" {
+		b.P("COMMENT =", quote(v.Comment))
+	}
+	b.P("AS", strings.TrimSuffix(strings.TrimSpace(v.Query), ";"))
+	return &migrate.Change{
+		Cmd:     b.String(),
+		Source:  source,
+		Comment: fmt.Sprintf("create view %q in schema: %q", v.Name, s.Name),
+		Reverse: Build("DROP VIEW").P(object(s, v.Name)).String(),
+	}
+}
+
+// uniqueChange returns the statement for adding the UNIQUE constraint of the given
+// index. The reverse statement drops it. Snowflake does not support indexes on
+// standard tables, and an error is returned for non-unique indexes.
+func uniqueChange(source schema.Change, t *schema.Table, idx *schema.Index) (*migrate.Change, error) {
+	if !idx.Unique {
+		return nil, fmt.Errorf("alter table %q: non-unique index %q is not supported", t.Name, idx.Name)
+	}
+	b := Build("ALTER TABLE").Table(t).P("ADD")
+	constraint(b, idx.Name).P("UNIQUE")
+	indexParts(b, idx.Parts)
+	return &migrate.Change{
+		Cmd:     b.String(),
+		Source:  source,
+		Comment: fmt.Sprintf("create unique constraint %q to table: %q", idx.Name, t.Name),
+		Reverse: Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(idx.Name).String(),
+	}, nil
+}
+
+// fkChange returns the statement for adding the given foreign key.
+// The reverse statement drops it.
+func (s *state) fkChange(source schema.Change, t *schema.Table, fk *schema.ForeignKey) (*migrate.Change, error) {
+	b := Build("ALTER TABLE").Table(t).P("ADD")
+	if err := s.fk(b, fk); err != nil {
+		return nil, fmt.Errorf("alter table %q: %w", t.Name, err)
+	}
+	return &migrate.Change{
+		Cmd:     b.String(),
+		Source:  source,
+		Comment: fmt.Sprintf("create foreign key %q to table: %q", fk.Symbol, t.Name),
+		Reverse: Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(fk.Symbol).String(),
+	}, nil
+}
+
+// fk writes the definition of the foreign key to the builder. Note that foreign
+// keys are not enforced by Snowflake, and their actions are informational.
+func (s *state) fk(b *sqlx.Builder, fk *schema.ForeignKey) error {
+	constraint(b, fk.Symbol).P("FOREIGN KEY")
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(fk.Columns, func(i int, b *sqlx.Builder) {
+			b.Ident(fk.Columns[i].Name)
+		})
+	})
+	b.P("REFERENCES").Table(fk.RefTable)
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(fk.RefColumns, func(i int, b *sqlx.Builder) {
+			b.Ident(fk.RefColumns[i].Name)
+		})
+	})
+	for _, a := range []struct {
+		clause string
+		action schema.ReferenceOption
+	}{{"ON UPDATE", fk.OnUpdate}, {"ON DELETE", fk.OnDelete}} {
+		switch a.action {
+		case "", schema.NoAction:
+		case schema.Cascade, schema.SetNull, schema.SetDefault, schema.Restrict:
+			b.P(a.clause, string(a.action))
+		default:
+			return fmt.Errorf("foreign key %q: unsupported %s action %q", fk.Symbol, a.clause, a.action)
+		}
+	}
+	return nil
+}
+
+// reverseChange returns a change that executes the
+// reverse statement of c, and reverses it with c.
+func reverseChange(c *migrate.Change) *migrate.Change {
+	comment := c.Comment
+	if i := strings.IndexByte(comment, ' '); i != -1 && comment[:i] == "create" {
+		comment = "drop" + comment[i:]
+	}
+	comment = strings.Replace(comment, " to table: ", " from table: ", 1)
+	return &migrate.Change{
+		Cmd:     c.Reverse,
+		Source:  c.Source,
+		Comment: strings.Replace(comment, " in schema: ", " from schema: ", 1),
+		Reverse: c.Cmd,
+	}
+}
+
+// constraint writes the CONSTRAINT clause of the given name, if
+// it was not generated by the database, to the builder.
+func constraint(b *sqlx.Builder, name string) *sqlx.Builder {
+	if name != "" && !isSystemName(name) {
+		b.P("CONSTRAINT").Ident(name)
+	}
+	return b
+}
+
+func indexParts(b *sqlx.Builder, parts []*schema.IndexPart) {
+	b.Wrap(func(b *sqlx.Builder) {
+		b.MapComma(parts, func(i int, b *sqlx.Builder) {
+			b.Ident(parts[i].C.Name)
+		})
+	})
+}
+
+func (s *state) append(c ...*migrate.Change) {
+	s.Changes = append(s.Changes, c...)
+}
+
+// Build instantiates a new builder and writes the given phrase to it.
+func Build(phrase string) *sqlx.Builder {
+	b := &sqlx.Builder{QuoteChar: '"'}
+	return b.P(phrase)
+}
+
+// object returns the name of a schema object (e.g. a view),
+// qualified with the schema name if it is known.
+func object(s *schema.Schema, name string) string {
+	if s != nil && s.Name != "" {
+		return ident(s.Name) + "." + ident(name)
+	}
+	return ident(name)
+}
+
+// ident returns the quoted form of the given identifier.
+func ident(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func quote(s string) string {
+	if sqlx.IsQuoted(s, '\'') {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,273 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package snowflake
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChanges(t *testing.T) {
+	public := &schema.Schema{Name: "PUBLIC"}
+	users := &schema.Table{
+		Name:   "USERS",
+		Schema: public,
+		Columns: []*schema.Column{
+			{Name: "ID", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&Identity{Start: 1, Increment: 1}}},
+			{Name: "NAME", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar, Size: 100}, Null: true}, Attrs: []schema.Attr{&schema.Collation{V: "en-ci"}, &schema.Comment{Text: "user's name"}}},
+			{Name: "DATA", Type: &schema.ColumnType{Type: &schema.JSONType{T: TypeVariant}, Null: true}},
+		},
+		Attrs: []schema.Attr{&ClusterBy{Exprs: []string{"NAME"}}, &schema.Comment{Text: "app users"}},
+	}
+	users.PrimaryKey = &schema.Index{Name: "USERS_PK", Parts: []*schema.IndexPart{{C: users.Columns[0]}}}
+	users.Indexes = []*schema.Index{
+		{Name: "USERS_NAME_UQ", Unique: true, Table: users, Parts: []*schema.IndexPart{{C: users.Columns[1]}}},
+	}
+	events := &schema.Table{
+		Name:   "EVENTS",
+		Schema: public,
+		Columns: []*schema.Column{
+			{Name: "ID", Type: &schema.ColumnType{Type: &schema.DecimalType{T: TypeNumber, Precision: 10}}},
+			{Name: "USER_ID", Type: &schema.ColumnType{Type: &schema.IntegerType{T: TypeNumber}, Null: true}},
+		},
+		Attrs: []schema.Attr{&Transient{}},
+	}
+	events.PrimaryKey = &schema.Index{Name: "SYS_CONSTRAINT_1", Parts: []*schema.IndexPart{{C: events.Columns[0]}}}
+	userFK := &schema.ForeignKey{Symbol: "USER_FK", Table: events, Columns: events.Columns[1:], RefTable: users, RefColumns: users.Columns[:1], OnDelete: schema.Cascade}
+	view := &View{Name: "ACTIVE", Query: "SELECT ID FROM USERS", Secure: true}
+
+	tests := []struct {
+		changes  []schema.Change
+		wantPlan *migrate.Plan
+		wantErr  bool
+	}{
+		{
+			changes: []schema.Change{&schema.AddSchema{S: &schema.Schema{Name: "STAGE", Attrs: []schema.Attr{&Transient{}, &schema.Comment{Text: "staging"}, view}}}},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TRANSIENT SCHEMA "STAGE" COMMENT = 'staging'`,
+						Reverse: `DROP SCHEMA "STAGE"`,
+					},
+					{
+						Cmd:     `CREATE OR REPLACE SECURE VIEW "STAGE"."ACTIVE" AS SELECT ID FROM USERS`,
+						Reverse: `DROP VIEW "STAGE"."ACTIVE"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.DropSchema{S: &schema.Schema{Name: "STAGE"}, Extra: []schema.Clause{&schema.IfExists{}}}},
+			wantPlan: &migrate.Plan{
+				Changes: []*migrate.Change{
+					{Cmd: `DROP SCHEMA IF EXISTS "STAGE"`},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: users}},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TABLE "PUBLIC"."USERS" ("ID" NUMBER(38,0) IDENTITY(1,1) NOT NULL, "NAME" VARCHAR(100) COLLATE 'en-ci' COMMENT 'user''s name', "DATA" VARIANT, CONSTRAINT "USERS_PK" PRIMARY KEY ("ID"), CONSTRAINT "USERS_NAME_UQ" UNIQUE ("NAME")) CLUSTER BY (NAME) COMMENT = 'app users'`,
+						Reverse: `DROP TABLE "PUBLIC"."USERS"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: events, Extra: []schema.Clause{&schema.IfNotExists{}}}},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `CREATE TRANSIENT TABLE IF NOT EXISTS "PUBLIC"."EVENTS" ("ID" NUMBER(10,0) NOT NULL, "USER_ID" NUMBER(38,0), PRIMARY KEY ("ID"))`,
+						Reverse: `DROP TABLE "PUBLIC"."EVENTS"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{&schema.AddTable{T: &schema.Table{Name: "T", Schema: public, Columns: users.Columns[:1], Attrs: []schema.Attr{&schema.Check{Expr: "ID > 0"}}}}},
+			wantErr: true,
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: events,
+					Changes: []schema.Change{
+						&schema.AddForeignKey{F: userFK},
+						&schema.AddColumn{C: &schema.Column{Name: "KIND", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar"}}, Default: &schema.Literal{V: "click"}}},
+						&schema.AddColumn{C: &schema.Column{Name: "PAYLOAD", Type: &schema.ColumnType{Type: &schema.JSONType{T: TypeObject}, Null: true}}},
+						&schema.AddAttr{A: &ClusterBy{Exprs: []string{"TO_DATE(CREATED)", "KIND"}}},
+						&schema.AddAttr{A: &schema.Comment{Text: "events"}},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "PUBLIC"."EVENTS" ADD COLUMN "KIND" VARCHAR DEFAULT 'click' NOT NULL, "PAYLOAD" OBJECT`,
+						Reverse: `ALTER TABLE "PUBLIC"."EVENTS" DROP COLUMN "KIND", "PAYLOAD"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "PUBLIC"."EVENTS" ADD CONSTRAINT "USER_FK" FOREIGN KEY ("USER_ID") REFERENCES "PUBLIC"."USERS" ("ID") ON DELETE CASCADE`,
+						Reverse: `ALTER TABLE "PUBLIC"."EVENTS" DROP CONSTRAINT "USER_FK"`,
+					},
+					{
+						Cmd:     `ALTER TABLE "PUBLIC"."EVENTS" CLUSTER BY (TO_DATE(CREATED), KIND)`,
+						Reverse: `ALTER TABLE "PUBLIC"."EVENTS" DROP CLUSTERING KEY`,
+					},
+					{
+						Cmd:     `ALTER TABLE "PUBLIC"."EVENTS" SET COMMENT = 'events'`,
+						Reverse: `ALTER TABLE "PUBLIC"."EVENTS" UNSET COMMENT`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.DropIndex{I: users.Indexes[0]},
+						&schema.DropColumn{C: users.Columns[2]},
+						&schema.RenameColumn{From: &schema.Column{Name: "NAME"}, To: &schema.Column{Name: "FULL_NAME"}},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "PUBLIC"."USERS" DROP CONSTRAINT "USERS_NAME_UQ"`,
+						Reverse: `ALTER TABLE "PUBLIC"."USERS" ADD CONSTRAINT "USERS_NAME_UQ" UNIQUE ("NAME")`,
+					},
+					{
+						Cmd:     `ALTER TABLE "PUBLIC"."USERS" RENAME COLUMN "NAME" TO "FULL_NAME"`,
+						Reverse: `ALTER TABLE "PUBLIC"."USERS" RENAME COLUMN "FULL_NAME" TO "NAME"`,
+					},
+					{
+						Cmd: `ALTER TABLE "PUBLIC"."USERS" DROP COLUMN "DATA"`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   users.Columns[1],
+							To:     &schema.Column{Name: "NAME", Type: &schema.ColumnType{Type: &schema.StringType{T: TypeVarchar, Size: 200}}, Attrs: []schema.Attr{&schema.Collation{V: "en-ci"}}},
+							Change: schema.ChangeType | schema.ChangeNull | schema.ChangeComment,
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "PUBLIC"."USERS" ALTER (COLUMN "NAME" SET DATA TYPE VARCHAR(200), COLUMN "NAME" SET NOT NULL, COLUMN "NAME" UNSET COMMENT)`,
+						Reverse: `ALTER TABLE "PUBLIC"."USERS" ALTER (COLUMN "NAME" SET DATA TYPE VARCHAR(100), COLUMN "NAME" DROP NOT NULL, COLUMN "NAME" COMMENT 'user''s name')`,
+					},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.ModifyColumn{
+							From:   users.Columns[2],
+							To:     &schema.Column{Name: "DATA", Type: &schema.ColumnType{Type: &schema.JSONType{T: TypeVariant}, Null: true}, Default: &schema.RawExpr{X: "PARSE_JSON('{}')"}},
+							Change: schema.ChangeDefault,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: users,
+					Changes: []schema.Change{
+						&schema.AddIndex{I: &schema.Index{Name: "USERS_DATA", Parts: []*schema.IndexPart{{C: users.Columns[2]}}}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifySchema{
+					S: public,
+					Changes: []schema.Change{
+						&schema.DropAttr{A: &View{Name: "OLD", Query: "SELECT 1"}},
+						&schema.ModifyAttr{From: view, To: &View{Name: "ACTIVE", Query: "SELECT ID, NAME FROM USERS", Comment: "active users"}},
+						&schema.AddAttr{A: &schema.Comment{Text: "public schema"}},
+					},
+				},
+				&schema.AddTable{T: events},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `DROP VIEW "PUBLIC"."OLD"`,
+						Reverse: `CREATE OR REPLACE VIEW "PUBLIC"."OLD" AS SELECT 1`,
+					},
+					{
+						Cmd:     `ALTER SCHEMA "PUBLIC" SET COMMENT = 'public schema'`,
+						Reverse: `ALTER SCHEMA "PUBLIC" UNSET COMMENT`,
+					},
+					{
+						Cmd:     `CREATE TRANSIENT TABLE "PUBLIC"."EVENTS" ("ID" NUMBER(10,0) NOT NULL, "USER_ID" NUMBER(38,0), PRIMARY KEY ("ID"))`,
+						Reverse: `DROP TABLE "PUBLIC"."EVENTS"`,
+					},
+					{
+						Cmd:     `CREATE OR REPLACE VIEW "PUBLIC"."ACTIVE" COMMENT = 'active users' AS SELECT ID, NAME FROM USERS`,
+						Reverse: `CREATE OR REPLACE SECURE VIEW "PUBLIC"."ACTIVE" AS SELECT ID FROM USERS`,
+					},
+				},
+			},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			db, m, err := sqlmock.New()
+			require.NoError(t, err)
+			mock{m}.params()
+			drv, err := Open(db)
+			require.NoError(t, err)
+			plan, err := drv.PlanChanges(context.Background(), "plan", tt.changes)
+			if tt.wantErr {
+				require.Error(t, err, "expect plan to fail")
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, plan)
+			require.Equal(t, tt.wantPlan.Reversible, plan.Reversible)
+			require.Len(t, plan.Changes, len(tt.wantPlan.Changes))
+			for i, c := range plan.Changes {
+				require.Equal(t, tt.wantPlan.Changes[i].Cmd, c.Cmd)
+				require.Equal(t, tt.wantPlan.Changes[i].Reverse, c.Reverse)
+			}
+		})
+	}
+}
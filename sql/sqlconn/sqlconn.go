@@ -0,0 +1,189 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqlconn allows running the Atlas drivers on database clients that are not
+// based on database/sql. For example, native pgx connections, query builders or
+// connection proxies. The client is expected to implement (or be wrapped by a thin
+// adapter that implements) the minimal ExecQuerier interface of this package, and
+// Open exposes it as a *sql.DB that can be passed to the Open function of the drivers.
+//
+//	db := sqlconn.Open(pgxAdapter{conn})
+//	drv, err := postgres.Open(db)
+//	if err != nil {
+//		return err
+//	}
+package sqlconn
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+)
+
+type (
+	// ExecQuerier is the minimal interface that is required for executing statements
+	// and queries on the database. Unlike schema.ExecQuerier, it is not tied to the
+	// types of the database/sql package.
+	ExecQuerier interface {
+		Exec(ctx context.Context, query string, args ...interface{}) (Result, error)
+		Query(ctx context.Context, query string, args ...interface{}) (Rows, error)
+	}
+
+	// Result summarizes an executed statement.
+	Result interface {
+		RowsAffected() (int64, error)
+	}
+
+	// Rows is the result of a query. Its cursor starts before the
+	// first row, and it is advanced using the Next method.
+	Rows interface {
+		// Columns returns the column names of the result.
+		Columns() ([]string, error)
+		// Next prepares the next row for reading by the Values method,
+		// and reports false if there are no more rows or an error occurred.
+		Next() bool
+		// Values returns the values of the current row. Values that are not of
+		// the types supported by database/sql/driver (e.g. int32) are converted
+		// using the driver.DefaultParameterConverter.
+		Values() ([]interface{}, error)
+		// Err returns the error, if any, that was encountered during iteration.
+		Err() error
+		// Close closes the rows.
+		Close() error
+	}
+)
+
+// Open returns a *sql.DB that executes all its statements and queries on the given
+// ExecQuerier, instead of opening connections to the database. The returned DB holds
+// at most one connection, as database clients usually do not support concurrent
+// statements on the same connection. Transactions and prepared statements are not
+// supported, and should be managed using the underlying client.
+func Open(eq ExecQuerier) *sql.DB {
+	db := sql.OpenDB(&connector{eq: eq})
+	db.SetMaxOpenConns(1)
+	return db
+}
+
+// ErrNotSupported is returned by the connections of the DB returned by Open for
+// operations that are not supported by them, like transactions.
+var ErrNotSupported = errors.New("sqlconn: operation is not supported")
+
+type (
+	connector struct{ eq ExecQuerier }
+	conn      struct{ eq ExecQuerier }
+	rows      struct {
+		Rows
+		columns []string
+	}
+	result struct{ Result }
+)
+
+// Connect implements the driver.Connector interface.
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{eq: c.eq}, nil
+}
+
+// Driver implements the driver.Connector interface.
+func (c *connector) Driver() driver.Driver {
+	return c
+}
+
+// Open implements the driver.Driver interface.
+func (c *connector) Open(string) (driver.Conn, error) {
+	return nil, ErrNotSupported
+}
+
+// Prepare implements the driver.Conn interface.
+func (*conn) Prepare(string) (driver.Stmt, error) {
+	return nil, ErrNotSupported
+}
+
+// Begin implements the driver.Conn interface.
+func (*conn) Begin() (driver.Tx, error) {
+	return nil, ErrNotSupported
+}
+
+// Close implements the driver.Conn interface. The underlying
+// ExecQuerier is owned by the caller, and it is not closed.
+func (*conn) Close() error {
+	return nil
+}
+
+// CheckNamedValue implements the driver.NamedValueChecker interface.
+// Arguments are passed to the underlying ExecQuerier as-is.
+func (*conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+// ExecContext implements the driver.ExecerContext interface.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res, err := c.eq.Exec(ctx, query, values(args)...)
+	if err != nil {
+		return nil, err
+	}
+	return result{res}, nil
+}
+
+// QueryContext implements the driver.QueryerContext interface.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r, err := c.eq.Query(ctx, query, values(args)...)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := r.Columns()
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return &rows{Rows: r, columns: columns}, nil
+}
+
+// Columns implements the driver.Rows interface.
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+// Next implements the driver.Rows interface.
+func (r *rows) Next(dest []driver.Value) error {
+	if !r.Rows.Next() {
+		if err := r.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	vs, err := r.Values()
+	if err != nil {
+		return err
+	}
+	for i := range dest {
+		if i >= len(vs) {
+			dest[i] = nil
+			continue
+		}
+		v := vs[i]
+		if !driver.IsValue(v) {
+			if v, err = driver.DefaultParameterConverter.ConvertValue(v); err != nil {
+				return err
+			}
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+// LastInsertId implements the driver.Result interface.
+func (result) LastInsertId() (int64, error) {
+	return 0, ErrNotSupported
+}
+
+// values returns the values of the given named arguments.
+func values(args []driver.NamedValue) []interface{} {
+	vs := make([]interface{}, len(args))
+	for i := range args {
+		vs[i] = args[i].Value
+	}
+	return vs
+}
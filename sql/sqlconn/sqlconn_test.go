@@ -0,0 +1,116 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlconn_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"ariga.io/atlas/sql/sqlconn"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	eq := &mockExecQuerier{
+		columns: []string{"name", "size", "comment"},
+		values: [][]interface{}{
+			{"users", int32(10), nil},
+			{"pets", int64(20), "pets table"},
+		},
+	}
+	db := sqlconn.Open(eq)
+	defer db.Close()
+
+	ctx := context.Background()
+	rows, err := db.QueryContext(ctx, "SELECT name, size, comment FROM tables WHERE schema = $1", "public")
+	require.NoError(t, err)
+	var (
+		names    []string
+		sizes    []int64
+		comments []sql.NullString
+	)
+	for rows.Next() {
+		var (
+			name    string
+			size    int64
+			comment sql.NullString
+		)
+		require.NoError(t, rows.Scan(&name, &size, &comment))
+		names, sizes, comments = append(names, name), append(sizes, size), append(comments, comment)
+	}
+	require.NoError(t, rows.Close())
+	require.Equal(t, []string{"users", "pets"}, names)
+	require.Equal(t, []int64{10, 20}, sizes)
+	require.Equal(t, []sql.NullString{{}, {String: "pets table", Valid: true}}, comments)
+	require.Equal(t, []interface{}{"public"}, eq.args)
+	require.True(t, eq.closed)
+
+	res, err := db.ExecContext(ctx, "DROP TABLE users")
+	require.NoError(t, err)
+	n, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.Equal(t, "DROP TABLE users", eq.stmt)
+
+	eq.err = errors.New("connection is closed")
+	_, err = db.ExecContext(ctx, "DROP TABLE pets")
+	require.EqualError(t, err, "connection is closed")
+
+	_, err = db.Begin()
+	require.ErrorIs(t, err, sqlconn.ErrNotSupported)
+}
+
+type (
+	mockExecQuerier struct {
+		columns []string
+		values  [][]interface{}
+		stmt    string
+		args    []interface{}
+		closed  bool
+		err     error
+	}
+	mockRows struct {
+		*mockExecQuerier
+		pos int
+	}
+	mockResult struct{}
+)
+
+func (m *mockExecQuerier) Exec(_ context.Context, query string, args ...interface{}) (sqlconn.Result, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.stmt, m.args = query, args
+	return mockResult{}, nil
+}
+
+func (m *mockExecQuerier) Query(_ context.Context, query string, args ...interface{}) (sqlconn.Rows, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.stmt, m.args = query, args
+	return &mockRows{mockExecQuerier: m}, nil
+}
+
+func (r *mockRows) Columns() ([]string, error) { return r.columns, nil }
+
+func (r *mockRows) Next() bool {
+	r.pos++
+	return r.pos <= len(r.values)
+}
+
+func (r *mockRows) Values() ([]interface{}, error) { return r.values[r.pos-1], nil }
+
+func (r *mockRows) Err() error { return nil }
+
+func (r *mockRows) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (mockResult) RowsAffected() (int64, error) { return 0, nil }
@@ -0,0 +1,562 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqlconvert provides an API for converting schemas between the
+// different database engines supported by Atlas. For example, converting a
+// realm that was inspected from a MySQL database to a realm that can be
+// planned and applied on a PostgreSQL database.
+//
+// The conversion maps column types, default values, index kinds and
+// auto-increment mechanisms to their equivalents in the target engine. Elements
+// that cannot be converted without losing data or semantics are converted to
+// their closest equivalent (or dropped), and reported to the caller.
+//
+// Note that SQLite does not enforce the length of string and binary types, and
+// therefore, these limits are not reported as lossy when SQLite is the target.
+package sqlconvert
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+)
+
+// Dialect names a database engine that is supported by Convert.
+type Dialect string
+
+// List of supported dialects.
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+type (
+	// Report describes the lossy conversions that were made by Convert.
+	Report struct {
+		Losses []*Loss
+	}
+
+	// A Loss describes a schema element that was converted to an equivalent
+	// that does not hold all its data or semantics, or that was dropped.
+	Loss struct {
+		// Schema and Table are the names of the schema and the table that
+		// contain the element. Table is empty for schema-level elements.
+		Schema, Table string
+		// Name is the name of the converted column, index or constraint.
+		// It is empty if the element is the table (or the schema) itself.
+		Name string
+		// Reason describes what was lost in the conversion.
+		Reason string
+	}
+)
+
+// String implements the fmt.Stringer interface.
+func (l *Loss) String() string {
+	path := make([]string, 0, 3)
+	for _, n := range []string{l.Schema, l.Table, l.Name} {
+		if n != "" {
+			path = append(path, n)
+		}
+	}
+	return fmt.Sprintf("%s: %s", strings.Join(path, "."), l.Reason)
+}
+
+// Convert converts the given realm, that was inspected from (or authored for) a
+// database of the "from" dialect, to a realm that can be applied on a database of
+// the "to" dialect. The realm is modified in place, and a report that describes
+// the lossy conversions is returned.
+//
+//	r, err := mysql.Driver.InspectRealm(ctx, nil)
+//	if err != nil {
+//		return err
+//	}
+//	report, err := sqlconvert.Convert(r, sqlconvert.MySQL, sqlconvert.Postgres)
+//	if err != nil {
+//		return err
+//	}
+//	for _, l := range report.Losses {
+//		log.Println(l)
+//	}
+//
+// An error is returned if one of the dialects is not supported, or if the realm
+// contains types that are unknown to Atlas (i.e. schema.UnsupportedType).
+func Convert(r *schema.Realm, from, to Dialect) (*Report, error) {
+	for _, d := range []Dialect{from, to} {
+		switch d {
+		case MySQL, Postgres, SQLite:
+		default:
+			return nil, fmt.Errorf("sqlconvert: unsupported dialect %q", d)
+		}
+	}
+	c := &converter{from: from, to: to, Report: &Report{}}
+	if from == to {
+		return c.Report, nil
+	}
+	for _, s := range r.Schemas {
+		s.Attrs = c.schemaAttrs(s)
+		for _, t := range s.Tables {
+			if err := c.table(t); err != nil {
+				return nil, err
+			}
+		}
+		if to == Postgres {
+			c.indexNames(s)
+		}
+	}
+	return c.Report, nil
+}
+
+// converter holds the state of a conversion.
+type converter struct {
+	from, to Dialect
+	*Report
+}
+
+// lossf records a lossy conversion of the given element.
+func (c *converter) lossf(t *schema.Table, name, format string, args ...interface{}) {
+	l := &Loss{Name: name, Reason: fmt.Sprintf(format, args...)}
+	if t != nil {
+		l.Table = t.Name
+		if t.Schema != nil {
+			l.Schema = t.Schema.Name
+		}
+	}
+	c.Losses = append(c.Losses, l)
+}
+
+// table converts the given table and its elements.
+func (c *converter) table(t *schema.Table) error {
+	autoinc := make(map[*schema.Column]int64)
+	for _, col := range t.Columns {
+		if start, ok := c.autoIncrement(t, col); ok {
+			autoinc[col] = start
+		}
+	}
+	for _, col := range t.Columns {
+		_, inc := autoinc[col]
+		typ, err := c.columnType(t, col, inc)
+		if err != nil {
+			return err
+		}
+		col.Type.Type = typ
+		c.columnDefault(t, col)
+		col.Attrs = c.columnAttrs(t, col)
+	}
+	t.Attrs = c.tableAttrs(t)
+	if t.PrimaryKey != nil {
+		c.index(t, t.PrimaryKey)
+	}
+	for _, idx := range t.Indexes {
+		c.index(t, idx)
+	}
+	for _, fk := range t.ForeignKeys {
+		c.foreignKey(t, fk)
+	}
+	for _, col := range t.Columns {
+		if start, ok := autoinc[col]; ok {
+			c.setAutoIncrement(t, col, start)
+		}
+	}
+	if c.to == Postgres {
+		if err := postgres.ConvertUnsigned(t, postgres.UnsignedWidenCheck); err != nil {
+			return err
+		}
+	}
+	for _, col := range t.Columns {
+		raw, err := c.formatType(col.Type.Type)
+		if err != nil {
+			return fmt.Errorf("sqlconvert: format type of column %q.%q: %w", t.Name, col.Name, err)
+		}
+		col.Type.Raw = raw
+	}
+	return nil
+}
+
+// formatType formats the given type using the target dialect.
+func (c *converter) formatType(t schema.Type) (string, error) {
+	switch c.to {
+	case MySQL:
+		return mysql.FormatType(t)
+	case Postgres:
+		return postgres.FormatType(t)
+	default:
+		return sqlite.FormatType(t)
+	}
+}
+
+// autoIncrement reports if the given column is an auto-increment column in the
+// source dialect, and returns the start value of its counter (or 0 if unknown).
+func (c *converter) autoIncrement(t *schema.Table, col *schema.Column) (int64, bool) {
+	switch c.from {
+	case MySQL:
+		if a := (&mysql.AutoIncrement{}); sqlx.Has(col.Attrs, a) {
+			if sqlx.Has(t.Attrs, a) {
+				return a.V, true
+			}
+			return 0, true
+		}
+	case Postgres:
+		for _, a := range col.Attrs {
+			if id, ok := a.(*postgres.Identity); ok {
+				if id.Sequence != nil {
+					return id.Sequence.Start, true
+				}
+				return 0, true
+			}
+		}
+		_, serial := col.Type.Type.(*postgres.SerialType)
+		if x, ok := col.Default.(*schema.RawExpr); ok && strings.HasPrefix(strings.ToLower(x.X), "nextval(") {
+			col.Default, serial = nil, true
+		}
+		if serial {
+			return 0, true
+		}
+	case SQLite:
+		inc := &sqlite.AutoIncrement{}
+		if sqlx.Has(col.Attrs, inc) || t.PrimaryKey != nil && len(t.PrimaryKey.Parts) == 1 && t.PrimaryKey.Parts[0].C == col && sqlx.Has(t.PrimaryKey.Attrs, inc) {
+			if inc.Seq > 0 {
+				return inc.Seq + 1, true
+			}
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+// setAutoIncrement configures the auto-increment mechanism of the target dialect on the given column.
+func (c *converter) setAutoIncrement(t *schema.Table, col *schema.Column, start int64) {
+	switch c.to {
+	case MySQL:
+		col.Attrs = append(col.Attrs, &mysql.AutoIncrement{})
+		if start > 1 {
+			t.Attrs = append(t.Attrs, &mysql.AutoIncrement{V: start})
+		}
+		if !indexed(t, col) {
+			c.lossf(t, col.Name, "AUTO_INCREMENT column must be defined as a key in MySQL")
+		}
+	case Postgres:
+		id := &postgres.Identity{Generation: "BY DEFAULT"}
+		if start > 1 {
+			id.Sequence = &postgres.Sequence{Start: start, Increment: 1}
+		}
+		col.Attrs = append(col.Attrs, id)
+	case SQLite:
+		if pk := t.PrimaryKey; pk == nil || len(pk.Parts) != 1 || pk.Parts[0].C != col {
+			c.lossf(t, col.Name, "AUTOINCREMENT is supported only for INTEGER PRIMARY KEY columns in SQLite")
+			return
+		}
+		col.Type.Type = &schema.IntegerType{T: sqlite.TypeInteger}
+		inc := &sqlite.AutoIncrement{}
+		if start > 1 {
+			inc.Seq = start - 1
+		}
+		col.Attrs = append(col.Attrs, inc)
+	}
+}
+
+// columnDefault converts the default value of the given column.
+func (c *converter) columnDefault(t *schema.Table, col *schema.Column) {
+	var x string
+	switch d := col.Default.(type) {
+	case *schema.Literal:
+		x = d.V
+	case *schema.RawExpr:
+		x = d.X
+	default:
+		return
+	}
+	n := c.normalizeDefault(x)
+	if _, ok := col.Type.Type.(*schema.BoolType); ok {
+		switch strings.ToUpper(strings.Trim(n, "'")) {
+		case "1", "TRUE", "T":
+			n = "TRUE"
+		case "0", "FALSE", "F":
+			n = "FALSE"
+		}
+	}
+	switch u := strings.ToUpper(n); {
+	case u == "NULL":
+		col.Default = &schema.RawExpr{X: u}
+	case u == "TRUE" || u == "FALSE":
+		if c.to != Postgres {
+			u = map[string]string{"TRUE": "1", "FALSE": "0"}[u]
+		}
+		col.Default = &schema.Literal{V: u}
+	case strings.HasPrefix(u, "CURRENT_") || strings.HasPrefix(u, "LOCAL"):
+		col.Default = &schema.RawExpr{X: c.timeDefault(col, u)}
+	case strings.HasPrefix(n, "'") || sqlx.IsLiteralNumber(n):
+		col.Default = &schema.Literal{V: n}
+	default:
+		col.Default = &schema.RawExpr{X: c.requote(x)}
+		c.lossf(t, col.Name, "default expression %q was copied as is", x)
+	}
+}
+
+// normalizeDefault normalizes the default value using the source dialect.
+func (c *converter) normalizeDefault(x string) string {
+	switch c.from {
+	case MySQL:
+		return mysql.NormalizeDefault(x)
+	case Postgres:
+		return postgres.NormalizeDefault(x)
+	default:
+		return sqlite.NormalizeDefault(x)
+	}
+}
+
+// timeDefault converts a normalized time function to its form in the target dialect.
+func (c *converter) timeDefault(col *schema.Column, x string) string {
+	name, p := x, ""
+	if i := strings.IndexByte(x, '('); i != -1 {
+		name, p = x[:i], x[i:]
+	}
+	switch name {
+	case "LOCALTIMESTAMP":
+		name = "CURRENT_TIMESTAMP"
+	case "LOCALTIME":
+		name = "CURRENT_TIME"
+	}
+	switch c.to {
+	case MySQL:
+		// MySQL requires the precision of the function to match the column precision,
+		// and accepts only CURRENT_TIMESTAMP as a literal default for temporal columns.
+		if tt, ok := col.Type.Type.(*schema.TimeType); ok && tt.Precision > 0 && name == "CURRENT_TIMESTAMP" {
+			return fmt.Sprintf("%s(%d)", name, tt.Precision)
+		}
+		if name != "CURRENT_TIMESTAMP" {
+			return "(" + name + ")"
+		}
+		return name
+	case Postgres:
+		return name + p
+	default:
+		return name
+	}
+}
+
+// schemaAttrs returns the schema attributes that are supported by the target dialect.
+func (c *converter) schemaAttrs(s *schema.Schema) []schema.Attr {
+	attrs := make([]schema.Attr, 0, len(s.Attrs))
+	for _, a := range s.Attrs {
+		switch a := a.(type) {
+		case *schema.Comment:
+			if c.to != SQLite {
+				attrs = append(attrs, a)
+			}
+		case *schema.Charset:
+			if c.to == MySQL {
+				attrs = append(attrs, a)
+			}
+		case *schema.Collation:
+			c.Losses = append(c.Losses, &Loss{Schema: s.Name, Reason: fmt.Sprintf("collation %q was dropped", a.V)})
+		}
+	}
+	return attrs
+}
+
+// tableAttrs returns the table attributes that are supported by the target dialect.
+func (c *converter) tableAttrs(t *schema.Table) []schema.Attr {
+	attrs := make([]schema.Attr, 0, len(t.Attrs))
+	for _, a := range t.Attrs {
+		switch a := a.(type) {
+		case *schema.Comment:
+			if c.to == SQLite {
+				c.lossf(t, "", "comment was dropped")
+				continue
+			}
+			attrs = append(attrs, a)
+		case *schema.Check:
+			attrs = append(attrs, c.check(t, a))
+		case *schema.Charset:
+			c.charset(t, "", a)
+		case *schema.Collation:
+			c.lossf(t, "", "collation %q was dropped", a.V)
+		case *mysql.CreateOptions:
+			if a.V != "" {
+				c.lossf(t, "", "table options %q were dropped", a.V)
+			}
+		}
+	}
+	return attrs
+}
+
+// columnAttrs returns the column attributes that are supported by the target dialect.
+func (c *converter) columnAttrs(t *schema.Table, col *schema.Column) []schema.Attr {
+	attrs := make([]schema.Attr, 0, len(col.Attrs))
+	for _, a := range col.Attrs {
+		switch a := a.(type) {
+		case *schema.Comment:
+			if c.to == SQLite {
+				c.lossf(t, col.Name, "comment was dropped")
+				continue
+			}
+			attrs = append(attrs, a)
+		case *schema.Charset:
+			c.charset(t, col.Name, a)
+		case *schema.Collation:
+			c.lossf(t, col.Name, "collation %q was dropped", a.V)
+		case *mysql.OnUpdate:
+			c.lossf(t, col.Name, "ON UPDATE %s was dropped", a.A)
+		}
+	}
+	return attrs
+}
+
+// charset reports the given charset as lost, unless it is a UTF-8 charset, which is
+// the common encoding of PostgreSQL and SQLite databases.
+func (c *converter) charset(t *schema.Table, name string, a *schema.Charset) {
+	if !strings.HasPrefix(strings.ToLower(a.V), "utf8") {
+		c.lossf(t, name, "character set %q was dropped", a.V)
+	}
+}
+
+// check converts the given CHECK constraint.
+func (c *converter) check(t *schema.Table, ck *schema.Check) *schema.Check {
+	for _, a := range ck.Attrs {
+		if e, ok := a.(*mysql.Enforced); ok && !e.V {
+			c.lossf(t, ck.Name, "NOT ENFORCED constraint is enforced in %s", c.to)
+		}
+	}
+	return &schema.Check{Name: ck.Name, Expr: c.requote(ck.Expr)}
+}
+
+// index converts the attributes of the given index and its parts.
+func (c *converter) index(t *schema.Table, idx *schema.Index) {
+	attrs := make([]schema.Attr, 0, len(idx.Attrs))
+	for _, a := range idx.Attrs {
+		switch a := a.(type) {
+		case *schema.Comment:
+			if c.to != SQLite {
+				attrs = append(attrs, a)
+			}
+		case *mysql.IndexType:
+			attrs = c.indexType(t, idx, attrs, a.T)
+		case *postgres.IndexType:
+			attrs = c.indexType(t, idx, attrs, a.T)
+		case *postgres.IndexPredicate:
+			attrs = c.indexPredicate(t, idx, attrs, a.P)
+		case *sqlite.IndexPredicate:
+			attrs = c.indexPredicate(t, idx, attrs, a.P)
+		}
+	}
+	idx.Attrs = attrs
+	for _, p := range idx.Parts {
+		for _, a := range p.Attrs {
+			if s, ok := a.(*mysql.SubPart); ok {
+				c.lossf(t, idx.Name, "prefix length (%d) of index part %d was dropped", s.Len, p.SeqNo)
+			}
+		}
+		p.Attrs = nil
+		if x, ok := p.X.(*schema.RawExpr); ok {
+			p.X = &schema.RawExpr{X: c.requote(x.X)}
+			c.lossf(t, idx.Name, "index expression %q was copied as is", x.X)
+		}
+	}
+}
+
+// indexType converts the given index type, and appends it to the attributes if it is not the default.
+func (c *converter) indexType(t *schema.Table, idx *schema.Index, attrs []schema.Attr, typ string) []schema.Attr {
+	switch u := strings.ToUpper(typ); {
+	case u == "BTREE":
+	case u == "HASH" && c.to == MySQL:
+		attrs = append(attrs, &mysql.IndexType{T: u})
+	case u == "HASH" && c.to == Postgres:
+		attrs = append(attrs, &postgres.IndexType{T: u})
+	default:
+		c.lossf(t, idx.Name, "index type %s was converted to BTREE", u)
+	}
+	return attrs
+}
+
+// indexPredicate converts the given partial index predicate.
+func (c *converter) indexPredicate(t *schema.Table, idx *schema.Index, attrs []schema.Attr, p string) []schema.Attr {
+	switch c.to {
+	case Postgres:
+		attrs = append(attrs, &postgres.IndexPredicate{P: c.requote(p)})
+	case SQLite:
+		attrs = append(attrs, &sqlite.IndexPredicate{P: c.requote(p)})
+	default:
+		c.lossf(t, idx.Name, "partial index predicate %q was dropped", p)
+	}
+	return attrs
+}
+
+// foreignKey converts the referential actions and the match type of the given foreign key.
+func (c *converter) foreignKey(t *schema.Table, fk *schema.ForeignKey) {
+	// MySQL and SQLite parse the MATCH clause, but ignore it.
+	if fk.Match != "" && c.to != Postgres {
+		if fk.Match != schema.MatchSimple {
+			c.lossf(t, fk.Symbol, "MATCH %s was dropped", fk.Match)
+		}
+		fk.Match = ""
+	}
+	if c.to != MySQL {
+		return
+	}
+	// The InnoDB engine rejects SET DEFAULT actions.
+	for _, a := range []*schema.ReferenceOption{&fk.OnUpdate, &fk.OnDelete} {
+		if *a == schema.SetDefault {
+			*a = schema.NoAction
+			c.lossf(t, fk.Symbol, "SET DEFAULT action was converted to NO ACTION")
+		}
+	}
+}
+
+// indexNames reports indexes that share the same name in the given schema, as index
+// names in PostgreSQL are unique per schema, and not per table as in MySQL and SQLite.
+func (c *converter) indexNames(s *schema.Schema) {
+	names := make(map[string]*schema.Table)
+	for _, t := range s.Tables {
+		for _, idx := range t.Indexes {
+			if other, ok := names[idx.Name]; ok && other != t {
+				c.lossf(t, idx.Name, "index name is already used by table %q", other.Name)
+				continue
+			}
+			names[idx.Name] = t
+		}
+	}
+}
+
+// requote converts the identifier quotes in the given expression to the quotes of the target dialect.
+// String literals (single-quoted) are copied as is.
+func (c *converter) requote(x string) string {
+	from, to := quoteChar(c.from), quoteChar(c.to)
+	if from == to || strings.IndexByte(x, from) == -1 {
+		return x
+	}
+	b := []byte(x)
+	for i, inStr := 0, false; i < len(b); i++ {
+		switch {
+		case b[i] == '\'':
+			inStr = !inStr
+		case b[i] == from && !inStr:
+			b[i] = to
+		}
+	}
+	return string(b)
+}
+
+// quoteChar returns the character that is used for quoting identifiers in the given dialect.
+func quoteChar(d Dialect) byte {
+	if d == Postgres {
+		return '"'
+	}
+	return '`'
+}
+
+// indexed reports if the given column is the first part of the primary key or one of the indexes.
+func indexed(t *schema.Table, col *schema.Column) bool {
+	for _, idx := range append([]*schema.Index{t.PrimaryKey}, t.Indexes...) {
+		if idx != nil && len(idx.Parts) > 0 && idx.Parts[0].C == col {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlconvert_test
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlconvert"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert_MySQLToPostgres(t *testing.T) {
+	users := mysqlUsers()
+	r := schema.NewRealm(schema.New("test").AddTables(users))
+	report, err := sqlconvert.Convert(r, sqlconvert.MySQL, sqlconvert.Postgres)
+	require.NoError(t, err)
+
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	m.ExpectQuery("SELECT setting FROM pg_settings").
+		WillReturnRows(sqlmock.NewRows([]string{"setting"}).AddRow("en_US.utf8").AddRow("en_US.utf8").AddRow("130000"))
+	m.ExpectQuery("SELECT \\* FROM pg_type").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}))
+	drv, err := postgres.Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.AddTable{T: users}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 4)
+	require.Equal(t, `CREATE TYPE "test"."users_status" AS ENUM ('active', 'deleted')`, plan.Changes[0].Cmd)
+	require.Equal(t, `CREATE TABLE "test"."users" (`+
+		`"id" bigint NOT NULL GENERATED BY DEFAULT AS IDENTITY (START WITH 100), `+
+		`"name" character varying(255) NOT NULL, `+
+		`"status" "test"."users_status" NOT NULL DEFAULT 'active', `+
+		`"active" boolean NOT NULL DEFAULT TRUE, `+
+		`"price" numeric(10,2) NOT NULL, `+
+		`"created_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP(6), `+
+		`"visits" bigint NOT NULL, `+
+		`"data" jsonb NULL, `+
+		`PRIMARY KEY ("id"), `+
+		`CONSTRAINT "positive" CHECK ("price" > 0), `+
+		`CONSTRAINT "users_price_unsigned" CHECK ("price" >= 0), `+
+		`CONSTRAINT "users_visits_unsigned" CHECK ("visits" BETWEEN 0 AND 4294967295))`, plan.Changes[1].Cmd)
+	require.Equal(t, `CREATE INDEX "name" ON "test"."users" ("name")`, plan.Changes[2].Cmd)
+	require.Equal(t, `COMMENT ON TABLE "test"."users" IS 'users table'`, plan.Changes[3].Cmd)
+
+	require.Equal(t, []string{
+		`test.users.id: bigint unsigned was converted to bigint`,
+		`test.users.name: collation "utf8mb4_bin" was dropped`,
+		`test.users.created_at: ON UPDATE CURRENT_TIMESTAMP(6) was dropped`,
+		`test.users: table options "ROW_FORMAT=COMPRESSED" were dropped`,
+		`test.users.name: index type FULLTEXT was converted to BTREE`,
+		`test.users.name: prefix length (10) of index part 1 was dropped`,
+	}, losses(report))
+}
+
+func TestConvert_PostgresToMySQL(t *testing.T) {
+	var (
+		id    = &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &postgres.SerialType{T: "bigserial"}}, Default: &schema.RawExpr{X: "nextval('pets_id_seq'::regclass)"}}
+		name  = &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}, Default: &schema.Literal{V: "'unknown'::text"}}
+		tags  = &schema.Column{Name: "tags", Type: &schema.ColumnType{Type: &postgres.ArrayType{T: "text[]"}, Null: true}}
+		owner = &schema.Column{Name: "owner", Type: &schema.ColumnType{Type: &postgres.UUIDType{T: "uuid"}}}
+		born  = &schema.Column{Name: "born", Type: &schema.ColumnType{Type: &schema.TimeType{T: "timestamp with time zone", Precision: 6}}, Default: &schema.RawExpr{X: "now()"}}
+		pets  = schema.NewTable("pets").AddColumns(id, name, tags, owner, born)
+	)
+	pets.SetPrimaryKey(schema.NewPrimaryKey(id))
+	pets.AddIndexes(schema.NewIndex("pets_name").AddColumns(name).AddAttrs(&postgres.IndexType{T: "GIN"}, &postgres.IndexPredicate{P: `"name" <> ''`}))
+	r := schema.NewRealm(schema.New("public").AddTables(pets))
+	report, err := sqlconvert.Convert(r, sqlconvert.Postgres, sqlconvert.MySQL)
+	require.NoError(t, err)
+
+	require.Equal(t, "bigint", id.Type.Raw)
+	require.Nil(t, id.Default)
+	require.Equal(t, []schema.Attr{&mysql.AutoIncrement{}}, id.Attrs)
+	require.Equal(t, "longtext", name.Type.Raw)
+	require.Equal(t, &schema.Literal{V: "'unknown'"}, name.Default)
+	require.Equal(t, "json", tags.Type.Raw)
+	require.Equal(t, "char(36)", owner.Type.Raw)
+	require.Equal(t, "timestamp(6)", born.Type.Raw)
+	require.Equal(t, &schema.RawExpr{X: "CURRENT_TIMESTAMP(6)"}, born.Default)
+	require.Empty(t, pets.Indexes[0].Attrs)
+	require.Equal(t, []string{
+		"public.pets.tags: array type text[] was converted to json",
+		"public.pets.born: timestamp with time zone was converted to timestamp that supports only the range of 1970-2038 (UTC)",
+		"public.pets.pets_name: index type GIN was converted to BTREE",
+		`public.pets.pets_name: partial index predicate "\"name\" <> ''" was dropped`,
+	}, losses(report))
+}
+
+func TestConvert_MySQLToSQLite(t *testing.T) {
+	users := mysqlUsers()
+	r := schema.NewRealm(schema.New("main").AddTables(users))
+	report, err := sqlconvert.Convert(r, sqlconvert.MySQL, sqlconvert.SQLite)
+	require.NoError(t, err)
+
+	raw := make([]string, len(users.Columns))
+	for i, c := range users.Columns {
+		raw[i] = c.Type.Raw
+	}
+	require.Equal(t, []string{"integer", "text", "text", "bool", "decimal", "datetime", "int", "json"}, raw)
+	require.Equal(t, []schema.Attr{&sqlite.AutoIncrement{Seq: 99}}, users.Columns[0].Attrs)
+	require.Equal(t, &schema.Literal{V: "1"}, users.Columns[3].Default)
+	require.Equal(t, &schema.RawExpr{X: "CURRENT_TIMESTAMP"}, users.Columns[5].Default)
+	var checks []string
+	for _, a := range users.Attrs {
+		if c, ok := a.(*schema.Check); ok {
+			checks = append(checks, c.Expr)
+		}
+	}
+	require.Equal(t, []string{"(`price` > 0)", "`status` IN ('active', 'deleted')", "`price` >= 0", "`visits` >= 0"}, checks)
+	require.Contains(t, losses(report), "main.users: comment was dropped")
+}
+
+func TestConvert_Errors(t *testing.T) {
+	_, err := sqlconvert.Convert(schema.NewRealm(), sqlconvert.MySQL, "oracle")
+	require.EqualError(t, err, `sqlconvert: unsupported dialect "oracle"`)
+
+	r := schema.NewRealm(schema.New("public").AddTables(
+		schema.NewTable("t").AddColumns(&schema.Column{Name: "c", Type: &schema.ColumnType{Type: &schema.UnsupportedType{T: "interval"}}}),
+	))
+	_, err = sqlconvert.Convert(r, sqlconvert.Postgres, sqlconvert.SQLite)
+	require.EqualError(t, err, `sqlconvert: unsupported type "interval" of column "t"."c"`)
+}
+
+func TestLoss_String(t *testing.T) {
+	l := &sqlconvert.Loss{Schema: "public", Table: "users", Name: "id", Reason: "dropped"}
+	require.Equal(t, "public.users.id: dropped", l.String())
+	l = &sqlconvert.Loss{Schema: "public", Reason: "dropped"}
+	require.Equal(t, "public: dropped", l.String())
+}
+
+func mysqlUsers() *schema.Table {
+	var (
+		id      = &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint", Unsigned: true}}, Attrs: []schema.Attr{&mysql.AutoIncrement{}}}
+		name    = &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}}, Attrs: []schema.Attr{&schema.Charset{V: "utf8mb4"}, &schema.Collation{V: "utf8mb4_bin"}}}
+		status  = &schema.Column{Name: "status", Type: &schema.ColumnType{Type: &schema.EnumType{T: "enum", Values: []string{"active", "deleted"}}}, Default: &schema.Literal{V: "'active'"}}
+		active  = &schema.Column{Name: "active", Type: &schema.ColumnType{Type: &schema.BoolType{T: "bool"}}, Default: &schema.Literal{V: "1"}}
+		price   = &schema.Column{Name: "price", Type: &schema.ColumnType{Type: &schema.DecimalType{T: "decimal", Precision: 10, Scale: 2, Unsigned: true}}}
+		created = &schema.Column{Name: "created_at", Type: &schema.ColumnType{Type: &schema.TimeType{T: "timestamp", Precision: 6}}, Default: &schema.RawExpr{X: "CURRENT_TIMESTAMP(6)"}, Attrs: []schema.Attr{&mysql.OnUpdate{A: "CURRENT_TIMESTAMP(6)"}}}
+		visits  = &schema.Column{Name: "visits", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "int", Unsigned: true}}}
+		data    = &schema.Column{Name: "data", Type: &schema.ColumnType{Type: &schema.JSONType{T: "json"}, Null: true}}
+		users   = schema.NewTable("users").AddColumns(id, name, status, active, price, created, visits, data)
+	)
+	users.SetPrimaryKey(schema.NewPrimaryKey(id))
+	users.AddIndexes(&schema.Index{
+		Name:  "name",
+		Attrs: []schema.Attr{&mysql.IndexType{T: "FULLTEXT"}},
+		Parts: []*schema.IndexPart{{SeqNo: 1, C: name, Attrs: []schema.Attr{&mysql.SubPart{Len: 10}}}},
+	})
+	users.AddAttrs(
+		&mysql.AutoIncrement{V: 100},
+		&mysql.CreateOptions{V: "ROW_FORMAT=COMPRESSED"},
+		&schema.Comment{Text: "users table"},
+		&schema.Check{Name: "positive", Expr: "(`price` > 0)", Attrs: []schema.Attr{&mysql.Enforced{V: true}}},
+	)
+	return users
+}
+
+func losses(r *sqlconvert.Report) []string {
+	s := make([]string, len(r.Losses))
+	for i, l := range r.Losses {
+		s[i] = l.String()
+	}
+	return s
+}
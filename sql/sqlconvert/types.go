@@ -0,0 +1,389 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlconvert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+)
+
+// columnType converts the type of the given column to a type of the target dialect.
+func (c *converter) columnType(t *schema.Table, col *schema.Column, autoinc bool) (schema.Type, error) {
+	if u, ok := col.Type.Type.(*schema.UnsupportedType); ok {
+		return nil, fmt.Errorf("sqlconvert: unsupported type %q of column %q.%q", u.T, t.Name, col.Name)
+	}
+	var typ schema.Type
+	switch c.to {
+	case MySQL:
+		typ = c.mysqlType(t, col)
+	case Postgres:
+		typ = c.postgresType(t, col, autoinc)
+	default:
+		typ = c.sqliteType(t, col, autoinc)
+	}
+	if typ == nil {
+		return nil, fmt.Errorf("sqlconvert: unexpected type %T of column %q.%q", col.Type.Type, t.Name, col.Name)
+	}
+	return typ, nil
+}
+
+// mysqlType converts the type of the given column to a MySQL type.
+func (c *converter) mysqlType(t *schema.Table, col *schema.Column) schema.Type {
+	switch x := col.Type.Type.(type) {
+	case *schema.BoolType:
+		return &schema.BoolType{T: mysql.TypeBool}
+	case *schema.IntegerType:
+		return &schema.IntegerType{T: mysqlInt(c.intSize(x.T))}
+	case *postgres.SerialType:
+		return &schema.IntegerType{T: mysqlInt(serialSize(x.T))}
+	case *schema.DecimalType:
+		d := &schema.DecimalType{T: mysql.TypeDecimal, Precision: x.Precision, Scale: x.Scale}
+		switch {
+		case d.Precision == 0 && d.Scale == 0:
+			d.Precision, d.Scale = 65, 30
+			c.lossf(t, col.Name, "unbounded %s was converted to decimal(65,30)", x.T)
+		case d.Precision > 65 || d.Scale > 30:
+			d.Precision, d.Scale = min(d.Precision, 65), min(d.Scale, 30)
+			c.lossf(t, col.Name, "%s(%d,%d) was converted to decimal(%d,%d)", x.T, x.Precision, x.Scale, d.Precision, d.Scale)
+		}
+		return d
+	case *schema.FloatType:
+		switch strings.ToLower(x.T) {
+		case postgres.TypeReal, postgres.TypeFloat4:
+			if c.from == Postgres {
+				return &schema.FloatType{T: mysql.TypeFloat}
+			}
+		}
+		return &schema.FloatType{T: mysql.TypeDouble}
+	case *schema.StringType:
+		switch n := strings.ToLower(x.T); {
+		case (n == postgres.TypeChar || n == postgres.TypeCharacter) && x.Size <= 255:
+			return &schema.StringType{T: mysql.TypeChar, Size: x.Size}
+		case n == postgres.TypeChar || n == postgres.TypeCharacter || x.Size > 16383:
+			c.lossf(t, col.Name, "%s(%d) was converted to longtext", n, x.Size)
+		case x.Size > 0:
+			return &schema.StringType{T: mysql.TypeVarchar, Size: x.Size}
+		}
+		return &schema.StringType{T: mysql.TypeLongText}
+	case *schema.BinaryType:
+		return &schema.BinaryType{T: mysql.TypeLongBlob}
+	case *schema.TimeType:
+		switch n := strings.ToLower(x.T); n {
+		case postgres.TypeDate:
+			return &schema.TimeType{T: mysql.TypeDate}
+		case postgres.TypeTimestampTZ, postgres.TypeTimestampWTZ:
+			c.lossf(t, col.Name, "%s was converted to timestamp that supports only the range of 1970-2038 (UTC)", n)
+			return &schema.TimeType{T: mysql.TypeTimestamp, Precision: x.Precision}
+		case postgres.TypeTime, postgres.TypeTimeWOTZ, postgres.TypeTimeWTZ:
+			if n == postgres.TypeTimeWTZ {
+				c.lossf(t, col.Name, "time zone of %s was dropped", n)
+			}
+			return &schema.TimeType{T: mysql.TypeTime, Precision: x.Precision}
+		default:
+			return &schema.TimeType{T: mysql.TypeDateTime, Precision: x.Precision}
+		}
+	case *schema.JSONType:
+		return &schema.JSONType{T: mysql.TypeJSON}
+	case *schema.EnumType:
+		return &schema.EnumType{T: mysql.TypeEnum, Values: x.Values}
+	case *schema.SpatialType:
+		typ := &schema.SpatialType{T: mysql.TypeGeometry}
+		switch n := strings.ToLower(x.T); n {
+		case postgres.TypePoint, postgres.TypePolygon:
+			typ.T = n
+		case postgres.TypeLine, postgres.TypeLseg, postgres.TypePath:
+			typ.T = mysql.TypeLineString
+		}
+		c.lossf(t, col.Name, "spatial type %s was converted to %s", x.T, typ.T)
+		return typ
+	case *postgres.UUIDType, *sqlite.UUIDType:
+		return &schema.StringType{T: mysql.TypeChar, Size: 36}
+	case *postgres.BitType:
+		n := x.Len
+		if strings.ToLower(x.T) == postgres.TypeBitVar || n > 64 {
+			n = 64
+			c.lossf(t, col.Name, "%s was converted to bit(64)", x.T)
+		}
+		if n <= 1 {
+			return &mysql.BitType{T: mysql.TypeBit}
+		}
+		return &mysql.BitType{T: fmt.Sprintf("%s(%d)", mysql.TypeBit, n)}
+	case *postgres.ArrayType:
+		c.lossf(t, col.Name, "array type %s was converted to json", x.T)
+		return &schema.JSONType{T: mysql.TypeJSON}
+	case *postgres.NetworkType:
+		c.lossf(t, col.Name, "network type %s was converted to varchar(43)", x.T)
+		return &schema.StringType{T: mysql.TypeVarchar, Size: 43}
+	case *postgres.CurrencyType:
+		c.lossf(t, col.Name, "currency type %s was converted to decimal(19,2)", x.T)
+		return &schema.DecimalType{T: mysql.TypeDecimal, Precision: 19, Scale: 2}
+	case *postgres.XMLType:
+		c.lossf(t, col.Name, "xml type was converted to longtext")
+		return &schema.StringType{T: mysql.TypeLongText}
+	case *postgres.UserDefinedType:
+		c.lossf(t, col.Name, "user-defined type %s was converted to longtext", x.T)
+		return &schema.StringType{T: mysql.TypeLongText}
+	}
+	return nil
+}
+
+// postgresType converts the type of the given column to a PostgreSQL type. Note that
+// unsigned integer types are converted later by postgres.ConvertUnsigned.
+func (c *converter) postgresType(t *schema.Table, col *schema.Column, autoinc bool) schema.Type {
+	switch x := col.Type.Type.(type) {
+	case *schema.BoolType:
+		return &schema.BoolType{T: postgres.TypeBoolean}
+	case *schema.IntegerType:
+		size := c.intSize(x.T)
+		switch {
+		case !x.Unsigned:
+			return &schema.IntegerType{T: postgresInt(size)}
+		// Identity columns cannot have a numeric type. Hence, unsigned
+		// types are widened to the next integer type, if there is one.
+		case autoinc && size == 8:
+			c.lossf(t, col.Name, "bigint unsigned was converted to bigint")
+			return &schema.IntegerType{T: postgres.TypeBigInt}
+		case autoinc:
+			return &schema.IntegerType{T: postgresInt(size + 1)}
+		default:
+			return &schema.IntegerType{T: mysqlInt(size), Unsigned: true}
+		}
+	case *schema.DecimalType:
+		c.unsignedCheck(t, col, x.Unsigned)
+		return &schema.DecimalType{T: postgres.TypeNumeric, Precision: x.Precision, Scale: x.Scale}
+	case *schema.FloatType:
+		c.unsignedCheck(t, col, x.Unsigned)
+		if n := strings.ToLower(x.T); c.from == MySQL && n == mysql.TypeFloat && x.Precision <= 24 {
+			return &schema.FloatType{T: postgres.TypeReal, Precision: 24}
+		}
+		return &schema.FloatType{T: postgres.TypeDouble, Precision: 53}
+	case *schema.StringType:
+		switch n := strings.ToLower(x.T); {
+		case (n == mysql.TypeChar || n == sqliteNChar) && x.Size > 0:
+			return &schema.StringType{T: postgres.TypeCharacter, Size: x.Size}
+		case x.Size > 0 && strings.Contains(n, "char"):
+			return &schema.StringType{T: postgres.TypeVarChar, Size: x.Size}
+		}
+		return &schema.StringType{T: postgres.TypeText}
+	case *schema.BinaryType:
+		return &schema.BinaryType{T: postgres.TypeBytea}
+	case *schema.TimeType:
+		// SQLite stores time values as text, and fractional seconds are kept.
+		p := x.Precision
+		if c.from == SQLite {
+			p = 6
+		}
+		switch n := strings.ToLower(x.T); n {
+		case mysql.TypeDate:
+			return &schema.TimeType{T: postgres.TypeDate}
+		case mysql.TypeTime:
+			return &schema.TimeType{T: postgres.TypeTimeWOTZ, Precision: p}
+		case mysql.TypeYear:
+			c.lossf(t, col.Name, "year was converted to smallint")
+			return &schema.IntegerType{T: postgres.TypeSmallInt}
+		case mysql.TypeTimestamp:
+			// MySQL converts TIMESTAMP values to UTC for storage.
+			if c.from == MySQL {
+				return &schema.TimeType{T: postgres.TypeTimestampWTZ, Precision: p}
+			}
+		}
+		return &schema.TimeType{T: postgres.TypeTimestampWOTZ, Precision: p}
+	case *schema.JSONType:
+		return &schema.JSONType{T: postgres.TypeJSONB}
+	case *schema.EnumType:
+		return &schema.EnumType{T: fmt.Sprintf("%s_%s", t.Name, col.Name), Values: x.Values}
+	case *schema.SpatialType:
+		c.lossf(t, col.Name, "spatial type %s requires the PostGIS extension, or has different semantics in PostgreSQL", x.T)
+		return &schema.SpatialType{T: strings.ToLower(x.T)}
+	case *mysql.BitType:
+		return &postgres.BitType{T: postgres.TypeBit, Len: bitLen(x.T)}
+	case *mysql.SetType:
+		c.lossf(t, col.Name, "set type was converted to text[] without restricting its values")
+		return &postgres.ArrayType{T: "text[]"}
+	case *sqlite.UUIDType:
+		return &postgres.UUIDType{T: postgres.TypeUUID}
+	}
+	return nil
+}
+
+// sqliteType converts the type of the given column to an SQLite type.
+func (c *converter) sqliteType(t *schema.Table, col *schema.Column, autoinc bool) schema.Type {
+	switch x := col.Type.Type.(type) {
+	case *schema.BoolType:
+		return &schema.BoolType{T: "bool"}
+	case *schema.IntegerType:
+		// Auto-increment values are always positive.
+		c.unsignedCheck(t, col, x.Unsigned && !autoinc)
+		if pk := t.PrimaryKey; pk != nil && len(pk.Parts) == 1 && pk.Parts[0].C == col {
+			return &schema.IntegerType{T: sqlite.TypeInteger}
+		}
+		return &schema.IntegerType{T: mysqlInt(c.intSize(x.T))}
+	case *postgres.SerialType:
+		return &schema.IntegerType{T: sqlite.TypeInteger}
+	case *schema.DecimalType:
+		c.unsignedCheck(t, col, x.Unsigned)
+		return &schema.DecimalType{T: "decimal", Precision: x.Precision, Scale: x.Scale}
+	case *schema.FloatType:
+		c.unsignedCheck(t, col, x.Unsigned)
+		return &schema.FloatType{T: sqlite.TypeReal}
+	case *schema.StringType:
+		return &schema.StringType{T: sqlite.TypeText}
+	case *schema.BinaryType:
+		return &schema.BinaryType{T: sqlite.TypeBlob}
+	case *schema.TimeType:
+		switch n := strings.ToLower(x.T); {
+		case n == mysql.TypeDate:
+			return &schema.TimeType{T: "date"}
+		case n == mysql.TypeYear:
+			c.lossf(t, col.Name, "year was converted to integer")
+			return &schema.IntegerType{T: sqlite.TypeInteger}
+		case strings.HasPrefix(n, mysql.TypeTime+" ") || n == mysql.TypeTime:
+			return &schema.TimeType{T: "time"}
+		}
+		return &schema.TimeType{T: "datetime"}
+	case *schema.JSONType:
+		return &schema.JSONType{T: "json"}
+	case *schema.EnumType:
+		values := make([]string, len(x.Values))
+		for i, v := range x.Values {
+			values[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		}
+		t.Attrs = append(t.Attrs, &schema.Check{
+			Name: fmt.Sprintf("%s_%s_enum", t.Name, col.Name),
+			Expr: sqlite.Build("").Ident(col.Name).P("IN").Wrap(func(b *sqlx.Builder) { b.P(strings.Join(values, ", ")) }).String(),
+		})
+		return &schema.StringType{T: sqlite.TypeText}
+	case *schema.SpatialType:
+		c.lossf(t, col.Name, "spatial type %s is stored as blob in SQLite", x.T)
+		return &schema.SpatialType{T: strings.ToLower(x.T)}
+	case *postgres.UUIDType:
+		return &sqlite.UUIDType{T: "uuid"}
+	case *mysql.BitType, *postgres.BitType:
+		c.lossf(t, col.Name, "bit type was converted to integer")
+		return &schema.IntegerType{T: sqlite.TypeInteger}
+	case *mysql.SetType:
+		c.lossf(t, col.Name, "set type was converted to text without restricting its values")
+		return &schema.StringType{T: sqlite.TypeText}
+	case *postgres.ArrayType:
+		c.lossf(t, col.Name, "array type %s was converted to json", x.T)
+		return &schema.JSONType{T: "json"}
+	case *postgres.NetworkType:
+		c.lossf(t, col.Name, "network type %s was converted to text", x.T)
+		return &schema.StringType{T: sqlite.TypeText}
+	case *postgres.CurrencyType:
+		c.lossf(t, col.Name, "currency type %s was converted to decimal", x.T)
+		return &schema.DecimalType{T: "decimal", Precision: 19, Scale: 2}
+	case *postgres.XMLType:
+		c.lossf(t, col.Name, "xml type was converted to text")
+		return &schema.StringType{T: sqlite.TypeText}
+	case *postgres.UserDefinedType:
+		c.lossf(t, col.Name, "user-defined type %s was converted to text", x.T)
+		return &schema.StringType{T: sqlite.TypeText}
+	}
+	return nil
+}
+
+// unsignedCheck adds a CHECK constraint that rejects negative values
+// to the table, in case the column type is unsigned.
+func (c *converter) unsignedCheck(t *schema.Table, col *schema.Column, unsigned bool) {
+	if !unsigned {
+		return
+	}
+	b := sqlite.Build("")
+	if c.to == Postgres {
+		b = postgres.Build("")
+	}
+	t.Attrs = append(t.Attrs, &schema.Check{
+		Name: fmt.Sprintf("%s_%s_unsigned", t.Name, col.Name),
+		Expr: b.Ident(col.Name).P(">= 0").String(),
+	})
+}
+
+// sqliteNChar is the SQLite name for fixed-length national character strings.
+const sqliteNChar = "nchar"
+
+// intSize returns the storage size in bytes of the given integer type in the source dialect.
+func (c *converter) intSize(t string) int {
+	switch t = strings.ToLower(t); t {
+	case mysql.TypeTinyInt:
+		return 1
+	case mysql.TypeSmallInt, postgres.TypeInt2:
+		return 2
+	case mysql.TypeMediumInt:
+		return 3
+	case mysql.TypeInt, postgres.TypeInteger, postgres.TypeInt4:
+		// INTEGER values are stored in 8 bytes in SQLite.
+		if c.from == SQLite && t == sqlite.TypeInteger {
+			return 8
+		}
+		return 4
+	default:
+		return 8
+	}
+}
+
+// serialSize returns the storage size in bytes of the given PostgreSQL serial type.
+func serialSize(t string) int {
+	switch strings.ToLower(t) {
+	case postgres.TypeSmallSerial, postgres.TypeSerial2:
+		return 2
+	case postgres.TypeSerial, postgres.TypeSerial4:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// mysqlInt returns the MySQL integer type for the given storage size.
+func mysqlInt(size int) string {
+	switch size {
+	case 1:
+		return mysql.TypeTinyInt
+	case 2:
+		return mysql.TypeSmallInt
+	case 3:
+		return mysql.TypeMediumInt
+	case 4:
+		return mysql.TypeInt
+	default:
+		return mysql.TypeBigInt
+	}
+}
+
+// postgresInt returns the smallest PostgreSQL integer type for the given storage size.
+func postgresInt(size int) string {
+	switch {
+	case size <= 2:
+		return postgres.TypeSmallInt
+	case size <= 4:
+		return postgres.TypeInteger
+	default:
+		return postgres.TypeBigInt
+	}
+}
+
+// bitLen extracts the length of a MySQL bit type (e.g. "bit(8)").
+func bitLen(t string) int64 {
+	if i := strings.IndexByte(t, '('); i != -1 {
+		if n, err := strconv.ParseInt(strings.TrimSuffix(t[i+1:], ")"), 10, 64); err == nil {
+			return n
+		}
+	}
+	return 1
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
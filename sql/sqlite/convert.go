@@ -9,14 +9,43 @@ import (
 	"strconv"
 	"strings"
 
+	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
 )
 
+// customTypes holds the types that were registered using RegisterType.
+var customTypes sqlx.CustomTypes
+
+// RegisterType registers a custom type, or an alias of a built-in type, in the driver. The parse
+// function is called with the raw definition of columns whose type is named as the registered
+// type (e.g. "vector" or "vector(3)"), on inspection and when parsing schema specs. The format
+// function is optional, and reports if it handles the given type and returns its raw definition.
+// Types that are handled by the format function are also compared by their formatted form.
+//
+//	sqlite.RegisterType("citext", func(string) (schema.Type, error) {
+//		return &schema.StringType{T: "citext"}, nil
+//	}, func(t schema.Type) (string, bool) {
+//		s, ok := t.(*schema.StringType)
+//		return "citext", ok && s.T == "citext"
+//	})
+//
+// Types are expected to be registered on init, before the driver is used.
+func RegisterType(name string, parse func(raw string) (schema.Type, error), format func(schema.Type) (string, bool)) error {
+	if name == "" || parse == nil {
+		return fmt.Errorf("sqlite: missing name or parse function for custom type %q", name)
+	}
+	customTypes.Register(&sqlx.CustomType{Name: name, Parse: parse, Format: format})
+	return registerSpec(name)
+}
+
 // FormatType converts types to one format. A lowered format.
 // This is due to SQLite flexibility to allow any data types
 // and use a set of rules to define the type affinity.
 // See: https://www.sqlite.org/datatype3.html
 func FormatType(t schema.Type) (string, error) {
+	if f, ok := customTypes.Format(t); ok {
+		return f, nil
+	}
 	var f string
 	switch t := t.(type) {
 	case *schema.BoolType:
@@ -41,8 +70,12 @@ func FormatType(t schema.Type) (string, error) {
 		f = strings.ToLower(t.T)
 	case *UUIDType:
 		f = strings.ToLower(t.T)
+	// Types that are unknown to the driver are passed as is.
 	case *schema.UnsupportedType:
-		return "", fmt.Errorf("sqlite: unsupported type: %q", t.T)
+		if t.T == "" {
+			return "", fmt.Errorf("sqlite: missing unsupported type definition")
+		}
+		f = t.T
 	default:
 		return "", fmt.Errorf("sqlite: invalid schema type: %T", t)
 	}
@@ -62,6 +95,9 @@ func mustFormat(t schema.Type) string {
 // It is expected to be one of the types in https://www.sqlite.org/datatypes.html,
 // or some of the common types used by ORMs like Ent.
 func ParseType(c string) (schema.Type, error) {
+	if t, ok, err := customTypes.Parse(c); ok {
+		return t, err
+	}
 	// A datatype may be zero or more names.
 	if c == "" {
 		return &schema.UnsupportedType{}, nil
@@ -17,10 +17,14 @@ import (
 // A diff provides a SQLite implementation for sqlx.DiffDriver.
 type diff struct{ conn }
 
+// ColumnOrder reports if the order of table columns is significant.
+func (d *diff) ColumnOrder() bool {
+	return d.ordered
+}
+
 // SchemaAttrDiff returns a changeset for migrating schema attributes from one state to the other.
-func (d *diff) SchemaAttrDiff(_, _ *schema.Schema) []schema.Change {
-	// No special schema attribute diffing for SQLite.
-	return nil
+func (d *diff) SchemaAttrDiff(from, to *schema.Schema) []schema.Change {
+	return pragmaDiff(from.Attrs, to.Attrs)
 }
 
 // TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
@@ -64,6 +68,17 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	if fromT == nil || toT == nil {
 		return false, fmt.Errorf("sqlite: missing type information for column %q", from.Name)
 	}
+	// Custom types are compared by their formatted form.
+	if f1, ok := customTypes.Format(fromT); ok {
+		f2, ok := customTypes.Format(toT)
+		return !ok || !strings.EqualFold(f1, f2), nil
+	}
+	// Types that are unknown to the driver are compared by their raw form.
+	if u1, ok := fromT.(*schema.UnsupportedType); ok {
+		if u2, ok := toT.(*schema.UnsupportedType); ok {
+			return !strings.EqualFold(u1.T, u2.T), nil
+		}
+	}
 	// Types are mismatched if they do not have the same "type affinity".
 	return reflect.TypeOf(fromT) != reflect.TypeOf(toT), nil
 }
@@ -76,7 +91,7 @@ func (d *diff) defaultChanged(from, to *schema.Column) bool {
 	if ok1 != ok2 {
 		return true
 	}
-	if d1 == d2 {
+	if d1 == d2 || NormalizeDefault(d1) == NormalizeDefault(d2) {
 		return false
 	}
 	x1, err1 := sqlx.Unquote(d1)
@@ -158,3 +173,21 @@ func sameFK(fk1, fk2 *schema.ForeignKey) bool {
 	}
 	return true
 }
+
+// NormalizeDefault returns a canonical form of the given DEFAULT expression. It can
+// be used for comparing DEFAULT values that are semantically identical but written
+// in a different form. For example, "(CURRENT_TIMESTAMP)" and "current_timestamp",
+// or "'a'" and "\"a\"".
+func NormalizeDefault(x string) string {
+	x = sqlx.TrimParens(x)
+	switch u := strings.ToUpper(x); u {
+	case "CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME", "NULL", "TRUE", "FALSE":
+		return u
+	}
+	if sqlx.IsQuoted(x, '"', '\'') {
+		if q, err := sqlx.SingleQuote(x); err == nil {
+			return q
+		}
+	}
+	return x
+}
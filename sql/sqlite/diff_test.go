@@ -286,3 +286,16 @@ func TestDiff_SchemaDiff(t *testing.T) {
 		&schema.AddTable{T: to.Tables[1]},
 	}, changes)
 }
+
+func TestNormalizeDefault(t *testing.T) {
+	for x, want := range map[string]string{
+		"current_timestamp":       "CURRENT_TIMESTAMP",
+		"(CURRENT_TIMESTAMP)":     "CURRENT_TIMESTAMP",
+		`"a"`:                     "'a'",
+		"('a')":                   "'a'",
+		"(datetime('now'))":       "datetime('now')",
+		"(strftime('%s', 'now'))": "strftime('%s', 'now')",
+	} {
+		require.Equal(t, want, NormalizeDefault(x), x)
+	}
+}
@@ -7,15 +7,27 @@ package sqlite
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/naming"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlmetrics"
+	"ariga.io/atlas/sql/sqltrace"
+
+	"golang.org/x/mod/semver"
 )
 
 type (
 	// Driver represents a SQLite driver for introspecting database schemas,
 	// generating diff between schema elements and apply migrations changes.
+	//
+	// A Driver is safe for concurrent use by multiple goroutines, as long as its
+	// underlying connection is (e.g. *sql.DB). Inspections, planning and applying
+	// of drivers that were opened on a single connection (i.e. *sql.Tx or *sql.Conn)
+	// are serialized. Note that diffing normalizes the given schema elements, and
+	// the same elements should not be diffed concurrently.
 	Driver struct {
 		conn
 		schema.Differ
@@ -23,6 +35,24 @@ type (
 		migrate.PlanApplier
 	}
 
+	// Option allows configuring the Driver using functional options.
+	Option func(*options)
+
+	// options holds the configuration of the Driver.
+	options struct {
+		trace    *sqltrace.Config
+		metrics  sqlmetrics.Recorder
+		version  string
+		log      sqlx.LogFunc
+		readOnly bool
+		batch    int64
+		ordered  bool
+		minQuote bool
+		pragmas  bool
+		namer    naming.Strategy
+		parsers  []sqlx.TypeParser
+	}
+
 	// database connection and its information.
 	conn struct {
 		schema.ExecQuerier
@@ -30,13 +60,52 @@ type (
 		fkEnabled  bool
 		version    string
 		collations []string
+		// The number of rows that are copied in each
+		// statement on table rewrites (0 means all).
+		batch int64
+		// Treat the order of table columns as significant.
+		ordered bool
+		// Quote only the identifiers that must be quoted.
+		minQuote bool
+		// Strategy for naming the unnamed objects of the planned changes.
+		namer naming.Strategy
+		// Parsers for column types that are not recognized by the driver.
+		parsers []sqlx.TypeParser
+		// Inspect the persistent PRAGMA settings of the database.
+		pragmas bool
+		// Opened on a single database connection (i.e. *sql.Tx or *sql.Conn),
+		// before it was wrapped by the options of the driver (e.g. WithReadOnly).
+		single bool
 	}
 )
 
 // Open opens a new SQLite driver.
-func Open(db schema.ExecQuerier) (*Driver, error) {
+func Open(db schema.ExecQuerier, opts ...Option) (*Driver, error) {
 	var (
-		c   = conn{ExecQuerier: db}
+		o      options
+		single = sqlx.SingleConn(db)
+	)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.readOnly {
+		db = sqlx.ReadOnlyExecQuerier(db)
+	}
+	if o.log != nil {
+		db = sqlx.LogExecQuerier(db, o.log)
+	}
+	var tracer *sqltrace.Tracer
+	if o.trace != nil {
+		tracer = sqltrace.New("sqlite", *o.trace)
+		db = tracer.ExecQuerier(db)
+	}
+	var metrics *sqlmetrics.Metrics
+	if o.metrics != nil {
+		metrics = sqlmetrics.New("sqlite", o.metrics)
+		db = metrics.ExecQuerier(db)
+	}
+	var (
+		c   = conn{ExecQuerier: db, single: single, batch: o.batch, ordered: o.ordered, minQuote: o.minQuote, namer: o.namer, parsers: o.parsers, pragmas: o.pragmas}
 		ctx = context.Background()
 	)
 	rows, err := db.QueryContext(ctx, "SELECT sqlite_version(), foreign_keys from pragma_foreign_keys")
@@ -46,18 +115,166 @@ func Open(db schema.ExecQuerier) (*Driver, error) {
 	if err := sqlx.ScanOne(rows, &c.version, &c.fkEnabled); err != nil {
 		return nil, fmt.Errorf("sqlite: scan version and foreign_keys pragma: %w", err)
 	}
+	if o.version != "" {
+		c.version = o.version
+	}
 	if rows, err = db.QueryContext(ctx, "SELECT name FROM pragma_collation_list()"); err != nil {
 		return nil, fmt.Errorf("sqlite: query foreign_keys pragma: %w", err)
 	}
 	if c.collations, err = sqlx.ScanStrings(rows); err != nil {
 		return nil, fmt.Errorf("sqlite: scanning database collations: %w", err)
 	}
-	return &Driver{
+	drv := &Driver{
 		conn:        c,
 		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
 		Inspector:   &inspect{c},
 		PlanApplier: &planApply{c},
-	}, nil
+	}
+	if single {
+		var mu sync.Mutex
+		drv.Inspector = sqlx.SerialInspector(drv.Inspector, &mu)
+		drv.PlanApplier = sqlx.SerialPlanApplier(drv.PlanApplier, &mu)
+	}
+	if tracer != nil {
+		drv.Differ = tracer.Differ(drv.Differ)
+		drv.Inspector = tracer.Inspector(drv.Inspector)
+		drv.PlanApplier = tracer.PlanApplier(drv.PlanApplier)
+	}
+	if metrics != nil {
+		drv.Differ = metrics.Differ(drv.Differ)
+		drv.Inspector = metrics.Inspector(drv.Inspector)
+		drv.PlanApplier = metrics.PlanApplier(drv.PlanApplier)
+	}
+	return drv, nil
+}
+
+// InspectTables implements the schema.TablesInspector interface. It streams the tables
+// of the connected database to fn, instead of loading the realm as a whole. See the
+// schema.TablesInspector for more info.
+func (d *Driver) InspectTables(ctx context.Context, opts *schema.InspectRealmOption, fn func(*schema.Table) error) error {
+	return schema.InspectTables(ctx, d.Inspector, opts, fn)
+}
+
+// WithTracing enables OpenTelemetry tracing for the driver. Spans are created
+// for schema inspection, diff computation and each executed statement.
+func WithTracing(cfg sqltrace.Config) Option {
+	return func(o *options) {
+		o.trace = &cfg
+	}
+}
+
+// WithMetrics reports the events of schema inspections, diff computations, planning,
+// and each executed statement of the driver to the given Recorder. For example, the
+// sqlmetrics.Collector exposes them as Prometheus metrics.
+func WithMetrics(r sqlmetrics.Recorder) Option {
+	return func(o *options) {
+		o.metrics = r
+	}
+}
+
+// WithVersion overrides the SQLite version that is detected on Open (e.g. "3.36.0").
+func WithVersion(v string) Option {
+	return func(o *options) {
+		o.version = v
+	}
+}
+
+// WithCopyBatchSize configures the PlanApplier to copy the rows of rewritten tables
+// (see the 12-step procedure in https://www.sqlite.org/lang_altertable.html) in chunks
+// of n rows, instead of one INSERT statement. When ApplyChanges is not called inside
+// a transaction, each chunk is committed separately, and the lock is released between
+// chunks, allowing other connections to access the database while large tables are
+// rewritten. Note that tables defined WITHOUT ROWID are copied in one statement.
+func WithCopyBatchSize(n int64) Option {
+	return func(o *options) {
+		o.batch = n
+	}
+}
+
+// WithColumnOrder configures the Differ to treat the order of table columns as
+// significant, and the PlanApplier to rewrite tables whose columns were moved, or
+// added before existing columns, as SQLite cannot reorder columns in place. Note
+// that such changes copy all rows of the table, as described in WithCopyBatchSize.
+func WithColumnOrder(b bool) Option {
+	return func(o *options) {
+		o.ordered = b
+	}
+}
+
+// WithMinimalQuoting configures the PlanApplier to quote identifiers only when it is
+// needed, that is, when they are reserved words or contain characters other than ASCII
+// letters, digits and underscores. By default, all identifiers are quoted.
+func WithMinimalQuoting(b bool) Option {
+	return func(o *options) {
+		o.minQuote = b
+	}
+}
+
+// WithNaming configures the PlanApplier to name the indexes, foreign keys and checks that
+// are added unnamed by the planned changes, using the given naming strategy. For example,
+// naming.Rails{}. Note that the objects are named in place, that is, the given changes are
+// modified. By default, unnamed objects are left for the database to name.
+func WithNaming(s naming.Strategy) Option {
+	return func(o *options) {
+		o.namer = s
+	}
+}
+
+// WithTypeParser registers a parser for column types that are not recognized by the
+// driver on inspection (e.g. types that are added by extensions or plugins). Parsers
+// are called in the order they were registered, and should return one of the types
+// that are supported by the driver. Types that are not handled by any of the parsers
+// are kept as schema.UnsupportedType, and compared and planned by their raw form.
+func WithTypeParser(p func(raw string) (schema.Type, bool)) Option {
+	return func(o *options) {
+		o.parsers = append(o.parsers, p)
+	}
+}
+
+// WithReadOnly opens the driver in read-only mode. In this mode, all statements that
+// may write to the database are rejected with a schema.ReadOnlyError before they are
+// sent to it, and the driver can be used only for inspecting the database. Note that
+// this is not a replacement for connecting with a read-only credential, but a guard
+// against writes that are attempted by the driver or by its users.
+func WithReadOnly(b bool) Option {
+	return func(o *options) {
+		o.readOnly = b
+	}
+}
+
+// WithPragmas configures the Inspector to inspect the PRAGMA settings of the database, and
+// add them to the attributes of the schemas (e.g. journal_mode) and the realm (foreign_keys)
+// as *Pragma. Pragmas are diffed and planned regardless of this option, and only the ones
+// that are declared by the desired state are compared. By default, pragmas are not inspected.
+func WithPragmas(b bool) Option {
+	return func(o *options) {
+		o.pragmas = b
+	}
+}
+
+// WithStatementLog calls f with each statement (and its arguments) that is
+// executed or queried by the driver, before it is sent to the database.
+func WithStatementLog(f func(ctx context.Context, stmt string, args []interface{})) Option {
+	return func(o *options) {
+		o.log = f
+	}
+}
+
+// supportsRenameColumn reports if the connected database
+// supports the ALTER TABLE RENAME COLUMN command (3.25.0).
+func (c *conn) supportsRenameColumn() bool {
+	return c.gteV("3.25.0")
+}
+
+// supportsDropColumn reports if the connected database
+// supports the ALTER TABLE DROP COLUMN command (3.35.0).
+func (c *conn) supportsDropColumn() bool {
+	return c.gteV("3.35.0")
+}
+
+// gteV reports if the connection version is >= w.
+func (c *conn) gteV(w string) bool {
+	return semver.Compare("v"+c.version, "v"+w) >= 0
 }
 
 // SQLite standard data types as defined in its codebase and documentation.
@@ -9,6 +9,7 @@ import (
 	"database/sql"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -32,6 +33,11 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 		return nil, fmt.Errorf("sqlite: multiple database files are not supported by the driver. got: %d", len(schemas))
 	}
 	realm := &schema.Realm{Schemas: schemas}
+	if i.pragmas {
+		if err := i.inspectPragmas(ctx, realm); err != nil {
+			return nil, err
+		}
+	}
 	for _, s := range schemas {
 		tables, err := i.tables(ctx, nil)
 		if err != nil {
@@ -51,6 +57,37 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 	return realm, nil
 }
 
+// InspectTables implements the schema.TablesInspector interface. Tables are inspected one
+// by one, and passed to fn without being added to their schema.
+func (i *inspect) InspectTables(ctx context.Context, opts *schema.InspectRealmOption, fn func(*schema.Table) error) error {
+	schemas, err := i.databases(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if len(schemas) > 1 {
+		return fmt.Errorf("sqlite: multiple database files are not supported by the driver. got: %d", len(schemas))
+	}
+	realm := &schema.Realm{Schemas: schemas}
+	for _, s := range schemas {
+		s.Realm = realm
+		tables, err := i.tables(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for _, t := range tables {
+			t.Schema = s
+			t, err := i.inspectTable(ctx, t)
+			if err != nil {
+				return err
+			}
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // InspectSchema returns schema descriptions of the tables in the given schema.
 // If the schema name is empty, the "main" database is used.
 func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
@@ -83,6 +120,11 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 	}
 	sqlx.LinkSchemaTables(schemas)
 	s.Realm = &schema.Realm{Schemas: schemas}
+	if i.pragmas {
+		if err := i.inspectPragmas(ctx, s.Realm); err != nil {
+			return nil, err
+		}
+	}
 	return s, nil
 }
 
@@ -114,13 +156,21 @@ func (i *inspect) columns(ctx context.Context, t *schema.Table) error {
 			return fmt.Errorf("sqlite: %w", err)
 		}
 	}
+	// Columns are ordered by their position in the table, and
+	// the primary key parts by their position in the key.
+	if t.PrimaryKey != nil {
+		sort.SliceStable(t.PrimaryKey.Parts, func(i, j int) bool {
+			return t.PrimaryKey.Parts[i].SeqNo < t.PrimaryKey.Parts[j].SeqNo
+		})
+	}
 	return autoinc(t)
 }
 
 // addColumn scans the current row and adds a new column from it to the table.
 func (i *inspect) addColumn(t *schema.Table, rows *sql.Rows) error {
 	var (
-		nullable, primary   bool
+		nullable            bool
+		primary             int
 		name, typ, defaults sql.NullString
 		err                 error
 	)
@@ -138,12 +188,17 @@ func (i *inspect) addColumn(t *schema.Table, rows *sql.Rows) error {
 	if err != nil {
 		return err
 	}
+	if _, ok := c.Type.Type.(*schema.UnsupportedType); ok {
+		if t, ok := sqlx.ParseCustomType(typ.String, i.parsers); ok {
+			c.Type.Type = t
+		}
+	}
 	if sqlx.ValidString(defaults) {
 		c.Default = defaultExpr(defaults.String)
 	}
 	// TODO(a8m): extract collation from 'CREATE TABLE' statement.
 	t.Columns = append(t.Columns, c)
-	if primary {
+	if primary > 0 {
 		if t.PrimaryKey == nil {
 			t.PrimaryKey = &schema.Index{
 				Name:   "PRIMARY",
@@ -151,10 +206,10 @@ func (i *inspect) addColumn(t *schema.Table, rows *sql.Rows) error {
 				Table:  t,
 			}
 		}
-		// Columns are ordered by the `pk` field.
+		// The `pk` field holds the 1-based position of the column in the key.
 		t.PrimaryKey.Parts = append(t.PrimaryKey.Parts, &schema.IndexPart{
 			C:     c,
-			SeqNo: len(t.PrimaryKey.Parts) + 1,
+			SeqNo: primary,
 		})
 	}
 	return nil
@@ -323,6 +378,7 @@ func (i *inspect) tables(ctx context.Context, opts *schema.InspectOptions) ([]*s
 			args = append(args, s)
 		}
 	}
+	query += " ORDER BY `name`"
 	rows, err := i.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("sqlite: querying schema tables: %w", err)
@@ -642,9 +698,9 @@ const (
 	// Query to list database tables.
 	tablesQuery = "SELECT `name`, `sql` FROM sqlite_master WHERE `type` = 'table' AND `name` NOT LIKE 'sqlite_%'"
 	// Query to list table information.
-	columnsQuery = "SELECT `name`, `type`, (not `notnull`) AS `nullable`, `dflt_value`, (`pk` <> 0) AS `pk`  FROM pragma_table_info('%s') ORDER BY `pk`, `cid`"
+	columnsQuery = "SELECT `name`, `type`, (not `notnull`) AS `nullable`, `dflt_value`, `pk` FROM pragma_table_info('%s') ORDER BY `cid`"
 	// Query to list table indexes.
-	indexesQuery = "SELECT `il`.`name`, `il`.`unique`, `il`.`origin`, `il`.`partial`, `m`.`sql` FROM pragma_index_list('%s') AS il JOIN sqlite_master AS m ON il.name = m.name"
+	indexesQuery = "SELECT `il`.`name`, `il`.`unique`, `il`.`origin`, `il`.`partial`, `m`.`sql` FROM pragma_index_list('%s') AS il JOIN sqlite_master AS m ON il.name = m.name ORDER BY `il`.`name`"
 	// Query to list index columns.
 	indexColumnsQuery = "SELECT name, desc FROM pragma_index_xinfo('%s') WHERE key = 1 ORDER BY seqno"
 	// Query to list table foreign-keys.
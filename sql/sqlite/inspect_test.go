@@ -6,6 +6,7 @@ package sqlite
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -71,6 +72,31 @@ func TestDriver_InspectTable(t *testing.T) {
 				}, t.PrimaryKey)
 			},
 		},
+		{
+			name: "composite primary key",
+			before: func(m mock) {
+				m.tableExists("users", true, "CREATE TABLE users(name TEXT, owner_id INTEGER, PRIMARY KEY(owner_id, name))")
+				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, "users"))).
+					WillReturnRows(sqltest.Rows(`
+ name     |   type   | nullable | dflt_value  | primary
+----------+----------+----------+-------------+----------
+ name     | text     |  0       |             |  2
+ owner_id | integer  |  0       |             |  1
+`))
+				m.noIndexes("users")
+				m.noFKs("users")
+			},
+			expect: func(require *require.Assertions, t *schema.Table, err error) {
+				require.NoError(err)
+				require.Len(t.Columns, 2)
+				require.Equal("name", t.Columns[0].Name)
+				require.Equal("owner_id", t.Columns[1].Name)
+				require.Equal([]*schema.IndexPart{
+					{SeqNo: 1, C: t.Columns[1]},
+					{SeqNo: 2, C: t.Columns[0]},
+				}, t.PrimaryKey.Parts)
+			},
+		},
 		{
 			name: "table indexes",
 			before: func(m mock) {
@@ -217,6 +243,67 @@ CREATE TABLE users(
 	}
 }
 
+func TestDriver_InspectTables(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.systemVars("3.36.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(databasesQuery)).
+		WillReturnRows(sqltest.Rows(`
+ name |   file
+------+-----------
+ main | app.db
+`))
+	m.ExpectQuery(sqltest.Escape(tablesQuery + " ORDER BY `name`")).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "sql"}).
+			AddRow("pets", "CREATE TABLE pets(id INTEGER PRIMARY KEY)").
+			AddRow("users", "CREATE TABLE users(id INTEGER PRIMARY KEY)"))
+	for _, name := range []string{"pets", "users"} {
+		m.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsQuery, name))).
+			WillReturnRows(sqltest.Rows(`
+ name |   type   | nullable | dflt_value  | primary
+------+----------+----------+-------------+----------
+ id   | integer  |  0       |             |  1
+`))
+		mk.noIndexes(name)
+		mk.noFKs(name)
+	}
+	var names []string
+	err = drv.InspectTables(context.Background(), nil, func(tt *schema.Table) error {
+		require.Equal(t, "main", tt.Schema.Name)
+		require.Empty(t, tt.Schema.Tables, "streamed tables are not held by their schema")
+		require.Len(t, tt.Columns, 1)
+		names = append(names, tt.Name)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"pets", "users"}, names)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	// Errors returned by the callback stop the inspection.
+	m.ExpectQuery(sqltest.Escape(databasesQuery)).
+		WillReturnRows(sqltest.Rows(`
+ name |   file
+------+-----------
+ main | app.db
+`))
+	m.ExpectQuery(sqltest.Escape(tablesQuery + " ORDER BY `name`")).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "sql"}).
+			AddRow("pets", "CREATE TABLE pets(id INTEGER PRIMARY KEY)").
+			AddRow("users", "CREATE TABLE users(id INTEGER PRIMARY KEY)"))
+	mk.noColumns("pets")
+	mk.noIndexes("pets")
+	mk.noFKs("pets")
+	stop := errors.New("stop")
+	err = drv.InspectTables(context.Background(), nil, func(*schema.Table) error {
+		return stop
+	})
+	require.Equal(t, stop, err)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
 func TestRegex_TableFK(t *testing.T) {
 	tests := []struct {
 		input   string
@@ -447,7 +534,7 @@ func (m mock) tableExists(table string, exists bool, stmt ...string) {
 	if exists {
 		rows.AddRow(table, stmt[0])
 	}
-	m.ExpectQuery(sqltest.Escape(tablesQuery + " AND name IN (?)")).
+	m.ExpectQuery(sqltest.Escape(tablesQuery + " AND name IN (?) ORDER BY `name`")).
 		WithArgs(table).
 		WillReturnRows(rows)
 }
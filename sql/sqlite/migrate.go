@@ -6,11 +6,14 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/naming"
 	"ariga.io/atlas/sql/schema"
 )
 
@@ -19,6 +22,9 @@ type planApply struct{ conn }
 
 // PlanChanges returns a migration plan for the given schema changes.
 func (p *planApply) PlanChanges(ctx context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+	if p.namer != nil {
+		naming.Changes(p.namer, changes)
+	}
 	s := &state{
 		conn: p.conn,
 		Plan: migrate.Plan{
@@ -26,6 +32,7 @@ func (p *planApply) PlanChanges(ctx context.Context, name string, changes []sche
 			Reversible:    true,
 			Transactional: true,
 		},
+		guards: migrate.GuardsFromContext(ctx),
 	}
 	if err := s.plan(ctx, changes); err != nil {
 		return nil, err
@@ -45,6 +52,185 @@ func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change) e
 	return sqlx.ApplyChanges(ctx, changes, p)
 }
 
+// SingleConn reports if the driver was opened on a single database connection.
+// It is used by sqlx.ApplyChanges to check if savepoints can be used.
+func (p *planApply) SingleConn() bool {
+	return p.single
+}
+
+// ConvertError implements the sqlx.ErrorConverter interface, and converts
+// SQLite errors returned by executing the given statement to typed errors.
+// Errors are detected by their messages, as they are shared by the different
+// SQLite drivers. See: https://www.sqlite.org/rescode.html.
+func (p *planApply) ConvertError(stmt string, err error) error {
+	e := &schema.StmtError{Stmt: stmt, Err: err}
+	switch msg := err.Error(); {
+	// SQLITE_BUSY and SQLITE_LOCKED.
+	case strings.Contains(msg, "database is locked"), strings.Contains(msg, "database table is locked"):
+		e.Err = &schema.LockError{Err: err}
+	// SQLITE_CONSTRAINT.
+	case strings.Contains(msg, "constraint failed"):
+		v := &schema.ConstraintViolationError{Err: err}
+		if m := reConstraintName.FindStringSubmatch(msg); m != nil {
+			v.Constraint = m[1]
+		}
+		e.Err = v
+	}
+	return e
+}
+
+// reConstraintName extracts the name of the violated
+// CHECK constraint from SQLITE_CONSTRAINT errors.
+var reConstraintName = regexp.MustCompile(`CHECK constraint failed: (\w+)`)
+
+// CountRows implements the sqlx.RowsCounter interface for reporting
+// the progress of copying rows on table rewrites.
+func (p *planApply) CountRows(ctx context.Context, c *migrate.Change) (int64, bool, error) {
+	cp, ok := c.Source.(*copyChange)
+	if !ok {
+		return 0, false, nil
+	}
+	rows, err := p.QueryContext(ctx, Build("SELECT COUNT(*) FROM").Ident(cp.From).String())
+	if err != nil {
+		return 0, false, fmt.Errorf("sqlite: counting rows of table %q: %w", cp.From, err)
+	}
+	var n int64
+	if err := sqlx.ScanOne(rows, &n); err != nil {
+		return 0, false, fmt.Errorf("sqlite: scanning rows count of table %q: %w", cp.From, err)
+	}
+	return n, true, nil
+}
+
+// ExecChunks implements the sqlx.ChunkExecer interface for copying the
+// rows of rewritten tables in chunks, in case a batch size was configured.
+// Chunks are selected by ranges of the rowid, as it is indexed.
+func (p *planApply) ExecChunks(ctx context.Context, c *migrate.Change, copied func(int64)) (bool, error) {
+	cp, ok := c.Source.(*copyChange)
+	if !ok || p.batch <= 0 || sqlx.Has(cp.T.Attrs, &WithoutRowID{}) {
+		return false, nil
+	}
+	rows, err := p.QueryContext(ctx, Build("SELECT MIN(rowid), MAX(rowid) FROM").Ident(cp.From).String())
+	if err != nil {
+		return false, fmt.Errorf("sqlite: querying rowid range of table %q: %w", cp.From, err)
+	}
+	var lo, hi sql.NullInt64
+	if err := sqlx.ScanOne(rows, &lo, &hi); err != nil {
+		return false, fmt.Errorf("sqlite: scanning rowid range of table %q: %w", cp.From, err)
+	}
+	// Empty table.
+	if !lo.Valid {
+		return true, nil
+	}
+	var total int64
+	for i := lo.Int64; ; i += p.batch {
+		if err := ctx.Err(); err != nil {
+			return true, err
+		}
+		// Avoid overflowing the range on large rowid values.
+		j := i + p.batch - 1
+		if j < i || j > hi.Int64 {
+			j = hi.Int64
+		}
+		args := append(append(make([]interface{}, 0, len(c.Args)+2), c.Args...), i, j)
+		res, err := p.ExecContext(ctx, c.Cmd+" WHERE rowid BETWEEN ? AND ?", args...)
+		if err != nil {
+			return true, err
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			total += n
+		}
+		copied(total)
+		if j == hi.Int64 {
+			return true, nil
+		}
+	}
+}
+
+// CheckChange implements the sqlx.ChangeChecker interface for running the
+// foreign-keys checks of rewritten tables, and reports the violations found
+// as a *schema.ConstraintViolationError.
+func (p *planApply) CheckChange(ctx context.Context, c *migrate.Change) (bool, error) {
+	fc, ok := c.Source.(*fkCheck)
+	if !ok {
+		return false, nil
+	}
+	rows, err := p.QueryContext(ctx, c.Cmd)
+	if err != nil {
+		return true, fmt.Errorf("sqlite: checking foreign keys of table %q: %w", fc.T.Name, err)
+	}
+	defer rows.Close()
+	var (
+		n       int
+		parents []string
+		seen    = make(map[string]bool)
+	)
+	for rows.Next() {
+		var (
+			table, parent string
+			rowid, fkid   sql.NullInt64
+		)
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return true, fmt.Errorf("sqlite: scanning foreign keys check of table %q: %w", fc.T.Name, err)
+		}
+		if n++; !seen[parent] {
+			seen[parent] = true
+			parents = append(parents, parent)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return true, err
+	}
+	if n > 0 {
+		return true, &schema.ConstraintViolationError{
+			Err: fmt.Errorf("sqlite: %d rows of table %q violate foreign keys referencing: %s", n, fc.T.Name, strings.Join(parents, ", ")),
+		}
+	}
+	return true, nil
+}
+
+// fkCheck is the source of the change that checks the
+// foreign keys of a table after it was rewritten.
+type fkCheck struct {
+	*schema.ModifyTable
+}
+
+// copyChange is the source of the change that copies rows
+// from the existing table to its temporary rewritten table.
+type copyChange struct {
+	*schema.ModifyTable
+	From string
+}
+
+// FormatStmt formats the given statement for readability. Keywords are uppercased,
+// the definitions of CREATE TABLE statements are written one per line, and ALTER TABLE
+// statements with multiple clauses are written one clause per line. For example:
+//
+//	FormatStmt("CREATE TABLE `t` (`id` integer NOT NULL, PRIMARY KEY (`id`))")
+//
+func FormatStmt(stmt string) string {
+	return formatter.Format(stmt)
+}
+
+// SplitStmts splits the given SQLite script into statements.
+func SplitStmts(script string) ([]*migrate.Stmt, error) {
+	stmts, err := splitter.Split(script)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: %w", err)
+	}
+	return stmts, nil
+}
+
+// SplitStmts implements the migrate.StmtSplitter interface.
+func (*Driver) SplitStmts(script string) ([]*migrate.Stmt, error) {
+	return SplitStmts(script)
+}
+
+var (
+	lexer     = sqlx.Lexer{Brackets: true}
+	formatter = &sqlx.Formatter{Lexer: lexer}
+	splitter  = &sqlx.Splitter{Lexer: lexer}
+)
+
 // state represents the state of a planning. It's not part of
 // planApply so that multiple planning/applying can be called
 // in parallel.
@@ -52,11 +238,17 @@ type state struct {
 	conn
 	migrate.Plan
 	skipFKs bool
+	// guards indicates if the planned statements are guarded
+	// with existence checks. See migrate.WithGuards.
+	guards bool
 }
 
 // Exec executes the changes on the database. An error is returned
 // if one of the operations fail, or a change is not supported.
 func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
+	// Pragmas of the connection (i.e. foreign_keys) are set after all
+	// other changes, as table rewrites may toggle their values.
+	var pragmas []*migrate.Change
 	for _, c := range changes {
 		switch c := c.(type) {
 		case *schema.AddTable:
@@ -65,6 +257,13 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
 			err = s.dropTable(c)
 		case *schema.ModifyTable:
 			err = s.modifyTable(ctx, c)
+		case *schema.ModifySchema:
+			err = s.modifySchema(c)
+		case *schema.AddAttr, *schema.ModifyAttr:
+			var pc *migrate.Change
+			if pc, err = s.pragmaChange(nil, c); err == nil {
+				pragmas = append(pragmas, pc)
+			}
 		default:
 			err = fmt.Errorf("unsupported change %T", c)
 		}
@@ -80,6 +279,7 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
 		s.Changes = append([]*migrate.Change{{Cmd: "PRAGMA foreign_keys = off", Comment: "disable the enforcement of foreign-keys constraints"}}, s.Changes...)
 		s.append(&migrate.Change{Cmd: "PRAGMA foreign_keys = on", Comment: "enable back the enforcement of foreign-keys constraints"})
 	}
+	s.Changes = append(s.Changes, pragmas...)
 	return nil
 }
 
@@ -87,11 +287,12 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) (err error) {
 func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 	var (
 		errs []string
-		b    = Build("CREATE TABLE").Ident(add.T.Name)
+		b    = s.build("CREATE TABLE")
 	)
-	if sqlx.Has(add.Extra, &schema.IfNotExists{}) {
+	if s.guards || sqlx.Has(add.Extra, &schema.IfNotExists{}) {
 		b.P("IF NOT EXISTS")
 	}
+	b.Ident(add.T.Name)
 	b.Wrap(func(b *sqlx.Builder) {
 		b.MapComma(add.T.Columns, func(i int, b *sqlx.Builder) {
 			if err := s.column(b, add.T.Columns[i]); err != nil {
@@ -123,7 +324,7 @@ func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 	s.append(&migrate.Change{
 		Cmd:     b.String(),
 		Source:  add,
-		Reverse: Build("DROP TABLE").Table(add.T).String(),
+		Reverse: s.build("DROP TABLE").Table(add.T).String(),
 		Comment: fmt.Sprintf("create %q table", add.T.Name),
 	})
 	if err := s.tableSeq(ctx, add); err != nil {
@@ -135,10 +336,11 @@ func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 // dropTable builds and executes the query for dropping a table from a schema.
 func (s *state) dropTable(drop *schema.DropTable) error {
 	s.skipFKs = true
-	b := Build("DROP TABLE").Ident(drop.T.Name)
-	if sqlx.Has(drop.Extra, &schema.IfExists{}) {
+	b := s.build("DROP TABLE")
+	if s.guards || sqlx.Has(drop.Extra, &schema.IfExists{}) {
 		b.P("IF EXISTS")
 	}
+	b.Ident(drop.T.Name)
 	s.append(&migrate.Change{
 		Cmd:     b.String(),
 		Source:  drop,
@@ -149,10 +351,16 @@ func (s *state) dropTable(drop *schema.DropTable) error {
 
 // modifyTable builds and executes the queries for bringing the table into its modified state.
 // If the modification contains changes that are not index creation/deletion or a simple column
-// addition, the changes are applied using a temporary table following the procedure mentioned
+// addition, rename or removal that are supported by the ALTER TABLE command of the connected
+// database, the changes are applied using a temporary table following the procedure mentioned
 // in: https://www.sqlite.org/lang_altertable.html#making_other_kinds_of_table_schema_changes.
 func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) error {
-	if alterable(modify) {
+	// SQLite supports existence checks only for index creation/deletion,
+	// and not for column changes or table rewrites.
+	if s.guards && (!indexOnly(modify) || !s.alterable(modify)) {
+		return fmt.Errorf("existence guards are not supported for modifying table %q", modify.T.Name)
+	}
+	if s.alterable(modify) {
 		return s.alterTable(modify)
 	}
 	s.skipFKs = true
@@ -164,20 +372,29 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 	if err := s.addTable(ctx, &schema.AddTable{T: &newT}); err != nil {
 		return err
 	}
-	if err := s.copyRows(modify.T, &newT, modify.Changes); err != nil {
+	if err := s.copyRows(modify, &newT); err != nil {
 		return err
 	}
 	// Drop the current table, and rename the new one to its real name.
 	s.append(&migrate.Change{
-		Cmd:     Build("DROP TABLE").Ident(modify.T.Name).String(),
+		Cmd:     s.build("DROP TABLE").Ident(modify.T.Name).String(),
 		Source:  modify,
 		Comment: fmt.Sprintf("drop %q table after copying rows", modify.T.Name),
 	})
 	s.append(&migrate.Change{
-		Cmd:     Build("ALTER TABLE").Ident(newT.Name).P("RENAME TO").Ident(modify.T.Name).String(),
+		Cmd:     s.build("ALTER TABLE").Ident(newT.Name).P("RENAME TO").Ident(modify.T.Name).String(),
 		Source:  modify,
 		Comment: fmt.Sprintf("rename temporary table %q to %q", newT.Name, modify.T.Name),
 	})
+	// Foreign keys are not enforced during the rewrite, and
+	// therefore, the copied rows are checked after the fact.
+	if s.fkEnabled && len(modify.T.ForeignKeys) > 0 {
+		s.append(&migrate.Change{
+			Cmd:     s.build("PRAGMA foreign_key_check").Wrap(func(b *sqlx.Builder) { b.Ident(modify.T.Name) }).String(),
+			Source:  &fkCheck{ModifyTable: modify},
+			Comment: fmt.Sprintf("check foreign keys of table %q", modify.T.Name),
+		})
+	}
 	return s.addIndexes(modify.T, indexes...)
 }
 
@@ -222,11 +439,14 @@ func (s *state) addIndexes(t *schema.Table, indexes ...*schema.Index) error {
 			}
 			idx.Name = strings.Join(names, "_")
 		}
-		b := Build("CREATE")
+		b := s.build("CREATE")
 		if idx.Unique {
 			b.P("UNIQUE")
 		}
 		b.P("INDEX")
+		if s.guards {
+			b.P("IF NOT EXISTS")
+		}
 		if idx.Name != "" {
 			b.Ident(idx.Name)
 		}
@@ -238,7 +458,7 @@ func (s *state) addIndexes(t *schema.Table, indexes ...*schema.Index) error {
 		s.append(&migrate.Change{
 			Cmd:     b.String(),
 			Source:  &schema.AddIndex{I: idx},
-			Reverse: Build("DROP INDEX").Ident(idx.Name).String(),
+			Reverse: s.build("DROP INDEX").Ident(idx.Name).String(),
 			Comment: fmt.Sprintf("create index %q to table: %q", idx.Name, t.Name),
 		})
 	}
@@ -288,16 +508,25 @@ func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) {
 	})
 }
 
-func (s *state) copyRows(from *schema.Table, to *schema.Table, changes []schema.Change) error {
+func (s *state) copyRows(modify *schema.ModifyTable, to *schema.Table) error {
 	var (
-		args       []interface{}
-		fromC, toC []string
+		from, changes = modify.T, modify.Changes
+		args          []interface{}
+		fromC, toC    []string
 	)
 	for _, column := range to.Columns {
 		// Find a change that associated with this column, if exists.
-		var change schema.Change
+		var (
+			change schema.Change
+			// The name of the column in the old table.
+			name = column.Name
+		)
 		for i := range changes {
 			switch c := changes[i].(type) {
+			case *schema.RenameColumn:
+				if c.To.Name == column.Name {
+					name = c.From.Name
+				}
 			case *schema.AddColumn:
 				if c.C.Name != column.Name {
 					break
@@ -327,49 +556,71 @@ func (s *state) copyRows(from *schema.Table, to *schema.Table, changes []schema.
 		// Column modification requires special handling if it was
 		// converted from nullable to non-nullable with default value.
 		case *schema.ModifyColumn:
-			toC = append(toC, column.Name)
+			toC = append(toC, s.build("").Ident(column.Name).String())
 			if !column.Type.Null && column.Default != nil && change.Change.Is(schema.ChangeNull|schema.ChangeDefault) {
-				fromC = append(fromC, fmt.Sprintf("IFNULL(`%[1]s`, ?) AS `%[1]s`", column.Name))
+				fromC = append(fromC, fmt.Sprintf("IFNULL(%s, ?) AS %s", s.build("").Ident(name), s.build("").Ident(column.Name)))
 				x, err := defaultValue(column)
 				if err != nil {
 					return err
 				}
 				args = append(args, x)
 			} else {
-				fromC = append(fromC, column.Name)
+				fromC = append(fromC, s.build("").Ident(name).String())
 			}
 		// Columns without changes, should transfer as-is.
 		case nil:
-			toC = append(toC, column.Name)
-			fromC = append(fromC, column.Name)
+			toC = append(toC, s.build("").Ident(column.Name).String())
+			fromC = append(fromC, s.build("").Ident(name).String())
 		}
 	}
-	stmt := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", to.Name, strings.Join(toC, ", "), strings.Join(fromC, ", "), from.Name)
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", s.build("").Ident(to.Name), strings.Join(toC, ", "), strings.Join(fromC, ", "), s.build("").Ident(from.Name))
+	comment := fmt.Sprintf("copy rows from old table %q to new temporary table %q", from.Name, to.Name)
+	if s.ordered && sqlx.ReorderColumns(modify.T, changes) {
+		comment += " to reorder its columns (full table rewrite)"
+	}
 	s.append(&migrate.Change{
 		Cmd:     stmt,
 		Args:    args,
-		Comment: fmt.Sprintf("copy rows from old table %q to new temporary table %q", from.Name, to.Name),
+		Source:  &copyChange{ModifyTable: modify, From: from.Name},
+		Comment: comment,
 	})
 	return nil
 }
 
 // alterTable alters the table with the given changes. Assuming the changes are "alterable".
+// Indexes are dropped first, as they may reference dropped columns, and created last, as
+// they may reference added or renamed columns.
 func (s *state) alterTable(modify *schema.ModifyTable) error {
+	var drops, columns, adds []schema.Change
 	for _, change := range modify.Changes {
+		switch change.(type) {
+		case *schema.DropIndex:
+			drops = append(drops, change)
+		case *schema.AddIndex:
+			adds = append(adds, change)
+		default:
+			columns = append(columns, change)
+		}
+	}
+	for _, change := range append(append(drops, columns...), adds...) {
 		switch change := change.(type) {
 		case *schema.AddIndex:
 			if err := s.addIndexes(modify.T, change.I); err != nil {
 				return err
 			}
 		case *schema.DropIndex:
-			b := Build("DROP INDEX").Ident(change.I.Name)
+			b := s.build("DROP INDEX")
+			if s.guards {
+				b.P("IF EXISTS")
+			}
+			b.Ident(change.I.Name)
 			s.append(&migrate.Change{
 				Cmd:     b.String(),
 				Source:  change,
 				Comment: fmt.Sprintf("drop index %q to table: %q", change.I.Name, modify.T.Name),
 			})
 		case *schema.AddColumn:
-			b := Build("ALTER TABLE").Ident(modify.T.Name).P("ADD COLUMN")
+			b := s.build("ALTER TABLE").Ident(modify.T.Name).P("ADD COLUMN")
 			if err := s.column(b, change.C); err != nil {
 				return err
 			}
@@ -379,6 +630,19 @@ func (s *state) alterTable(modify *schema.ModifyTable) error {
 				Source:  change,
 				Comment: fmt.Sprintf("add column %q to table: %q", change.C.Name, modify.T.Name),
 			})
+		case *schema.RenameColumn:
+			s.append(&migrate.Change{
+				Cmd:     s.build("ALTER TABLE").Ident(modify.T.Name).P("RENAME COLUMN").Ident(change.From.Name).P("TO").Ident(change.To.Name).String(),
+				Source:  change,
+				Reverse: s.build("ALTER TABLE").Ident(modify.T.Name).P("RENAME COLUMN").Ident(change.To.Name).P("TO").Ident(change.From.Name).String(),
+				Comment: fmt.Sprintf("rename column %q to %q in table: %q", change.From.Name, change.To.Name, modify.T.Name),
+			})
+		case *schema.DropColumn:
+			s.append(&migrate.Change{
+				Cmd:     s.build("ALTER TABLE").Ident(modify.T.Name).P("DROP COLUMN").Ident(change.C.Name).String(),
+				Source:  change,
+				Comment: fmt.Sprintf("drop column %q from table: %q", change.C.Name, modify.T.Name),
+			})
 		default:
 			return fmt.Errorf("unexpected change in alter table: %T", change)
 		}
@@ -405,9 +669,9 @@ func (s *state) tableSeq(ctx context.Context, add *schema.AddTable) error {
 	rows, err := s.QueryContext(ctx, "SELECT seq FROM sqlite_sequence WHERE name = ?", add.T.Name)
 	if err != nil || !rows.Next() {
 		s.append(&migrate.Change{
-			Cmd:     fmt.Sprintf("INSERT INTO sqlite_sequence (name, seq) VALUES (%q, %d)", add.T.Name, inc.Seq),
+			Cmd:     fmt.Sprintf("INSERT INTO sqlite_sequence (name, seq) VALUES (%s, %d)", quote(add.T.Name), inc.Seq),
 			Source:  add,
-			Reverse: fmt.Sprintf("UPDATE sqlite_sequence SET seq = 0 WHERE name = %q", add.T.Name),
+			Reverse: fmt.Sprintf("UPDATE sqlite_sequence SET seq = 0 WHERE name = %s", quote(add.T.Name)),
 			Comment: fmt.Sprintf("set sequence for %q table", add.T.Name),
 		})
 	}
@@ -421,12 +685,44 @@ func (s *state) append(c *migrate.Change) {
 	s.Changes = append(s.Changes, c)
 }
 
-func alterable(modify *schema.ModifyTable) bool {
+// alterable reports if the table modification can be applied using the ALTER TABLE
+// command of the connected database, instead of copying the table rows. Note that
+// the ALTER TABLE command restrictions that are not listed below (e.g. dropping a
+// column that is referenced by a PRIMARY KEY or an index) are prevented either by
+// the Differ, or by the fact that such changes are accompanied with changes that
+// are not alterable (e.g. DropForeignKey).
+func (s *state) alterable(modify *schema.ModifyTable) bool {
+	// Columns can be added only to the end of the table.
+	if s.ordered && sqlx.ReorderColumns(modify.T, modify.Changes) {
+		return false
+	}
 	for _, change := range modify.Changes {
 		switch change := change.(type) {
-		case *schema.DropIndex, *schema.AddIndex:
+		case *schema.AddIndex:
+		// Indexes that were created by UNIQUE or PRIMARY KEY
+		// constraints cannot be dropped using DROP INDEX.
+		case *schema.DropIndex:
+			if strings.HasPrefix(change.I.Name, "sqlite_autoindex") {
+				return false
+			}
+		// Columns can be added only with constant default values, and NOT NULL
+		// columns must have one, even if the table is empty.
 		case *schema.AddColumn:
-			if len(change.C.Indexes) > 0 || len(change.C.ForeignKeys) > 0 || change.C.Default != nil {
+			if len(change.C.Indexes) > 0 || len(change.C.ForeignKeys) > 0 || sqlx.Has(change.C.Attrs, &AutoIncrement{}) {
+				return false
+			}
+			if !change.C.Type.Null && change.C.Default == nil {
+				return false
+			}
+			if _, ok := change.C.Default.(*schema.Literal); change.C.Default != nil && !ok {
+				return false
+			}
+		case *schema.RenameColumn:
+			if !s.supportsRenameColumn() {
+				return false
+			}
+		case *schema.DropColumn:
+			if !s.supportsDropColumn() || len(change.C.ForeignKeys) > 0 {
 				return false
 			}
 		default:
@@ -436,6 +732,18 @@ func alterable(modify *schema.ModifyTable) bool {
 	return true
 }
 
+// indexOnly reports if the table modification contains only index creation/deletion.
+func indexOnly(modify *schema.ModifyTable) bool {
+	for _, change := range modify.Changes {
+		switch change.(type) {
+		case *schema.AddIndex, *schema.DropIndex:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // checks writes the CHECK constraint to the builder.
 func check(b *sqlx.Builder, c *schema.Check) {
 	expr := c.Expr
@@ -460,6 +768,27 @@ func Build(phrase string) *sqlx.Builder {
 	return b.P(phrase)
 }
 
+// build is like Build, but identifiers are quoted only
+// when it is needed, if the driver was configured so.
+func (s *state) build(phrase string) *sqlx.Builder {
+	b := Build(phrase)
+	if s.minQuote {
+		b.MustQuote = mustQuote
+	}
+	return b
+}
+
+// mustQuote reports if the given identifier must be quoted.
+func mustQuote(s string) bool {
+	return sqlx.ReservedWord(s) || !sqlx.SimpleIdent(s, nil)
+}
+
+// quote returns the given string as a string literal. Double-quoted
+// strings are not used, as SQLite resolves them as identifiers first.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
 func defaultValue(c *schema.Column) (string, error) {
 	switch x := c.Default.(type) {
 	case *schema.Literal:
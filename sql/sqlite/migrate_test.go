@@ -7,11 +7,13 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
 
 	"ariga.io/atlas/sql/internal/sqltest"
 
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/naming"
 	"ariga.io/atlas/sql/schema"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -72,7 +74,7 @@ func TestPlanChanges(t *testing.T) {
 				Transactional: true,
 				Changes: []*migrate.Change{
 					{Cmd: "CREATE TABLE `posts` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `text` text NULL, CHECK (text <> ''), CONSTRAINT `positive_id` CHECK (id <> 0))", Reverse: "DROP TABLE `posts`"},
-					{Cmd: `INSERT INTO sqlite_sequence (name, seq) VALUES ("posts", 1024)`, Reverse: `UPDATE sqlite_sequence SET seq = 0 WHERE name = "posts"`},
+					{Cmd: `INSERT INTO sqlite_sequence (name, seq) VALUES ('posts', 1024)`, Reverse: `UPDATE sqlite_sequence SET seq = 0 WHERE name = 'posts'`},
 				},
 			},
 		},
@@ -102,7 +104,7 @@ func TestPlanChanges(t *testing.T) {
 						T: users,
 						Changes: []schema.Change{
 							&schema.AddColumn{
-								C: &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar(255)"}}},
+								C: &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar(255)"}, Null: true}},
 							},
 							&schema.AddIndex{
 								I: &schema.Index{
@@ -122,7 +124,7 @@ func TestPlanChanges(t *testing.T) {
 			plan: &migrate.Plan{
 				Transactional: true,
 				Changes: []*migrate.Change{
-					{Cmd: "ALTER TABLE `users` ADD COLUMN `name` varchar(255) NOT NULL"},
+					{Cmd: "ALTER TABLE `users` ADD COLUMN `name` varchar(255) NULL"},
 					{Cmd: "CREATE INDEX `id_key` ON `users` (`id`)", Reverse: "DROP INDEX `id_key`"},
 				},
 			},
@@ -157,7 +159,7 @@ func TestPlanChanges(t *testing.T) {
 				Changes: []*migrate.Change{
 					{Cmd: "PRAGMA foreign_keys = off"},
 					{Cmd: "CREATE TABLE `new_users` (`id` bigint NOT NULL, CHECK (id <> 0))", Reverse: "DROP TABLE `new_users`"},
-					{Cmd: "INSERT INTO new_users (id) SELECT id FROM users"},
+					{Cmd: "INSERT INTO `new_users` (`id`) SELECT `id` FROM `users`"},
 					{Cmd: "DROP TABLE `users`"},
 					{Cmd: "ALTER TABLE `new_users` RENAME TO `users`"},
 					{Cmd: "PRAGMA foreign_keys = on"},
@@ -185,3 +187,421 @@ func TestPlanChanges(t *testing.T) {
 		}
 	}
 }
+
+func TestPlanChanges_AlterTable(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(
+		schema.NewIntColumn("id", "integer"),
+		schema.NewIntColumn("nick", "integer"),
+		schema.NewIntColumn("age", "integer").SetDefault(&schema.Literal{V: "0"}),
+	)
+	changes := []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.DropColumn{C: schema.NewIntColumn("name", "integer")},
+				&schema.RenameColumn{From: schema.NewIntColumn("alias", "integer"), To: users.Columns[1]},
+				&schema.AddColumn{C: users.Columns[2]},
+				&schema.AddIndex{I: schema.NewIndex("nick").AddColumns(users.Columns[1])},
+				&schema.DropIndex{I: schema.NewIndex("name").AddColumns(schema.NewIntColumn("name", "integer"))},
+			},
+		},
+	}
+	for v, cmds := range map[string][]string{
+		"3.36.0": {
+			"DROP INDEX `name`",
+			"ALTER TABLE `users` DROP COLUMN `name`",
+			"ALTER TABLE `users` RENAME COLUMN `alias` TO `nick`",
+			"ALTER TABLE `users` ADD COLUMN `age` integer NOT NULL DEFAULT '0'",
+			"CREATE INDEX `nick` ON `users` (`nick`)",
+		},
+		"3.34.0": {
+			"PRAGMA foreign_keys = off",
+			"CREATE TABLE `new_users` (`id` integer NOT NULL, `nick` integer NOT NULL, `age` integer NOT NULL DEFAULT '0')",
+			"INSERT INTO `new_users` (`id`, `nick`) SELECT `id`, `alias` FROM `users`",
+			"DROP TABLE `users`",
+			"ALTER TABLE `new_users` RENAME TO `users`",
+			"PRAGMA foreign_keys = on",
+		},
+	} {
+		db, mk, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{mk}.systemVars(v)
+		drv, err := Open(db)
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, len(cmds), v)
+		for i, c := range plan.Changes {
+			require.Equal(t, cmds[i], c.Cmd)
+		}
+	}
+}
+
+func TestPlanChanges_AddNotNullColumn(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(
+		schema.NewIntColumn("id", "integer"),
+		schema.NewIntColumn("age", "integer"),
+	)
+	// SQLite rejects adding NOT NULL columns without a default
+	// value, even to empty tables. Hence, the table is rewritten.
+	changes := []schema.Change{
+		&schema.ModifyTable{
+			T:       users,
+			Changes: []schema.Change{&schema.AddColumn{C: users.Columns[1]}},
+		},
+	}
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.systemVars("3.36.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	cmds := []string{
+		"PRAGMA foreign_keys = off",
+		"CREATE TABLE `new_users` (`id` integer NOT NULL, `age` integer NOT NULL)",
+		"INSERT INTO `new_users` (`id`) SELECT `id` FROM `users`",
+		"DROP TABLE `users`",
+		"ALTER TABLE `new_users` RENAME TO `users`",
+		"PRAGMA foreign_keys = on",
+	}
+	require.Len(t, plan.Changes, len(cmds))
+	for i, c := range plan.Changes {
+		require.Equal(t, cmds[i], c.Cmd)
+	}
+}
+
+func TestPlanChanges_ColumnOrder(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(
+		schema.NewIntColumn("id", "integer"),
+		schema.NewIntColumn("age", "integer").SetDefault(&schema.Literal{V: "0"}),
+		schema.NewIntColumn("rank", "integer"),
+	)
+	changes := []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddColumn{C: users.Columns[1]},
+			},
+		},
+	}
+	for b, cmds := range map[bool][]string{
+		false: {
+			"ALTER TABLE `users` ADD COLUMN `age` integer NOT NULL DEFAULT '0'",
+		},
+		true: {
+			"PRAGMA foreign_keys = off",
+			"CREATE TABLE `new_users` (`id` integer NOT NULL, `age` integer NOT NULL DEFAULT '0', `rank` integer NOT NULL)",
+			"INSERT INTO `new_users` (`id`, `rank`) SELECT `id`, `rank` FROM `users`",
+			"DROP TABLE `users`",
+			"ALTER TABLE `new_users` RENAME TO `users`",
+			"PRAGMA foreign_keys = on",
+		},
+	} {
+		db, mk, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{mk}.systemVars("3.36.0")
+		drv, err := Open(db, WithColumnOrder(b))
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, len(cmds))
+		for i, c := range plan.Changes {
+			require.Equal(t, cmds[i], c.Cmd)
+		}
+		if b {
+			require.Contains(t, plan.Changes[2].Comment, "full table rewrite")
+		}
+	}
+
+	// Moved columns are always copied to a new table.
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.systemVars("3.36.0")
+	drv, err := Open(db, WithColumnOrder(true))
+	require.NoError(t, err)
+	from := schema.NewTable("users").AddColumns(
+		schema.NewIntColumn("age", "integer"),
+		schema.NewIntColumn("id", "integer"),
+	)
+	to := schema.NewTable("users").AddColumns(
+		schema.NewIntColumn("id", "integer"),
+		schema.NewIntColumn("age", "integer"),
+	)
+	diff, err := drv.TableDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{&schema.ModifyColumn{From: from.Columns[0], To: to.Columns[1], Change: schema.ChangePosition}}, diff)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{&schema.ModifyTable{T: to, Changes: diff}})
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO `new_users` (`id`, `age`) SELECT `id`, `age` FROM `users`", plan.Changes[2].Cmd)
+}
+
+func TestPlanChanges_Naming(t *testing.T) {
+	users := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "int"), schema.NewStringColumn("name", "text"))
+	users.AddIndexes(
+		schema.NewIndex("").AddColumns(users.Columns[1]),
+		schema.NewUniqueIndex("users_id").AddColumns(users.Columns[0]),
+	)
+	changes := []schema.Change{
+		&schema.AddTable{T: users},
+	}
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.systemVars("3.36.0")
+	drv, err := Open(db, WithNaming(naming.Django{}))
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"CREATE TABLE `users` (`id` int NOT NULL, `name` text NOT NULL)",
+		"CREATE INDEX `users_name_3b42b832` ON `users` (`name`)",
+		"CREATE UNIQUE INDEX `users_id` ON `users` (`id`)",
+	}, func() []string {
+		var cmds []string
+		for _, c := range plan.Changes {
+			cmds = append(cmds, c.Cmd)
+		}
+		return cmds
+	}())
+}
+
+func TestPlanChanges_MinimalQuoting(t *testing.T) {
+	order := schema.NewTable("order").
+		AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewIntColumn("userId", "int"),
+			schema.NewIntColumn("unit price", "int"),
+		)
+	changes := []schema.Change{
+		&schema.AddTable{T: order},
+		&schema.ModifyTable{T: order, Changes: []schema.Change{
+			&schema.RenameColumn{From: schema.NewIntColumn("user`id", "int"), To: order.Columns[1]},
+		}},
+	}
+	for _, tt := range []struct {
+		opts []Option
+		want []string
+	}{
+		{
+			want: []string{
+				"CREATE TABLE `order` (`id` int NOT NULL, `userId` int NOT NULL, `unit price` int NOT NULL)",
+				"ALTER TABLE `order` RENAME COLUMN `user``id` TO `userId`",
+			},
+		},
+		{
+			opts: []Option{WithMinimalQuoting(true)},
+			want: []string{
+				"CREATE TABLE `order` (id int NOT NULL, userId int NOT NULL, `unit price` int NOT NULL)",
+				"ALTER TABLE `order` RENAME COLUMN `user``id` TO userId",
+			},
+		},
+	} {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		mock{m}.systemVars("3.36.0")
+		drv, err := Open(db, tt.opts...)
+		require.NoError(t, err)
+		plan, err := drv.PlanChanges(context.Background(), "plan", changes)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, len(tt.want))
+		for i, c := range plan.Changes {
+			require.Equal(t, tt.want[i], c.Cmd)
+		}
+	}
+}
+
+func TestPlanApply_ApplyChangesProgress(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	// DROP COLUMN is not supported by this version, and the table is copied.
+	m.systemVars("3.34.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	users := &schema.Table{
+		Name: "users",
+		Columns: []*schema.Column{
+			{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+		},
+	}
+	changes := []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.DropColumn{C: &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "text"}}}},
+			},
+		},
+	}
+	m.ExpectExec(sqltest.Escape("PRAGMA foreign_keys = off")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("CREATE TABLE `new_users` (`id` bigint NOT NULL)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectQuery(sqltest.Escape("SELECT COUNT(*) FROM `users`")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(100))
+	m.ExpectExec(sqltest.Escape("INSERT INTO `new_users` (`id`) SELECT `id` FROM `users`")).WillReturnResult(sqlmock.NewResult(0, 100))
+	m.ExpectExec(sqltest.Escape("DROP TABLE `users`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("ALTER TABLE `new_users` RENAME TO `users`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("PRAGMA foreign_keys = on")).WillReturnResult(sqlmock.NewResult(0, 0))
+	var reports []migrate.Progress
+	ctx := migrate.WithProgress(context.Background(), func(p migrate.Progress) {
+		reports = append(reports, p)
+	})
+	require.NoError(t, drv.ApplyChanges(ctx, changes))
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Len(t, reports, 12)
+	for i, p := range reports {
+		require.Equal(t, i/2, p.Index)
+		require.Equal(t, 6, p.Total)
+		require.Equal(t, i%2 == 1, p.Done)
+	}
+	require.Equal(t, int64(100), reports[4].RowsTotal)
+	require.Zero(t, reports[4].RowsCopied)
+	require.Equal(t, int64(100), reports[5].RowsTotal)
+	require.Equal(t, int64(100), reports[5].RowsCopied)
+	require.Zero(t, reports[3].RowsTotal)
+}
+
+func TestPlanApply_ApplyChangesBatch(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.systemVars("3.34.0")
+	drv, err := Open(db, WithCopyBatchSize(40))
+	require.NoError(t, err)
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint"))
+	changes := []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.DropColumn{C: schema.NewStringColumn("name", "text")},
+			},
+		},
+	}
+	m.ExpectExec(sqltest.Escape("PRAGMA foreign_keys = off")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("CREATE TABLE `new_users` (`id` bigint NOT NULL)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectQuery(sqltest.Escape("SELECT COUNT(*) FROM `users`")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(90))
+	m.ExpectQuery(sqltest.Escape("SELECT MIN(rowid), MAX(rowid) FROM `users`")).WillReturnRows(sqlmock.NewRows([]string{"min", "max"}).AddRow(1, 100))
+	for _, r := range [][3]int64{{1, 40, 40}, {41, 80, 30}, {81, 100, 20}} {
+		m.ExpectExec(sqltest.Escape("INSERT INTO `new_users` (`id`) SELECT `id` FROM `users` WHERE rowid BETWEEN ? AND ?")).
+			WithArgs(r[0], r[1]).
+			WillReturnResult(sqlmock.NewResult(0, r[2]))
+	}
+	m.ExpectExec(sqltest.Escape("DROP TABLE `users`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("ALTER TABLE `new_users` RENAME TO `users`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("PRAGMA foreign_keys = on")).WillReturnResult(sqlmock.NewResult(0, 0))
+	var copied []int64
+	ctx := migrate.WithProgress(context.Background(), func(p migrate.Progress) {
+		if p.Index == 2 {
+			copied = append(copied, p.RowsCopied)
+		}
+	})
+	require.NoError(t, drv.ApplyChanges(ctx, changes))
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Equal(t, []int64{0, 40, 70, 90, 90}, copied)
+}
+
+func TestPlanApply_ApplyChangesFKCheck(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.systemVars("3.36.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		users = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "integer"))
+		pets  = schema.NewTable("pets").AddColumns(schema.NewIntColumn("owner_id", "integer"))
+		fk    = schema.NewForeignKey("owner").AddColumns(pets.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])
+	)
+	pets.AddForeignKeys(fk)
+	changes := []schema.Change{
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.AddForeignKey{F: fk}}},
+	}
+	m.ExpectExec(sqltest.Escape("PRAGMA foreign_keys = off")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("CREATE TABLE `new_pets` (`owner_id` integer NOT NULL, CONSTRAINT `owner` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`))")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("INSERT INTO `new_pets` (`owner_id`) SELECT `owner_id` FROM `pets`")).WillReturnResult(sqlmock.NewResult(0, 2))
+	m.ExpectExec(sqltest.Escape("DROP TABLE `pets`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec(sqltest.Escape("ALTER TABLE `new_pets` RENAME TO `pets`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectQuery(sqltest.Escape("PRAGMA foreign_key_check (`pets`)")).
+		WillReturnRows(sqltest.Rows(`
+ table | rowid | parent | fkid
+-------+-------+--------+------
+ pets  | 1     | users  | 0
+ pets  | 2     | users  | 0
+`))
+	err = drv.ApplyChanges(context.Background(), changes)
+	require.True(t, schema.IsConstraintViolationError(err))
+	require.Contains(t, err.Error(), `sqlite: 2 rows of table "pets" violate foreign keys referencing: users`)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestPlanApply_ApplyChangesError(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.systemVars("3.36.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	users := &schema.Table{
+		Name: "users",
+		Columns: []*schema.Column{
+			{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+		},
+	}
+	m.ExpectExec(sqltest.Escape("CREATE UNIQUE INDEX `id` ON `users` (`id`)")).
+		WillReturnError(errors.New("UNIQUE constraint failed: users.id"))
+	err = drv.ApplyChanges(context.Background(), []schema.Change{
+		&schema.ModifyTable{
+			T: users,
+			Changes: []schema.Change{
+				&schema.AddIndex{I: &schema.Index{Name: "id", Unique: true, Table: users, Parts: []*schema.IndexPart{{C: users.Columns[0]}}}},
+			},
+		},
+	})
+	require.True(t, schema.IsConstraintViolationError(err))
+	var se *schema.StmtError
+	require.True(t, errors.As(err, &se))
+	require.Equal(t, "CREATE UNIQUE INDEX `id` ON `users` (`id`)", se.Stmt)
+
+	p := &planApply{}
+	require.True(t, schema.IsLockError(p.ConvertError("DROP TABLE `users`", errors.New("database is locked"))))
+	var cv *schema.ConstraintViolationError
+	require.True(t, errors.As(p.ConvertError("INSERT INTO `users` SELECT * FROM `old`", errors.New("CHECK constraint failed: positive")), &cv))
+	require.Equal(t, "positive", cv.Constraint)
+}
+
+func TestPlanChanges_Guards(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.systemVars("3.36.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "integer"))
+	pets := schema.NewTable("pets").AddColumns(schema.NewIntColumn("age", "integer"))
+	pets.AddIndexes(schema.NewIndex("pets_age").AddColumns(pets.Columns[0]))
+	plan, err := drv.PlanChanges(migrate.WithGuards(context.Background()), "plan", []schema.Change{
+		&schema.DropTable{T: schema.NewTable("tmp")},
+		&schema.AddTable{T: users},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{
+			&schema.DropIndex{I: schema.NewIndex("pets_old")},
+			&schema.AddIndex{I: pets.Indexes[0]},
+		}},
+	})
+	require.NoError(t, err)
+	var cmds []string
+	for _, c := range plan.Changes {
+		cmds = append(cmds, c.Cmd)
+	}
+	require.Equal(t, []string{
+		"PRAGMA foreign_keys = off",
+		"DROP TABLE IF EXISTS `tmp`",
+		"CREATE TABLE IF NOT EXISTS `users` (`id` integer NOT NULL)",
+		"DROP INDEX IF EXISTS `pets_old`",
+		"CREATE INDEX IF NOT EXISTS `pets_age` ON `pets` (`age`)",
+		"PRAGMA foreign_keys = on",
+	}, cmds)
+
+	_, err = drv.PlanChanges(migrate.WithGuards(context.Background()), "plan", []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.AddColumn{C: schema.NewNullStringColumn("name", "text")},
+		}},
+	})
+	require.EqualError(t, err, `existence guards are not supported for modifying table "users"`)
+}
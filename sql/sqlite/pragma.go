@@ -0,0 +1,213 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// Pragma describes a PRAGMA setting of the database. The settings that are persisted
+// in the database file (e.g. journal_mode) are held in the attributes of its schema,
+// and foreign_keys, which is set per connection, is held in the attributes of the realm.
+//
+//	schema.New("main").AddAttrs(&sqlite.Pragma{Name: sqlite.PragmaJournalMode, V: "wal"})
+//
+type Pragma struct {
+	schema.Attr
+	Name, V string
+}
+
+// List of the pragmas that are inspected by the driver.
+const (
+	PragmaJournalMode   = "journal_mode"
+	PragmaUserVersion   = "user_version"
+	PragmaApplicationID = "application_id"
+	PragmaForeignKeys   = "foreign_keys"
+)
+
+// pragmaDefaults holds the default values of the inspected pragmas.
+var pragmaDefaults = map[string]string{
+	PragmaJournalMode:   "delete",
+	PragmaUserVersion:   "0",
+	PragmaApplicationID: "0",
+	PragmaForeignKeys:   "off",
+}
+
+// Query for inspecting the persistent pragmas of a database (schema).
+const pragmasQuery = "SELECT (SELECT `journal_mode` FROM `%[1]s`.pragma_journal_mode), (SELECT `user_version` FROM `%[1]s`.pragma_user_version), (SELECT `application_id` FROM `%[1]s`.pragma_application_id)"
+
+// inspectPragmas adds the pragmas of the connection to the realm attributes,
+// and the persistent pragmas of each database to the schema attributes.
+func (i *inspect) inspectPragmas(ctx context.Context, r *schema.Realm) error {
+	r.Attrs = append(r.Attrs, i.foreignKeysPragma())
+	for _, s := range r.Schemas {
+		if err := i.schemaPragmas(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaPragmas adds the persistent pragmas of the database to the schema attributes.
+func (i *inspect) schemaPragmas(ctx context.Context, s *schema.Schema) error {
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(pragmasQuery, s.Name))
+	if err != nil {
+		return fmt.Errorf("sqlite: querying schema %q pragmas: %w", s.Name, err)
+	}
+	var mode, version, appID string
+	if err := sqlx.ScanOne(rows, &mode, &version, &appID); err != nil {
+		return fmt.Errorf("sqlite: scanning schema %q pragmas: %w", s.Name, err)
+	}
+	s.Attrs = append(
+		s.Attrs,
+		&Pragma{Name: PragmaJournalMode, V: mode},
+		&Pragma{Name: PragmaUserVersion, V: version},
+		&Pragma{Name: PragmaApplicationID, V: appID},
+	)
+	return nil
+}
+
+// foreignKeysPragma returns the foreign_keys pragma of the connection.
+func (c *conn) foreignKeysPragma() *Pragma {
+	p := &Pragma{Name: PragmaForeignKeys, V: "off"}
+	if c.fkEnabled {
+		p.V = "on"
+	}
+	return p
+}
+
+// RealmAttrDiff implements the sqlx.RealmAttrDiffer interface, and returns
+// the changes of the realm pragmas that are declared by the desired state.
+func (d *diff) RealmAttrDiff(from, to *schema.Realm) []schema.Change {
+	return pragmaDiff(from.Attrs, to.Attrs)
+}
+
+// pragmaDiff returns the changes of the pragmas that are declared by the desired state.
+// Pragmas that are not declared are ignored, as they cannot be dropped.
+func pragmaDiff(from, to []schema.Attr) []schema.Change {
+	var changes []schema.Change
+	for _, p2 := range pragmas(to) {
+		switch p1, ok := pragma(from, p2.Name); {
+		case !ok:
+			changes = append(changes, &schema.AddAttr{A: p2})
+		case p1.value() != p2.value():
+			changes = append(changes, &schema.ModifyAttr{From: p1, To: p2})
+		}
+	}
+	return changes
+}
+
+// pragmas returns the pragmas in the given attributes.
+func pragmas(attrs []schema.Attr) []*Pragma {
+	var ps []*Pragma
+	for _, a := range attrs {
+		if p, ok := a.(*Pragma); ok {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// pragma returns the pragma with the given name, if exists.
+func pragma(attrs []schema.Attr, name string) (*Pragma, bool) {
+	for _, p := range pragmas(attrs) {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// value returns the normalized value of the pragma. Boolean pragmas are
+// normalized to on/off, and integer pragmas to their decimal form.
+func (p *Pragma) value() string {
+	v := strings.ToLower(strings.TrimSpace(p.V))
+	switch strings.ToLower(p.Name) {
+	case PragmaForeignKeys:
+		switch v {
+		case "1", "on", "true", "yes":
+			return "on"
+		default:
+			return "off"
+		}
+	case PragmaUserVersion, PragmaApplicationID:
+		if i, err := strconv.ParseInt(v, 0, 64); err == nil {
+			return strconv.FormatInt(i, 10)
+		}
+	}
+	return v
+}
+
+// modifySchema builds and appends the migrate.Changes for bringing
+// the schema into its modified state. Only pragmas can be modified.
+func (s *state) modifySchema(modify *schema.ModifySchema) error {
+	for _, c := range modify.Changes {
+		pc, err := s.pragmaChange(modify.S, c)
+		if err != nil {
+			return err
+		}
+		pc.Source = modify
+		s.append(pc)
+	}
+	return nil
+}
+
+// pragmaChange returns the statement for setting the pragma of the given attribute change.
+// Pragmas of the realm (i.e. foreign_keys) are set on the connection, and the schema is nil.
+func (s *state) pragmaChange(sc *schema.Schema, c schema.Change) (*migrate.Change, error) {
+	var from, to *Pragma
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		p, ok := c.A.(*Pragma)
+		if !ok {
+			return nil, fmt.Errorf("unsupported attribute %T", c.A)
+		}
+		to = p
+		if v, ok := pragmaDefaults[strings.ToLower(p.Name)]; ok {
+			from = &Pragma{Name: p.Name, V: v}
+		}
+	case *schema.ModifyAttr:
+		p1, ok1 := c.From.(*Pragma)
+		p2, ok2 := c.To.(*Pragma)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("unsupported attribute change %T -> %T", c.From, c.To)
+		}
+		from, to = p1, p2
+	default:
+		return nil, fmt.Errorf("unsupported pragma change %T", c)
+	}
+	// Changing the journal mode into (or out of) WAL fails inside a transaction,
+	// and setting foreign_keys in a transaction is a no-op. See the docs of these
+	// pragmas in https://sqlite.org/pragma.html.
+	switch strings.ToLower(to.Name) {
+	case PragmaJournalMode, PragmaForeignKeys:
+		s.Transactional = false
+	}
+	pc := &migrate.Change{
+		Cmd:     s.setPragma(sc, to),
+		Source:  c,
+		Comment: fmt.Sprintf("set pragma %s to %q", to.Name, to.V),
+	}
+	if from != nil {
+		pc.Reverse = s.setPragma(sc, from)
+	}
+	return pc, nil
+}
+
+// setPragma returns the PRAGMA statement for setting the given pragma.
+func (s *state) setPragma(sc *schema.Schema, p *Pragma) string {
+	var qualifier string
+	if sc != nil {
+		qualifier = sc.Name
+	}
+	return s.build("PRAGMA").QualifiedIdent(qualifier, p.Name).P("=", p.value()).String()
+}
@@ -0,0 +1,132 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriver_InspectPragmas(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.systemVars("3.36.0")
+	drv, err := Open(db, WithPragmas(true))
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(databasesQuery)).
+		WillReturnRows(sqltest.Rows(`
+ name |   file
+------+-----------
+ main | app.db
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(pragmasQuery, "main"))).
+		WillReturnRows(sqltest.Rows(`
+ journal_mode | user_version | application_id
+--------------+--------------+----------------
+ wal          | 3            | 1096043603
+`))
+	m.ExpectQuery(sqltest.Escape(tablesQuery + " ORDER BY `name`")).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "sql"}))
+	realm, err := drv.InspectRealm(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Attr{&Pragma{Name: PragmaForeignKeys, V: "on"}}, realm.Attrs)
+	require.Equal(t, []schema.Attr{
+		&File{Name: "app.db"},
+		&Pragma{Name: PragmaJournalMode, V: "wal"},
+		&Pragma{Name: PragmaUserVersion, V: "3"},
+		&Pragma{Name: PragmaApplicationID, V: "1096043603"},
+	}, realm.Schemas[0].Attrs)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestDiff_Pragmas(t *testing.T) {
+	var (
+		from = schema.NewRealm(schema.New("main").AddAttrs(
+			&Pragma{Name: PragmaJournalMode, V: "delete"},
+			&Pragma{Name: PragmaUserVersion, V: "3"},
+			&Pragma{Name: PragmaApplicationID, V: "1096043603"},
+		)).AddAttrs(&Pragma{Name: PragmaForeignKeys, V: "off"})
+		to = schema.NewRealm(schema.New("main").AddAttrs(
+			&Pragma{Name: PragmaJournalMode, V: "WAL"},
+			&Pragma{Name: PragmaApplicationID, V: "0x41544C53"},
+		)).AddAttrs(&Pragma{Name: PragmaForeignKeys, V: "1"})
+	)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.systemVars("3.36.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	changes, err := drv.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		&schema.ModifySchema{S: to.Schemas[0], Changes: []schema.Change{
+			&schema.ModifyAttr{From: from.Schemas[0].Attrs[0], To: to.Schemas[0].Attrs[0]},
+		}},
+		&schema.ModifyAttr{From: from.Attrs[0], To: to.Attrs[0]},
+	}, changes)
+
+	// Pragmas that are not declared by the desired state are ignored.
+	changes, err = drv.RealmDiff(to, schema.NewRealm(schema.New("main")))
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestPlanChanges_Pragmas(t *testing.T) {
+	var (
+		main  = schema.New("main")
+		users = schema.NewTable("users").SetSchema(main).AddColumns(schema.NewIntColumn("id", "int"))
+	)
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.systemVars("3.36.0")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifySchema{S: main, Changes: []schema.Change{
+			&schema.ModifyAttr{From: &Pragma{Name: PragmaJournalMode, V: "delete"}, To: &Pragma{Name: PragmaJournalMode, V: "WAL"}},
+			&schema.AddAttr{A: &Pragma{Name: PragmaApplicationID, V: "0x41544C53"}},
+		}},
+		&schema.ModifyAttr{From: &Pragma{Name: PragmaForeignKeys, V: "on"}, To: &Pragma{Name: PragmaForeignKeys, V: "off"}},
+		&schema.AddTable{T: users},
+	})
+	require.NoError(t, err)
+	require.True(t, plan.Reversible)
+	for i, c := range []struct{ cmd, reverse string }{
+		{"PRAGMA `main`.`journal_mode` = wal", "PRAGMA `main`.`journal_mode` = delete"},
+		{"PRAGMA `main`.`application_id` = 1096043603", "PRAGMA `main`.`application_id` = 0"},
+		{"CREATE TABLE `users` (`id` int NOT NULL)", "DROP TABLE `main`.`users`"},
+		{"PRAGMA `foreign_keys` = off", "PRAGMA `foreign_keys` = on"},
+	} {
+		require.Equal(t, c.cmd, plan.Changes[i].Cmd)
+		require.Equal(t, c.reverse, plan.Changes[i].Reverse)
+	}
+	require.Len(t, plan.Changes, 4)
+	// The journal_mode and foreign_keys pragmas cannot be set in a transaction.
+	require.False(t, plan.Transactional)
+
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifySchema{S: main, Changes: []schema.Change{
+			&schema.AddAttr{A: &Pragma{Name: PragmaUserVersion, V: "2"}},
+		}},
+	})
+	require.NoError(t, err)
+	require.True(t, plan.Transactional)
+	require.Len(t, plan.Changes, 1)
+	require.Equal(t, "PRAGMA `main`.`user_version` = 2", plan.Changes[0].Cmd)
+
+	plan, err = drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.AddAttr{A: &Pragma{Name: PragmaForeignKeys, V: "on"}},
+	})
+	require.NoError(t, err)
+	require.False(t, plan.Transactional)
+}
@@ -1,7 +1,9 @@
 package sqlite
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 
 	"ariga.io/atlas/schema/schemaspec"
 	"ariga.io/atlas/schema/schemaspec/schemahcl"
@@ -68,6 +70,21 @@ func columnTypeSpec(t schema.Type) (*sqlspec.Column, error) {
 	return &sqlspec.Column{Type: st}, nil
 }
 
+// registerSpec exposes the custom type to schema specs, if it is not already
+// defined there. For example, "double precision" is named "double_precision".
+func registerSpec(name string) error {
+	for _, s := range TypeRegistry.Specs() {
+		if strings.EqualFold(s.T, name) {
+			return nil
+		}
+	}
+	if err := TypeRegistry.Register(specutil.AliasTypeSpec(strings.ReplaceAll(name, " ", "_"), name)); err != nil {
+		return fmt.Errorf("sqlite: registering custom type %q: %w", name, err)
+	}
+	hclState = schemahcl.New(schemahcl.WithTypes(TypeRegistry.Specs()))
+	return nil
+}
+
 // TypeRegistry contains the supported TypeSpecs for the sqlite driver.
 var TypeRegistry = specutil.NewRegistry(
 	specutil.WithFormatter(FormatType),
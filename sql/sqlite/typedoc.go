@@ -0,0 +1,78 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"reflect"
+
+	"ariga.io/atlas/sql/typedoc"
+)
+
+// TypeDocs describes the column types that are recognized by the SQLite driver. Note that SQLite
+// accepts any type name, and determines the storage class of the column by its type affinity.
+// The types below are the ones that are mapped by the driver to a schema.Type.
+var TypeDocs = &typedoc.Dialect{
+	Name: "sqlite",
+	Types: []*typedoc.Type{
+		{
+			Name:     TypeInteger,
+			Aliases:  []string{"int", "tinyint", "smallint", "mediumint", "bigint", "unsigned big int", "int2", "int8"},
+			Category: typedoc.CategoryInteger,
+			Params:   []*typedoc.Param{{Name: "size", Kind: reflect.Int}},
+			Doc:      "A signed integer with INTEGER affinity, stored in 1 to 8 bytes.",
+		},
+		{
+			Name:     TypeReal,
+			Aliases:  []string{"double", "double precision", "float"},
+			Category: typedoc.CategoryFloat,
+			Doc:      "An 8-byte floating-point number with REAL affinity.",
+		},
+		{
+			Name:     "numeric",
+			Aliases:  []string{"decimal"},
+			Category: typedoc.CategoryDecimal,
+			Params: []*typedoc.Param{
+				{Name: "precision", Kind: reflect.Int},
+				{Name: "scale", Kind: reflect.Int},
+			},
+			Doc: "A number with NUMERIC affinity. The precision and scale are not enforced.",
+		},
+		{
+			Name:     TypeText,
+			Aliases:  []string{"char", "character", "varchar", "varying character", "nchar", "native character", "nvarchar", "clob"},
+			Category: typedoc.CategoryString,
+			Params:   []*typedoc.Param{{Name: "size", Kind: reflect.Int}},
+			Doc:      "A string with TEXT affinity. The size is not enforced.",
+		},
+		{
+			Name:     TypeBlob,
+			Category: typedoc.CategoryBinary,
+			Params:   []*typedoc.Param{{Name: "size", Kind: reflect.Int}},
+			Doc:      "A blob of data, stored exactly as it was input.",
+		},
+		{
+			Name:     "boolean",
+			Aliases:  []string{"bool"},
+			Category: typedoc.CategoryBool,
+			Doc:      "A Boolean with NUMERIC affinity, stored as the integers 0 (false) and 1 (true).",
+		},
+		{
+			Name:     "datetime",
+			Aliases:  []string{"date", "time", "timestamp"},
+			Category: typedoc.CategoryTime,
+			Doc:      "A date and time with NUMERIC affinity, stored as TEXT, REAL or INTEGER values.",
+		},
+		{
+			Name:     "json",
+			Category: typedoc.CategoryJSON,
+			Doc:      "A JSON document with NUMERIC affinity, stored as TEXT.",
+		},
+		{
+			Name:     "uuid",
+			Category: typedoc.CategoryUUID,
+			Doc:      "A universally unique identifier with NUMERIC affinity, stored as TEXT or BLOB.",
+		},
+	},
+}
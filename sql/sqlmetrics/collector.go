@@ -0,0 +1,170 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlmetrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefBuckets are the default buckets (in seconds) of the duration histograms.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+type (
+	// A Collector is a Recorder that aggregates the events of the operations into
+	// counters and histograms, and exposes them in the Prometheus text format. For
+	// example:
+	//
+	//	c := sqlmetrics.NewCollector()
+	//	drv, err := mysql.Open(db, mysql.WithMetrics(c))
+	//	http.Handle("/metrics", c)
+	//
+	// The following metrics are exposed, labeled by the database system and the
+	// operation:
+	//
+	//	atlas_operations_total            counter of completed operations.
+	//	atlas_operation_errors_total      counter of failed operations.
+	//	atlas_operation_changes_total     counter of computed, planned and applied changes.
+	//	atlas_operation_duration_seconds  histogram of operation durations.
+	//
+	Collector struct {
+		buckets []float64
+		mu      sync.Mutex
+		series  map[seriesKey]*series
+	}
+
+	// CollectorOption allows configuring the Collector using functional options.
+	CollectorOption func(*Collector)
+
+	seriesKey struct {
+		system, op string
+	}
+
+	series struct {
+		count, errors, changes uint64
+		sum                    float64
+		// Counts of observations per bucket (non-cumulative).
+		buckets []uint64
+	}
+)
+
+// NewCollector returns a new Collector.
+func NewCollector(opts ...CollectorOption) *Collector {
+	c := &Collector{buckets: DefBuckets, series: make(map[seriesKey]*series)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithBuckets sets the upper bounds (in seconds, sorted in increasing
+// order) of the buckets of the duration histograms.
+func WithBuckets(b ...float64) CollectorOption {
+	return func(c *Collector) {
+		c.buckets = b
+	}
+}
+
+// Record implements the Recorder interface.
+func (c *Collector) Record(_ context.Context, e *Event) {
+	k := seriesKey{system: e.System, op: e.Op}
+	d := e.Duration.Seconds()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[k]
+	if !ok {
+		s = &series{buckets: make([]uint64, len(c.buckets))}
+		c.series[k] = s
+	}
+	s.count++
+	s.sum += d
+	s.changes += uint64(e.Changes)
+	if e.Err != nil {
+		s.errors++
+	}
+	if i := sort.SearchFloat64s(c.buckets, d); i < len(c.buckets) {
+		s.buckets[i]++
+	}
+}
+
+// ServeHTTP writes the metrics of the collector in the Prometheus text format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := c.WriteTo(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WriteTo writes the metrics of the collector to w in the Prometheus text format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	keys := make([]seriesKey, 0, len(c.series))
+	for k := range c.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].system != keys[j].system {
+			return keys[i].system < keys[j].system
+		}
+		return keys[i].op < keys[j].op
+	})
+	// Copy the series in order to not hold the lock while writing.
+	ss := make([]series, len(keys))
+	for i, k := range keys {
+		ss[i] = *c.series[k]
+		ss[i].buckets = append([]uint64(nil), ss[i].buckets...)
+	}
+	c.mu.Unlock()
+	cw := &countWriter{w: w}
+	counter := func(name, help string, v func(*series) uint64) {
+		fmt.Fprintf(cw, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for i, k := range keys {
+			fmt.Fprintf(cw, "%s{%s} %d\n", name, k.labels(), v(&ss[i]))
+		}
+	}
+	counter("atlas_operations_total", "Total number of completed schema operations.", func(s *series) uint64 { return s.count })
+	counter("atlas_operation_errors_total", "Total number of failed schema operations.", func(s *series) uint64 { return s.errors })
+	counter("atlas_operation_changes_total", "Total number of computed, planned and applied changes.", func(s *series) uint64 { return s.changes })
+	const hist = "atlas_operation_duration_seconds"
+	fmt.Fprintf(cw, "# HELP %s Duration of schema operations in seconds.\n# TYPE %s histogram\n", hist, hist)
+	for i, k := range keys {
+		var n uint64
+		for j, b := range c.buckets {
+			n += ss[i].buckets[j]
+			fmt.Fprintf(cw, "%s_bucket{%s,le=%q} %d\n", hist, k.labels(), strconv.FormatFloat(b, 'g', -1, 64), n)
+		}
+		fmt.Fprintf(cw, "%s_bucket{%s,le=\"+Inf\"} %d\n", hist, k.labels(), ss[i].count)
+		fmt.Fprintf(cw, "%s_sum{%s} %s\n", hist, k.labels(), strconv.FormatFloat(ss[i].sum, 'g', -1, 64))
+		fmt.Fprintf(cw, "%s_count{%s} %d\n", hist, k.labels(), ss[i].count)
+	}
+	return cw.n, cw.err
+}
+
+func (k seriesKey) labels() string {
+	return fmt.Sprintf("system=%q,op=%q", k.system, k.op)
+}
+
+// countWriter counts the bytes that are written to w,
+// and records the first error that is returned by it.
+type countWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.err = err
+	return n, err
+}
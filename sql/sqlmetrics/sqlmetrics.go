@@ -0,0 +1,213 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqlmetrics provides metrics instrumentation for the Atlas drivers.
+// It is usually enabled using the WithMetrics option of the different drivers
+// and not used directly. Operations are reported to a Recorder, which can be
+// implemented on top of any metrics library, or to the Collector of this
+// package, which exposes them in the Prometheus text format.
+package sqlmetrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// List of the operations that are reported by the wrappers.
+const (
+	OpQuery         = "query"
+	OpExec          = "exec"
+	OpInspectSchema = "inspect_schema"
+	OpInspectRealm  = "inspect_realm"
+	OpInspectTables = "inspect_tables"
+	OpDiffRealm     = "diff_realm"
+	OpDiffSchema    = "diff_schema"
+	OpDiffTable     = "diff_table"
+	OpPlanChanges   = "plan_changes"
+	OpApplyChanges  = "apply_changes"
+)
+
+type (
+	// An Event describes a schema operation that was completed.
+	Event struct {
+		// System is the database system (e.g. "mysql").
+		System string
+		// Op is the operation (e.g. "inspect_schema"). See the Op constants.
+		Op string
+		// Duration of the operation.
+		Duration time.Duration
+		// Changes holds the number of changes that were computed by diffs,
+		// the number of statements that were planned by PlanChanges, and
+		// the number of changes that were passed to ApplyChanges.
+		Changes int
+		// Err is the error of the operation, if it failed.
+		Err error
+	}
+
+	// Recorder records the events of schema operations. Implementations are
+	// called synchronously after each operation, and must be safe for concurrent use.
+	Recorder interface {
+		Record(context.Context, *Event)
+	}
+
+	// RecordFunc type is an adapter to allow the use of ordinary
+	// functions as Recorders.
+	RecordFunc func(context.Context, *Event)
+
+	// Metrics reports the events of schema operations to a Recorder.
+	Metrics struct {
+		system string
+		r      Recorder
+	}
+
+	metricsExecQuerier struct {
+		schema.ExecQuerier
+		*Metrics
+	}
+
+	metricsInspector struct {
+		schema.Inspector
+		*Metrics
+	}
+
+	metricsDiffer struct {
+		schema.Differ
+		*Metrics
+	}
+
+	metricsPlanApplier struct {
+		migrate.PlanApplier
+		*Metrics
+	}
+)
+
+// Record calls f(ctx, e).
+func (f RecordFunc) Record(ctx context.Context, e *Event) { f(ctx, e) }
+
+// New returns a new Metrics for the given database system (e.g. "mysql").
+func New(system string, r Recorder) *Metrics {
+	return &Metrics{system: system, r: r}
+}
+
+// record reports the event of an operation that was started at the given time.
+func (m *Metrics) record(ctx context.Context, op string, start time.Time, changes int, err error) {
+	m.r.Record(ctx, &Event{
+		System:   m.system,
+		Op:       op,
+		Duration: time.Since(start),
+		Changes:  changes,
+		Err:      err,
+	})
+}
+
+// ExecQuerier wraps the given ExecQuerier and reports each executed statement.
+func (m *Metrics) ExecQuerier(eq schema.ExecQuerier) schema.ExecQuerier {
+	return &metricsExecQuerier{ExecQuerier: eq, Metrics: m}
+}
+
+// QueryContext calls the underlying QueryContext and reports its event.
+func (e *metricsExecQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := e.ExecQuerier.QueryContext(ctx, query, args...)
+	e.record(ctx, OpQuery, start, 0, err)
+	return rows, err
+}
+
+// ExecContext calls the underlying ExecContext and reports its event.
+func (e *metricsExecQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	res, err := e.ExecQuerier.ExecContext(ctx, query, args...)
+	e.record(ctx, OpExec, start, 0, err)
+	return res, err
+}
+
+// Inspector wraps the given Inspector and reports each inspection.
+func (m *Metrics) Inspector(i schema.Inspector) schema.Inspector {
+	return &metricsInspector{Inspector: i, Metrics: m}
+}
+
+// InspectSchema calls the underlying InspectSchema and reports its event.
+func (i *metricsInspector) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
+	start := time.Now()
+	s, err := i.Inspector.InspectSchema(ctx, name, opts)
+	i.record(ctx, OpInspectSchema, start, 0, err)
+	return s, err
+}
+
+// InspectRealm calls the underlying InspectRealm and reports its event.
+func (i *metricsInspector) InspectRealm(ctx context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	start := time.Now()
+	r, err := i.Inspector.InspectRealm(ctx, opts)
+	i.record(ctx, OpInspectRealm, start, 0, err)
+	return r, err
+}
+
+// InspectTables calls the underlying InspectTables and reports its event.
+func (i *metricsInspector) InspectTables(ctx context.Context, opts *schema.InspectRealmOption, fn func(*schema.Table) error) error {
+	start := time.Now()
+	err := schema.InspectTables(ctx, i.Inspector, opts, fn)
+	i.record(ctx, OpInspectTables, start, 0, err)
+	return err
+}
+
+// Differ wraps the given Differ and reports each diff computation. Note that diff
+// events are reported with a background context, as the Differ interface does not
+// accept a context.
+func (m *Metrics) Differ(d schema.Differ) schema.Differ {
+	return &metricsDiffer{Differ: d, Metrics: m}
+}
+
+// RealmDiff calls the underlying RealmDiff and reports its event.
+func (d *metricsDiffer) RealmDiff(from, to *schema.Realm) ([]schema.Change, error) {
+	start := time.Now()
+	changes, err := d.Differ.RealmDiff(from, to)
+	d.record(context.Background(), OpDiffRealm, start, len(changes), err)
+	return changes, err
+}
+
+// SchemaDiff calls the underlying SchemaDiff and reports its event.
+func (d *metricsDiffer) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
+	start := time.Now()
+	changes, err := d.Differ.SchemaDiff(from, to)
+	d.record(context.Background(), OpDiffSchema, start, len(changes), err)
+	return changes, err
+}
+
+// TableDiff calls the underlying TableDiff and reports its event.
+func (d *metricsDiffer) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
+	start := time.Now()
+	changes, err := d.Differ.TableDiff(from, to)
+	d.record(context.Background(), OpDiffTable, start, len(changes), err)
+	return changes, err
+}
+
+// PlanApplier wraps the given PlanApplier and reports planning and applying of changes.
+// Statements that are executed by ApplyChanges are reported by the ExecQuerier wrapper.
+func (m *Metrics) PlanApplier(p migrate.PlanApplier) migrate.PlanApplier {
+	return &metricsPlanApplier{PlanApplier: p, Metrics: m}
+}
+
+// PlanChanges calls the underlying PlanChanges and reports its event.
+func (p *metricsPlanApplier) PlanChanges(ctx context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+	start := time.Now()
+	plan, err := p.PlanApplier.PlanChanges(ctx, name, changes)
+	var n int
+	if plan != nil {
+		n = len(plan.Changes)
+	}
+	p.record(ctx, OpPlanChanges, start, n, err)
+	return plan, err
+}
+
+// ApplyChanges calls the underlying ApplyChanges and reports its event.
+func (p *metricsPlanApplier) ApplyChanges(ctx context.Context, changes []schema.Change) error {
+	start := time.Now()
+	err := p.PlanApplier.ApplyChanges(ctx, changes)
+	p.record(ctx, OpApplyChanges, start, len(changes), err)
+	return err
+}
@@ -0,0 +1,104 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlmetrics_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlmetrics"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_ExecQuerier(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	var events []*sqlmetrics.Event
+	eq := sqlmetrics.New("mysql", sqlmetrics.RecordFunc(func(_ context.Context, e *sqlmetrics.Event) {
+		events = append(events, e)
+	})).ExecQuerier(db)
+	m.ExpectExec(sqltest.Escape("CREATE TABLE `t` (`c` int)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	_, err = eq.ExecContext(context.Background(), "CREATE TABLE `t` (`c` int)")
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape("SELECT 1")).
+		WillReturnError(errors.New("boom"))
+	_, err = eq.QueryContext(context.Background(), "SELECT 1")
+	require.EqualError(t, err, "boom")
+
+	require.Len(t, events, 2)
+	require.Equal(t, "mysql", events[0].System)
+	require.Equal(t, sqlmetrics.OpExec, events[0].Op)
+	require.NoError(t, events[0].Err)
+	require.Equal(t, sqlmetrics.OpQuery, events[1].Op)
+	require.EqualError(t, events[1].Err, "boom")
+}
+
+func TestMetrics_Differ(t *testing.T) {
+	var events []*sqlmetrics.Event
+	d := sqlmetrics.New("postgresql", sqlmetrics.RecordFunc(func(_ context.Context, e *sqlmetrics.Event) {
+		events = append(events, e)
+	})).Differ(&mockDiffer{changes: []schema.Change{&schema.AddTable{}, &schema.DropTable{}}})
+	_, err := d.SchemaDiff(schema.New("public"), schema.New("public"))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, sqlmetrics.OpDiffSchema, events[0].Op)
+	require.Equal(t, 2, events[0].Changes)
+}
+
+func TestCollector(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = sqlmetrics.NewCollector(sqlmetrics.WithBuckets(0.1, 1))
+	)
+	c.Record(ctx, &sqlmetrics.Event{System: "mysql", Op: sqlmetrics.OpExec, Duration: 50 * time.Millisecond})
+	c.Record(ctx, &sqlmetrics.Event{System: "mysql", Op: sqlmetrics.OpExec, Duration: 2 * time.Second, Err: errors.New("boom")})
+	c.Record(ctx, &sqlmetrics.Event{System: "mysql", Op: sqlmetrics.OpDiffRealm, Duration: 500 * time.Millisecond, Changes: 3})
+	var b bytes.Buffer
+	n, err := c.WriteTo(&b)
+	require.NoError(t, err)
+	require.EqualValues(t, b.Len(), n)
+	require.Equal(t, `# HELP atlas_operations_total Total number of completed schema operations.
+# TYPE atlas_operations_total counter
+atlas_operations_total{system="mysql",op="diff_realm"} 1
+atlas_operations_total{system="mysql",op="exec"} 2
+# HELP atlas_operation_errors_total Total number of failed schema operations.
+# TYPE atlas_operation_errors_total counter
+atlas_operation_errors_total{system="mysql",op="diff_realm"} 0
+atlas_operation_errors_total{system="mysql",op="exec"} 1
+# HELP atlas_operation_changes_total Total number of computed, planned and applied changes.
+# TYPE atlas_operation_changes_total counter
+atlas_operation_changes_total{system="mysql",op="diff_realm"} 3
+atlas_operation_changes_total{system="mysql",op="exec"} 0
+# HELP atlas_operation_duration_seconds Duration of schema operations in seconds.
+# TYPE atlas_operation_duration_seconds histogram
+atlas_operation_duration_seconds_bucket{system="mysql",op="diff_realm",le="0.1"} 0
+atlas_operation_duration_seconds_bucket{system="mysql",op="diff_realm",le="1"} 1
+atlas_operation_duration_seconds_bucket{system="mysql",op="diff_realm",le="+Inf"} 1
+atlas_operation_duration_seconds_sum{system="mysql",op="diff_realm"} 0.5
+atlas_operation_duration_seconds_count{system="mysql",op="diff_realm"} 1
+atlas_operation_duration_seconds_bucket{system="mysql",op="exec",le="0.1"} 1
+atlas_operation_duration_seconds_bucket{system="mysql",op="exec",le="1"} 1
+atlas_operation_duration_seconds_bucket{system="mysql",op="exec",le="+Inf"} 2
+atlas_operation_duration_seconds_sum{system="mysql",op="exec"} 2.05
+atlas_operation_duration_seconds_count{system="mysql",op="exec"} 2
+`, b.String())
+}
+
+type mockDiffer struct {
+	schema.Differ
+	changes []schema.Change
+}
+
+func (d *mockDiffer) SchemaDiff(_, _ *schema.Schema) ([]schema.Change, error) {
+	return d.changes, nil
+}
@@ -71,6 +71,7 @@ type (
 		RefColumns []*schemaspec.Ref      `spec:"ref_columns"`
 		OnUpdate   schema.ReferenceOption `spec:"on_update"`
 		OnDelete   schema.ReferenceOption `spec:"on_delete"`
+		Match      schema.ReferenceMatch  `spec:"match,omitempty"`
 		schemaspec.DefaultExtension
 	}
 
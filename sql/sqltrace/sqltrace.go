@@ -0,0 +1,227 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqltrace provides OpenTelemetry instrumentation for the Atlas drivers.
+// It is usually enabled using the WithTracing option of the different drivers
+// and not used directly.
+package sqltrace
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer that is used for creating spans.
+const InstrumentationName = "ariga.io/atlas"
+
+type (
+	// Config configures the tracing instrumentation.
+	Config struct {
+		// Provider is the tracer provider used for creating spans.
+		// The global provider is used if it is nil.
+		Provider trace.TracerProvider
+		// Redact allows changing, or hiding, the SQL statements before they
+		// are recorded in the "db.statement" attribute. An empty string
+		// returned from the function omits the attribute from the span.
+		Redact func(stmt string) string
+		// Attrs are extra attributes that are added to all spans.
+		Attrs []attribute.KeyValue
+	}
+
+	// Tracer creates spans for schema operations.
+	Tracer struct {
+		t      trace.Tracer
+		redact func(string) string
+		attrs  []attribute.KeyValue
+	}
+
+	tracedExecQuerier struct {
+		schema.ExecQuerier
+		*Tracer
+	}
+
+	tracedInspector struct {
+		schema.Inspector
+		*Tracer
+	}
+
+	tracedDiffer struct {
+		schema.Differ
+		*Tracer
+	}
+
+	tracedPlanApplier struct {
+		migrate.PlanApplier
+		*Tracer
+	}
+)
+
+// New returns a new Tracer for the given database system (e.g. "mysql").
+func New(system string, cfg Config) *Tracer {
+	tp := cfg.Provider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &Tracer{
+		t:      tp.Tracer(InstrumentationName),
+		redact: cfg.Redact,
+		attrs:  append([]attribute.KeyValue{semconv.DBSystemKey.String(system)}, cfg.Attrs...),
+	}
+}
+
+// Start starts a new span with the given name and attributes.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(append(attrs, t.attrs...)...))
+}
+
+// End ends the given span and records the error, if exists.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// stmt returns the "db.statement" attribute of the given query, if it should be added.
+func (t *Tracer) stmt(query string) []attribute.KeyValue {
+	if t.redact != nil {
+		query = t.redact(query)
+	}
+	if query == "" {
+		return nil
+	}
+	return []attribute.KeyValue{semconv.DBStatementKey.String(query)}
+}
+
+// ExecQuerier wraps the given ExecQuerier with spans for each executed statement.
+func (t *Tracer) ExecQuerier(eq schema.ExecQuerier) schema.ExecQuerier {
+	return &tracedExecQuerier{ExecQuerier: eq, Tracer: t}
+}
+
+// QueryContext calls the underlying QueryContext within a span.
+func (e *tracedExecQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := e.Start(ctx, "atlas.query", e.stmt(query)...)
+	rows, err := e.ExecQuerier.QueryContext(ctx, query, args...)
+	End(span, err)
+	return rows, err
+}
+
+// ExecContext calls the underlying ExecContext within a span.
+func (e *tracedExecQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := e.Start(ctx, "atlas.exec", e.stmt(query)...)
+	res, err := e.ExecQuerier.ExecContext(ctx, query, args...)
+	End(span, err)
+	return res, err
+}
+
+// Inspector wraps the given Inspector with spans for each inspection.
+func (t *Tracer) Inspector(i schema.Inspector) schema.Inspector {
+	return &tracedInspector{Inspector: i, Tracer: t}
+}
+
+// InspectSchema calls the underlying InspectSchema within a span.
+func (i *tracedInspector) InspectSchema(ctx context.Context, name string, opts *schema.InspectOptions) (*schema.Schema, error) {
+	ctx, span := i.Start(ctx, "atlas.inspect_schema", semconv.DBNameKey.String(name))
+	s, err := i.Inspector.InspectSchema(ctx, name, opts)
+	End(span, err)
+	return s, err
+}
+
+// InspectRealm calls the underlying InspectRealm within a span.
+func (i *tracedInspector) InspectRealm(ctx context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	ctx, span := i.Start(ctx, "atlas.inspect_realm")
+	r, err := i.Inspector.InspectRealm(ctx, opts)
+	End(span, err)
+	return r, err
+}
+
+// InspectTables calls the underlying InspectTables within a span.
+func (i *tracedInspector) InspectTables(ctx context.Context, opts *schema.InspectRealmOption, fn func(*schema.Table) error) error {
+	ctx, span := i.Start(ctx, "atlas.inspect_tables")
+	err := schema.InspectTables(ctx, i.Inspector, opts, fn)
+	End(span, err)
+	return err
+}
+
+// Differ wraps the given Differ with spans for each diff computation. Note that
+// diff spans are started as root spans, as the Differ interface does not accept
+// a context.
+func (t *Tracer) Differ(d schema.Differ) schema.Differ {
+	return &tracedDiffer{Differ: d, Tracer: t}
+}
+
+// RealmDiff calls the underlying RealmDiff within a span.
+func (d *tracedDiffer) RealmDiff(from, to *schema.Realm) ([]schema.Change, error) {
+	_, span := d.Start(context.Background(), "atlas.diff_realm")
+	changes, err := d.Differ.RealmDiff(from, to)
+	d.end(span, changes, err)
+	return changes, err
+}
+
+// SchemaDiff calls the underlying SchemaDiff within a span.
+func (d *tracedDiffer) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
+	_, span := d.Start(context.Background(), "atlas.diff_schema", semconv.DBNameKey.String(to.Name))
+	changes, err := d.Differ.SchemaDiff(from, to)
+	d.end(span, changes, err)
+	return changes, err
+}
+
+// TableDiff calls the underlying TableDiff within a span.
+func (d *tracedDiffer) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
+	_, span := d.Start(context.Background(), "atlas.diff_table", semconv.DBSQLTableKey.String(to.Name))
+	changes, err := d.Differ.TableDiff(from, to)
+	d.end(span, changes, err)
+	return changes, err
+}
+
+func (d *tracedDiffer) end(span trace.Span, changes []schema.Change, err error) {
+	span.SetAttributes(attribute.Int("atlas.changes", len(changes)))
+	End(span, err)
+}
+
+// PlanApplier wraps the given PlanApplier with spans for planning and applying changes.
+// Statements that are executed by ApplyChanges are traced by the ExecQuerier wrapper.
+func (t *Tracer) PlanApplier(p migrate.PlanApplier) migrate.PlanApplier {
+	return &tracedPlanApplier{PlanApplier: p, Tracer: t}
+}
+
+// PlanChanges calls the underlying PlanChanges within a span.
+func (p *tracedPlanApplier) PlanChanges(ctx context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+	ctx, span := p.Start(ctx, "atlas.plan_changes", attribute.Int("atlas.changes", len(changes)))
+	plan, err := p.PlanApplier.PlanChanges(ctx, name, changes)
+	if plan != nil {
+		span.SetAttributes(attribute.Int("atlas.statements", len(plan.Changes)))
+	}
+	End(span, err)
+	return plan, err
+}
+
+// ApplyChanges calls the underlying ApplyChanges within a span.
+func (p *tracedPlanApplier) ApplyChanges(ctx context.Context, changes []schema.Change) error {
+	ctx, span := p.Start(ctx, "atlas.apply_changes", attribute.Int("atlas.changes", len(changes)))
+	err := p.PlanApplier.ApplyChanges(ctx, changes)
+	End(span, err)
+	return err
+}
+
+// reLiteral matches string and numeric literals in SQL statements.
+var reLiteral = regexp.MustCompile(`'(?:[^']|'')*'|\b\d+(?:\.\d+)?\b`)
+
+// RedactLiterals is a redaction function that replaces all string and numeric
+// literals in the given statement with a question mark. It can be used as the
+// Config.Redact function to avoid recording sensitive data.
+func RedactLiterals(stmt string) string {
+	return reLiteral.ReplaceAllString(stmt, "?")
+}
@@ -0,0 +1,176 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqltrace_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ariga.io/atlas/sql/internal/sqltest"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqltrace"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer_ExecQuerier(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	tp := &mockProvider{}
+	tr := sqltrace.New("mysql", sqltrace.Config{
+		Provider: tp,
+		Redact:   sqltrace.RedactLiterals,
+		Attrs:    []attribute.KeyValue{attribute.String("tenant", "a8m")},
+	})
+	eq := tr.ExecQuerier(db)
+	m.ExpectExec(sqltest.Escape("INSERT INTO `t` VALUES ('secret', 10)")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	_, err = eq.ExecContext(context.Background(), "INSERT INTO `t` VALUES ('secret', 10)")
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape("SELECT 1")).
+		WillReturnError(errors.New("boom"))
+	_, err = eq.QueryContext(context.Background(), "SELECT 1")
+	require.EqualError(t, err, "boom")
+
+	require.Len(t, tp.spans, 2)
+	require.Equal(t, "atlas.exec", tp.spans[0].name)
+	require.Equal(t, map[attribute.Key]string{
+		"db.statement": "INSERT INTO `t` VALUES (?, ?)",
+		"db.system":    "mysql",
+		"tenant":       "a8m",
+	}, tp.spans[0].attrs)
+	require.True(t, tp.spans[0].ended)
+	require.Equal(t, codes.Unset, tp.spans[0].code)
+	require.Equal(t, "atlas.query", tp.spans[1].name)
+	require.Equal(t, "SELECT ?", tp.spans[1].attrs["db.statement"])
+	require.Equal(t, codes.Error, tp.spans[1].code)
+	require.Len(t, tp.spans[1].errs, 1)
+}
+
+func TestTracer_Omit(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	tp := &mockProvider{}
+	tr := sqltrace.New("sqlite", sqltrace.Config{
+		Provider: tp,
+		Redact:   func(string) string { return "" },
+	})
+	m.ExpectExec(sqltest.Escape("DROP TABLE `t`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	_, err = tr.ExecQuerier(db).ExecContext(context.Background(), "DROP TABLE `t`")
+	require.NoError(t, err)
+	require.Len(t, tp.spans, 1)
+	require.NotContains(t, tp.spans[0].attrs, attribute.Key("db.statement"))
+}
+
+func TestTracer_Wrappers(t *testing.T) {
+	tp := &mockProvider{}
+	tr := sqltrace.New("postgresql", sqltrace.Config{Provider: tp})
+
+	s, err := tr.Inspector(mockInspector{}).InspectSchema(context.Background(), "public", nil)
+	require.NoError(t, err)
+	require.Equal(t, "public", s.Name)
+	_, err = tr.Inspector(mockInspector{}).InspectRealm(context.Background(), nil)
+	require.NoError(t, err)
+
+	changes, err := tr.Differ(mockDiffer{}).SchemaDiff(schema.New("public"), schema.New("public"))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+
+	pa := tr.PlanApplier(mockPlanApplier{})
+	plan, err := pa.PlanChanges(context.Background(), "plan", changes)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	require.EqualError(t, pa.ApplyChanges(context.Background(), changes), "apply")
+
+	require.Len(t, tp.spans, 5)
+	require.Equal(t, "atlas.inspect_schema", tp.spans[0].name)
+	require.Equal(t, "public", tp.spans[0].attrs["db.name"])
+	require.Equal(t, "atlas.inspect_realm", tp.spans[1].name)
+	require.Equal(t, "atlas.diff_schema", tp.spans[2].name)
+	require.Equal(t, "1", tp.spans[2].attrs["atlas.changes"])
+	require.Equal(t, "atlas.plan_changes", tp.spans[3].name)
+	require.Equal(t, "1", tp.spans[3].attrs["atlas.statements"])
+	require.Equal(t, "atlas.apply_changes", tp.spans[4].name)
+	require.Equal(t, codes.Error, tp.spans[4].code)
+	for _, s := range tp.spans {
+		require.True(t, s.ended)
+		require.Equal(t, "postgresql", s.attrs["db.system"])
+	}
+}
+
+func TestRedactLiterals(t *testing.T) {
+	require.Equal(t, "SELECT * FROM `t1` WHERE `a` = ? AND `b` = ?", sqltrace.RedactLiterals("SELECT * FROM `t1` WHERE `a` = 'it''s' AND `b` = 1.5"))
+}
+
+type (
+	mockProvider struct {
+		trace.TracerProvider
+		spans []*mockSpan
+	}
+	mockTracer struct {
+		trace.Tracer
+		p *mockProvider
+	}
+	mockSpan struct {
+		trace.Span
+		name  string
+		attrs map[attribute.Key]string
+		code  codes.Code
+		errs  []error
+		ended bool
+	}
+	mockInspector   struct{ schema.Inspector }
+	mockDiffer      struct{ schema.Differ }
+	mockPlanApplier struct{ migrate.PlanApplier }
+)
+
+func (p *mockProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return &mockTracer{p: p}
+}
+
+func (t *mockTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	s := &mockSpan{Span: trace.SpanFromContext(ctx), name: name, attrs: make(map[attribute.Key]string)}
+	cfg := trace.NewSpanStartConfig(opts...)
+	s.SetAttributes(cfg.Attributes()...)
+	t.p.spans = append(t.p.spans, s)
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+func (s *mockSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value.Emit()
+	}
+}
+
+func (s *mockSpan) RecordError(err error, _ ...trace.EventOption) { s.errs = append(s.errs, err) }
+func (s *mockSpan) SetStatus(code codes.Code, _ string)           { s.code = code }
+func (s *mockSpan) End(...trace.SpanEndOption)                    { s.ended = true }
+
+func (mockInspector) InspectSchema(_ context.Context, name string, _ *schema.InspectOptions) (*schema.Schema, error) {
+	return schema.New(name), nil
+}
+
+func (mockInspector) InspectRealm(context.Context, *schema.InspectRealmOption) (*schema.Realm, error) {
+	return schema.NewRealm(), nil
+}
+
+func (mockDiffer) SchemaDiff(_, to *schema.Schema) ([]schema.Change, error) {
+	return []schema.Change{&schema.AddTable{T: schema.NewTable("t").SetSchema(to)}}, nil
+}
+
+func (mockPlanApplier) PlanChanges(context.Context, string, []schema.Change) (*migrate.Plan, error) {
+	return &migrate.Plan{Changes: []*migrate.Change{{Cmd: "CREATE TABLE t"}}}, nil
+}
+
+func (mockPlanApplier) ApplyChanges(context.Context, []schema.Change) error {
+	return errors.New("apply")
+}
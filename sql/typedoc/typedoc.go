@@ -0,0 +1,213 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package typedoc provides metadata about the column types that are supported by the database
+// drivers, such as their aliases, parameters and the versions they are available in. The metadata
+// is vendored with each driver (e.g. mysql.TypeDocs), and allows building type pickers and code
+// generators on top of Atlas without hardcoding the knowledge of each dialect.
+//
+//	t, ok := mysql.TypeDocs.Lookup("integer")
+//	if ok {
+//		fmt.Println(t.Name, t.Category, t.Params)
+//	}
+//	types := postgres.TypeDocs.Query(&typedoc.Query{Category: typedoc.CategoryTime, Version: "14.2"})
+//
+package typedoc
+
+import (
+	"reflect"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+type (
+	// Dialect describes the column types of a database dialect.
+	Dialect struct {
+		// Name of the dialect. For example, "mysql".
+		Name string
+		// Types that are supported by the dialect.
+		Types []*Type
+	}
+
+	// Type describes a column type of a dialect.
+	Type struct {
+		// Name is the canonical name of the type, as it is reported by the
+		// database on inspection. For example, "character varying".
+		Name string
+		// Aliases are other names of the type that are accepted by the
+		// database. For example, "varchar".
+		Aliases []string
+		// Category of the type. For example, CategoryInteger.
+		Category string
+		// Params are the parameters of the type, in the order they are given in its
+		// definition. For example, the precision and the scale of decimal(p, s).
+		Params []*Param
+		// Availability holds the versions the type is available in.
+		// Empty means the type is available in all versions.
+		Availability []*Availability
+		// Doc is a short description of the type.
+		Doc string
+	}
+
+	// Param describes a parameter of a column type.
+	Param struct {
+		// Name of the parameter as it is named in the type attributes
+		// of the HCL documents. For example, "size" or "precision".
+		Name string
+		// Kind of the parameter value. For example, reflect.Int.
+		Kind reflect.Kind
+		// Required indicates if the parameter must be set.
+		Required bool
+		// Max is the maximum value of an integer parameter.
+		// Zero means the maximum value is not known.
+		Max int64
+	}
+
+	// Availability describes a range of versions of a database flavor (e.g. MariaDB)
+	// that a type is available in. Versions are in the form of "major[.minor[.patch]]".
+	Availability struct {
+		// Flavor of the database. Empty means all flavors of the dialect.
+		Flavor string
+		// Since is the first version the type is available in.
+		// Empty means the type is available in all versions before Until.
+		Since string
+		// Until is the first version the type is no longer available
+		// in. Empty means the type is available in all versions since.
+		Until string
+	}
+
+	// Query describes a query for the types of a dialect. Empty fields match all types.
+	Query struct {
+		// Name matches types by their name or one of their aliases, case-insensitive.
+		Name string
+		// Category matches types by their category.
+		Category string
+		// Flavor and Version match the types that are available in the
+		// given database flavor and version. For example, "mariadb" and "10.5".
+		Flavor, Version string
+	}
+)
+
+// List of the type categories.
+const (
+	CategoryBool    = "bool"
+	CategoryInteger = "integer"
+	CategoryDecimal = "decimal"
+	CategoryFloat   = "float"
+	CategoryString  = "string"
+	CategoryBinary  = "binary"
+	CategoryBit     = "bit"
+	CategoryTime    = "time"
+	CategoryJSON    = "json"
+	CategoryEnum    = "enum"
+	CategorySpatial = "spatial"
+	CategoryNetwork = "network"
+	CategoryUUID    = "uuid"
+	CategoryRange   = "range"
+	CategoryOther   = "other"
+)
+
+// Lookup returns the type with the given name or alias, case-insensitive. Type parameters
+// are ignored, and therefore, full type definitions (e.g. "varchar(255)") are accepted.
+func (d *Dialect) Lookup(name string) (*Type, bool) {
+	if i := strings.IndexByte(name, '('); i != -1 {
+		name = name[:i]
+	}
+	name = strings.TrimSpace(name)
+	for _, t := range d.Types {
+		if t.Is(name) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Query returns the types of the dialect that match the given query.
+func (d *Dialect) Query(q *Query) []*Type {
+	var types []*Type
+	for _, t := range d.Types {
+		if q == nil || q.match(t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// match reports if the type matches the query.
+func (q *Query) match(t *Type) bool {
+	switch {
+	case q.Name != "" && !t.Is(q.Name):
+		return false
+	case q.Category != "" && q.Category != t.Category:
+		return false
+	default:
+		return t.AvailableIn(q.Flavor, q.Version)
+	}
+}
+
+// Is reports if the given name is the name of the type or one of its aliases.
+func (t *Type) Is(name string) bool {
+	if strings.EqualFold(t.Name, name) {
+		return true
+	}
+	for _, a := range t.Aliases {
+		if strings.EqualFold(a, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Param returns the parameter of the type with the given name, if exists.
+func (t *Type) Param(name string) (*Param, bool) {
+	for _, p := range t.Params {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// AvailableIn reports if the type is available in the given database flavor and version.
+// An empty flavor matches all flavors, and an empty version matches all versions.
+func (t *Type) AvailableIn(flavor, version string) bool {
+	if len(t.Availability) == 0 {
+		return true
+	}
+	for _, a := range t.Availability {
+		if a.match(flavor, version) {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports if the availability range matches the given flavor and version.
+func (a *Availability) match(flavor, version string) bool {
+	if flavor != "" && a.Flavor != "" && !strings.EqualFold(a.Flavor, flavor) {
+		return false
+	}
+	if version == "" {
+		return true
+	}
+	v := canonical(version)
+	if a.Since != "" && semver.Compare(v, canonical(a.Since)) == -1 {
+		return false
+	}
+	return a.Until == "" || semver.Compare(v, canonical(a.Until)) == -1
+}
+
+// canonical returns the semver form of the given version. Suffixes that
+// are not part of the version number (e.g. "-MariaDB") are dropped.
+func canonical(v string) string {
+	v = strings.TrimPrefix(v, "v")
+	for i, r := range v {
+		if (r < '0' || r > '9') && r != '.' {
+			v = v[:i]
+			break
+		}
+	}
+	return "v" + strings.TrimSuffix(v, ".")
+}
@@ -0,0 +1,85 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package typedoc_test
+
+import (
+	"reflect"
+	"testing"
+
+	"ariga.io/atlas/sql/typedoc"
+
+	"github.com/stretchr/testify/require"
+)
+
+var dialect = &typedoc.Dialect{
+	Name: "test",
+	Types: []*typedoc.Type{
+		{Name: "int", Aliases: []string{"integer"}, Category: typedoc.CategoryInteger},
+		{Name: "varchar", Category: typedoc.CategoryString, Params: []*typedoc.Param{{Name: "size", Kind: reflect.Int, Required: true}}},
+		{
+			Name:     "json",
+			Category: typedoc.CategoryJSON,
+			Availability: []*typedoc.Availability{
+				{Flavor: "mysql", Since: "5.7.8"},
+				{Flavor: "mariadb", Since: "10.2.7"},
+			},
+		},
+		{Name: "legacy", Category: typedoc.CategoryString, Availability: []*typedoc.Availability{{Until: "8"}}},
+	},
+}
+
+func TestDialect_Lookup(t *testing.T) {
+	typ, ok := dialect.Lookup("INTEGER")
+	require.True(t, ok)
+	require.Equal(t, "int", typ.Name)
+
+	typ, ok = dialect.Lookup("varchar(255)")
+	require.True(t, ok)
+	require.Equal(t, "varchar", typ.Name)
+	p, ok := typ.Param("size")
+	require.True(t, ok)
+	require.True(t, p.Required)
+	_, ok = typ.Param("scale")
+	require.False(t, ok)
+
+	_, ok = dialect.Lookup("unknown")
+	require.False(t, ok)
+}
+
+func TestDialect_Query(t *testing.T) {
+	names := func(types []*typedoc.Type) []string {
+		var n []string
+		for _, t := range types {
+			n = append(n, t.Name)
+		}
+		return n
+	}
+	require.Equal(t, []string{"int", "varchar", "json", "legacy"}, names(dialect.Query(nil)))
+	require.Equal(t, []string{"int"}, names(dialect.Query(&typedoc.Query{Name: "Integer"})))
+	require.Equal(t, []string{"varchar", "legacy"}, names(dialect.Query(&typedoc.Query{Category: typedoc.CategoryString})))
+	require.Equal(t, []string{"int", "varchar", "json"}, names(dialect.Query(&typedoc.Query{Version: "8.0.13"})))
+	require.Equal(t, []string{"int", "varchar", "legacy"}, names(dialect.Query(&typedoc.Query{Flavor: "mysql", Version: "5.7"})))
+	require.Equal(t, []string{"int", "varchar", "json", "legacy"}, names(dialect.Query(&typedoc.Query{Flavor: "mysql", Version: "5.7.8"})))
+	require.Equal(t, []string{"int", "varchar", "legacy"}, names(dialect.Query(&typedoc.Query{Flavor: "mariadb", Version: "5.7.8"})))
+	require.Equal(t, []string{"int", "varchar", "json"}, names(dialect.Query(&typedoc.Query{Flavor: "MariaDB", Version: "10.5.8-MariaDB"})))
+	require.Equal(t, []string{"int", "varchar", "legacy"}, names(dialect.Query(&typedoc.Query{Flavor: "tidb"})))
+}
+
+func TestType_AvailableIn(t *testing.T) {
+	typ, ok := dialect.Lookup("json")
+	require.True(t, ok)
+	require.True(t, typ.AvailableIn("", ""))
+	require.True(t, typ.AvailableIn("mysql", ""))
+	require.True(t, typ.AvailableIn("", "v8.0"))
+	require.False(t, typ.AvailableIn("mysql", "5.6.51"))
+	require.False(t, typ.AvailableIn("mariadb", "10.2"))
+	require.True(t, typ.AvailableIn("mariadb", "10.2.7"))
+
+	typ, ok = dialect.Lookup("legacy")
+	require.True(t, ok)
+	require.True(t, typ.AvailableIn("", "7.4.1"))
+	require.False(t, typ.AvailableIn("", "8"))
+	require.False(t, typ.AvailableIn("", "8.0.1"))
+}